@@ -42,6 +42,7 @@ func main() {
 	}
 
 	if err := cmd.Execute(); err != nil {
+		helmcmd.ReportError(os.Stderr, err)
 		var cerr helmcmd.CommandError
 		if errors.As(err, &cerr) {
 			os.Exit(cerr.ExitCode)
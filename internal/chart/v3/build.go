@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+// Built-in build steps.
+const (
+	// BuildStepValuesSchema generates values.schema.json from the types of
+	// the default values in values.yaml.
+	BuildStepValuesSchema = "values-schema"
+	// BuildStepReadmeValues renders a table of the default values in
+	// README.md.
+	BuildStepReadmeValues = "readme-values"
+)
+
+// Build describes the steps that prepare a chart directory before the chart
+// is packaged, such as generating files. It is the build section of
+// Chart.yaml:
+//
+//	build:
+//	  steps:
+//	  - name: render CRDs
+//	    command: [make, crds]
+//	  - builtin: values-schema
+//	  - builtin: readme-values
+type Build struct {
+	// Steps are run in order.
+	Steps []*BuildStep `json:"steps,omitempty"`
+}
+
+// BuildStep is a step of a chart build. It either runs a command or a
+// built-in step.
+type BuildStep struct {
+	// Name describes the step in the build output.
+	Name string `json:"name,omitempty"`
+	// Command is the program to run and its arguments. It is not run by a
+	// shell.
+	Command []string `json:"command,omitempty"`
+	// Builtin is the name of a step implemented by Helm.
+	Builtin string `json:"builtin,omitempty"`
+}
+
+// String returns the name of the step, or what it runs.
+func (s *BuildStep) String() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.Builtin != "":
+		return s.Builtin
+	case len(s.Command) > 0:
+		return s.Command[0]
+	}
+	return ""
+}
+
+// Validate checks that each step runs either a command or a known built-in
+// step.
+func (b *Build) Validate() error {
+	if b == nil {
+		return nil
+	}
+	for i, s := range b.Steps {
+		if s == nil {
+			return ValidationError("chart.metadata.build.steps must not contain empty or null nodes")
+		}
+		s.Name = sanitizeString(s.Name)
+		switch {
+		case len(s.Command) > 0 && s.Builtin != "":
+			return ValidationErrorf("chart.metadata.build.steps[%d] must not set both command and builtin", i)
+		case len(s.Command) > 0:
+			if s.Command[0] == "" {
+				return ValidationErrorf("chart.metadata.build.steps[%d].command must start with a program", i)
+			}
+		case s.Builtin != "":
+			if !isValidBuiltinStep(s.Builtin) {
+				return ValidationErrorf("chart.metadata.build.steps[%d].builtin %q is unknown", i, s.Builtin)
+			}
+		default:
+			return ValidationErrorf("chart.metadata.build.steps[%d] must set command or builtin", i)
+		}
+	}
+	return nil
+}
+
+func isValidBuiltinStep(name string) bool {
+	switch name {
+	case BuildStepValuesSchema, BuildStepReadmeValues:
+		return true
+	}
+	return false
+}
@@ -45,8 +45,25 @@ type Dependency struct {
 	// ImportValues holds the mapping of source values to parent key to be imported. Each item can be a
 	// string or pair of child/parent sublist items.
 	ImportValues []any `json:"import-values,omitempty" yaml:"import-values,omitempty"`
+	// ExportValues holds the mapping of parent values to keys of the dependency's values. It is the
+	// counterpart of ImportValues and lets a parent chart feed charts with a different values layout.
+	ExportValues []ExportValue `json:"export-values,omitempty" yaml:"export-values,omitempty"`
 	// Alias usable alias to be used for the chart
 	Alias string `json:"alias,omitempty" yaml:"alias,omitempty"`
+	// TemplateAlias imports the templates the dependency exports with
+	// exportTemplates. Each is available to the chart as
+	// "<TemplateAlias>.<name>".
+	TemplateAlias string `json:"template-alias,omitempty" yaml:"template-alias,omitempty"`
+}
+
+// ExportValue maps a path in the values of a parent chart to a path in the
+// values of one of its dependencies. Paths use the dot notation, e.g.
+// "database.host", and "." refers to the root of the values.
+type ExportValue struct {
+	// Parent is the path of the value in the parent chart.
+	Parent string `json:"parent" yaml:"parent"`
+	// Child is the path the value is copied to in the dependency.
+	Child string `json:"child" yaml:"child"`
 }
 
 // Validate checks for common problems with the dependency datastructure in
@@ -63,9 +80,20 @@ func (d *Dependency) Validate() error {
 	for i := range d.Tags {
 		d.Tags[i] = sanitizeString(d.Tags[i])
 	}
+	for i := range d.ExportValues {
+		ev := &d.ExportValues[i]
+		ev.Parent = sanitizeString(ev.Parent)
+		ev.Child = sanitizeString(ev.Child)
+		if ev.Parent == "" || ev.Child == "" {
+			return ValidationErrorf("dependency %q has an export-values entry without a parent or child path", d.Name)
+		}
+	}
 	if d.Alias != "" && !aliasNameFormat.MatchString(d.Alias) {
 		return ValidationErrorf("dependency %q has disallowed characters in the alias", d.Name)
 	}
+	if d.TemplateAlias != "" && !aliasNameFormat.MatchString(d.TemplateAlias) {
+		return ValidationErrorf("dependency %q has disallowed characters in the template alias", d.Name)
+	}
 	return nil
 }
 
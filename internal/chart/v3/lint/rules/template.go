@@ -89,9 +89,12 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 
 	// lint ignores import-values
 	// See https://github.com/helm/helm/issues/9658
-	if err := chartutil.ProcessDependencies(chart, values); err != nil {
+	if err := chartutil.ProcessDependenciesWithoutValidation(chart, values); err != nil {
 		return
 	}
+	if !skipSchemaValidation {
+		linter.RunLinterRule(support.ErrorSev, "values.yaml", chartutil.ValidateGlobalValues(chart, values))
+	}
 
 	cvals, err := util.CoalesceValues(chart, values)
 	if err != nil {
@@ -105,6 +108,15 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 	}
 	var e engine.Engine
 	e.LintMode = true
+	e.DuplicateTemplateHandler = func(err *engine.DuplicateTemplateError) {
+		// Identical definitions, such as helpers vendored by several
+		// subcharts, behave the same whichever one is used.
+		severity := support.WarningSev
+		if err.Identical {
+			severity = support.InfoSev
+		}
+		linter.RunLinterRule(severity, fpath, err)
+	}
 	renderedContentMap, err := e.RenderWithContext(context.Background(), chart, valuesToRender)
 
 	renderOk := linter.RunLinterRule(support.ErrorSev, fpath, err)
@@ -17,6 +17,7 @@ package v3
 
 import (
 	"path/filepath"
+	"slices"
 	"strings"
 	"unicode"
 
@@ -81,6 +82,24 @@ type Metadata struct {
 	Dependencies []*Dependency `json:"dependencies,omitempty"`
 	// Specifies the chart type: application or library
 	Type string `json:"type,omitempty"`
+	// TemplateDelimiters replaces the "{{" and "}}" delimiters of the
+	// templates of the chart, such as with ["[[", "]]"]. It is useful for
+	// charts whose templates contain other templating languages. Strings
+	// rendered with the tpl function keep the default delimiters.
+	TemplateDelimiters []string `json:"templateDelimiters,omitempty"`
+	// ExportTemplates are the named templates of a library chart that the
+	// charts depending on it can import under an alias, see
+	// Dependency.TemplateAlias.
+	ExportTemplates []string `json:"exportTemplates,omitempty"`
+	// Build lists the steps that prepare the chart directory before it is
+	// packaged with 'helm package --run-build'.
+	Build *Build `json:"build,omitempty"`
+	// RawManifests includes the manifests under the manifests/ directory of
+	// the chart in the release verbatim, without rendering them as templates.
+	RawManifests bool `json:"rawManifests,omitempty"`
+	// SubchartPatches applies the patches under the patches/<subchart>/
+	// directories of the chart to the rendered output of its subcharts.
+	SubchartPatches bool `json:"subchartPatches,omitempty"`
 }
 
 // Validate checks the metadata for known issues and sanitizes string
@@ -128,6 +147,9 @@ func (md *Metadata) Validate() error {
 	if !isValidChartType(md.Type) {
 		return ValidationError("chart.metadata.type must be application or library")
 	}
+	if !isValidTemplateDelimiters(md.TemplateDelimiters) {
+		return ValidationError("chart.metadata.templateDelimiters must be a left and a right delimiter, without spaces")
+	}
 
 	for _, m := range md.Maintainers {
 		if err := m.Validate(); err != nil {
@@ -151,7 +173,7 @@ func (md *Metadata) Validate() error {
 		}
 		dependencies[key] = dependency
 	}
-	return nil
+	return md.Build.Validate()
 }
 
 func isValidChartType(in string) bool {
@@ -162,6 +184,15 @@ func isValidChartType(in string) bool {
 	return false
 }
 
+func isValidTemplateDelimiters(delims []string) bool {
+	if len(delims) == 0 {
+		return true
+	}
+	return len(delims) == 2 && !slices.ContainsFunc(delims, func(d string) bool {
+		return d == "" || strings.ContainsFunc(d, unicode.IsSpace)
+	})
+}
+
 func isValidSemver(v string) bool {
 	_, err := semver.NewVersion(v)
 	return err == nil
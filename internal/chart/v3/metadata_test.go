@@ -66,6 +66,21 @@ func TestValidate(t *testing.T) {
 			&Metadata{Name: "test", APIVersion: "v3", Version: "1.0", Type: "test"},
 			ValidationError("chart.metadata.type must be application or library"),
 		},
+		{
+			"chart with custom template delimiters",
+			&Metadata{Name: "test", APIVersion: "v3", Version: "1.0", TemplateDelimiters: []string{"[[", "]]"}},
+			nil,
+		},
+		{
+			"chart with a single template delimiter",
+			&Metadata{Name: "test", APIVersion: "v3", Version: "1.0", TemplateDelimiters: []string{"[["}},
+			ValidationError("chart.metadata.templateDelimiters must be a left and a right delimiter, without spaces"),
+		},
+		{
+			"chart with an empty template delimiter",
+			&Metadata{Name: "test", APIVersion: "v3", Version: "1.0", TemplateDelimiters: []string{"[[", " "}},
+			ValidationError("chart.metadata.templateDelimiters must be a left and a right delimiter, without spaces"),
+		},
 		{
 			"chart without dependency",
 			&Metadata{Name: "test", APIVersion: "v3", Version: "1.0", Type: "application"},
@@ -97,6 +112,19 @@ func TestValidate(t *testing.T) {
 			},
 			ValidationError("dependency \"bad\" has disallowed characters in the alias"),
 		},
+		{
+			"dependency with bad characters in template alias",
+			&Metadata{
+				Name:       "test",
+				APIVersion: "v3",
+				Version:    "1.0",
+				Type:       "application",
+				Dependencies: []*Dependency{
+					{Name: "bad", TemplateAlias: "illegal.alias"},
+				},
+			},
+			ValidationError("dependency \"bad\" has disallowed characters in the template alias"),
+		},
 		{
 			"same dependency twice",
 			&Metadata{
@@ -188,6 +216,31 @@ func TestValidate(t *testing.T) {
 			&Metadata{APIVersion: "3", Name: "test", Version: "1.2.3.4"},
 			ValidationError("chart.metadata.version \"1.2.3.4\" is invalid"),
 		},
+		{
+			"build with valid steps",
+			&Metadata{APIVersion: "v3", Name: "test", Version: "1.0", Build: &Build{Steps: []*BuildStep{
+				{Name: "crds", Command: []string{"make", "crds"}},
+				{Builtin: BuildStepValuesSchema},
+			}}},
+			nil,
+		},
+		{
+			"build step without command or builtin",
+			&Metadata{APIVersion: "v3", Name: "test", Version: "1.0", Build: &Build{Steps: []*BuildStep{{Name: "nothing"}}}},
+			ValidationError("chart.metadata.build.steps[0] must set command or builtin"),
+		},
+		{
+			"build step with command and builtin",
+			&Metadata{APIVersion: "v3", Name: "test", Version: "1.0", Build: &Build{Steps: []*BuildStep{
+				{Command: []string{"make"}, Builtin: BuildStepReadmeValues},
+			}}},
+			ValidationError("chart.metadata.build.steps[0] must not set both command and builtin"),
+		},
+		{
+			"build step with unknown builtin",
+			&Metadata{APIVersion: "v3", Name: "test", Version: "1.0", Build: &Build{Steps: []*BuildStep{{Builtin: "helm-docs"}}}},
+			ValidationError("chart.metadata.build.steps[0].builtin \"helm-docs\" is unknown"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,285 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	chart "helm.sh/helm/v4/internal/chart/v3"
+)
+
+// ReadmefileName is the name of the chart README.
+const ReadmefileName = "README.md"
+
+// The markers around the values table that the readme-values build step
+// renders in the README.
+const (
+	ReadmeValuesStart = "<!-- helm:values:start -->"
+	ReadmeValuesEnd   = "<!-- helm:values:end -->"
+)
+
+// DefaultBuildStepTimeout is the time a build command may run when
+// BuildOptions does not set a timeout.
+var DefaultBuildStepTimeout = 5 * time.Minute
+
+// ErrBuildCommandsNotAllowed is returned by RunBuild for builds that run
+// commands when BuildOptions.AllowCommands is not set.
+var ErrBuildCommandsNotAllowed = errors.New("running build commands is not allowed")
+
+// BuildOptions configures RunBuild.
+type BuildOptions struct {
+	// Out receives the build progress and the output of the commands.
+	Out io.Writer
+	// AllowCommands allows the build steps that run commands.
+	AllowCommands bool
+	// Timeout is the time each command may run.
+	Timeout time.Duration
+}
+
+// RunBuild runs the build steps declared in the Chart.yaml of the chart
+// directory dir, in order. The metadata is validated first, and nothing is run
+// when a command is not allowed.
+//
+// Commands run without a shell, in the chart directory, with an environment
+// limited to PATH, HOME, an empty TMPDIR and the HELM_CHART_DIR,
+// HELM_CHART_NAME and HELM_CHART_VERSION variables, and are stopped after the
+// timeout. They are otherwise not isolated from the system, so only allow the
+// commands of charts you trust.
+func RunBuild(ctx context.Context, dir string, opts BuildOptions) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	md, err := LoadChartfile(filepath.Join(dir, ChartfileName))
+	if err != nil {
+		return err
+	}
+	if err := md.Validate(); err != nil {
+		return err
+	}
+	if md.Build == nil || len(md.Build.Steps) == 0 {
+		return nil
+	}
+	steps := md.Build.Steps
+	if !opts.AllowCommands {
+		for _, s := range steps {
+			if len(s.Command) > 0 {
+				return fmt.Errorf("build step %q: %w", s, ErrBuildCommandsNotAllowed)
+			}
+		}
+	}
+
+	out := opts.Out
+	if out == nil {
+		out = io.Discard
+	}
+	for i, s := range steps {
+		fmt.Fprintf(out, "Running build step %d/%d: %s\n", i+1, len(steps), s)
+		var err error
+		switch {
+		case len(s.Command) > 0:
+			err = runBuildCommand(ctx, dir, md, s.Command, out, opts.Timeout)
+		case s.Builtin == chart.BuildStepValuesSchema:
+			err = buildValuesSchema(dir)
+		case s.Builtin == chart.BuildStepReadmeValues:
+			err = buildReadmeValues(dir, md)
+		}
+		if err != nil {
+			return fmt.Errorf("build step %q: %w", s, err)
+		}
+	}
+	return nil
+}
+
+func runBuildCommand(ctx context.Context, dir string, md *chart.Metadata, command []string, out io.Writer, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultBuildStepTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tmp, err := os.MkdirTemp("", "helm-build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	env := []string{
+		"TMPDIR=" + tmp,
+		"HELM_CHART_DIR=" + dir,
+		"HELM_CHART_NAME=" + md.Name,
+		"HELM_CHART_VERSION=" + md.Version,
+	}
+	for _, name := range []string{"PATH", "HOME", "SYSTEMROOT"} {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("command timed out after %s", timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// buildValuesSchema writes a values.schema.json describing the types of the
+// default values.
+func buildValuesSchema(dir string) error {
+	values, err := readBuildValues(dir)
+	if err != nil {
+		return err
+	}
+	schema := inferSchema(values)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeBuildFile(dir, SchemafileName, append(data, '\n'))
+}
+
+// inferSchema returns a JSON schema matching the type of v.
+func inferSchema(v any) map[string]any {
+	switch v := v.(type) {
+	case map[string]any:
+		properties := map[string]any{}
+		for k, val := range v {
+			properties[k] = inferSchema(val)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	case []any:
+		schema := map[string]any{"type": "array"}
+		if len(v) > 0 {
+			schema["items"] = inferSchema(v[0])
+		}
+		return schema
+	case string:
+		return map[string]any{"type": "string"}
+	case bool:
+		return map[string]any{"type": "boolean"}
+	case float64:
+		if v == math.Trunc(v) {
+			return map[string]any{"type": "integer"}
+		}
+		return map[string]any{"type": "number"}
+	}
+	// Null values may be set to anything.
+	return map[string]any{}
+}
+
+// buildReadmeValues renders the table of the default values between the
+// values markers of the README, adding a Values section with the markers when
+// the README has none.
+func buildReadmeValues(dir string, md *chart.Metadata) error {
+	values, err := readBuildValues(dir)
+	if err != nil {
+		return err
+	}
+	var table bytes.Buffer
+	table.WriteString("| Key | Type | Default |\n|-----|------|---------|\n")
+	writeValuesRows(&table, "", values)
+	region := ReadmeValuesStart + "\n" + table.String() + ReadmeValuesEnd
+
+	readme, err := os.ReadFile(filepath.Join(dir, ReadmefileName))
+	if errors.Is(err, os.ErrNotExist) {
+		readme = []byte("# " + md.Name + "\n")
+	} else if err != nil {
+		return err
+	}
+	content := string(readme)
+	start := strings.Index(content, ReadmeValuesStart)
+	end := strings.Index(content, ReadmeValuesEnd)
+	switch {
+	case start >= 0 && end > start:
+		content = content[:start] + region + content[end+len(ReadmeValuesEnd):]
+	case start >= 0 || end >= 0:
+		return fmt.Errorf("%s must contain both %s and %s, in order", ReadmefileName, ReadmeValuesStart, ReadmeValuesEnd)
+	default:
+		content = strings.TrimRight(content, "\n") + "\n\n## Values\n\n" + region + "\n"
+	}
+	return writeBuildFile(dir, ReadmefileName, []byte(content))
+}
+
+// writeValuesRows writes a table row for each value that is not a non-empty
+// map, keyed by its dotted path.
+func writeValuesRows(w io.Writer, prefix string, values map[string]any) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		v := values[k]
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			writeValuesRows(w, prefix+k+".", m)
+			continue
+		}
+		typ, _ := inferSchema(v)["type"].(string)
+		if typ == "" {
+			typ = "null"
+		}
+		def, _ := json.Marshal(v)
+		fmt.Fprintf(w, "| %s | %s | `%s` |\n", prefix+k, typ, strings.ReplaceAll(string(def), "|", `\|`))
+	}
+}
+
+func readBuildValues(dir string) (map[string]any, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ValuesfileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]any{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", ValuesfileName, err)
+	}
+	return values, nil
+}
+
+// writeBuildFile writes a file of the chart directory, refusing to follow a
+// symbolic link out of it.
+func writeBuildFile(dir, name string, data []byte) error {
+	path := filepath.Join(dir, name)
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("refusing to write %s: it is a symbolic link", name)
+	}
+	return os.WriteFile(path, data, 0644)
+}
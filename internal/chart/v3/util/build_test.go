@@ -0,0 +1,166 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuildHelperProcess is run as a build command by the build tests.
+func TestBuildHelperProcess(_ *testing.T) {
+	dir := os.Getenv("HELM_CHART_DIR")
+	if dir == "" {
+		return
+	}
+	if len(os.Args) > 0 && os.Args[len(os.Args)-1] == "sleep" {
+		time.Sleep(time.Minute)
+	}
+	out := fmt.Sprintf("name=%s version=%s secret=%s tmp=%t\n",
+		os.Getenv("HELM_CHART_NAME"), os.Getenv("HELM_CHART_VERSION"), os.Getenv("HELM_BUILD_TEST_SECRET"), os.Getenv("TMPDIR") != "")
+	if err := os.WriteFile(filepath.Join(dir, "generated.txt"), []byte(out), 0644); err != nil {
+		os.Exit(1)
+	}
+	fmt.Println("generated.txt written")
+	os.Exit(0)
+}
+
+func writeBuildChart(t *testing.T, build string) string {
+	t.Helper()
+	dir := t.TempDir()
+	chartfile := "apiVersion: v3\nname: builder\nversion: 1.2.3\n" + build
+	values := "replicaCount: 1\nratio: 0.5\nimage:\n  repository: nginx\n  tag: \"\"\nlabels: {}\nports: [80]\ncommand: null\nmotd: a|b\n"
+	for name, data := range map[string]string{ChartfileName: chartfile, ValuesfileName: values} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRunBuild(t *testing.T) {
+	t.Setenv("HELM_BUILD_TEST_SECRET", "leaked")
+	helper := fmt.Sprintf("[%q, \"-test.run=^TestBuildHelperProcess$\"]", os.Args[0])
+	dir := writeBuildChart(t, `build:
+  steps:
+  - name: generate
+    command: `+helper+`
+  - builtin: values-schema
+  - builtin: readme-values
+`)
+	readme := "# Builder\n\nIntro.\n\n" + ReadmeValuesStart + "\nstale\n" + ReadmeValuesEnd + "\n\nFooter.\n"
+	if err := os.WriteFile(filepath.Join(dir, ReadmefileName), []byte(readme), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunBuild(context.Background(), dir, BuildOptions{}); !errors.Is(err, ErrBuildCommandsNotAllowed) {
+		t.Fatalf("expected commands not to be allowed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SchemafileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("expected no step to run when commands are not allowed")
+	}
+
+	var out bytes.Buffer
+	if err := RunBuild(context.Background(), dir, BuildOptions{Out: &out, AllowCommands: true}); err != nil {
+		t.Fatalf("build failed: %s\n%s", err, out.String())
+	}
+	for _, line := range []string{
+		"Running build step 1/3: generate\n",
+		"generated.txt written\n",
+		"Running build step 3/3: readme-values\n",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected output to contain %q, got %q", line, out.String())
+		}
+	}
+
+	generated, err := os.ReadFile(filepath.Join(dir, "generated.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "name=builder version=1.2.3 secret= tmp=true\n"; string(generated) != expect {
+		t.Errorf("expected the command to run with the build environment %q, got %q", expect, generated)
+	}
+
+	schema, err := os.ReadFile(filepath.Join(dir, SchemafileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"replicaCount": {`, `"type": "integer"`, `"type": "number"`, `"items": {`, `"$schema": "http://json-schema.org/draft-07/schema#"`} {
+		if !bytes.Contains(schema, []byte(s)) {
+			t.Errorf("expected the schema to contain %s, got:\n%s", s, schema)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, ReadmefileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "# Builder\n\nIntro.\n\n" + ReadmeValuesStart + `
+| Key | Type | Default |
+|-----|------|---------|
+| command | null | ` + "`null`" + ` |
+| image.repository | string | ` + "`\"nginx\"`" + ` |
+| image.tag | string | ` + "`\"\"`" + ` |
+| labels | object | ` + "`{}`" + ` |
+| motd | string | ` + "`\"a\\|b\"`" + ` |
+| ports | array | ` + "`[80]`" + ` |
+| ratio | number | ` + "`0.5`" + ` |
+| replicaCount | integer | ` + "`1`" + ` |
+` + ReadmeValuesEnd + "\n\nFooter.\n"
+	if string(got) != expect {
+		t.Errorf("expected README:\n%s\ngot:\n%s", expect, got)
+	}
+}
+
+func TestRunBuildReadmeWithoutMarkers(t *testing.T) {
+	dir := writeBuildChart(t, "build:\n  steps:\n  - builtin: readme-values\n")
+	if err := RunBuild(context.Background(), dir, BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, ReadmefileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "# builder\n\n## Values\n\n"+ReadmeValuesStart+"\n| Key | Type | Default |\n") {
+		t.Errorf("expected a Values section to be added, got:\n%s", got)
+	}
+}
+
+func TestRunBuildTimeout(t *testing.T) {
+	helper := fmt.Sprintf("[%q, \"-test.run=^TestBuildHelperProcess$\", \"sleep\"]", os.Args[0])
+	dir := writeBuildChart(t, "build:\n  steps:\n  - command: "+helper+"\n")
+	err := RunBuild(context.Background(), dir, BuildOptions{AllowCommands: true, Timeout: 100 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "command timed out after 100ms") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestRunBuildInvalid(t *testing.T) {
+	dir := writeBuildChart(t, "build:\n  steps:\n  - builtin: helm-docs\n")
+	err := RunBuild(context.Background(), dir, BuildOptions{})
+	if err == nil || err.Error() != `validation: chart.metadata.build.steps[0].builtin "helm-docs" is unknown` {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package util
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -29,13 +30,93 @@ import (
 )
 
 // ProcessDependencies checks through this chart's dependencies, processing accordingly.
+// The global values are then validated against the schemas of the enabled
+// subcharts, see ValidateGlobalValues.
 func ProcessDependencies(c *chart.Chart, v common.Values) error {
+	if err := ProcessDependenciesWithoutValidation(c, v); err != nil {
+		return err
+	}
+	return ValidateGlobalValues(c, v)
+}
+
+// ProcessDependenciesWithoutValidation processes the dependencies of a chart
+// like ProcessDependencies, without validating the global values.
+func ProcessDependenciesWithoutValidation(c *chart.Chart, v common.Values) error {
 	if err := processDependencyEnabled(c, v, ""); err != nil {
 		return err
 	}
+	if err := processDependencyExportValues(c, v); err != nil {
+		return err
+	}
 	return processDependencyImportValues(c, true)
 }
 
+// ValidateGlobalValues validates the global values of a chart, coalesced with
+// the given values, against the "global" section of the schema of each of its
+// subcharts, recursively. Together, these sections make up the schema of the
+// global values. The error reports which subchart rejects which global key.
+func ValidateGlobalValues(c *chart.Chart, v common.Values) error {
+	vals, err := util.CoalesceValues(c, v)
+	if err != nil {
+		return err
+	}
+	globals, ok := vals[common.GlobalKey].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var sb strings.Builder
+	validateSubchartGlobals(c, "", common.Values{common.GlobalKey: globals}, &sb)
+	if sb.Len() > 0 {
+		return fmt.Errorf("global values rejected by subcharts:\n%s", sb.String())
+	}
+	return nil
+}
+
+func validateSubchartGlobals(c *chart.Chart, path string, globals common.Values, sb *strings.Builder) {
+	for _, sub := range c.Dependencies() {
+		subPath := sub.Name()
+		if path != "" {
+			subPath = path + "/" + subPath
+		}
+		schema, err := globalSchema(sub.Schema)
+		if err != nil {
+			fmt.Fprintf(sb, "%s:\ninvalid schema: %s\n", subPath, err)
+		} else if schema != nil {
+			if err := util.ValidateAgainstSingleSchema(globals, schema); err != nil {
+				fmt.Fprintf(sb, "%s:\n%s", subPath, err)
+			}
+		}
+		validateSubchartGlobals(sub, subPath, globals, sb)
+	}
+}
+
+// globalSchema returns a schema with only the "global" section of a values
+// schema, along with its definitions, or nil if it has none.
+func globalSchema(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	global, ok := properties[common.GlobalKey]
+	if !ok {
+		return nil, nil
+	}
+	globalOnly := map[string]any{
+		"properties": map[string]any{common.GlobalKey: global},
+	}
+	for _, key := range []string{"$schema", "$id", "definitions", "$defs"} {
+		if v, ok := schema[key]; ok {
+			globalOnly[key] = v
+		}
+	}
+	return json.Marshal(globalOnly)
+}
+
 // processDependencyConditions disables charts based on condition path value in values
 func processDependencyConditions(reqs []*chart.Dependency, cvals common.Values, cpath string) {
 	if reqs == nil {
@@ -372,6 +453,81 @@ func istable(v any) bool {
 	return ok
 }
 
+// processDependencyExportValues copies values from parent to child based on
+// the ExportValues field of the chart's dependencies, recursively. The values
+// are read from the chart values coalesced with v, so values supplied by the
+// user are exported too.
+//
+// Exported values take precedence over the defaults the parent chart sets for
+// the dependency, while values the user sets for the dependency directly still
+// override them.
+func processDependencyExportValues(c *chart.Chart, v map[string]any) error {
+	if c.Metadata.Dependencies == nil {
+		return nil
+	}
+	cvals, err := util.CoalesceValues(c, v)
+	if err != nil {
+		return err
+	}
+	b := make(map[string]any)
+	for _, r := range c.Metadata.Dependencies {
+		for _, ev := range r.ExportValues {
+			vv, ok := valueAtPath(cvals, ev.Parent)
+			if !ok {
+				slog.Warn(
+					"ExportValues missing value from parent chart",
+					slog.String("chart", r.Name),
+					slog.String("path", ev.Parent),
+				)
+				continue
+			}
+			b = util.MergeTables(b, pathToMap(r.Name, valueToMap(ev.Child, vv)))
+		}
+	}
+	if len(b) > 0 {
+		c.Values = util.MergeTables(deepCopyMap(b), deepCopyMap(c.Values))
+		if cvals, err = util.CoalesceValues(c, v); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range c.Dependencies() {
+		sub, _ := cvals[d.Name()].(map[string]any)
+		if err := processDependencyExportValues(d, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valueAtPath returns the table or the value at path in YAML dot notation.
+func valueAtPath(vals common.Values, path string) (any, bool) {
+	if path == "." {
+		return vals.AsMap(), true
+	}
+	if t, err := vals.Table(path); err == nil {
+		return t.AsMap(), true
+	}
+	v, err := vals.PathValue(path)
+	return v, err == nil
+}
+
+// valueToMap creates a nested map holding v at path in YAML dot notation.
+func valueToMap(path string, v any) map[string]any {
+	if path == "." {
+		if m, ok := v.(map[string]any); ok {
+			return m
+		}
+		return map[string]any{}
+	}
+	keys := parsePath(path)
+	m := map[string]any{keys[len(keys)-1]: v}
+	for _, k := range slices.Backward(keys[:len(keys)-1]) {
+		m = map[string]any{k: m}
+	}
+	return m
+}
+
 // processDependencyImportValues imports specified chart values from child to parent.
 func processDependencyImportValues(c *chart.Chart, merge bool) error {
 	for _, d := range c.Dependencies() {
@@ -95,6 +95,14 @@ func Expand(dir string, r io.Reader) error {
 		if err := os.WriteFile(outpath, file.Data, 0644); err != nil {
 			return err
 		}
+
+		// Keep the modification times of the archive so that packaging the
+		// expanded chart again can reproduce it.
+		if !file.ModTime.IsZero() {
+			if err := os.Chtimes(outpath, file.ModTime, file.ModTime); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
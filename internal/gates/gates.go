@@ -19,3 +19,6 @@ import "helm.sh/helm/v4/pkg/gates"
 
 // ChartV3 is the feature gate for chart API version v3.
 const ChartV3 gates.Gate = "HELM_EXPERIMENTAL_CHART_V3"
+
+// StagedUpgrade is the feature gate for staged upgrades.
+const StagedUpgrade gates.Gate = "HELM_EXPERIMENTAL_STAGED_UPGRADE"
@@ -0,0 +1,319 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package manifestschema validates rendered Kubernetes manifests without a cluster.
+
+Resources are validated against, in order:
+
+  - the schemas of CustomResourceDefinitions registered with AddCRDs,
+  - JSON schemas found in the schema directories, and
+  - the built-in Kubernetes types, decoded strictly so that unknown and
+    mistyped fields are reported.
+
+Schema directories use the layout of the kubeconform schema catalogs:
+
+	<dir>/v<kube version>-standalone-strict/<kind>-<group>-<version>.json
+	<dir>/v<kube version>-standalone/<kind>-<group>-<version>.json
+	<dir>/v<kube version>/<kind>-<group>-<version>.json
+	<dir>/<kind>-<group>-<version>.json
+	<dir>/<group>/<kind>_<version>.json
+
+where kind is lower case and group is the first label of the API group, or
+is left out, along with its dash, for the core group.
+*/
+package manifestschema
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrSchemaNotFound is returned when no schema is known for a resource.
+var ErrSchemaNotFound = errors.New("no schema found")
+
+// strictDecoder decodes built-in types, failing on unknown or duplicate fields.
+var strictDecoder = serializer.NewCodecFactory(scheme.Scheme, serializer.EnableStrict).UniversalDeserializer()
+
+// Validator validates manifests against the schemas of their kinds.
+type Validator struct {
+	kubeVersion string
+	schemaDirs  []string
+
+	crds    map[schema.GroupVersionKind]*jsonschema.Schema
+	schemas map[string]*jsonschema.Schema
+}
+
+// New returns a Validator looking up schemas for kubeVersion, such as
+// "v1.31.0", in schemaDirs.
+func New(kubeVersion string, schemaDirs []string) *Validator {
+	if kubeVersion != "" && !strings.HasPrefix(kubeVersion, "v") {
+		kubeVersion = "v" + kubeVersion
+	}
+	return &Validator{
+		kubeVersion: kubeVersion,
+		schemaDirs:  schemaDirs,
+		crds:        map[schema.GroupVersionKind]*jsonschema.Schema{},
+		schemas:     map[string]*jsonschema.Schema{},
+	}
+}
+
+// AddCRDs registers the schemas of the CustomResourceDefinition in manifest so
+// that its custom resources can be validated. Manifests of any other kind are
+// ignored.
+func (v *Validator) AddCRDs(manifest []byte) error {
+	var meta struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := yaml.Unmarshal(manifest, &meta); err != nil {
+		return err
+	}
+	if meta.Kind != "CustomResourceDefinition" || meta.APIVersion != apiextensionsv1.SchemeGroupVersion.String() {
+		return nil
+	}
+
+	var crd apiextensionsv1.CustomResourceDefinition
+	if err := yaml.Unmarshal(manifest, &crd); err != nil {
+		return fmt.Errorf("CustomResourceDefinition %q: %w", crd.Name, err)
+	}
+	for _, version := range crd.Spec.Versions {
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		data, err := json.Marshal(version.Schema.OpenAPIV3Schema)
+		if err != nil {
+			return err
+		}
+		id := fmt.Sprintf("file:///crds/%s/%s.json", crd.Name, version.Name)
+		s, err := compile(id, data)
+		if err != nil {
+			return fmt.Errorf("CustomResourceDefinition %q version %s: %w", crd.Name, version.Name, err)
+		}
+		gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+		v.crds[gvk] = s
+	}
+	return nil
+}
+
+// AddCRDFile registers the CustomResourceDefinitions of a file that may hold
+// several YAML documents.
+func (v *Validator) AddCRDFile(data []byte) error {
+	docs, err := splitDocuments(data)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := v.AddCRDs(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateManifests validates each YAML document of manifests and returns the
+// errors of the invalid ones.
+func (v *Validator) ValidateManifests(manifests []byte) []error {
+	docs, err := splitDocuments(manifests)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, doc := range docs {
+		if err := v.Validate(doc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Validate validates a single manifest. Empty manifests are valid. An error
+// wrapping ErrSchemaNotFound is returned for resources of unknown kinds.
+func (v *Validator) Validate(manifest []byte) error {
+	data, err := yaml.YAMLToJSON(manifest)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var meta struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return err
+	}
+	if meta.APIVersion == "" || meta.Kind == "" {
+		return errors.New("apiVersion and kind must be set")
+	}
+	gv, err := schema.ParseGroupVersion(meta.APIVersion)
+	if err != nil {
+		return err
+	}
+	gvk := gv.WithKind(meta.Kind)
+	resource := fmt.Sprintf("%s %q", meta.Kind, meta.Metadata.Name)
+
+	s, err := v.schemaFor(gvk)
+	if err != nil {
+		return fmt.Errorf("%s: %w", resource, err)
+	}
+	if s != nil {
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		if err := s.Validate(doc); err != nil {
+			return fmt.Errorf("%s: %s", resource, validationMessage(err))
+		}
+		return nil
+	}
+
+	if scheme.Scheme.Recognizes(gvk) {
+		if _, _, err := strictDecoder.Decode(data, nil, nil); err != nil {
+			return fmt.Errorf("%s: %w", resource, cleanDecodeError(err))
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: %w for %s", resource, ErrSchemaNotFound, gvk)
+}
+
+// schemaFor returns the JSON schema of gvk, or nil when there is none.
+func (v *Validator) schemaFor(gvk schema.GroupVersionKind) (*jsonschema.Schema, error) {
+	if s, ok := v.crds[gvk]; ok {
+		return s, nil
+	}
+	for _, dir := range v.schemaDirs {
+		for _, path := range v.candidates(dir, gvk) {
+			if s, ok := v.schemas[path]; ok {
+				if s != nil {
+					return s, nil
+				}
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if errors.Is(err, os.ErrNotExist) {
+				v.schemas[path] = nil
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return nil, err
+			}
+			s, err := compile("file://"+filepath.ToSlash(abs), data)
+			if err != nil {
+				return nil, fmt.Errorf("loading schema %s: %w", path, err)
+			}
+			v.schemas[path] = s
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+// candidates returns the files of dir that may hold the schema of gvk.
+func (v *Validator) candidates(dir string, gvk schema.GroupVersionKind) []string {
+	kind := strings.ToLower(gvk.Kind)
+	name := kind + "-" + gvk.Version + ".json"
+	if gvk.Group != "" {
+		name = kind + "-" + strings.Split(gvk.Group, ".")[0] + "-" + gvk.Version + ".json"
+	}
+
+	var paths []string
+	if v.kubeVersion != "" {
+		paths = append(paths,
+			filepath.Join(dir, v.kubeVersion+"-standalone-strict", name),
+			filepath.Join(dir, v.kubeVersion+"-standalone", name),
+			filepath.Join(dir, v.kubeVersion, name),
+		)
+	}
+	paths = append(paths, filepath.Join(dir, name))
+	if gvk.Group != "" {
+		paths = append(paths, filepath.Join(dir, gvk.Group, kind+"_"+gvk.Version+".json"))
+	}
+	return paths
+}
+
+func splitDocuments(data []byte) ([][]byte, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	var docs [][]byte
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+func compile(id string, data []byte) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(id, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(id)
+}
+
+// validationMessage joins the causes of a schema validation error, leaving out
+// the line naming the schema.
+func validationMessage(err error) string {
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	if len(lines) > 1 {
+		lines = lines[1:]
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimSpace(l), "- ")
+	}
+	return strings.Join(lines, "; ")
+}
+
+// cleanDecodeError drops the decoder's type prefix from strict decoding errors.
+func cleanDecodeError(err error) error {
+	if runtime.IsStrictDecodingError(err) {
+		return errors.New(strings.TrimPrefix(err.Error(), "strict decoding error: "))
+	}
+	return err
+}
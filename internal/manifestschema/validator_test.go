@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifestschema
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	crd, err := os.ReadFile("testdata/crd.yaml")
+	require.NoError(t, err)
+
+	v := New("1.31.0", []string{"testdata/schemas"})
+	require.NoError(t, v.AddCRDs(crd))
+	require.NoError(t, v.AddCRDs([]byte("apiVersion: v1\nkind: ConfigMap\n")))
+
+	tests := []struct {
+		name     string
+		manifest string
+		err      string
+		notFound bool
+	}{
+		{
+			name:     "empty",
+			manifest: "# just a comment\n",
+		},
+		{
+			name:     "valid built-in",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  a: b\n",
+		},
+		{
+			name:     "unknown built-in field",
+			manifest: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replica: 3\n",
+			err:      `Deployment "web": unknown field "spec.replica"`,
+		},
+		{
+			name:     "mistyped built-in field",
+			manifest: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: three\n",
+			err:      `Deployment "web"`,
+		},
+		{
+			name:     "valid custom resource",
+			manifest: "apiVersion: example.com/v1\nkind: Gadget\nmetadata:\n  name: g\nspec:\n  color: red\n",
+		},
+		{
+			name:     "invalid custom resource",
+			manifest: "apiVersion: example.com/v1\nkind: Gadget\nmetadata:\n  name: g\nspec:\n  color: green\n",
+			err:      `Gadget "g": at '/spec/color'`,
+		},
+		{
+			name:     "valid with schema dir",
+			manifest: "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\nspec:\n  size: 2\n",
+		},
+		{
+			name:     "invalid with schema dir",
+			manifest: "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\nspec:\n  size: 2\n  color: red\n",
+			err:      `Widget "w": at '/spec'`,
+		},
+		{
+			name:     "unknown kind",
+			manifest: "apiVersion: example.com/v2\nkind: Widget\nmetadata:\n  name: w\n",
+			err:      `Widget "w": no schema found for example.com/v2, Kind=Widget`,
+			notFound: true,
+		},
+		{
+			name:     "missing kind",
+			manifest: "apiVersion: v1\nmetadata:\n  name: w\n",
+			err:      "apiVersion and kind must be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate([]byte(tt.manifest))
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Equal(t, tt.notFound, errors.Is(err, ErrSchemaNotFound))
+		})
+	}
+}
@@ -50,7 +50,31 @@ type SearchResult struct {
 
 // ArtifactHub represents data specific to Artifact Hub instances
 type ArtifactHub struct {
-	PackageURL string `json:"packageUrl"`
+	PackageURL            string                 `json:"packageUrl"`
+	Stars                 int                    `json:"stars"`
+	Deprecated            bool                   `json:"deprecated"`
+	Signed                bool                   `json:"signed"`
+	VerifiedPublisher     bool                   `json:"verifiedPublisher"`
+	Official              bool                   `json:"official"`
+	SecurityReportSummary *SecurityReportSummary `json:"securityReportSummary,omitempty"`
+}
+
+// SecurityReportSummary contains the number of vulnerabilities found by the
+// Artifact Hub security scanner, grouped by severity.
+type SecurityReportSummary struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+// Total returns the total number of vulnerabilities in the summary.
+func (s *SecurityReportSummary) Total() int {
+	if s == nil {
+		return 0
+	}
+	return s.Critical + s.High + s.Medium + s.Low + s.Unknown
 }
 
 // Chart is the attributes for the chart
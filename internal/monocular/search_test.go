@@ -43,3 +43,23 @@ func TestSearch(t *testing.T) {
 
 	assert.Len(t, results, 2)
 }
+
+func TestSearchArtifactHubFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"data":[{"id":"bitnami/phpmyadmin","artifactHub":{"packageUrl":"https://artifacthub.io/packages/helm/bitnami/phpmyadmin","deprecated":true,"verifiedPublisher":true,"securityReportSummary":{"critical":1,"high":2}}}]}`)
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	require.NoError(t, err, "unable to create monocular client")
+
+	results, err := c.SearchWithContext(t.Context(), "phpmyadmin")
+	require.NoError(t, err, "unable to search monocular")
+	require.Len(t, results, 1)
+
+	ah := results[0].ArtifactHub
+	assert.True(t, ah.Deprecated)
+	assert.True(t, ah.VerifiedPublisher)
+	require.NotNil(t, ah.SecurityReportSummary)
+	assert.Equal(t, 3, ah.SecurityReportSummary.Total())
+}
@@ -80,6 +80,12 @@ For legacy plugins, the type is inferred by which fields are set on the plugin:
 
 For v1 plugins, the metadata includes explicit apiVersion and type fields. It will also contain type-specific Config, and RuntimeConfig fields.
 
+# Hooks
+Subprocess plugins may declare hooks, commands run on plugin management events ("install", "update", "delete") and on
+release lifecycle events ("pre-install", "post-install", "pre-upgrade", "post-upgrade", "pre-uninstall",
+"post-uninstall"). The Helm CLI runs release hooks around the corresponding commands, passing the release and chart
+as JSON (schema.InputMessageReleaseHookV1) on the hook's stdin. A failing pre hook aborts the command.
+
 # Runtime and type cardinality
 From a cardinality perspective, this means there a "few" runtimes, and "many" plugins types. It is also expected that the subprocess runtime will not be extended to support extra plugin types, and deprecated in a future version of Helm.
 
@@ -0,0 +1,199 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer // import "helm.sh/helm/v4/internal/plugin/installer"
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// LockFileName is the name of the plugins lockfile in HELM_DATA_HOME.
+const LockFileName = "plugins.lock"
+
+// ErrVersionsUnsupported indicates that the versions available for a plugin
+// source cannot be listed.
+var ErrVersionsUnsupported = errors.New("plugin source does not have versions")
+
+// Lock records the source and version of the installed plugins, so that the
+// same plugins can be installed and updated reproducibly.
+type Lock struct {
+	APIVersion string          `json:"apiVersion"`
+	Plugins    []*LockedPlugin `json:"plugins"`
+}
+
+// LockedPlugin is a plugin recorded in a Lock.
+type LockedPlugin struct {
+	// Name is the name of the plugin.
+	Name string `json:"name"`
+	// Source is where the plugin was installed from.
+	Source string `json:"source"`
+	// Constraint is the version constraint the plugin is pinned to. Updates
+	// stay within it.
+	Constraint string `json:"constraint,omitempty"`
+	// Version is the installed version of the plugin.
+	Version string `json:"version,omitempty"`
+}
+
+// LockFilePath returns the path of the plugins lockfile.
+func LockFilePath() string {
+	return helmpath.DataPath(LockFileName)
+}
+
+// LoadLock loads a plugins lockfile. A lockfile that does not exist yet is
+// empty.
+func LoadLock(path string) (*Lock, error) {
+	l := &Lock{APIVersion: "v1"}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(data, l); err != nil {
+		return nil, fmt.Errorf("invalid plugins lockfile %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// WriteFile writes the lockfile, creating its directory if needed.
+func (l *Lock) WriteFile(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Get returns the locked plugin with a name, or nil.
+func (l *Lock) Get(name string) *LockedPlugin {
+	for _, p := range l.Plugins {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Set adds a plugin to the lockfile, or replaces the plugin with the same
+// name.
+func (l *Lock) Set(p *LockedPlugin) {
+	l.Remove(p.Name)
+	l.Plugins = append(l.Plugins, p)
+	slices.SortFunc(l.Plugins, func(a, b *LockedPlugin) int { return strings.Compare(a.Name, b.Name) })
+}
+
+// Remove removes a plugin from the lockfile. It reports whether the plugin
+// was locked.
+func (l *Lock) Remove(name string) bool {
+	n := len(l.Plugins)
+	l.Plugins = slices.DeleteFunc(l.Plugins, func(p *LockedPlugin) bool { return p.Name == name })
+	return len(l.Plugins) != n
+}
+
+// AvailableVersions lists the versions of a plugin available from its source,
+// from the highest to the lowest. Only OCI and VCS sources have versions:
+// the tags of the OCI repository, or the tags of the VCS repository. The tags
+// of an OCI repository are listed with client, or with a client using the
+// registry credentials of the environment when it is nil.
+func AvailableVersions(source string, client *registry.Client) ([]*semver.Version, error) {
+	var tags []string
+	switch {
+	case strings.HasPrefix(source, registry.OCIScheme+"://"):
+		if client == nil {
+			var err error
+			if client, err = registry.NewClient(registry.ClientOptCredentialsFile(cli.New().RegistryConfig)); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if tags, err = client.Tags(ociRepository(source)); err != nil {
+			return nil, err
+		}
+	case isLocalReference(source), isRemoteHTTPArchive(source):
+		return nil, ErrVersionsUnsupported
+	default:
+		i, err := NewVCSInstaller(source, "")
+		if err != nil {
+			return nil, err
+		}
+		if err := i.sync(i.Repo); err != nil {
+			return nil, err
+		}
+		if tags, err = i.Repo.Tags(); err != nil {
+			return nil, err
+		}
+	}
+
+	versions := getSemVers(tags)
+	slices.SortFunc(versions, func(a, b *semver.Version) int { return b.Compare(a) })
+	return versions, nil
+}
+
+// ResolveVersions returns the highest version satisfying a constraint, or nil,
+// and the highest version. An empty constraint allows any stable version.
+func ResolveVersions(versions []*semver.Version, constraint string) (wanted, latest *semver.Version, err error) {
+	c := ">=0.0.0"
+	if constraint != "" {
+		c = constraint
+	}
+	constraints, err := semver.NewConstraint(c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+	for _, v := range versions {
+		if latest == nil && v.Prerelease() == "" {
+			latest = v
+		}
+		if wanted == nil && constraints.Check(v) {
+			wanted = v
+		}
+	}
+	return wanted, latest, nil
+}
+
+// ociRepository returns the repository of an OCI plugin reference, without
+// the scheme, tag or digest.
+func ociRepository(source string) string {
+	ref := strings.TrimPrefix(source, registry.OCIScheme+"://")
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// OCIReference returns an OCI plugin reference at another version.
+func OCIReference(source, version string) string {
+	return registry.OCIScheme + "://" + ociRepository(source) + ":" + version
+}
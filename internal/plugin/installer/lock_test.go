@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer // import "helm.sh/helm/v4/internal/plugin/installer"
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", LockFileName)
+
+	lock, err := LoadLock(path)
+	if err != nil {
+		t.Fatalf("loading a missing lockfile: %v", err)
+	}
+	if len(lock.Plugins) != 0 {
+		t.Fatalf("expected an empty lockfile, got %d plugins", len(lock.Plugins))
+	}
+
+	lock.Set(&LockedPlugin{Name: "zeta", Source: "https://github.com/example/zeta", Version: "1.0.0"})
+	lock.Set(&LockedPlugin{Name: "alpha", Source: "oci://example.com/alpha", Constraint: "^1.2.0", Version: "1.2.3"})
+	lock.Set(&LockedPlugin{Name: "zeta", Source: "https://github.com/example/zeta", Version: "1.1.0"})
+	if err := lock.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Plugins) != 2 || loaded.Plugins[0].Name != "alpha" || loaded.Plugins[1].Name != "zeta" {
+		t.Fatalf("expected plugins alpha and zeta, got %+v", loaded.Plugins)
+	}
+	if p := loaded.Get("zeta"); p == nil || p.Version != "1.1.0" {
+		t.Errorf("expected zeta at 1.1.0, got %+v", p)
+	}
+	if p := loaded.Get("alpha"); p == nil || p.Constraint != "^1.2.0" {
+		t.Errorf("expected alpha pinned to ^1.2.0, got %+v", p)
+	}
+	if !loaded.Remove("alpha") {
+		t.Error("expected alpha to be removed")
+	}
+	if loaded.Remove("alpha") {
+		t.Error("expected alpha to be removed once")
+	}
+	if loaded.Get("alpha") != nil {
+		t.Error("expected alpha to be gone")
+	}
+
+	if err := os.WriteFile(path, []byte("plugins: [{name: a, unknown: b}]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLock(path); err == nil {
+		t.Error("expected an invalid lockfile to fail to load")
+	}
+}
+
+func TestResolveVersions(t *testing.T) {
+	var versions []*semver.Version
+	for _, v := range []string{"2.1.0-rc.1", "2.0.0", "1.3.0", "1.2.5", "1.2.0"} {
+		versions = append(versions, semver.MustParse(v))
+	}
+
+	tests := []struct {
+		constraint string
+		wanted     string
+		latest     string
+		err        bool
+	}{
+		{constraint: "", wanted: "2.0.0", latest: "2.0.0"},
+		{constraint: "~1.2.0", wanted: "1.2.5", latest: "2.0.0"},
+		{constraint: "^1.0.0", wanted: "1.3.0", latest: "2.0.0"},
+		{constraint: ">=2.1.0-0", wanted: "2.1.0-rc.1", latest: "2.0.0"},
+		{constraint: "^3.0.0", latest: "2.0.0"},
+		{constraint: "not a constraint", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			wanted, latest, err := ResolveVersions(versions, tt.constraint)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := versionString(wanted); got != tt.wanted {
+				t.Errorf("expected wanted version %q, got %q", tt.wanted, got)
+			}
+			if got := versionString(latest); got != tt.latest {
+				t.Errorf("expected latest version %q, got %q", tt.latest, got)
+			}
+		})
+	}
+}
+
+func TestOCIReference(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "oci://example.com/plugins/diff", want: "oci://example.com/plugins/diff:1.2.0"},
+		{source: "oci://example.com/plugins/diff:1.0.0", want: "oci://example.com/plugins/diff:1.2.0"},
+		{source: "oci://localhost:5000/diff:1.0.0", want: "oci://localhost:5000/diff:1.2.0"},
+		{source: "oci://localhost:5000/diff@sha256:abc", want: "oci://localhost:5000/diff:1.2.0"},
+	}
+	for _, tt := range tests {
+		if got := OCIReference(tt.source, "1.2.0"); got != tt.want {
+			t.Errorf("OCIReference(%q): expected %q, got %q", tt.source, tt.want, got)
+		}
+	}
+}
+
+func TestAvailableVersionsUnsupported(t *testing.T) {
+	for _, source := range []string{t.TempDir(), "https://example.com/plugin.tgz"} {
+		if _, err := AvailableVersions(source, nil); !errors.Is(err, ErrVersionsUnsupported) {
+			t.Errorf("%s: expected ErrVersionsUnsupported, got %v", source, err)
+		}
+	}
+}
+
+func versionString(v *semver.Version) string {
+	if v == nil {
+		return ""
+	}
+	return v.Original()
+}
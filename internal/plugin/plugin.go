@@ -19,6 +19,8 @@ import (
 	"context"
 	"io"
 	"regexp"
+
+	"helm.sh/helm/v4/internal/plugin/schema"
 )
 
 const PluginFileName = "plugin.yaml"
@@ -49,6 +51,12 @@ type PluginHook interface { //nolint:revive
 	InvokeHook(event string) error
 }
 
+// ReleaseHook allows plugins to implement hooks that are invoked on release lifecycle events (pre-install, post-upgrade, etc)
+type ReleaseHook interface {
+	// InvokeReleaseHook runs the hook of the plugin for the event, if any, passing the input as JSON on stdin
+	InvokeReleaseHook(ctx context.Context, input *schema.InputMessageReleaseHookV1) error
+}
+
 // Input defines the input message and parameters to be passed to the plugin
 type Input struct {
 	// Message represents the type-elided value to be passed to the plugin.
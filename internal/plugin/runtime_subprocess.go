@@ -18,6 +18,7 @@ package plugin
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
@@ -133,6 +134,26 @@ func (r *SubprocessPluginRuntime) InvokeWithEnv(main string, argv []string, env
 }
 
 func (r *SubprocessPluginRuntime) InvokeHook(event string) error {
+	return r.invokeHook(context.Background(), event, nil, os.Stdout)
+}
+
+var _ ReleaseHook = (*SubprocessPluginRuntime)(nil)
+
+// InvokeReleaseHook runs the hook of the plugin for a release lifecycle event.
+// The hook output goes to stderr, so that it does not mix with the output of
+// the Helm command.
+func (r *SubprocessPluginRuntime) InvokeReleaseHook(ctx context.Context, input *schema.InputMessageReleaseHookV1) error {
+	if len(r.RuntimeConfig.PlatformHooks[input.Event]) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	return r.invokeHook(ctx, input.Event, bytes.NewReader(data), os.Stderr)
+}
+
+func (r *SubprocessPluginRuntime) invokeHook(ctx context.Context, event string, stdin io.Reader, stdout io.Writer) error {
 	cmds := r.RuntimeConfig.PlatformHooks[event]
 
 	if len(cmds) == 0 {
@@ -149,9 +170,10 @@ func (r *SubprocessPluginRuntime) InvokeHook(event string) error {
 		return err
 	}
 
-	cmd := exec.CommandContext(context.Background(), main, argv...)
+	cmd := exec.CommandContext(ctx, main, argv...)
 	cmd.Env = FormatEnv(env)
-	cmd.Stdout = os.Stdout
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 
 	slog.Debug("executing plugin hook command", slog.String("pluginName", r.metadata.Name), slog.String("command", cmd.String()))
@@ -25,6 +25,23 @@ const (
 	Update = "update"
 )
 
+// Types of release hooks. They are executed by the Helm CLI around release
+// lifecycle events, with the context of the event as JSON on stdin.
+const (
+	// PreInstall is executed before a release is installed.
+	PreInstall = "pre-install"
+	// PostInstall is executed after a release is installed.
+	PostInstall = "post-install"
+	// PreUpgrade is executed before a release is upgraded.
+	PreUpgrade = "pre-upgrade"
+	// PostUpgrade is executed after a release is upgraded.
+	PostUpgrade = "post-upgrade"
+	// PreUninstall is executed before a release is uninstalled.
+	PreUninstall = "pre-uninstall"
+	// PostUninstall is executed after a release is uninstalled.
+	PostUninstall = "post-uninstall"
+)
+
 // PlatformHooks is a map of events to a command for a particular operating system and architecture.
 type PlatformHooks map[string][]PlatformCommand
 
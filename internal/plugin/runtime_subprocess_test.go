@@ -84,3 +84,37 @@ func TestSubprocessPluginRuntime(t *testing.T) {
 
 	assert.Nil(t, output)
 }
+
+func TestSubprocessPluginRuntimeInvokeReleaseHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event.json")
+	p := &SubprocessPluginRuntime{
+		metadata:  Metadata{Name: "notify", Type: "cli/v1", Runtime: "subprocess"},
+		pluginDir: t.TempDir(),
+		RuntimeConfig: RuntimeConfigSubprocess{
+			PlatformHooks: PlatformHooks{
+				PostInstall: []PlatformCommand{{Command: "sh", Args: []string{"-c", "cat > " + out}}},
+				PreUpgrade:  []PlatformCommand{{Command: "sh", Args: []string{"-c", "exit 3"}}},
+			},
+		},
+	}
+
+	input := &schema.InputMessageReleaseHookV1{
+		Event:   PostInstall,
+		Release: schema.ReleaseHookReleaseV1{Name: "myrelease", Namespace: "default", Revision: 1, Status: "deployed"},
+		Chart:   &schema.ReleaseHookChartV1{Name: "mychart", Version: "0.1.0"},
+	}
+	require.NoError(t, p.InvokeReleaseHook(t.Context(), input))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"event": "post-install",
+		"release": {"name": "myrelease", "namespace": "default", "revision": 1, "status": "deployed"},
+		"chart": {"name": "mychart", "version": "0.1.0"}
+	}`, string(data))
+
+	// Events without a hook are a no-op.
+	require.NoError(t, p.InvokeReleaseHook(t.Context(), &schema.InputMessageReleaseHookV1{Event: PreInstall}))
+
+	err = p.InvokeReleaseHook(t.Context(), &schema.InputMessageReleaseHookV1{Event: PreUpgrade})
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+// InputMessageReleaseHookV1 is the context of a release lifecycle event,
+// passed as JSON on the stdin of the plugin hooks registered for the event
+type InputMessageReleaseHookV1 struct {
+	// Event is the release lifecycle event, such as "pre-install"
+	Event   string               `json:"event"`
+	Release ReleaseHookReleaseV1 `json:"release"`
+	// Chart is unset when the chart is not known before the event, such as
+	// for pre-uninstall hooks
+	Chart  *ReleaseHookChartV1 `json:"chart,omitempty"`
+	DryRun bool                `json:"dryRun,omitempty"`
+}
+
+// ReleaseHookReleaseV1 describes the release of a release lifecycle event.
+// Revision and status are unset for pre hooks.
+type ReleaseHookReleaseV1 struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int    `json:"revision,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+// ReleaseHookChartV1 describes the chart of a release lifecycle event
+type ReleaseHookChartV1 struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	AppVersion string `json:"appVersion,omitempty"`
+}
@@ -51,6 +51,9 @@ type Release struct {
 	// ApplyMethod stores whether server-side or client-side apply was used for the release
 	// Unset (empty string) should be treated as the default of client-side apply
 	ApplyMethod string `json:"apply_method,omitempty"` // "ssa" | "csa"
+	// ChartSource records where the chart was installed from. It is nil for
+	// releases created by older versions of Helm or from in-memory charts.
+	ChartSource *common.ChartSource `json:"chart_source,omitempty"`
 }
 
 // SetStatus is a helper for setting the status on a release.
@@ -26,19 +26,77 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
+// Policy controls which symbolic links Walk follows.
+type Policy int
+
+const (
+	// FollowAll follows every symbolic link.
+	FollowAll Policy = iota
+	// FollowWithinRoot follows symbolic links that resolve to a path inside
+	// the walked root and rejects the others.
+	FollowWithinRoot
+	// Deny rejects every symbolic link.
+	Deny
+)
+
+// String returns the name of the policy.
+func (p Policy) String() string {
+	switch p {
+	case FollowAll:
+		return "follow-all"
+	case FollowWithinRoot:
+		return "follow-within-root"
+	case Deny:
+		return "deny"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// LinkError is returned when a symbolic link is rejected by the policy.
+type LinkError struct {
+	// Path is the path of the link relative to the walked root.
+	Path string
+	// Target is the path the link resolves to.
+	Target string
+	Policy Policy
+}
+
+func (e *LinkError) Error() string {
+	if e.Policy == FollowWithinRoot {
+		return fmt.Sprintf("symbolic link %q resolves to %q, outside of the chart directory", e.Path, e.Target)
+	}
+	return fmt.Sprintf("symbolic link %q to %q is not allowed", e.Path, e.Target)
+}
+
 // Walk walks the file tree rooted at root, calling walkFn for each file or directory
 // in the tree, including root. All errors that arise visiting files and directories
 // are filtered by walkFn. The files are walked in lexical order, which makes the
 // output deterministic but means that for very large directories Walk can be
 // inefficient. Walk follows symbolic links.
 func Walk(root string, walkFn filepath.WalkFunc) error {
+	return WalkWithPolicy(root, FollowAll, walkFn)
+}
+
+// WalkWithPolicy is like Walk, but only follows the symbolic links allowed by
+// policy. A link that is not allowed stops the walk with a *LinkError.
+func WalkWithPolicy(root string, policy Policy, walkFn filepath.WalkFunc) error {
+	w := &walker{root: root, policy: policy, walkFn: walkFn}
+	if policy == FollowWithinRoot {
+		resolved, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			return err
+		}
+		w.resolvedRoot = resolved
+	}
+
 	info, err := os.Lstat(root)
 	if err != nil {
 		err = walkFn(root, nil, err)
 	} else {
-		err = symwalk(root, info, walkFn)
+		err = w.symwalk(root, info)
 	}
 	if err == filepath.SkipDir {
 		return nil
@@ -46,6 +104,32 @@ func Walk(root string, walkFn filepath.WalkFunc) error {
 	return err
 }
 
+type walker struct {
+	root         string
+	resolvedRoot string
+	policy       Policy
+	walkFn       filepath.WalkFunc
+}
+
+// checkLink returns an error when the policy does not allow following the
+// symbolic link at path to resolved.
+func (w *walker) checkLink(path, resolved string) error {
+	switch w.policy {
+	case FollowAll:
+		return nil
+	case FollowWithinRoot:
+		rel, err := filepath.Rel(w.resolvedRoot, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	name, err := filepath.Rel(w.root, path)
+	if err != nil {
+		name = path
+	}
+	return &LinkError{Path: filepath.ToSlash(name), Target: resolved, Policy: w.policy}
+}
+
 // readDirNames reads the directory named by dirname and returns
 // a sorted list of directory entries.
 func readDirNames(dirname string) ([]string, error) {
@@ -63,19 +147,27 @@ func readDirNames(dirname string) ([]string, error) {
 }
 
 // symwalk recursively descends path, calling walkFn.
-func symwalk(path string, info os.FileInfo, walkFn filepath.WalkFunc) error {
+func (w *walker) symwalk(path string, info os.FileInfo) error {
+	walkFn := w.walkFn
 	// Recursively walk symlinked directories.
 	if IsSymlink(info) {
+		if w.policy == Deny {
+			target, _ := os.Readlink(path)
+			return w.checkLink(path, target)
+		}
 		resolved, err := filepath.EvalSymlinks(path)
 		if err != nil {
 			return fmt.Errorf("error evaluating symlink %s: %w", path, err)
 		}
+		if err := w.checkLink(path, resolved); err != nil {
+			return err
+		}
 		// This log message is to highlight a symlink that is being used within a chart, symlinks can be used for nefarious reasons.
 		slog.Info("found symbolic link in path. Contents of linked file included and used", "path", path, "resolved", resolved)
 		if info, err = os.Lstat(resolved); err != nil {
 			return err
 		}
-		if err := symwalk(path, info, walkFn); err != nil && err != filepath.SkipDir {
+		if err := w.symwalk(path, info); err != nil && err != filepath.SkipDir {
 			return err
 		}
 		return nil
@@ -102,7 +194,7 @@ func symwalk(path string, info os.FileInfo, walkFn filepath.WalkFunc) error {
 				return err
 			}
 		} else {
-			err = symwalk(filename, fileInfo, walkFn)
+			err = w.symwalk(filename, fileInfo)
 			if err != nil {
 				if (!fileInfo.IsDir() && !IsSymlink(fileInfo)) || err != filepath.SkipDir {
 					return err
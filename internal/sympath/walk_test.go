@@ -140,3 +140,47 @@ func TestWalk(t *testing.T) {
 	// cleanup
 	assert.NoError(t, os.RemoveAll(tree.name), "removeTree")
 }
+
+func TestWalkWithPolicy(t *testing.T) {
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("x"), 0644))
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "file"), []byte("x"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(root, "dir"), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(root, "file"), filepath.Join(root, "dir", "inside")))
+
+	walk := func(policy Policy) ([]string, error) {
+		var visited []string
+		err := WalkWithPolicy(root, policy, func(path string, _ os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(root, path)
+			visited = append(visited, filepath.ToSlash(rel))
+			return nil
+		})
+		return visited, err
+	}
+
+	visited, err := walk(FollowWithinRoot)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".", "dir", "dir/inside", "file"}, visited)
+
+	_, err = walk(Deny)
+	var linkErr *LinkError
+	require.ErrorAs(t, err, &linkErr)
+	assert.Equal(t, "dir/inside", linkErr.Path)
+	assert.Equal(t, Deny, linkErr.Policy)
+
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret"), filepath.Join(root, "dir", "outside")))
+	_, err = walk(FollowWithinRoot)
+	require.ErrorAs(t, err, &linkErr)
+	assert.Equal(t, "dir/outside", linkErr.Path)
+	assert.Contains(t, err.Error(), `symbolic link "dir/outside" resolves to`)
+	assert.Contains(t, err.Error(), "outside of the chart directory")
+
+	visited, err = walk(FollowAll)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".", "dir", "dir/inside", "dir/outside", "file"}, visited)
+}
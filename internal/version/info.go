@@ -0,0 +1,73 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+
+	"helm.sh/helm/v4/internal/gates"
+)
+
+// features are the features of this version of Helm that tools may gate on.
+// A feature is added with the change that implements it, and is never
+// renamed.
+var features = []string{
+	"apply",
+	"config-file",
+	"cross-namespace-guard",
+	"env-json",
+	"oci",
+	"provenance-v2",
+	"release-needs",
+	"server-side-apply",
+	"shared-cluster-scoped",
+}
+
+// Info describes this version of Helm and what it supports, for tools to
+// gate features on.
+type Info struct {
+	BuildInfo
+	// SemVer is the version of Helm as a full semantic version.
+	SemVer string `json:"semver"`
+	// ChartAPIVersions are the apiVersions of the charts Helm supports.
+	ChartAPIVersions []string `json:"chart_api_versions"`
+	// Features are the features of Helm that tools may gate on.
+	Features []string `json:"features"`
+}
+
+// GetInfo returns the build information of Helm and what it supports.
+func GetInfo() Info {
+	info := Info{
+		BuildInfo:        Get(),
+		ChartAPIVersions: []string{"v1", "v2"},
+		Features:         slices.Clone(features),
+	}
+	if v, err := semver.NewVersion(GetVersion()); err == nil {
+		info.SemVer = v.String()
+	}
+	if gates.ChartV3.IsEnabled() {
+		info.ChartAPIVersions = append(info.ChartAPIVersions, "v3")
+	}
+	return info
+}
+
+// Supports reports whether Helm supports a feature.
+func (i Info) Supports(feature string) bool {
+	return slices.Contains(i.Features, feature)
+}
@@ -29,6 +29,7 @@ import (
 	"path/filepath"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
@@ -48,6 +49,7 @@ import (
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/notify"
 	"helm.sh/helm/v4/pkg/postrenderer"
 	"helm.sh/helm/v4/pkg/registry"
 	ri "helm.sh/helm/v4/pkg/release"
@@ -133,12 +135,36 @@ type Configuration struct {
 	// Capabilities describes the capabilities of the Kubernetes cluster.
 	Capabilities *common.Capabilities
 
+	// ClusterFeatures overrides the features of the cluster exposed as
+	// Capabilities.Features when set, rather than detecting them. It also
+	// applies when rendering without a cluster.
+	ClusterFeatures *common.ClusterFeatures
+
 	// CustomTemplateFuncs is defined by users to provide custom template funcs
 	CustomTemplateFuncs template.FuncMap
 
+	// TemplateFuncPolicy restricts the template functions that charts may
+	// call when set, such as engine.UntrustedFuncPolicy() for untrusted charts.
+	TemplateFuncPolicy *engine.FuncPolicy
+
 	// HookOutputFunc called with container name and returns and expects writer that will receive the log output.
 	HookOutputFunc func(namespace, pod, container string) io.Writer
 
+	// Metrics collects metrics about the operations when set.
+	Metrics *Metrics
+
+	// Notifier is notified of the completed operations when set.
+	Notifier *notify.Notifier
+
+	// Warnings collects the warnings raised by the actions when set, such as
+	// the use of deprecated charts. Otherwise, the warnings are logged.
+	Warnings *Warnings
+
+	// ForNamespace returns the configuration of another namespace when set.
+	// It is used to look up the releases of other namespaces, such as the
+	// releases needed by a release.
+	ForNamespace func(namespace string) (*Configuration, error)
+
 	// Mutex is an exclusive lock for concurrent access to the action
 	mutex sync.Mutex
 
@@ -270,6 +296,60 @@ func splitAndDeannotate(postrendered, fallbackPrefix string) (map[string]string,
 	return reconstructed, nil
 }
 
+// extractNotes removes the NOTES.txt files from the rendered files and
+// returns the notes of the chart, along with those of its subcharts when
+// subNotes is set.
+func extractNotes(files map[string]string, chartName string, subNotes bool) string {
+	var notesBuffer bytes.Buffer
+	for k, v := range files {
+		if strings.HasSuffix(k, notesFileSuffix) {
+			if subNotes || (k == path.Join(chartName, "templates", notesFileSuffix)) {
+				// If buffer contains data, add newline before adding more
+				if notesBuffer.Len() > 0 {
+					notesBuffer.WriteString("\n")
+				}
+				notesBuffer.WriteString(v)
+			}
+			delete(files, k)
+		}
+	}
+	return notesBuffer.String()
+}
+
+// renderNotes renders the notes of a chart, as renderResources does, without
+// rendering its other templates. Only the partials are kept for the notes to
+// include.
+func (cfg *Configuration) renderNotes(ctx context.Context, ch *chart.Chart, values common.Values, subNotes, interactWithRemote, enableDNS bool) (string, error) {
+	e, err := cfg.newEngine(interactWithRemote, enableDNS)
+	if err != nil {
+		return "", err
+	}
+	files, err := e.RenderWithContext(ctx, notesChart(ch), values)
+	if err != nil {
+		return "", err
+	}
+	return extractNotes(files, ch.Name(), subNotes), nil
+}
+
+// notesChart returns a copy of a chart and of its dependencies that only
+// holds their notes and partials.
+func notesChart(ch *chart.Chart) *chart.Chart {
+	c := *ch
+	c.Templates = slices.DeleteFunc(slices.Clone(ch.Templates), func(t *common.File) bool {
+		if t == nil {
+			return true
+		}
+		base := path.Base(t.Name)
+		return base != notesFileSuffix && !strings.HasPrefix(base, "_")
+	})
+	deps := make([]*chart.Chart, 0, len(ch.Dependencies()))
+	for _, dep := range ch.Dependencies() {
+		deps = append(deps, notesChart(dep))
+	}
+	c.SetDependencies(deps...)
+	return &c
+}
+
 // renderResources renders the templates in a chart
 //
 // TODO: This function is badly in need of a refactor.
@@ -290,33 +370,17 @@ func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart,
 			return hs, b, "", fmt.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", ch.Metadata.KubeVersion, caps.KubeVersion.Version)
 		}
 	}
-
-	var files map[string]string
-	var err2 error
-
-	// A `helm template` should not talk to the remote cluster. However, commands with the flag
-	// `--dry-run` with the value of `false`, `none`, or `server` should try to interact with the cluster.
-	// It may break in interesting and exotic ways because other data (e.g. discovery) is mocked.
-	if interactWithRemote && cfg.RESTClientGetter != nil {
-		restConfig, err := cfg.RESTClientGetter.ToRESTConfig()
-		if err != nil {
-			return hs, b, "", err
-		}
-		e := engine.New(restConfig)
-		e.EnableDNS = enableDNS
-		e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
-
-		files, err2 = e.RenderWithContext(ctx, ch, values)
-	} else {
-		var e engine.Engine
-		e.EnableDNS = enableDNS
-		e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
-
-		files, err2 = e.RenderWithContext(ctx, ch, values)
+	if err := checkRequiredCRDs(ch, caps.Features); err != nil {
+		return hs, b, "", err
 	}
 
-	if err2 != nil {
-		return hs, b, "", err2
+	e, err := cfg.newEngine(interactWithRemote, enableDNS)
+	if err != nil {
+		return hs, b, "", err
+	}
+	files, err := e.RenderWithContext(ctx, ch, values)
+	if err != nil {
+		return hs, b, "", err
 	}
 
 	// NOTES.txt gets rendered like all the other files, but because it's not a hook nor a resource,
@@ -324,20 +388,7 @@ func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart,
 	// text file. We have to spin through this map because the file contains path information, so we
 	// look for terminating NOTES.txt. We also remove it from the files so that we don't have to skip
 	// it in the sortHooks.
-	var notesBuffer bytes.Buffer
-	for k, v := range files {
-		if strings.HasSuffix(k, notesFileSuffix) {
-			if subNotes || (k == path.Join(ch.Name(), "templates", notesFileSuffix)) {
-				// If buffer contains data, add newline before adding more
-				if notesBuffer.Len() > 0 {
-					notesBuffer.WriteString("\n")
-				}
-				notesBuffer.WriteString(v)
-			}
-			delete(files, k)
-		}
-	}
-	notes := notesBuffer.String()
+	notes := extractNotes(files, ch.Name(), subNotes)
 
 	if pr != nil {
 		switch postRenderStrategy {
@@ -475,6 +526,9 @@ func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart,
 			}
 			fmt.Fprintf(b, "---\n# Source: %s\n%s\n", name, content)
 		}
+		if pr == nil {
+			err = locateManifestError(ctx, e, ch, values, files, err)
+		}
 		return hs, b, "", err
 	}
 
@@ -567,6 +621,7 @@ func (cfg *Configuration) getCapabilities() (*common.Capabilities, error) {
 			Minor:   kubeVersion.Minor,
 		},
 		HelmVersion: common.DefaultCapabilities.HelmVersion,
+		Features:    cfg.clusterFeatures(),
 	}
 	return cfg.Capabilities, nil
 }
@@ -660,62 +715,118 @@ func (cfg *Configuration) recordRelease(r *release.Release) {
 	}
 }
 
+// setDeployStats records in the release info the number of resources and
+// images of its manifest, and how long its deployment took since start.
+func setDeployStats(rel *release.Release, start time.Time) {
+	rel.Info.ResourceCount = len(releaseutil.SplitManifests(rel.Manifest))
+	rel.Info.ImageCount = len(releaseutil.ManifestImages(rel.Manifest))
+	rel.Info.DeployDuration = time.Since(start)
+}
+
+// addPhaseTime adds the time elapsed since start to the duration of a phase of
+// a deployment, recorded in the release info.
+func addPhaseTime(phase *time.Duration, start time.Time) {
+	*phase += time.Since(start)
+}
+
+// retentionFor returns the retention recorded on a new revision: the one
+// requested by the operation if any, else the one of the previous revision.
+func retentionFor(requested *release.Retention, previous *release.Release) *release.Retention {
+	if requested != nil {
+		return requested
+	}
+	if previous != nil {
+		return previous.Retention
+	}
+	return nil
+}
+
 // Init initializes the action configuration
 func (cfg *Configuration) Init(getter genericclioptions.RESTClientGetter, namespace, helmDriver string) error {
 	kc := kube.New(getter)
 	kc.SetLogger(cfg.Logger().Handler())
 
+	d, err := cfg.newStorageDriver(kc.Factory.KubernetesClientSet, namespace, helmDriver)
+	if err != nil {
+		return err
+	}
+	store := storage.Init(d)
+
+	keyProvider, err := storage.KeyProviderFromEnv()
+	if err != nil {
+		return fmt.Errorf("unable to configure the encryption of sensitive values: %w", err)
+	}
+	store.KeyProvider = keyProvider
+	if v := os.Getenv("HELM_STORAGE_DELTAS"); v != "" {
+		if store.ManifestDeltas, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid HELM_STORAGE_DELTAS: %w", err)
+		}
+	}
+
+	cfg.RESTClientGetter = getter
+	cfg.KubeClient = kc
+	cfg.Releases = store
+	cfg.HookOutputFunc = func(_, _, _ string) io.Writer { return io.Discard }
+
+	return nil
+}
+
+// newStorageDriver creates the storage driver named helmDriver for the
+// namespace, encrypting the records as configured by the environment.
+func (cfg *Configuration) newStorageDriver(clientFn func() (*kubernetes.Clientset, error), namespace, helmDriver string) (driver.Driver, error) {
 	lazyClient := &lazyClient{
 		namespace: namespace,
-		clientFn:  kc.Factory.KubernetesClientSet,
+		clientFn:  clientFn,
 	}
 
-	var store *storage.Storage
+	var d driver.Driver
 	switch helmDriver {
 	case "secret", "secrets", "":
-		d := driver.NewSecrets(newSecretClient(lazyClient))
-		d.SetLogger(cfg.Logger().Handler())
-		store = storage.Init(d)
+		sd := driver.NewSecrets(newSecretClient(lazyClient))
+		sd.SetLogger(cfg.Logger().Handler())
+		d = sd
 	case "configmap", "configmaps":
-		d := driver.NewConfigMaps(newConfigMapClient(lazyClient))
-		d.SetLogger(cfg.Logger().Handler())
-		store = storage.Init(d)
+		cd := driver.NewConfigMaps(newConfigMapClient(lazyClient))
+		cd.SetLogger(cfg.Logger().Handler())
+		d = cd
 	case "memory":
-		var d *driver.Memory
+		var md *driver.Memory
 		if cfg.Releases != nil {
 			if mem, ok := cfg.Releases.Driver.(*driver.Memory); ok {
 				// This function can be called more than once (e.g., helm list --all-namespaces).
 				// If a memory driver was already initialized, reuse it but set the possibly new namespace.
 				// We reuse it in case some releases where already created in the existing memory driver.
-				d = mem
+				md = mem
 			}
 		}
-		if d == nil {
-			d = driver.NewMemory()
+		if md == nil {
+			md = driver.NewMemory()
 		}
-		d.SetLogger(cfg.Logger().Handler())
-		d.SetNamespace(namespace)
-		store = storage.Init(d)
+		md.SetLogger(cfg.Logger().Handler())
+		md.SetNamespace(namespace)
+		d = md
 	case "sql":
-		d, err := driver.NewSQL(
+		sd, err := driver.NewSQL(
 			os.Getenv("HELM_DRIVER_SQL_CONNECTION_STRING"),
 			namespace,
 		)
 		if err != nil {
-			return fmt.Errorf("unable to instantiate SQL driver: %w", err)
+			return nil, fmt.Errorf("unable to instantiate SQL driver: %w", err)
 		}
-		d.SetLogger(cfg.Logger().Handler())
-		store = storage.Init(d)
+		sd.SetLogger(cfg.Logger().Handler())
+		d = sd
 	default:
-		return fmt.Errorf("unknown driver %q", helmDriver)
+		return nil, fmt.Errorf("unknown driver %q", helmDriver)
 	}
 
-	cfg.RESTClientGetter = getter
-	cfg.KubeClient = kc
-	cfg.Releases = store
-	cfg.HookOutputFunc = func(_, _, _ string) io.Writer { return io.Discard }
-
-	return nil
+	if es, ok := d.(driver.EncryptionSetter); ok {
+		p, err := driver.EncryptionProviderFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure the encryption of the storage: %w", err)
+		}
+		es.SetEncryptionProvider(p)
+	}
+	return d, nil
 }
 
 // SetHookOutputFunc sets the HookOutputFunc on the Configuration.
@@ -0,0 +1,386 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	v2loader "helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/kube"
+	ri "helm.sh/helm/v4/pkg/release"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// CompositeSpec declares several releases that are reconciled together by
+// Composite.
+type CompositeSpec struct {
+	Releases []CompositeRelease `json:"releases"`
+}
+
+// CompositeRelease declares a release of a CompositeSpec.
+type CompositeRelease struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// Namespace is the namespace of the release. It defaults to the
+	// namespace of the Composite action.
+	Namespace string `json:"namespace,omitempty"`
+	// Chart is the chart reference, as given to helm install.
+	Chart string `json:"chart"`
+	// Version is the version constraint of the chart.
+	Version string `json:"version,omitempty"`
+	// ValuesFiles are values files, merged in order. Later files take
+	// precedence.
+	ValuesFiles []string `json:"valuesFiles,omitempty"`
+	// Values take precedence over the values files.
+	Values map[string]any `json:"values,omitempty"`
+	// Needs lists the releases that must be reconciled before this one, by
+	// name, or by namespace/name for releases in other namespaces.
+	Needs []string `json:"needs,omitempty"`
+}
+
+// key returns the namespace/name of the release.
+func (r *CompositeRelease) key() string {
+	return r.Namespace + "/" + r.Name
+}
+
+// LoadCompositeSpec reads a CompositeSpec from a file. Relative paths of local
+// charts and values files are resolved from the directory of the file.
+func LoadCompositeSpec(filename string) (*CompositeSpec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	spec := &CompositeSpec{}
+	if err := yaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", filename, err)
+	}
+
+	dir := filepath.Dir(filename)
+	resolve := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	for i := range spec.Releases {
+		r := &spec.Releases[i]
+		if strings.HasPrefix(r.Chart, "./") || strings.HasPrefix(r.Chart, "../") {
+			r.Chart = resolve(r.Chart)
+		}
+		for j, f := range r.ValuesFiles {
+			r.ValuesFiles[j] = resolve(f)
+		}
+	}
+	return spec, nil
+}
+
+// CompositeStatus is the result of the reconciliation of a release.
+type CompositeStatus string
+
+const (
+	// CompositeInstalled is the status of a release that was installed.
+	CompositeInstalled CompositeStatus = "installed"
+	// CompositeUpgraded is the status of a release that was upgraded.
+	CompositeUpgraded CompositeStatus = "upgraded"
+	// CompositeFailed is the status of a release that failed to reconcile.
+	CompositeFailed CompositeStatus = "failed"
+	// CompositeSkipped is the status of a release that was not reconciled
+	// because a release it needs failed.
+	CompositeSkipped CompositeStatus = "skipped"
+)
+
+// CompositeResult is the result of the reconciliation of a release.
+type CompositeResult struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Status    CompositeStatus `json:"status"`
+	// Revision is the revision of the release after the reconciliation.
+	Revision int `json:"revision,omitempty"`
+	// Error explains why the release failed or was skipped.
+	Error string `json:"error,omitempty"`
+	// Release is the reconciled release.
+	Release *release.Release `json:"-"`
+}
+
+// Composite is the action for reconciling several releases declared in a
+// CompositeSpec.
+//
+// Each release is installed if it does not exist and upgraded otherwise. The
+// releases are reconciled one at a time, every release after the releases it
+// needs. When a release fails, the releases that need it are skipped, and the
+// others are still reconciled.
+type Composite struct {
+	cfg *Configuration
+
+	ChartPathOptions
+
+	// Namespace is the namespace of the releases that do not declare one.
+	Namespace string
+	// Settings are used to locate the charts.
+	Settings *cli.EnvSettings
+	// Configurations returns the configuration of the releases of another
+	// namespace than Namespace. It defaults to the ForNamespace function of
+	// the configuration of the action.
+	Configurations func(namespace string) (*Configuration, error)
+	// Load returns the chart and values of a release. By default, the chart
+	// is located with ChartPathOptions and the values are read from the
+	// values files of the release.
+	Load func(r *CompositeRelease) (ci.Charter, map[string]any, error)
+
+	// The options below apply to the install or upgrade of every release.
+	DryRunStrategy  DryRunStrategy
+	WaitStrategy    kube.WaitStrategy
+	WaitForJobs     bool
+	Timeout         time.Duration
+	CreateNamespace bool
+}
+
+// NewComposite creates a new Composite object with the given configuration.
+func NewComposite(cfg *Configuration) *Composite {
+	c := &Composite{
+		cfg:            cfg,
+		DryRunStrategy: DryRunNone,
+	}
+	c.registryClient = cfg.RegistryClient
+	return c
+}
+
+// Run reconciles the releases of the spec, and returns the result of every
+// release in the order they were reconciled. The error summarizes the
+// releases that failed or were skipped.
+func (c *Composite) Run(ctx context.Context, spec *CompositeSpec) ([]CompositeResult, error) {
+	releases, err := c.order(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []CompositeResult
+	var errs []error
+	failed := map[string]bool{}
+	for _, r := range releases {
+		res := CompositeResult{Name: r.Name, Namespace: r.Namespace}
+		for _, need := range r.Needs {
+			if failed[need] {
+				res.Status = CompositeSkipped
+				res.Error = fmt.Sprintf("release %s, which is needed, was not reconciled", need)
+				break
+			}
+		}
+		if res.Status == "" {
+			if res.Status, res.Release, err = c.reconcile(ctx, r); err != nil {
+				res.Status = CompositeFailed
+				res.Error = err.Error()
+			}
+		}
+		if res.Release != nil {
+			res.Revision = res.Release.Version
+		}
+		if res.Error != "" {
+			failed[r.key()] = true
+			errs = append(errs, fmt.Errorf("release %s %s: %s", r.key(), res.Status, res.Error))
+		}
+		results = append(results, res)
+	}
+	if len(errs) > 0 {
+		return results, fmt.Errorf("%d of %d releases were not reconciled: %w", len(errs), len(results), errors.Join(errs...))
+	}
+	return results, nil
+}
+
+// order returns the releases of the spec with their default namespace and
+// fully qualified needs, each release after the releases it needs. Releases
+// are otherwise kept in the order of the spec.
+func (c *Composite) order(spec *CompositeSpec) ([]*CompositeRelease, error) {
+	byKey := map[string]*CompositeRelease{}
+	var releases []*CompositeRelease
+	for i := range spec.Releases {
+		r := spec.Releases[i]
+		if r.Name == "" || r.Chart == "" {
+			return nil, fmt.Errorf("release %d of the spec must have a name and a chart", i+1)
+		}
+		if r.Namespace == "" {
+			r.Namespace = c.Namespace
+		}
+		if _, ok := byKey[r.key()]; ok {
+			return nil, fmt.Errorf("release %s is declared more than once", r.key())
+		}
+		byKey[r.key()] = &r
+		releases = append(releases, &r)
+	}
+	for _, r := range releases {
+		r.Needs = qualifyNeeds(r.Needs, r.Namespace)
+		for _, need := range r.Needs {
+			if _, ok := byKey[need]; !ok {
+				return nil, fmt.Errorf("release %s needs release %s, which is not declared", r.key(), need)
+			}
+		}
+	}
+
+	var ordered []*CompositeRelease
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(r *CompositeRelease, path []string) error
+	visit = func(r *CompositeRelease, path []string) error {
+		switch state[r.key()] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("releases need each other: %s", strings.Join(append(path, r.key()), " -> "))
+		}
+		state[r.key()] = visiting
+		for _, need := range r.Needs {
+			if err := visit(byKey[need], append(path, r.key())); err != nil {
+				return err
+			}
+		}
+		state[r.key()] = visited
+		ordered = append(ordered, r)
+		return nil
+	}
+	for _, r := range releases {
+		if err := visit(r, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// reconcile installs or upgrades a release.
+func (c *Composite) reconcile(ctx context.Context, r *CompositeRelease) (CompositeStatus, *release.Release, error) {
+	cfg := c.cfg
+	if r.Namespace != c.Namespace {
+		configurations := c.Configurations
+		if configurations == nil {
+			configurations = c.cfg.ForNamespace
+		}
+		if configurations == nil {
+			return "", nil, fmt.Errorf("releases cannot be reconciled in namespace %s", r.Namespace)
+		}
+		var err error
+		if cfg, err = configurations(r.Namespace); err != nil {
+			return "", nil, err
+		}
+	}
+
+	ch, vals, err := c.load(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	history := NewHistory(cfg)
+	history.Max = 1
+	versions, err := history.Run(r.Name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return "", nil, err
+	}
+
+	var rel ri.Releaser
+	status := CompositeUpgraded
+	if uninstalled := isUninstalled(versions); err != nil || uninstalled {
+		status = CompositeInstalled
+		install := NewInstall(cfg)
+		install.ReleaseName = r.Name
+		install.Namespace = r.Namespace
+		install.Replace = uninstalled
+		install.DryRunStrategy = c.DryRunStrategy
+		install.WaitStrategy = c.WaitStrategy
+		install.WaitForJobs = c.WaitForJobs
+		install.Timeout = c.Timeout
+		install.CreateNamespace = c.CreateNamespace
+		install.Needs = r.Needs
+		rel, err = install.RunWithContext(ctx, ch, vals)
+	} else {
+		upgrade := NewUpgrade(cfg)
+		upgrade.Namespace = r.Namespace
+		upgrade.DryRunStrategy = c.DryRunStrategy
+		upgrade.WaitStrategy = c.WaitStrategy
+		upgrade.WaitForJobs = c.WaitForJobs
+		upgrade.Timeout = c.Timeout
+		// The needs of the spec replace the recorded ones, even when empty.
+		upgrade.Needs = append([]string{}, r.Needs...)
+		rel, err = upgrade.RunWithContext(ctx, r.Name, ch, vals)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	v1rel, err := releaserToV1Release(rel)
+	return status, v1rel, err
+}
+
+// isUninstalled reports whether the last revision of a release was
+// uninstalled with its history kept.
+func isUninstalled(versions []ri.Releaser) bool {
+	if len(versions) == 0 {
+		return false
+	}
+	last, err := releaserToV1Release(versions[len(versions)-1])
+	return err == nil && last.Info.Status == rcommon.StatusUninstalled
+}
+
+// load returns the chart and values of a release.
+func (c *Composite) load(r *CompositeRelease) (ci.Charter, map[string]any, error) {
+	if c.Load != nil {
+		return c.Load(r)
+	}
+
+	opts := c.ChartPathOptions
+	opts.Version = r.Version
+	path, err := opts.LocateChart(r.Chart, c.Settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch, err := loader.Load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	ac, err := ci.NewAccessor(ch)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req := ac.MetaDependencies(); len(req) > 0 {
+		if err := CheckDependencies(ch, req); err != nil {
+			return nil, nil, fmt.Errorf("an error occurred while checking for chart dependencies. You may need to run 'helm dependency build' to fetch missing dependencies: %w", err)
+		}
+	}
+
+	vals := map[string]any{}
+	for _, f := range r.ValuesFiles {
+		fileVals, err := common.ReadValuesFile(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", f, err)
+		}
+		vals = v2loader.MergeMaps(vals, fileVals)
+	}
+	return ch, v2loader.MergeMaps(vals, r.Values), nil
+}
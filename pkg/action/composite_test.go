@@ -0,0 +1,162 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+)
+
+func compositeAction(t *testing.T) *Composite {
+	t.Helper()
+	configs := map[string]*Configuration{}
+	cfg := actionConfigFixture(t)
+	cfg.ForNamespace = func(namespace string) (*Configuration, error) {
+		if configs[namespace] == nil {
+			configs[namespace] = actionConfigFixture(t)
+		}
+		return configs[namespace], nil
+	}
+	c := NewComposite(cfg)
+	c.Namespace = "default"
+	c.Load = func(r *CompositeRelease) (ci.Charter, map[string]any, error) {
+		if r.Chart == "broken" {
+			return nil, nil, errors.New("chart not found")
+		}
+		return buildChart(), r.Values, nil
+	}
+	return c
+}
+
+func TestComposite(t *testing.T) {
+	spec := &CompositeSpec{Releases: []CompositeRelease{
+		{Name: "app", Chart: "app", Needs: []string{"data/db", "cache"}},
+		{Name: "db", Namespace: "data", Chart: "db"},
+		{Name: "cache", Chart: "cache"},
+	}}
+	c := compositeAction(t)
+
+	results, err := c.Run(t.Context(), spec)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data/db", "default/cache"}, results[2].Release.Needs)
+	assert.Equal(t, []CompositeResult{
+		{Name: "db", Namespace: "data", Status: CompositeInstalled, Revision: 1},
+		{Name: "cache", Namespace: "default", Status: CompositeInstalled, Revision: 1},
+		{Name: "app", Namespace: "default", Status: CompositeInstalled, Revision: 1},
+	}, withoutReleases(results))
+
+	results, err = c.Run(t.Context(), spec)
+	require.NoError(t, err)
+	for _, res := range results {
+		assert.Equal(t, CompositeUpgraded, res.Status, res.Name)
+		assert.Equal(t, 2, res.Revision, res.Name)
+	}
+}
+
+func TestComposite_Failure(t *testing.T) {
+	spec := &CompositeSpec{Releases: []CompositeRelease{
+		{Name: "db", Chart: "broken"},
+		{Name: "app", Chart: "app", Needs: []string{"db"}},
+		{Name: "cache", Chart: "cache"},
+	}}
+
+	results, err := compositeAction(t).Run(t.Context(), spec)
+	require.ErrorContains(t, err, "2 of 3 releases were not reconciled")
+	assert.Equal(t, []CompositeResult{
+		{Name: "db", Namespace: "default", Status: CompositeFailed, Error: "chart not found"},
+		{Name: "app", Namespace: "default", Status: CompositeSkipped, Error: "release default/db, which is needed, was not reconciled"},
+		{Name: "cache", Namespace: "default", Status: CompositeInstalled, Revision: 1},
+	}, withoutReleases(results))
+}
+
+func TestComposite_InvalidSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		releases []CompositeRelease
+		wantErr  string
+	}{
+		{
+			name:     "missing chart",
+			releases: []CompositeRelease{{Name: "app"}},
+			wantErr:  "release 1 of the spec must have a name and a chart",
+		},
+		{
+			name:     "duplicate release",
+			releases: []CompositeRelease{{Name: "app", Chart: "app"}, {Name: "app", Namespace: "default", Chart: "app"}},
+			wantErr:  "release default/app is declared more than once",
+		},
+		{
+			name:     "unknown need",
+			releases: []CompositeRelease{{Name: "app", Chart: "app", Needs: []string{"db"}}},
+			wantErr:  "release default/app needs release default/db, which is not declared",
+		},
+		{
+			name: "cycle",
+			releases: []CompositeRelease{
+				{Name: "a", Chart: "a", Needs: []string{"b"}},
+				{Name: "b", Chart: "b", Needs: []string{"c"}},
+				{Name: "c", Chart: "c", Needs: []string{"a"}},
+			},
+			wantErr: "releases need each other: default/a -> default/b -> default/c -> default/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compositeAction(t).Run(t.Context(), &CompositeSpec{Releases: tt.releases})
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadCompositeSpec(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "releases.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(`releases:
+- name: app
+  chart: ./charts/app
+  valuesFiles: [app.yaml, /etc/app.yaml]
+- name: db
+  chart: repo/db
+  version: 1.2.3
+`), 0o644))
+
+	spec, err := LoadCompositeSpec(filename)
+	require.NoError(t, err)
+	assert.Equal(t, []CompositeRelease{
+		{Name: "app", Chart: filepath.Join(dir, "charts/app"), ValuesFiles: []string{filepath.Join(dir, "app.yaml"), "/etc/app.yaml"}},
+		{Name: "db", Chart: "repo/db", Version: "1.2.3"},
+	}, spec.Releases)
+
+	require.NoError(t, os.WriteFile(filename, []byte("releases:\n- name: app\n  chrat: app\n"), 0o644))
+	_, err = LoadCompositeSpec(filename)
+	assert.ErrorContains(t, err, `unknown field "chrat"`)
+}
+
+func withoutReleases(results []CompositeResult) []CompositeResult {
+	for i := range results {
+		results[i].Release = nil
+	}
+	return results
+}
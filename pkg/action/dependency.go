@@ -21,11 +21,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/gosuri/uitable"
 
+	"helm.sh/helm/v4/internal/resolver"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 )
@@ -45,6 +47,7 @@ type Dependency struct {
 	CaFile                string
 	InsecureSkipTLSVerify bool
 	PlainHTTP             bool
+	Untar                 bool
 }
 
 // NewDependency creates a new Dependency object with the given configuration.
@@ -234,3 +237,155 @@ func (d *Dependency) printMissing(chartpath string, out io.Writer, reqs []*chart
 		}
 	}
 }
+
+// DependencyReport describes the health of the dependencies of a chart.
+type DependencyReport struct {
+	// Dependencies are the dependencies declared in Chart.yaml, in order.
+	Dependencies []DependencyInfo `json:"dependencies"`
+	// Missing are the names of the declared dependencies not found in charts/.
+	Missing []string `json:"missing,omitempty"`
+	// Extra are the charts in charts/ that are not declared in Chart.yaml.
+	Extra []string `json:"extra,omitempty"`
+	// LockOutOfSync is set when Chart.lock was not generated from the
+	// dependencies currently declared in Chart.yaml.
+	LockOutOfSync bool `json:"lockOutOfSync,omitempty"`
+}
+
+// DependencyInfo describes a single dependency of a chart.
+type DependencyInfo struct {
+	Name       string `json:"name"`
+	Alias      string `json:"alias,omitempty"`
+	Version    string `json:"version"`
+	Repository string `json:"repository"`
+	Status     string `json:"status"`
+	// Locked is the version of the dependency recorded in Chart.lock.
+	Locked string `json:"locked,omitempty"`
+	// Digest is the digest of the chart archive recorded in Chart.lock.
+	Digest string `json:"digest,omitempty"`
+	// Latest is the newest version in the repository satisfying Version.
+	Latest string `json:"latest,omitempty"`
+	// UpdateAvailable is set when Latest is newer than the locked version.
+	UpdateAvailable bool `json:"updateAvailable"`
+}
+
+// Report inspects the dependencies of the chart at chartpath.
+//
+// If versions is not nil, it is called with the declared dependencies and
+// must return the versions available for each of them, newest first; these
+// are used to look for updates. downloader.Manager.AvailableVersions
+// satisfies it.
+func (d *Dependency) Report(chartpath string, versions func([]*chart.Dependency) ([][]*semver.Version, error)) (*DependencyReport, error) {
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := c.Metadata.Dependencies
+	report := &DependencyReport{Dependencies: []DependencyInfo{}}
+
+	locked := map[string]*chart.Dependency{}
+	if c.Lock != nil {
+		for _, dep := range c.Lock.Dependencies {
+			locked[dep.Name] = dep
+		}
+		if len(reqs) > 0 {
+			if digest, err := resolver.HashReq(reqs, c.Lock.Dependencies); err != nil || digest != c.Lock.Digest {
+				report.LockOutOfSync = true
+			}
+		}
+	}
+
+	var available [][]*semver.Version
+	if versions != nil && len(reqs) > 0 {
+		// The callback may rewrite repository aliases, so hand it copies.
+		deps := make([]*chart.Dependency, len(reqs))
+		for i, dep := range reqs {
+			cp := *dep
+			deps[i] = &cp
+		}
+		if available, err = versions(deps); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, dep := range reqs {
+		info := DependencyInfo{
+			Name:       dep.Name,
+			Alias:      dep.Alias,
+			Version:    dep.Version,
+			Repository: dep.Repository,
+			Status:     d.dependencyStatus(chartpath, dep, c),
+		}
+		if info.Status == "missing" {
+			report.Missing = append(report.Missing, dep.Name)
+		}
+		if l, ok := locked[dep.Name]; ok {
+			info.Locked = l.Version
+			info.Digest = l.Digest
+		}
+		if i < len(available) {
+			info.Latest, info.UpdateAvailable = latestVersion(dep.Version, current(info.Locked, dep.Name, c), available[i])
+		}
+		report.Dependencies = append(report.Dependencies, info)
+	}
+
+	report.Extra = undeclaredCharts(chartpath, reqs)
+	return report, nil
+}
+
+// current returns the version a dependency is at: the locked version if there
+// is one, otherwise the version of the vendored chart.
+func current(lockedVersion, name string, parent *chart.Chart) string {
+	if lockedVersion != "" {
+		return lockedVersion
+	}
+	for _, item := range parent.Dependencies() {
+		if item.Name() == name {
+			return item.Metadata.Version
+		}
+	}
+	return ""
+}
+
+// latestVersion returns the newest of the available versions, which are
+// sorted newest first, satisfying the constraint, and whether it is newer than
+// the current version.
+func latestVersion(constraint, currentVersion string, available []*semver.Version) (string, bool) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", false
+	}
+	for _, v := range available {
+		if !c.Check(v) {
+			continue
+		}
+		cur, err := semver.NewVersion(currentVersion)
+		return v.Original(), err != nil || v.GreaterThan(cur)
+	}
+	return "", false
+}
+
+// undeclaredCharts returns the charts in the charts/ directory of chartpath
+// that are not declared in Chart.yaml.
+func undeclaredCharts(chartpath string, reqs []*chart.Dependency) []string {
+	files, err := filepath.Glob(filepath.Join(chartpath, "charts/*"))
+	if err != nil {
+		return nil
+	}
+
+	var extra []string
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil || (!fi.IsDir() && filepath.Ext(f) != ".tgz") {
+			continue
+		}
+		c, err := loader.Load(f)
+		if err != nil {
+			continue
+		}
+		if !slices.ContainsFunc(reqs, func(dep *chart.Dependency) bool { return dep.Name == c.Name() }) {
+			extra = append(extra, f)
+		}
+	}
+	return extra
+}
@@ -18,12 +18,15 @@ package action
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/assert"
 
+	"helm.sh/helm/v4/internal/resolver"
 	"helm.sh/helm/v4/internal/test"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
@@ -150,3 +153,88 @@ func TestStatArchiveForStatus(t *testing.T) {
 	}
 	is.Equal("ok", statArchiveForStatus(where, dep))
 }
+
+func TestDependencyReport(t *testing.T) {
+	dir := t.TempDir()
+	chartsdir := filepath.Join(dir, "charts")
+	if err := os.MkdirAll(chartsdir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := []*chart.Dependency{
+		{Name: "first", Version: "~1.0.0", Repository: "https://example.com/charts"},
+		{Name: "second", Version: "^2.0.0", Repository: "https://example.com/charts"},
+		{Name: "third", Version: "3.0.0", Repository: "file://../third"},
+	}
+	lock := []*chart.Dependency{
+		{Name: "first", Version: "1.0.0", Repository: "https://example.com/charts", Digest: "sha256:0123"},
+		{Name: "second", Version: "2.0.0", Repository: "https://example.com/charts", Digest: "sha256:4567"},
+	}
+	digest, err := resolver.HashReq(reqs, lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := buildChart(withName("parent"))
+	parent.Metadata.APIVersion = chart.APIVersionV2
+	parent.Metadata.Dependencies = reqs
+	if err := chartutil.SaveDir(parent, dir); err != nil {
+		t.Fatal(err)
+	}
+	chartpath := filepath.Join(dir, "parent")
+	lockfile, err := json.Marshal(&chart.Lock{Digest: digest, Dependencies: lock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// JSON is valid YAML.
+	if err := os.WriteFile(filepath.Join(chartpath, "Chart.lock"), lockfile, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*chart.Chart{
+		buildChart(withName("first"), withVersion("1.0.0")),
+		buildChart(withName("third"), withVersion("3.0.0")),
+		buildChart(withName("stray"), withVersion("0.1.0")),
+	} {
+		if _, err := chartutil.Save(c, filepath.Join(chartpath, "charts")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions := func(deps []*chart.Dependency) ([][]*semver.Version, error) {
+		assert.Len(t, deps, len(reqs))
+		return [][]*semver.Version{
+			{semver.MustParse("2.0.0"), semver.MustParse("1.0.3"), semver.MustParse("1.0.0")},
+			{semver.MustParse("2.0.0")},
+			nil,
+		}, nil
+	}
+
+	is := assert.New(t)
+
+	report, err := NewDependency().Report(chartpath, versions)
+	is.NoError(err)
+	is.Equal([]DependencyInfo{
+		{Name: "first", Version: "~1.0.0", Repository: "https://example.com/charts", Status: "ok", Locked: "1.0.0", Digest: "sha256:0123", Latest: "1.0.3", UpdateAvailable: true},
+		{Name: "second", Version: "^2.0.0", Repository: "https://example.com/charts", Status: "missing", Locked: "2.0.0", Digest: "sha256:4567", Latest: "2.0.0"},
+		{Name: "third", Version: "3.0.0", Repository: "file://../third", Status: "ok"},
+	}, report.Dependencies)
+	is.Equal([]string{"second"}, report.Missing)
+	is.Equal([]string{filepath.Join(chartpath, "charts", "stray-0.1.0.tgz")}, report.Extra)
+	is.False(report.LockOutOfSync)
+
+	// Without a version lookup, no updates are reported.
+	report, err = NewDependency().Report(chartpath, nil)
+	is.NoError(err)
+	is.Empty(report.Dependencies[0].Latest)
+	is.False(report.Dependencies[0].UpdateAvailable)
+
+	// A changed constraint puts the lock out of sync.
+	reqs[1].Version = "^2.1.0"
+	parent.Metadata.Dependencies = reqs
+	if err := chartutil.SaveDir(parent, dir); err != nil {
+		t.Fatal(err)
+	}
+	report, err = NewDependency().Report(chartpath, versions)
+	is.NoError(err)
+	is.True(report.LockOutOfSync)
+	is.Empty(report.Dependencies[1].Latest)
+}
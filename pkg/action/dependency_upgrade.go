@@ -0,0 +1,247 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.yaml.in/yaml/v3"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+// Bump policies for Dependency.Upgrade. Each allows the dependencies to move
+// to a newer version differing from the current one at most in the given part.
+const (
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+// DependencyBump describes the version constraint of a dependency that was
+// rewritten by Dependency.Upgrade.
+type DependencyBump struct {
+	Name string `json:"name"`
+	// From is the previous version constraint.
+	From string `json:"from"`
+	// To is the new version constraint.
+	To string `json:"to"`
+	// Version is the newest version satisfying the policy.
+	Version string `json:"version"`
+}
+
+// simpleConstraint matches a constraint on a single version, capturing its
+// operator so the version can be replaced and the operator kept.
+var simpleConstraint = regexp.MustCompile(`^\s*(\^|~|=|>=)?\s*(v?[0-9][^\s,|]*)\s*$`)
+
+// Upgrade rewrites the version constraints of the dependencies of the chart
+// directory at chartpath to the newest available versions allowed by policy,
+// one of BumpPatch, BumpMinor or BumpMajor.
+//
+// versions must return the versions available for each dependency, newest
+// first, as downloader.Manager.AvailableVersions does. A dependency moves
+// relative to its locked version or, when it is not locked, to the version of
+// its vendored chart, the version of its constraint, or the newest version
+// satisfying its constraint, in that order. Constraints on a single version keep their operator;
+// other constraints, such as ranges and wildcards, are replaced with the exact
+// version.
+//
+// Chart.yaml, or requirements.yaml for apiVersion v1 charts, is edited in
+// place. Chart.lock and charts/ are left alone: they are brought up to date by
+// running 'helm dependency update' afterwards.
+func (d *Dependency) Upgrade(chartpath, policy string, versions func([]*chart.Dependency) ([][]*semver.Version, error)) ([]DependencyBump, error) {
+	switch policy {
+	case BumpPatch, BumpMinor, BumpMajor:
+	default:
+		return nil, fmt.Errorf("invalid bump policy %q: must be one of %s, %s or %s", policy, BumpPatch, BumpMinor, BumpMajor)
+	}
+	if fi, err := os.Stat(chartpath); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("%q is not a chart directory", chartpath)
+	}
+
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return nil, err
+	}
+	reqs := c.Metadata.Dependencies
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	deps := make([]*chart.Dependency, len(reqs))
+	for i, dep := range reqs {
+		cp := *dep
+		deps[i] = &cp
+	}
+	available, err := versions(deps)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := map[string]string{}
+	if c.Lock != nil {
+		for _, dep := range c.Lock.Dependencies {
+			locked[dep.Name] = dep.Version
+		}
+	}
+
+	var bumps []DependencyBump
+	constraints := map[int]string{}
+	for i, dep := range reqs {
+		if i >= len(available) {
+			break
+		}
+		base := current(locked[dep.Name], dep.Name, c)
+		if base == "" {
+			if m := simpleConstraint.FindStringSubmatch(dep.Version); m != nil {
+				base = m[2]
+			} else {
+				base, _ = latestVersion(dep.Version, "", available[i])
+			}
+		}
+		from, err := semver.NewVersion(base)
+		if err != nil {
+			continue
+		}
+		to := newestAllowed(from, policy, available[i])
+		if to == nil {
+			continue
+		}
+		constraint := to.Original()
+		if m := simpleConstraint.FindStringSubmatch(dep.Version); m != nil {
+			constraint = m[1] + to.Original()
+		}
+		if constraint == dep.Version {
+			continue
+		}
+		constraints[i] = constraint
+		bumps = append(bumps, DependencyBump{Name: dep.Name, From: dep.Version, To: constraint, Version: to.Original()})
+	}
+	if len(bumps) == 0 {
+		return nil, nil
+	}
+
+	filename := filepath.Join(chartpath, "Chart.yaml")
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		filename = filepath.Join(chartpath, "requirements.yaml")
+	}
+	if err := rewriteDependencyVersions(filename, reqs, constraints); err != nil {
+		return nil, err
+	}
+	return bumps, nil
+}
+
+// newestAllowed returns the first of the available versions, sorted newest
+// first, that is newer than current and allowed by policy. Pre-releases are
+// only considered when current is one.
+func newestAllowed(current *semver.Version, policy string, available []*semver.Version) *semver.Version {
+	for _, v := range available {
+		if !v.GreaterThan(current) {
+			return nil
+		}
+		if v.Prerelease() != "" && current.Prerelease() == "" {
+			continue
+		}
+		switch {
+		case policy == BumpPatch && (v.Major() != current.Major() || v.Minor() != current.Minor()):
+			continue
+		case policy == BumpMinor && v.Major() != current.Major():
+			continue
+		}
+		return v
+	}
+	return nil
+}
+
+// rewriteDependencyVersions sets the version of the dependencies at the given
+// indexes in the dependencies list of filename. Only the version values are
+// changed, so the formatting and comments of the file are kept.
+func rewriteDependencyVersions(filename string, reqs []*chart.Dependency, constraints map[int]string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", filename, err)
+	}
+	var list *yaml.Node
+	if len(doc.Content) > 0 {
+		list = yamlMappingValue(doc.Content[0], "dependencies")
+	}
+	if list == nil || list.Kind != yaml.SequenceNode || len(list.Content) != len(reqs) {
+		return fmt.Errorf("cannot find the dependencies in %s", filename)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i, constraint := range constraints {
+		item := list.Content[i]
+		name := yamlMappingValue(item, "name")
+		version := yamlMappingValue(item, "version")
+		if name == nil || name.Value != reqs[i].Name || version == nil || version.Kind != yaml.ScalarNode {
+			return fmt.Errorf("cannot find the version of dependency %q in %s", reqs[i].Name, filename)
+		}
+
+		old, replacement := version.Value, constraint
+		switch version.Style {
+		case yaml.DoubleQuotedStyle:
+			old, replacement = `"`+old+`"`, `"`+replacement+`"`
+		case yaml.SingleQuotedStyle:
+			old, replacement = `'`+old+`'`, `'`+replacement+`'`
+		case 0:
+			// Quote the constraint when it would not be read back as a string.
+			if strings.HasPrefix(replacement, ">") || strings.ContainsAny(replacement, ":#,|&*!{}[]'\"%@`") {
+				replacement = `"` + replacement + `"`
+			}
+		default:
+			return fmt.Errorf("cannot rewrite the version of dependency %q in %s", reqs[i].Name, filename)
+		}
+
+		line, col := version.Line-1, version.Column-1
+		if line >= len(lines) || !strings.HasPrefix(lines[line][col:], old) {
+			return fmt.Errorf("cannot rewrite the version of dependency %q in %s", reqs[i].Name, filename)
+		}
+		lines[line] = lines[line][:col] + replacement + lines[line][col+len(old):]
+	}
+
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, []byte(strings.Join(lines, "\n")), fi.Mode())
+}
+
+// yamlMappingValue returns the value of key in a YAML mapping node, or nil.
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+const upgradeChartfile = `apiVersion: v2
+name: parent
+version: 0.1.0
+# Keep the dependencies sorted.
+dependencies:
+  - name: exact
+    version: 1.0.0 # pinned
+    repository: https://example.com/charts
+  - name: caret
+    version: "^1.0.0"
+    repository: https://example.com/charts
+  - name: range
+    version: '>=1.0.0 <3.0.0'
+    repository: https://example.com/charts
+  - name: local
+    version: 1.0.0
+    repository: file://../local
+`
+
+func TestDependencyUpgrade(t *testing.T) {
+	available := func(vs ...string) []*semver.Version {
+		var versions []*semver.Version
+		for _, v := range vs {
+			versions = append(versions, semver.MustParse(v))
+		}
+		return versions
+	}
+	versions := func(deps []*chart.Dependency) ([][]*semver.Version, error) {
+		all := available("2.1.0", "2.0.0", "1.2.0-beta.1", "1.1.0", "1.0.2", "1.0.1", "1.0.0")
+		return [][]*semver.Version{all, all, all, nil}, nil
+	}
+
+	for _, tt := range []struct {
+		policy string
+		bumps  []DependencyBump
+		expect string
+	}{
+		{
+			policy: BumpPatch,
+			bumps: []DependencyBump{
+				{Name: "exact", From: "1.0.0", To: "1.0.2", Version: "1.0.2"},
+				{Name: "caret", From: "^1.0.0", To: "^1.0.2", Version: "1.0.2"},
+			},
+			expect: `
+  - name: exact
+    version: 1.0.2 # pinned
+    repository: https://example.com/charts
+  - name: caret
+    version: "^1.0.2"
+    repository: https://example.com/charts
+  - name: range
+    version: '>=1.0.0 <3.0.0'
+`,
+		},
+		{
+			policy: BumpMinor,
+			bumps: []DependencyBump{
+				{Name: "exact", From: "1.0.0", To: "1.1.0", Version: "1.1.0"},
+				{Name: "caret", From: "^1.0.0", To: "^1.1.0", Version: "1.1.0"},
+			},
+			expect: `
+  - name: exact
+    version: 1.1.0 # pinned
+`,
+		},
+		{
+			policy: BumpMajor,
+			bumps: []DependencyBump{
+				{Name: "exact", From: "1.0.0", To: "2.1.0", Version: "2.1.0"},
+				{Name: "caret", From: "^1.0.0", To: "^2.1.0", Version: "2.1.0"},
+			},
+			expect: `
+  - name: local
+    version: 1.0.0
+`,
+		},
+	} {
+		t.Run(tt.policy, func(t *testing.T) {
+			dir := t.TempDir()
+			chartfile := filepath.Join(dir, "Chart.yaml")
+			require.NoError(t, os.WriteFile(chartfile, []byte(upgradeChartfile), 0644))
+
+			bumps, err := NewDependency().Upgrade(dir, tt.policy, versions)
+			require.NoError(t, err)
+			assert.Equal(t, tt.bumps, bumps)
+
+			data, err := os.ReadFile(chartfile)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "# Keep the dependencies sorted.\n")
+			assert.Contains(t, string(data), tt.expect)
+		})
+	}
+
+	t.Run("locked versions", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(upgradeChartfile), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.lock"), []byte(`dependencies:
+- name: range
+  version: 2.0.0
+digest: sha256:0
+`), 0644))
+
+		bumps, err := NewDependency().Upgrade(dir, BumpMinor, versions)
+		require.NoError(t, err)
+		assert.Contains(t, bumps, DependencyBump{Name: "range", From: ">=1.0.0 <3.0.0", To: "2.1.0", Version: "2.1.0"})
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(upgradeChartfile), 0644))
+
+		bumps, err := NewDependency().Upgrade(dir, BumpMajor, func(deps []*chart.Dependency) ([][]*semver.Version, error) {
+			return [][]*semver.Version{available("1.0.0"), available("1.0.0"), nil, nil}, nil
+		})
+		require.NoError(t, err)
+		assert.Empty(t, bumps)
+
+		data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, upgradeChartfile, string(data))
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := NewDependency().Upgrade(t.TempDir(), "latest", versions)
+		assert.ErrorContains(t, err, `invalid bump policy "latest"`)
+	})
+}
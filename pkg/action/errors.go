@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/repo/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// The errors returned by the actions are marked with one of the following
+// classes when the cause of the failure is known. The message of the error is
+// left unchanged; use errors.Is to test for a class and ErrorCodeOf to get its
+// code.
+var (
+	// ErrChartNotFound indicates that the requested chart could not be located.
+	ErrChartNotFound = errors.New("chart not found")
+	// ErrRenderFailure indicates that the templates of the chart could not be rendered.
+	ErrRenderFailure = errors.New("rendering failed")
+	// ErrStorageConflict indicates that the release storage holds a release
+	// conflicting with the operation, e.g. a name in use or a pending operation.
+	ErrStorageConflict = errors.New("conflicting release in storage")
+	// ErrApplyConflict indicates that a resource conflicts with an object in the cluster.
+	ErrApplyConflict = kube.ErrApplyConflict
+	// ErrWaitTimeout indicates that the resources were not ready before the timeout.
+	ErrWaitTimeout = kube.ErrWaitTimeout
+	// ErrInterrupted indicates that the operation was cancelled before it
+	// completed, e.g. when the process received SIGINT.
+	ErrInterrupted = errors.New("operation interrupted")
+)
+
+// ErrorCode is a stable identifier of a class of failures, meant for scripts
+// and SDK callers that need to branch on the cause of an error.
+type ErrorCode string
+
+const (
+	ErrorCodeUnknown         ErrorCode = "UNKNOWN"
+	ErrorCodeChartNotFound   ErrorCode = "CHART_NOT_FOUND"
+	ErrorCodeReleaseNotFound ErrorCode = "RELEASE_NOT_FOUND"
+	ErrorCodeRenderFailure   ErrorCode = "RENDER_FAILURE"
+	ErrorCodeStorageConflict ErrorCode = "STORAGE_CONFLICT"
+	ErrorCodeApplyConflict   ErrorCode = "APPLY_CONFLICT"
+	ErrorCodeWaitTimeout     ErrorCode = "WAIT_TIMEOUT"
+	ErrorCodeInterrupted     ErrorCode = "INTERRUPTED"
+)
+
+// errorCodes lists the classes in the order they are checked, so that the
+// most specific cause wins when an error carries several of them.
+var errorCodes = []struct {
+	class error
+	code  ErrorCode
+}{
+	{ErrChartNotFound, ErrorCodeChartNotFound},
+	{ErrRenderFailure, ErrorCodeRenderFailure},
+	{ErrStorageConflict, ErrorCodeStorageConflict},
+	{ErrApplyConflict, ErrorCodeApplyConflict},
+	{ErrWaitTimeout, ErrorCodeWaitTimeout},
+	{ErrInterrupted, ErrorCodeInterrupted},
+	{driver.ErrReleaseNotFound, ErrorCodeReleaseNotFound},
+}
+
+// ErrorCodeOf returns the code of the class err belongs to, or
+// ErrorCodeUnknown if the cause of err is not known.
+func ErrorCodeOf(err error) ErrorCode {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.class) {
+			return c.code
+		}
+	}
+	return ErrorCodeUnknown
+}
+
+// classify marks err as belonging to class. A nil err stays nil.
+func classify(err, class error) error {
+	return kube.Classify(err, class)
+}
+
+// interrupted returns the error of an operation whose context is done. The
+// cause of the cancellation, if any, is kept as the message.
+func interrupted(ctx context.Context) error {
+	return classify(context.Cause(ctx), ErrInterrupted)
+}
+
+// locateError classifies the errors of LocateChart.
+func locateError(err error) error {
+	if errors.Is(err, repo.ChartNotFoundError{}) || errors.Is(err, repo.ErrNoChartName) || errors.Is(err, repo.ErrNoChartVersion) {
+		return classify(err, ErrChartNotFound)
+	}
+	return err
+}
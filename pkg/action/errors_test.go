@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"nil", nil, ErrorCodeUnknown},
+		{"unclassified", errors.New("boom"), ErrorCodeUnknown},
+		{"chart not found", classify(errors.New("boom"), ErrChartNotFound), ErrorCodeChartNotFound},
+		{"render failure", fmt.Errorf("install: %w", classify(errors.New("boom"), ErrRenderFailure)), ErrorCodeRenderFailure},
+		{"storage conflict", classify(errPending, ErrStorageConflict), ErrorCodeStorageConflict},
+		{"apply conflict", fmt.Errorf("apply: %w", ErrApplyConflict), ErrorCodeApplyConflict},
+		{"wait timeout", errors.Join(errors.New("resource not ready"), ErrWaitTimeout), ErrorCodeWaitTimeout},
+		{"release not found", fmt.Errorf("get: %w", driver.ErrReleaseNotFound), ErrorCodeReleaseNotFound},
+		{"interrupted", classify(context.Canceled, ErrInterrupted), ErrorCodeInterrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorCodeOf(tt.err))
+		})
+	}
+}
+
+func TestClassifyKeepsMessageAndCause(t *testing.T) {
+	err := classify(context.DeadlineExceeded, ErrWaitTimeout)
+	assert.Equal(t, context.DeadlineExceeded.Error(), err.Error())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, ErrWaitTimeout)
+	assert.NoError(t, classify(nil, ErrWaitTimeout))
+}
+
+func TestActionErrorClasses(t *testing.T) {
+	t.Run("render failure", func(t *testing.T) {
+		instAction := installAction(t)
+		_, err := instAction.Run(buildChart(withSampleIncludingIncorrectTemplates()), nil)
+		assert.ErrorIs(t, err, ErrRenderFailure)
+	})
+
+	t.Run("name in use", func(t *testing.T) {
+		instAction := installAction(t)
+		rel := releaseStub()
+		rel.Name = instAction.ReleaseName
+		require.NoError(t, instAction.cfg.Releases.Create(rel))
+		_, err := instAction.Run(buildChart(), nil)
+		assert.ErrorIs(t, err, ErrStorageConflict)
+		assert.ErrorIs(t, err, ErrReleaseNameInUse)
+	})
+
+	t.Run("pending upgrade", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := releaseStub()
+		rel.Name = "pending"
+		rel.Info.Status = rcommon.StatusPendingUpgrade
+		require.NoError(t, upAction.cfg.Releases.Create(rel))
+		_, err := upAction.Run(rel.Name, buildChart(), nil)
+		assert.ErrorIs(t, err, ErrStorageConflict)
+		assert.Equal(t, ErrorCodeStorageConflict, ErrorCodeOf(err))
+	})
+
+	t.Run("chart not found", func(t *testing.T) {
+		c := &ChartPathOptions{}
+		_, err := c.LocateChart("./does-not-exist", cli.New())
+		assert.ErrorIs(t, err, ErrChartNotFound)
+	})
+
+	t.Run("wait timeout", func(t *testing.T) {
+		instAction := installAction(t)
+		failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+		failer.WaitError = fmt.Errorf("waiting: %w", ErrWaitTimeout)
+		instAction.cfg.KubeClient = failer
+		instAction.WaitStrategy = kube.StatusWatcherStrategy
+		_, err := instAction.Run(buildChart(), nil)
+		assert.Equal(t, ErrorCodeWaitTimeout, ErrorCodeOf(err))
+	})
+}
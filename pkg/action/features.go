@@ -0,0 +1,133 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// RequiredCRDsAnnotation is the chart annotation that lists, separated by
+// commas, the CustomResourceDefinitions that the chart requires in the
+// cluster. It is checked before the chart is rendered.
+const RequiredCRDsAnnotation = "helm.sh/required-crds"
+
+const (
+	podSecurityEnforceLabel       = "pod-security.kubernetes.io/enforce"
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+)
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// clusterFeatures returns the features of the cluster: the ClusterFeatures
+// of the configuration when set, or else the features detected in the
+// cluster.
+func (cfg *Configuration) clusterFeatures() common.ClusterFeatures {
+	if cfg.ClusterFeatures != nil {
+		return cfg.ClusterFeatures.Copy()
+	}
+	restConfig, err := cfg.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		cfg.Logger().Debug("skipping cluster feature detection", slog.Any("error", err))
+		return common.ClusterFeatures{}
+	}
+	mc, err := metadata.NewForConfig(restConfig)
+	if err != nil {
+		cfg.Logger().Debug("skipping cluster feature detection", slog.Any("error", err))
+		return common.ClusterFeatures{}
+	}
+	cs, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		cfg.Logger().Debug("skipping cluster feature detection", slog.Any("error", err))
+		return common.ClusterFeatures{}
+	}
+	return detectClusterFeatures(context.Background(), mc, cs, cfg.Logger())
+}
+
+// detectClusterFeatures detects the features of a cluster. Detection is best
+// effort: the features that cannot be read, for example for lack of
+// permissions, are left unknown.
+func detectClusterFeatures(ctx context.Context, mc metadata.Interface, cs kubernetes.Interface, logger *slog.Logger) common.ClusterFeatures {
+	var features common.ClusterFeatures
+
+	if crds, err := mc.Resource(crdResource).List(ctx, metav1.ListOptions{}); err != nil {
+		logger.Debug("could not list CustomResourceDefinitions", slog.Any("error", err))
+	} else {
+		features.CRDs = make([]string, 0, len(crds.Items))
+		for _, crd := range crds.Items {
+			features.CRDs = append(features.CRDs, crd.Name)
+		}
+		slices.Sort(features.CRDs)
+	}
+
+	if namespaces, err := cs.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: podSecurityEnforceLabel}); err != nil {
+		logger.Debug("could not list namespaces", slog.Any("error", err))
+	} else {
+		for _, ns := range namespaces.Items {
+			if level := ns.Labels[podSecurityEnforceLabel]; level != "" {
+				if features.PodSecurity == nil {
+					features.PodSecurity = map[string]string{}
+				}
+				features.PodSecurity[ns.Name] = level
+			}
+		}
+	}
+
+	if classes, err := cs.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{}); err != nil {
+		logger.Debug("could not list StorageClasses", slog.Any("error", err))
+	} else {
+		for _, sc := range classes.Items {
+			if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+				features.DefaultStorageClass = sc.Name
+				break
+			}
+		}
+	}
+
+	return features
+}
+
+// checkRequiredCRDs checks that the cluster has the CustomResourceDefinitions
+// required by the chart. Nothing is checked when the CRDs of the cluster are
+// unknown.
+func checkRequiredCRDs(ch *chart.Chart, features common.ClusterFeatures) error {
+	required := ch.Metadata.Annotations[RequiredCRDsAnnotation]
+	if required == "" || features.CRDs == nil {
+		return nil
+	}
+	var missing []string
+	for name := range strings.SplitSeq(required, ",") {
+		if name = strings.TrimSpace(name); name != "" && !features.HasCRD(name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("chart requires CustomResourceDefinitions that are not installed in the cluster: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+func TestDetectClusterFeatures(t *testing.T) {
+	crd := func(name string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}
+	}
+	scheme := metadatafake.NewTestScheme()
+	assert.NoError(t, metav1.AddMetaToScheme(scheme))
+	mc := metadatafake.NewSimpleMetadataClient(scheme,
+		crd("widgets.example.com"),
+		crd("certificates.cert-manager.io"),
+	)
+	cs := fakeclientset.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "locked", Labels: map[string]string{podSecurityEnforceLabel: "restricted"}}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "slow"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast", Annotations: map[string]string{defaultStorageClassAnnotation: "true"}}},
+	)
+
+	features := detectClusterFeatures(t.Context(), mc, cs, slog.New(slog.DiscardHandler))
+	assert.Equal(t, []string{"certificates.cert-manager.io", "widgets.example.com"}, features.CRDs)
+	assert.True(t, features.HasCRD("widgets.example.com"))
+	assert.Equal(t, map[string]string{"locked": "restricted"}, features.PodSecurity)
+	assert.Equal(t, "fast", features.DefaultStorageClass)
+}
+
+func TestCheckRequiredCRDs(t *testing.T) {
+	ch := buildChart()
+	ch.Metadata.Annotations = map[string]string{RequiredCRDsAnnotation: "widgets.example.com, gadgets.example.com"}
+
+	tests := []struct {
+		name     string
+		features common.ClusterFeatures
+		wantErr  string
+	}{
+		{
+			name:     "unknown CRDs",
+			features: common.ClusterFeatures{},
+		},
+		{
+			name:     "all installed",
+			features: common.ClusterFeatures{CRDs: []string{"gadgets.example.com", "widgets.example.com"}},
+		},
+		{
+			name:     "missing",
+			features: common.ClusterFeatures{CRDs: []string{"widgets.example.com"}},
+			wantErr:  "not installed in the cluster: gadgets.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRequiredCRDs(ch, tt.features)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -23,9 +23,12 @@ import (
 	"strings"
 	"time"
 
+	v2release "helm.sh/helm/v4/internal/release/v2"
 	ci "helm.sh/helm/v4/pkg/chart"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/release"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	v1release "helm.sh/helm/v4/pkg/release/v1"
 )
 
 // GetMetadata is the action for checking a given release's metadata.
@@ -52,6 +55,13 @@ type Metadata struct {
 	Status       string            `json:"status" yaml:"status"`
 	DeployedAt   string            `json:"deployedAt" yaml:"deployedAt"`
 	ApplyMethod  string            `json:"applyMethod,omitempty" yaml:"applyMethod,omitempty"`
+	// ChartSource is where the chart was installed from, if it was recorded
+	ChartSource *rcommon.ChartSource `json:"chartSource,omitempty" yaml:"chartSource,omitempty"`
+	// Namespaces are the namespaces of the resources of the release, if they
+	// were recorded
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// Needs are the releases that the release depends on, as namespace/name
+	Needs []string `json:"needs,omitempty" yaml:"needs,omitempty"`
 }
 
 // NewGetMetadata creates a new GetMetadata object with the given configuration.
@@ -106,9 +116,41 @@ func (g *GetMetadata) Run(name string) (*Metadata, error) {
 		Status:       rac.Status(),
 		DeployedAt:   rac.DeployedAt().Format(time.RFC3339),
 		ApplyMethod:  rac.ApplyMethod(),
+		ChartSource:  chartSource(rel),
+		Namespaces:   releaseNamespaces(rel),
+		Needs:        releaseNeeds(rel),
 	}, nil
 }
 
+// chartSource returns the recorded chart source of the release, if any.
+func chartSource(rel release.Releaser) *rcommon.ChartSource {
+	switch r := rel.(type) {
+	case *v1release.Release:
+		return r.ChartSource
+	case *v2release.Release:
+		return r.ChartSource
+	default:
+		return nil
+	}
+}
+
+// releaseNamespaces returns the recorded namespaces of the resources of the
+// release, if any.
+func releaseNamespaces(rel release.Releaser) []string {
+	if r, ok := rel.(*v1release.Release); ok {
+		return r.Namespaces()
+	}
+	return nil
+}
+
+// releaseNeeds returns the releases that the release depends on, if any.
+func releaseNeeds(rel release.Releaser) []string {
+	if r, ok := rel.(*v1release.Release); ok {
+		return r.Needs
+	}
+	return nil
+}
+
 // FormattedDepNames formats metadata.dependencies names into a comma-separated list.
 func (m *Metadata) FormattedDepNames() string {
 	depsNames := make([]string, 0, len(m.Dependencies))
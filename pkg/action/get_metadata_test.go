@@ -656,3 +656,34 @@ func TestGetMetadata_Labels(t *testing.T) {
 	assert.Equal(t, metadata.Name, rel.Name)
 	assert.Equal(t, metadata.Labels, customLabels)
 }
+
+func TestGetMetadata_Run_WithChartSource(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	client := NewGetMetadata(cfg)
+
+	source := &common.ChartSource{
+		Ref:    "oci://registry.example.com/charts/test-chart",
+		Digest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	}
+	rel := &release.Release{
+		Name: "test-release",
+		Info: &release.Info{
+			Status:       common.StatusDeployed,
+			LastDeployed: time.Now(),
+		},
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:    "test-chart",
+				Version: "1.0.0",
+			},
+		},
+		Version:     1,
+		Namespace:   "default",
+		ChartSource: source,
+	}
+	require.NoError(t, cfg.Releases.Create(rel))
+
+	result, err := client.Run("test-release")
+	require.NoError(t, err)
+	assert.Equal(t, source, result.ChartSource)
+}
@@ -32,6 +32,9 @@ type GetValues struct {
 
 	Version   int
 	AllValues bool
+	// RedactSensitive replaces the values the chart schema marks as
+	// sensitive with util.RedactedValue.
+	RedactSensitive bool
 }
 
 // NewGetValues creates a new GetValues object with the given configuration.
@@ -57,15 +60,25 @@ func (g *GetValues) Run(name string) (map[string]any, error) {
 		return nil, err
 	}
 
+	vals := rel.Config
 	// If the user wants all values, compute the values and return.
 	if g.AllValues {
 		cfg, err := util.CoalesceValues(rel.Chart, rel.Config)
 		if err != nil {
 			return nil, err
 		}
-		return cfg, nil
+		vals = cfg
 	}
-	return rel.Config, nil
+	if g.RedactSensitive && rel.Chart != nil {
+		paths, err := util.SensitivePaths(rel.Chart)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) > 0 {
+			vals = util.RedactSensitiveValues(vals, paths)
+		}
+	}
+	return vals, nil
 }
 
 // releaserToV1Release is a helper function to convert a v1 release passed by interface
@@ -0,0 +1,138 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// HealthStatus is the health of a release, aggregated from the kstatus of its
+// resources.
+type HealthStatus string
+
+// The health statuses, from the best to the worst. A release has the health of
+// its least healthy resource.
+const (
+	// HealthHealthy means all the resources are current.
+	HealthHealthy HealthStatus = "healthy"
+	// HealthProgressing means some resources are still being reconciled or
+	// terminated.
+	HealthProgressing HealthStatus = "progressing"
+	// HealthMissing means some resources of the manifest do not exist.
+	HealthMissing HealthStatus = "missing"
+	// HealthDegraded means some resources failed.
+	HealthDegraded HealthStatus = "degraded"
+)
+
+var healthOrder = []HealthStatus{HealthHealthy, HealthProgressing, HealthMissing, HealthDegraded}
+
+// String returns the string representation of the health status.
+func (h HealthStatus) String() string { return string(h) }
+
+// worse returns the least healthy of the two statuses.
+func (h HealthStatus) worse(other HealthStatus) HealthStatus {
+	if slices.Index(healthOrder, other) > slices.Index(healthOrder, h) {
+		return other
+	}
+	return h
+}
+
+// Health is the action for checking the health of the resources of releases.
+//
+// It provides the health column of 'helm list --check-health'.
+type Health struct {
+	cfg *Configuration
+}
+
+// NewHealth creates a new Health object with the given configuration.
+func NewHealth(cfg *Configuration) *Health {
+	return &Health{
+		cfg: cfg,
+	}
+}
+
+// Run computes the kstatus of the resources of the manifest of the release,
+// and returns the health of the least healthy one.
+func (h *Health) Run(rel *release.Release) (HealthStatus, error) {
+	if err := h.cfg.KubeClient.IsReachable(); err != nil {
+		return "", err
+	}
+
+	kubeClient := h.cfg.KubeClient
+	// Look the resources up in the namespace of the release, which differs
+	// from the namespace of the client when listing all namespaces.
+	getter, isGetter := h.cfg.RESTClientGetter.(genericclioptions.RESTClientGetter)
+	if kc, ok := kubeClient.(*kube.Client); ok && isGetter && kc.Namespace != rel.Namespace {
+		nc := kube.New(getter)
+		nc.Namespace = rel.Namespace
+		kubeClient = nc
+	}
+
+	resources, err := kubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return "", fmt.Errorf("unable to build kubernetes objects from release manifest: %w", err)
+	}
+	objs, err := kubeClient.Get(resources, false)
+	if err != nil {
+		return "", err
+	}
+
+	health := HealthHealthy
+	found := 0
+	for _, list := range objs {
+		for _, obj := range list {
+			found++
+			health = health.worse(objectHealth(obj))
+		}
+	}
+	if found < len(resources) {
+		health = health.worse(HealthMissing)
+	}
+	return health, nil
+}
+
+// objectHealth maps the kstatus of an object to a health status.
+func objectHealth(obj runtime.Object) HealthStatus {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return HealthProgressing
+	}
+	res, err := status.Compute(&unstructured.Unstructured{Object: content})
+	if err != nil {
+		return HealthProgressing
+	}
+	switch res.Status {
+	case status.CurrentStatus:
+		return HealthHealthy
+	case status.FailedStatus:
+		return HealthDegraded
+	case status.NotFoundStatus:
+		return HealthMissing
+	default:
+		return HealthProgressing
+	}
+}
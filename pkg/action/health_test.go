@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// healthKubeClient builds resources resources and gets objs.
+type healthKubeClient struct {
+	kubefake.PrintingKubeClient
+	resources int
+	objs      []runtime.Object
+}
+
+func (c *healthKubeClient) Build(_ io.Reader, _ bool) (kube.ResourceList, error) {
+	var list kube.ResourceList
+	for range c.resources {
+		list = append(list, &resource.Info{})
+	}
+	return list, nil
+}
+
+func (c *healthKubeClient) Get(_ kube.ResourceList, _ bool) (map[string][]runtime.Object, error) {
+	return map[string][]runtime.Object{"v1/Mixed": c.objs}, nil
+}
+
+func healthObject(apiVersion, kind string, status map[string]any) runtime.Object {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"name": "test", "namespace": "default"},
+		"status":     status,
+	}}
+}
+
+func TestHealth(t *testing.T) {
+	configMap := healthObject("v1", "ConfigMap", nil)
+	pending := healthObject("v1", "Pod", map[string]any{"phase": "Pending"})
+	failed := healthObject("batch/v1", "Job", map[string]any{
+		"conditions": []any{map[string]any{"type": "Failed", "status": "True"}},
+	})
+
+	tests := []struct {
+		name      string
+		resources int
+		objs      []runtime.Object
+		want      HealthStatus
+	}{
+		{"all current", 1, []runtime.Object{configMap}, HealthHealthy},
+		{"in progress", 2, []runtime.Object{configMap, pending}, HealthProgressing},
+		{"missing", 2, []runtime.Object{configMap}, HealthMissing},
+		{"degraded", 3, []runtime.Object{configMap, pending, failed}, HealthDegraded},
+		{"degraded wins over missing", 3, []runtime.Object{failed}, HealthDegraded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := actionConfigFixture(t)
+			cfg.KubeClient = &healthKubeClient{
+				PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+				resources:          tt.resources,
+				objs:               tt.objs,
+			}
+			got, err := NewHealth(cfg).Run(&release.Release{Name: "test", Namespace: "default"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// idempotencyPollInterval is how often the storage is checked while waiting
+// for an operation with the same idempotency key to finish.
+var idempotencyPollInterval = 2 * time.Second
+
+// idempotentRelease returns the latest revision of the release name created
+// by an operation with the given idempotency key. An operation that is still
+// pending is waited for, up to timeout. It returns nil if no revision was
+// created with key or if that revision is not deployed, e.g. because the
+// operation failed or the release was uninstalled or upgraded since, so that
+// the operation runs again.
+func (cfg *Configuration) idempotentRelease(ctx context.Context, name, key string, timeout time.Duration) (*release.Release, error) {
+	rel, err := cfg.releaseWithIdempotencyKey(name, key)
+	if rel == nil || err != nil {
+		return nil, err
+	}
+
+	if rel.Info.Status.IsPending() {
+		if timeout <= 0 {
+			return nil, classify(errPending, ErrStorageConflict)
+		}
+		cfg.Logger().Info("waiting for the operation with the same idempotency key to finish", "name", name, "revision", rel.Version, "idempotencyKey", key)
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		ticker := time.NewTicker(idempotencyPollInterval)
+		defer ticker.Stop()
+		for rel.Info.Status.IsPending() {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("waiting for revision %d of release %q with idempotency key %q: %w", rel.Version, name, key, classify(ctx.Err(), ErrWaitTimeout))
+			case <-ticker.C:
+			}
+			if rel, err = cfg.releaseWithIdempotencyKey(name, key); rel == nil || err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if rel.Info.Status != rcommon.StatusDeployed {
+		return nil, nil
+	}
+	return rel, nil
+}
+
+// releaseWithIdempotencyKey returns the latest revision of the release name
+// created with the given idempotency key, or nil.
+func (cfg *Configuration) releaseWithIdempotencyKey(name, key string) (*release.Release, error) {
+	h, err := cfg.Releases.History(name)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rels, err := releaseListToV1List(h)
+	if err != nil {
+		return nil, err
+	}
+	releaseutil.Reverse(rels, releaseutil.SortByRevision)
+	for _, rel := range rels {
+		if rel.Info.IdempotencyKey == key {
+			return rel, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestUpgradeIdempotencyKey(t *testing.T) {
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "idempotent"
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	upAction.IdempotencyKey = "ci-run-1"
+	first, err := upAction.Run(rel.Name, buildChart(), nil)
+	require.NoError(t, err)
+	firstRel, err := releaserToV1Release(first)
+	require.NoError(t, err)
+	assert.Equal(t, 2, firstRel.Version)
+	assert.Equal(t, "ci-run-1", firstRel.Info.IdempotencyKey)
+
+	// Retrying the same operation returns the revision it created.
+	second, err := upAction.Run(rel.Name, buildChart(), nil)
+	require.NoError(t, err)
+	secondRel, err := releaserToV1Release(second)
+	require.NoError(t, err)
+	assert.Equal(t, 2, secondRel.Version)
+
+	// Another key creates another revision.
+	upAction.IdempotencyKey = "ci-run-2"
+	third, err := upAction.Run(rel.Name, buildChart(), nil)
+	require.NoError(t, err)
+	thirdRel, err := releaserToV1Release(third)
+	require.NoError(t, err)
+	assert.Equal(t, 3, thirdRel.Version)
+
+	// The key of a superseded revision no longer matches, so the upgrade
+	// runs again instead of returning a revision that is not deployed.
+	upAction.IdempotencyKey = "ci-run-1"
+	fourth, err := upAction.Run(rel.Name, buildChart(), nil)
+	require.NoError(t, err)
+	fourthRel, err := releaserToV1Release(fourth)
+	require.NoError(t, err)
+	assert.Equal(t, 4, fourthRel.Version)
+}
+
+func TestInstallIdempotencyKey(t *testing.T) {
+	instAction := installAction(t)
+	instAction.IdempotencyKey = "ci-run-1"
+	_, err := instAction.Run(buildChart(), nil)
+	require.NoError(t, err)
+
+	again := installActionWithConfig(instAction.cfg)
+	again.IdempotencyKey = "ci-run-1"
+	resi, err := again.Run(buildChart(), nil)
+	require.NoError(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Version)
+
+	again = installActionWithConfig(instAction.cfg)
+	again.IdempotencyKey = "ci-run-2"
+	_, err = again.Run(buildChart(), nil)
+	assert.ErrorIs(t, err, ErrReleaseNameInUse)
+}
+
+func TestIdempotentRelease(t *testing.T) {
+	idempotencyPollInterval = 10 * time.Millisecond
+	defer func() { idempotencyPollInterval = 2 * time.Second }()
+
+	for _, status := range []rcommon.Status{rcommon.StatusFailed, rcommon.StatusUninstalled, rcommon.StatusSuperseded} {
+		t.Run(status.String()+" revision is not returned", func(t *testing.T) {
+			cfg := actionConfigFixture(t)
+			rel := namedReleaseStub("done", status)
+			rel.Info.IdempotencyKey = "key"
+			require.NoError(t, cfg.Releases.Create(rel))
+
+			got, err := cfg.idempotentRelease(context.Background(), rel.Name, "key", time.Second)
+			require.NoError(t, err)
+			assert.Nil(t, got)
+		})
+	}
+
+	t.Run("pending operation is waited for", func(t *testing.T) {
+		cfg := actionConfigFixture(t)
+		rel := namedReleaseStub("pending", rcommon.StatusPendingUpgrade)
+		rel.Info.IdempotencyKey = "key"
+		require.NoError(t, cfg.Releases.Create(rel))
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			info := *rel.Info
+			info.Status = rcommon.StatusDeployed
+			done := *rel
+			done.Info = &info
+			_ = cfg.Releases.Update(&done)
+		}()
+
+		got, err := cfg.idempotentRelease(context.Background(), rel.Name, "key", 5*time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, rcommon.StatusDeployed, got.Info.Status)
+	})
+
+	t.Run("pending operation times out", func(t *testing.T) {
+		cfg := actionConfigFixture(t)
+		rel := namedReleaseStub("stuck", rcommon.StatusPendingInstall)
+		rel.Info.IdempotencyKey = "key"
+		require.NoError(t, cfg.Releases.Create(rel))
+
+		_, err := cfg.idempotentRelease(context.Background(), rel.Name, "key", 50*time.Millisecond)
+		assert.ErrorIs(t, err, ErrWaitTimeout)
+
+		_, err = cfg.idempotentRelease(context.Background(), rel.Name, "key", 0)
+		assert.ErrorIs(t, err, ErrStorageConflict)
+	})
+}
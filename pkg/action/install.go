@@ -24,9 +24,11 @@ import (
 	"io"
 	"io/fs"
 	"log/slog"
+	"maps"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -39,11 +41,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 
 	ci "helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/chart/loader/archive"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli"
@@ -52,6 +56,7 @@ import (
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 	"helm.sh/helm/v4/pkg/postrenderer"
+	"helm.sh/helm/v4/pkg/provenance"
 	"helm.sh/helm/v4/pkg/registry"
 	ri "helm.sh/helm/v4/pkg/release"
 	rcommon "helm.sh/helm/v4/pkg/release/common"
@@ -100,26 +105,66 @@ type Install struct {
 	Devel            bool
 	DependencyUpdate bool
 	Timeout          time.Duration
-	Namespace        string
-	ReleaseName      string
-	GenerateName     bool
-	NameTemplate     string
-	Description      string
-	OutputDir        string
+	// PhaseTimeouts are the deadlines of the phases of the install.
+	PhaseTimeouts PhaseTimeouts
+	Namespace     string
+	ReleaseName   string
+	GenerateName  bool
+	NameTemplate  string
+	// NameGenerator generates the release name when no name is given. It
+	// takes precedence over GenerateName.
+	NameGenerator NameGenerator
+	Description   string
+	OutputDir     string
 	// RollbackOnFailure enables rolling back (uninstalling) the release on failure if set
 	RollbackOnFailure        bool
 	SkipCRDs                 bool
 	SubNotes                 bool
 	HideNotes                bool
 	SkipSchemaValidation     bool
+	StrictValuesSchema       bool
 	DisableOpenAPIValidation bool
 	IncludeCRDs              bool
 	Labels                   map[string]string
+	// Annotations are added to the storage object of the release, such as its
+	// Secret, for ownership or chargeback tooling.
+	Annotations map[string]string
+	// Retry configures re-applying the resources after a transient failure.
+	Retry RetryPolicy
+	// WaitForEndpoints waits, once the release is installed, for its Services
+	// of type LoadBalancer and its Ingresses to be given an address, within
+	// the time left to the wait of the resources. The addresses are recorded
+	// in the release info and the notes are rendered again with them as
+	// .Release.Endpoints.
+	WaitForEndpoints bool
+	// IdempotencyKey is recorded with the release. When the release already
+	// has a revision created with the same key, the install waits for that
+	// operation to finish if it is pending and returns its release instead
+	// of installing again, as long as that revision is deployed.
+	IdempotencyKey string
+	// Retention, when set, is recorded on the release and limits its history
+	// in all later operations.
+	Retention *release.Retention
+	// Slot, when set, installs the chart to a slot of a blue/green release,
+	// which becomes the active slot. See Upgrade.Slot.
+	Slot string
 	// KubeVersion allows specifying a custom kubernetes version to use and
 	// APIVersions allows a manual set of supported API Versions to be passed
 	// (for things like templating).
 	KubeVersion *common.KubeVersion
 	APIVersions common.VersionSet
+	// Needs lists the releases the release depends on, by name, or by
+	// namespace/name for releases in other namespaces. The install fails
+	// unless they are deployed and healthy, except on dry runs.
+	Needs []string
+	// RestrictNamespace fails the install when the chart renders namespaced
+	// resources in other namespaces than the release namespace.
+	RestrictNamespace bool
+	// UseCluster reads the capabilities of the cluster and serves the
+	// 'lookup' template function from it during a client-side dry run.
+	// Nothing else is read from or written to the cluster, so read-only
+	// credentials suffice. It is ignored when interacting with the server.
+	UseCluster bool
 	// Used by helm template to render charts with .Release.IsUpgrade. Ignored if Dry-Run is false
 	IsUpgrade bool
 	// Enable DNS lookups when rendering templates
@@ -127,9 +172,16 @@ type Install struct {
 	// Used by helm template to add the release as part of OutputDir path
 	// OutputDir/<ReleaseName>
 	UseReleaseName bool
+	// SourceMapFile is where helm template writes a JSON source map from the
+	// rendered lines to template files. No source map is written when empty.
+	SourceMapFile string
 	// TakeOwnership will ignore the check for helm annotations and take ownership of the resources.
 	TakeOwnership bool
-	PostRenderer  postrenderer.PostRenderer
+	// ShareClusterScoped allows the release to share cluster-scoped resources
+	// that are owned by other releases. A shared resource is only deleted
+	// when the last of its owners is uninstalled.
+	ShareClusterScoped bool
+	PostRenderer       postrenderer.PostRenderer
 	// PostRenderStrategy controls how hooks and regular templates are passed
 	// to the configured post-renderer. See PostRenderStrategy for the
 	// available modes. Defaults to PostRenderStrategyCombined.
@@ -153,12 +205,29 @@ type ChartPathOptions struct {
 	Username              string // --username
 	Verify                bool   // --verify
 	Version               string // --version
+	Channel               string // --channel
+	RequireDigest         bool   // --require-digest
+
+	// Source is set by LocateChart to where the chart was found. It is
+	// recorded in the release by install and upgrade.
+	Source *rcommon.ChartSource
+	// Getters, when set, download the charts and the repository indexes
+	// rather than the getters of the settings, e.g. fake getters in tests.
+	Getters getter.Providers
 
 	// registryClient provides a registry client but is not added with
 	// options from a flag
 	registryClient *registry.Client
 }
 
+// getters returns the getters that download charts.
+func (c *ChartPathOptions) getters(settings *cli.EnvSettings) getter.Providers {
+	if c.Getters != nil {
+		return c.Getters
+	}
+	return getter.All(settings)
+}
+
 // NewInstall creates a new Install object with the given configuration.
 func NewInstall(cfg *Configuration) *Install {
 	in := &Install{
@@ -282,6 +351,22 @@ func (i *Install) Run(chrt ci.Charter, vals map[string]any) (ri.Releaser, error)
 // When the task is cancelled through ctx, the function returns and the install
 // proceeds in the background.
 func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[string]any) (ri.Releaser, error) {
+	start := time.Now()
+	rel, err := i.run(ctx, ch, vals)
+	if !isDryRun(i.DryRunStrategy) {
+		i.cfg.Metrics.observe(OperationInstall, start, rel, err)
+		i.cfg.notify(ctx, OperationInstall, i.ReleaseName, i.Namespace, start, rel, err)
+	}
+	return rel, err
+}
+
+// renderWithCluster reports whether the templates are rendered with a cluster
+// connection, which the 'lookup' template function uses.
+func (i *Install) renderWithCluster() bool {
+	return interactWithServer(i.DryRunStrategy) || i.UseCluster
+}
+
+func (i *Install) run(ctx context.Context, ch ci.Charter, vals map[string]any) (ri.Releaser, error) {
 	var chrt *chart.Chart
 	switch c := ch.(type) {
 	case *chart.Chart:
@@ -292,6 +377,10 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 		return nil, errors.New("invalid chart apiVersion")
 	}
 
+	if chrt.Metadata != nil && chrt.Metadata.Deprecated {
+		i.cfg.warn(WarningDeprecatedChart, fmt.Sprintf("chart %s is deprecated", chrt.Name()))
+	}
+
 	if interactWithServer(i.DryRunStrategy) {
 		if err := i.cfg.KubeClient.IsReachable(); err != nil {
 			i.cfg.Logger().Error(fmt.Sprintf("cluster reachability check failed: %v", err))
@@ -305,12 +394,34 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 		return nil, errors.New("hiding Kubernetes secrets requires a dry-run mode")
 	}
 
+	if i.IdempotencyKey != "" && !isDryRun(i.DryRunStrategy) {
+		rel, err := i.cfg.idempotentRelease(ctx, i.ReleaseName, i.IdempotencyKey, i.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		if rel != nil {
+			i.cfg.Logger().Info("release already installed with the same idempotency key", "name", rel.Name, "revision", rel.Version)
+			return rel, nil
+		}
+	}
+
 	if err := i.availableName(); err != nil {
 		i.cfg.Logger().Error("release name check failed", slog.Any("error", err))
 		return nil, fmt.Errorf("release name check failed: %w", err)
 	}
 
-	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
+	needs := qualifyNeeds(i.Needs, i.Namespace)
+	if !isDryRun(i.DryRunStrategy) {
+		if err := i.cfg.checkNeeds(i.Namespace, needs); err != nil {
+			return nil, fmt.Errorf("release %s cannot be installed: %w", i.ReleaseName, err)
+		}
+	}
+
+	processDependencies := chartutil.ProcessDependencies
+	if i.SkipSchemaValidation {
+		processDependencies = chartutil.ProcessDependenciesWithoutValidation
+	}
+	if err := processDependencies(chrt, vals); err != nil {
 		i.cfg.Logger().Error("chart dependencies processing failed", slog.Any("error", err))
 		return nil, fmt.Errorf("chart dependencies processing failed: %w", err)
 	}
@@ -329,11 +440,22 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 	if !interactWithServer(i.DryRunStrategy) {
 		// Add mock objects in here so it doesn't use Kube API server
 		// NOTE(bacongobbler): used for `helm template`
-		i.cfg.Capabilities = common.DefaultCapabilities.Copy()
+		if i.UseCluster {
+			caps, err := i.cfg.getCapabilities()
+			if err != nil {
+				return nil, err
+			}
+			i.cfg.Capabilities = caps.Copy()
+		} else {
+			i.cfg.Capabilities = common.DefaultCapabilities.Copy()
+		}
 		if i.KubeVersion != nil {
 			i.cfg.Capabilities.KubeVersion = *i.KubeVersion
 		}
 		i.cfg.Capabilities.APIVersions = append(i.cfg.Capabilities.APIVersions, i.APIVersions...)
+		if i.cfg.ClusterFeatures != nil {
+			i.cfg.Capabilities.Features = i.cfg.ClusterFeatures.Copy()
+		}
 		i.cfg.KubeClient = &kubefake.PrintingKubeClient{Out: io.Discard}
 
 		mem := driver.NewMemory()
@@ -363,19 +485,36 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 		IsInstall: !isUpgrade,
 		IsUpgrade: isUpgrade,
 	}
+	if i.Slot != "" {
+		if err := validateSlot(i.Slot); err != nil {
+			return nil, err
+		}
+		options.Slot, options.ActiveSlot = i.Slot, i.Slot
+	}
 	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chrt, vals, options, caps, i.SkipSchemaValidation)
 	if err != nil {
 		return nil, err
 	}
+	if !i.SkipSchemaValidation {
+		if err := i.cfg.checkUnknownValues(chrt, vals, i.StrictValuesSchema); err != nil {
+			return nil, err
+		}
+	}
 
 	if driver.ContainsSystemLabels(i.Labels) {
 		return nil, fmt.Errorf("user supplied labels contains system reserved label name. System labels: %+v", driver.GetSystemLabels())
 	}
 
 	rel := i.createRelease(chrt, vals, i.Labels)
+	rel.Needs = needs
+	rel.Annotations = i.Annotations
+	rel.Info.IdempotencyKey = i.IdempotencyKey
+	rel.Retention = i.Retention
 
 	var manifestDoc *bytes.Buffer
-	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(ctx, chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, interactWithServer(i.DryRunStrategy), i.EnableDNS, i.HideSecret, i.PostRenderStrategy)
+	start := time.Now()
+	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(ctx, chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, i.renderWithCluster(), i.EnableDNS, i.HideSecret, i.PostRenderStrategy)
+	addPhaseTime(&rel.Info.Timings.Render, start)
 	// Even for errors, attach this if available
 	if manifestDoc != nil {
 		rel.Manifest = manifestDoc.String()
@@ -384,13 +523,27 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 	if err != nil {
 		rel.SetStatus(rcommon.StatusFailed, "failed to render resource: "+err.Error())
 		// Return a release with partial data so that the client can show debugging information.
-		return rel, err
+		return rel, classify(err, ErrRenderFailure)
+	}
+	if i.Slot != "" {
+		rel.Manifest, rel.Slots, err = slotManifest(rel.Manifest, i.Slot, nil)
+		if err != nil {
+			return nil, err
+		}
+		rel.ActiveSlot = i.Slot
+	}
+
+	if i.SourceMapFile != "" {
+		if err := i.cfg.writeSourceMap(ctx, chrt, valuesToRender, i.SourceMapFile, i.renderWithCluster(), i.EnableDNS); err != nil {
+			return rel, fmt.Errorf("writing source map: %w", err)
+		}
 	}
 
 	// Mark this release as in-progress
 	rel.SetStatus(rcommon.StatusPendingInstall, "Initial install underway")
 
 	var toBeAdopted kube.ResourceList
+	start = time.Now()
 	resources, err := i.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), !i.DisableOpenAPIValidation)
 	if err != nil {
 		return nil, fmt.Errorf("unable to build kubernetes objects from release manifest: %w", err)
@@ -401,6 +554,12 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 	if err != nil {
 		return nil, err
 	}
+	if i.RestrictNamespace {
+		if err := checkCrossNamespace(resources, rel.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	rel.Resources = resourceRefs(resources)
 
 	// Install requires an extra validation step of checking that resources
 	// don't already exist before we actually create resources. If we continue
@@ -412,12 +571,13 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 		if i.TakeOwnership {
 			toBeAdopted, err = requireAdoption(resources)
 		} else {
-			toBeAdopted, err = existingResourceConflict(resources, rel.Name, rel.Namespace)
+			toBeAdopted, err = existingResourceConflict(resources, rel.Name, rel.Namespace, i.ShareClusterScoped)
 		}
 		if err != nil {
 			return nil, fmt.Errorf("unable to continue with install: %w", err)
 		}
 	}
+	addPhaseTime(&rel.Info.Timings.Validate, start)
 
 	// Bail out here if it is a dry run
 	if isDryRun(i.DryRunStrategy) {
@@ -466,17 +626,20 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 		// We could try to recover gracefully here, but since nothing has been installed
 		// yet, this is probably safer than trying to continue when we know storage is
 		// not working.
+		if errors.Is(err, driver.ErrReleaseExists) {
+			err = classify(err, ErrStorageConflict)
+		}
 		return rel, err
 	}
 
-	rel, err = i.performInstallCtx(ctx, rel, toBeAdopted, resources)
+	rel, err = i.performInstallCtx(ctx, rel, toBeAdopted, resources, valuesToRender)
 	if err != nil {
 		rel, err = i.failRelease(rel, err)
 	}
 	return rel, err
 }
 
-func (i *Install) performInstallCtx(ctx context.Context, rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList) (*release.Release, error) {
+func (i *Install) performInstallCtx(ctx context.Context, rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList, values common.Values) (*release.Release, error) {
 	type Msg struct {
 		r *release.Release
 		e error
@@ -485,14 +648,20 @@ func (i *Install) performInstallCtx(ctx context.Context, rel *release.Release, t
 
 	go func() {
 		i.goroutineCount.Add(1)
-		rel, err := i.performInstall(rel, toBeAdopted, resources)
+		rel, err := i.performInstall(ctx, rel, toBeAdopted, resources, values)
 		resultChan <- Msg{rel, err}
 		i.goroutineCount.Add(-1)
 	}()
 	select {
 	case <-ctx.Done():
-		err := ctx.Err()
-		return rel, err
+		// The install stops at the end of its current phase. It is only
+		// reported as interrupted if it did not complete in the meantime.
+		i.Lock.Lock()
+		defer i.Lock.Unlock()
+		if rel.Info.Status == rcommon.StatusDeployed {
+			return rel, nil
+		}
+		return rel, interrupted(ctx)
 	case msg := <-resultChan:
 		return msg.r, msg.e
 	}
@@ -503,34 +672,59 @@ func (i *Install) getGoroutineCount() int32 {
 	return i.goroutineCount.Load()
 }
 
-func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList) (*release.Release, error) {
+func (i *Install) performInstall(ctx context.Context, rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList, values common.Values) (*release.Release, error) {
 	var err error
+	start := time.Now()
 	// pre-install hooks
 	if !i.DisableHooks {
-		if err := i.cfg.execHook(rel, release.HookPreInstall, i.WaitStrategy, i.WaitOptions, i.Timeout, i.ServerSideApply); err != nil {
+		phaseStart := time.Now()
+		if err := i.cfg.execHook(rel, release.HookPreInstall, i.WaitStrategy, i.WaitOptions, i.PhaseTimeouts.hooks(i.Timeout), i.ServerSideApply); err != nil {
 			return rel, fmt.Errorf("failed pre-install: %w", err)
 		}
+		addPhaseTime(&rel.Info.Timings.Hooks, phaseStart)
+		if ctx.Err() != nil {
+			return rel, interrupted(ctx)
+		}
 	}
 
 	// At this point, we can do the install. Note that before we were detecting whether to
 	// do an update, but it's not clear whether we WANT to do an update if the reuse is set
 	// to true, since that is basically an upgrade operation.
-	if len(toBeAdopted) == 0 && len(resources) > 0 {
-		_, err = i.cfg.KubeClient.Create(
-			resources,
-			kube.ClientCreateOptionServerSideApply(i.ServerSideApply, false))
-	} else if len(resources) > 0 {
-		updateThreeWayMergeForUnstructured := i.TakeOwnership && !i.ServerSideApply // Use three-way merge when taking ownership (and not using server-side apply)
-		_, err = i.cfg.KubeClient.Update(
-			toBeAdopted,
-			resources,
-			kube.ClientUpdateOptionForceReplace(i.ForceReplace),
-			kube.ClientUpdateOptionServerSideApply(i.ServerSideApply, i.ForceConflicts),
-			kube.ClientUpdateOptionThreeWayMergeForUnstructured(updateThreeWayMergeForUnstructured),
-			kube.ClientUpdateOptionUpgradeClientSideFieldManager(true))
-	}
+	phaseStart := time.Now()
+	applyDeadline := deadline(i.PhaseTimeouts.Apply)
+	// The resources created by a failed attempt exist when the next one runs,
+	// so they are updated instead of being created again.
+	var created kube.ResourceList
+	err = i.Retry.do(i.cfg.Logger(), applyDeadline, func() error {
+		var res *kube.Result
+		var err error
+		if len(toBeAdopted) == 0 && len(created) == 0 && len(resources) > 0 {
+			res, err = i.cfg.KubeClient.Create(
+				resources,
+				kube.ClientCreateOptionServerSideApply(i.ServerSideApply, false),
+				kube.ClientCreateOptionDeadline(applyDeadline))
+		} else if len(resources) > 0 {
+			updateThreeWayMergeForUnstructured := i.TakeOwnership && !i.ServerSideApply // Use three-way merge when taking ownership (and not using server-side apply)
+			res, err = i.cfg.KubeClient.Update(
+				append(slices.Clone(toBeAdopted), created...),
+				resources,
+				kube.ClientUpdateOptionForceReplace(i.ForceReplace),
+				kube.ClientUpdateOptionServerSideApply(i.ServerSideApply, i.ForceConflicts),
+				kube.ClientUpdateOptionThreeWayMergeForUnstructured(updateThreeWayMergeForUnstructured),
+				kube.ClientUpdateOptionUpgradeClientSideFieldManager(true),
+				kube.ClientUpdateOptionDeadline(applyDeadline))
+		}
+		if err != nil && res != nil {
+			created = append(created, res.Created...)
+		}
+		return err
+	})
 	if err != nil {
-		return rel, err
+		return rel, phaseError(err, "applying the resources", i.PhaseTimeouts.Apply, applyDeadline)
+	}
+	addPhaseTime(&rel.Info.Timings.Apply, phaseStart)
+	if ctx.Err() != nil {
+		return rel, interrupted(ctx)
 	}
 
 	var waiter kube.Waiter
@@ -543,26 +737,56 @@ func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.Resource
 		return rel, fmt.Errorf("failed to get waiter: %w", err)
 	}
 
+	phaseStart = time.Now()
+	// The endpoints are waited for within the time left to the wait of the
+	// resources, rather than for a timeout of their own.
+	waitDeadline := deadline(i.PhaseTimeouts.wait(i.Timeout))
 	if i.WaitForJobs {
-		err = waiter.WaitWithJobs(resources, i.Timeout)
+		err = waiter.WaitWithJobs(resources, i.PhaseTimeouts.wait(i.Timeout))
 	} else {
-		err = waiter.Wait(resources, i.Timeout)
+		err = waiter.Wait(resources, i.PhaseTimeouts.wait(i.Timeout))
 	}
 	if err != nil {
 		return rel, err
 	}
+	addPhaseTime(&rel.Info.Timings.Wait, phaseStart)
+	if ctx.Err() != nil {
+		return rel, interrupted(ctx)
+	}
 
 	if !i.DisableHooks {
-		if err := i.cfg.execHook(rel, release.HookPostInstall, i.WaitStrategy, i.WaitOptions, i.Timeout, i.ServerSideApply); err != nil {
+		phaseStart = time.Now()
+		if err := i.cfg.execHook(rel, release.HookPostInstall, i.WaitStrategy, i.WaitOptions, i.PhaseTimeouts.hooks(i.Timeout), i.ServerSideApply); err != nil {
 			return rel, fmt.Errorf("failed post-install: %w", err)
 		}
+		addPhaseTime(&rel.Info.Timings.Hooks, phaseStart)
+	}
+
+	if i.WaitForEndpoints {
+		phaseStart = time.Now()
+		cl, err := i.cfg.KubernetesClientSet()
+		if err != nil {
+			return rel, err
+		}
+		if err := i.collectEndpoints(ctx, cl, rel, resources, values, waitDeadline); err != nil {
+			return rel, err
+		}
+		addPhaseTime(&rel.Info.Timings.Wait, phaseStart)
 	}
 
+	// The release is not recorded as deployed once the install was reported
+	// as interrupted.
+	i.Lock.Lock()
+	defer i.Lock.Unlock()
+	if ctx.Err() != nil {
+		return rel, interrupted(ctx)
+	}
 	if len(i.Description) > 0 {
 		rel.SetStatus(rcommon.StatusDeployed, i.Description)
 	} else {
 		rel.SetStatus(rcommon.StatusDeployed, "Install complete")
 	}
+	setDeployStats(rel, start)
 
 	// This is a tricky case. The release has been created, but the result
 	// cannot be recorded. The truest thing to tell the user is that the
@@ -578,6 +802,46 @@ func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.Resource
 	return rel, nil
 }
 
+// collectEndpoints waits, until the deadline of the wait of the resources,
+// for the addresses of the Services of type LoadBalancer and the Ingresses of
+// the release and records them. The notes are rendered again so that they can
+// show the addresses. Running out of time, or failing to render the notes
+// again, is only a warning, as the release is installed.
+func (i *Install) collectEndpoints(ctx context.Context, cl kubernetes.Interface, rel *release.Release, resources kube.ResourceList, values common.Values, waitDeadline time.Time) error {
+	timeout := i.PhaseTimeouts.wait(i.Timeout)
+	if !waitDeadline.IsZero() {
+		timeout = max(time.Until(waitDeadline), 0)
+	}
+	endpoints, err := kube.WaitForEndpoints(ctx, cl, resources, timeout)
+	if err != nil {
+		if ctx.Err() != nil {
+			return interrupted(ctx)
+		}
+		i.cfg.warn(WarningEndpoints, err.Error())
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+	rel.Info.Endpoints = make([]release.Endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		rel.Info.Endpoints = append(rel.Info.Endpoints, release.Endpoint(e))
+	}
+
+	renderValues := maps.Clone(values)
+	if r, ok := values["Release"].(map[string]any); ok {
+		r = maps.Clone(r)
+		r["Endpoints"] = rel.Info.Endpoints
+		renderValues["Release"] = r
+	}
+	notes, err := i.cfg.renderNotes(ctx, rel.Chart, renderValues, i.SubNotes, i.renderWithCluster(), i.EnableDNS)
+	if err != nil {
+		i.cfg.warn(WarningEndpoints, fmt.Sprintf("failed to render the notes with the endpoints: %s", err))
+		return nil
+	}
+	rel.Info.Notes = notes
+	return nil
+}
+
 func (i *Install) failRelease(rel *release.Release, err error) (*release.Release, error) {
 	rel.SetStatus(rcommon.StatusFailed, fmt.Sprintf("Release %q failed: %s", i.ReleaseName, err.Error()))
 	if i.RollbackOnFailure {
@@ -632,7 +896,7 @@ func (i *Install) availableName() error {
 	if st := rel.Info.Status; i.Replace && (st == rcommon.StatusUninstalled || st == rcommon.StatusFailed) {
 		return nil
 	}
-	return errors.New("cannot reuse a name that is still in use")
+	return classify(ErrReleaseNameInUse, ErrStorageConflict)
 }
 
 func releaseListToV1List(ls []ri.Releaser) ([]*release.Release, error) {
@@ -674,6 +938,7 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]any, label
 		Version:     1,
 		Labels:      labels,
 		ApplyMethod: string(determineReleaseSSApplyMethod(i.ServerSideApply)),
+		ChartSource: i.Source,
 	}
 
 	return r
@@ -782,29 +1047,52 @@ func (i *Install) NameAndChart(args []string) (string, string, error) {
 		return args[0], args[1], flagsNotSet()
 	}
 
-	if i.NameTemplate != "" {
-		name, err := TemplateName(i.NameTemplate)
-		return name, args[0], err
+	var generator NameGenerator
+	switch {
+	case i.NameTemplate != "":
+		generator = TemplateNameGenerator{Template: i.NameTemplate}
+	case i.ReleaseName != "":
+		return i.ReleaseName, args[0], nil
+	case i.NameGenerator != nil:
+		generator = i.NameGenerator
+	case i.GenerateName:
+		generator = TimestampNameGenerator{}
+	default:
+		return "", args[0], errors.New("must either provide a name or specify --generate-name")
 	}
 
-	if i.ReleaseName != "" {
-		return i.ReleaseName, args[0], nil
+	name, err := generator.GenerateName(args[0], i.nameTaken)
+	if err != nil {
+		return "", args[0], err
 	}
+	if i.nameTaken(name) {
+		return "", args[0], fmt.Errorf("generated name %q is already in use", name)
+	}
+	return name, args[0], nil
+}
 
-	if !i.GenerateName {
-		return "", args[0], errors.New("must either provide a name or specify --generate-name")
+// nameTaken reports whether a release with the given name exists in the
+// storage of the install.
+func (i *Install) nameTaken(name string) bool {
+	if i.cfg == nil || i.cfg.Releases == nil {
+		return false
 	}
+	_, err := i.cfg.Releases.Last(name)
+	return err == nil
+}
 
-	base := filepath.Base(args[0])
-	if base == "." || base == "" {
-		base = "chart"
+// checkUnknownValues reports the values not declared in the schema of the
+// chart, as an error in strict mode and as a warning otherwise.
+func (cfg *Configuration) checkUnknownValues(chrt *chart.Chart, vals map[string]any, strict bool) error {
+	keys, err := util.UnknownValues(chrt, vals)
+	if err != nil || len(keys) == 0 {
+		return err
 	}
-	// if present, strip out the file extension from the name
-	if idx := strings.Index(base, "."); idx != -1 {
-		base = base[0:idx]
+	if strict {
+		return util.UnknownValuesError{Keys: keys}
 	}
-
-	return fmt.Sprintf("%s-%d", base, time.Now().Unix()), args[0], nil
+	cfg.Logger().Warn("values not defined in the chart schema", "keys", strings.Join(keys, ", "))
+	return nil
 }
 
 // TemplateName renders a name template, returning the name or an error.
@@ -895,28 +1183,41 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 	name = strings.TrimSpace(name)
 	version := strings.TrimSpace(c.Version)
 
+	manifestDigest, err := ociManifestDigest(name)
+	if err != nil {
+		return "", err
+	}
+
 	if c.RepoURL == "" {
 		if _, err := os.Stat(name); err == nil {
 			abs, err := filepath.Abs(name)
 			if err != nil {
 				return abs, err
 			}
+			var ver *provenance.Verification
 			if c.Verify {
-				if _, err := downloader.VerifyChart(abs, abs+".prov", c.Keyring); err != nil {
+				if ver, err = downloader.VerifyChart(abs, abs+".prov", c.Keyring); err != nil {
 					return "", err
 				}
 			}
+			c.Source = localChartSource(abs)
+			c.Source.Verification = chartVerification(ver)
 			return abs, nil
 		}
 		if filepath.IsAbs(name) || strings.HasPrefix(name, ".") {
-			return name, fmt.Errorf("path %q not found", name)
+			return name, classify(fmt.Errorf("path %q not found", name), ErrChartNotFound)
 		}
 	}
 
+	if c.RequireDigest && manifestDigest == "" {
+		return "", errDigestRequired(name)
+	}
+
 	dl := downloader.ChartDownloader{
-		Out:     os.Stdout,
-		Keyring: c.Keyring,
-		Getters: getter.All(settings),
+		Out:         os.Stdout,
+		Keyring:     c.Keyring,
+		TrustPolicy: settings.TrustPolicy,
+		Getters:     c.getters(settings),
 		Options: []getter.Option{
 			getter.WithPassCredentialsAll(c.PassCredentialsAll),
 			getter.WithTLSClientConfig(c.CertFile, c.KeyFile, c.CaFile),
@@ -928,6 +1229,7 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		RepositoryCache:  settings.RepositoryCache,
 		ContentCache:     settings.ContentCache,
 		RegistryClient:   c.registryClient,
+		Channel:          c.Channel,
 	}
 
 	if registry.IsOCI(name) {
@@ -941,15 +1243,16 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		chartURL, err := repo.FindChartInRepoURL(
 			c.RepoURL,
 			name,
-			getter.All(settings),
+			c.getters(settings),
 			repo.WithChartVersion(version),
+			repo.WithChartChannel(c.Channel),
 			repo.WithClientTLS(c.CertFile, c.KeyFile, c.CaFile),
 			repo.WithUsernamePassword(c.Username, c.Password),
 			repo.WithInsecureSkipTLSVerify(c.InsecureSkipTLSVerify),
 			repo.WithPassCredentialsAll(c.PassCredentialsAll),
 		)
 		if err != nil {
-			return "", err
+			return "", locateError(err)
 		}
 		name = chartURL
 
@@ -980,14 +1283,96 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		return "", err
 	}
 
-	filename, _, err := dl.DownloadToCache(name, version)
+	filename, ver, err := dl.DownloadToCache(name, version)
 	if err != nil {
-		return "", err
+		return "", locateError(err)
 	}
 
 	lname, err := filepath.Abs(filename)
 	if err != nil {
 		return filename, err
 	}
+	c.Source = &rcommon.ChartSource{Ref: dl.ResolvedURL, ManifestDigest: manifestDigest}
+	if c.Source.Version, err = archiveChartVersion(lname); err != nil {
+		return lname, err
+	}
+	if c.Verify {
+		c.Source.Verification = chartVerification(ver)
+	}
+	if c.Source.Digest, err = chartutil.FileDigest(lname); err != nil {
+		return lname, err
+	}
 	return lname, nil
 }
+
+// ociManifestDigest returns the manifest digest the chart reference name is
+// pinned to, if it is an OCI reference pinned to a digest.
+func ociManifestDigest(name string) (string, error) {
+	if !registry.IsOCI(name) {
+		return "", nil
+	}
+	return registry.ReferenceDigest(name)
+}
+
+// errDigestRequired is the error of ChartPathOptions.RequireDigest for the
+// chart reference name that is not pinned to a digest.
+func errDigestRequired(name string) error {
+	return fmt.Errorf("chart %q is not pinned to a digest: only OCI references such as oci://registry/chart@sha256:<digest> are allowed", name)
+}
+
+// chartVerification describes a successful verification of the provenance of
+// a chart, to be recorded in the release.
+func chartVerification(ver *provenance.Verification) *rcommon.ChartVerification {
+	if ver == nil || ver.Report == nil {
+		return nil
+	}
+	v := &rcommon.ChartVerification{
+		Method: rcommon.VerificationPGPDetached,
+		Time:   time.Now().UTC(),
+	}
+	if ver.Report.Legacy {
+		v.Method = rcommon.VerificationPGPClearSign
+	}
+	for _, s := range ver.Report.Trusted() {
+		v.Signers = append(v.Signers, s.Fingerprint)
+	}
+	return v
+}
+
+// archiveChartVersion returns the version in the Chart.yaml file of a chart
+// archive.
+func archiveChartVersion(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := archive.ReadArchiveFile(f, "Chart.yaml")
+	if err != nil {
+		return "", fmt.Errorf("unable to read the version of chart %s: %w", path, err)
+	}
+	var metadata struct {
+		Version string `json:"version"`
+	}
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return "", fmt.Errorf("unable to read the version of chart %s: %w", path, err)
+	}
+	return metadata.Version, nil
+}
+
+// localChartSource describes a chart found on the local filesystem. A chart
+// directory unpacked by 'helm pull --record-origin' that is unchanged since is
+// reported with its recorded origin.
+func localChartSource(path string) *rcommon.ChartSource {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		if origin, err := chartutil.LoadOrigin(path); err == nil && origin.VerifyDir(path) == nil {
+			return &rcommon.ChartSource{Ref: origin.Source, Digest: origin.Digest}
+		}
+		return &rcommon.ChartSource{Ref: path}
+	}
+	digest, err := chartutil.FileDigest(path)
+	if err != nil {
+		return &rcommon.ChartSource{Ref: path}
+	}
+	return &rcommon.ChartSource{Ref: path, Digest: digest}
+}
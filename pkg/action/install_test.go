@@ -35,12 +35,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kuberuntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest/fake"
 
@@ -48,12 +50,19 @@ import (
 
 	"helm.sh/helm/v4/internal/test"
 	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli"
+	getterfake "helm.sh/helm/v4/pkg/getter/fake"
+	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 	"helm.sh/helm/v4/pkg/registry"
 	rcommon "helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/repo/v1"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
@@ -465,6 +474,33 @@ func TestInstallRelease_DryRunClient(t *testing.T) {
 	}
 }
 
+func TestInstallRelease_DryRunClientUseCluster(t *testing.T) {
+	kubeVersion, err := common.ParseKubeVersion("v1.99.3")
+	require.NoError(t, err)
+	ch := buildChartWithTemplates([]*common.File{{
+		Name: "templates/version",
+		Data: []byte(`kubeVersion: {{ .Capabilities.KubeVersion.Version }}`),
+	}})
+
+	for _, useCluster := range []bool{false, true} {
+		instAction := installAction(t)
+		instAction.cfg.Capabilities = &common.Capabilities{KubeVersion: *kubeVersion, APIVersions: common.DefaultVersionSet}
+		instAction.DryRunStrategy = DryRunClient
+		instAction.UseCluster = useCluster
+
+		resi, err := instAction.Run(ch, map[string]any{})
+		require.NoError(t, err)
+		res, err := releaserToV1Release(resi)
+		require.NoError(t, err)
+
+		expect := common.DefaultCapabilities.KubeVersion.Version
+		if useCluster {
+			expect = "v1.99.3"
+		}
+		assert.Contains(t, res.Manifest, "kubeVersion: "+expect, "use cluster: %t", useCluster)
+	}
+}
+
 func TestInstallRelease_DryRunHiddenSecret(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -667,10 +703,19 @@ func TestInstallRelease_Wait_Interrupted(t *testing.T) {
 	_, err := instAction.RunWithContext(ctx, buildChart(), vals)
 	is.Error(err)
 	is.Contains(err.Error(), "context canceled")
+	is.ErrorIs(err, ErrInterrupted)
 
 	is.Equal(goroutines+1, instAction.getGoroutineCount()) // installation goroutine still is in background
 	time.Sleep(10 * time.Second)                           // wait for goroutine to finish
 	is.Equal(goroutines, instAction.getGoroutineCount())
+
+	// The installation goroutine does not record the release as deployed
+	// once it was interrupted.
+	last, err := instAction.cfg.Releases.Last(instAction.ReleaseName)
+	is.NoError(err)
+	lastRel, err := releaserToV1Release(last)
+	is.NoError(err)
+	is.Equal(rcommon.StatusFailed, lastRel.Info.Status)
 }
 func TestInstallRelease_WaitForJobs(t *testing.T) {
 	is := assert.New(t)
@@ -1023,6 +1068,41 @@ func TestInstallWithLabels(t *testing.T) {
 	is.Equal(instAction.Labels, res.Labels)
 }
 
+func TestInstallWithAnnotations(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.Annotations = map[string]string{"example.com/team": "payments"}
+	resi, err := instAction.Run(buildChart(), nil)
+	is.NoError(err)
+	res, err := releaserToV1Release(resi)
+	is.NoError(err)
+
+	is.Equal(instAction.Annotations, res.Annotations)
+}
+
+func TestInstallRecordsDeployStats(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	resi, err := instAction.Run(buildChart(), nil)
+	is.NoError(err)
+	res, err := releaserToV1Release(resi)
+	is.NoError(err)
+
+	is.Equal(len(releaseutil.SplitManifests(res.Manifest)), res.Info.ResourceCount)
+	is.Positive(res.Info.ResourceCount)
+	is.Positive(res.Info.DeployDuration)
+	is.Positive(res.Info.Timings.Render)
+	is.Positive(res.Info.Timings.Apply)
+	is.Positive(res.Info.Timings.Wait)
+	is.Positive(res.Info.Timings.Hooks)
+
+	stored, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	is.NoError(err)
+	storedRel, err := releaserToV1Release(stored)
+	is.NoError(err)
+	is.Equal(res.Info.DeployDuration, storedRel.Info.DeployDuration)
+}
+
 func TestInstallWithSystemLabels(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -1294,3 +1374,164 @@ func TestInstallRelease_WaitOptionsPassedDownstream(t *testing.T) {
 	// Verify that WaitOptions were passed to GetWaiter
 	is.NotEmpty(failer.RecordedWaitOptions, "WaitOptions should be passed to GetWaiter")
 }
+
+func TestLocateChartRecordsSource(t *testing.T) {
+	settings := cli.New()
+	archive := "testdata/charts/compressedchart-0.1.0.tgz"
+
+	c := &ChartPathOptions{}
+	path, err := c.LocateChart(archive, settings)
+	require.NoError(t, err)
+	digest, err := chartutil.FileDigest(path)
+	require.NoError(t, err)
+	assert.Equal(t, &rcommon.ChartSource{Ref: path, Digest: digest}, c.Source)
+
+	dir := "testdata/charts/chart-with-schema"
+	_, err = c.LocateChart(dir, settings)
+	require.NoError(t, err)
+	abs, err := filepath.Abs(dir)
+	require.NoError(t, err)
+	assert.Equal(t, &rcommon.ChartSource{Ref: abs}, c.Source)
+
+	origin, err := chartutil.NewOriginFromArchive(archive, "https://example.com/compressedchart-0.1.0.tgz")
+	require.NoError(t, err)
+	dest := t.TempDir()
+	require.NoError(t, chartutil.ExpandFile(dest, archive))
+	unpacked := filepath.Join(dest, origin.Name)
+	require.NoError(t, chartutil.WriteOrigin(unpacked, origin))
+	_, err = c.LocateChart(unpacked, settings)
+	require.NoError(t, err)
+	assert.Equal(t, &rcommon.ChartSource{Ref: origin.Source, Digest: origin.Digest}, c.Source)
+}
+
+func TestLocateChartRecordsResolvedSource(t *testing.T) {
+	archive, err := os.ReadFile("testdata/charts/compressedchart-0.1.0.tgz")
+	require.NoError(t, err)
+	getters := &getterfake.Getter{}
+	getters.Serve("https://charts.example.com/charts/compressedchart-0.1.0.tgz", archive)
+
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(t.TempDir(), "repositories.yaml")
+	settings.RepositoryCache = t.TempDir()
+	settings.ContentCache = t.TempDir()
+	f := repo.NewFile()
+	f.Add(&repo.Entry{Name: "example", URL: "https://charts.example.com"})
+	require.NoError(t, f.WriteFile(settings.RepositoryConfig, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile("example")), []byte(`apiVersion: v1
+entries:
+  compressedchart:
+  - apiVersion: v1
+    name: compressedchart
+    version: 0.1.0
+    urls:
+    - charts/compressedchart-0.1.0.tgz
+`), 0644))
+
+	c := &ChartPathOptions{Getters: getters.Providers()}
+	_, err = c.LocateChart("example/compressedchart", settings)
+	require.NoError(t, err)
+	assert.Equal(t, "https://charts.example.com/charts/compressedchart-0.1.0.tgz", c.Source.Ref)
+	assert.Equal(t, "0.1.0", c.Source.Version)
+	assert.NotEmpty(t, c.Source.Digest)
+}
+
+func TestLocateChartRecordsVerification(t *testing.T) {
+	settings := cli.New()
+	c := &ChartPathOptions{Verify: true, Keyring: "../downloader/testdata/helm-test-key.pub"}
+	_, err := c.LocateChart("../downloader/testdata/signtest-0.1.0.tgz", settings)
+	require.NoError(t, err)
+
+	v := c.Source.Verification
+	require.NotNil(t, v)
+	assert.Equal(t, rcommon.VerificationPGPClearSign, v.Method)
+	assert.Equal(t, []string{"5E615389B53CA37F0EE60BD3843BBF981FC18762"}, v.Signers)
+	assert.WithinDuration(t, time.Now(), v.Time, time.Minute)
+
+	c = &ChartPathOptions{}
+	_, err = c.LocateChart("../downloader/testdata/signtest-0.1.0.tgz", settings)
+	require.NoError(t, err)
+	assert.Nil(t, c.Source.Verification)
+}
+
+func TestLocateChartRequireDigest(t *testing.T) {
+	settings := cli.New()
+	c := &ChartPathOptions{RequireDigest: true}
+
+	// Local charts are allowed.
+	_, err := c.LocateChart("testdata/charts/compressedchart-0.1.0.tgz", settings)
+	require.NoError(t, err)
+
+	for _, ref := range []string{"stable/nginx", "https://example.com/nginx-1.0.0.tgz"} {
+		_, err = c.LocateChart(ref, settings)
+		assert.ErrorContains(t, err, "is not pinned to a digest", ref)
+	}
+
+	// OCI references need a registry client, and the digest is checked first.
+	c.registryClient, err = registry.NewClient()
+	require.NoError(t, err)
+	_, err = c.LocateChart("oci://example.com/charts/nginx:1.0.0", settings)
+	assert.ErrorContains(t, err, "is not pinned to a digest")
+	_, err = c.LocateChart("oci://example.com/charts/nginx@sha256:1234", settings)
+	assert.ErrorContains(t, err, "invalid digest")
+}
+
+func TestInstallRelease_StrictValuesSchema(t *testing.T) {
+	withSchema := func(opts *chartOptions) {
+		opts.Schema = []byte(`{"type": "object", "properties": {"replicas": {"type": "integer"}}}`)
+	}
+	vals := map[string]any{"replicas": 2, "replcias": 3}
+
+	instAction := installAction(t)
+	_, err := instAction.Run(buildChart(withSchema), vals)
+	require.NoError(t, err, "unknown values should only cause a warning")
+
+	instAction = installAction(t)
+	instAction.StrictValuesSchema = true
+	_, err = instAction.Run(buildChart(withSchema), vals)
+	require.EqualError(t, err, "values not defined in the chart schema: replcias")
+
+	instAction = installAction(t)
+	instAction.StrictValuesSchema = true
+	instAction.SkipSchemaValidation = true
+	_, err = instAction.Run(buildChart(withSchema), vals)
+	require.NoError(t, err)
+}
+
+func TestInstallCollectEndpoints(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.WaitForEndpoints = true
+	instAction.Timeout = time.Second
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "spaced"},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeLoadBalancer,
+			Ports: []corev1.ServicePort{{Port: 80}},
+		},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+			Ingress: []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}},
+		}},
+	}
+	resources := kube.ResourceList{{Object: svc, Name: svc.Name, Namespace: svc.Namespace}}
+
+	chrt := buildChart(withNotes("{{ range .Release.Endpoints }}{{ .Kind }} {{ .Name }}: {{ join \",\" .Addresses }}{{ end }}"))
+	// Only the notes are rendered again.
+	chrt.Templates = append(chrt.Templates, &common.File{Name: "templates/once.yaml", Data: []byte(`{{ fail "rendered again" }}`)})
+	vals, err := util.ToRenderValues(chrt, map[string]any{}, common.ReleaseOptions{Name: instAction.ReleaseName, Namespace: instAction.Namespace}, nil)
+	require.NoError(t, err)
+	rel := instAction.createRelease(chrt, map[string]any{}, nil)
+
+	err = instAction.collectEndpoints(context.Background(), kubernetesfake.NewClientset(svc), rel, resources, vals, time.Now().Add(instAction.Timeout))
+	require.NoError(t, err)
+	is.Equal([]release.Endpoint{{
+		Kind:      "Service",
+		Namespace: "spaced",
+		Name:      "web",
+		Addresses: []string{"203.0.113.10"},
+		Ports:     []int32{80},
+	}}, rel.Info.Endpoints)
+	is.Equal("Service web: 203.0.113.10", rel.Info.Notes)
+	_, ok := vals["Release"].(map[string]any)["Endpoints"]
+	is.False(ok, "the render values must not be modified")
+}
@@ -37,7 +37,18 @@ type Lint struct {
 	WithSubcharts        bool
 	Quiet                bool
 	SkipSchemaValidation bool
+	StrictValuesSchema   bool
 	KubeVersion          *common.KubeVersion
+	// ValidateOffline validates the rendered manifests against the schemas of
+	// their kinds without a cluster, using SchemaDirs for kinds that are not
+	// built in or defined by the chart's CRDs.
+	ValidateOffline bool
+	SchemaDirs      []string
+	// PolicyDir holds CEL policies that the rendered manifests are checked
+	// against.
+	PolicyDir string
+	// EnabledRules lists the optional rule groups to run, such as "security".
+	EnabledRules []string
 }
 
 // LintResult is the result of Lint
@@ -60,7 +71,17 @@ func (l *Lint) Run(paths []string, vals map[string]any) *LintResult {
 	}
 	result := &LintResult{}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation)
+		var options []lint.LinterOption
+		if l.ValidateOffline {
+			options = append(options, lint.WithOfflineValidation(l.SchemaDirs))
+		}
+		if l.PolicyDir != "" {
+			options = append(options, lint.WithPolicyDir(l.PolicyDir))
+		}
+		if len(l.EnabledRules) > 0 {
+			options = append(options, lint.WithEnabledRules(l.EnabledRules))
+		}
+		linter, err := lintChart(path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation, l.StrictValuesSchema, options...)
 		if err != nil {
 			result.Errors = append(result.Errors, err)
 			continue
@@ -87,7 +108,7 @@ func HasWarningsOrErrors(result *LintResult) bool {
 	return len(result.Errors) > 0
 }
 
-func lintChart(path string, vals map[string]any, namespace string, kubeVersion *common.KubeVersion, skipSchemaValidation bool) (support.Linter, error) {
+func lintChart(path string, vals map[string]any, namespace string, kubeVersion *common.KubeVersion, skipSchemaValidation, strictValuesSchema bool, options ...lint.LinterOption) (support.Linter, error) {
 	var chartPath string
 	linter := support.Linter{}
 
@@ -126,11 +147,10 @@ func lintChart(path string, vals map[string]any, namespace string, kubeVersion *
 		return linter, fmt.Errorf("unable to check Chart.yaml file in chart: %w", err)
 	}
 
-	return lint.RunAll(
-		chartPath,
-		vals,
-		namespace,
+	options = append([]lint.LinterOption{
 		lint.WithKubeVersion(kubeVersion),
 		lint.WithSkipSchemaValidation(skipSchemaValidation),
-	), nil
+		lint.WithStrictValuesSchema(strictValuesSchema),
+	}, options...)
+	return lint.RunAll(chartPath, vals, namespace, options...), nil
 }
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sync"
+)
+
+// LintCombination is a named set of values to lint charts with, such as the
+// values of one file of a values matrix.
+type LintCombination struct {
+	Name   string
+	Values map[string]any
+}
+
+// LintMatrixResult is the result of linting one chart with one combination.
+type LintMatrixResult struct {
+	Path        string
+	Combination string
+	*LintResult
+}
+
+// Failed reports whether the chart failed to lint with the combination.
+func (r *LintMatrixResult) Failed() bool {
+	return len(r.Errors) != 0
+}
+
+// Count returns the number of messages with the given severity.
+func (r *LintMatrixResult) Count(severity int) int {
+	n := 0
+	for _, msg := range r.Messages {
+		if msg.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// RunMatrix lints every chart once per combination of values.
+//
+// Up to parallelism combinations are linted at the same time; values smaller
+// than one lint them one after the other. The results are ordered by
+// combination, then by chart.
+func (l *Lint) RunMatrix(paths []string, combinations []LintCombination, parallelism int) []*LintMatrixResult {
+	parallelism = max(parallelism, 1)
+	results := make([]*LintMatrixResult, len(paths)*len(combinations))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+	for i, combination := range combinations {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// The charts of a combination share its values, so they are
+			// linted one after the other.
+			for j, path := range paths {
+				results[i*len(paths)+j] = &LintMatrixResult{
+					Path:        path,
+					Combination: combination.Name,
+					LintResult:  l.Run([]string{path}, combination.Values),
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -88,7 +88,7 @@ func TestLintChart(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := lintChart(tt.chartPath, map[string]any{}, namespace, nil, tt.skipSchemaValidation)
+			_, err := lintChart(tt.chartPath, map[string]any{}, namespace, nil, tt.skipSchemaValidation, false)
 			switch {
 			case err != nil && !tt.err:
 				t.Errorf("%s", err)
@@ -210,3 +210,21 @@ func TestHasWarningsOrErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestLint_RunMatrix(t *testing.T) {
+	testCharts := []string{chart1MultipleChartLint, corruptedTgzChart}
+	combinations := []LintCombination{
+		{Name: "a", Values: map[string]any{}},
+		{Name: "b", Values: map[string]any{}},
+		{Name: "c", Values: map[string]any{}},
+	}
+
+	results := NewLint().RunMatrix(testCharts, combinations, 2)
+	assert.Len(t, results, 6)
+	for i, r := range results {
+		assert.Equal(t, combinations[i/2].Name, r.Combination)
+		assert.Equal(t, testCharts[i%2], r.Path)
+		assert.Equal(t, r.Path == corruptedTgzChart, r.Failed(), r.Path)
+	}
+	assert.Equal(t, 0, results[0].Count(support.ErrorSev))
+}
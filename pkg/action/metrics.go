@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	ri "helm.sh/helm/v4/pkg/release"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// The operations reported by Metrics.
+const (
+	OperationInstall   = "install"
+	OperationUpgrade   = "upgrade"
+	OperationRollback  = "rollback"
+	OperationUninstall = "uninstall"
+)
+
+// Metrics collects Prometheus metrics about the operations of the actions:
+// how many ran and failed, and how long they and their phases took.
+//
+// It is optional. Embedders set Configuration.Metrics to collect the metrics,
+// and expose the registerer they created it with. Dry runs are not reported.
+type Metrics struct {
+	operations    *prometheus.CounterVec
+	failures      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	phaseDuration *prometheus.HistogramVec
+	waitTimeouts  *prometheus.CounterVec
+}
+
+// NewMetrics creates the metrics and registers them with reg.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "helm",
+			Name:      "operations_total",
+			Help:      "Number of operations run, by operation and result.",
+		}, []string{"operation", "result"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "helm",
+			Name:      "operation_failures_total",
+			Help:      "Number of failed operations, by operation and reason.",
+		}, []string{"operation", "reason"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "helm",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of the operations.",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12),
+		}, []string{"operation"}),
+		phaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "helm",
+			Name:      "operation_phase_duration_seconds",
+			Help:      "Duration of the phases of the deployments: render, validate, apply, hooks and wait.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 15),
+		}, []string{"operation", "phase"}),
+		waitTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "helm",
+			Name:      "wait_timeouts_total",
+			Help:      "Number of operations that timed out, by operation.",
+		}, []string{"operation"}),
+	}
+	for _, c := range []prometheus.Collector{m.operations, m.failures, m.duration, m.phaseDuration, m.waitTimeouts} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// observe records an operation started at start, which returned rel and err.
+// It is a no-op on a nil Metrics, so the actions call it unconditionally.
+func (m *Metrics) observe(operation string, start time.Time, rel ri.Releaser, err error) {
+	if m == nil {
+		return
+	}
+	m.duration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.operations.WithLabelValues(operation, "failure").Inc()
+		reason := failureReason(err)
+		m.failures.WithLabelValues(operation, reason).Inc()
+		if reason == "timeout" {
+			m.waitTimeouts.WithLabelValues(operation).Inc()
+		}
+	} else {
+		m.operations.WithLabelValues(operation, "success").Inc()
+	}
+
+	r, ok := rel.(*release.Release)
+	if !ok || r == nil || r.Info == nil {
+		return
+	}
+	for phase, d := range map[string]time.Duration{
+		"render":   r.Info.Timings.Render,
+		"validate": r.Info.Timings.Validate,
+		"apply":    r.Info.Timings.Apply,
+		"hooks":    r.Info.Timings.Hooks,
+		"wait":     r.Info.Timings.Wait,
+	} {
+		if d > 0 {
+			m.phaseDuration.WithLabelValues(operation, phase).Observe(d.Seconds())
+		}
+	}
+}
+
+// failureReason classifies the error of a failed operation for the failures
+// metric.
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ErrWaitTimeout), errors.Is(err, context.DeadlineExceeded), wait.Interrupted(err):
+		return "timeout"
+	case errors.Is(err, ErrRenderFailure):
+		return "render"
+	case errors.Is(err, ErrChartNotFound), errors.Is(err, driver.ErrReleaseNotFound), errors.Is(err, driver.ErrNoDeployedReleases):
+		return "not_found"
+	case errors.Is(err, ErrStorageConflict), errors.Is(err, ErrApplyConflict), apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		return "conflict"
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return "unauthorized"
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return "invalid"
+	}
+	return "other"
+}
@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	require.NoError(t, err)
+
+	instAction := installAction(t)
+	instAction.cfg.Metrics = m
+	_, err = instAction.Run(buildChart(), nil)
+	require.NoError(t, err)
+
+	instAction = installAction(t)
+	instAction.cfg.Metrics = m
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WaitError = fmt.Errorf("waiting: %w", context.DeadlineExceeded)
+	instAction.WaitStrategy = kube.StatusWatcherStrategy
+	_, err = instAction.Run(buildChart(), nil)
+	require.Error(t, err)
+
+	// Dry runs are not reported.
+	instAction = installAction(t)
+	instAction.cfg.Metrics = m
+	instAction.DryRunStrategy = DryRunClient
+	_, err = instAction.Run(buildChart(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.operations.WithLabelValues(OperationInstall, "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.operations.WithLabelValues(OperationInstall, "failure")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.failures.WithLabelValues(OperationInstall, "timeout")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.waitTimeouts.WithLabelValues(OperationInstall)))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.duration))
+	assert.Positive(t, testutil.CollectAndCount(m.phaseDuration))
+
+	// A second registration fails.
+	_, err = NewMetrics(reg)
+	assert.Error(t, err)
+}
+
+func TestMetricsNil(t *testing.T) {
+	var m *Metrics
+	m.observe(OperationInstall, time.Now(), nil, nil)
+}
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("wait: %w", context.DeadlineExceeded), "timeout"},
+		{context.Canceled, "canceled"},
+		{driver.ErrReleaseNotFound, "not_found"},
+		{errors.New("boom"), "other"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, failureReason(tt.err), tt.err.Error())
+	}
+}
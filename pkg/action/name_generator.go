@@ -0,0 +1,122 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// maxNameAttempts is the number of names a random NameGenerator may try
+// before giving up on finding an unused name.
+const maxNameAttempts = 10
+
+// NameGenerator generates release names for installs that are not given one.
+//
+// taken reports whether a name is already used by a release in the target
+// namespace. Generators should return a name for which taken is false.
+type NameGenerator interface {
+	GenerateName(chartRef string, taken func(name string) bool) (string, error)
+}
+
+// NameGeneratorFunc adapts a function to the NameGenerator interface.
+type NameGeneratorFunc func(chartRef string, taken func(name string) bool) (string, error)
+
+// GenerateName calls f(chartRef, taken).
+func (f NameGeneratorFunc) GenerateName(chartRef string, taken func(name string) bool) (string, error) {
+	return f(chartRef, taken)
+}
+
+// TimestampNameGenerator generates names of the form "<chart>-<unix time>".
+// It is used by 'helm install --generate-name'.
+type TimestampNameGenerator struct{}
+
+// GenerateName implements NameGenerator.
+func (TimestampNameGenerator) GenerateName(chartRef string, _ func(string) bool) (string, error) {
+	return fmt.Sprintf("%s-%d", chartBaseName(chartRef), time.Now().Unix()), nil
+}
+
+// TemplateNameGenerator generates names by rendering a Go template with the
+// Sprig functions and a 'chart' function returning the name of the chart,
+// e.g. "{{chart}}-{{randAlpha 5 | lower}}". Templates using random functions
+// are rendered again when the name is taken.
+type TemplateNameGenerator struct {
+	Template string
+}
+
+// GenerateName implements NameGenerator.
+func (g TemplateNameGenerator) GenerateName(chartRef string, taken func(string) bool) (string, error) {
+	t, err := template.New("name-template").Funcs(sprig.TxtFuncMap()).Funcs(template.FuncMap{
+		"chart": func() string { return chartBaseName(chartRef) },
+	}).Parse(g.Template)
+	if err != nil {
+		return "", err
+	}
+
+	var name string
+	for range maxNameAttempts {
+		var b bytes.Buffer
+		if err := t.Execute(&b, nil); err != nil {
+			return "", err
+		}
+		name = b.String()
+		if !taken(name) {
+			break
+		}
+	}
+	return name, nil
+}
+
+// SequenceNameGenerator generates names of the form "<prefix>-<n>", using the
+// lowest n starting at 1 that is not taken. The prefix defaults to the name
+// of the chart.
+type SequenceNameGenerator struct {
+	Prefix string
+}
+
+// GenerateName implements NameGenerator.
+func (g SequenceNameGenerator) GenerateName(chartRef string, taken func(string) bool) (string, error) {
+	prefix := g.Prefix
+	if prefix == "" {
+		prefix = chartBaseName(chartRef)
+	}
+	for n := 1; ; n++ {
+		if name := fmt.Sprintf("%s-%d", prefix, n); !taken(name) {
+			return name, nil
+		}
+	}
+}
+
+// chartBaseName derives a name from a chart reference, stripping the
+// directory and any file extension.
+func chartBaseName(chartRef string) string {
+	base := filepath.Base(chartRef)
+	if base == "." || base == "" {
+		base = "chart"
+	}
+	// if present, strip out the file extension from the name
+	if idx := strings.Index(base, "."); idx != -1 {
+		base = base[0:idx]
+	}
+	return base
+}
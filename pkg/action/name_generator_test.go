@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func takenSet(names ...string) func(string) bool {
+	return func(name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestTemplateNameGenerator(t *testing.T) {
+	name, err := TemplateNameGenerator{Template: "{{chart}}-{{randNumeric 4}}"}.GenerateName("./mychart.tgz", takenSet())
+	require.NoError(t, err)
+	assert.Regexp(t, "^mychart-[0-9]{4}$", name)
+
+	_, err = TemplateNameGenerator{Template: "{{"}.GenerateName("mychart", takenSet())
+	assert.ErrorContains(t, err, "unclosed action")
+}
+
+func TestTemplateNameGeneratorRetriesTakenNames(t *testing.T) {
+	attempts := 0
+	taken := func(string) bool {
+		attempts++
+		return attempts < 3
+	}
+	_, err := TemplateNameGenerator{Template: "{{randAlpha 5}}"}.GenerateName("mychart", taken)
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestSequenceNameGenerator(t *testing.T) {
+	name, err := SequenceNameGenerator{}.GenerateName("repo/mychart", takenSet("mychart-1", "mychart-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "mychart-3", name)
+
+	name, err = SequenceNameGenerator{Prefix: "web"}.GenerateName("repo/mychart", takenSet("web-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "web-1", name)
+}
+
+func TestNameAndChartNameGenerator(t *testing.T) {
+	instAction := installAction(t)
+	instAction.ReleaseName = ""
+	instAction.NameGenerator = SequenceNameGenerator{}
+
+	rel := releaseStub()
+	rel.Name = "mychart-1"
+	require.NoError(t, instAction.cfg.Releases.Create(rel))
+
+	name, chrt, err := instAction.NameAndChart([]string{"./mychart"})
+	require.NoError(t, err)
+	assert.Equal(t, "mychart-2", name)
+	assert.Equal(t, "./mychart", chrt)
+}
+
+func TestNameAndChartGeneratedNameTaken(t *testing.T) {
+	instAction := installAction(t)
+	instAction.ReleaseName = ""
+	instAction.NameGenerator = NameGeneratorFunc(func(string, func(string) bool) (string, error) {
+		return "existing", nil
+	})
+
+	rel := releaseStub()
+	rel.Name = "existing"
+	require.NoError(t, instAction.cfg.Releases.Create(rel))
+
+	_, _, err := instAction.NameAndChart([]string{"./mychart"})
+	assert.EqualError(t, err, `generated name "existing" is already in use`)
+}
+
+func TestNameAndChartTemplateNameTaken(t *testing.T) {
+	instAction := installAction(t)
+	instAction.ReleaseName = ""
+	instAction.NameTemplate = "existing"
+
+	rel := releaseStub()
+	rel.Name = "existing"
+	require.NoError(t, instAction.cfg.Releases.Create(rel))
+
+	_, _, err := instAction.NameAndChart([]string{"./mychart"})
+	assert.EqualError(t, err, `generated name "existing" is already in use`)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// resourceRefs records the resources of a release with the namespaces they
+// are deployed to.
+func resourceRefs(resources kube.ResourceList) []release.ResourceRef {
+	refs := make([]release.ResourceRef, 0, len(resources))
+	for _, info := range resources {
+		gvk := resourceGVK(info)
+		ref := release.ResourceRef{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Name:       info.Name,
+		}
+		if namespaced(info) {
+			ref.Namespace = info.Namespace
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// checkCrossNamespace fails when namespaced resources are deployed to other
+// namespaces than the release namespace.
+func checkCrossNamespace(resources kube.ResourceList, namespace string) error {
+	var outside []string
+	for _, info := range resources {
+		if namespaced(info) && info.Namespace != namespace {
+			gvk := resourceGVK(info)
+			outside = append(outside, fmt.Sprintf("%s %s/%s", gvk.Kind, info.Namespace, info.Name))
+		}
+	}
+	if len(outside) > 0 {
+		return fmt.Errorf("resources are outside of the release namespace %q, which is not allowed: %s", namespace, strings.Join(outside, ", "))
+	}
+	return nil
+}
+
+// restoreNamespaces moves the resources built from the manifest of a release
+// to the namespaces recorded for them, so that a resource is found where it
+// was deployed even when the manifest does not set its namespace and the
+// default namespace of the client differs. Resources that are not recorded,
+// or recorded in several namespaces, are left as built.
+func restoreNamespaces(resources kube.ResourceList, refs []release.ResourceRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	return resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil || !namespaced(info) {
+			return err
+		}
+		gvk := resourceGVK(info)
+		var namespaces []string
+		for _, ref := range refs {
+			if ref.APIVersion == gvk.GroupVersion().String() && ref.Kind == gvk.Kind && ref.Name == info.Name && !slices.Contains(namespaces, ref.Namespace) {
+				namespaces = append(namespaces, ref.Namespace)
+			}
+		}
+		if len(namespaces) != 1 || namespaces[0] == info.Namespace || slices.Contains(namespaces, "") {
+			return nil
+		}
+		info.Namespace = namespaces[0]
+		if obj, err := meta.Accessor(info.Object); err == nil {
+			obj.SetNamespace(info.Namespace)
+		}
+		return nil
+	})
+}
+
+// resourceGVK returns the kind of a resource, also for resources built as
+// tables.
+func resourceGVK(info *resource.Info) schema.GroupVersionKind {
+	if info.Mapping != nil {
+		return info.Mapping.GroupVersionKind
+	}
+	return info.Object.GetObjectKind().GroupVersionKind()
+}
+
+// namespaced reports whether a resource is namespaced, also for resources
+// whose mapping has no scope.
+func namespaced(info *resource.Info) bool {
+	if info.Mapping != nil && info.Mapping.Scope != nil {
+		return info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	}
+	return info.Namespace != ""
+}
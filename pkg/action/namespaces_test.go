@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func newScopedResource(kind, name, namespace string, scope meta.RESTScope) *resource.Info {
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: kind}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return &resource.Info{
+		Name:      name,
+		Namespace: namespace,
+		Mapping:   &meta.RESTMapping{GroupVersionKind: gvk, Scope: scope},
+		Object:    obj,
+	}
+}
+
+func TestResourceNamespaces(t *testing.T) {
+	resources := kube.ResourceList{
+		newScopedResource("ConfigMap", "local", "apps", meta.RESTScopeNamespace),
+		newScopedResource("ConfigMap", "remote", "monitoring", meta.RESTScopeNamespace),
+		newScopedResource("Namespace", "monitoring", "", meta.RESTScopeRoot),
+	}
+
+	refs := resourceRefs(resources)
+	assert.Equal(t, []release.ResourceRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "local", Namespace: "apps"},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "remote", Namespace: "monitoring"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "monitoring"},
+	}, refs)
+	assert.Equal(t, []string{"apps", "monitoring"}, (&release.Release{Resources: refs}).Namespaces())
+
+	assert.NoError(t, checkCrossNamespace(resources[:1], "apps"))
+	assert.NoError(t, checkCrossNamespace(resources[2:], "apps"), "cluster-scoped resources are in no namespace")
+	assert.EqualError(t, checkCrossNamespace(resources, "apps"),
+		`resources are outside of the release namespace "apps", which is not allowed: ConfigMap monitoring/remote`)
+
+	// Built by a client whose default namespace is not the one the
+	// resources were deployed to.
+	rebuilt := kube.ResourceList{
+		newScopedResource("ConfigMap", "local", "default", meta.RESTScopeNamespace),
+		newScopedResource("ConfigMap", "remote", "monitoring", meta.RESTScopeNamespace),
+		newScopedResource("ConfigMap", "unrecorded", "default", meta.RESTScopeNamespace),
+		newScopedResource("Namespace", "monitoring", "", meta.RESTScopeRoot),
+	}
+	require.NoError(t, restoreNamespaces(rebuilt, refs))
+	for i, ns := range []string{"apps", "monitoring", "default", ""} {
+		assert.Equal(t, ns, rebuilt[i].Namespace, rebuilt[i].Name)
+		assert.Equal(t, ns, rebuilt[i].Object.(*unstructured.Unstructured).GetNamespace(), rebuilt[i].Name)
+	}
+}
+
+func TestInstallRelease_CrossNamespace(t *testing.T) {
+	resources := kube.ResourceList{
+		newMissingDeployment("local", "spaced"),
+		newMissingDeployment("remote", "monitoring"),
+	}
+
+	for _, restrict := range []bool{false, true} {
+		instAction := installActionWithConfig(actionConfigFixtureWithDummyResources(t, resources))
+		instAction.RestrictNamespace = restrict
+
+		resi, err := instAction.Run(buildChart(), map[string]any{})
+		if restrict {
+			require.ErrorContains(t, err, `outside of the release namespace "spaced", which is not allowed: Deployment monitoring/remote`)
+			continue
+		}
+		require.NoError(t, err)
+		res, err := releaserToV1Release(resi)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"monitoring", "spaced"}, res.Namespaces())
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// qualifyNeeds returns the needs of a release of the namespace as
+// namespace/name.
+func qualifyNeeds(needs []string, namespace string) []string {
+	if len(needs) == 0 {
+		return nil
+	}
+	qualified := make([]string, 0, len(needs))
+	for _, need := range needs {
+		if !strings.Contains(need, "/") {
+			need = namespace + "/" + need
+		}
+		qualified = append(qualified, need)
+	}
+	return qualified
+}
+
+// checkNeeds fails when a release needed by a release of the namespace is
+// missing, not deployed, or unhealthy. Releases that are still progressing
+// are not considered unhealthy.
+func (cfg *Configuration) checkNeeds(namespace string, needs []string) error {
+	var errs []error
+	for _, need := range needs {
+		if err := cfg.checkNeed(namespace, need); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (cfg *Configuration) checkNeed(namespace, need string) error {
+	ns, name, _ := strings.Cut(need, "/")
+	nc := cfg
+	if ns != namespace {
+		if cfg.ForNamespace == nil {
+			return fmt.Errorf("needed release %s cannot be looked up in another namespace", need)
+		}
+		var err error
+		if nc, err = cfg.ForNamespace(ns); err != nil {
+			return fmt.Errorf("needed release %s cannot be looked up: %w", need, err)
+		}
+	}
+
+	reli, err := nc.Releases.Last(name)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return fmt.Errorf("needed release %s is not installed", need)
+		}
+		return err
+	}
+	rel, err := releaserToV1Release(reli)
+	if err != nil {
+		return err
+	}
+	if rel.Info.Status != rcommon.StatusDeployed {
+		return fmt.Errorf("needed release %s is %s", need, rel.Info.Status)
+	}
+	health, err := NewHealth(nc).Run(rel)
+	if err != nil {
+		return fmt.Errorf("unable to check the health of needed release %s: %w", need, err)
+	}
+	if health == HealthMissing || health == HealthDegraded {
+		return fmt.Errorf("needed release %s is %s", need, health)
+	}
+	return nil
+}
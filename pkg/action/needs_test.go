@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestCheckNeeds(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	for name, status := range map[string]rcommon.Status{"db": rcommon.StatusDeployed, "queue": rcommon.StatusFailed} {
+		rel := namedReleaseStub(name, status)
+		rel.Namespace = "spaced"
+		require.NoError(t, cfg.Releases.Create(rel))
+	}
+	other := actionConfigFixture(t)
+	require.NoError(t, other.Releases.Create(namedReleaseStub("cache", rcommon.StatusDeployed)))
+
+	tests := []struct {
+		name         string
+		needs        []string
+		forNamespace bool
+		wantErr      string
+	}{
+		{
+			name: "no needs",
+		},
+		{
+			name:  "deployed",
+			needs: qualifyNeeds([]string{"db"}, "spaced"),
+		},
+		{
+			name:    "missing and failed",
+			needs:   []string{"spaced/web", "spaced/queue"},
+			wantErr: "needed release spaced/web is not installed\nneeded release spaced/queue is failed",
+		},
+		{
+			name:    "other namespace",
+			needs:   []string{"other/cache"},
+			wantErr: "needed release other/cache cannot be looked up in another namespace",
+		},
+		{
+			name:         "other namespace looked up",
+			needs:        []string{"other/cache"},
+			forNamespace: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.ForNamespace = nil
+			if tt.forNamespace {
+				cfg.ForNamespace = func(namespace string) (*Configuration, error) {
+					assert.Equal(t, "other", namespace)
+					return other, nil
+				}
+			}
+			err := cfg.checkNeeds("spaced", tt.needs)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUpgradeRelease_Needs(t *testing.T) {
+	upAction := upgradeAction(t)
+	db := namedReleaseStub("db", rcommon.StatusDeployed)
+	db.Namespace = "spaced"
+	require.NoError(t, upAction.cfg.Releases.Create(db))
+	app := namedReleaseStub("app", rcommon.StatusDeployed)
+	app.Namespace = "spaced"
+	app.Needs = []string{"spaced/db"}
+	require.NoError(t, upAction.cfg.Releases.Create(app))
+
+	// The needs of the current release are kept.
+	resi, err := upAction.Run("app", buildChart(), map[string]any{})
+	require.NoError(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spaced/db"}, res.Needs)
+
+	upAction = NewUpgrade(upAction.cfg)
+	upAction.Needs = []string{"cache"}
+	_, err = upAction.Run("app", buildChart(), map[string]any{})
+	assert.EqualError(t, err, "release app cannot be upgraded: needed release spaced/cache is not installed")
+}
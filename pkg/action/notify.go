@@ -0,0 +1,66 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"helm.sh/helm/v4/pkg/notify"
+	ri "helm.sh/helm/v4/pkg/release"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// notify sends the event of an operation on the release name started at
+// start, which returned rel and err, to the notifier of the configuration.
+// It is a no-op without a notifier. Notifications are best effort: a failed
+// notification is logged and does not fail the operation.
+func (cfg *Configuration) notify(ctx context.Context, operation, name, namespace string, start time.Time, rel ri.Releaser, err error) {
+	if cfg.Notifier == nil {
+		return
+	}
+	e := notify.Event{
+		Operation: operation,
+		Outcome:   notify.OutcomeSuccess,
+		Release:   name,
+		Namespace: namespace,
+		Duration:  time.Since(start),
+		Time:      time.Now(),
+	}
+	if err != nil {
+		e.Outcome = notify.OutcomeFailure
+		e.Error = err.Error()
+	}
+	if r, ok := rel.(*release.Release); ok && r != nil {
+		e.Release = r.Name
+		e.Namespace = r.Namespace
+		e.Revision = r.Version
+		if r.Info != nil {
+			e.Status = r.Info.Status.String()
+		}
+		if r.Chart != nil && r.Chart.Metadata != nil {
+			e.Chart = r.Chart.Metadata.Name
+			e.ChartVersion = r.Chart.Metadata.Version
+			e.AppVersion = r.Chart.Metadata.AppVersion
+		}
+	}
+	// The notification is sent even when the operation was canceled.
+	if nerr := cfg.Notifier.Notify(context.WithoutCancel(ctx), e); nerr != nil {
+		cfg.Logger().Warn("could not send notification", slog.String("operation", operation), slog.Any("error", nerr))
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/notify"
+)
+
+type recordingSink struct {
+	events []notify.Event
+	err    error
+}
+
+func (s *recordingSink) Notify(_ context.Context, e notify.Event) error {
+	s.events = append(s.events, e)
+	return s.err
+}
+
+func TestNotify(t *testing.T) {
+	sink := &recordingSink{}
+
+	instAction := installAction(t)
+	instAction.cfg.Notifier = notify.New(sink)
+	_, err := instAction.Run(buildChart(), nil)
+	require.NoError(t, err)
+
+	// Failed notifications do not fail the operation.
+	sink.err = errors.New("unreachable")
+	instAction = installAction(t)
+	instAction.cfg.Notifier = notify.New(sink)
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.CreateError = errors.New("create failed")
+	_, err = instAction.Run(buildChart(), nil)
+	require.ErrorContains(t, err, "create failed")
+
+	// Dry runs are not reported.
+	instAction = installAction(t)
+	instAction.cfg.Notifier = notify.New(sink)
+	instAction.DryRunStrategy = DryRunClient
+	_, err = instAction.Run(buildChart(), nil)
+	require.NoError(t, err)
+
+	require.Len(t, sink.events, 2)
+	e := sink.events[0]
+	assert.Equal(t, OperationInstall, e.Operation)
+	assert.Equal(t, notify.OutcomeSuccess, e.Outcome)
+	assert.Equal(t, instAction.ReleaseName, e.Release)
+	assert.Equal(t, "spaced", e.Namespace)
+	assert.Equal(t, 1, e.Revision)
+	assert.Equal(t, "deployed", e.Status)
+	assert.Equal(t, "hello", e.Chart)
+	assert.Equal(t, "0.1.0", e.ChartVersion)
+
+	e = sink.events[1]
+	assert.Equal(t, notify.OutcomeFailure, e.Outcome)
+	assert.Contains(t, e.Error, "create failed")
+	assert.Equal(t, "failed", e.Status)
+}
@@ -18,16 +18,21 @@ package action
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"golang.org/x/term"
 	"sigs.k8s.io/yaml"
 
+	v3chart "helm.sh/helm/v4/internal/chart/v3"
+	v3util "helm.sh/helm/v4/internal/chart/v3/util"
 	ci "helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/loader"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
@@ -44,10 +49,37 @@ type Package struct {
 	Keyring          string
 	PassphraseFile   string
 	cachedPassphrase []byte
+	// ProvenanceV2 writes a provenance file with a detached signature of the
+	// chart digest. When the provenance file of the chart already exists and
+	// has the same digest, the signature is added to it.
+	ProvenanceV2 bool
+	// SignatureExpiry, when set, is how long a provenance file written with
+	// ProvenanceV2 stays valid.
+	SignatureExpiry  time.Duration
 	Version          string
 	AppVersion       string
 	Destination      string
 	DependencyUpdate bool
+	// Reproduce verifies the chart directory against the origin recorded by
+	// 'helm pull --record-origin' and checks that the packaged archive has
+	// the recorded digest.
+	Reproduce bool
+	// OnIgnored, if set, is called for each file and directory of the chart
+	// that is left out by the .helmignore files, with the rule that excluded it.
+	OnIgnored func(name, rule string)
+	// Exclude and Include are patterns, in the .helmignore format, of files
+	// to leave out of the package and of files to keep in it. They take
+	// precedence over the .helmignore files of the chart.
+	Exclude []string
+	Include []string
+	// RunBuild runs the build steps of the Chart.yaml of a v3 chart before
+	// packaging it. Steps that run commands also need AllowBuildCommands.
+	RunBuild           bool
+	AllowBuildCommands bool
+	// BuildTimeout is the time each build command may run.
+	BuildTimeout time.Duration
+	// BuildOut receives the build progress and the output of the commands.
+	BuildOut io.Writer
 
 	RepositoryConfig      string
 	RepositoryCache       string
@@ -71,40 +103,62 @@ func NewPackage() *Package {
 
 // Run executes 'helm package' against the given chart and returns the path to the packaged chart.
 func (p *Package) Run(path string, _ map[string]any) (string, error) {
-	chrt, err := loader.LoadDir(path)
+	if p.RunBuild {
+		if p.Reproduce {
+			return "", errors.New("the build cannot be run when reproducing a chart")
+		}
+		if err := p.runBuild(path); err != nil {
+			return "", err
+		}
+	}
+
+	var origin *chartutil.Origin
+	if p.Reproduce {
+		if p.Version != "" || p.AppVersion != "" {
+			return "", errors.New("the version and app version cannot be changed when reproducing a chart")
+		}
+		var err error
+		if origin, err = chartutil.LoadOrigin(path); err != nil {
+			return "", fmt.Errorf("cannot reproduce chart: %w", err)
+		}
+		if err := origin.VerifyDir(path); err != nil {
+			return "", fmt.Errorf("cannot reproduce chart: %w", err)
+		}
+	}
+
+	chrt, err := loader.LoadDirWithOptions(path, loader.Options{
+		OnIgnored: p.OnIgnored,
+		Exclude:   p.Exclude,
+		Include:   p.Include,
+	})
 	if err != nil {
 		return "", err
 	}
-	var ch *chart.Chart
+
+	var save func(dest string) (string, error)
 	switch c := chrt.(type) {
 	case *chart.Chart:
-		ch = c
+		err = p.setVersions(&c.Metadata.Version, &c.Metadata.AppVersion)
+		save = func(dest string) (string, error) { return chartutil.Save(c, dest) }
 	case chart.Chart:
-		ch = &c
+		err = p.setVersions(&c.Metadata.Version, &c.Metadata.AppVersion)
+		save = func(dest string) (string, error) { return chartutil.Save(&c, dest) }
+	case *v3chart.Chart:
+		err = p.setVersions(&c.Metadata.Version, &c.Metadata.AppVersion)
+		save = func(dest string) (string, error) { return v3util.Save(c, dest) }
 	default:
 		return "", errors.New("invalid chart apiVersion")
 	}
-
-	ac, err := ci.NewAccessor(ch)
 	if err != nil {
 		return "", err
 	}
 
-	// If version is set, modify the version.
-	if p.Version != "" {
-		ch.Metadata.Version = p.Version
-	}
-
-	if err := validateVersion(ch.Metadata.Version); err != nil {
+	ac, err := ci.NewAccessor(chrt)
+	if err != nil {
 		return "", err
 	}
-
-	if p.AppVersion != "" {
-		ch.Metadata.AppVersion = p.AppVersion
-	}
-
 	if reqs := ac.MetaDependencies(); len(reqs) > 0 {
-		if err := CheckDependencies(ch, reqs); err != nil {
+		if err := CheckDependencies(chrt, reqs); err != nil {
 			return "", err
 		}
 	}
@@ -121,11 +175,21 @@ func (p *Package) Run(path string, _ map[string]any) (string, error) {
 		dest = p.Destination
 	}
 
-	name, err := chartutil.Save(ch, dest)
+	name, err := save(dest)
 	if err != nil {
 		return "", fmt.Errorf("failed to save: %w", err)
 	}
 
+	if origin != nil {
+		digest, err := chartutil.FileDigest(name)
+		if err != nil {
+			return name, err
+		}
+		if digest != origin.Digest {
+			return name, fmt.Errorf("packaged chart %s has digest %s, expected %s from %s", name, digest, origin.Digest, origin.Source)
+		}
+	}
+
 	if p.Sign {
 		err = p.Clearsign(name)
 	}
@@ -133,6 +197,44 @@ func (p *Package) Run(path string, _ map[string]any) (string, error) {
 	return name, err
 }
 
+// runBuild runs the build steps of a v3 chart directory.
+func (p *Package) runBuild(path string) error {
+	data, err := os.ReadFile(filepath.Join(path, chartutil.ChartfileName))
+	if err != nil {
+		return err
+	}
+	var md struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := yaml.Unmarshal(data, &md); err != nil {
+		return fmt.Errorf("cannot load %s: %w", chartutil.ChartfileName, err)
+	}
+	if md.APIVersion != v3chart.APIVersionV3 {
+		return fmt.Errorf("build steps are only supported by charts with apiVersion %s", v3chart.APIVersionV3)
+	}
+	return v3util.RunBuild(context.Background(), path, v3util.BuildOptions{
+		Out:           p.BuildOut,
+		AllowCommands: p.AllowBuildCommands,
+		Timeout:       p.BuildTimeout,
+	})
+}
+
+// setVersions overrides the version and app version of a chart with the ones
+// of the package action, and validates the version.
+func (p *Package) setVersions(version, appVersion *string) error {
+	// If version is set, modify the version.
+	if p.Version != "" {
+		*version = p.Version
+	}
+	if err := validateVersion(*version); err != nil {
+		return err
+	}
+	if p.AppVersion != "" {
+		*appVersion = p.AppVersion
+	}
+	return nil
+}
+
 // validateVersion Verify that version is a Version, and error out if it is not.
 func validateVersion(ver string) error {
 	if _, err := semver.NewVersion(ver); err != nil {
@@ -188,6 +290,10 @@ func (p *Package) Clearsign(filename string) error {
 		return fmt.Errorf("failed to read chart archive: %w", err)
 	}
 
+	if p.ProvenanceV2 {
+		return p.signV2(signer, archiveData, filename, ch)
+	}
+
 	// Use the generic provenance signing function
 	sig, err := signer.ClearSign(archiveData, filepath.Base(filename), metadataBytes)
 	if err != nil {
@@ -197,6 +303,47 @@ func (p *Package) Clearsign(filename string) error {
 	return os.WriteFile(filename+".prov", []byte(sig), 0644)
 }
 
+// signV2 writes or co-signs a provenance file of provenance.APIVersionV2.
+func (p *Package) signV2(signer *provenance.Signatory, archiveData []byte, filename string, ch *chart.Chart) error {
+	provfile := filename + ".prov"
+	statement, err := provenance.NewStatement(archiveData, filepath.Base(filename), ch.Name(), ch.Metadata.Version)
+	if err != nil {
+		return err
+	}
+	if p.SignatureExpiry > 0 {
+		expires := statement.Created.Add(p.SignatureExpiry)
+		statement.Expires = &expires
+	}
+	prov, err := provenance.NewProvenance(statement)
+	if err != nil {
+		return err
+	}
+
+	// Co-sign an existing provenance file of the same archive.
+	if data, err := os.ReadFile(provfile); err == nil && provenance.IsProvenanceV2(data) {
+		existing, err := provenance.ParseProvenance(data)
+		if err != nil {
+			return err
+		}
+		st, err := existing.ParseStatement()
+		if err != nil {
+			return err
+		}
+		if st.Digest == statement.Digest && st.FileName == statement.FileName {
+			prov = existing
+		}
+	}
+
+	if err := signer.Sign(prov, p.SignatureExpiry); err != nil {
+		return err
+	}
+	data, err := prov.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(provfile, data, 0644)
+}
+
 // promptUser implements provenance.PassphraseFetcher
 func promptUser(name string) ([]byte, error) {
 	fmt.Printf("Password for key %q >  ", name)
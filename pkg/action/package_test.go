@@ -20,12 +20,18 @@ import (
 	"errors"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/require"
 
+	v3chart "helm.sh/helm/v4/internal/chart/v3"
 	"helm.sh/helm/v4/internal/test/ensure"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/provenance"
 )
 
 func TestPassphraseFileFetcher(t *testing.T) {
@@ -169,3 +175,99 @@ func TestRun(t *testing.T) {
 	require.Equal(t, "empty-0.1.0.tgz", filename)
 	require.NoError(t, os.Remove(filename))
 }
+
+func TestRun_Reproduce(t *testing.T) {
+	packaged := t.TempDir()
+	client := NewPackage()
+	client.Destination = packaged
+	archive, err := client.Run("testdata/charts/chart-with-schema", nil)
+	require.NoError(t, err)
+
+	origin, err := chartutil.NewOriginFromArchive(archive, "https://example.com/charts")
+	require.NoError(t, err)
+	dest := t.TempDir()
+	require.NoError(t, chartutil.ExpandFile(dest, archive))
+	chartDir := filepath.Join(dest, origin.Name)
+	require.NoError(t, chartutil.WriteOrigin(chartDir, origin))
+
+	client = NewPackage()
+	client.Destination = t.TempDir()
+	client.Reproduce = true
+	reproduced, err := client.Run(chartDir, nil)
+	require.NoError(t, err)
+	digest, err := chartutil.FileDigest(reproduced)
+	require.NoError(t, err)
+	require.Equal(t, origin.Digest, digest)
+
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte("changed: true\n"), 0644))
+	_, err = client.Run(chartDir, nil)
+	require.ErrorContains(t, err, "modified: values.yaml")
+
+	client.Version = "1.0.0"
+	_, err = client.Run(chartDir, nil)
+	require.ErrorContains(t, err, "cannot be changed")
+}
+
+func TestRun_Build(t *testing.T) {
+	chartDir := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml":        "apiVersion: v3\nname: built\nversion: 0.1.0\nbuild:\n  steps:\n  - builtin: values-schema\n",
+		"values.yaml":       "replicaCount: 1\n",
+		"templates/cm.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: built\n",
+	}
+	for name, data := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(chartDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(chartDir, name), []byte(data), 0644))
+	}
+
+	client := NewPackage()
+	client.Destination = t.TempDir()
+	client.RunBuild = true
+	client.AppVersion = "2.0.0"
+	archive, err := client.Run(chartDir, nil)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(client.Destination, "built-0.1.0.tgz"), archive)
+
+	chrt, err := loader.Load(archive)
+	require.NoError(t, err)
+	c, ok := chrt.(*v3chart.Chart)
+	require.True(t, ok, "expected a v3 chart, got %T", chrt)
+	require.Equal(t, "2.0.0", c.Metadata.AppVersion)
+	require.Contains(t, string(c.Schema), `"replicaCount"`)
+
+	client.Reproduce = true
+	_, err = client.Run(chartDir, nil)
+	require.EqualError(t, err, "the build cannot be run when reproducing a chart")
+
+	client = NewPackage()
+	client.RunBuild = true
+	_, err = client.Run("testdata/charts/chart-with-schema", nil)
+	require.EqualError(t, err, "build steps are only supported by charts with apiVersion v3")
+}
+
+func TestRun_SignProvenanceV2(t *testing.T) {
+	client := NewPackage()
+	client.Destination = t.TempDir()
+	client.Sign = true
+	client.Key = "helm-test"
+	client.Keyring = "../downloader/testdata/helm-test-key.secret"
+	client.ProvenanceV2 = true
+	client.SignatureExpiry = 24 * time.Hour
+
+	archive, err := client.Run("testdata/charts/chart-with-schema", nil)
+	require.NoError(t, err)
+	data, err := os.ReadFile(archive + ".prov")
+	require.NoError(t, err)
+	require.True(t, provenance.IsProvenanceV2(data))
+
+	// Signing the same archive again with the same key is refused.
+	require.ErrorContains(t, client.Clearsign(archive), "already signed")
+
+	verify := NewVerify()
+	verify.Keyring = "../downloader/testdata/helm-test-key.pub"
+	out, err := verify.Run(archive)
+	require.NoError(t, err)
+	require.Contains(t, out, "Signature: trusted key ")
+	require.Contains(t, out, "Signatures Expire: ")
+	require.Contains(t, out, "Chart Hash Verified: sha256:")
+}
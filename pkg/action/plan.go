@@ -0,0 +1,403 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"helm.sh/helm/v4/pkg/chart"
+	ri "helm.sh/helm/v4/pkg/release"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// PlanFormatVersion is the version of the format of the plans.
+const PlanFormatVersion = "1"
+
+// ErrPlanStale is returned when applying a plan to a release that changed
+// since the plan was made.
+var ErrPlanStale = errors.New("the release changed since the plan was made")
+
+// PlanAction is the change a plan makes to a resource.
+type PlanAction string
+
+const (
+	PlanCreate PlanAction = "create"
+	PlanUpdate PlanAction = "update"
+	PlanDelete PlanAction = "delete"
+	PlanNoOp   PlanAction = "no-op"
+)
+
+// Plan describes the changes an upgrade would make to the resources of a
+// release, and holds the revision it would create. It is made by Upgrade.Plan
+// and applied by Upgrade.ApplyPlan.
+type Plan struct {
+	// FormatVersion is the version of the format of the plan.
+	FormatVersion string `json:"format_version"`
+	// Release is the name of the release.
+	Release string `json:"release"`
+	// Namespace is the namespace of the release.
+	Namespace string `json:"namespace"`
+	// CurrentRevision is the revision the changes are relative to.
+	CurrentRevision int `json:"current_revision"`
+	// CurrentDigest is the digest of the manifest of CurrentRevision.
+	CurrentDigest string `json:"current_digest"`
+	// LiveDigest is the digest of the resource versions of the live
+	// resources of CurrentRevision, so that changes made to them outside of
+	// Helm make the plan stale.
+	LiveDigest string `json:"live_digest"`
+	// Summary counts the resources per change.
+	Summary PlanSummary `json:"summary"`
+	// ResourceChanges lists the changes to the resources of the release.
+	ResourceChanges []ResourceChange `json:"resource_changes"`
+	// PlannedDigest is the digest of the manifest of Planned.
+	PlannedDigest string `json:"planned_digest"`
+	// ServerSideApply tells whether the resources are applied server-side.
+	ServerSideApply bool `json:"server_side_apply"`
+	// DisableHooks tells whether the hooks of the chart are skipped.
+	DisableHooks bool `json:"disable_hooks,omitempty"`
+	// Planned is the revision applied by the plan. Its values are left out,
+	// as they may hold secrets; the plan is applied with the values it was
+	// made with, which must match ValuesDigest.
+	Planned *release.Release `json:"planned"`
+	// ValuesDigest is the digest of the values of Planned.
+	ValuesDigest string `json:"values_digest"`
+	// ResetValues, ReuseValues and ResetThenReuseValues are the options of
+	// the upgrade that combine the values with those of CurrentRevision.
+	ResetValues          bool `json:"reset_values,omitempty"`
+	ReuseValues          bool `json:"reuse_values,omitempty"`
+	ResetThenReuseValues bool `json:"reset_then_reuse_values,omitempty"`
+	// Labels and Annotations are those of the storage object of Planned,
+	// which its JSON encoding omits.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// PlanSummary counts the resources per change.
+type PlanSummary struct {
+	Add     int `json:"add"`
+	Change  int `json:"change"`
+	Destroy int `json:"destroy"`
+}
+
+// ResourceChange describes the change a plan makes to a resource.
+type ResourceChange struct {
+	// Address identifies the resource as apiVersion/kind/namespace/name.
+	Address    string     `json:"address"`
+	APIVersion string     `json:"api_version"`
+	Kind       string     `json:"kind"`
+	Namespace  string     `json:"namespace,omitempty"`
+	Name       string     `json:"name"`
+	Action     PlanAction `json:"action"`
+	// BeforeDigest and AfterDigest are the digests of the manifest of the
+	// resource before and after the change.
+	BeforeDigest string `json:"before_digest,omitempty"`
+	AfterDigest  string `json:"after_digest,omitempty"`
+	// Diff is a unified diff of the manifest of the resource.
+	Diff string `json:"diff,omitempty"`
+}
+
+// Plan renders the upgrade of the release like Run, and returns the changes
+// it would make without changing the storage or the cluster.
+func (u *Upgrade) Plan(ctx context.Context, name string, ch chart.Charter, vals map[string]any) (*Plan, error) {
+	chrt, err := u.upgradeChart(ch)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	currentRelease, upgradedRelease, serverSideApply, err := u.prepareUpgrade(ctx, name, chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := newPlan(currentRelease, upgradedRelease, serverSideApply)
+	if err != nil {
+		return nil, err
+	}
+	if plan.LiveDigest, err = u.cfg.liveDigest(currentRelease.Manifest); err != nil {
+		return nil, err
+	}
+	plan.DisableHooks = u.DisableHooks
+	plan.ResetValues = u.ResetValues
+	plan.ReuseValues = u.ReuseValues
+	plan.ResetThenReuseValues = u.ResetThenReuseValues
+
+	// Leave the values out of the plan. Reusing the values of the current
+	// revision also sets them as the values of the chart.
+	planned := *upgradedRelease
+	planned.Config = nil
+	if u.ReuseValues && planned.Chart != nil {
+		ch := *planned.Chart
+		ch.Values = nil
+		planned.Chart = &ch
+	}
+	plan.Planned = &planned
+	return plan, nil
+}
+
+// ApplyPlan creates the revision of a plan and applies it like Run, with the
+// wait and failure options of the upgrade. vals are the values the plan was
+// made with. The hooks are skipped when either the plan or the upgrade
+// disables them. It returns ErrPlanStale when the release or its resources
+// changed since the plan was made.
+func (u *Upgrade) ApplyPlan(ctx context.Context, plan *Plan, vals map[string]any) (ri.Releaser, error) {
+	start := time.Now()
+	rel, err := u.applyPlan(ctx, plan, vals)
+	u.cfg.Metrics.observe(OperationUpgrade, start, rel, err)
+	u.cfg.notify(ctx, OperationUpgrade, plan.Release, plan.Namespace, start, rel, err)
+	return rel, err
+}
+
+func (u *Upgrade) applyPlan(ctx context.Context, plan *Plan, vals map[string]any) (ri.Releaser, error) {
+	if plan.FormatVersion != PlanFormatVersion {
+		return nil, fmt.Errorf("unsupported plan format version %q", plan.FormatVersion)
+	}
+	if plan.Planned == nil || plan.Planned.Info == nil || plan.Planned.Chart == nil || manifestDigest(plan.Planned.Manifest) != plan.PlannedDigest {
+		return nil, errors.New("the plan is invalid: its planned manifest does not match its digest")
+	}
+	if err := u.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	lastReleasei, err := u.cfg.Releases.Last(plan.Release)
+	if err != nil {
+		return nil, err
+	}
+	lastRelease, err := releaserToV1Release(lastReleasei)
+	if err != nil {
+		return nil, err
+	}
+	if lastRelease.Info.Status.IsPending() {
+		return nil, classify(errPending, ErrStorageConflict)
+	}
+	if lastRelease.Version != plan.Planned.Version-1 {
+		return nil, fmt.Errorf("%w: it is at revision %d, the plan was made at revision %d", ErrPlanStale, lastRelease.Version, plan.Planned.Version-1)
+	}
+	currentReleasei, err := u.cfg.Releases.Get(plan.Release, plan.CurrentRevision)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPlanStale, err)
+	}
+	currentRelease, err := releaserToV1Release(currentReleasei)
+	if err != nil {
+		return nil, err
+	}
+	if manifestDigest(currentRelease.Manifest) != plan.CurrentDigest {
+		return nil, fmt.Errorf("%w: the manifest of revision %d changed", ErrPlanStale, plan.CurrentRevision)
+	}
+	liveDigest, err := u.cfg.liveDigest(currentRelease.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if liveDigest != plan.LiveDigest {
+		return nil, fmt.Errorf("%w: the resources of revision %d changed in the cluster", ErrPlanStale, plan.CurrentRevision)
+	}
+
+	planned := *plan.Planned
+	ch := *planned.Chart
+	planned.Chart = &ch
+	u.ResetValues = plan.ResetValues
+	u.ReuseValues = plan.ReuseValues
+	u.ResetThenReuseValues = plan.ResetThenReuseValues
+	if planned.Config, err = u.reuseValues(planned.Chart, currentRelease, vals); err != nil {
+		return nil, err
+	}
+	if digest, err := valuesDigest(planned.Config); err != nil {
+		return nil, err
+	} else if digest != plan.ValuesDigest {
+		return nil, errors.New("the values do not match those the plan was made with")
+	}
+	info := *plan.Planned.Info
+	info.LastDeployed = Timestamper()
+	info.Status = rcommon.StatusPendingUpgrade
+	info.Description = "Preparing upgrade" // This should be overwritten later.
+	planned.Info = &info
+	planned.Labels = plan.Labels
+	planned.Annotations = plan.Annotations
+
+	u.cfg.Releases.MaxHistory = u.MaxHistory
+	u.DisableHooks = u.DisableHooks || plan.DisableHooks
+
+	u.cfg.Logger().Debug("applying upgrade plan", "name", plan.Release, "revision", planned.Version)
+	res, err := u.performUpgrade(ctx, currentRelease, &planned, plan.ServerSideApply)
+	if err != nil {
+		return res, err
+	}
+	if !isDryRun(u.DryRunStrategy) {
+		if err := u.cfg.Releases.Update(&planned); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// newPlan returns the plan upgrading the resources of current to those of
+// upgraded.
+func newPlan(current, upgraded *release.Release, serverSideApply bool) (*Plan, error) {
+	valuesSum, err := valuesDigest(upgraded.Config)
+	if err != nil {
+		return nil, err
+	}
+	before, err := planResources(current.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the manifest of revision %d: %w", current.Version, err)
+	}
+	after, err := planResources(upgraded.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the planned manifest: %w", err)
+	}
+
+	plan := &Plan{
+		FormatVersion:   PlanFormatVersion,
+		Release:         upgraded.Name,
+		Namespace:       upgraded.Namespace,
+		CurrentRevision: current.Version,
+		CurrentDigest:   manifestDigest(current.Manifest),
+		ResourceChanges: []ResourceChange{},
+		PlannedDigest:   manifestDigest(upgraded.Manifest),
+		ValuesDigest:    valuesSum,
+		ServerSideApply: serverSideApply,
+		Planned:         upgraded,
+		Labels:          upgraded.Labels,
+		Annotations:     upgraded.Annotations,
+	}
+
+	beforeByAddress := make(map[string]planResource, len(before))
+	for _, r := range before {
+		beforeByAddress[r.Address] = r
+	}
+	afterAddresses := make(map[string]bool, len(after))
+	for _, r := range after {
+		afterAddresses[r.Address] = true
+		c := r.ResourceChange
+		c.AfterDigest = manifestDigest(r.doc)
+		b, ok := beforeByAddress[r.Address]
+		switch {
+		case !ok:
+			c.Action = PlanCreate
+			plan.Summary.Add++
+		case b.doc == r.doc:
+			c.Action = PlanNoOp
+		default:
+			c.Action = PlanUpdate
+			plan.Summary.Change++
+		}
+		if ok {
+			c.BeforeDigest = manifestDigest(b.doc)
+		}
+		if c.Diff, err = releaseutil.DiffManifests(b.doc, r.doc, c.Address, c.Address); err != nil {
+			return nil, err
+		}
+		plan.ResourceChanges = append(plan.ResourceChanges, c)
+	}
+	for _, b := range before {
+		if afterAddresses[b.Address] {
+			continue
+		}
+		c := b.ResourceChange
+		c.Action = PlanDelete
+		c.BeforeDigest = manifestDigest(b.doc)
+		if c.Diff, err = releaseutil.DiffManifests(b.doc, "", c.Address, c.Address); err != nil {
+			return nil, err
+		}
+		plan.Summary.Destroy++
+		plan.ResourceChanges = append(plan.ResourceChanges, c)
+	}
+	return plan, nil
+}
+
+// planResource is a resource of a manifest.
+type planResource struct {
+	ResourceChange
+	doc string
+}
+
+// planResources returns the resources of a manifest, in order.
+func planResources(manifest string) ([]planResource, error) {
+	objects, err := releaseutil.ParseManifestObjects(manifest)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]planResource, 0, len(objects))
+	for _, obj := range objects {
+		resources = append(resources, planResource{
+			ResourceChange: ResourceChange{
+				Address:    obj.String(),
+				APIVersion: obj.APIVersion,
+				Kind:       obj.Kind,
+				Namespace:  obj.Namespace,
+				Name:       obj.Name,
+			},
+			doc: obj.Manifest,
+		})
+	}
+	return resources, nil
+}
+
+// liveDigest returns the digest of the resource versions of the resources of
+// a manifest in the cluster. Resources that do not exist are left out.
+func (cfg *Configuration) liveDigest(manifest string) (string, error) {
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(manifest), false)
+	if err != nil {
+		return "", fmt.Errorf("unable to build kubernetes objects from the current release manifest: %w", err)
+	}
+	live, err := cfg.KubeClient.Get(resources, false)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the current resources of the release: %w", err)
+	}
+	var versions []string
+	for kind, objs := range live {
+		for _, obj := range objs {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				continue
+			}
+			versions = append(versions, fmt.Sprintf("%s %s/%s %s %s", kind, accessor.GetNamespace(), accessor.GetName(), accessor.GetUID(), accessor.GetResourceVersion()))
+		}
+	}
+	slices.Sort(versions)
+	return manifestDigest(strings.Join(versions, "\n")), nil
+}
+
+// valuesDigest returns the digest of the JSON encoding of values.
+func valuesDigest(vals map[string]any) (string, error) {
+	if len(vals) == 0 {
+		vals = map[string]any{}
+	}
+	b, err := json.Marshal(vals)
+	if err != nil {
+		return "", err
+	}
+	return manifestDigest(string(b)), nil
+}
+
+func manifestDigest(manifest string) string {
+	sum := sha256.Sum256([]byte(manifest))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,198 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+)
+
+// liveKubeClient returns a live ConfigMap with a resource version.
+type liveKubeClient struct {
+	*kubefake.FailingKubeClient
+	resourceVersion string
+}
+
+func (c *liveKubeClient) Get(_ kube.ResourceList, _ bool) (map[string][]runtime.Object, error) {
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", ResourceVersion: c.resourceVersion}}
+	return map[string][]runtime.Object{"v1/ConfigMap": {cm}}, nil
+}
+
+func configMapManifest(name, value string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\ndata:\n  key: " + value + "\n"
+}
+
+func planFixture(t *testing.T) (*Upgrade, *Plan) {
+	t.Helper()
+	return planFixtureWithValues(t, map[string]any{})
+}
+
+func planFixtureWithValues(t *testing.T, vals map[string]any) (*Upgrade, *Plan) {
+	t.Helper()
+	upAction := upgradeAction(t)
+	upAction.cfg.KubeClient = &liveKubeClient{
+		FailingKubeClient: upAction.cfg.KubeClient.(*kubefake.FailingKubeClient),
+		resourceVersion:   "1",
+	}
+	rel := releaseStub()
+	rel.Name = "planned"
+	rel.Manifest = "---\n# Source: hello/templates/a\n" + configMapManifest("a", "one") +
+		"---\n# Source: hello/templates/b\n" + configMapManifest("b", "one") +
+		"---\n# Source: hello/templates/d\n" + configMapManifest("d", "one")
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	modTime := time.Now()
+	ch := buildChartWithTemplates([]*common.File{
+		{Name: "templates/a", ModTime: modTime, Data: []byte(configMapManifest("a", "two"))},
+		{Name: "templates/c", ModTime: modTime, Data: []byte(configMapManifest("c", "one"))},
+		{Name: "templates/d", ModTime: modTime, Data: []byte(configMapManifest("d", "one"))},
+	})
+	upAction.DisableHooks = true
+	plan, err := upAction.Plan(context.Background(), rel.Name, ch, vals)
+	require.NoError(t, err)
+	return upAction, plan
+}
+
+func TestUpgradePlan(t *testing.T) {
+	upAction, plan := planFixture(t)
+
+	assert.Equal(t, "planned", plan.Release)
+	assert.Equal(t, 1, plan.CurrentRevision)
+	assert.Equal(t, 2, plan.Planned.Version)
+	assert.Equal(t, PlanSummary{Add: 1, Change: 1, Destroy: 1}, plan.Summary)
+
+	actions := map[string]PlanAction{}
+	for _, c := range plan.ResourceChanges {
+		actions[c.Name] = c.Action
+		switch c.Action {
+		case PlanCreate:
+			assert.Empty(t, c.BeforeDigest)
+			assert.NotEmpty(t, c.AfterDigest)
+		case PlanDelete:
+			assert.NotEmpty(t, c.BeforeDigest)
+			assert.Empty(t, c.AfterDigest)
+		case PlanNoOp:
+			assert.Equal(t, c.BeforeDigest, c.AfterDigest)
+			assert.Empty(t, c.Diff)
+		case PlanUpdate:
+			assert.NotEqual(t, c.BeforeDigest, c.AfterDigest)
+			assert.Contains(t, c.Diff, "-  key: one\n+  key: two\n")
+		}
+	}
+	assert.Equal(t, map[string]PlanAction{"a": PlanUpdate, "b": PlanDelete, "c": PlanCreate, "d": PlanNoOp}, actions)
+
+	// Planning does not change the storage.
+	last, err := upAction.cfg.Releases.Last("planned")
+	require.NoError(t, err)
+	lastRel, err := releaserToV1Release(last)
+	require.NoError(t, err)
+	assert.Equal(t, 1, lastRel.Version)
+}
+
+func TestUpgradeApplyPlan(t *testing.T) {
+	upAction, plan := planFixture(t)
+
+	// Plans are applied from their JSON encoding.
+	b, err := json.Marshal(plan)
+	require.NoError(t, err)
+	var decoded Plan
+	require.NoError(t, json.Unmarshal(b, &decoded))
+
+	tampered := decoded
+	planned := *decoded.Planned
+	planned.Manifest += configMapManifest("e", "one")
+	tampered.Planned = &planned
+	_, err = upAction.ApplyPlan(context.Background(), &tampered, nil)
+	assert.ErrorContains(t, err, "does not match its digest")
+
+	resi, err := upAction.ApplyPlan(context.Background(), &decoded, nil)
+	require.NoError(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Version)
+	assert.Equal(t, rcommon.StatusDeployed, res.Info.Status)
+	assert.Equal(t, plan.Planned.Manifest, res.Manifest)
+
+	// The plan cannot be applied twice.
+	_, err = upAction.ApplyPlan(context.Background(), &decoded, nil)
+	assert.ErrorIs(t, err, ErrPlanStale)
+}
+
+func TestUpgradeApplyPlanStale(t *testing.T) {
+	upAction, plan := planFixture(t)
+
+	// The current revision is changed behind the plan.
+	currenti, err := upAction.cfg.Releases.Get("planned", 1)
+	require.NoError(t, err)
+	current, err := releaserToV1Release(currenti)
+	require.NoError(t, err)
+	changed := *current
+	changed.Manifest = configMapManifest("a", "three")
+	require.NoError(t, upAction.cfg.Releases.Update(&changed))
+
+	_, err = upAction.ApplyPlan(context.Background(), plan, nil)
+	assert.ErrorIs(t, err, ErrPlanStale)
+	assert.ErrorContains(t, err, "manifest of revision 1 changed")
+
+	unsupported := *plan
+	unsupported.FormatVersion = "0"
+	_, err = upAction.ApplyPlan(context.Background(), &unsupported, nil)
+	assert.ErrorContains(t, err, "unsupported plan format version")
+}
+
+func TestUpgradeApplyPlanLiveResourcesChanged(t *testing.T) {
+	upAction, plan := planFixture(t)
+
+	upAction.cfg.KubeClient.(*liveKubeClient).resourceVersion = "2"
+	_, err := upAction.ApplyPlan(context.Background(), plan, nil)
+	assert.ErrorIs(t, err, ErrPlanStale)
+	assert.ErrorContains(t, err, "resources of revision 1 changed in the cluster")
+}
+
+func TestUpgradePlanValues(t *testing.T) {
+	vals := map[string]any{"password": "hunter2"}
+	upAction, plan := planFixtureWithValues(t, vals)
+
+	// The values are left out of the plan.
+	b, err := json.Marshal(plan)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "hunter2")
+	var decoded Plan
+	require.NoError(t, json.Unmarshal(b, &decoded))
+
+	_, err = upAction.ApplyPlan(context.Background(), &decoded, map[string]any{"password": "other"})
+	assert.ErrorContains(t, err, "the values do not match those the plan was made with")
+
+	resi, err := upAction.ApplyPlan(context.Background(), &decoded, vals)
+	require.NoError(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+	assert.Equal(t, vals, res.Config)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// Promote is the action for switching the Services of a blue/green release
+// to one of its slots.
+//
+// It provides the implementation of 'helm promote'.
+type Promote struct {
+	cfg *Configuration
+
+	// Slot is the slot the Services are switched to.
+	Slot string
+	// ForceConflicts causes server-side apply to force conflicts.
+	ForceConflicts bool
+	// DryRunStrategy can be set to prepare, but not execute the operation.
+	DryRunStrategy DryRunStrategy
+}
+
+// NewPromote creates a new Promote object with the given configuration.
+func NewPromote(cfg *Configuration) *Promote {
+	return &Promote{
+		cfg:            cfg,
+		DryRunStrategy: DryRunNone,
+	}
+}
+
+// Run switches the Services of the release that select a slot with SlotLabel
+// to the slot of the action. The Services are switched in a new revision of
+// the release, which records the slot as active.
+func (p *Promote) Run(name string) (*release.Release, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, fmt.Errorf("promote: Release name is invalid: %s", name)
+	}
+	if err := validateSlot(p.Slot); err != nil {
+		return nil, err
+	}
+	if err := p.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	lastReleasei, err := p.cfg.Releases.Last(name)
+	if err != nil {
+		return nil, err
+	}
+	currentRelease, err := releaserToV1Release(lastReleasei)
+	if err != nil {
+		return nil, err
+	}
+	if currentRelease.Info.Status.IsPending() {
+		return nil, classify(errPending, ErrStorageConflict)
+	}
+	if currentRelease.Info.Status != common.StatusDeployed {
+		return nil, fmt.Errorf("release %q is %s: only a deployed release can be promoted", name, currentRelease.Info.Status)
+	}
+	if len(currentRelease.Slots) == 0 {
+		return nil, fmt.Errorf("release %q has no slots: upgrade it with a slot first", name)
+	}
+	if _, ok := currentRelease.Slots[p.Slot]; !ok {
+		return nil, fmt.Errorf("release %q has no %s slot", name, p.Slot)
+	}
+	if currentRelease.ActiveSlot == p.Slot {
+		return nil, fmt.Errorf("slot %s of release %q is already active", p.Slot, name)
+	}
+
+	manifest, switched, err := promoteManifest(currentRelease.Manifest, p.Slot)
+	if err != nil {
+		return nil, fmt.Errorf("unable to switch the Services of release %q: %w", name, err)
+	}
+	if switched == 0 {
+		return nil, fmt.Errorf("release %q has no Service selecting a slot with the %s label", name, SlotLabel)
+	}
+
+	targetRelease := &release.Release{
+		Name:      name,
+		Namespace: currentRelease.Namespace,
+		Chart:     currentRelease.Chart,
+		Config:    currentRelease.Config,
+		Info: &release.Info{
+			FirstDeployed: currentRelease.Info.FirstDeployed,
+			LastDeployed:  Timestamper(),
+			Status:        common.StatusPendingUpgrade,
+			Notes:         currentRelease.Info.Notes,
+			Description:   fmt.Sprintf("Promote slot %s", p.Slot),
+		},
+		Version:     currentRelease.Version + 1,
+		Labels:      currentRelease.Labels,
+		Annotations: currentRelease.Annotations,
+		Manifest:    manifest,
+		Hooks:       currentRelease.Hooks,
+		ApplyMethod: currentRelease.ApplyMethod,
+		ChartSource: currentRelease.ChartSource,
+		Retention:   currentRelease.Retention,
+		Resources:   currentRelease.Resources,
+		Needs:       currentRelease.Needs,
+		Slots:       currentRelease.Slots,
+		ActiveSlot:  p.Slot,
+	}
+	if isDryRun(p.DryRunStrategy) {
+		targetRelease.Info.Description = "Dry run complete"
+		return targetRelease, nil
+	}
+
+	if err := p.cfg.Releases.Create(targetRelease); err != nil {
+		return nil, classify(err, ErrStorageConflict)
+	}
+	if err := p.switchServices(currentRelease, targetRelease); err != nil {
+		msg := fmt.Sprintf("Promote %q failed: %s", name, err)
+		p.cfg.Logger().Warn(msg)
+		targetRelease.SetStatus(common.StatusFailed, msg)
+		p.cfg.recordRelease(targetRelease)
+		return targetRelease, err
+	}
+
+	currentRelease.Info.Status = common.StatusSuperseded
+	p.cfg.recordRelease(currentRelease)
+	targetRelease.Info.Status = common.StatusDeployed
+	p.cfg.recordRelease(targetRelease)
+	return targetRelease, nil
+}
+
+// switchServices applies the manifest of the promoted release.
+func (p *Promote) switchServices(currentRelease, targetRelease *release.Release) error {
+	start := time.Now()
+	current, err := p.cfg.KubeClient.Build(bytes.NewBufferString(currentRelease.Manifest), false)
+	if err != nil {
+		return fmt.Errorf("unable to build kubernetes objects from current release manifest: %w", err)
+	}
+	target, err := p.cfg.KubeClient.Build(bytes.NewBufferString(targetRelease.Manifest), false)
+	if err != nil {
+		return fmt.Errorf("unable to build kubernetes objects from new release manifest: %w", err)
+	}
+	if err := restoreNamespaces(current, currentRelease.Resources); err != nil {
+		return err
+	}
+	if err := restoreNamespaces(target, targetRelease.Resources); err != nil {
+		return err
+	}
+	if err := target.Visit(setMetadataVisitor(targetRelease.Name, targetRelease.Namespace, true)); err != nil {
+		return fmt.Errorf("unable to set metadata visitor from target release: %w", err)
+	}
+	if err := keepSharedOwners(target); err != nil {
+		return err
+	}
+
+	serverSideApply := !isReleaseApplyMethodClientSideApply(currentRelease.ApplyMethod)
+	if _, err := p.cfg.KubeClient.Update(
+		current,
+		target,
+		kube.ClientUpdateOptionServerSideApply(serverSideApply, p.ForceConflicts)); err != nil {
+		return err
+	}
+	addPhaseTime(&targetRelease.Info.Timings.Apply, start)
+	setDeployStats(targetRelease, start)
+	return nil
+}
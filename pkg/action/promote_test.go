@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestPromote(t *testing.T) {
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	rel.Name = "bluegreen"
+	rel.Info.Status = common.StatusDeployed
+	rel.Slots = map[string]string{
+		"blue":  joinManifestDocs([]string{slotDeployment("blue")}),
+		"green": joinManifestDocs([]string{slotDeployment("green")}),
+	}
+	rel.Manifest = joinManifestDocs([]string{slotService, slotDeployment("blue"), slotDeployment("green")})
+	rel.ActiveSlot = "blue"
+	require.NoError(t, config.Releases.Create(rel))
+
+	client := NewPromote(config)
+	client.Slot = "green"
+	promoted, err := client.Run(rel.Name)
+	require.NoError(t, err)
+	assert.Equal(t, 2, promoted.Version)
+	assert.Equal(t, "green", promoted.ActiveSlot)
+	assert.Equal(t, common.StatusDeployed, promoted.Info.Status)
+	assert.Equal(t, rel.Slots, promoted.Slots)
+	service := manifestDocs(promoted.Manifest)[0]
+	assert.Contains(t, service, "helm.sh/slot: green")
+	assert.NotContains(t, service, "helm.sh/slot: blue")
+
+	previous, err := config.Releases.Get(rel.Name, 1)
+	require.NoError(t, err)
+	previousRel, err := releaserToV1Release(previous)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusSuperseded, previousRel.Info.Status)
+
+	_, err = client.Run(rel.Name)
+	assert.ErrorContains(t, err, "is already active")
+}
+
+func TestPromoteErrors(t *testing.T) {
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	rel.Name = "noslots"
+	rel.Info.Status = common.StatusDeployed
+	require.NoError(t, config.Releases.Create(rel))
+
+	client := NewPromote(config)
+	client.Slot = "green"
+	_, err := client.Run(rel.Name)
+	assert.ErrorContains(t, err, "has no slots")
+
+	client.Slot = "red"
+	_, err = client.Run(rel.Name)
+	assert.ErrorContains(t, err, `invalid slot "red"`)
+
+	rel = releaseStub()
+	rel.Name = "noservice"
+	rel.Info.Status = common.StatusDeployed
+	rel.Slots = map[string]string{"green": joinManifestDocs([]string{slotDeployment("green")})}
+	rel.Manifest = rel.Slots["green"]
+	require.NoError(t, config.Releases.Create(rel))
+	client.Slot = "green"
+	_, err = client.Run(rel.Name)
+	assert.ErrorContains(t, err, "has no Service selecting a slot")
+}
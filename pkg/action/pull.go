@@ -17,11 +17,15 @@ limitations under the License.
 package action
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"helm.sh/helm/v4/internal/fileutil"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/downloader"
@@ -43,7 +47,13 @@ type Pull struct {
 	VerifyLater bool
 	UntarDir    string
 	DestDir     string
-	cfg         *Configuration
+	// KeepArchive keeps the chart archive, and its provenance file, in
+	// DestDir when the chart is untarred.
+	KeepArchive bool
+	// RecordOrigin writes the source, archive digest and file digests of an
+	// untarred chart to chartutil.OriginFileName inside the chart directory.
+	RecordOrigin bool
+	cfg          *Configuration
 }
 
 type PullOpt func(*Pull)
@@ -73,11 +83,22 @@ func (p *Pull) SetRegistryClient(client *registry.Client) {
 func (p *Pull) Run(chartRef string) (string, error) {
 	var out strings.Builder
 
+	if p.RequireDigest {
+		d, err := ociManifestDigest(chartRef)
+		if err != nil {
+			return out.String(), err
+		}
+		if d == "" {
+			return out.String(), errDigestRequired(chartRef)
+		}
+	}
+
 	c := downloader.ChartDownloader{
-		Out:     &out,
-		Keyring: p.Keyring,
-		Verify:  downloader.VerifyNever,
-		Getters: getter.All(p.Settings),
+		Out:         &out,
+		Keyring:     p.Keyring,
+		TrustPolicy: p.Settings.TrustPolicy,
+		Verify:      downloader.VerifyNever,
+		Getters:     p.getters(p.Settings),
 		Options: []getter.Option{
 			getter.WithBasicAuth(p.Username, p.Password),
 			getter.WithPassCredentialsAll(p.PassCredentialsAll),
@@ -89,6 +110,7 @@ func (p *Pull) Run(chartRef string) (string, error) {
 		RepositoryConfig: p.Settings.RepositoryConfig,
 		RepositoryCache:  p.Settings.RepositoryCache,
 		ContentCache:     p.Settings.ContentCache,
+		Channel:          p.Channel,
 	}
 
 	if registry.IsOCI(chartRef) {
@@ -120,8 +142,9 @@ func (p *Pull) Run(chartRef string) (string, error) {
 		chartURL, err := repo.FindChartInRepoURL(
 			p.RepoURL,
 			chartRef,
-			getter.All(p.Settings),
+			p.getters(p.Settings),
 			repo.WithChartVersion(p.Version),
+			repo.WithChartChannel(p.Channel),
 			repo.WithClientTLS(p.CertFile, p.KeyFile, p.CaFile),
 			repo.WithUsernamePassword(p.Username, p.Password),
 			repo.WithInsecureSkipTLSVerify(p.InsecureSkipTLSVerify),
@@ -169,7 +192,43 @@ func (p *Pull) Run(chartRef string) (string, error) {
 			return out.String(), fmt.Errorf("failed to untar: a file or directory with the name %s already exists", udCheck)
 		}
 
-		return out.String(), chartutil.ExpandFile(ud, saved)
+		if err := chartutil.ExpandFile(ud, saved); err != nil {
+			return out.String(), err
+		}
+
+		if p.RecordOrigin {
+			origin, err := chartutil.NewOriginFromArchive(saved, downloadSourceRef)
+			if err != nil {
+				return out.String(), fmt.Errorf("failed to record origin: %w", err)
+			}
+			if err := chartutil.WriteOrigin(filepath.Join(ud, origin.Name), origin); err != nil {
+				return out.String(), fmt.Errorf("failed to record origin: %w", err)
+			}
+		}
+
+		if p.KeepArchive {
+			if err := keepArchive(saved, p.DestDir); err != nil {
+				return out.String(), fmt.Errorf("failed to keep archive: %w", err)
+			}
+		}
 	}
 	return out.String(), nil
 }
+
+// keepArchive copies the chart archive, and its provenance file if one was
+// downloaded, into dir.
+func keepArchive(archive, dir string) error {
+	for _, src := range []string{archive, archive + ".prov"} {
+		data, err := os.ReadFile(src)
+		if errors.Is(err, fs.ErrNotExist) && src != archive {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fileutil.AtomicWriteFile(filepath.Join(dir, filepath.Base(src)), bytes.NewReader(data), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
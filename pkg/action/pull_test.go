@@ -20,12 +20,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli"
+	getterfake "helm.sh/helm/v4/pkg/getter/fake"
 	"helm.sh/helm/v4/pkg/registry"
 )
 
@@ -63,6 +67,41 @@ func TestPullRun_ChartNotFound(t *testing.T) {
 	require.ErrorContains(t, err, "404 Not Found")
 }
 
+func TestPullRun_Getters(t *testing.T) {
+	path, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "nginx", Version: "0.1.0"},
+	}, t.TempDir())
+	require.NoError(t, err)
+	archive, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	getters := &getterfake.Getter{}
+	getters.Serve("https://charts.example.com/index.yaml", []byte(`apiVersion: v1
+entries:
+  nginx:
+  - apiVersion: v2
+    name: nginx
+    version: 0.1.0
+    urls:
+    - nginx-0.1.0.tgz
+`))
+	getters.Serve("https://charts.example.com/nginx-0.1.0.tgz", archive)
+
+	config := actionConfigFixture(t)
+	client := NewPull(WithConfig(config))
+	client.Settings = cli.New()
+	client.Settings.RepositoryCache = t.TempDir()
+	client.Settings.ContentCache = t.TempDir()
+	client.Getters = getters.Providers()
+	client.RepoURL = "https://charts.example.com"
+	client.DestDir = t.TempDir()
+
+	_, err = client.Run("nginx")
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(client.DestDir, "nginx-0.1.0.tgz"))
+	assert.Equal(t, []string{"https://charts.example.com/index.yaml", "https://charts.example.com/nginx-0.1.0.tgz"}, getters.Requested())
+}
+
 func startLocalServerForTests(t *testing.T, handler http.Handler) (*httptest.Server, error) {
 	t.Helper()
 	if handler == nil {
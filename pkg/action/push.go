@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/pusher"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/uploader"
@@ -38,6 +39,11 @@ type Push struct {
 	insecureSkipTLSVerify bool
 	plainHTTP             bool
 	out                   io.Writer
+	force                 bool
+	digestOnly            bool
+	annotations           map[string]string
+	chunkSize             int64
+	resume                bool
 }
 
 // PushOpt is a type of function that sets options for a push action.
@@ -80,6 +86,46 @@ func WithPushOptWriter(out io.Writer) PushOpt {
 	}
 }
 
+// WithPushForce allows overwriting a tag that already refers to a different
+// chart in the registry.
+func WithPushForce(force bool) PushOpt {
+	return func(p *Push) {
+		p.force = force
+	}
+}
+
+// WithPushDigestOnly pushes the chart to the registry without tagging it.
+func WithPushDigestOnly(digestOnly bool) PushOpt {
+	return func(p *Push) {
+		p.digestOnly = digestOnly
+	}
+}
+
+// WithPushAnnotations adds annotations to the manifest of the pushed chart.
+func WithPushAnnotations(annotations map[string]string) PushOpt {
+	return func(p *Push) {
+		p.annotations = annotations
+	}
+}
+
+// WithPushChunkSize uploads the chart to the registry in chunks of the given
+// size, so that a chunk that failed to upload is sent again from the last
+// byte the registry received.
+func WithPushChunkSize(size int64) PushOpt {
+	return func(p *Push) {
+		p.chunkSize = size
+	}
+}
+
+// WithPushResume makes the upload of the chart to the registry resumable, so
+// that pushing a chart again after an interrupted push continues the upload
+// where it stopped.
+func WithPushResume(resume bool) PushOpt {
+	return func(p *Push) {
+		p.resume = resume
+	}
+}
+
 // NewPushWithOpts creates a new push, with configuration options.
 func NewPushWithOpts(opts ...PushOpt) *Push {
 	p := &Push{}
@@ -90,6 +136,9 @@ func NewPushWithOpts(opts ...PushOpt) *Push {
 }
 
 // Run executes 'helm push' against the given chart archive.
+//
+// Pushing to a registry fails with an error matching registry.ErrTagExists
+// when the tag already refers to a different chart, unless force is set.
 func (p *Push) Run(chartRef string, remote string) (string, error) {
 	var out strings.Builder
 
@@ -100,8 +149,15 @@ func (p *Push) Run(chartRef string, remote string) (string, error) {
 			pusher.WithTLSClientConfig(p.certFile, p.keyFile, p.caFile),
 			pusher.WithInsecureSkipTLSVerify(p.insecureSkipTLSVerify),
 			pusher.WithPlainHTTP(p.plainHTTP),
+			pusher.WithAnnotations(p.annotations),
+			pusher.WithImmutableTag(!p.force),
+			pusher.WithDigestOnly(p.digestOnly),
+			pusher.WithChunkSize(p.chunkSize),
 		},
 	}
+	if p.resume {
+		c.Options = append(c.Options, pusher.WithResumeDir(helmpath.CachePath("registry", "uploads")))
+	}
 
 	if registry.IsOCI(remote) {
 		// Don't use the default registry client if tls options are set.
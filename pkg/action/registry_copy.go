@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// RegistryCopy performs a registry copy operation.
+//
+// It provides the implementation of 'helm registry copy'.
+type RegistryCopy struct {
+	cfg   *Configuration
+	force bool
+}
+
+// RegistryCopyOpt is a type of function that sets options for a registry
+// copy action.
+type RegistryCopyOpt func(*RegistryCopy) error
+
+// WithCopyForce specifies whether the destination tag is overwritten when it
+// already refers to a different chart.
+func WithCopyForce(force bool) RegistryCopyOpt {
+	return func(r *RegistryCopy) error {
+		r.force = force
+		return nil
+	}
+}
+
+// NewRegistryCopy creates a new RegistryCopy object with the given configuration.
+func NewRegistryCopy(cfg *Configuration) *RegistryCopy {
+	return &RegistryCopy{
+		cfg: cfg,
+	}
+}
+
+// Run executes the registry copy operation, copying the chart at src and the
+// artifacts attached to it to dst. Both are OCI references.
+func (a *RegistryCopy) Run(_ io.Writer, src, dst string, opts ...RegistryCopyOpt) (*registry.CopyResult, error) {
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, err
+		}
+	}
+	if !registry.IsOCI(src) || !registry.IsOCI(dst) {
+		return nil, errors.New("source and destination must be OCI references, such as oci://registry.example.com/charts/mychart:1.2.3")
+	}
+	if a.cfg.RegistryClient == nil {
+		return nil, errors.New("missing registry client")
+	}
+
+	return a.cfg.RegistryClient.Copy(
+		strings.TrimPrefix(src, registry.OCIScheme+"://"),
+		strings.TrimPrefix(dst, registry.OCIScheme+"://"),
+		registry.CopyOptImmutableTag(!a.force),
+	)
+}
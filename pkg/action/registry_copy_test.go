@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistryCopy(t *testing.T) {
+	config := actionConfigFixture(t)
+	client := NewRegistryCopy(config)
+
+	assert.NotNil(t, client)
+	assert.Equal(t, config, client.cfg)
+
+	require.NoError(t, WithCopyForce(true)(client))
+	assert.True(t, client.force)
+}
+
+func TestRegistryCopyRequiresOCI(t *testing.T) {
+	client := NewRegistryCopy(actionConfigFixture(t))
+
+	_, err := client.Run(io.Discard, "https://example.com/charts/mychart-1.2.3.tgz", "oci://registry.example.com/charts/mychart:1.2.3")
+	assert.ErrorContains(t, err, "must be OCI references")
+	_, err = client.Run(io.Discard, "oci://registry.example.com/charts/mychart:1.2.3", "registry.example.com/charts/mychart:1.2.3")
+	assert.ErrorContains(t, err, "must be OCI references")
+}
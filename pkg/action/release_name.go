@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// ErrReleaseNameInUse indicates that a release with the given name already
+// exists in the namespace.
+var ErrReleaseNameInUse = errors.New("cannot reuse a name that is still in use")
+
+// ValidateReleaseName checks whether name can be used for a new release in
+// namespace, applying the same rules as 'helm install':
+//
+//   - the name must be a valid DNS-1123 subdomain
+//   - the name must not be longer than 53 characters, which leaves room for
+//     resource name suffixes within the 63 character DNS-1123/DNS-1035 label
+//     limit, and for the revision suffix of the storage objects
+//   - no release with the name may exist in the namespace, including
+//     uninstalled releases whose history was kept
+//
+// The releases of namespace are looked up with ForNamespace when it is set,
+// otherwise in the storage of the configuration, which must then be bound to
+// namespace or to all namespaces. An empty namespace matches releases in any
+// namespace the storage driver can see. The returned error wraps
+// ErrReleaseNameInUse and ErrStorageConflict if the name is taken.
+func (cfg *Configuration) ValidateReleaseName(name, namespace string) error {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return fmt.Errorf("release name %q: %w", name, err)
+	}
+
+	nc := cfg
+	if namespace != "" && cfg.ForNamespace != nil {
+		var err error
+		if nc, err = cfg.ForNamespace(namespace); err != nil {
+			return fmt.Errorf("unable to look up the releases of namespace %q: %w", namespace, err)
+		}
+	}
+	h, err := nc.Releases.History(name)
+	if errors.Is(err, driver.ErrReleaseNotFound) || (err == nil && len(h) < 1) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to look up release %q: %w", name, err)
+	}
+	rels, err := releaseListToV1List(h)
+	if err != nil {
+		return err
+	}
+	for _, rel := range rels {
+		if namespace == "" || rel.Namespace == namespace {
+			return classify(fmt.Errorf("release name %q in namespace %q: %w", name, rel.Namespace, ErrReleaseNameInUse), ErrStorageConflict)
+		}
+	}
+	return nil
+}
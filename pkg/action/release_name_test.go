@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestValidateReleaseName(t *testing.T) {
+	config := actionConfigFixture(t)
+
+	rel := releaseStub()
+	rel.Name = "existing"
+	rel.Namespace = "default"
+	rel.Info.Status = rcommon.StatusUninstalled
+	require.NoError(t, config.Releases.Create(rel))
+
+	tests := []struct {
+		name      string
+		release   string
+		namespace string
+		wantErr   string
+		inUse     bool
+	}{
+		{name: "valid", release: "my-release", namespace: "default"},
+		{name: "empty", release: "", namespace: "default", wantErr: "no name provided"},
+		{name: "uppercase", release: "MyRelease", namespace: "default", wantErr: "invalid release name"},
+		{name: "too long", release: strings.Repeat("a", 54), namespace: "default", wantErr: "invalid release name"},
+		{name: "in use", release: "existing", namespace: "default", wantErr: "still in use", inUse: true},
+		{name: "in use in any namespace", release: "existing", namespace: "", wantErr: "still in use", inUse: true},
+		{name: "other namespace", release: "existing", namespace: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := config.ValidateReleaseName(tt.release, tt.namespace)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, tt.inUse, errors.Is(err, ErrReleaseNameInUse))
+		})
+	}
+}
+
+func TestValidateReleaseNameForNamespace(t *testing.T) {
+	config := actionConfigFixture(t)
+	other := actionConfigFixture(t)
+
+	rel := releaseStub()
+	rel.Name = "existing"
+	rel.Namespace = "other"
+	require.NoError(t, other.Releases.Create(rel))
+
+	config.ForNamespace = func(namespace string) (*Configuration, error) {
+		assert.Equal(t, "other", namespace)
+		return other, nil
+	}
+	err := config.ValidateReleaseName("existing", "other")
+	assert.ErrorIs(t, err, ErrReleaseNameInUse)
+	assert.NoError(t, config.ValidateReleaseName("unused", "other"))
+}
+
+// queryErrorDriver is a storage driver whose queries fail.
+type queryErrorDriver struct {
+	driver.Driver
+	err error
+}
+
+func (d *queryErrorDriver) Query(map[string]string) ([]release.Releaser, error) {
+	return nil, d.err
+}
+
+func TestValidateReleaseNameStorageError(t *testing.T) {
+	config := actionConfigFixture(t)
+	errForbidden := errors.New("secrets is forbidden")
+	config.Releases = storage.Init(&queryErrorDriver{Driver: driver.NewMemory(), err: errForbidden})
+
+	err := config.ValidateReleaseName("my-release", "default")
+	assert.ErrorIs(t, err, errForbidden)
+	assert.False(t, errors.Is(err, ErrReleaseNameInUse))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// RetryClass is a class of errors an operation can be retried on.
+type RetryClass string
+
+const (
+	// RetryAPIServer retries on transient errors of the Kubernetes API
+	// server: timeouts, throttling, unavailability and dropped connections.
+	RetryAPIServer RetryClass = "api-server"
+	// RetryWebhook retries when the API server failed to call an admission
+	// webhook, e.g. because the webhook is still starting.
+	RetryWebhook RetryClass = "webhook"
+	// RetryConflict retries when a resource conflicts with an object in the
+	// cluster, e.g. because it was modified concurrently.
+	RetryConflict RetryClass = "conflict"
+)
+
+// RetryClasses lists the supported retry classes.
+var RetryClasses = []RetryClass{RetryAPIServer, RetryWebhook, RetryConflict}
+
+const (
+	defaultRetryBackoff    = 2 * time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy configures how the resources of a release are re-applied after
+// a transient failure. The zero value disables retries.
+//
+// Retries are idempotent with server-side apply. With client-side apply, the
+// retry of an install may fail on the resources created by a previous attempt.
+type RetryPolicy struct {
+	// Retries is the number of times the resources are re-applied after the
+	// first attempt failed.
+	Retries int
+	// Backoff is the delay before the first retry. It doubles after each
+	// retry, up to MaxBackoff. Defaults to 2 seconds.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries. Defaults to 30 seconds.
+	MaxBackoff time.Duration
+	// On lists the classes of errors that are retried. Defaults to
+	// RetryAPIServer and RetryWebhook.
+	On []RetryClass
+}
+
+// ParseRetryClass parses the name of a retry class.
+func ParseRetryClass(s string) (RetryClass, error) {
+	c := RetryClass(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(RetryClasses, c) {
+		return "", fmt.Errorf("invalid retry class %q: must be one of %s", s, strings.Join(RetryClassNames(), ", "))
+	}
+	return c, nil
+}
+
+// RetryClassNames returns the names of the supported retry classes.
+func RetryClassNames() []string {
+	names := make([]string, len(RetryClasses))
+	for i, c := range RetryClasses {
+		names[i] = string(c)
+	}
+	return names
+}
+
+// Retryable reports whether err belongs to one of the classes retried by the policy.
+func (p RetryPolicy) Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	on := p.On
+	if len(on) == 0 {
+		on = []RetryClass{RetryAPIServer, RetryWebhook}
+	}
+	for _, c := range on {
+		if retryClassMatches(c, err) {
+			return true
+		}
+	}
+	return false
+}
+
+func retryClassMatches(c RetryClass, err error) bool {
+	switch c {
+	case RetryAPIServer:
+		return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+			apierrors.IsServiceUnavailable(err) || apierrors.IsUnexpectedServerError(err) ||
+			utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+	case RetryWebhook:
+		return strings.Contains(err.Error(), "failed calling webhook")
+	case RetryConflict:
+		return errors.Is(err, ErrApplyConflict) || apierrors.IsConflict(err)
+	}
+	return false
+}
+
+// do runs op until it succeeds, fails with an error that is not retryable or
+// the retries of the policy are exhausted. No retry is started after the
+// deadline, unless it is zero. It returns the last error of op.
+func (p RetryPolicy) do(logger *slog.Logger, deadline time.Time, op func() error) error {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+
+	err := op()
+	for retry := 1; retry <= p.Retries && p.Retryable(err); retry++ {
+		if !deadline.IsZero() && time.Now().Add(backoff).After(deadline) {
+			logger.Warn("not retrying after a transient error, as the deadline would pass", "retry", retry, "retries", p.Retries, slog.Any("error", err))
+			break
+		}
+		logger.Warn("retrying after a transient error", "retry", retry, "retries", p.Retries, "backoff", backoff, slog.Any("error", err))
+		retrySleep(backoff)
+		backoff = min(2*backoff, maxBackoff)
+		err = op()
+	}
+	return err
+}
+
+// retrySleep is replaced in tests to not wait between retries.
+var retrySleep = time.Sleep
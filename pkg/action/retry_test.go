@@ -0,0 +1,215 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+)
+
+// flakyKubeClient fails the first calls of Create and Update with err.
+type flakyKubeClient struct {
+	*kubefake.FailingKubeClient
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyKubeClient) Create(resources kube.ResourceList, options ...kube.ClientCreateOption) (*kube.Result, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return f.FailingKubeClient.Create(resources, options...)
+}
+
+func (f *flakyKubeClient) Update(original, target kube.ResourceList, options ...kube.ClientUpdateOption) (*kube.Result, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return &kube.Result{}, f.err
+	}
+	return f.FailingKubeClient.Update(original, target, options...)
+}
+
+func noRetrySleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var sleeps []time.Duration
+	retrySleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	t.Cleanup(func() { retrySleep = time.Sleep })
+	return &sleeps
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	gr := schema.GroupResource{Resource: "deployments"}
+	webhook := apierrors.NewInternalError(errors.New(`failed calling webhook "validate.example.com": connection refused`))
+	conflict := apierrors.NewConflict(gr, "foo", errors.New("the object has been modified"))
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		err    error
+		want   bool
+	}{
+		{"nil", RetryPolicy{}, nil, false},
+		{"throttled", RetryPolicy{}, apierrors.NewTooManyRequests("slow down", 1), true},
+		{"server timeout", RetryPolicy{}, fmt.Errorf("apply: %w", apierrors.NewServerTimeout(gr, "create", 1)), true},
+		{"webhook", RetryPolicy{}, webhook, true},
+		{"webhook not selected", RetryPolicy{On: []RetryClass{RetryAPIServer}}, webhook, false},
+		{"conflict by default", RetryPolicy{}, conflict, false},
+		{"conflict", RetryPolicy{On: []RetryClass{RetryConflict}}, conflict, true},
+		{"invalid", RetryPolicy{On: RetryClasses}, apierrors.NewBadRequest("bad"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Retryable(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	sleeps := noRetrySleep(t)
+	p := RetryPolicy{Retries: 4, Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	transient := apierrors.NewServiceUnavailable("unavailable")
+
+	calls := 0
+	err := p.do(actionConfigFixture(t).Logger(), time.Time{}, func() error {
+		calls++
+		return transient
+	})
+	assert.Equal(t, transient, err)
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, *sleeps)
+}
+
+func TestRetryPolicyDeadline(t *testing.T) {
+	sleeps := noRetrySleep(t)
+	p := RetryPolicy{Retries: 4, Backoff: time.Minute, MaxBackoff: time.Hour}
+	transient := apierrors.NewServiceUnavailable("unavailable")
+
+	calls := 0
+	err := p.do(actionConfigFixture(t).Logger(), time.Now().Add(90*time.Second), func() error {
+		calls++
+		return transient
+	})
+	assert.Equal(t, transient, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []time.Duration{time.Minute}, *sleeps)
+}
+
+func TestParseRetryClass(t *testing.T) {
+	c, err := ParseRetryClass(" Webhook ")
+	require.NoError(t, err)
+	assert.Equal(t, RetryWebhook, c)
+
+	_, err = ParseRetryClass("everything")
+	assert.ErrorContains(t, err, `invalid retry class "everything"`)
+}
+
+func TestInstallRetry(t *testing.T) {
+	noRetrySleep(t)
+	transient := apierrors.NewTooManyRequests("slow down", 1)
+
+	instAction := installActionWithConfig(actionConfigFixtureWithDummyResources(t, createDummyResourceList(false)))
+	instAction.TakeOwnership = true
+	flaky := &flakyKubeClient{FailingKubeClient: instAction.cfg.KubeClient.(*kubefake.FailingKubeClient), failures: 2, err: transient}
+	instAction.cfg.KubeClient = flaky
+	instAction.DisableHooks = true
+	instAction.Retry = RetryPolicy{Retries: 2}
+	_, err := instAction.Run(buildChart(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, flaky.calls)
+
+	instAction = installActionWithConfig(actionConfigFixtureWithDummyResources(t, createDummyResourceList(false)))
+	instAction.TakeOwnership = true
+	flaky = &flakyKubeClient{FailingKubeClient: instAction.cfg.KubeClient.(*kubefake.FailingKubeClient), failures: 2, err: transient}
+	instAction.cfg.KubeClient = flaky
+	instAction.DisableHooks = true
+	instAction.Retry = RetryPolicy{Retries: 1}
+	_, err = instAction.Run(buildChart(), nil)
+	assert.Error(t, err)
+	assert.Equal(t, 2, flaky.calls)
+}
+
+// partialCreateKubeClient fails the first Create after creating the first
+// resource, and records the originals passed to Update.
+type partialCreateKubeClient struct {
+	*kubefake.FailingKubeClient
+	err       error
+	creates   int
+	originals []kube.ResourceList
+}
+
+func (p *partialCreateKubeClient) Create(resources kube.ResourceList, options ...kube.ClientCreateOption) (*kube.Result, error) {
+	p.creates++
+	if p.creates == 1 {
+		return &kube.Result{Created: resources[:1]}, p.err
+	}
+	return p.FailingKubeClient.Create(resources, options...)
+}
+
+func (p *partialCreateKubeClient) Update(original, target kube.ResourceList, options ...kube.ClientUpdateOption) (*kube.Result, error) {
+	p.originals = append(p.originals, original)
+	return p.FailingKubeClient.Update(original, target, options...)
+}
+
+func TestInstallRetryUpdatesCreatedResources(t *testing.T) {
+	noRetrySleep(t)
+	resources := kube.ResourceList{newMissingDeployment("first", "spaced"), newMissingDeployment("second", "spaced")}
+	instAction := installActionWithConfig(actionConfigFixtureWithDummyResources(t, resources))
+	partial := &partialCreateKubeClient{
+		FailingKubeClient: instAction.cfg.KubeClient.(*kubefake.FailingKubeClient),
+		err:               apierrors.NewTooManyRequests("slow down", 1),
+	}
+	instAction.cfg.KubeClient = partial
+	instAction.DisableHooks = true
+	instAction.Retry = RetryPolicy{Retries: 1}
+	_, err := instAction.Run(buildChart(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, partial.creates, "the retry must not create the resources again")
+	require.Len(t, partial.originals, 1)
+	assert.Len(t, partial.originals[0], 1, "the retry must update the resources created by the failed attempt")
+}
+
+func TestUpgradeRetry(t *testing.T) {
+	noRetrySleep(t)
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "flaky"
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	flaky := &flakyKubeClient{
+		FailingKubeClient: upAction.cfg.KubeClient.(*kubefake.FailingKubeClient),
+		failures:          1,
+		err:               apierrors.NewInternalError(errors.New(`failed calling webhook "validate.example.com"`)),
+	}
+	upAction.cfg.KubeClient = flaky
+	upAction.DisableHooks = true
+	upAction.Retry = RetryPolicy{Retries: 1}
+	_, err := upAction.Run(rel.Name, buildChart(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, flaky.calls)
+}
@@ -18,6 +18,7 @@ package action
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
@@ -59,6 +61,10 @@ type Rollback struct {
 	ServerSideApply string
 	CleanupOnFail   bool
 	MaxHistory      int // MaxHistory limits the maximum number of revisions saved per release
+	// Retention, when set, is recorded on the new revision and limits the
+	// history of the release in this and all later operations. Otherwise the
+	// retention of the current revision is carried over.
+	Retention *release.Retention
 }
 
 // NewRollback creates a new Rollback object with the given configuration.
@@ -72,8 +78,20 @@ func NewRollback(cfg *Configuration) *Rollback {
 
 // Run executes 'helm rollback' against the given release.
 func (r *Rollback) Run(name string) error {
+	start := time.Now()
+	rel, err := r.run(name)
+	if !isDryRun(r.DryRunStrategy) {
+		r.cfg.Metrics.observe(OperationRollback, start, rel, err)
+		r.cfg.notify(context.Background(), OperationRollback, name, "", start, rel, err)
+	}
+	return err
+}
+
+// run rolls the release back, and returns the rolled back release when it
+// was prepared.
+func (r *Rollback) run(name string) (*release.Release, error) {
 	if err := r.cfg.KubeClient.IsReachable(); err != nil {
-		return err
+		return nil, err
 	}
 
 	r.cfg.Releases.MaxHistory = r.MaxHistory
@@ -81,28 +99,66 @@ func (r *Rollback) Run(name string) error {
 	r.cfg.Logger().Debug("preparing rollback", "name", name)
 	currentRelease, targetRelease, serverSideApply, err := r.prepareRollback(name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !isDryRun(r.DryRunStrategy) {
 		r.cfg.Logger().Debug("creating rolled back release", "name", name)
 		if err := r.cfg.Releases.Create(targetRelease); err != nil {
-			return err
+			return targetRelease, err
 		}
 	}
 
 	r.cfg.Logger().Debug("performing rollback", "name", name)
 	if _, err := r.performRollback(currentRelease, targetRelease, serverSideApply); err != nil {
-		return err
+		return targetRelease, err
 	}
 
 	if !isDryRun(r.DryRunStrategy) {
 		r.cfg.Logger().Debug("updating status for rolled back release", "name", name)
 		if err := r.cfg.Releases.Update(targetRelease); err != nil {
-			return err
+			return targetRelease, err
 		}
 	}
-	return nil
+	return targetRelease, nil
+}
+
+// RollbackDiff describes the changes a rollback would make to a release.
+type RollbackDiff struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// CurrentRevision is the revision the release is at.
+	CurrentRevision int `json:"currentRevision"`
+	// TargetRevision is the revision the release would be rolled back to.
+	TargetRevision int `json:"targetRevision"`
+	// Diff is a unified diff from the current manifest to the manifest of
+	// the target revision. It is empty when the manifests are equal.
+	Diff string `json:"diff"`
+}
+
+// Diff renders what a rollback of the release would apply and compares it
+// with the manifest of the current revision. Neither the storage nor the
+// cluster is modified.
+func (r *Rollback) Diff(name string) (*RollbackDiff, error) {
+	currentRelease, targetRelease, _, err := r.prepareRollback(name)
+	if err != nil {
+		return nil, err
+	}
+
+	target := targetRelease.Info.RollbackRevision
+	diff, err := releaseutil.DiffManifests(currentRelease.Manifest, targetRelease.Manifest,
+		fmt.Sprintf("%s (revision %d)", name, currentRelease.Version),
+		fmt.Sprintf("%s (revision %d)", name, target))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RollbackDiff{
+		Name:            name,
+		CurrentRevision: currentRelease.Version,
+		TargetRevision:  target,
+		Diff:            diff,
+	}, nil
 }
 
 // prepareRollback finds the previous release and prepares a new release object with
@@ -187,9 +243,16 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 		},
 		Version:     currentRelease.Version + 1,
 		Labels:      previousRelease.Labels,
+		Annotations: previousRelease.Annotations,
 		Manifest:    previousRelease.Manifest,
 		Hooks:       previousRelease.Hooks,
 		ApplyMethod: string(determineReleaseSSApplyMethod(serverSideApply)),
+		ChartSource: previousRelease.ChartSource,
+		Retention:   retentionFor(r.Retention, currentRelease),
+		Resources:   previousRelease.Resources,
+		Needs:       previousRelease.Needs,
+		Slots:       previousRelease.Slots,
+		ActiveSlot:  previousRelease.ActiveSlot,
 	}
 
 	return currentRelease, targetRelease, serverSideApply, nil
@@ -200,6 +263,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		r.cfg.Logger().Debug("dry run", "name", targetRelease.Name)
 		return targetRelease, nil
 	}
+	start := time.Now()
 
 	current, err := r.cfg.KubeClient.Build(bytes.NewBufferString(currentRelease.Manifest), false)
 	if err != nil {
@@ -209,13 +273,21 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	if err != nil {
 		return targetRelease, fmt.Errorf("unable to build kubernetes objects from new release manifest: %w", err)
 	}
+	if err := restoreNamespaces(current, currentRelease.Resources); err != nil {
+		return targetRelease, err
+	}
+	if err := restoreNamespaces(target, targetRelease.Resources); err != nil {
+		return targetRelease, err
+	}
 
 	// pre-rollback hooks
 
 	if !r.DisableHooks {
+		phaseStart := time.Now()
 		if err := r.cfg.execHook(targetRelease, release.HookPreRollback, r.WaitStrategy, r.WaitOptions, r.Timeout, serverSideApply); err != nil {
 			return targetRelease, err
 		}
+		addPhaseTime(&targetRelease.Info.Timings.Hooks, phaseStart)
 	} else {
 		r.cfg.Logger().Debug("rollback hooks disabled", "name", targetRelease.Name)
 	}
@@ -225,6 +297,10 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	if err != nil {
 		return targetRelease, fmt.Errorf("unable to set metadata visitor from target release: %w", err)
 	}
+	if err := keepSharedOwners(target); err != nil {
+		return targetRelease, err
+	}
+	phaseStart := time.Now()
 	results, err := r.cfg.KubeClient.Update(
 		current,
 		target,
@@ -253,6 +329,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		}
 		return targetRelease, err
 	}
+	addPhaseTime(&targetRelease.Info.Timings.Apply, phaseStart)
 
 	var waiter kube.Waiter
 	if c, supportsOptions := r.cfg.KubeClient.(kube.InterfaceWaitOptions); supportsOptions {
@@ -263,6 +340,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	if err != nil {
 		return nil, fmt.Errorf("unable to get waiter: %w", err)
 	}
+	phaseStart = time.Now()
 	if r.WaitForJobs {
 		if err := waiter.WaitWithJobs(target, r.Timeout); err != nil {
 			targetRelease.SetStatus(common.StatusFailed, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
@@ -279,11 +357,15 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		}
 	}
 
+	addPhaseTime(&targetRelease.Info.Timings.Wait, phaseStart)
+
 	// post-rollback hooks
 	if !r.DisableHooks {
+		phaseStart = time.Now()
 		if err := r.cfg.execHook(targetRelease, release.HookPostRollback, r.WaitStrategy, r.WaitOptions, r.Timeout, serverSideApply); err != nil {
 			return targetRelease, err
 		}
+		addPhaseTime(&targetRelease.Info.Timings.Hooks, phaseStart)
 	}
 
 	deployed, err := r.cfg.Releases.DeployedAll(currentRelease.Name)
@@ -302,6 +384,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	}
 
 	targetRelease.Info.Status = common.StatusDeployed
+	setDeployStats(targetRelease, start)
 
 	return targetRelease, nil
 }
@@ -131,3 +131,34 @@ func TestRollbackRevisionZeroForNonRollback(t *testing.T) {
 
 	assert.Equal(t, 0, r.Info.RollbackRevision)
 }
+
+func TestRollbackDiff(t *testing.T) {
+	config := actionConfigFixture(t)
+
+	rel1 := releaseStub()
+	rel1.Name = "diff-rollback"
+	rel1.Info.Status = "superseded"
+	rel1.Manifest = "kind: ConfigMap\ndata:\n  drink: tea\n"
+	require.NoError(t, config.Releases.Create(rel1))
+
+	rel2 := releaseStub()
+	rel2.Name = "diff-rollback"
+	rel2.Version = 2
+	rel2.Info.Status = "deployed"
+	rel2.Manifest = "kind: ConfigMap\ndata:\n  drink: coffee\n"
+	require.NoError(t, config.Releases.Create(rel2))
+
+	client := NewRollback(config)
+	diff, err := client.Diff(rel1.Name)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, diff.CurrentRevision)
+	assert.Equal(t, 1, diff.TargetRevision)
+	assert.Contains(t, diff.Diff, "-  drink: coffee\n")
+	assert.Contains(t, diff.Diff, "+  drink: tea\n")
+
+	// The storage is not modified.
+	history, err := config.Releases.History(rel1.Name)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+}
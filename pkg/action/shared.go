@@ -0,0 +1,210 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/util/retry"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// helmSharedOwnersAnnotation lists the releases that share the ownership of a
+// cluster-scoped resource, as comma separated "namespace/name" pairs. The
+// resource is only deleted when the last of them is uninstalled.
+const helmSharedOwnersAnnotation = "meta.helm.sh/release-owners"
+
+// releaseOwner returns how a release is listed in helmSharedOwnersAnnotation.
+func releaseOwner(releaseName, releaseNamespace string) string {
+	return releaseNamespace + "/" + releaseName
+}
+
+// sharedOwners returns the releases listed in helmSharedOwnersAnnotation.
+func sharedOwners(annos map[string]string) []string {
+	var owners []string
+	for o := range strings.SplitSeq(annos[helmSharedOwnersAnnotation], ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			owners = append(owners, o)
+		}
+	}
+	return owners
+}
+
+// helmOwners returns the releases that own a resource managed by Helm, and
+// false if the resource is not managed by Helm.
+func helmOwners(obj runtime.Object) ([]string, bool) {
+	lbls, err := accessor.Labels(obj)
+	if err != nil || lbls[appManagedByLabel] != appManagedByHelm {
+		return nil, false
+	}
+	annos, err := accessor.Annotations(obj)
+	if err != nil {
+		return nil, false
+	}
+	owners := sharedOwners(annos)
+	if len(owners) == 0 && annos[helmReleaseNameAnnotation] != "" {
+		owners = []string{releaseOwner(annos[helmReleaseNameAnnotation], annos[helmReleaseNamespaceAnnotation])}
+	}
+	return owners, len(owners) > 0
+}
+
+// shareOwnership adds a release to the owners of an existing cluster-scoped
+// resource, when sharing it is allowed. Otherwise a conflict error is returned.
+func shareOwnership(info *resource.Info, owners []string, releaseName, releaseNamespace string, share bool) error {
+	if !share {
+		return fmt.Errorf("cluster-scoped %s %q is owned by release(s) %s, and cannot be owned by release %s as well. Set --share-cluster-scoped to share it between these releases",
+			resourceGVK(info).Kind, info.Name, strings.Join(owners, ", "), releaseOwner(releaseName, releaseNamespace))
+	}
+	owners = append(slices.Clone(owners), releaseOwner(releaseName, releaseNamespace))
+	slices.Sort(owners)
+	return mergeAnnotations(info.Object, map[string]string{
+		helmSharedOwnersAnnotation: strings.Join(slices.Compact(owners), ","),
+	})
+}
+
+// keepSharedOwners carries the owners of shared cluster-scoped resources over
+// to the resources to apply, so that they are not lost when the resources are
+// updated.
+func keepSharedOwners(resources kube.ResourceList) error {
+	return resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Client == nil || namespaced(info) {
+			return nil
+		}
+		existing, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("could not get information about the resource %s: %w", resourceString(info), err)
+		}
+		annos, err := accessor.Annotations(existing)
+		if err != nil {
+			return err
+		}
+		if owners := annos[helmSharedOwnersAnnotation]; owners != "" {
+			return mergeAnnotations(info.Object, map[string]string{helmSharedOwnersAnnotation: owners})
+		}
+		return nil
+	})
+}
+
+// releaseSharedOwnership removes a release from the owners of the resources
+// that it shares with other releases. It returns the resources that are not
+// owned by any other release, which can be deleted, and the shared resources,
+// which are kept. The resources are not changed when dryRun is set.
+func releaseSharedOwnership(resources kube.ResourceList, releaseName, releaseNamespace string, dryRun bool) (kube.ResourceList, kube.ResourceList, error) {
+	var deletable, shared kube.ResourceList
+	self := releaseOwner(releaseName, releaseNamespace)
+
+	err := resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Client == nil || namespaced(info) {
+			deletable.Append(info)
+			return nil
+		}
+		kept, err := releaseOwnership(info, self, dryRun)
+		if err != nil {
+			return err
+		}
+		if kept {
+			shared.Append(info)
+		} else {
+			deletable.Append(info)
+		}
+		return nil
+	})
+
+	return deletable, shared, err
+}
+
+// releaseOwnership removes a release from the owners of a cluster-scoped
+// resource, and reports whether other releases still own it. The patch is
+// made against the version of the resource that was read, and retried when
+// another release changed the owners in the meantime.
+func releaseOwnership(info *resource.Info, self string, dryRun bool) (bool, error) {
+	helper := resource.NewHelper(info.Client, info.Mapping)
+	var kept bool
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				kept = false
+				return nil
+			}
+			return fmt.Errorf("could not get information about the resource %s: %w", resourceString(info), err)
+		}
+		annos, err := accessor.Annotations(existing)
+		if err != nil {
+			return err
+		}
+		owners := slices.DeleteFunc(sharedOwners(annos), func(o string) bool { return o == self })
+		kept = len(owners) > 0
+		if !kept || dryRun {
+			return nil
+		}
+
+		resourceVersion, err := accessor.ResourceVersion(existing)
+		if err != nil {
+			return err
+		}
+		patch, err := releaseOwnershipPatch(annos, resourceVersion, owners, self)
+		if err != nil {
+			return err
+		}
+		if _, err := helper.Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil); err != nil {
+			return fmt.Errorf("could not release the ownership of %s: %w", resourceString(info), err)
+		}
+		return nil
+	})
+	return kept, err
+}
+
+// releaseOwnershipPatch returns the merge patch that leaves a shared resource
+// to its remaining owners. The release annotations are moved to the first
+// remaining owner when they name the leaving release. The patch carries the
+// resource version it was computed from, so that it fails with a conflict
+// instead of overwriting owners added concurrently.
+func releaseOwnershipPatch(annos map[string]string, resourceVersion string, owners []string, self string) ([]byte, error) {
+	patch := map[string]any{}
+	if len(owners) > 1 {
+		patch[helmSharedOwnersAnnotation] = strings.Join(owners, ",")
+	} else {
+		patch[helmSharedOwnersAnnotation] = nil
+	}
+	if releaseOwner(annos[helmReleaseNameAnnotation], annos[helmReleaseNamespaceAnnotation]) == self {
+		namespace, name, _ := strings.Cut(owners[0], "/")
+		patch[helmReleaseNameAnnotation] = name
+		patch[helmReleaseNamespaceAnnotation] = namespace
+	}
+	return json.Marshal(map[string]any{"metadata": map[string]any{
+		"resourceVersion": resourceVersion,
+		"annotations":     patch,
+	}})
+}
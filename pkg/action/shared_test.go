@@ -0,0 +1,245 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest/fake"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+var rbacV1GV = schema.GroupVersion{Group: "rbac.authorization.k8s.io", Version: "v1"}
+
+// newClusterRole returns a cluster role to apply, and records the patches
+// sent for it. The cluster role exists with the given annotations unless
+// existing is nil.
+func newClusterRole(name string, existing map[string]string, patches *[]map[string]any) *resource.Info {
+	codec := scheme.Codecs.LegacyCodec(rbacV1GV)
+	live := &rbacv1.ClusterRole{ObjectMeta: v1.ObjectMeta{
+		Name:            name,
+		Labels:          map[string]string{appManagedByLabel: appManagedByHelm},
+		Annotations:     existing,
+		ResourceVersion: "7",
+	}}
+	return &resource.Info{
+		Name: name,
+		Mapping: &meta.RESTMapping{
+			Resource:         rbacV1GV.WithResource("clusterroles"),
+			GroupVersionKind: rbacV1GV.WithKind("ClusterRole"),
+			Scope:            meta.RESTScopeRoot,
+		},
+		Object: &rbacv1.ClusterRole{ObjectMeta: v1.ObjectMeta{Name: name}},
+		Client: &fake.RESTClient{
+			GroupVersion:         rbacV1GV,
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+			Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("Content-Type", runtime.ContentTypeJSON)
+				if existing == nil {
+					return &http.Response{StatusCode: http.StatusNotFound, Header: header, Body: stringBody("")}, nil
+				}
+				if req.Method == http.MethodPatch {
+					patch := map[string]any{}
+					data, _ := io.ReadAll(req.Body)
+					if err := json.Unmarshal(data, &patch); err != nil {
+						return nil, err
+					}
+					*patches = append(*patches, patch)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: stringBody(runtime.EncodeOrDie(codec, live))}, nil
+			}),
+		},
+	}
+}
+
+func TestExistingResourceConflict_ClusterScoped(t *testing.T) {
+	owned := map[string]string{
+		helmReleaseNameAnnotation:      "other",
+		helmReleaseNamespaceAnnotation: "ns-b",
+	}
+
+	_, err := existingResourceConflict(kube.ResourceList{newClusterRole("reader", owned, nil)}, "rel", "ns-a", false)
+	assert.EqualError(t, err, "cluster-scoped ClusterRole \"reader\" is owned by release(s) ns-b/other, and cannot be owned by release ns-a/rel as well. Set --share-cluster-scoped to share it between these releases")
+
+	info := newClusterRole("reader", owned, nil)
+	found, err := existingResourceConflict(kube.ResourceList{info}, "rel", "ns-a", true)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+	annos, err := accessor.Annotations(info.Object)
+	require.NoError(t, err)
+	assert.Equal(t, "ns-a/rel,ns-b/other", annos[helmSharedOwnersAnnotation])
+
+	// A release listed as a shared owner owns the resource.
+	shared := map[string]string{
+		helmReleaseNameAnnotation:      "other",
+		helmReleaseNamespaceAnnotation: "ns-b",
+		helmSharedOwnersAnnotation:     "ns-a/rel,ns-b/other",
+	}
+	_, err = existingResourceConflict(kube.ResourceList{newClusterRole("reader", shared, nil)}, "rel", "ns-a", false)
+	assert.NoError(t, err)
+}
+
+func TestReleaseSharedOwnership(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		dryRun      bool
+		deletable   bool
+		patch       map[string]any
+	}{
+		{
+			name:      "not found",
+			deletable: true,
+		},
+		{
+			name: "single owner",
+			annotations: map[string]string{
+				helmReleaseNameAnnotation:      "rel",
+				helmReleaseNamespaceAnnotation: "ns-a",
+			},
+			deletable: true,
+		},
+		{
+			name: "last shared owner",
+			annotations: map[string]string{
+				helmReleaseNameAnnotation:      "rel",
+				helmReleaseNamespaceAnnotation: "ns-a",
+				helmSharedOwnersAnnotation:     "ns-a/rel",
+			},
+			deletable: true,
+		},
+		{
+			name: "shared with another release",
+			annotations: map[string]string{
+				helmReleaseNameAnnotation:      "rel",
+				helmReleaseNamespaceAnnotation: "ns-a",
+				helmSharedOwnersAnnotation:     "ns-a/rel,ns-b/other",
+			},
+			patch: map[string]any{"metadata": map[string]any{"resourceVersion": "7", "annotations": map[string]any{
+				helmSharedOwnersAnnotation:     nil,
+				helmReleaseNameAnnotation:      "other",
+				helmReleaseNamespaceAnnotation: "ns-b",
+			}}},
+		},
+		{
+			name: "shared with other releases",
+			annotations: map[string]string{
+				helmReleaseNameAnnotation:      "other",
+				helmReleaseNamespaceAnnotation: "ns-b",
+				helmSharedOwnersAnnotation:     "ns-a/rel,ns-b/other,ns-c/third",
+			},
+			patch: map[string]any{"metadata": map[string]any{"resourceVersion": "7", "annotations": map[string]any{
+				helmSharedOwnersAnnotation: "ns-b/other,ns-c/third",
+			}}},
+		},
+		{
+			name: "dry run",
+			annotations: map[string]string{
+				helmSharedOwnersAnnotation: "ns-a/rel,ns-b/other",
+			},
+			dryRun: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patches []map[string]any
+			resources := kube.ResourceList{
+				newMissingDeployment("deployment", "ns-a"),
+				newClusterRole("reader", tt.annotations, &patches),
+			}
+
+			deletable, shared, err := releaseSharedOwnership(resources, "rel", "ns-a", tt.dryRun)
+			require.NoError(t, err)
+			if tt.deletable {
+				assert.Equal(t, resources, deletable)
+				assert.Empty(t, shared)
+			} else {
+				assert.Equal(t, resources[:1], deletable)
+				assert.Equal(t, resources[1:], shared)
+			}
+			if tt.patch != nil {
+				assert.Equal(t, []map[string]any{tt.patch}, patches)
+			} else {
+				assert.Empty(t, patches)
+			}
+		})
+	}
+}
+
+func TestReleaseSharedOwnershipConflict(t *testing.T) {
+	codec := scheme.Codecs.LegacyCodec(rbacV1GV)
+	live := &rbacv1.ClusterRole{ObjectMeta: v1.ObjectMeta{
+		Name:   "reader",
+		Labels: map[string]string{appManagedByLabel: appManagedByHelm},
+		Annotations: map[string]string{
+			helmSharedOwnersAnnotation: "ns-a/rel,ns-b/other",
+		},
+		ResourceVersion: "7",
+	}}
+	var patches []map[string]any
+	info := newClusterRole("reader", nil, nil)
+	info.Client = &fake.RESTClient{
+		GroupVersion:         rbacV1GV,
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("Content-Type", runtime.ContentTypeJSON)
+			if req.Method == http.MethodPatch {
+				patch := map[string]any{}
+				data, _ := io.ReadAll(req.Body)
+				if err := json.Unmarshal(data, &patch); err != nil {
+					return nil, err
+				}
+				patches = append(patches, patch)
+				if len(patches) == 1 {
+					// Another release joined the owners since the resource was read.
+					live.Annotations[helmSharedOwnersAnnotation] = "ns-a/rel,ns-b/other,ns-c/third"
+					live.ResourceVersion = "8"
+					status := apierrors.NewConflict(rbacV1GV.WithResource("clusterroles").GroupResource(), "reader", errors.New("the object has been modified"))
+					return &http.Response{StatusCode: http.StatusConflict, Header: header, Body: stringBody(runtime.EncodeOrDie(codec, &status.ErrStatus))}, nil
+				}
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: stringBody(runtime.EncodeOrDie(codec, live))}, nil
+		}),
+	}
+
+	deletable, shared, err := releaseSharedOwnership(kube.ResourceList{info}, "rel", "ns-a", false)
+	require.NoError(t, err)
+	assert.Empty(t, deletable)
+	assert.Len(t, shared, 1)
+	require.Len(t, patches, 2)
+	assert.Equal(t, map[string]any{"metadata": map[string]any{"resourceVersion": "8", "annotations": map[string]any{
+		helmSharedOwnersAnnotation: "ns-b/other,ns-c/third",
+	}}}, patches[1])
+}
@@ -18,13 +18,16 @@ package action
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"k8s.io/cli-runtime/pkg/printers"
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
@@ -61,6 +64,7 @@ type Show struct {
 	Devel            bool
 	OutputFormat     ShowOutputFormat
 	JSONPathTemplate string
+	MaxVersions      int          // maximum number of versions listed by Versions
 	chart            *chart.Chart // for testing
 }
 
@@ -141,6 +145,52 @@ func (s *Show) Run(chartpath string) (string, error) {
 	return out.String(), nil
 }
 
+// ValuesDocs returns the documentation of the values of a chart, merging the
+// comments of its values.yaml with the descriptions of its values.schema.json.
+func (s *Show) ValuesDocs(chartpath string) (*util.ValueDoc, error) {
+	if s.chart == nil {
+		chrt, err := loader.Load(chartpath)
+		if err != nil {
+			return nil, err
+		}
+		s.chart = chrt
+	}
+	var values []byte
+	for _, f := range s.chart.Raw {
+		if f.Name == chartutil.ValuesfileName {
+			values = f.Data
+		}
+	}
+	return util.ValuesDocs(values, s.chart.Schema)
+}
+
+// Versions returns the versions of the chart at an OCI reference, from the
+// highest to the lowest. Only the versions that satisfy the Version
+// constraint are returned. Prereleases are returned when Devel is set or the
+// constraint has a prerelease.
+func (s *Show) Versions(ref string) ([]string, error) {
+	if !registry.IsOCI(ref) {
+		return nil, fmt.Errorf("listing the versions of %q is only supported for OCI references", ref)
+	}
+	if s.registryClient == nil {
+		return nil, errors.New("missing registry client")
+	}
+	version := s.Version
+	switch {
+	case version == "" && s.Devel:
+		version = ">=0.0.0-0"
+	case version == "":
+		version = ">=0.0.0"
+	}
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %w", version, err)
+	}
+	return s.registryClient.ListTags(strings.TrimPrefix(ref, registry.OCIScheme+"://"),
+		registry.TagsOptConstraint(constraint),
+		registry.TagsOptLimit(s.MaxVersions))
+}
+
 func findReadme(files []*common.File) (file *common.File) {
 	for _, file := range files {
 		for _, n := range readmeFileNames {
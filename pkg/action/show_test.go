@@ -180,3 +180,40 @@ func TestShowSetRegistryClient(t *testing.T) {
 	client.SetRegistryClient(registryClient)
 	assert.Equal(t, registryClient, client.registryClient)
 }
+
+func TestShowVersions(t *testing.T) {
+	client := NewShow(ShowAll, actionConfigFixture(t))
+
+	_, err := client.Versions("https://charts.example.com/mychart")
+	assert.ErrorContains(t, err, "only supported for OCI references")
+
+	client.SetRegistryClient(nil)
+	_, err = client.Versions("oci://registry.example.com/charts/mychart")
+	assert.EqualError(t, err, "missing registry client")
+
+	client.SetRegistryClient(&registry.Client{})
+	client.Version = "not a constraint"
+	_, err = client.Versions("oci://registry.example.com/charts/mychart")
+	assert.ErrorContains(t, err, `invalid version constraint "not a constraint"`)
+}
+
+func TestShowValuesDocs(t *testing.T) {
+	config := actionConfigFixture(t)
+	client := NewShow(ShowValues, config)
+	client.chart = buildChart(withSampleValues())
+	client.chart.Raw = []*common.File{{Name: "values.yaml", Data: []byte("# The nested values\nnestedKey:\n  simpleKey: simpleValue # A simple key\n")}}
+	client.chart.Schema = []byte(`{"properties": {"nestedKey": {"description": "Nested", "required": ["simpleKey"]}}}`)
+
+	docs, err := client.ValuesDocs("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested := docs.Lookup("nestedKey")
+	if nested == nil || nested.Description != "Nested" {
+		t.Fatalf("expected the schema description of nestedKey, got %+v", nested)
+	}
+	simple := docs.Lookup("nestedKey.simpleKey")
+	if simple == nil || simple.Description != "A simple key" || simple.Default != "simpleValue" || !simple.Required {
+		t.Errorf("unexpected docs of nestedKey.simpleKey: %+v", simple)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// SlotLabel is the label the objects of a slot of a blue/green release carry,
+// set to the name of the slot. The Services shared by the slots select the
+// active slot with it.
+const SlotLabel = "helm.sh/slot"
+
+// The slots of a blue/green release.
+const (
+	SlotBlue  = "blue"
+	SlotGreen = "green"
+)
+
+// slotNames lists the slots in the order their objects appear in the
+// manifest of a release.
+var slotNames = []string{SlotBlue, SlotGreen}
+
+func validateSlot(slot string) error {
+	if !slices.Contains(slotNames, slot) {
+		return fmt.Errorf("invalid slot %q: must be %q or %q", slot, SlotBlue, SlotGreen)
+	}
+	return nil
+}
+
+// slotManifest builds the manifest of a release from a manifest rendered for
+// one of its slots. The objects labelled with SlotLabel replace the objects of
+// the slot, the objects of the other slot are kept from slots, and the other
+// objects are shared by the slots. It returns the manifest and the updated
+// slots.
+func slotManifest(manifest, slot string, slots map[string]string) (string, map[string]string, error) {
+	objects, err := releaseutil.ParseManifestObjects(manifest)
+	if err != nil {
+		return "", nil, err
+	}
+	var shared, slotted []string
+	for _, obj := range objects {
+		switch s := obj.Labels[SlotLabel]; s {
+		case "":
+			shared = append(shared, strings.TrimSpace(obj.Manifest))
+		case slot:
+			slotted = append(slotted, strings.TrimSpace(obj.Manifest))
+		default:
+			return "", nil, fmt.Errorf("%s %q is labelled for slot %q, but slot %q is deployed", obj.Kind, obj.Name, s, slot)
+		}
+	}
+	if len(slotted) == 0 {
+		return "", nil, fmt.Errorf("no object is labelled with %s=%s: use .Release.Slot to label the objects of the slot", SlotLabel, slot)
+	}
+
+	updated := maps.Clone(slots)
+	if updated == nil {
+		updated = map[string]string{}
+	}
+	updated[slot] = joinManifestDocs(slotted)
+	docs := shared
+	for _, s := range slotNames {
+		docs = append(docs, manifestDocs(updated[s])...)
+	}
+	return joinManifestDocs(docs), updated, nil
+}
+
+// promoteManifest switches the Services of a manifest that select a slot with
+// SlotLabel to the given slot, and returns how many it switched. The Services
+// that belong to a slot are left as they are.
+func promoteManifest(manifest, slot string) (string, int, error) {
+	docs := manifestDocs(manifest)
+	var switched int
+	for i, doc := range docs {
+		var obj map[string]any
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return "", 0, err
+		}
+		if obj["kind"] != "Service" {
+			continue
+		}
+		labels, _, _ := unstructured.NestedStringMap(obj, "metadata", "labels")
+		if _, ok := labels[SlotLabel]; ok {
+			continue
+		}
+		selector, found, err := unstructured.NestedStringMap(obj, "spec", "selector")
+		if err != nil || !found {
+			continue
+		}
+		if _, ok := selector[SlotLabel]; !ok {
+			continue
+		}
+		selector[SlotLabel] = slot
+		if err := unstructured.SetNestedStringMap(obj, selector, "spec", "selector"); err != nil {
+			return "", 0, err
+		}
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return "", 0, err
+		}
+		docs[i] = manifestComments(doc) + strings.TrimSpace(string(out))
+		switched++
+	}
+	return joinManifestDocs(docs), switched, nil
+}
+
+// manifestComments returns the comment lines at the top of a document, such
+// as its "# Source:" comment.
+func manifestComments(doc string) string {
+	var comments strings.Builder
+	for _, line := range strings.Split(doc, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			break
+		}
+		comments.WriteString(line + "\n")
+	}
+	return comments.String()
+}
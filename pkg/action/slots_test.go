@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const slotService = `# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  selector:
+    app: app
+    helm.sh/slot: blue`
+
+func slotDeployment(slot string) string {
+	return `# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-` + slot + `
+  labels:
+    helm.sh/slot: ` + slot
+}
+
+func TestSlotManifest(t *testing.T) {
+	rendered := joinManifestDocs([]string{slotService, slotDeployment("green")})
+	slots := map[string]string{"blue": joinManifestDocs([]string{slotDeployment("blue")})}
+
+	manifest, updated, err := slotManifest(rendered, "green", slots)
+	require.NoError(t, err)
+	assert.Equal(t, joinManifestDocs([]string{slotService, slotDeployment("blue"), slotDeployment("green")}), manifest)
+	assert.Equal(t, joinManifestDocs([]string{slotDeployment("green")}), updated["green"])
+	assert.Equal(t, slots["blue"], updated["blue"])
+	assert.NotContains(t, slots, "green", "the slots of the previous release must not be modified")
+
+	// Upgrading the slot again replaces its objects.
+	rendered = joinManifestDocs([]string{slotService, slotDeployment("green") + "\n  annotations:\n    v: \"2\""})
+	manifest, _, err = slotManifest(rendered, "green", updated)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(manifest, "name: app-green"))
+	assert.Contains(t, manifest, `v: "2"`)
+}
+
+func TestSlotManifestErrors(t *testing.T) {
+	_, _, err := slotManifest(joinManifestDocs([]string{slotService}), "blue", nil)
+	assert.ErrorContains(t, err, "no object is labelled with helm.sh/slot=blue")
+
+	_, _, err = slotManifest(joinManifestDocs([]string{slotDeployment("green")}), "blue", nil)
+	assert.ErrorContains(t, err, `is labelled for slot "green", but slot "blue" is deployed`)
+
+	assert.Error(t, validateSlot("red"))
+	assert.NoError(t, validateSlot("green"))
+}
+
+func TestPromoteManifest(t *testing.T) {
+	previewService := `apiVersion: v1
+kind: Service
+metadata:
+  name: app-green
+  labels:
+    helm.sh/slot: green
+spec:
+  selector:
+    helm.sh/slot: green`
+	manifest := joinManifestDocs([]string{slotService, previewService, slotDeployment("green")})
+
+	promoted, switched, err := promoteManifest(manifest, "green")
+	require.NoError(t, err)
+	assert.Equal(t, 1, switched)
+	docs := manifestDocs(promoted)
+	require.Len(t, docs, 3)
+	assert.Contains(t, docs[0], "# Source: app/templates/service.yaml\n")
+	assert.Contains(t, docs[0], "helm.sh/slot: green")
+	assert.NotContains(t, docs[0], "helm.sh/slot: blue")
+	assert.Equal(t, previewService, docs[1])
+	assert.Equal(t, slotDeployment("green"), docs[2])
+}
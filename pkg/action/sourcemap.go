@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/engine"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// yamlErrorLine extracts the line number from a YAML parser error.
+var yamlErrorLine = regexp.MustCompile(`yaml: line (\d+):`)
+
+// newEngine returns the template engine used to render a chart.
+//
+// A `helm template` should not talk to the remote cluster. However, commands with the flag
+// `--dry-run` with the value of `false`, `none`, or `server` should try to interact with the cluster.
+// It may break in interesting and exotic ways because other data (e.g. discovery) is mocked.
+func (cfg *Configuration) newEngine(interactWithRemote, enableDNS bool) (engine.Engine, error) {
+	var e engine.Engine
+	if interactWithRemote && cfg.RESTClientGetter != nil {
+		restConfig, err := cfg.RESTClientGetter.ToRESTConfig()
+		if err != nil {
+			return e, err
+		}
+		e = engine.New(restConfig)
+	}
+	e.EnableDNS = enableDNS
+	e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
+	e.FuncPolicy = cfg.TemplateFuncPolicy
+	return e, nil
+}
+
+// writeSourceMap renders a source map of the chart and writes it to path as JSON.
+func (cfg *Configuration) writeSourceMap(ctx context.Context, ch *chart.Chart, values common.Values, path string, interactWithRemote, enableDNS bool) error {
+	e, err := cfg.newEngine(interactWithRemote, enableDNS)
+	if err != nil {
+		return err
+	}
+	sm, err := e.RenderSourceMap(ctx, ch, values)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(sm, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// locateManifestError adds the template line an invalid manifest was rendered
+// from to the YAML parse error returned when sorting the rendered files. Any
+// other error is returned unchanged.
+func locateManifestError(ctx context.Context, e engine.Engine, ch *chart.Chart, values common.Values, files map[string]string, err error) error {
+	var perr *releaseutil.ManifestParseError
+	if !errors.As(err, &perr) {
+		return err
+	}
+	m := yamlErrorLine.FindStringSubmatch(perr.Err.Error())
+	if m == nil {
+		return err
+	}
+	content := files[perr.Path]
+	offset := strings.Index(content, perr.Manifest)
+	if offset < 0 {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	line += strings.Count(content[:offset], "\n")
+
+	sm, serr := e.RenderSourceMap(ctx, ch, values)
+	if serr != nil {
+		return err
+	}
+	loc, ok := sm.Lookup(perr.Path, line)
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%w (output line %d was rendered from %s:%d)", err, line, loc.Template, loc.Line)
+}
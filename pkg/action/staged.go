@@ -0,0 +1,187 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// CanaryAnnotation marks the resources of a chart that a staged upgrade
+// applies in its canary stage, when set to "true".
+const CanaryAnnotation = "helm.sh/canary"
+
+// Stage is a stage of a staged upgrade.
+type Stage string
+
+const (
+	// StageCanary applies the canary resources and checks their health.
+	StageCanary Stage = "canary"
+	// StageRemainder applies the other resources.
+	StageRemainder Stage = "remainder"
+)
+
+// StagePhase is how far a stage of a staged upgrade got.
+type StagePhase string
+
+const (
+	// StagePhaseApplying means the resources of the stage are being applied.
+	StagePhaseApplying StagePhase = "applying"
+	// StagePhaseWaiting means the resources of the stage are being waited
+	// for.
+	StagePhaseWaiting StagePhase = "waiting"
+	// StagePhaseHolding means the canary resources are healthy and must stay
+	// healthy for the hold period.
+	StagePhaseHolding StagePhase = "holding"
+	// StagePhaseSucceeded means the stage is complete.
+	StagePhaseSucceeded StagePhase = "succeeded"
+	// StagePhaseFailed means the stage failed and the upgrade is failed.
+	StagePhaseFailed StagePhase = "failed"
+)
+
+// StageStatus reports the progress of a staged upgrade.
+type StageStatus struct {
+	Stage Stage
+	Phase StagePhase
+	// Resources are the resources of the stage, as kind/name.
+	Resources []string
+	// Err is why the stage failed.
+	Err error
+}
+
+// StagedRollout configures an upgrade that applies a subset of the resources,
+// the canary, before the others. The remainder is only applied once the
+// canary resources are healthy and stayed healthy for the hold period. A
+// staged upgrade that fails is rolled back, as with RollbackOnFailure.
+//
+// Staged upgrades are experimental.
+type StagedRollout struct {
+	// DeploymentPercent is the percentage of the Deployments of the release,
+	// rounded up, that are applied in the canary stage, in addition to the
+	// resources annotated with CanaryAnnotation.
+	DeploymentPercent int
+	// Hold is how long the canary resources must stay healthy before the
+	// remainder is applied.
+	Hold time.Duration
+	// Progress is called when a stage enters a new phase, if set.
+	Progress func(StageStatus)
+}
+
+func (s *StagedRollout) report(stage Stage, phase StagePhase, resources kube.ResourceList, err error) {
+	if s.Progress == nil {
+		return
+	}
+	status := StageStatus{Stage: stage, Phase: phase, Err: err}
+	for _, r := range resources {
+		status.Resources = append(status.Resources, fmt.Sprintf("%s/%s", resourceGVK(r).Kind, r.Name))
+	}
+	s.Progress(status)
+}
+
+// canaryResources splits the target resources of an upgrade into the canary
+// resources and the others.
+func (s *StagedRollout) canaryResources(target kube.ResourceList) (canary, remainder kube.ResourceList) {
+	var deployments int
+	for _, r := range target {
+		if isDeployment(r) && !isCanary(r) {
+			deployments++
+		}
+	}
+	// Round up, so that any percentage selects at least one Deployment.
+	pick := (deployments*s.DeploymentPercent + 99) / 100
+	for _, r := range target {
+		switch {
+		case isCanary(r):
+			canary = append(canary, r)
+		case pick > 0 && isDeployment(r):
+			canary = append(canary, r)
+			pick--
+		default:
+			remainder = append(remainder, r)
+		}
+	}
+	return canary, remainder
+}
+
+func isCanary(r *resource.Info) bool {
+	obj, err := meta.Accessor(r.Object)
+	return err == nil && obj.GetAnnotations()[CanaryAnnotation] == "true"
+}
+
+func isDeployment(r *resource.Info) bool {
+	gvk := resourceGVK(r)
+	return gvk.Group == "apps" && gvk.Kind == "Deployment"
+}
+
+// applyCanaryStage applies the canary resources of a staged upgrade, waits
+// for them to be healthy and holds. It returns the current and target
+// resources that are left to the remainder stage, and the resources it
+// created.
+func (u *Upgrade) applyCanaryStage(ctx context.Context, res *upgradeResult, waiter kube.Waiter, current, target kube.ResourceList, updateOptions ...kube.ClientUpdateOption) (kube.ResourceList, kube.ResourceList, kube.ResourceList, error) {
+	canary, remainder := u.Staged.canaryResources(target)
+	if len(canary) == 0 {
+		u.cfg.Logger().Debug("no canary resources, applying the upgrade in a single stage")
+		return current, target, nil, nil
+	}
+	canaryKeys := make(map[string]bool, len(canary))
+	for _, r := range canary {
+		canaryKeys[objectKey(r)] = true
+	}
+	var currentCanary, currentRemainder kube.ResourceList
+	for _, r := range current {
+		if canaryKeys[objectKey(r)] {
+			currentCanary = append(currentCanary, r)
+		} else {
+			currentRemainder = append(currentRemainder, r)
+		}
+	}
+
+	res.setStage(StageCanary)
+	u.Staged.report(StageCanary, StagePhaseApplying, canary, nil)
+	result, err := u.cfg.KubeClient.Update(currentCanary, canary, updateOptions...)
+	var created kube.ResourceList
+	if result != nil {
+		created = result.Created
+	}
+	if err != nil {
+		return nil, nil, created, err
+	}
+
+	u.Staged.report(StageCanary, StagePhaseWaiting, canary, nil)
+	if err := waiter.Wait(canary, u.PhaseTimeouts.wait(u.Timeout)); err != nil {
+		return nil, nil, created, fmt.Errorf("canary resources are not healthy: %w", err)
+	}
+	if u.Staged.Hold > 0 {
+		u.Staged.report(StageCanary, StagePhaseHolding, canary, nil)
+		select {
+		case <-ctx.Done():
+			return nil, nil, created, interrupted(ctx)
+		case <-time.After(u.Staged.Hold):
+		}
+		if err := waiter.Wait(canary, u.PhaseTimeouts.wait(u.Timeout)); err != nil {
+			return nil, nil, created, fmt.Errorf("canary resources did not stay healthy: %w", err)
+		}
+	}
+	u.Staged.report(StageCanary, StagePhaseSucceeded, canary, nil)
+	return currentRemainder, remainder, created, nil
+}
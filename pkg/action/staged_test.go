@@ -0,0 +1,165 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+func stagedResources() kube.ResourceList {
+	info := func(obj runtime.Object, gvk schema.GroupVersionKind) *resource.Info {
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		m, _ := meta.Accessor(obj)
+		return &resource.Info{
+			Name:      m.GetName(),
+			Namespace: m.GetNamespace(),
+			Object:    obj,
+			Mapping:   &meta.RESTMapping{GroupVersionKind: gvk, Scope: meta.RESTScopeNamespace},
+		}
+	}
+	deployment := func(name string, annotations map[string]string) *resource.Info {
+		return info(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "spaced", Annotations: annotations}},
+			appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	}
+	return kube.ResourceList{
+		info(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "spaced", Annotations: map[string]string{CanaryAnnotation: "true"}}},
+			corev1.SchemeGroupVersion.WithKind("ConfigMap")),
+		deployment("api", nil),
+		deployment("web", nil),
+		deployment("worker", nil),
+	}
+}
+
+func TestStagedRolloutCanaryResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		percent   int
+		canary    []string
+		remainder []string
+	}{
+		{
+			name:      "annotated only",
+			canary:    []string{"config"},
+			remainder: []string{"api", "web", "worker"},
+		},
+		{
+			name:      "percentage rounded up",
+			percent:   50,
+			canary:    []string{"config", "api", "web"},
+			remainder: []string{"worker"},
+		},
+		{
+			name:    "all deployments",
+			percent: 100,
+			canary:  []string{"config", "api", "web", "worker"},
+		},
+	}
+	names := func(resources kube.ResourceList) []string {
+		var n []string
+		for _, r := range resources {
+			n = append(n, r.Name)
+		}
+		return n
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StagedRollout{DeploymentPercent: tt.percent}
+			canary, remainder := s.canaryResources(stagedResources())
+			assert.Equal(t, tt.canary, names(canary))
+			assert.Equal(t, tt.remainder, names(remainder))
+		})
+	}
+}
+
+func TestUpgradeRelease_Staged(t *testing.T) {
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	upAction.cfg.KubeClient = &kubefake.FailingKubeClient{PrintingKubeClient: upAction.cfg.KubeClient.(*kubefake.FailingKubeClient).PrintingKubeClient, DummyResources: stagedResources()}
+	rel := releaseStub()
+	rel.Name = "staged"
+	rel.Info.Status = common.StatusDeployed
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	var progress []StageStatus
+	upAction.Staged = &StagedRollout{
+		DeploymentPercent: 33,
+		Progress:          func(s StageStatus) { progress = append(progress, s) },
+	}
+	resi, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+	req.NoError(err)
+	res, err := releaserToV1Release(resi)
+	req.NoError(err)
+	assert.Equal(t, common.StatusDeployed, res.Info.Status)
+
+	canary := []string{"ConfigMap/config", "Deployment/api"}
+	remainder := []string{"Deployment/web", "Deployment/worker"}
+	assert.Equal(t, []StageStatus{
+		{Stage: StageCanary, Phase: StagePhaseApplying, Resources: canary},
+		{Stage: StageCanary, Phase: StagePhaseWaiting, Resources: canary},
+		{Stage: StageCanary, Phase: StagePhaseSucceeded, Resources: canary},
+		{Stage: StageRemainder, Phase: StagePhaseApplying, Resources: remainder},
+		{Stage: StageRemainder, Phase: StagePhaseWaiting, Resources: remainder},
+		{Stage: StageRemainder, Phase: StagePhaseSucceeded, Resources: remainder},
+	}, progress)
+}
+
+func TestUpgradeRelease_StagedCanaryFailure(t *testing.T) {
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	waitErr := errors.New("deployment api is not ready")
+	upAction.cfg.KubeClient = &kubefake.FailingKubeClient{
+		PrintingKubeClient: upAction.cfg.KubeClient.(*kubefake.FailingKubeClient).PrintingKubeClient,
+		DummyResources:     stagedResources(),
+		WaitError:          waitErr,
+	}
+	rel := releaseStub()
+	rel.Name = "staged"
+	rel.Info.Status = common.StatusDeployed
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	var progress []StageStatus
+	upAction.Staged = &StagedRollout{Progress: func(s StageStatus) { progress = append(progress, s) }}
+	_, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+	req.ErrorIs(err, waitErr)
+	assert.Contains(t, err.Error(), "rolling back the release")
+
+	req.NotEmpty(progress)
+	last := progress[len(progress)-1]
+	assert.Equal(t, StageCanary, last.Stage)
+	assert.Equal(t, StagePhaseFailed, last.Phase)
+	assert.ErrorIs(t, last.Err, waitErr)
+	for _, s := range progress {
+		assert.NotEqual(t, StageRemainder, s.Stage, "the remainder must not be applied")
+	}
+}
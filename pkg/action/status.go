@@ -72,6 +72,10 @@ func (s *Status) Run(name string) (ri.Releaser, error) {
 		}
 	}
 
+	if err := restoreNamespaces(resources, rel.Resources); err != nil {
+		return nil, err
+	}
+
 	resp, err := s.cfg.KubeClient.Get(resources, true)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,205 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package action
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// StorageRotateKey is the action for re-encrypting the release records.
+//
+// It provides the implementation of 'helm storage rotate-key'.
+type StorageRotateKey struct {
+	cfg *Configuration
+}
+
+// NewStorageRotateKey creates a new StorageRotateKey object with the given configuration.
+func NewStorageRotateKey(cfg *Configuration) *StorageRotateKey {
+	return &StorageRotateKey{
+		cfg: cfg,
+	}
+}
+
+// Run rewrites every release record of the namespace, so the records are
+// encrypted by the current encryption provider of the storage driver with its
+// current key. The records encrypted with a key that is no longer configured
+// cannot be read, and are left as is. Run returns the rewritten releases.
+func (r *StorageRotateKey) Run() ([]release.Releaser, error) {
+	if err := r.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	ls, err := r.cfg.Releases.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range ls {
+		rac, err := release.NewAccessor(rel)
+		if err != nil {
+			return nil, err
+		}
+		r.cfg.Logger().Debug("re-encrypting release", "release", rac.Name(), "revision", rac.Version())
+		if err := r.cfg.Releases.Update(rel); err != nil {
+			return nil, fmt.Errorf("unable to re-encrypt release %q revision %d: %w", rac.Name(), rac.Version(), err)
+		}
+	}
+	return ls, nil
+}
+
+// StorageList is the action for listing the release records.
+//
+// It provides the implementation of 'helm storage ls' and 'helm storage inspect'.
+type StorageList struct {
+	cfg *Configuration
+
+	// Version selects a revision of the release when listing the records of
+	// a release.
+	Version int
+}
+
+// NewStorageList creates a new StorageList object with the given configuration.
+func NewStorageList(cfg *Configuration) *StorageList {
+	return &StorageList{
+		cfg: cfg,
+	}
+}
+
+// Run lists the records of the storage driver, sorted by namespace, release
+// and revision. When name is set, only the records of the release are listed.
+// The records that cannot be decoded are listed too, with their error.
+func (l *StorageList) Run(name string) ([]*driver.Record, error) {
+	if err := l.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	rl, ok := l.cfg.Releases.Driver.(driver.RecordLister)
+	if !ok {
+		return nil, fmt.Errorf("the %s storage driver cannot list its records", l.cfg.Releases.Name())
+	}
+	recs, err := rl.ListRecords()
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		recs = slices.DeleteFunc(recs, func(r *driver.Record) bool {
+			return r.Labels["name"] != name || (l.Version > 0 && r.Labels["version"] != strconv.Itoa(l.Version))
+		})
+		if len(recs) == 0 {
+			return nil, driver.ErrReleaseNotFound
+		}
+	}
+	slices.SortFunc(recs, func(a, b *driver.Record) int {
+		if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Labels["name"], b.Labels["name"]); c != 0 {
+			return c
+		}
+		av, _ := strconv.Atoi(a.Labels["version"])
+		bv, _ := strconv.Atoi(b.Labels["version"])
+		if av != bv {
+			return av - bv
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return recs, nil
+}
+
+// MigrateStorage is the action for copying all the revisions of the releases
+// from a storage driver to another, for instance from Secrets to SQL.
+//
+// It provides the implementation of 'helm storage migrate'.
+type MigrateStorage struct {
+	cfg *Configuration
+
+	// From and To are the names of the drivers, as in $HELM_DRIVER.
+	From string
+	To   string
+	// Namespace is the namespace of the releases to migrate.
+	Namespace string
+	Overwrite bool
+	// Verify reads back every copied release from the destination driver.
+	Verify bool
+	// DeleteSource deletes the records from the source driver once they are
+	// copied, and verified when Verify is set.
+	DeleteSource bool
+	DryRun       bool
+}
+
+// NewMigrateStorage creates a new MigrateStorage object with the given configuration.
+func NewMigrateStorage(cfg *Configuration) *MigrateStorage {
+	return &MigrateStorage{
+		cfg:    cfg,
+		Verify: true,
+	}
+}
+
+// Run migrates the records, see storage.Migration.
+func (m *MigrateStorage) Run() ([]storage.MigratedRelease, error) {
+	if m.From == "" || m.To == "" {
+		return nil, errors.New("the source and the destination drivers are required")
+	}
+	if normalizeDriverName(m.From) == normalizeDriverName(m.To) {
+		return nil, fmt.Errorf("the source and the destination drivers are both %q", m.From)
+	}
+
+	clientFn := func() (*kubernetes.Clientset, error) {
+		conf, err := m.cfg.RESTClientGetter.ToRESTConfig()
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate config for kubernetes client: %w", err)
+		}
+		return kubernetes.NewForConfig(conf)
+	}
+	from, err := m.cfg.newStorageDriver(clientFn, m.Namespace, m.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := m.cfg.newStorageDriver(clientFn, m.Namespace, m.To)
+	if err != nil {
+		return nil, err
+	}
+
+	migration := &storage.Migration{
+		From:         from,
+		To:           to,
+		Overwrite:    m.Overwrite,
+		Verify:       m.Verify,
+		DeleteSource: m.DeleteSource,
+		DryRun:       m.DryRun,
+	}
+	return migration.Run()
+}
+
+// normalizeDriverName returns the canonical name of a storage driver.
+func normalizeDriverName(name string) string {
+	switch name {
+	case "", "secret", "secrets":
+		return "secrets"
+	case "configmap", "configmaps":
+		return "configmaps"
+	}
+	return name
+}
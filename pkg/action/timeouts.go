@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"time"
+)
+
+// PhaseTimeouts are the deadlines of the phases of an install or an upgrade,
+// so that the failure of a phase is reported before the timeout of the whole
+// operation, e.g. a failed hook of a chart whose resources take long to be
+// ready.
+type PhaseTimeouts struct {
+	// Hooks is the time each hook may take. Defaults to the timeout of the
+	// operation.
+	Hooks time.Duration
+	// Apply is the time the creation or update of the resources may take,
+	// retries included. Defaults to no deadline.
+	Apply time.Duration
+	// Wait is the time the resources may take to be ready. Defaults to the
+	// timeout of the operation.
+	Wait time.Duration
+}
+
+func (t PhaseTimeouts) hooks(timeout time.Duration) time.Duration {
+	if t.Hooks > 0 {
+		return t.Hooks
+	}
+	return timeout
+}
+
+func (t PhaseTimeouts) wait(timeout time.Duration) time.Duration {
+	if t.Wait > 0 {
+		return t.Wait
+	}
+	return timeout
+}
+
+// deadline returns the deadline of a phase that starts now, or the zero time
+// when the phase has no deadline.
+func deadline(timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
+
+// phaseError reports the error of a phase that failed after its deadline as
+// a timeout of the phase.
+func phaseError(err error, phase string, timeout time.Duration, deadline time.Time) error {
+	if err == nil || deadline.IsZero() || time.Now().Before(deadline) {
+		return err
+	}
+	return fmt.Errorf("%s did not complete within %s: %w: %w", phase, timeout, ErrWaitTimeout, err)
+}
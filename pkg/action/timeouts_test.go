@@ -0,0 +1,169 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+// timingKubeClient records the timeouts passed to its waiters, and delays
+// the update of resources.
+type timingKubeClient struct {
+	*kubefake.FailingKubeClient
+	applyDelay time.Duration
+	applyErr   error
+
+	mu    sync.Mutex
+	hooks []time.Duration
+	waits []time.Duration
+}
+
+func (c *timingKubeClient) Update(original, target kube.ResourceList, options ...kube.ClientUpdateOption) (*kube.Result, error) {
+	time.Sleep(c.applyDelay)
+	if c.applyErr != nil {
+		return &kube.Result{}, c.applyErr
+	}
+	return c.FailingKubeClient.Update(original, target, options...)
+}
+
+func (c *timingKubeClient) GetWaiter(ws kube.WaitStrategy) (kube.Waiter, error) {
+	return c.GetWaiterWithOptions(ws)
+}
+
+func (c *timingKubeClient) GetWaiterWithOptions(ws kube.WaitStrategy, opts ...kube.WaitOption) (kube.Waiter, error) {
+	w, err := c.FailingKubeClient.GetWaiterWithOptions(ws, opts...)
+	return &timingKubeWaiter{Waiter: w, client: c}, err
+}
+
+type timingKubeWaiter struct {
+	kube.Waiter
+	client *timingKubeClient
+}
+
+func (w *timingKubeWaiter) record(list *[]time.Duration, d time.Duration) {
+	w.client.mu.Lock()
+	defer w.client.mu.Unlock()
+	*list = append(*list, d)
+}
+
+func (w *timingKubeWaiter) Wait(resources kube.ResourceList, d time.Duration) error {
+	w.record(&w.client.waits, d)
+	return w.Waiter.Wait(resources, d)
+}
+
+func (w *timingKubeWaiter) WaitWithJobs(resources kube.ResourceList, d time.Duration) error {
+	w.record(&w.client.waits, d)
+	return w.Waiter.WaitWithJobs(resources, d)
+}
+
+func (w *timingKubeWaiter) WatchUntilReady(resources kube.ResourceList, d time.Duration) error {
+	w.record(&w.client.hooks, d)
+	return w.Waiter.WatchUntilReady(resources, d)
+}
+
+func TestPhaseTimeoutsDefaults(t *testing.T) {
+	var zero PhaseTimeouts
+	assert.Equal(t, 5*time.Minute, zero.hooks(5*time.Minute))
+	assert.Equal(t, 5*time.Minute, zero.wait(5*time.Minute))
+	assert.True(t, deadline(zero.Apply).IsZero())
+
+	set := PhaseTimeouts{Hooks: time.Minute, Apply: 2 * time.Minute, Wait: 3 * time.Minute}
+	assert.Equal(t, time.Minute, set.hooks(5*time.Minute))
+	assert.Equal(t, 3*time.Minute, set.wait(5*time.Minute))
+	assert.False(t, deadline(set.Apply).IsZero())
+}
+
+func TestPhaseError(t *testing.T) {
+	failed := errors.New("failed")
+
+	assert.NoError(t, phaseError(nil, "phase", time.Minute, time.Now().Add(-time.Second)))
+	assert.Equal(t, failed, phaseError(failed, "phase", 0, time.Time{}))
+	assert.Equal(t, failed, phaseError(failed, "phase", time.Minute, time.Now().Add(time.Minute)))
+
+	err := phaseError(failed, "phase", 10*time.Millisecond, time.Now().Add(-time.Second))
+	require.ErrorIs(t, err, ErrWaitTimeout)
+	require.ErrorIs(t, err, failed)
+	assert.EqualError(t, err, "phase did not complete within 10ms: "+ErrWaitTimeout.Error()+": failed")
+}
+
+func TestInstallPhaseTimeouts(t *testing.T) {
+	instAction := installAction(t)
+	client := &timingKubeClient{FailingKubeClient: instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)}
+	instAction.cfg.KubeClient = client
+	instAction.WaitStrategy = kube.StatusWatcherStrategy
+	instAction.Timeout = 5 * time.Minute
+	instAction.PhaseTimeouts = PhaseTimeouts{Hooks: time.Minute, Wait: 2 * time.Minute}
+
+	_, err := instAction.Run(buildChart(withSampleTemplates()), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, client.hooks)
+	for _, d := range client.hooks {
+		assert.Equal(t, time.Minute, d)
+	}
+	assert.Equal(t, []time.Duration{2 * time.Minute}, client.waits)
+}
+
+func TestInstallApplyTimeout(t *testing.T) {
+	instAction := installActionWithConfig(actionConfigFixtureWithDummyResources(t, createDummyResourceList(false)))
+	instAction.TakeOwnership = true
+	// The requests of the update are cancelled at the deadline.
+	instAction.cfg.KubeClient = &timingKubeClient{
+		FailingKubeClient: instAction.cfg.KubeClient.(*kubefake.FailingKubeClient),
+		applyDelay:        20 * time.Millisecond,
+		applyErr:          context.DeadlineExceeded,
+	}
+	instAction.DisableHooks = true
+	instAction.PhaseTimeouts = PhaseTimeouts{Apply: 10 * time.Millisecond}
+
+	_, err := instAction.Run(buildChart(withSampleTemplates()), nil)
+	require.ErrorIs(t, err, ErrWaitTimeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "applying the resources did not complete within 10ms")
+}
+
+func TestUpgradePhaseTimeouts(t *testing.T) {
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "phases"
+	rel.Info.Status = common.StatusDeployed
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	client := &timingKubeClient{FailingKubeClient: upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)}
+	upAction.cfg.KubeClient = client
+	upAction.WaitStrategy = kube.StatusWatcherStrategy
+	upAction.Timeout = 5 * time.Minute
+	upAction.PhaseTimeouts = PhaseTimeouts{Hooks: time.Minute, Wait: 2 * time.Minute}
+
+	_, err := upAction.Run(rel.Name, buildChart(withSampleTemplates()), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, client.hooks)
+	for _, d := range client.hooks {
+		assert.Equal(t, time.Minute, d)
+	}
+	assert.Equal(t, []time.Duration{2 * time.Minute}, client.waits)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -60,6 +61,20 @@ func NewUninstall(cfg *Configuration) *Uninstall {
 
 // Run uninstalls the given release.
 func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error) {
+	start := time.Now()
+	res, err := u.run(name)
+	if !u.DryRun {
+		u.cfg.Metrics.observe(OperationUninstall, start, nil, err)
+		var rel releasei.Releaser
+		if res != nil {
+			rel = res.Release
+		}
+		u.cfg.notify(context.Background(), OperationUninstall, name, "", start, rel, err)
+	}
+	return res, err
+}
+
+func (u *Uninstall) run(name string) (*releasei.UninstallReleaseResponse, error) {
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
@@ -101,6 +116,9 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 			}
 
 			resources, err := u.cfg.KubeClient.Build(strings.NewReader(builder.String()), false)
+			if err == nil {
+				err = restoreNamespaces(resources, r.Resources)
+			}
 			if err == nil && len(resources) > 0 {
 				ownedResources, unownedResources, unverifiableResources, err := verifyOwnershipBeforeDelete(resources, r.Name, r.Namespace)
 				if err == nil {
@@ -129,7 +147,15 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 						}
 					}
 
-					if len(ownedResources) > 0 {
+					var sharedResources kube.ResourceList
+					ownedResources, sharedResources, err = releaseSharedOwnership(ownedResources, r.Name, r.Namespace, true)
+					for _, info := range sharedResources {
+						u.cfg.Logger().Debug("dry-run: would keep resource shared with other releases",
+							"kind", info.Mapping.GroupVersionKind.Kind,
+							"name", info.Name)
+					}
+
+					if err == nil && len(ownedResources) > 0 {
 						u.cfg.Logger().Debug("dry-run: resources would be deleted",
 							"release", r.Name,
 							"count", len(ownedResources))
@@ -349,6 +375,9 @@ func (u *Uninstall) deleteRelease(rel *release.Release) (kube.ResourceList, stri
 	if err != nil {
 		return nil, "", []error{fmt.Errorf("unable to build kubernetes objects for delete: %w", err)}
 	}
+	if err := restoreNamespaces(resources, rel.Resources); err != nil {
+		return nil, "", []error{err}
+	}
 
 	// Verify ownership before deleting resources
 	var ownedResources, unownedResources kube.ResourceList
@@ -358,6 +387,20 @@ func (u *Uninstall) deleteRelease(rel *release.Release) (kube.ResourceList, stri
 		if err != nil {
 			return nil, "", []error{fmt.Errorf("unable to verify resource ownership: %w", err)}
 		}
+		var sharedResources kube.ResourceList
+		ownedResources, sharedResources, err = releaseSharedOwnership(ownedResources, rel.Name, rel.Namespace, false)
+		if err != nil {
+			return nil, "", []error{fmt.Errorf("unable to release shared resources: %w", err)}
+		}
+		if len(sharedResources) > 0 {
+			if kept.Len() > 0 {
+				kept.WriteString("\n")
+			}
+			fmt.Fprintf(&kept, "%d resource(s) were not deleted because they are shared with other releases:\n", len(sharedResources))
+			for _, info := range sharedResources {
+				fmt.Fprintf(&kept, "[%s] %s\n", info.Mapping.GroupVersionKind.Kind, info.Name)
+			}
+		}
 
 		// Log warnings for unowned resources
 		if len(unownedResources) > 0 {
@@ -18,10 +18,15 @@ package action
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -70,12 +75,22 @@ type Upgrade struct {
 	SkipCRDs bool
 	// Timeout is the timeout for this operation
 	Timeout time.Duration
+	// PhaseTimeouts are the deadlines of the phases of the upgrade.
+	PhaseTimeouts PhaseTimeouts
 	// WaitStrategy determines what type of waiting should be done
 	WaitStrategy kube.WaitStrategy
 	// WaitOptions are additional options for waiting on resources
 	WaitOptions []kube.WaitOption
 	// WaitForJobs determines whether the wait operation for the Jobs should be performed after the upgrade is requested.
 	WaitForJobs bool
+	// Retry configures re-applying the resources after a transient failure.
+	Retry RetryPolicy
+	// IdempotencyKey is recorded with the new revision. When the release
+	// already has a revision created with the same key, the upgrade waits for
+	// that operation to finish if it is pending and returns its release
+	// instead of creating another revision, as long as that revision is
+	// deployed.
+	IdempotencyKey string
 	// DisableHooks disables hook processing if set to true.
 	DisableHooks bool
 	// DryRunStrategy can be set to prepare, but not execute the operation and whether or not to interact with the remote cluster
@@ -101,8 +116,16 @@ type Upgrade struct {
 	ReuseValues bool
 	// ResetThenReuseValues will reset the values to the chart's built-ins then merge with user's last supplied values.
 	ResetThenReuseValues bool
+	// ReuseChart upgrades using the chart stored in the deployed release
+	// instead of the chart passed to Run, which must then be nil.
+	ReuseChart bool
 	// MaxHistory limits the maximum number of revisions saved per release
+	// when the release does not record a retention.
 	MaxHistory int
+	// Retention, when set, is recorded on the new revision and limits the
+	// history of the release in this and all later operations. Otherwise the
+	// retention of the current revision is carried over.
+	Retention *release.Retention
 	// RollbackOnFailure enables rolling back the upgraded release on failure
 	RollbackOnFailure bool
 	// CleanupOnFail will, if true, cause the upgrade to delete newly-created resources on a failed update.
@@ -113,9 +136,15 @@ type Upgrade struct {
 	HideNotes bool
 	// SkipSchemaValidation determines if JSON schema validation is disabled.
 	SkipSchemaValidation bool
+	// StrictValuesSchema fails the upgrade when values set keys that are not
+	// declared in the chart schema, instead of logging a warning.
+	StrictValuesSchema bool
 	// Description is the description of this operation
 	Description string
 	Labels      map[string]string
+	// Annotations are merged into the annotations of the storage object of
+	// the release. An annotation set to "null" is removed.
+	Annotations map[string]string
 	// PostRenderer is an optional post-renderer
 	//
 	// If this is non-nil, then after templates are rendered, they will be sent to the
@@ -131,10 +160,31 @@ type Upgrade struct {
 	DependencyUpdate bool
 	// Lock to control raceconditions when the process receives a SIGTERM
 	Lock sync.Mutex
+	// RestrictNamespace fails the upgrade when the chart renders namespaced
+	// resources in other namespaces than the release namespace.
+	RestrictNamespace bool
 	// Enable DNS lookups when rendering templates
 	EnableDNS bool
 	// TakeOwnership will skip the check for helm annotations and adopt all existing resources.
 	TakeOwnership bool
+	// Needs lists the releases the release depends on, by name, or by
+	// namespace/name for releases in other namespaces. The upgrade fails
+	// unless they are deployed and healthy, except on dry runs. The needs of the current release
+	// are kept when it is nil.
+	Needs []string
+	// ShareClusterScoped allows the release to share cluster-scoped resources
+	// that are owned by other releases.
+	ShareClusterScoped bool
+	// Staged, when set, applies the upgrade in stages. This is experimental.
+	Staged *StagedRollout
+	// Slot, when set, deploys the chart to a slot of a blue/green release.
+	// The objects labelled with SlotLabel set to the slot replace those of
+	// the slot, while the objects of the other slot are kept as they are.
+	// The Services only switch to the slot when it is promoted.
+	Slot string
+
+	// onlyChanged limits the update to the resources whose manifests changed.
+	onlyChanged bool
 }
 
 type resultMessage struct {
@@ -142,6 +192,28 @@ type resultMessage struct {
 	e error
 }
 
+// upgradeResult receives the result of an upgrade. The result is reported
+// once, either by the upgrade or by its interruption, whichever comes first.
+type upgradeResult struct {
+	once sync.Once
+	c    chan resultMessage
+	// stage is the stage a staged upgrade is at, guarded by mu.
+	mu    sync.Mutex
+	stage Stage
+}
+
+func (r *upgradeResult) setStage(stage Stage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stage = stage
+}
+
+func (r *upgradeResult) currentStage() Stage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stage
+}
+
 // NewUpgrade creates a new Upgrade object with the given configuration.
 func NewUpgrade(cfg *Configuration) *Upgrade {
 	up := &Upgrade{
@@ -168,23 +240,28 @@ func (u *Upgrade) Run(name string, chart chart.Charter, vals map[string]any) (ri
 
 // RunWithContext executes the upgrade on the given release with context.
 func (u *Upgrade) RunWithContext(ctx context.Context, name string, ch chart.Charter, vals map[string]any) (ri.Releaser, error) {
+	start := time.Now()
+	rel, err := u.run(ctx, name, ch, vals)
+	if !isDryRun(u.DryRunStrategy) {
+		u.cfg.Metrics.observe(OperationUpgrade, start, rel, err)
+		u.cfg.notify(ctx, OperationUpgrade, name, u.Namespace, start, rel, err)
+	}
+	return rel, err
+}
+
+func (u *Upgrade) run(ctx context.Context, name string, ch chart.Charter, vals map[string]any) (ri.Releaser, error) {
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
 
-	var chrt *chartv2.Chart
-	switch c := ch.(type) {
-	case *chartv2.Chart:
-		chrt = c
-	case chartv2.Chart:
-		chrt = &c
-	default:
-		return nil, errors.New("invalid chart apiVersion")
+	chrt, err := u.upgradeChart(ch)
+	if err != nil {
+		return nil, err
 	}
 
 	// Make sure wait is set if RollbackOnFailure. This makes it so
 	// the user doesn't have to specify both
-	if u.WaitStrategy == kube.HookOnlyStrategy && u.RollbackOnFailure {
+	if u.WaitStrategy == kube.HookOnlyStrategy && (u.RollbackOnFailure || u.Staged != nil) {
 		u.WaitStrategy = kube.StatusWatcherStrategy
 	}
 
@@ -192,11 +269,23 @@ func (u *Upgrade) RunWithContext(ctx context.Context, name string, ch chart.Char
 		return nil, fmt.Errorf("release name is invalid: %s", name)
 	}
 
+	if u.IdempotencyKey != "" && !isDryRun(u.DryRunStrategy) {
+		rel, err := u.cfg.idempotentRelease(ctx, name, u.IdempotencyKey, u.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		if rel != nil {
+			u.cfg.Logger().Info("release already upgraded with the same idempotency key", "name", name, "revision", rel.Version)
+			return rel, nil
+		}
+	}
+
 	u.cfg.Logger().Debug("preparing upgrade", "name", name)
 	currentRelease, upgradedRelease, serverSideApply, err := u.prepareUpgrade(ctx, name, chrt, vals)
 	if err != nil {
 		return nil, err
 	}
+	upgradedRelease.Info.IdempotencyKey = u.IdempotencyKey
 
 	u.cfg.Releases.MaxHistory = u.MaxHistory
 
@@ -217,9 +306,31 @@ func (u *Upgrade) RunWithContext(ctx context.Context, name string, ch chart.Char
 	return res, nil
 }
 
+// upgradeChart returns the chart to upgrade to, which is nil when reusing the
+// chart of the release.
+func (u *Upgrade) upgradeChart(ch chart.Charter) (*chartv2.Chart, error) {
+	var chrt *chartv2.Chart
+	switch c := ch.(type) {
+	case *chartv2.Chart:
+		chrt = c
+	case chartv2.Chart:
+		chrt = &c
+	case nil:
+		if !u.ReuseChart {
+			return nil, errMissingChart
+		}
+	default:
+		return nil, errors.New("invalid chart apiVersion")
+	}
+	if u.ReuseChart && chrt != nil {
+		return nil, errors.New("a chart cannot be given when reusing the chart of the release")
+	}
+	return chrt, nil
+}
+
 // prepareUpgrade builds an upgraded release for an upgrade operation.
 func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv2.Chart, vals map[string]any) (*release.Release, *release.Release, bool, error) {
-	if chart == nil {
+	if chart == nil && !u.ReuseChart {
 		return nil, nil, false, errMissingChart
 	}
 
@@ -228,8 +339,9 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 		return nil, nil, false, errors.New("hiding Kubernetes secrets requires a dry-run mode")
 	}
 
-	// finds the last non-deleted release with the given name
-	lastReleasei, err := u.cfg.Releases.Last(name)
+	// finds the last non-deleted release with the given name. The history is
+	// loaded once so that the templates can be told how many revisions it has.
+	revisions, err := u.cfg.Releases.History(name)
 	if err != nil {
 		// to keep existing behavior of returning the "%q has no deployed releases" error when an existing release does not exist
 		if errors.Is(err, driver.ErrReleaseNotFound) {
@@ -238,14 +350,14 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 		return nil, nil, false, err
 	}
 
-	lastRelease, err := releaserToV1Release(lastReleasei)
+	lastRelease, err := lastRevision(name, revisions)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
 	// Concurrent `helm upgrade`s will either fail here with `errPending` or when creating the release with "already exists". This should act as a pessimistic lock.
 	if lastRelease.Info.Status.IsPending() {
-		return nil, nil, false, errPending
+		return nil, nil, false, classify(errPending, ErrStorageConflict)
 	}
 
 	var currentRelease *release.Release
@@ -270,13 +382,41 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 		}
 	}
 
+	needs := currentRelease.Needs
+	if u.Needs != nil {
+		needs = qualifyNeeds(u.Needs, currentRelease.Namespace)
+	}
+	if !isDryRun(u.DryRunStrategy) {
+		if err := u.cfg.checkNeeds(currentRelease.Namespace, needs); err != nil {
+			return nil, nil, false, fmt.Errorf("release %s cannot be upgraded: %w", name, err)
+		}
+	}
+
+	source := u.Source
+	if u.ReuseChart {
+		if currentRelease.Chart == nil {
+			return nil, nil, false, fmt.Errorf("release %q has no stored chart to reuse", name)
+		}
+		// Copy the chart so the stored release is not modified when the
+		// values are reused.
+		c := *currentRelease.Chart
+		chart, source = &c, currentRelease.ChartSource
+	}
+	if chart.Metadata != nil && chart.Metadata.Deprecated {
+		u.cfg.warn(WarningDeprecatedChart, fmt.Sprintf("chart %s is deprecated", chart.Name()))
+	}
+
 	// determine if values will be reused
 	vals, err = u.reuseValues(chart, currentRelease, vals)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
-	if err := chartutil.ProcessDependencies(chart, vals); err != nil {
+	processDependencies := chartutil.ProcessDependencies
+	if u.SkipSchemaValidation {
+		processDependencies = chartutil.ProcessDependenciesWithoutValidation
+	}
+	if err := processDependencies(chart, vals); err != nil {
 		return nil, nil, false, err
 	}
 
@@ -284,11 +424,23 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 	// the release object.
 	revision := lastRelease.Version + 1
 
+	history := u.releaseHistory(currentRelease, len(revisions))
 	options := common.ReleaseOptions{
 		Name:      name,
 		Namespace: currentRelease.Namespace,
 		Revision:  revision,
 		IsUpgrade: true,
+		History:   history,
+	}
+	if u.Slot != "" {
+		if err := validateSlot(u.Slot); err != nil {
+			return nil, nil, false, err
+		}
+		// The first slot deployed is active until another is promoted.
+		options.Slot = u.Slot
+		options.ActiveSlot = cmp.Or(currentRelease.ActiveSlot, u.Slot)
+	} else if len(currentRelease.Slots) > 0 {
+		return nil, nil, false, fmt.Errorf("release %q has blue/green slots: upgrade one of them with a slot", name)
 	}
 
 	caps, err := u.cfg.getCapabilities()
@@ -299,10 +451,26 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 	if err != nil {
 		return nil, nil, false, err
 	}
+	if !u.SkipSchemaValidation {
+		if err := u.cfg.checkUnknownValues(chart, vals, u.StrictValuesSchema); err != nil {
+			return nil, nil, false, err
+		}
+	}
 
+	renderStart := time.Now()
 	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(ctx, chart, valuesToRender, "", "", u.SubNotes, false, false, u.PostRenderer, interactWithServer(u.DryRunStrategy), u.EnableDNS, u.HideSecret, u.PostRenderStrategy)
 	if err != nil {
-		return nil, nil, false, err
+		return nil, nil, false, classify(err, ErrRenderFailure)
+	}
+	renderTime := time.Since(renderStart)
+
+	manifest := manifestDoc.String()
+	var slots map[string]string
+	if u.Slot != "" {
+		manifest, slots, err = slotManifest(manifest, u.Slot, currentRelease.Slots)
+		if err != nil {
+			return nil, nil, false, err
+		}
 	}
 
 	if driver.ContainsSystemLabels(u.Labels) {
@@ -327,23 +495,37 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 			LastDeployed:  Timestamper(),
 			Status:        rcommon.StatusPendingUpgrade,
 			Description:   "Preparing upgrade", // This should be overwritten later.
+			Timings:       release.Timings{Render: renderTime},
 		},
 		Version:     revision,
-		Manifest:    manifestDoc.String(),
+		Manifest:    manifest,
 		Hooks:       hooks,
 		Labels:      mergeCustomLabels(lastRelease.Labels, u.Labels),
+		Annotations: mergeCustomLabels(lastRelease.Annotations, u.Annotations),
 		ApplyMethod: string(determineReleaseSSApplyMethod(serverSideApply)),
+		ChartSource: source,
+		Retention:   retentionFor(u.Retention, lastRelease),
+		Needs:       needs,
+		Slots:       slots,
+		ActiveSlot:  options.ActiveSlot,
 	}
 
 	if len(notesTxt) > 0 {
 		upgradedRelease.Info.Notes = notesTxt
 	}
-	err = validateManifest(u.cfg.KubeClient, manifestDoc.Bytes(), !u.DisableOpenAPIValidation)
+	validateStart := time.Now()
+	err = validateManifest(u.cfg.KubeClient, []byte(manifest), !u.DisableOpenAPIValidation)
+	addPhaseTime(&upgradedRelease.Info.Timings.Validate, validateStart)
 	return currentRelease, upgradedRelease, serverSideApply, err
 }
 
 func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedRelease *release.Release, serverSideApply bool) (*release.Release, error) {
-	current, err := u.cfg.KubeClient.Build(bytes.NewBufferString(originalRelease.Manifest), false)
+	currentManifest, targetManifest := originalRelease.Manifest, upgradedRelease.Manifest
+	if u.onlyChanged {
+		currentManifest, targetManifest = changedManifests(currentManifest, targetManifest)
+	}
+
+	current, err := u.cfg.KubeClient.Build(bytes.NewBufferString(currentManifest), false)
 	if err != nil {
 		// Checking for removed Kubernetes API error so can provide a more informative error message to the user
 		// Ref: https://github.com/helm/helm/issues/7219
@@ -354,16 +536,36 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		}
 		return upgradedRelease, fmt.Errorf("unable to build kubernetes objects from current release manifest: %w", err)
 	}
-	target, err := u.cfg.KubeClient.Build(bytes.NewBufferString(upgradedRelease.Manifest), !u.DisableOpenAPIValidation)
+	validateStart := time.Now()
+	target, err := u.cfg.KubeClient.Build(bytes.NewBufferString(targetManifest), !u.DisableOpenAPIValidation)
 	if err != nil {
 		return upgradedRelease, fmt.Errorf("unable to build kubernetes objects from new release manifest: %w", err)
 	}
+	addPhaseTime(&upgradedRelease.Info.Timings.Validate, validateStart)
 
 	// It is safe to use force only on target because these are resources currently rendered by the chart.
 	err = target.Visit(setMetadataVisitor(upgradedRelease.Name, upgradedRelease.Namespace, true))
 	if err != nil {
 		return upgradedRelease, err
 	}
+	if err := restoreNamespaces(current, originalRelease.Resources); err != nil {
+		return upgradedRelease, err
+	}
+
+	// Only the changed resources are built when onlyChanged is set, so the
+	// full manifest is built to record the resources of the release.
+	all := target
+	if u.onlyChanged {
+		if all, err = u.cfg.KubeClient.Build(bytes.NewBufferString(upgradedRelease.Manifest), false); err != nil {
+			return upgradedRelease, fmt.Errorf("unable to build kubernetes objects from new release manifest: %w", err)
+		}
+	}
+	if u.RestrictNamespace {
+		if err := checkCrossNamespace(all, upgradedRelease.Namespace); err != nil {
+			return upgradedRelease, err
+		}
+	}
+	upgradedRelease.Resources = resourceRefs(all)
 
 	// Do a basic diff using gvk + name to figure out what new resources are being created so we can validate they don't already exist
 	existingResources := make(map[string]bool)
@@ -371,18 +573,23 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		existingResources[objectKey(r)] = true
 	}
 
-	var toBeCreated kube.ResourceList
+	var toBeCreated, toBeKept kube.ResourceList
 	for _, r := range target {
 		if !existingResources[objectKey(r)] {
 			toBeCreated = append(toBeCreated, r)
+		} else {
+			toBeKept = append(toBeKept, r)
 		}
 	}
+	if err := keepSharedOwners(toBeKept); err != nil {
+		return nil, fmt.Errorf("unable to continue with update: %w", err)
+	}
 
 	var toBeUpdated kube.ResourceList
 	if u.TakeOwnership {
 		toBeUpdated, err = requireAdoption(toBeCreated)
 	} else {
-		toBeUpdated, err = existingResourceConflict(toBeCreated, upgradedRelease.Name, upgradedRelease.Namespace)
+		toBeUpdated, err = existingResourceConflict(toBeCreated, upgradedRelease.Name, upgradedRelease.Namespace, u.ShareClusterScoped)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("unable to continue with update: %w", err)
@@ -406,45 +613,68 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		return upgradedRelease, nil
 	}
 
+	// Resources removed from the chart are kept when they are still shared
+	// with other releases. The release only leaves their owners once the
+	// update succeeded, so that it still owns them if the update fails.
+	targetResources := make(map[string]bool)
+	for _, r := range target {
+		targetResources[objectKey(r)] = true
+	}
+	var toBeRemoved kube.ResourceList
+	for _, r := range current {
+		if !targetResources[objectKey(r)] {
+			toBeRemoved = append(toBeRemoved, r)
+		}
+	}
+	_, shared, err := releaseSharedOwnership(toBeRemoved, upgradedRelease.Name, upgradedRelease.Namespace, true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to continue with update: %w", err)
+	}
+	if len(shared) > 0 {
+		current = current.Difference(shared)
+	}
+
 	u.cfg.Logger().Debug("creating upgraded release", "name", upgradedRelease.Name)
 	if err := u.cfg.Releases.Create(upgradedRelease); err != nil {
+		if errors.Is(err, driver.ErrReleaseExists) {
+			err = classify(err, ErrStorageConflict)
+		}
 		return nil, err
 	}
-	rChan := make(chan resultMessage)
-	ctxChan := make(chan resultMessage)
+	res := &upgradeResult{c: make(chan resultMessage, 1)}
 	doneChan := make(chan any)
 	defer close(doneChan)
-	go u.releasingUpgrade(rChan, upgradedRelease, current, target, originalRelease, serverSideApply)
-	go u.handleContext(ctx, doneChan, ctxChan, upgradedRelease)
+	go u.releasingUpgrade(ctx, res, upgradedRelease, current, target, shared, originalRelease, serverSideApply)
+	go u.handleContext(ctx, doneChan, res, upgradedRelease)
 
-	select {
-	case result := <-rChan:
-		return result.r, result.e
-	case result := <-ctxChan:
-		return result.r, result.e
-	}
+	result := <-res.c
+	return result.r, result.e
 }
 
 // Function used to lock the Mutex, this is important for the case when RollbackOnFailure is set.
 // In that case the upgrade will finish before the rollback is finished so it is necessary to wait for the rollback to finish.
 // The rollback will be trigger by the function failRelease
-func (u *Upgrade) reportToPerformUpgrade(c chan<- resultMessage, rel *release.Release, created kube.ResourceList, err error) {
-	u.Lock.Lock()
-	if err != nil {
-		rel, err = u.failRelease(rel, created, err)
-	}
-	c <- resultMessage{r: rel, e: err}
-	u.Lock.Unlock()
+func (u *Upgrade) reportToPerformUpgrade(res *upgradeResult, rel *release.Release, created kube.ResourceList, err error) {
+	res.once.Do(func() {
+		u.Lock.Lock()
+		defer u.Lock.Unlock()
+		if err != nil {
+			if stage := res.currentStage(); u.Staged != nil && stage != "" {
+				u.Staged.report(stage, StagePhaseFailed, nil, err)
+			}
+			rel, err = u.failRelease(rel, created, err)
+		}
+		res.c <- resultMessage{r: rel, e: err}
+	})
 }
 
 // Setup listener for SIGINT and SIGTERM
-func (u *Upgrade) handleContext(ctx context.Context, done chan any, c chan<- resultMessage, upgradedRelease *release.Release) {
+func (u *Upgrade) handleContext(ctx context.Context, done chan any, res *upgradeResult, upgradedRelease *release.Release) {
 	select {
 	case <-ctx.Done():
-		err := ctx.Err()
-
 		// when RollbackOnFailure is set, the ongoing release finish first and doesn't give time for the rollback happens.
-		u.reportToPerformUpgrade(c, upgradedRelease, kube.ResourceList{}, err)
+		// The ongoing release stops at the end of its current phase.
+		u.reportToPerformUpgrade(res, upgradedRelease, kube.ResourceList{}, interrupted(ctx))
 	case <-done:
 		return
 	}
@@ -454,74 +684,169 @@ func isReleaseApplyMethodClientSideApply(applyMethod string) bool {
 	return applyMethod == "" || applyMethod == string(release.ApplyMethodClientSideApply)
 }
 
-func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *release.Release, current kube.ResourceList, target kube.ResourceList, originalRelease *release.Release, serverSideApply bool) {
+func (u *Upgrade) releasingUpgrade(ctx context.Context, res *upgradeResult, upgradedRelease *release.Release, current kube.ResourceList, target kube.ResourceList, shared kube.ResourceList, originalRelease *release.Release, serverSideApply bool) {
+	start := time.Now()
+
 	// pre-upgrade hooks
 
 	if !u.DisableHooks {
-		if err := u.cfg.execHook(upgradedRelease, release.HookPreUpgrade, u.WaitStrategy, u.WaitOptions, u.Timeout, serverSideApply); err != nil {
-			u.reportToPerformUpgrade(c, upgradedRelease, kube.ResourceList{}, fmt.Errorf("pre-upgrade hooks failed: %w", err))
+		phaseStart := time.Now()
+		if err := u.cfg.execHook(upgradedRelease, release.HookPreUpgrade, u.WaitStrategy, u.WaitOptions, u.PhaseTimeouts.hooks(u.Timeout), serverSideApply); err != nil {
+			u.reportToPerformUpgrade(res, upgradedRelease, kube.ResourceList{}, fmt.Errorf("pre-upgrade hooks failed: %w", err))
 			return
 		}
+		addPhaseTime(&upgradedRelease.Info.Timings.Hooks, phaseStart)
 	} else {
 		u.cfg.Logger().Debug("upgrade hooks disabled", "name", upgradedRelease.Name)
 	}
+	if ctx.Err() != nil {
+		return
+	}
 
 	upgradeClientSideFieldManager := isReleaseApplyMethodClientSideApply(originalRelease.ApplyMethod) && serverSideApply // Update client-side field manager if transitioning from client-side to server-side apply
-	results, err := u.cfg.KubeClient.Update(
-		current,
-		target,
+	updateOptions := []kube.ClientUpdateOption{
 		kube.ClientUpdateOptionForceReplace(u.ForceReplace),
 		kube.ClientUpdateOptionServerSideApply(serverSideApply, u.ForceConflicts),
-		kube.ClientUpdateOptionUpgradeClientSideFieldManager(upgradeClientSideFieldManager))
+		kube.ClientUpdateOptionUpgradeClientSideFieldManager(upgradeClientSideFieldManager),
+	}
+	phaseStart := time.Now()
+	var results *kube.Result
+
+	// A staged upgrade applies the canary resources first, and the others
+	// below.
+	applyCurrent, applyTarget := current, target
+	if u.Staged != nil {
+		waiter, err := u.getWaiter()
+		if err != nil {
+			u.cfg.recordRelease(originalRelease)
+			u.reportToPerformUpgrade(res, upgradedRelease, kube.ResourceList{}, err)
+			return
+		}
+		var created kube.ResourceList
+		applyCurrent, applyTarget, created, err = u.applyCanaryStage(ctx, res, waiter, current, target, updateOptions...)
+		if err != nil {
+			u.cfg.recordRelease(originalRelease)
+			u.reportToPerformUpgrade(res, upgradedRelease, created, err)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		results = &kube.Result{Created: created}
+		res.setStage(StageRemainder)
+		u.Staged.report(StageRemainder, StagePhaseApplying, applyTarget, nil)
+	}
+
+	applyDeadline := deadline(u.PhaseTimeouts.Apply)
+	applyOptions := append(slices.Clone(updateOptions), kube.ClientUpdateOptionDeadline(applyDeadline))
+	err := u.Retry.do(u.cfg.Logger(), applyDeadline, func() error {
+		res, err := u.cfg.KubeClient.Update(applyCurrent, applyTarget, applyOptions...)
+		if results == nil {
+			results = res
+		} else if res != nil {
+			// Keep track of the resources created by the previous attempts
+			// so that they are cleaned up if the upgrade fails.
+			results.Created = append(results.Created, res.Created...)
+		}
+		if err != nil && res != nil {
+			// The resources created by this attempt exist now and are
+			// updated by the next one.
+			applyCurrent = append(applyCurrent, res.Created...)
+		}
+		return err
+	})
 	if err != nil {
+		var created kube.ResourceList
+		if results != nil {
+			created = results.Created
+		}
 		u.cfg.recordRelease(originalRelease)
-		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
+		u.reportToPerformUpgrade(res, upgradedRelease, created, phaseError(err, "applying the resources", u.PhaseTimeouts.Apply, applyDeadline))
 		return
 	}
-
-	var waiter kube.Waiter
-	if c, supportsOptions := u.cfg.KubeClient.(kube.InterfaceWaitOptions); supportsOptions {
-		waiter, err = c.GetWaiterWithOptions(u.WaitStrategy, u.WaitOptions...)
-	} else {
-		waiter, err = u.cfg.KubeClient.GetWaiter(u.WaitStrategy)
+	addPhaseTime(&upgradedRelease.Info.Timings.Apply, phaseStart)
+
+	// The update deleted the resources removed from the chart, except the
+	// shared ones, which the release now leaves to their other owners.
+	if len(shared) > 0 {
+		if _, _, err := releaseSharedOwnership(shared, upgradedRelease.Name, upgradedRelease.Namespace, false); err != nil {
+			// The release is still listed as an owner, which only keeps
+			// the resources from being deleted with the other owners.
+			u.cfg.Logger().Warn("unable to release the ownership of shared resources", "name", upgradedRelease.Name, slog.Any("error", err))
+		}
 	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	waiter, err := u.getWaiter()
 	if err != nil {
 		u.cfg.recordRelease(originalRelease)
-		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
+		u.reportToPerformUpgrade(res, upgradedRelease, results.Created, err)
 		return
 	}
+	if u.Staged != nil {
+		u.Staged.report(StageRemainder, StagePhaseWaiting, applyTarget, nil)
+	}
+	phaseStart = time.Now()
 	if u.WaitForJobs {
-		if err := waiter.WaitWithJobs(target, u.Timeout); err != nil {
+		if err := waiter.WaitWithJobs(target, u.PhaseTimeouts.wait(u.Timeout)); err != nil {
 			u.cfg.recordRelease(originalRelease)
-			u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
+			u.reportToPerformUpgrade(res, upgradedRelease, results.Created, err)
 			return
 		}
 	} else {
-		if err := waiter.Wait(target, u.Timeout); err != nil {
+		if err := waiter.Wait(target, u.PhaseTimeouts.wait(u.Timeout)); err != nil {
 			u.cfg.recordRelease(originalRelease)
-			u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
+			u.reportToPerformUpgrade(res, upgradedRelease, results.Created, err)
 			return
 		}
 	}
 
+	addPhaseTime(&upgradedRelease.Info.Timings.Wait, phaseStart)
+	if ctx.Err() != nil {
+		return
+	}
+	if u.Staged != nil {
+		res.setStage("")
+		u.Staged.report(StageRemainder, StagePhaseSucceeded, applyTarget, nil)
+	}
+
 	// post-upgrade hooks
 	if !u.DisableHooks {
-		if err := u.cfg.execHook(upgradedRelease, release.HookPostUpgrade, u.WaitStrategy, u.WaitOptions, u.Timeout, serverSideApply); err != nil {
-			u.reportToPerformUpgrade(c, upgradedRelease, results.Created, fmt.Errorf("post-upgrade hooks failed: %w", err))
+		phaseStart = time.Now()
+		if err := u.cfg.execHook(upgradedRelease, release.HookPostUpgrade, u.WaitStrategy, u.WaitOptions, u.PhaseTimeouts.hooks(u.Timeout), serverSideApply); err != nil {
+			u.reportToPerformUpgrade(res, upgradedRelease, results.Created, fmt.Errorf("post-upgrade hooks failed: %w", err))
 			return
 		}
+		addPhaseTime(&upgradedRelease.Info.Timings.Hooks, phaseStart)
 	}
 
-	originalRelease.Info.Status = rcommon.StatusSuperseded
-	u.cfg.recordRelease(originalRelease)
+	// The previous release is only superseded if the upgrade was not
+	// reported as interrupted in the meantime.
+	res.once.Do(func() {
+		u.Lock.Lock()
+		defer u.Lock.Unlock()
+		originalRelease.Info.Status = rcommon.StatusSuperseded
+		u.cfg.recordRelease(originalRelease)
 
-	upgradedRelease.Info.Status = rcommon.StatusDeployed
-	if len(u.Description) > 0 {
-		upgradedRelease.Info.Description = u.Description
-	} else {
-		upgradedRelease.Info.Description = "Upgrade complete"
+		upgradedRelease.Info.Status = rcommon.StatusDeployed
+		if len(u.Description) > 0 {
+			upgradedRelease.Info.Description = u.Description
+		} else {
+			upgradedRelease.Info.Description = "Upgrade complete"
+		}
+		setDeployStats(upgradedRelease, start)
+		res.c <- resultMessage{r: upgradedRelease}
+	})
+}
+
+// getWaiter returns the waiter of the wait strategy of the upgrade.
+func (u *Upgrade) getWaiter() (kube.Waiter, error) {
+	if c, supportsOptions := u.cfg.KubeClient.(kube.InterfaceWaitOptions); supportsOptions {
+		return c.GetWaiterWithOptions(u.WaitStrategy, u.WaitOptions...)
 	}
-	u.reportToPerformUpgrade(c, upgradedRelease, nil, nil)
+	return u.cfg.KubeClient.GetWaiter(u.WaitStrategy)
 }
 
 func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, err error) (*release.Release, error) {
@@ -551,7 +876,7 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 		u.cfg.Logger().Debug("resource cleanup complete")
 	}
 
-	if u.RollbackOnFailure {
+	if u.RollbackOnFailure || u.Staged != nil {
 		u.cfg.Logger().Debug("Upgrade failed and rollback-on-failure is set, rolling back to previous successful release")
 
 		// As a protection, get the last successful release before rollback.
@@ -605,6 +930,9 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 //
 // This is skipped if the u.ResetValues flag is set, in which case the
 // request values are not altered.
+//
+// Values carried over from the current release are first migrated with the
+// values migrations of the new chart when its major version increases.
 func (u *Upgrade) reuseValues(chart *chartv2.Chart, current *release.Release, newVals map[string]any) (map[string]any, error) {
 	if u.ResetValues {
 		// If ResetValues is set, we completely ignore current.Config.
@@ -612,6 +940,12 @@ func (u *Upgrade) reuseValues(chart *chartv2.Chart, current *release.Release, ne
 		return newVals, nil
 	}
 
+	// Migrate the old values when upgrading to a new major chart version.
+	currentConfig, err := migrateReleaseValues(current, chart, current.Config)
+	if err != nil {
+		return nil, err
+	}
+
 	// If the ReuseValues flag is set, we always copy the old values over the new config's values.
 	if u.ReuseValues {
 		u.cfg.Logger().Debug("reusing the old release's values")
@@ -621,8 +955,12 @@ func (u *Upgrade) reuseValues(chart *chartv2.Chart, current *release.Release, ne
 		if err != nil {
 			return nil, fmt.Errorf("failed to rebuild old values: %w", err)
 		}
+		oldVals, err = migrateReleaseValues(current, chart, oldVals)
+		if err != nil {
+			return nil, err
+		}
 
-		newVals = util.CoalesceTables(newVals, current.Config)
+		newVals = util.CoalesceTables(newVals, currentConfig)
 
 		chart.Values = oldVals
 
@@ -633,18 +971,73 @@ func (u *Upgrade) reuseValues(chart *chartv2.Chart, current *release.Release, ne
 	if u.ResetThenReuseValues {
 		u.cfg.Logger().Debug("merging values from old release to new values")
 
-		newVals = util.CoalesceTables(newVals, current.Config)
+		newVals = util.CoalesceTables(newVals, currentConfig)
 
 		return newVals, nil
 	}
 
-	if len(newVals) == 0 && len(current.Config) > 0 {
+	if len(newVals) == 0 && len(currentConfig) > 0 {
 		u.cfg.Logger().Debug("copying values from old release", "name", current.Name, "version", current.Version)
-		newVals = current.Config
+		newVals = currentConfig
 	}
 	return newVals, nil
 }
 
+// lastRevision returns the revision with the highest version of a release.
+func lastRevision(name string, revisions []ri.Releaser) (*release.Release, error) {
+	var last *release.Release
+	for _, r := range revisions {
+		rel, err := releaserToV1Release(r)
+		if err != nil {
+			return nil, err
+		}
+		if last == nil || rel.Version > last.Version {
+			last = rel
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("no revision for release %q", name)
+	}
+	return last, nil
+}
+
+// releaseHistory describes the revisions of the release to the templates of
+// the upgrade, from the current release the upgrade starts from. The history
+// is informational, so when it cannot be described the templates get an empty
+// one rather than failing the upgrade.
+func (u *Upgrade) releaseHistory(current *release.Release, revisions int) *common.ReleaseHistory {
+	config, err := json.Marshal(current.Config)
+	if err != nil {
+		u.cfg.Logger().Warn("failed to hash the values of the release, rendering with an empty history", "name", current.Name, slog.Any("error", err))
+		return &common.ReleaseHistory{}
+	}
+	sum := sha256.Sum256(config)
+	h := &common.ReleaseHistory{
+		Revision:   current.Version,
+		Revisions:  revisions,
+		ValuesHash: "sha256:" + hex.EncodeToString(sum[:]),
+	}
+	if current.Chart != nil && current.Chart.Metadata != nil {
+		h.ChartName = current.Chart.Metadata.Name
+		h.ChartVersion = current.Chart.Metadata.Version
+		h.AppVersion = current.Chart.Metadata.AppVersion
+	}
+	return h
+}
+
+// migrateReleaseValues applies the values migrations of chart to values of the
+// current release.
+func migrateReleaseValues(current *release.Release, chart *chartv2.Chart, vals map[string]any) (map[string]any, error) {
+	if current.Chart == nil || current.Chart.Metadata == nil {
+		return vals, nil
+	}
+	migrated, err := migrateValues(current.Chart.Metadata.Version, chart, vals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate values of release %q: %w", current.Name, err)
+	}
+	return migrated, nil
+}
+
 func validateManifest(c kube.Interface, manifest []byte, openAPIValidation bool) error {
 	_, err := c.Build(bytes.NewReader(manifest), openAPIValidation)
 	return err
@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
 
+	chartcommon "helm.sh/helm/v4/pkg/chart/common"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
@@ -80,6 +81,76 @@ func TestUpgradeRelease_Success(t *testing.T) {
 	is.Equal(lastRelease.Info.Status, common.StatusDeployed)
 }
 
+func TestUpgradeRelease_History(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "historic"
+	rel.Info.Status = common.StatusSuperseded
+	req.NoError(upAction.cfg.Releases.Create(rel))
+	rel = releaseStub()
+	rel.Name = "historic"
+	rel.Version = 2
+	rel.Chart.Metadata.Version = "0.0.9"
+	rel.Chart.Metadata.AppVersion = "1.0"
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	ch := buildChartWithTemplates([]*chartcommon.File{{
+		Name: "templates/history",
+		Data: []byte(`# from {{ .Release.History.ChartName }} {{ .Release.History.ChartVersion }} ({{ .Release.History.AppVersion }}) revision {{ .Release.History.Revision }} of {{ .Release.History.Revisions }}, {{ .Release.History.ValuesHash }}`),
+	}})
+	resi, err := upAction.Run(rel.Name, ch, map[string]any{})
+	req.NoError(err)
+	res, err := releaserToV1Release(resi)
+	req.NoError(err)
+	// sha256 of {"name":"value"}
+	is.Contains(res.Manifest, "# from hello 0.0.9 (1.0) revision 2 of 2, sha256:ae1fca77a81ea8b568ef60cdad1dee6bae1faaf716cddca2f5750b7cdc9b6ed4")
+}
+
+func TestUpgradeRelease_Slot(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "bluegreen"
+	rel.Info.Status = common.StatusDeployed
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	ch := buildChartWithTemplates([]*chartcommon.File{{
+		Name: "templates/service",
+		Data: []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  selector:\n    helm.sh/slot: {{ .Release.ActiveSlot }}\n"),
+	}, {
+		Name: "templates/deployment",
+		Data: []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app-{{ .Release.Slot }}\n  labels:\n    helm.sh/slot: {{ .Release.Slot }}\n"),
+	}})
+
+	upAction.Slot = "blue"
+	resi, err := upAction.Run(rel.Name, ch, map[string]any{})
+	req.NoError(err)
+	res, err := releaserToV1Release(resi)
+	req.NoError(err)
+	is.Equal("blue", res.ActiveSlot)
+	is.Contains(res.Slots["blue"], "name: app-blue")
+
+	upAction.Slot = "green"
+	resi, err = upAction.Run(rel.Name, ch, map[string]any{})
+	req.NoError(err)
+	res, err = releaserToV1Release(resi)
+	req.NoError(err)
+	is.Equal("blue", res.ActiveSlot, "the active slot only changes when a slot is promoted")
+	is.Contains(res.Manifest, "name: app-blue")
+	is.Contains(res.Manifest, "name: app-green")
+	is.Contains(res.Manifest, "helm.sh/slot: blue\n")
+	is.Len(res.Slots, 2)
+
+	upAction.Slot = ""
+	_, err = upAction.Run(rel.Name, ch, map[string]any{})
+	is.ErrorContains(err, "has blue/green slots")
+}
+
 func TestUpgradeRelease_Wait(t *testing.T) {
 	is := assert.New(t)
 	req := require.New(t)
@@ -211,6 +282,32 @@ func TestUpgradeRelease_RollbackOnFailure(t *testing.T) {
 	})
 }
 
+func TestUpgradeRelease_ReuseChart(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "reuse-chart"
+	rel.Info.Status = common.StatusDeployed
+	rel.ChartSource = &common.ChartSource{Ref: "oci://registry.example.com/charts/hello", Digest: "sha256:abc"}
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	upAction.ReuseChart = true
+	resi, err := upAction.Run(rel.Name, nil, map[string]any{"name": "value"})
+	req.NoError(err)
+	res, err := releaserToV1Release(resi)
+	req.NoError(err)
+
+	is.Equal(2, res.Version)
+	is.Equal(rel.Chart.Metadata.Name, res.Chart.Metadata.Name)
+	is.Equal(rel.ChartSource, res.ChartSource)
+	is.Equal(map[string]any{"name": "value"}, res.Config)
+
+	_, err = upAction.Run(rel.Name, buildChart(), nil)
+	is.Error(err)
+}
+
 func TestUpgradeRelease_ReuseValues(t *testing.T) {
 	is := assert.New(t)
 
@@ -445,6 +542,36 @@ func TestUpgradeRelease_Interrupted_Wait(t *testing.T) {
 	is.Equal(res.Info.Status, common.StatusFailed)
 }
 
+func TestUpgradeRelease_Interrupted_KeepsPreviousRelease(t *testing.T) {
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "interrupted-release"
+	rel.Info.Status = common.StatusDeployed
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WaitDuration = 500 * time.Millisecond
+	upAction.WaitStrategy = kube.StatusWatcherStrategy
+
+	ctx, cancel := context.WithCancelCause(t.Context())
+	time.AfterFunc(100*time.Millisecond, func() { cancel(fmt.Errorf("received interrupt: %w", context.Canceled)) })
+
+	_, err := upAction.RunWithContext(ctx, rel.Name, buildChart(), nil)
+	require.ErrorIs(t, err, ErrInterrupted)
+	assert.ErrorContains(t, err, "received interrupt: context canceled")
+
+	// Once the wait completes, the interrupted upgrade stops instead of
+	// superseding the previous release.
+	time.Sleep(time.Second)
+	upAction.Lock.Lock()
+	defer upAction.Lock.Unlock()
+	previous, err := upAction.cfg.Releases.Get(rel.Name, rel.Version)
+	require.NoError(t, err)
+	previousRel, err := releaserToV1Release(previous)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusDeployed, previousRel.Info.Status)
+}
+
 func TestUpgradeRelease_Interrupted_RollbackOnFailure(t *testing.T) {
 	is := assert.New(t)
 	req := require.New(t)
@@ -548,6 +675,36 @@ func TestUpgradeRelease_Labels(t *testing.T) {
 	is.Equal(initialRes.Labels, rel.Labels)
 }
 
+func TestUpgradeRelease_Annotations(t *testing.T) {
+	is := assert.New(t)
+	upAction := upgradeAction(t)
+
+	rel := releaseStub()
+	rel.Name = "annotations"
+	rel.Annotations = map[string]string{
+		"example.com/team":        "payments",
+		"example.com/cost-center": "42",
+	}
+	rel.Info.Status = common.StatusDeployed
+	is.NoError(upAction.cfg.Releases.Create(rel))
+
+	upAction.Annotations = map[string]string{
+		"example.com/cost-center": "null",
+		"example.com/owner":       "alice",
+	}
+	_, err := upAction.Run(rel.Name, buildChart(), nil)
+	is.NoError(err)
+
+	updatedResi, err := upAction.cfg.Releases.Get(rel.Name, 2)
+	is.NoError(err)
+	updatedRes, err := releaserToV1Release(updatedResi)
+	is.NoError(err)
+	is.Equal(map[string]string{
+		"example.com/team":  "payments",
+		"example.com/owner": "alice",
+	}, updatedRes.Annotations)
+}
+
 func TestUpgradeRelease_SystemLabels(t *testing.T) {
 	is := assert.New(t)
 	upAction := upgradeAction(t)
@@ -800,3 +957,41 @@ func TestUpgradeRelease_WaitOptionsPassedDownstream(t *testing.T) {
 	// Verify that WaitOptions were passed to GetWaiter
 	is.NotEmpty(failer.RecordedWaitOptions, "WaitOptions should be passed to GetWaiter")
 }
+
+func TestUpgradeRelease_Retention(t *testing.T) {
+	recorded := &release.Retention{MaxHistory: 3, MaxAge: 24 * time.Hour}
+
+	tests := []struct {
+		name      string
+		retention *release.Retention
+		want      *release.Retention
+	}{
+		{
+			name: "carried over from the previous revision",
+			want: recorded,
+		},
+		{
+			name:      "overridden by the upgrade",
+			retention: &release.Retention{MaxHistory: 5},
+			want:      &release.Retention{MaxHistory: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upAction := upgradeAction(t)
+			rel := releaseStub()
+			rel.Name = "retained"
+			rel.Info.Status = common.StatusDeployed
+			rel.Retention = recorded
+			require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+			upAction.Retention = tt.retention
+			resi, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+			require.NoError(t, err)
+			res, err := releaserToV1Release(resi)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res.Retention)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	ri "helm.sh/helm/v4/pkg/release"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// UpgradeValues is the action for changing the values of a release while
+// keeping its chart.
+//
+// The chart stored in the deployed release is rendered with the new values
+// and only the resources whose manifests changed are sent to Kubernetes.
+// Resources that render identically are neither updated nor validated again.
+//
+// It provides the implementation of 'helm upgrade --values-only'.
+type UpgradeValues struct {
+	*Upgrade
+}
+
+// NewUpgradeValues creates a new UpgradeValues object with the given configuration.
+func NewUpgradeValues(cfg *Configuration) *UpgradeValues {
+	return &UpgradeValues{Upgrade: NewUpgrade(cfg)}
+}
+
+// Run changes the values of the given release.
+func (u *UpgradeValues) Run(name string, vals map[string]any) (ri.Releaser, error) {
+	return u.RunWithContext(context.Background(), name, vals)
+}
+
+// RunWithContext changes the values of the given release with context.
+func (u *UpgradeValues) RunWithContext(ctx context.Context, name string, vals map[string]any) (ri.Releaser, error) {
+	u.ReuseChart = true
+	u.onlyChanged = true
+	return u.Upgrade.RunWithContext(ctx, name, nil, vals)
+}
+
+// changedManifests drops the documents that are identical in both manifests,
+// returning the documents that only exist in current and in target.
+func changedManifests(current, target string) (string, string) {
+	currentDocs := manifestDocs(current)
+	targetDocs := manifestDocs(target)
+
+	unchanged := map[string]bool{}
+	for _, d := range targetDocs {
+		unchanged[d] = true
+	}
+	var removed []string
+	inCurrent := map[string]bool{}
+	for _, d := range currentDocs {
+		inCurrent[d] = true
+		if !unchanged[d] {
+			removed = append(removed, d)
+		}
+	}
+	var changed []string
+	for _, d := range targetDocs {
+		if !inCurrent[d] {
+			changed = append(changed, d)
+		}
+	}
+	return joinManifestDocs(removed), joinManifestDocs(changed)
+}
+
+func manifestDocs(manifest string) []string {
+	split := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(split))
+	for k := range split {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	docs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		docs = append(docs, strings.TrimSpace(split[k]))
+	}
+	return docs
+}
+
+func joinManifestDocs(docs []string) string {
+	if len(docs) == 0 {
+		return ""
+	}
+	return "---\n" + strings.Join(docs, "\n---\n") + "\n"
+}
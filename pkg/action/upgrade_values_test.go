@@ -0,0 +1,81 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestChangedManifests(t *testing.T) {
+	tests := []struct {
+		name            string
+		current, target string
+		wantCurrent     string
+		wantTarget      string
+	}{
+		{
+			name:    "unchanged",
+			current: "---\na: 1\n---\nb: 1\n",
+			target:  "---\na: 1\n---\nb: 1\n",
+		},
+		{
+			name:        "changed document",
+			current:     "---\na: 1\n---\nb: 1\n",
+			target:      "---\na: 1\n---\nb: 2\n",
+			wantCurrent: "---\nb: 1\n",
+			wantTarget:  "---\nb: 2\n",
+		},
+		{
+			name:        "added and removed documents",
+			current:     "---\na: 1\n---\nb: 1\n",
+			target:      "---\nb: 1\n---\nc: 1\n---\nd: 1\n",
+			wantCurrent: "---\na: 1\n",
+			wantTarget:  "---\nc: 1\n---\nd: 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, target := changedManifests(tt.current, tt.target)
+			assert.Equal(t, tt.wantCurrent, current)
+			assert.Equal(t, tt.wantTarget, target)
+		})
+	}
+}
+
+func TestUpgradeValues(t *testing.T) {
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "values-only"
+	rel.Info.Status = common.StatusDeployed
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	client := &UpgradeValues{Upgrade: upAction}
+	resi, err := client.Run(rel.Name, map[string]any{"name": "value"})
+	require.NoError(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, res.Version)
+	assert.Equal(t, common.StatusDeployed, res.Info.Status)
+	assert.Equal(t, map[string]any{"name": "value"}, res.Config)
+	assert.Equal(t, rel.Chart.Metadata.Name, res.Chart.Metadata.Name)
+}
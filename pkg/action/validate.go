@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -69,7 +70,10 @@ func requireAdoption(resources kube.ResourceList) (kube.ResourceList, error) {
 	return requireUpdate, err
 }
 
-func existingResourceConflict(resources kube.ResourceList, releaseName, releaseNamespace string) (kube.ResourceList, error) {
+// existingResourceConflict returns the subset of resources that already exist
+// in the cluster and can be adopted by the release. Cluster-scoped resources
+// owned by other releases are shared with them when shareClusterScoped is set.
+func existingResourceConflict(resources kube.ResourceList, releaseName, releaseNamespace string, shareClusterScoped bool) (kube.ResourceList, error) {
 	var requireUpdate kube.ResourceList
 
 	err := resources.Visit(func(info *resource.Info, err error) error {
@@ -93,7 +97,13 @@ func existingResourceConflict(resources kube.ResourceList, releaseName, releaseN
 
 		// Allow adoption of the resource if it is managed by Helm and is annotated with correct release name and namespace.
 		if err := checkOwnership(existing, releaseName, releaseNamespace); err != nil {
-			return fmt.Errorf("%s exists and cannot be imported into the current release: %w", resourceString(info), err)
+			owners, ok := helmOwners(existing)
+			if !ok || namespaced(info) {
+				return fmt.Errorf("%s exists and cannot be imported into the current release: %w", resourceString(info), err)
+			}
+			if err := shareOwnership(info, owners, releaseName, releaseNamespace, shareClusterScoped); err != nil {
+				return err
+			}
 		}
 		// Resources that are not found are skipped because they are already deleted and do not need deletion.
 		infoCopy := *info
@@ -177,6 +187,11 @@ func checkOwnership(obj runtime.Object, releaseName, releaseNamespace string) er
 		return err
 	}
 
+	// Shared cluster-scoped resources are owned by every listed release.
+	if lbls[appManagedByLabel] == appManagedByHelm && slices.Contains(sharedOwners(annos), releaseOwner(releaseName, releaseNamespace)) {
+		return nil
+	}
+
 	var errs []error
 	if err := requireValue(lbls, appManagedByLabel, appManagedByHelm); err != nil {
 		errs = append(errs, fmt.Errorf("label validation error: %w", err))
@@ -0,0 +1,153 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"sigs.k8s.io/yaml"
+
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// valuesMigrationsDir is the chart directory holding values migrations.
+//
+// A migration is a JSON Patch (RFC 6902), written in JSON or YAML, named after
+// the major chart version it migrates to, e.g. values-migrations/3.yaml. When
+// the values of a release are reused for an upgrade that crosses major
+// versions, the migrations of every major version after the old one up to and
+// including the new one are applied to the old values, in order.
+const valuesMigrationsDir = "values-migrations"
+
+// valuesMigration is a JSON Patch migrating values to a major chart version.
+type valuesMigration struct {
+	major uint64
+	name  string
+	patch jsonpatch.Patch
+}
+
+// loadValuesMigrations loads the values migrations of a chart, ordered by
+// major version.
+func loadValuesMigrations(ch *chartv2.Chart) ([]valuesMigration, error) {
+	var migrations []valuesMigration
+	for _, f := range ch.Files {
+		dir, file := path.Split(f.Name)
+		if path.Clean(dir) != valuesMigrationsDir {
+			continue
+		}
+		ext := path.Ext(file)
+		if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		major, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSuffix(file, ext), "v"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("values migration %s: name must be a major version", f.Name)
+		}
+		data, err := yaml.YAMLToJSON(f.Data)
+		if err != nil {
+			return nil, fmt.Errorf("values migration %s: %w", f.Name, err)
+		}
+		patch, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			return nil, fmt.Errorf("values migration %s: %w", f.Name, err)
+		}
+		migrations = append(migrations, valuesMigration{major: major, name: f.Name, patch: patch})
+	}
+	slices.SortFunc(migrations, func(a, b valuesMigration) int {
+		switch {
+		case a.major < b.major:
+			return -1
+		case a.major > b.major:
+			return 1
+		}
+		return 0
+	})
+	return migrations, nil
+}
+
+// applyValuesMigration applies a migration operation by operation. Operations
+// other than "test" referring to values that are not set are skipped, as
+// old values rarely set every value a migration handles.
+func applyValuesMigration(m valuesMigration, doc []byte) ([]byte, error) {
+	opts := jsonpatch.NewApplyOptions()
+	opts.EnsurePathExistsOnAdd = true
+	for _, op := range m.patch {
+		out, err := jsonpatch.Patch{op}.ApplyWithOptions(doc, opts)
+		if errors.Is(err, jsonpatch.ErrMissing) && op.Kind() != "test" {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("values migration %s: %w", m.name, err)
+		}
+		doc = out
+	}
+	return doc, nil
+}
+
+// migrateValues applies the values migrations of the target chart to values
+// used with the chart version from. Values are returned unchanged when the
+// major version does not increase or either version is not valid semver.
+func migrateValues(from string, to *chartv2.Chart, vals map[string]any) (map[string]any, error) {
+	if len(vals) == 0 || to == nil || to.Metadata == nil {
+		return vals, nil
+	}
+	fromVersion, err := semver.NewVersion(from)
+	if err != nil {
+		return vals, nil
+	}
+	toVersion, err := semver.NewVersion(to.Metadata.Version)
+	if err != nil || toVersion.Major() <= fromVersion.Major() {
+		return vals, nil
+	}
+
+	migrations, err := loadValuesMigrations(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc []byte
+	for _, m := range migrations {
+		if m.major <= fromVersion.Major() || m.major > toVersion.Major() {
+			continue
+		}
+		if doc == nil {
+			if doc, err = json.Marshal(vals); err != nil {
+				return nil, err
+			}
+		}
+		if doc, err = applyValuesMigration(m, doc); err != nil {
+			return nil, err
+		}
+	}
+	if doc == nil {
+		return vals, nil
+	}
+
+	migrated := map[string]any{}
+	if err := json.Unmarshal(doc, &migrated); err != nil {
+		return nil, err
+	}
+	return migrated, nil
+}
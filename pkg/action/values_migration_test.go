@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+)
+
+func withVersion(version string) chartOption {
+	return func(opts *chartOptions) {
+		opts.Metadata.Version = version
+	}
+}
+
+func withMigration(name, patch string) chartOption {
+	return withFile(common.File{Name: "values-migrations/" + name, Data: []byte(patch)})
+}
+
+func TestMigrateValues(t *testing.T) {
+	ch := buildChart(
+		withVersion("3.1.0"),
+		withMigration("2.yaml", "- op: move\n  from: /image/name\n  path: /image/repository\n"),
+		withMigration("3.json", `[{"op": "add", "path": "/service/port", "value": 80}, {"op": "remove", "path": "/legacy"}]`),
+		withMigration("4.yaml", "- op: remove\n  path: /image\n"),
+	)
+	vals := map[string]any{
+		"image":  map[string]any{"name": "nginx"},
+		"legacy": true,
+	}
+
+	tests := []struct {
+		name string
+		from string
+		want map[string]any
+	}{
+		{
+			name: "all migrations up to the new major version",
+			from: "1.2.0",
+			want: map[string]any{
+				"image":   map[string]any{"repository": "nginx"},
+				"service": map[string]any{"port": float64(80)},
+			},
+		},
+		{
+			name: "only migrations after the old major version",
+			from: "2.0.0",
+			want: map[string]any{
+				"image":   map[string]any{"name": "nginx"},
+				"service": map[string]any{"port": float64(80)},
+			},
+		},
+		{name: "same major version", from: "3.0.0", want: vals},
+		{name: "invalid version", from: "latest", want: vals},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := migrateValues(tt.from, ch, vals)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMigrateValuesErrors(t *testing.T) {
+	vals := map[string]any{"a": 1}
+
+	_, err := migrateValues("1.0.0", buildChart(withVersion("2.0.0"), withMigration("next.yaml", "[]")), vals)
+	assert.ErrorContains(t, err, "name must be a major version")
+
+	_, err = migrateValues("1.0.0", buildChart(withVersion("2.0.0"), withMigration("2.yaml", "- op: test\n  path: /b\n  value: 1\n")), vals)
+	assert.ErrorContains(t, err, "values-migrations/2.yaml")
+}
+
+func TestUpgradeRelease_ReuseValuesMigration(t *testing.T) {
+	upAction := upgradeAction(t)
+
+	rel := releaseStub()
+	rel.Name = "migrated"
+	rel.Info.Status = rcommon.StatusDeployed
+	rel.Chart.Metadata.Version = "1.0.0"
+	rel.Chart.Values = map[string]any{"image": map[string]any{"name": "default", "tag": "1"}}
+	rel.Config = map[string]any{"image": map[string]any{"name": "custom"}}
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	upAction.ReuseValues = true
+	ch := buildChart(
+		withVersion("2.0.0"),
+		withMigration("2.yaml", "- op: move\n  from: /image/name\n  path: /image/repository\n"),
+	)
+	resi, err := upAction.Run(rel.Name, ch, map[string]any{})
+	require.NoError(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"image": map[string]any{"repository": "custom"}}, res.Config)
+	assert.Equal(t, map[string]any{"repository": "custom", "tag": "1"}, res.Chart.Values["image"])
+}
@@ -17,10 +17,16 @@ limitations under the License.
 package action
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/provenance"
 )
 
 // Verify is the action for building a given chart's Verify tree.
@@ -28,6 +34,18 @@ import (
 // It provides the implementation of 'helm verify'.
 type Verify struct {
 	Keyring string
+	// RekorURL is the URL of a Rekor transparency log. When set, at least one
+	// trusted signature of the chart must be recorded in the log.
+	RekorURL string
+	// RekorKey is the path of the PEM encoded public key of the Rekor log,
+	// used to check that its entries are signed by the log. It defaults to
+	// the key of the public Rekor log.
+	RekorKey string
+	// Origin checks the files of an unpacked chart directory against the
+	// origin file written by 'helm pull --record-origin' instead of checking
+	// a signature. The origin file is not signed: it only detects local
+	// changes, so it cannot be combined with a keyring or Rekor check.
+	Origin bool
 }
 
 // NewVerify creates a new Verify object with the given configuration.
@@ -37,17 +55,93 @@ func NewVerify() *Verify {
 
 // Run executes 'helm verify'.
 func (v *Verify) Run(chartfile string) (string, error) {
+	if v.Origin {
+		return v.checkOrigin(chartfile)
+	}
+
 	var out strings.Builder
 	p, err := downloader.VerifyChart(chartfile, chartfile+".prov", v.Keyring)
 	if err != nil {
 		return "", err
 	}
+	if v.RekorURL != "" {
+		if err := v.verifyRekor(chartfile+".prov", p.Report); err != nil {
+			return "", err
+		}
+	}
+
+	if r := p.Report; r != nil && !r.Legacy {
+		for _, s := range r.Signers {
+			_, _ = fmt.Fprintf(&out, "Signature: %s", s.Status)
+			if s.Fingerprint != "" {
+				_, _ = fmt.Fprintf(&out, " key %s", s.Fingerprint)
+			} else if s.KeyID != "" {
+				_, _ = fmt.Fprintf(&out, " key ID %s", s.KeyID)
+			}
+			if len(s.Identities) > 0 {
+				_, _ = fmt.Fprintf(&out, " (%s)", strings.Join(s.Identities, ", "))
+			}
+			if s.Rekor != nil {
+				_, _ = fmt.Fprintf(&out, " log index %d", s.Rekor.LogIndex)
+			}
+			_, _ = fmt.Fprintln(&out)
+		}
+		if r.Expires != nil {
+			_, _ = fmt.Fprintf(&out, "Signatures Expire: %s\n", r.Expires.Format(time.RFC3339))
+		}
+		_, _ = fmt.Fprintf(&out, "Chart Hash Verified: %s\n", p.FileHash)
+		return out.String(), nil
+	}
 
 	for name := range p.SignedBy.Identities {
 		_, _ = fmt.Fprintf(&out, "Signed by: %v\n", name)
 	}
 	_, _ = fmt.Fprintf(&out, "Using Key With Fingerprint: %X\n", p.SignedBy.PrimaryKey.Fingerprint)
+	if r := p.Report; r != nil && len(r.Signers) > 0 && r.Signers[0].Rekor != nil {
+		_, _ = fmt.Fprintf(&out, "Transparency Log Index: %d\n", r.Signers[0].Rekor.LogIndex)
+	}
 	_, _ = fmt.Fprintf(&out, "Chart Hash Verified: %s\n", p.FileHash)
 
 	return out.String(), err
 }
+
+// checkOrigin compares the files of an unpacked chart with its origin file.
+func (v *Verify) checkOrigin(chartDir string) (string, error) {
+	if v.Keyring != "" || v.RekorURL != "" {
+		return "", errors.New("the origin file is not signed: origin checks cannot be combined with a keyring or transparency log check")
+	}
+	origin, err := chartutil.LoadOrigin(chartDir)
+	if err != nil {
+		return "", err
+	}
+	if err := origin.VerifyDir(chartDir); err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	_, _ = fmt.Fprintf(&out, "Chart Origin: %s\n", origin.Source)
+	_, _ = fmt.Fprintf(&out, "Chart Files Unchanged: %d files match the unsigned origin file for %s\n", len(origin.Files), origin.Digest)
+	return out.String(), nil
+}
+
+// verifyRekor checks that the trusted signatures of the report are recorded in
+// the transparency log.
+func (v *Verify) verifyRekor(provfile string, report *provenance.Report) error {
+	if report == nil {
+		return errors.New("no verification report to check against the transparency log")
+	}
+	provData, err := os.ReadFile(provfile)
+	if err != nil {
+		return err
+	}
+	rekor := &provenance.Rekor{URL: v.RekorURL}
+	if v.RekorKey != "" {
+		data, err := os.ReadFile(v.RekorKey)
+		if err != nil {
+			return err
+		}
+		if rekor.PublicKey, err = provenance.ParseRekorPublicKey(data); err != nil {
+			return fmt.Errorf("%s: %w", v.RekorKey, err)
+		}
+	}
+	return rekor.Verify(context.Background(), provData, report)
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package action
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 )
 
 func TestNewVerify(t *testing.T) {
@@ -46,3 +50,38 @@ func TestVerifyRun_DownloadError(t *testing.T) {
 	require.Error(t, err)
 	assert.Empty(t, output)
 }
+
+func TestVerifyRun_Origin(t *testing.T) {
+	archive := "../downloader/testdata/signtest-0.1.0.tgz"
+	origin, err := chartutil.NewOriginFromArchive(archive, "https://example.com/signtest-0.1.0.tgz")
+	require.NoError(t, err)
+	dest := t.TempDir()
+	require.NoError(t, chartutil.ExpandFile(dest, archive))
+	chartDir := filepath.Join(dest, origin.Name)
+	require.NoError(t, chartutil.WriteOrigin(chartDir, origin))
+
+	// Without Origin, the unsigned origin file is not accepted as a signature.
+	client := NewVerify()
+	_, err = client.Run(chartDir)
+	require.Error(t, err)
+
+	client.Origin = true
+	output, err := client.Run(chartDir)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Chart Origin: https://example.com/signtest-0.1.0.tgz")
+	assert.Contains(t, output, "Chart Files Unchanged:")
+	assert.NotContains(t, output, "Verified")
+
+	client.Keyring = "../downloader/testdata/helm-test-key.pub"
+	_, err = client.Run(chartDir)
+	require.ErrorContains(t, err, "origin file is not signed")
+	client.Keyring = ""
+	client.RekorURL = "https://rekor.example.com"
+	_, err = client.Run(chartDir)
+	require.ErrorContains(t, err, "origin file is not signed")
+	client.RekorURL = ""
+
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: changed\n"), 0644))
+	_, err = client.Run(chartDir)
+	require.ErrorContains(t, err, "modified: Chart.yaml")
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+
+	"helm.sh/helm/v4/internal/version"
+)
+
+// VersionInfo describes the version of Helm, and of the Kubernetes cluster
+// it talks to.
+type VersionInfo struct {
+	version.Info
+	// KubeServerVersion is the version of the Kubernetes cluster. It is empty
+	// when the cluster was not looked up.
+	KubeServerVersion string `json:"kube_server_version,omitempty"`
+}
+
+// Version is the action for reporting the version of Helm.
+//
+// It provides the implementation of 'helm version'.
+type Version struct {
+	cfg *Configuration
+
+	// Server looks up the version of the Kubernetes cluster as well. The
+	// cluster is not contacted otherwise.
+	Server bool
+}
+
+// NewVersion creates a new Version object with the given configuration.
+func NewVersion(cfg *Configuration) *Version {
+	return &Version{cfg: cfg}
+}
+
+// Run returns the version of Helm and, when Server is set, of the Kubernetes
+// cluster. The version of Helm is returned along with the error
+// when the cluster cannot be reached.
+func (v *Version) Run() (*VersionInfo, error) {
+	info := &VersionInfo{Info: version.GetInfo()}
+	if !v.Server {
+		return info, nil
+	}
+	if v.cfg.Capabilities == nil && v.cfg.RESTClientGetter == nil {
+		return info, errors.New("no Kubernetes cluster is configured")
+	}
+	caps, err := v.cfg.getCapabilities()
+	if err != nil {
+		return info, err
+	}
+	info.KubeServerVersion = caps.KubeVersion.Version
+	return info, nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/internal/version"
+)
+
+func TestVersion(t *testing.T) {
+	client := NewVersion(actionConfigFixture(t))
+	info, err := client.Run()
+	require.NoError(t, err)
+	assert.Equal(t, version.GetVersion(), info.Version)
+	assert.NotEmpty(t, info.SemVer)
+	assert.Equal(t, []string{"v1", "v2"}, info.ChartAPIVersions)
+	assert.True(t, info.Supports("apply"))
+	assert.False(t, info.Supports("time-travel"))
+	assert.Empty(t, info.KubeServerVersion, "the cluster is only looked up on request")
+
+	client.Server = true
+	info, err = client.Run()
+	require.NoError(t, err)
+	assert.NotEmpty(t, info.KubeServerVersion)
+
+	client = NewVersion(&Configuration{})
+	client.Server = true
+	info, err = client.Run()
+	assert.ErrorContains(t, err, "no Kubernetes cluster is configured")
+	assert.Equal(t, version.GetVersion(), info.Version)
+}
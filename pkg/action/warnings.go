@@ -0,0 +1,116 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// WarningKind is a stable identifier of a class of warnings, meant for
+// scripts and SDK callers that need to branch on them.
+type WarningKind string
+
+const (
+	// WarningDeprecatedFlag is the use of a deprecated command-line flag.
+	WarningDeprecatedFlag WarningKind = "DEPRECATED_FLAG"
+	// WarningDeprecatedChart is the use of a chart marked as deprecated.
+	WarningDeprecatedChart WarningKind = "DEPRECATED_CHART"
+	// WarningDeprecatedAPI is the use of a deprecated Kubernetes API, as
+	// reported by the Kubernetes API server.
+	WarningDeprecatedAPI WarningKind = "DEPRECATED_API"
+	// WarningKubernetes is any other warning of the Kubernetes API server.
+	WarningKubernetes WarningKind = "KUBERNETES"
+	// WarningEndpoints is a load balancer of a release that was not given an
+	// address in time.
+	WarningEndpoints WarningKind = "ENDPOINTS"
+)
+
+// Warning is a warning raised while running an action.
+type Warning struct {
+	Kind    WarningKind `json:"kind"`
+	Message string      `json:"message"`
+}
+
+// Warnings collects the warnings raised while running actions. It is safe
+// for concurrent use.
+//
+// Warnings implements rest.WarningHandler, so that it collects the warnings
+// sent by the Kubernetes API server when it is set as the warning handler of
+// the Kubernetes client configuration, or with rest.SetDefaultWarningHandler.
+type Warnings struct {
+	// Handler is called with each warning when it is first raised, if set.
+	Handler func(Warning)
+
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+// Add records a warning. A warning that was already recorded is ignored, as
+// the Kubernetes API server repeats its warnings for every request.
+func (w *Warnings) Add(kind WarningKind, message string) {
+	warning := Warning{Kind: kind, Message: message}
+	w.mu.Lock()
+	if slices.Contains(w.warnings, warning) {
+		w.mu.Unlock()
+		return
+	}
+	w.warnings = append(w.warnings, warning)
+	w.mu.Unlock()
+	if w.Handler != nil {
+		w.Handler(warning)
+	}
+}
+
+// List returns the recorded warnings, in the order they were raised.
+func (w *Warnings) List() []Warning {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.warnings)
+}
+
+// Reset forgets the recorded warnings.
+func (w *Warnings) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.warnings = nil
+}
+
+// HandleWarningHeader records a warning sent by the Kubernetes API server.
+// The server only sends warnings of code 299, "miscellaneous persistent
+// warning".
+func (w *Warnings) HandleWarningHeader(code int, _ string, message string) {
+	if code != 299 || message == "" {
+		return
+	}
+	kind := WarningKubernetes
+	if strings.Contains(message, "deprecated") {
+		kind = WarningDeprecatedAPI
+	}
+	w.Add(kind, message)
+}
+
+// warn raises a warning. It is recorded by the warnings of the configuration
+// when set, and logged otherwise.
+func (cfg *Configuration) warn(kind WarningKind, message string) {
+	if cfg.Warnings != nil {
+		cfg.Warnings.Add(kind, message)
+		return
+	}
+	cfg.Logger().Warn(message)
+}
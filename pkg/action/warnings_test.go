@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarnings(t *testing.T) {
+	var handled []Warning
+	w := &Warnings{Handler: func(warning Warning) { handled = append(handled, warning) }}
+
+	w.Add(WarningDeprecatedFlag, "Flag --atomic has been deprecated")
+	w.HandleWarningHeader(299, "", "policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+")
+	w.HandleWarningHeader(299, "", "policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+")
+	w.HandleWarningHeader(299, "", "unknown field \"spec.foo\"")
+	w.HandleWarningHeader(199, "", "not a warning of the API server")
+
+	expected := []Warning{
+		{Kind: WarningDeprecatedFlag, Message: "Flag --atomic has been deprecated"},
+		{Kind: WarningDeprecatedAPI, Message: "policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+"},
+		{Kind: WarningKubernetes, Message: "unknown field \"spec.foo\""},
+	}
+	assert.Equal(t, expected, w.List())
+	assert.Equal(t, expected, handled)
+
+	w.Reset()
+	assert.Empty(t, w.List())
+}
+
+func TestInstallRelease_DeprecatedChart(t *testing.T) {
+	instAction := installAction(t)
+	instAction.cfg.Warnings = &Warnings{}
+	ch := buildChart()
+	ch.Metadata.Deprecated = true
+
+	_, err := instAction.Run(ch, map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{{Kind: WarningDeprecatedChart, Message: "chart hello is deprecated"}}, instAction.cfg.Warnings.List())
+}
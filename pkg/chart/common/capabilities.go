@@ -59,6 +59,8 @@ type Capabilities struct {
 	APIVersions VersionSet
 	// HelmVersion is the build information for this helm version
 	HelmVersion helmversion.BuildInfo
+	// Features are the features detected in the cluster.
+	Features ClusterFeatures
 }
 
 func (capabilities *Capabilities) Copy() *Capabilities {
@@ -66,6 +68,7 @@ func (capabilities *Capabilities) Copy() *Capabilities {
 		KubeVersion: capabilities.KubeVersion,
 		APIVersions: capabilities.APIVersions,
 		HelmVersion: capabilities.HelmVersion,
+		Features:    capabilities.Features.Copy(),
 	}
 }
 
@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ClusterFeatures describes features of a Kubernetes cluster that templates
+// and preflight checks may depend on. The features are detected once per
+// operation, or read from a file when rendering offline.
+type ClusterFeatures struct {
+	// CRDs are the names of the CustomResourceDefinitions of the cluster,
+	// such as "certificates.cert-manager.io". It is nil when the CRDs are
+	// unknown, and empty when the cluster has none.
+	CRDs []string `json:"crds,omitempty"`
+	// PodSecurity maps namespaces to the Pod Security admission level that
+	// they enforce, such as "restricted". Namespaces that do not enforce a
+	// level are omitted.
+	PodSecurity map[string]string `json:"podSecurity,omitempty"`
+	// DefaultStorageClass is the name of the default StorageClass, if any.
+	DefaultStorageClass string `json:"defaultStorageClass,omitempty"`
+}
+
+// HasCRD reports whether the cluster has the CustomResourceDefinition.
+//
+//	{{ if .Capabilities.Features.HasCRD "servicemonitors.monitoring.coreos.com" }}
+func (f ClusterFeatures) HasCRD(name string) bool {
+	return slices.Contains(f.CRDs, name)
+}
+
+// Copy returns a deep copy of the features.
+func (f ClusterFeatures) Copy() ClusterFeatures {
+	f.CRDs = slices.Clone(f.CRDs)
+	f.PodSecurity = maps.Clone(f.PodSecurity)
+	return f
+}
+
+// LoadClusterFeatures reads cluster features from a YAML file, to render
+// charts offline as for a cluster with these features.
+func LoadClusterFeatures(filename string) (*ClusterFeatures, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	f := &ClusterFeatures{}
+	if err := yaml.UnmarshalStrict(data, f); err != nil {
+		return nil, fmt.Errorf("invalid cluster features file %s: %w", filename, err)
+	}
+	if f.CRDs == nil {
+		f.CRDs = []string{}
+	}
+	return f, nil
+}
@@ -15,7 +15,13 @@ limitations under the License.
 
 package common
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
 
 // File represents a file as a name/value pair.
 //
@@ -28,4 +34,71 @@ type File struct {
 	Data []byte `json:"data"`
 	// ModTime is the file's mod-time
 	ModTime time.Time `json:"modtime,omitzero"`
+
+	// size is the size of a lazily loaded file that is not loaded yet.
+	size int64
+	// open streams the data of a lazily loaded file.
+	open func() (io.ReadCloser, error)
+}
+
+// NewLazyFile returns a file of the given size whose data is read with open
+// when it is needed, rather than held in memory from the start.
+func NewLazyFile(name string, modTime time.Time, size int64, open func() (io.ReadCloser, error)) *File {
+	return &File{Name: name, ModTime: modTime, size: size, open: open}
+}
+
+// IsLoaded reports whether the data of the file is available in Data. Only
+// lazily loaded files that were not loaded yet are not.
+func (f *File) IsLoaded() bool {
+	return f.open == nil
+}
+
+// Load reads the data of a lazily loaded file into Data. It does nothing for
+// files that are already loaded.
+func (f *File) Load() error {
+	if f.open == nil {
+		return nil
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", f.Name, err)
+	}
+	f.Data = data
+	f.open = nil
+	return nil
+}
+
+// Size returns the size of the data of the file, without loading it.
+func (f *File) Size() int64 {
+	if f.open == nil {
+		return int64(len(f.Data))
+	}
+	return f.size
+}
+
+// Open returns a reader of the data of the file. The data of lazily loaded
+// files is streamed without loading it into Data.
+func (f *File) Open() (io.ReadCloser, error) {
+	if f.open == nil {
+		return io.NopCloser(bytes.NewReader(f.Data)), nil
+	}
+	r, err := f.open()
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", f.Name, err)
+	}
+	return r, nil
+}
+
+// MarshalJSON loads lazily loaded files so that their data is encoded.
+func (f *File) MarshalJSON() ([]byte, error) {
+	if err := f.Load(); err != nil {
+		return nil, err
+	}
+	type file File
+	return json.Marshal((*file)(f))
 }
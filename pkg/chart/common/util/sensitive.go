@@ -0,0 +1,179 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	chart "helm.sh/helm/v4/pkg/chart"
+)
+
+// SensitiveSchemaKey is the schema annotation that marks a value as sensitive.
+// Sensitive values are encrypted in the release records when an encryption key
+// is configured, and redacted from the values shown to users.
+//
+//	"password": {"type": "string", "x-helm-sensitive": true}
+const SensitiveSchemaKey = "x-helm-sensitive"
+
+// RedactedValue replaces sensitive values in redacted values.
+const RedactedValue = "REDACTED"
+
+// anyKey matches any key of a map in a sensitive path. It is used for the
+// values declared by "additionalProperties".
+const anyKey = "*"
+
+// SensitivePaths returns the paths of the values marked as sensitive in the
+// schemas of a chart and of its subcharts. Each path is a list of keys, and
+// the key "*" matches any key.
+func SensitivePaths(ch chart.Charter) ([][]string, error) {
+	chrt, err := chart.NewAccessor(ch)
+	if err != nil {
+		return nil, err
+	}
+	var paths [][]string
+	if len(chrt.Schema()) > 0 {
+		var schema map[string]any
+		if err := json.Unmarshal(chrt.Schema(), &schema); err != nil {
+			return nil, fmt.Errorf("%s: invalid values schema: %w", chrt.Name(), err)
+		}
+		paths = collectSensitivePaths(nil, schema, schema, paths, 0)
+	}
+	for _, dep := range chrt.Dependencies() {
+		sub, err := chart.NewAccessor(dep)
+		if err != nil {
+			return nil, err
+		}
+		subPaths, err := SensitivePaths(dep)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range subPaths {
+			paths = append(paths, append([]string{sub.Name()}, p...))
+		}
+	}
+	return paths, nil
+}
+
+// maxSensitiveDepth bounds the recursion in schemas with recursive references.
+const maxSensitiveDepth = 32
+
+func collectSensitivePaths(path []string, root, schema map[string]any, paths [][]string, depth int) [][]string {
+	if depth > maxSensitiveDepth {
+		return paths
+	}
+	schema = ResolveSchemaRef(root, schema)
+	if sensitive, _ := schema[SensitiveSchemaKey].(bool); sensitive {
+		return append(paths, append([]string(nil), path...))
+	}
+	if props, ok := schema["properties"].(map[string]any); ok {
+		for name, prop := range props {
+			if p, ok := prop.(map[string]any); ok {
+				paths = collectSensitivePaths(append(path, name), root, p, paths, depth+1)
+			}
+		}
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+		paths = collectSensitivePaths(append(path, anyKey), root, additional, paths, depth+1)
+	}
+	return paths
+}
+
+// SplitSensitiveValues splits values into a copy without the values at the
+// given paths, and a sparse tree holding only those values. Merging the two
+// trees back with MergeTables restores the values.
+func SplitSensitiveValues(vals map[string]any, paths [][]string) (rest, sensitive map[string]any) {
+	rest = copyTree(vals)
+	sensitive = map[string]any{}
+	for _, path := range paths {
+		splitPath(rest, sensitive, path)
+	}
+	return rest, sensitive
+}
+
+func splitPath(rest, sensitive map[string]any, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	for key, v := range rest {
+		if key != path[0] && path[0] != anyKey {
+			continue
+		}
+		if len(path) == 1 {
+			sensitive[key] = v
+			delete(rest, key)
+			continue
+		}
+		next, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		sub, ok := sensitive[key].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+		}
+		splitPath(next, sub, path[1:])
+		if len(sub) > 0 {
+			sensitive[key] = sub
+		}
+	}
+}
+
+// RedactSensitiveValues returns a copy of values with the values at the given
+// paths replaced by RedactedValue.
+func RedactSensitiveValues(vals map[string]any, paths [][]string) map[string]any {
+	out := copyTree(vals)
+	for _, path := range paths {
+		redactPath(out, path)
+	}
+	return out
+}
+
+func redactPath(vals map[string]any, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	for key, v := range vals {
+		if key != path[0] && path[0] != anyKey {
+			continue
+		}
+		if len(path) == 1 {
+			if v != nil {
+				vals[key] = RedactedValue
+			}
+			continue
+		}
+		if next, ok := v.(map[string]any); ok {
+			redactPath(next, path[1:])
+		}
+	}
+}
+
+// copyTree copies the maps of a values tree, so it can be modified without
+// modifying the original. Other values are shared.
+func copyTree(vals map[string]any) map[string]any {
+	if vals == nil {
+		return nil
+	}
+	out := make(map[string]any, len(vals))
+	for k, v := range vals {
+		if m, ok := v.(map[string]any); ok {
+			v = copyTree(m)
+		}
+		out[k] = v
+	}
+	return out
+}
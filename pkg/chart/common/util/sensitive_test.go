@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func sensitiveTestChart() *chart.Chart {
+	db := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "db", Version: "0.1.0"},
+		Schema:   []byte(`{"properties": {"auth": {"$ref": "#/definitions/auth"}}, "definitions": {"auth": {"properties": {"password": {"type": "string", "x-helm-sensitive": true}}}}}`),
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app", Version: "0.1.0"},
+		Schema: []byte(`{"properties": {
+			"apiKey": {"type": "string", "x-helm-sensitive": true},
+			"tokens": {"type": "object", "additionalProperties": {"type": "string", "x-helm-sensitive": true}},
+			"replicas": {"type": "integer"}
+		}}`),
+	}
+	c.SetDependencies(db)
+	return c
+}
+
+func TestSensitivePaths(t *testing.T) {
+	paths, err := SensitivePaths(sensitiveTestChart())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, [][]string{{"apiKey"}, {"tokens", "*"}, {"db", "auth", "password"}}, paths)
+
+	_, err = SensitivePaths(&chart.Chart{Metadata: &chart.Metadata{Name: "broken"}, Schema: []byte("{")})
+	assert.ErrorContains(t, err, "broken: invalid values schema")
+}
+
+func TestSplitSensitiveValues(t *testing.T) {
+	paths, err := SensitivePaths(sensitiveTestChart())
+	require.NoError(t, err)
+	vals := map[string]any{
+		"apiKey":   "key",
+		"replicas": 2,
+		"tokens":   map[string]any{"a": "1", "b": "2"},
+		"db":       map[string]any{"auth": map[string]any{"password": "hunter2", "username": "admin"}},
+	}
+
+	rest, sensitive := SplitSensitiveValues(vals, paths)
+	assert.Equal(t, map[string]any{
+		"replicas": 2,
+		"tokens":   map[string]any{},
+		"db":       map[string]any{"auth": map[string]any{"username": "admin"}},
+	}, rest)
+	assert.Equal(t, map[string]any{
+		"apiKey": "key",
+		"tokens": map[string]any{"a": "1", "b": "2"},
+		"db":     map[string]any{"auth": map[string]any{"password": "hunter2"}},
+	}, sensitive)
+	assert.Equal(t, vals, MergeTables(sensitive, rest))
+	assert.Equal(t, "hunter2", vals["db"].(map[string]any)["auth"].(map[string]any)["password"], "the values must not be modified")
+}
+
+func TestRedactSensitiveValues(t *testing.T) {
+	paths, err := SensitivePaths(sensitiveTestChart())
+	require.NoError(t, err)
+	vals := map[string]any{
+		"replicas": 2,
+		"tokens":   map[string]any{"a": "1"},
+		"db":       map[string]any{"auth": map[string]any{"password": "hunter2", "username": "admin"}},
+	}
+
+	assert.Equal(t, map[string]any{
+		"replicas": 2,
+		"tokens":   map[string]any{"a": RedactedValue},
+		"db":       map[string]any{"auth": map[string]any{"password": RedactedValue, "username": "admin"}},
+	}, RedactSensitiveValues(vals, paths))
+	assert.Equal(t, "1", vals["tokens"].(map[string]any)["a"], "the values must not be modified")
+}
@@ -0,0 +1,167 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// UnknownValuesError is returned when values contain keys that are not
+// declared in the schema of a chart.
+type UnknownValuesError struct {
+	// Keys are the paths of the unknown keys, e.g. "replicaCont" or
+	// "subchart.imge".
+	Keys []string
+}
+
+// Error prints the error message
+func (e UnknownValuesError) Error() string {
+	return "values not defined in the chart schema: " + strings.Join(e.Keys, ", ")
+}
+
+// UnknownValues returns the paths of the keys in values that are not declared
+// in the schema of the chart or, recursively, of its subcharts.
+//
+// Only the top-level keys of each chart are checked, and only when the chart
+// schema declares properties without specifying additionalProperties or
+// patternProperties, in which case undeclared keys are most likely typos.
+// "global" and the values of the dependencies declared in Chart.yaml, under
+// their name or alias, are always allowed, even for disabled dependencies.
+func UnknownValues(ch chart.Charter, values map[string]any) ([]string, error) {
+	accessor, err := chart.NewAccessor(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := []string{common.GlobalKey}
+	for _, dep := range accessor.MetaDependencies() {
+		da, err := chart.NewDependencyAccessor(dep)
+		if err != nil {
+			return nil, err
+		}
+		allowed = append(allowed, da.Name())
+		if da.Alias() != "" {
+			allowed = append(allowed, da.Alias())
+		}
+	}
+	var unknown []string
+	for _, sub := range accessor.Dependencies() {
+		subAccessor, err := chart.NewAccessor(sub)
+		if err != nil {
+			return nil, err
+		}
+		name := subAccessor.Name()
+		allowed = append(allowed, name)
+
+		subValues, ok := values[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		keys, err := UnknownValues(sub, subValues)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			unknown = append(unknown, name+"."+k)
+		}
+	}
+	if len(accessor.Dependencies()) > 0 || len(accessor.MetaDependencies()) > 0 {
+		allowed = append(allowed, "tags")
+	}
+
+	if schema := accessor.Schema(); len(schema) > 0 {
+		keys, err := UnknownSchemaKeys(values, schema, allowed...)
+		if err != nil {
+			return nil, err
+		}
+		unknown = append(unknown, keys...)
+	}
+
+	slices.Sort(unknown)
+	return unknown, nil
+}
+
+// UnknownSchemaKeys returns the sorted top-level keys of values that are not
+// declared in the properties of schemaJSON, ignoring the allowed keys. It
+// returns no keys for schemas that allow additional or pattern properties,
+// or whose properties cannot be determined without resolving references.
+func UnknownSchemaKeys(values map[string]any, schemaJSON []byte, allowed ...string) ([]string, error) {
+	var schema map[string]any
+	if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+		// Boolean schemas do not declare properties.
+		return nil, nil
+	}
+
+	properties, ok := declaredProperties(schema)
+	if !ok {
+		return nil, nil
+	}
+
+	var unknown []string
+	for k := range values {
+		if _, ok := properties[k]; ok || slices.Contains(allowed, k) {
+			continue
+		}
+		unknown = append(unknown, k)
+	}
+	slices.Sort(unknown)
+	return unknown, nil
+}
+
+// declaredProperties collects the properties declared by a schema and the
+// schemas of its allOf. It reports false if the schema does not restrict the
+// keys it accepts.
+func declaredProperties(schema map[string]any) (map[string]any, bool) {
+	for _, k := range []string{"additionalProperties", "patternProperties", "unevaluatedProperties", "$ref", "anyOf", "oneOf", "if", "dependentSchemas"} {
+		if _, ok := schema[k]; ok {
+			return nil, false
+		}
+	}
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	all := make(map[string]any, len(properties))
+	for k, v := range properties {
+		all[k] = v
+	}
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, s := range allOf {
+			sub, ok := s.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if _, ok := sub["properties"]; !ok {
+				continue
+			}
+			props, ok := declaredProperties(sub)
+			if !ok {
+				return nil, false
+			}
+			for k, v := range props {
+				all[k] = v
+			}
+		}
+	}
+	return all, true
+}
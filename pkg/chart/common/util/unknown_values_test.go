@@ -0,0 +1,116 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestUnknownSchemaKeys(t *testing.T) {
+	values := map[string]any{"name": "x", "replicaCont": 3, "global": map[string]any{}}
+
+	tests := []struct {
+		name   string
+		schema string
+		want   []string
+	}{
+		{
+			name:   "undeclared key",
+			schema: `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+			want:   []string{"replicaCont"},
+		},
+		{
+			name:   "allOf properties",
+			schema: `{"properties": {"name": {}}, "allOf": [{"properties": {"replicaCont": {}}}, {"required": ["name"]}]}`,
+		},
+		{
+			name:   "additional properties allowed",
+			schema: `{"properties": {"name": {}}, "additionalProperties": true}`,
+		},
+		{
+			name:   "additional properties forbidden",
+			schema: `{"properties": {"name": {}}, "additionalProperties": false}`,
+		},
+		{
+			name:   "pattern properties",
+			schema: `{"properties": {"name": {}}, "patternProperties": {"^r": {}}}`,
+		},
+		{
+			name:   "no properties",
+			schema: `{"type": "object"}`,
+		},
+		{
+			name:   "boolean schema",
+			schema: `true`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnknownSchemaKeys(values, []byte(tt.schema), "global")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnknownValues(t *testing.T) {
+	subchart := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "subchart"},
+		Schema:   []byte(`{"properties": {"age": {}}}`),
+	}
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "chrt",
+			// The disabled dependency was removed from the loaded charts.
+			Dependencies: []*chart.Dependency{{Name: "subchart"}, {Name: "cache", Alias: "redis"}},
+		},
+		Schema: []byte(`{"properties": {"name": {}}}`),
+	}
+	chrt.AddDependency(subchart)
+
+	vals := map[string]any{
+		"name":     "John",
+		"nmae":     "typo",
+		"global":   map[string]any{"x": 1},
+		"tags":     map[string]any{"backend": true},
+		"subchart": map[string]any{"age": 25, "aeg": 26},
+		"cache":    map[string]any{"enabled": false},
+		"redis":    map[string]any{"enabled": false},
+	}
+
+	got, err := UnknownValues(chrt, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"nmae", "subchart.aeg"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	expectedErr := "values not defined in the chart schema: nmae, subchart.aeg"
+	if err := (UnknownValuesError{Keys: got}); err.Error() != expectedErr {
+		t.Errorf("expected %q, got %q", expectedErr, err.Error())
+	}
+}
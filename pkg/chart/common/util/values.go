@@ -45,12 +45,15 @@ func ToRenderValuesWithSchemaValidation(chrt chart.Charter, chrtVals map[string]
 		"Chart":        accessor.MetadataAsMap(),
 		"Capabilities": caps,
 		"Release": map[string]any{
-			"Name":      options.Name,
-			"Namespace": options.Namespace,
-			"IsUpgrade": options.IsUpgrade,
-			"IsInstall": options.IsInstall,
-			"Revision":  options.Revision,
-			"Service":   "Helm",
+			"Name":       options.Name,
+			"Namespace":  options.Namespace,
+			"IsUpgrade":  options.IsUpgrade,
+			"IsInstall":  options.IsInstall,
+			"Revision":   options.Revision,
+			"Service":    "Helm",
+			"History":    historyValues(options.History),
+			"Slot":       options.Slot,
+			"ActiveSlot": options.ActiveSlot,
 		},
 	}
 
@@ -68,3 +71,19 @@ func ToRenderValuesWithSchemaValidation(chrt chart.Charter, chrtVals map[string]
 	top["Values"] = vals
 	return top, nil
 }
+
+// historyValues returns .Release.History. It is empty rather than nil on
+// install, so that templates can test its fields without checking it first.
+func historyValues(h *common.ReleaseHistory) map[string]any {
+	if h == nil {
+		h = &common.ReleaseHistory{}
+	}
+	return map[string]any{
+		"Revision":     h.Revision,
+		"Revisions":    h.Revisions,
+		"ChartName":    h.ChartName,
+		"ChartVersion": h.ChartVersion,
+		"AppVersion":   h.AppVersion,
+		"ValuesHash":   h.ValuesHash,
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ValueDoc documents a key of the values of a chart.
+type ValueDoc struct {
+	// Key is the path of the key, such as "image.tag". It is empty for the
+	// root of the values.
+	Key string `json:"key,omitempty"`
+	// Type is the JSON schema type of the value, as declared by the schema or
+	// else as found in values.yaml.
+	Type string `json:"type,omitempty"`
+	// Default is the value in values.yaml, or the default declared by the
+	// schema. It is unset for keys with children.
+	Default any `json:"default,omitempty"`
+	// Description is the description of the key in the schema, or else the
+	// comment above or beside the key in values.yaml.
+	Description string `json:"description,omitempty"`
+	// Required is set when the schema requires the key.
+	Required bool `json:"required,omitempty"`
+	// Children are the nested keys, in values.yaml order followed by the keys
+	// only declared by the schema.
+	Children []*ValueDoc `json:"children,omitempty"`
+}
+
+// ValuesDocs extracts the documentation of the values of a chart from its
+// values.yaml and its values.schema.json. Both are optional.
+func ValuesDocs(values, schema []byte) (*ValueDoc, error) {
+	var root map[string]any
+	if len(schema) > 0 {
+		if err := json.Unmarshal(schema, &root); err != nil {
+			return nil, fmt.Errorf("invalid values schema: %w", err)
+		}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(values, &doc); err != nil {
+		return nil, fmt.Errorf("invalid values: %w", err)
+	}
+	var node *yaml.Node
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		node = doc.Content[0]
+	}
+
+	docs := &ValueDoc{Type: "object"}
+	if d, ok := root["description"].(string); ok {
+		docs.Description = d
+	}
+	addValueDocs(docs, node, root, root, map[string]bool{}, 0)
+	return docs, nil
+}
+
+// Lookup returns the documentation of a nested key, or nil.
+func (d *ValueDoc) Lookup(key string) *ValueDoc {
+	if key == d.Key {
+		return d
+	}
+	for _, c := range d.Children {
+		if c.Key == key || strings.HasPrefix(key, c.Key+".") {
+			if found := c.Lookup(key); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// All returns the nested keys, depth first.
+func (d *ValueDoc) All() []*ValueDoc {
+	var all []*ValueDoc
+	for _, c := range d.Children {
+		all = append(all, c)
+		all = append(all, c.All()...)
+	}
+	return all
+}
+
+// maxValueDocsDepth bounds the recursion in deeply nested values and schemas.
+const maxValueDocsDepth = 32
+
+// addValueDocs adds the documentation of the keys of node and schema to
+// parent. refs holds the schema references being expanded, so that the keys
+// only declared by a recursive schema are documented once.
+func addValueDocs(parent *ValueDoc, node *yaml.Node, root, schema map[string]any, refs map[string]bool, depth int) {
+	if depth > maxValueDocsDepth {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if refs[ref] {
+			if node == nil {
+				return
+			}
+		} else {
+			refs[ref] = true
+			defer delete(refs, ref)
+		}
+	}
+	schema = ResolveSchemaRef(root, schema)
+	properties, _ := schema["properties"].(map[string]any)
+	required := map[string]bool{}
+	if names, ok := schema["required"].([]any); ok {
+		for _, n := range names {
+			if name, ok := n.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	if node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if v.Kind == yaml.AliasNode {
+				v = v.Alias
+			}
+			seen[k.Value] = true
+			property, _ := properties[k.Value].(map[string]any)
+			resolved := ResolveSchemaRef(root, property)
+
+			child := &ValueDoc{
+				Key:         joinValuesKey(parent.Key, k.Value),
+				Type:        schemaType(resolved),
+				Description: schemaDescription(resolved),
+				Required:    required[k.Value],
+			}
+			if child.Type == "" {
+				child.Type = nodeType(v)
+			}
+			if child.Description == "" {
+				child.Description = nodeComment(k, v)
+			}
+			if v.Kind == yaml.MappingNode && len(v.Content) > 0 {
+				addValueDocs(child, v, root, property, refs, depth+1)
+			} else {
+				var value any
+				if err := v.Decode(&value); err == nil {
+					child.Default = value
+				}
+			}
+			parent.Children = append(parent.Children, child)
+		}
+	}
+
+	for _, name := range slices.Sorted(func(yield func(string) bool) {
+		for name := range properties {
+			if !seen[name] && !yield(name) {
+				return
+			}
+		}
+	}) {
+		property, _ := properties[name].(map[string]any)
+		resolved := ResolveSchemaRef(root, property)
+		child := &ValueDoc{
+			Key:         joinValuesKey(parent.Key, name),
+			Type:        schemaType(resolved),
+			Description: schemaDescription(resolved),
+			Required:    required[name],
+			Default:     resolved["default"],
+		}
+		if _, ok := resolved["properties"].(map[string]any); ok {
+			if child.Type == "" {
+				child.Type = "object"
+			}
+			addValueDocs(child, nil, root, property, refs, depth+1)
+		}
+		parent.Children = append(parent.Children, child)
+	}
+}
+
+func joinValuesKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// ResolveSchemaRef resolves a local "$ref" of a JSON schema node, such as
+// "#/definitions/image". Other nodes are returned as they are.
+func ResolveSchemaRef(root, node map[string]any) map[string]any {
+	ref, ok := node["$ref"].(string)
+	if !ok || !strings.HasPrefix(ref, "#/") {
+		return node
+	}
+	resolved := root
+	for segment := range strings.SplitSeq(strings.TrimPrefix(ref, "#/"), "/") {
+		next, ok := resolved[segment].(map[string]any)
+		if !ok {
+			return node
+		}
+		resolved = next
+	}
+	return resolved
+}
+
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		var types []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return strings.Join(types, "|")
+	}
+	return ""
+}
+
+func schemaDescription(schema map[string]any) string {
+	d, _ := schema["description"].(string)
+	return strings.TrimSpace(d)
+}
+
+// nodeType returns the JSON schema type of a YAML value.
+func nodeType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch n.Tag {
+	case "!!str", "!!binary", "!!timestamp":
+		return "string"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	}
+	return ""
+}
+
+// nodeComment returns the comment above a key, or else beside its value. The
+// "-- " prefix of the helm-docs comment convention is removed.
+func nodeComment(k, v *yaml.Node) string {
+	comment := k.HeadComment
+	if comment == "" {
+		comment = k.LineComment
+	}
+	if comment == "" && v.Kind == yaml.ScalarNode {
+		comment = v.LineComment
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(comment, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		line = strings.TrimPrefix(line, "-- ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
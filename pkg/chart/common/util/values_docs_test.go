@@ -0,0 +1,149 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDocsValues = `# Number of replicas
+replicaCount: 1
+
+image:
+  # -- The image repository
+  repository: nginx
+  tag: "1.25" # The image tag
+  pullPolicy: IfNotPresent
+
+# Extra labels
+labels: {}
+`
+
+const testDocsSchema = `{
+  "required": ["image"],
+  "properties": {
+    "replicaCount": {"type": "integer", "minimum": 1},
+    "image": {"$ref": "#/definitions/image"},
+    "serviceAccount": {
+      "description": "The service account",
+      "properties": {
+        "create": {"type": "boolean", "default": true}
+      }
+    }
+  },
+  "definitions": {
+    "image": {
+      "description": "The container image",
+      "required": ["repository"],
+      "properties": {
+        "pullPolicy": {"type": "string", "description": "When to pull the image"}
+      }
+    }
+  }
+}`
+
+func TestValuesDocs(t *testing.T) {
+	docs, err := ValuesDocs([]byte(testDocsValues), []byte(testDocsSchema))
+	require.NoError(t, err)
+
+	var keys []string
+	for _, d := range docs.All() {
+		keys = append(keys, d.Key)
+	}
+	assert.Equal(t, []string{
+		"replicaCount", "image", "image.repository", "image.tag", "image.pullPolicy",
+		"labels", "serviceAccount", "serviceAccount.create",
+	}, keys)
+
+	tests := []struct {
+		key      string
+		expected ValueDoc
+	}{
+		{"replicaCount", ValueDoc{Key: "replicaCount", Type: "integer", Default: 1, Description: "Number of replicas"}},
+		{"image", ValueDoc{Key: "image", Type: "object", Description: "The container image", Required: true}},
+		{"image.repository", ValueDoc{Key: "image.repository", Type: "string", Default: "nginx", Description: "The image repository", Required: true}},
+		{"image.tag", ValueDoc{Key: "image.tag", Type: "string", Default: "1.25", Description: "The image tag"}},
+		{"image.pullPolicy", ValueDoc{Key: "image.pullPolicy", Type: "string", Default: "IfNotPresent", Description: "When to pull the image"}},
+		{"labels", ValueDoc{Key: "labels", Type: "object", Default: map[string]any{}, Description: "Extra labels"}},
+		{"serviceAccount.create", ValueDoc{Key: "serviceAccount.create", Type: "boolean", Default: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			d := docs.Lookup(tt.key)
+			require.NotNil(t, d)
+			got := *d
+			got.Children = nil
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	assert.Nil(t, docs.Lookup("image.missing"))
+	assert.Same(t, docs, docs.Lookup(""))
+}
+
+func TestValuesDocsWithoutSchema(t *testing.T) {
+	docs, err := ValuesDocs([]byte(testDocsValues), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "string", docs.Lookup("image.repository").Type)
+	assert.False(t, docs.Lookup("image").Required)
+
+	_, err = ValuesDocs([]byte(testDocsValues), []byte("{"))
+	assert.Error(t, err)
+}
+
+func TestValuesDocsRecursiveSchema(t *testing.T) {
+	schema := `{
+  "properties": {
+    "tree": {"$ref": "#/definitions/node"}
+  },
+  "definitions": {
+    "node": {
+      "type": "object",
+      "description": "A node of the tree",
+      "properties": {
+        "name": {"type": "string"},
+        "child": {"$ref": "#/definitions/node"}
+      }
+    }
+  }
+}`
+	values := `tree:
+  name: root
+  child:
+    name: leaf
+`
+	docs, err := ValuesDocs([]byte(values), []byte(schema))
+	require.NoError(t, err)
+
+	var keys []string
+	for _, d := range docs.All() {
+		keys = append(keys, d.Key)
+	}
+	assert.Equal(t, []string{
+		"tree", "tree.name", "tree.child", "tree.child.name",
+		"tree.child.child",
+	}, keys)
+	assert.Equal(t, "A node of the tree", docs.Lookup("tree.child.child").Description)
+
+	docs, err = ValuesDocs(nil, []byte(schema))
+	require.NoError(t, err)
+	assert.NotNil(t, docs.Lookup("tree.child"))
+	assert.Nil(t, docs.Lookup("tree.child.child"))
+}
@@ -86,6 +86,12 @@ func TestToRenderValues(t *testing.T) {
 	if !relmap["IsInstall"].(bool) {
 		t.Error("Expected install to be true.")
 	}
+	if history := relmap["History"].(map[string]any); history["Revisions"].(int) != 0 || history["ChartVersion"].(string) != "" {
+		t.Errorf("Expected an empty history on install, got %v", history)
+	}
+	if relmap["Slot"].(string) != "" || relmap["ActiveSlot"].(string) != "" {
+		t.Errorf("Expected no slots, got %q and %q", relmap["Slot"], relmap["ActiveSlot"])
+	}
 	if !res["Capabilities"].(*common.Capabilities).APIVersions.Has("v1") {
 		t.Error("Expected Capabilities to have v1 as an API")
 	}
@@ -126,6 +126,33 @@ type ReleaseOptions struct {
 	Revision  int
 	IsUpgrade bool
 	IsInstall bool
+	// History describes the previous revisions of the release during an
+	// upgrade. It is exposed to templates as .Release.History.
+	History *ReleaseHistory
+	// Slot is the blue/green slot being deployed, and ActiveSlot the slot the
+	// Services of the release select. They are exposed to templates as
+	// .Release.Slot and .Release.ActiveSlot, and are empty for releases
+	// without slots.
+	Slot       string
+	ActiveSlot string
+}
+
+// ReleaseHistory describes the previous revisions of a release to the
+// templates rendered during an upgrade, so that charts can migrate from the
+// deployed chart conditionally.
+type ReleaseHistory struct {
+	// Revision is the revision of the release the upgrade starts from.
+	Revision int
+	// Revisions is the number of revisions of the release in the history.
+	Revisions int
+	// ChartName, ChartVersion and AppVersion describe the chart of the
+	// revision the upgrade starts from.
+	ChartName    string
+	ChartVersion string
+	AppVersion   string
+	// ValuesHash is the SHA-256 digest of the user-supplied values of the
+	// revision the upgrade starts from, prepended with the scheme.
+	ValuesHash string
 }
 
 // istable is a special-purpose function to see if the present thing matches the definition of a YAML table.
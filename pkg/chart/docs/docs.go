@@ -0,0 +1,129 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package docs generates reference documentation for charts.
+
+The values of a chart are documented in a Markdown table built from the
+comments of its values.yaml and the descriptions of its values.schema.json.
+The table is injected into the chart README between a pair of markers:
+
+	<!-- helm:values:begin -->
+	<!-- helm:values:end -->
+
+Only the content between the markers is replaced, so the rest of the README
+is left as written and regenerating an up to date README changes nothing.
+*/
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common/util"
+)
+
+const (
+	// BeginMarker starts the generated region of a README.
+	BeginMarker = "<!-- helm:values:begin -->"
+	// EndMarker ends the generated region of a README.
+	EndMarker = "<!-- helm:values:end -->"
+)
+
+// ErrMarkers is returned when the markers of a README are not a single,
+// ordered pair.
+var ErrMarkers = errors.New("the README must contain a single " + BeginMarker + " followed by a single " + EndMarker)
+
+// ValuesTable renders the documentation of values as a Markdown table, with
+// one row for each key that has no nested keys.
+func ValuesTable(values *util.ValueDoc) string {
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Description |\n")
+	b.WriteString("|-----|------|---------|-------------|\n")
+	for _, d := range values.All() {
+		if len(d.Children) > 0 {
+			continue
+		}
+		description := d.Description
+		if d.Required {
+			description = strings.TrimSpace("Required. " + description)
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", d.Key, cell(d.Type), defaultCell(d.Default), cell(description))
+	}
+	return b.String()
+}
+
+// Inject replaces the generated region of readme with section. A README
+// without markers gets a "Values" heading and the region appended.
+func Inject(readme, section []byte) ([]byte, error) {
+	begin, end := []byte(BeginMarker), []byte(EndMarker)
+	region := append(append(append(append([]byte{}, begin...), '\n'), section...), end...)
+
+	nb, ne := bytes.Count(readme, begin), bytes.Count(readme, end)
+	if nb == 0 && ne == 0 {
+		var out bytes.Buffer
+		out.Write(readme)
+		if len(readme) > 0 {
+			if !bytes.HasSuffix(readme, []byte("\n")) {
+				out.WriteByte('\n')
+			}
+			out.WriteByte('\n')
+		}
+		out.WriteString("## Values\n\n")
+		out.Write(region)
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+
+	i, j := bytes.Index(readme, begin), bytes.Index(readme, end)
+	if nb != 1 || ne != 1 || j < i {
+		return nil, ErrMarkers
+	}
+	out := append([]byte{}, readme[:i]...)
+	out = append(out, region...)
+	return append(out, readme[j+len(end):]...), nil
+}
+
+// Generate returns readme with its values table generated from the values
+// file and the values schema of a chart. Both are optional.
+func Generate(readme, values, schema []byte) ([]byte, error) {
+	d, err := util.ValuesDocs(values, schema)
+	if err != nil {
+		return nil, err
+	}
+	return Inject(readme, []byte(ValuesTable(d)))
+}
+
+func defaultCell(v any) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return cell(fmt.Sprint(v))
+	}
+	// Table cells are split on pipes even inside code spans.
+	return "`" + strings.ReplaceAll(string(b), "|", `\|`) + "`"
+}
+
+// cell escapes a value so it fits in a single Markdown table cell.
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
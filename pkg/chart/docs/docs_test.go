@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const values = `# -- Number of replicas.
+replicaCount: 1
+image:
+  repository: nginx # the image to run
+  tag: ""
+ingress:
+  hosts: []
+`
+
+const schema = `{
+  "properties": {
+    "image": {
+      "properties": {
+        "tag": {"type": "string", "description": "Overrides the | image tag."}
+      },
+      "required": ["tag"]
+    }
+  }
+}`
+
+const table = `| Key | Type | Default | Description |
+|-----|------|---------|-------------|
+| ` + "`replicaCount`" + ` | integer | ` + "`1`" + ` | Number of replicas. |
+| ` + "`image.repository`" + ` | string | ` + "`\"nginx\"`" + ` | the image to run |
+| ` + "`image.tag`" + ` | string | ` + "`\"\"`" + ` | Required. Overrides the \| image tag. |
+| ` + "`ingress.hosts`" + ` | array | ` + "`[]`" + ` |  |
+`
+
+func TestGenerate(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		readme string
+		expect string
+		err    error
+	}{
+		{
+			name:   "empty README",
+			expect: "## Values\n\n" + BeginMarker + "\n" + table + EndMarker + "\n",
+		},
+		{
+			name:   "README without markers",
+			readme: "# mychart\n\nA chart.",
+			expect: "# mychart\n\nA chart.\n\n## Values\n\n" + BeginMarker + "\n" + table + EndMarker + "\n",
+		},
+		{
+			name:   "README with markers",
+			readme: "# mychart\n\n" + BeginMarker + "\nstale\n" + EndMarker + "\n\n## License\n",
+			expect: "# mychart\n\n" + BeginMarker + "\n" + table + EndMarker + "\n\n## License\n",
+		},
+		{
+			name:   "markers out of order",
+			readme: EndMarker + "\n" + BeginMarker + "\n",
+			err:    ErrMarkers,
+		},
+		{
+			name:   "missing end marker",
+			readme: BeginMarker + "\n",
+			err:    ErrMarkers,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Generate([]byte(tt.readme), []byte(values), []byte(schema))
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, string(out))
+
+			// Generating again is stable.
+			again, err := Generate(out, []byte(values), []byte(schema))
+			require.NoError(t, err)
+			assert.Equal(t, string(out), string(again))
+		})
+	}
+}
@@ -19,6 +19,7 @@ package archive
 
 import (
 	"archive/tar"
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"errors"
@@ -55,7 +56,75 @@ type BufferedFile struct {
 // LoadArchiveFiles reads in files out of an archive into memory. This function
 // performs important path security checks and should always be used before
 // expanding a tarball
+//
+// Charts larger than the loader limits, MaxDecompressedChartSize,
+// MaxDecompressedFileSize, MaxFileCount and MaxPathDepth, are rejected with an
+// error matching ErrLimitExceeded.
 func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
+	return loadArchiveFiles(in, nil)
+}
+
+// LoadArchiveFilesLazy is like LoadArchiveFiles, but does not read the data of
+// the files for which lazy returns true: their Data is nil and they can be
+// read later with ReadArchiveFile. The loader limits apply to all files.
+func LoadArchiveFilesLazy(in io.Reader, lazy func(name string, size int64) bool) ([]*BufferedFile, error) {
+	return loadArchiveFiles(in, lazy)
+}
+
+// ReadArchiveFile reads the data of the file with the given name, as returned
+// by LoadArchiveFiles, from a chart archive.
+func ReadArchiveFile(in io.Reader, name string) ([]byte, error) {
+	r, err := OpenArchiveFile(in, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, MaxDecompressedFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > MaxDecompressedFileSize {
+		return nil, limitErrorf("chart file %q is larger than the maximum file size %d", name, MaxDecompressedFileSize)
+	}
+	return data, nil
+}
+
+// OpenArchiveFile returns a reader of the data of the file with the given
+// name, as returned by LoadArchiveFiles, from a chart archive. The data is
+// streamed from in, so that parts of large files can be read without holding
+// the whole file in memory.
+func OpenArchiveFile(in io.Reader, name string) (io.Reader, error) {
+	unzipped, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(unzipped)
+	for {
+		hd, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("file %q not found in chart archive", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hd.FileInfo().IsDir() || hd.Typeflag == tar.TypeXGlobalHeader || hd.Typeflag == tar.TypeXHeader {
+			continue
+		}
+		if n, err := entryName(hd.Name); err != nil || n != name {
+			continue
+		}
+		if hd.Size > MaxDecompressedFileSize {
+			return nil, limitErrorf("chart file %q is larger than the maximum file size %d", name, MaxDecompressedFileSize)
+		}
+		r := bufio.NewReader(tr)
+		if bom, err := r.Peek(len(utf8bom)); err == nil && bytes.Equal(bom, utf8bom) {
+			_, _ = r.Discard(len(utf8bom))
+		}
+		return r, nil
+	}
+}
+
+func loadArchiveFiles(in io.Reader, lazy func(name string, size int64) bool) ([]*BufferedFile, error) {
 	unzipped, err := gzip.NewReader(in)
 	if err != nil {
 		return nil, err
@@ -65,6 +134,7 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 	files := []*BufferedFile{}
 	tr := tar.NewReader(unzipped)
 	remainingSize := MaxDecompressedChartSize
+	budget := NewBudget()
 	for {
 		b := bytes.NewBuffer(nil)
 		hd, err := tr.Next()
@@ -87,49 +157,18 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 			continue
 		}
 
-		// Archive could contain \ if generated on Windows
-		delimiter := "/"
-		if strings.ContainsRune(hd.Name, '\\') {
-			delimiter = "\\"
-		}
-
-		parts := strings.Split(hd.Name, delimiter)
-		n := strings.Join(parts[1:], delimiter)
-
-		// Normalize the path to the / delimiter
-		n = strings.ReplaceAll(n, delimiter, "/")
-
-		if path.IsAbs(n) {
-			return nil, errors.New("chart illegally contains absolute paths")
-		}
-
-		n = path.Clean(n)
-		if n == "." {
-			// In this case, the original path was relative when it should have been absolute.
-			return nil, fmt.Errorf("chart illegally contains content outside the base directory: %q", hd.Name)
-		}
-		if strings.HasPrefix(n, "..") {
-			return nil, errors.New("chart illegally references parent directory")
-		}
-
-		// In some particularly arcane acts of path creativity, it is possible to intermix
-		// UNIX and Windows style paths in such a way that you produce a result of the form
-		// c:/foo even after all the built-in absolute path checks. So we explicitly check
-		// for this condition.
-		if drivePathPattern.MatchString(n) {
-			return nil, errors.New("chart contains illegally named files")
-		}
-
-		if parts[0] == "Chart.yaml" {
-			return nil, errors.New("chart yaml not in base directory")
+		n, err := entryName(hd.Name)
+		if err != nil {
+			return nil, err
 		}
 
-		if hd.Size > remainingSize {
-			return nil, fmt.Errorf("decompressed chart is larger than the maximum size %d", MaxDecompressedChartSize)
+		if err := budget.Add(n, hd.Size); err != nil {
+			return nil, err
 		}
 
-		if hd.Size > MaxDecompressedFileSize {
-			return nil, fmt.Errorf("decompressed chart file %q is larger than the maximum file size %d", hd.Name, MaxDecompressedFileSize)
+		if lazy != nil && lazy(n, hd.Size) {
+			files = append(files, &BufferedFile{Name: n, ModTime: hd.ModTime})
+			continue
 		}
 
 		limitedReader := io.LimitReader(tr, remainingSize)
@@ -145,7 +184,7 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 		// is the one that goes over the limit. It assumes the Size stored in the tar header
 		// is correct, something many applications do.
 		if bytesWritten < hd.Size || remainingSize <= 0 {
-			return nil, fmt.Errorf("decompressed chart is larger than the maximum size %d", MaxDecompressedChartSize)
+			return nil, limitErrorf("decompressed chart is larger than the maximum size %d", MaxDecompressedChartSize)
 		}
 
 		data := bytes.TrimPrefix(b.Bytes(), utf8bom)
@@ -160,6 +199,48 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 	return files, nil
 }
 
+// entryName returns the path of an archive entry relative to the chart
+// directory, rejecting paths that point outside of it.
+func entryName(name string) (string, error) {
+	// Archive could contain \ if generated on Windows
+	delimiter := "/"
+	if strings.ContainsRune(name, '\\') {
+		delimiter = "\\"
+	}
+
+	parts := strings.Split(name, delimiter)
+	n := strings.Join(parts[1:], delimiter)
+
+	// Normalize the path to the / delimiter
+	n = strings.ReplaceAll(n, delimiter, "/")
+
+	if path.IsAbs(n) {
+		return "", errors.New("chart illegally contains absolute paths")
+	}
+
+	n = path.Clean(n)
+	if n == "." {
+		// In this case, the original path was relative when it should have been absolute.
+		return "", fmt.Errorf("chart illegally contains content outside the base directory: %q", name)
+	}
+	if strings.HasPrefix(n, "..") {
+		return "", errors.New("chart illegally references parent directory")
+	}
+
+	// In some particularly arcane acts of path creativity, it is possible to intermix
+	// UNIX and Windows style paths in such a way that you produce a result of the form
+	// c:/foo even after all the built-in absolute path checks. So we explicitly check
+	// for this condition.
+	if drivePathPattern.MatchString(n) {
+		return "", errors.New("chart contains illegally named files")
+	}
+
+	if parts[0] == "Chart.yaml" {
+		return "", errors.New("chart yaml not in base directory")
+	}
+	return n, nil
+}
+
 // ensureArchive's job is to return an informative error if the file does not appear to be a gzipped archive.
 //
 // Sometimes users will provide a values.yaml for an argument where a chart is expected. One common occurrence
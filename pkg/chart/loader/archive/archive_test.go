@@ -90,3 +90,59 @@ func TestLoadArchiveFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadArchiveFilesLazy(t *testing.T) {
+	buf := &bytes.Buffer{}
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+	for name, data := range map[string]string{
+		"mychart/Chart.yaml":    "name: mychart\n",
+		"mychart/data/big.bin":  "0123456789",
+		"mychart/data/small.js": "{}",
+	} {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(data)), Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = tw.Close()
+	_ = gzw.Close()
+	archive := buf.Bytes()
+
+	files, err := LoadArchiveFilesLazy(bytes.NewReader(archive), func(name string, size int64) bool {
+		return name != "Chart.yaml" && size > 5
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+	for _, f := range files {
+		switch f.Name {
+		case "data/big.bin":
+			if f.Data != nil {
+				t.Errorf("expected %s not to be read, got %q", f.Name, f.Data)
+			}
+		case "Chart.yaml", "data/small.js":
+			if len(f.Data) == 0 {
+				t.Errorf("expected %s to be read", f.Name)
+			}
+		default:
+			t.Errorf("unexpected file %s", f.Name)
+		}
+	}
+
+	data, err := ReadArchiveFile(bytes.NewReader(archive), "data/big.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "0123456789" {
+		t.Errorf("expected the data of data/big.bin, got %q", data)
+	}
+	if _, err := ReadArchiveFile(bytes.NewReader(archive), "data/missing"); err == nil || err.Error() != `file "data/missing" not found in chart archive` {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxFileCount is the maximum number of files in a chart, not counting the
+// files of subcharts packaged as archives.
+var MaxFileCount = 10000
+
+// MaxPathDepth is the maximum number of elements of the path of a chart file,
+// such as 3 for "templates/tests/test.yaml".
+var MaxPathDepth = 32
+
+// ErrLimitExceeded is matched by the errors returned when a chart is larger
+// than the loader limits, MaxDecompressedChartSize, MaxDecompressedFileSize,
+// MaxFileCount and MaxPathDepth. MaxDecompressedChartSize applies to archives
+// only.
+var ErrLimitExceeded = errors.New("chart exceeds the loader limits")
+
+type limitError string
+
+func (e limitError) Error() string { return string(e) }
+
+func (e limitError) Is(target error) bool { return target == ErrLimitExceeded }
+
+func limitErrorf(format string, a ...any) error {
+	return limitError(fmt.Sprintf(format, a...))
+}
+
+// Budget accounts for the files of a chart being loaded and reports when the
+// chart exceeds the loader limits. The limits are read when the budget is
+// created.
+type Budget struct {
+	maxChartSize int64
+	maxFileSize  int64
+	maxFileCount int
+	maxPathDepth int
+
+	size  int64
+	files int
+}
+
+// NewBudget returns a budget with the current loader limits.
+func NewBudget() *Budget {
+	return &Budget{
+		maxChartSize: MaxDecompressedChartSize,
+		maxFileSize:  MaxDecompressedFileSize,
+		maxFileCount: MaxFileCount,
+		maxPathDepth: MaxPathDepth,
+	}
+}
+
+// NewDirectoryBudget returns a budget with the current loader limits that
+// apply to chart directories. MaxDecompressedChartSize bounds the memory used
+// to decompress an archive and is not enforced on directories, whose files
+// are already on disk.
+func NewDirectoryBudget() *Budget {
+	b := NewBudget()
+	b.maxChartSize = 0
+	return b
+}
+
+// Add accounts for a file of the given size. The name is the slash separated
+// path of the file in the chart.
+func (b *Budget) Add(name string, size int64) error {
+	b.files++
+	if b.maxFileCount > 0 && b.files > b.maxFileCount {
+		return limitErrorf("chart has more than the maximum of %d files", b.maxFileCount)
+	}
+	if depth := strings.Count(strings.Trim(name, "/"), "/") + 1; b.maxPathDepth > 0 && depth > b.maxPathDepth {
+		return limitErrorf("chart file %q is nested deeper than the maximum path depth %d", name, b.maxPathDepth)
+	}
+	if size > b.maxFileSize {
+		return limitErrorf("chart file %q is larger than the maximum file size %d", name, b.maxFileSize)
+	}
+	b.size += size
+	if b.maxChartSize > 0 && b.size > b.maxChartSize {
+		return limitErrorf("chart is larger than the maximum size %d", b.maxChartSize)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func setLimits(t *testing.T, chartSize, fileSize int64, fileCount, pathDepth int) {
+	t.Helper()
+	oldChartSize, oldFileSize, oldFileCount, oldPathDepth := MaxDecompressedChartSize, MaxDecompressedFileSize, MaxFileCount, MaxPathDepth
+	MaxDecompressedChartSize, MaxDecompressedFileSize, MaxFileCount, MaxPathDepth = chartSize, fileSize, fileCount, pathDepth
+	t.Cleanup(func() {
+		MaxDecompressedChartSize, MaxDecompressedFileSize, MaxFileCount, MaxPathDepth = oldChartSize, oldFileSize, oldFileCount, oldPathDepth
+	})
+}
+
+func archiveOf(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "chart/" + name, Size: int64(len(data)), Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestLoadArchiveFilesLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		chartSize int64
+		err       string
+	}{
+		{
+			name:  "within limits",
+			files: map[string]string{"Chart.yaml": "name: a", "templates/a.yaml": "a: b"},
+		},
+		{
+			name:  "too many files",
+			files: map[string]string{"Chart.yaml": "name: a", "a": "", "b": ""},
+			err:   "chart has more than the maximum of 2 files",
+		},
+		{
+			name:  "nested too deep",
+			files: map[string]string{"templates/tests/a.yaml": ""},
+			err:   `chart file "templates/tests/a.yaml" is nested deeper than the maximum path depth 2`,
+		},
+		{
+			name:  "file too large",
+			files: map[string]string{"values.yaml": strings.Repeat("a", 11)},
+			err:   `chart file "values.yaml" is larger than the maximum file size 10`,
+		},
+		{
+			name:      "chart too large",
+			files:     map[string]string{"a": strings.Repeat("a", 8), "b": strings.Repeat("b", 8)},
+			chartSize: 10,
+			err:       "chart is larger than the maximum size 10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chartSize := int64(100)
+			if tt.chartSize != 0 {
+				chartSize = tt.chartSize
+			}
+			setLimits(t, chartSize, 10, 2, 2)
+			_, err := LoadArchiveFiles(archiveOf(t, tt.files))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("expected %v to match ErrLimitExceeded", err)
+			}
+		})
+	}
+}
@@ -66,6 +66,48 @@ func Load(name string) (chart.Charter, error) {
 	return l.Load()
 }
 
+// LoadLazy loads a chart like Load, but the large files of a v2 chart archive
+// are only read from the archive when they are needed, as with the v2
+// loader's LoadFileLazy. The archive must not change while the chart is in
+// use.
+func LoadLazy(name string) (chart.Charter, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return LoadDir(name)
+	}
+
+	raw, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer raw.Close()
+	if err := archive.EnsureArchive(name, raw); err != nil {
+		return nil, err
+	}
+	data, err := archive.ReadArchiveFile(raw, "Chart.yaml")
+	if err != nil {
+		if errors.Is(err, gzip.ErrHeader) {
+			return nil, fmt.Errorf("file '%s' does not appear to be a valid chart file (details: %w)", name, err)
+		}
+		return nil, err
+	}
+	c := new(chartBase)
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("cannot load Chart.yaml: %w", err)
+	}
+	switch c.APIVersion {
+	case c2.APIVersionV1, c2.APIVersionV2, "":
+		return c2load.LoadFileLazy(name)
+	case c3.APIVersionV3:
+		return c3load.Load(name)
+	default:
+		return nil, errors.New("unsupported chart version")
+	}
+}
+
 // DirLoader loads a chart from a directory
 type DirLoader string
 
@@ -74,7 +116,17 @@ func (l DirLoader) Load() (chart.Charter, error) {
 	return LoadDir(string(l))
 }
 
+// Options configures how a chart is loaded from a directory.
+type Options = c2load.Options
+
+// LoadDir loads a chart from a directory, detecting its API version.
 func LoadDir(dir string) (chart.Charter, error) {
+	return LoadDirWithOptions(dir, Options{})
+}
+
+// LoadDirWithOptions loads a chart from a directory like LoadDir, following
+// only the symbolic links allowed by opts.SymlinkPolicy.
+func LoadDirWithOptions(dir string, opts Options) (chart.Charter, error) {
 	topdir, err := filepath.Abs(dir)
 	if err != nil {
 		return nil, err
@@ -94,9 +146,14 @@ func LoadDir(dir string) (chart.Charter, error) {
 
 	switch c.APIVersion {
 	case c2.APIVersionV1, c2.APIVersionV2, "":
-		return c2load.Load(dir)
+		return c2load.LoadDirWithOptions(dir, opts)
 	case c3.APIVersionV3:
-		return c3load.Load(dir)
+		return c3load.LoadDirWithOptions(dir, c3load.Options{
+			SymlinkPolicy: opts.SymlinkPolicy,
+			OnIgnored:     opts.OnIgnored,
+			Exclude:       opts.Exclude,
+			Include:       opts.Include,
+		})
 	default:
 		return nil, errors.New("unsupported chart version")
 	}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -31,6 +32,7 @@ import (
 	c3 "helm.sh/helm/v4/internal/chart/v3"
 	"helm.sh/helm/v4/pkg/chart"
 	c2 "helm.sh/helm/v4/pkg/chart/v2"
+	c2load "helm.sh/helm/v4/pkg/chart/v2/loader"
 )
 
 // createChartArchive is a helper function to create a gzipped tar archive in memory
@@ -184,3 +186,47 @@ func TestLoadArchive(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadLazy(t *testing.T) {
+	defer func(threshold int64) { c2load.LazyFileThreshold = threshold }(c2load.LazyFileThreshold)
+	c2load.LazyFileThreshold = 0
+
+	writeArchive := func(apiVersion string) string {
+		archive := createChartArchive(t, "lazy", apiVersion, map[string][]byte{
+			"templates/config.yaml": []byte("key: value"),
+			"data.bin":              []byte("large data"),
+		}, true)
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := filepath.Join(t.TempDir(), "lazy-0.1.0.tgz")
+		if err := os.WriteFile(name, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+
+	ch, err := LoadLazy(writeArchive(c2.APIVersionV2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, ok := ch.(*c2.Chart)
+	if !ok {
+		t.Fatalf("expected a v2 chart, got %T", ch)
+	}
+	if len(c.Files) != 1 || c.Files[0].IsLoaded() {
+		t.Fatalf("expected data.bin to be loaded lazily, got %v", c.Files)
+	}
+	if err := c.Files[0].Load(); err != nil || string(c.Files[0].Data) != "large data" {
+		t.Errorf("unexpected lazy file data %q: %v", c.Files[0].Data, err)
+	}
+
+	ch, err = LoadLazy(writeArchive(c3.APIVersionV3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ch.(*c3.Chart); !ok {
+		t.Errorf("expected a v3 chart, got %T", ch)
+	}
+}
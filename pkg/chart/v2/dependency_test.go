@@ -42,3 +42,23 @@ func TestValidateDependency(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDependencyExportValues(t *testing.T) {
+	for _, tt := range []struct {
+		ev         ExportValue
+		shouldFail bool
+	}{
+		{ExportValue{Parent: "db.host", Child: "primary.host"}, false},
+		{ExportValue{Parent: ".", Child: "config"}, false},
+		{ExportValue{Parent: "db.host"}, true},
+		{ExportValue{Child: "primary.host"}, true},
+	} {
+		dep := &Dependency{Name: "example", ExportValues: []ExportValue{tt.ev}}
+		res := dep.Validate()
+		if res != nil && !tt.shouldFail {
+			t.Errorf("Failed on case %+v", tt.ev)
+		} else if res == nil && tt.shouldFail {
+			t.Errorf("Expected failure for %+v", tt.ev)
+		}
+	}
+}
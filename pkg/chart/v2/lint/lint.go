@@ -18,6 +18,7 @@ package lint // import "helm.sh/helm/v4/pkg/chart/v2/lint"
 
 import (
 	"path/filepath"
+	"slices"
 
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
@@ -27,6 +28,11 @@ import (
 type linterOptions struct {
 	KubeVersion          *common.KubeVersion
 	SkipSchemaValidation bool
+	StrictValuesSchema   bool
+	ValidateOffline      bool
+	SchemaDirs           []string
+	PolicyDir            string
+	EnabledRules         []string
 }
 
 type LinterOption func(lo *linterOptions)
@@ -43,6 +49,40 @@ func WithSkipSchemaValidation(skipSchemaValidation bool) LinterOption {
 	}
 }
 
+// WithStrictValuesSchema reports values keys that are not declared in the
+// chart schema as errors. They are not checked otherwise.
+func WithStrictValuesSchema(strictValuesSchema bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.StrictValuesSchema = strictValuesSchema
+	}
+}
+
+// WithOfflineValidation validates the rendered manifests against the schemas
+// of their kinds without a cluster. Schemas are looked up in the chart's CRDs,
+// in schemaDirs and in the built-in Kubernetes types.
+func WithOfflineValidation(schemaDirs []string) LinterOption {
+	return func(lo *linterOptions) {
+		lo.ValidateOffline = true
+		lo.SchemaDirs = schemaDirs
+	}
+}
+
+// WithPolicyDir checks the rendered manifests against the CEL policies of the
+// YAML files in dir.
+func WithPolicyDir(dir string) LinterOption {
+	return func(lo *linterOptions) {
+		lo.PolicyDir = dir
+	}
+}
+
+// WithEnabledRules enables the optional rule groups, such as
+// rules.SecurityRules, that are not run by default.
+func WithEnabledRules(groups []string) LinterOption {
+	return func(lo *linterOptions) {
+		lo.EnabledRules = groups
+	}
+}
+
 func RunAll(baseDir string, values map[string]any, namespace string, options ...LinterOption) support.Linter {
 	chartDir, _ := filepath.Abs(baseDir)
 
@@ -57,12 +97,24 @@ func RunAll(baseDir string, values map[string]any, namespace string, options ...
 
 	rules.Chartfile(&result)
 	rules.ValuesWithOverrides(&result, values, lo.SkipSchemaValidation)
+	if !lo.SkipSchemaValidation && lo.StrictValuesSchema {
+		rules.UnknownValues(&result, values, true)
+	}
+	var policies []*rules.Policy
+	if lo.PolicyDir != "" {
+		var err error
+		policies, err = rules.LoadPolicies(lo.PolicyDir)
+		result.RunLinterRule(support.ErrorSev, lo.PolicyDir, err)
+	}
 	rules.Templates(
 		&result,
 		namespace,
 		values,
 		rules.TemplateLinterKubeVersion(lo.KubeVersion),
-		rules.TemplateLinterSkipSchemaValidation(lo.SkipSchemaValidation))
+		rules.TemplateLinterSkipSchemaValidation(lo.SkipSchemaValidation),
+		rules.TemplateLinterOfflineValidation(lo.ValidateOffline, lo.SchemaDirs),
+		rules.TemplateLinterPolicies(policies),
+		rules.TemplateLinterSecurityRules(slices.Contains(lo.EnabledRules, rules.SecurityRules)))
 	rules.Dependencies(&result)
 	rules.Crds(&result)
 
@@ -0,0 +1,257 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// Policy is a check written in CEL that every matching rendered resource must
+// pass.
+//
+// Policies are read from the YAML files of a policy directory, one policy per
+// document:
+//
+//	name: no-latest-tag
+//	severity: warning
+//	match:
+//	  kinds: [Deployment, StatefulSet]
+//	rule: object.spec.template.spec.containers.all(c, !c.image.endsWith(':latest'))
+//	message: containers must not use the latest tag
+//
+// The rule is evaluated with the resource bound to 'object' and must return
+// true for resources that comply with the policy.
+type Policy struct {
+	Name     string      `json:"name"`
+	Severity string      `json:"severity,omitempty"`
+	Match    PolicyMatch `json:"match,omitempty"`
+	Rule     string      `json:"rule"`
+	Message  string      `json:"message,omitempty"`
+
+	program  cel.Program
+	severity int
+}
+
+// PolicyMatch restricts a policy to some resources. Empty lists match all
+// resources.
+type PolicyMatch struct {
+	APIVersions []string `json:"apiVersions,omitempty"`
+	Kinds       []string `json:"kinds,omitempty"`
+}
+
+// PolicyViolation is the lint error reported for a resource that does not
+// comply with a policy.
+type PolicyViolation struct {
+	Policy    string
+	Severity  int
+	Template  string
+	Kind      string
+	Namespace string
+	Name      string
+	Message   string
+}
+
+// Error implements the error interface.
+func (v PolicyViolation) Error() string {
+	resource := v.Name
+	if v.Namespace != "" {
+		resource = v.Namespace + "/" + v.Name
+	}
+	return fmt.Sprintf("policy %q: %s %q: %s", v.Policy, v.Kind, resource, v.Message)
+}
+
+var policySeverities = map[string]int{
+	"":        support.ErrorSev,
+	"error":   support.ErrorSev,
+	"warning": support.WarningSev,
+	"info":    support.InfoSev,
+}
+
+// LoadPolicies reads and compiles the policies of the YAML files in dir.
+func LoadPolicies(dir string) ([]*Policy, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*Policy
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		switch filepath.Ext(name) {
+		case ".yaml", ".yml":
+		case ".rego":
+			return nil, fmt.Errorf("%s: Rego policies are not supported, write the policy in CEL", name)
+		default:
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		filePolicies, err := parsePolicies(env, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		policies = append(policies, filePolicies...)
+	}
+	return policies, nil
+}
+
+func parsePolicies(env *cel.Env, data []byte) ([]*Policy, error) {
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	var policies []*Policy
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return policies, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var p Policy
+		if err := yaml.UnmarshalStrict(doc, &p); err != nil {
+			return nil, err
+		}
+		if p.Name == "" && p.Rule == "" {
+			continue
+		}
+		if err := p.compile(env); err != nil {
+			return nil, err
+		}
+		policies = append(policies, &p)
+	}
+}
+
+func (p *Policy) compile(env *cel.Env) error {
+	if p.Name == "" {
+		return errors.New("policy name is required")
+	}
+	severity, ok := policySeverities[strings.ToLower(p.Severity)]
+	if !ok {
+		return fmt.Errorf("policy %q: unknown severity %q", p.Name, p.Severity)
+	}
+	p.severity = severity
+
+	ast, iss := env.Compile(p.Rule)
+	if iss.Err() != nil {
+		return fmt.Errorf("policy %q: %w", p.Name, iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return fmt.Errorf("policy %q: rule must return a bool, not %s", p.Name, ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return fmt.Errorf("policy %q: %w", p.Name, err)
+	}
+	p.program = program
+	return nil
+}
+
+// matches reports whether the policy applies to a resource.
+func (p *Policy) matches(apiVersion, kind string) bool {
+	if len(p.Match.APIVersions) > 0 && !slices.Contains(p.Match.APIVersions, apiVersion) {
+		return false
+	}
+	return len(p.Match.Kinds) == 0 || slices.Contains(p.Match.Kinds, kind)
+}
+
+// evaluate checks a resource against the policy and returns the violation,
+// if any.
+func (p *Policy) evaluate(template string, meta k8sYamlStruct, object map[string]any) error {
+	if !p.matches(meta.APIVersion, meta.Kind) {
+		return nil
+	}
+
+	out, _, err := p.program.Eval(map[string]any{"object": object})
+	if err != nil {
+		return fmt.Errorf("policy %q: evaluating %s %q: %w", p.Name, meta.Kind, meta.Metadata.Name, err)
+	}
+	if out == types.True {
+		return nil
+	}
+	if out != types.False {
+		return fmt.Errorf("policy %q: rule returned %v, not a bool", p.Name, out.Value())
+	}
+	message := p.Message
+	if message == "" {
+		message = "failed rule " + p.Rule
+	}
+	return PolicyViolation{
+		Policy:    p.Name,
+		Severity:  p.severity,
+		Template:  template,
+		Kind:      meta.Kind,
+		Namespace: meta.Metadata.Namespace,
+		Name:      meta.Metadata.Name,
+		Message:   message,
+	}
+}
+
+// checkPolicies evaluates the policies against every document of a rendered
+// template.
+func (t *templateLinter) checkPolicies(fileName, renderedContent string) {
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(strings.NewReader(renderedContent)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			// Invalid YAML is reported by the other template checks.
+			return
+		}
+		var object map[string]any
+		if err := yaml.Unmarshal(doc, &object); err != nil || object == nil {
+			continue
+		}
+		var meta k8sYamlStruct
+		if err := yaml.Unmarshal(doc, &meta); err != nil {
+			continue
+		}
+		for _, p := range t.policies {
+			err := p.evaluate(fileName, meta, object)
+			var violation PolicyViolation
+			if errors.As(err, &violation) {
+				t.linter.RunLinterRule(violation.Severity, fileName, err)
+				continue
+			}
+			t.linter.RunLinterRule(support.ErrorSev, fileName, err)
+		}
+	}
+}
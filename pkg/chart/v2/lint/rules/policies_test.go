@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestLoadPolicies(t *testing.T) {
+	policies, err := LoadPolicies("testdata/policies")
+	require.NoError(t, err)
+	var names []string
+	for _, p := range policies {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"team-label", "no-latest-tag", "replicas"}, names)
+
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{
+			name:   "unknown severity",
+			policy: "name: p\nseverity: fatal\nrule: 'true'\n",
+			err:    `policy "p": unknown severity "fatal"`,
+		},
+		{
+			name:   "syntax error",
+			policy: "name: p\nrule: object.spec.(\n",
+			err:    `policy "p": ERROR`,
+		},
+		{
+			name:   "not a bool",
+			policy: "name: p\nrule: '1 + 1'\n",
+			err:    `policy "p": rule must return a bool, not int`,
+		},
+		{
+			name:   "missing name",
+			policy: "rule: 'true'\n",
+			err:    "policy name is required",
+		},
+		{
+			name:   "unknown field",
+			policy: "name: p\nexpression: 'true'\n",
+			err:    `unknown field "expression"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte(tt.policy), 0644))
+			_, err := LoadPolicies(dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "policy.rego"), []byte("package helm\n"), 0644))
+	_, err = LoadPolicies(dir)
+	assert.EqualError(t, err, "policy.rego: Rego policies are not supported, write the policy in CEL")
+}
+
+func TestTemplatePolicies(t *testing.T) {
+	policies, err := LoadPolicies("testdata/policies")
+	require.NoError(t, err)
+
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "policies",
+			Version:    "0.1.0",
+		},
+		Templates: []*common.File{
+			{
+				Name: "templates/deployment.yaml",
+				Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  labels:
+    team: web
+spec:
+  replicas: 1
+  selector: {matchLabels: {app: web}}
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:latest
+`),
+			},
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"),
+			},
+		},
+	}
+	tmpdir := t.TempDir()
+	require.NoError(t, chartutil.SaveDir(&mychart, tmpdir))
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, namespace, values, TemplateLinterPolicies(policies))
+
+	require.Len(t, linter.Messages, 3)
+	var violations []PolicyViolation
+	for _, msg := range linter.Messages {
+		var v PolicyViolation
+		require.ErrorAs(t, msg.Err, &v)
+		assert.Equal(t, v.Severity, msg.Severity)
+		violations = append(violations, v)
+	}
+	assert.ElementsMatch(t, []PolicyViolation{
+		{Policy: "team-label", Severity: support.InfoSev, Template: "templates/configmap.yaml", Kind: "ConfigMap", Name: "config", Message: "failed rule has(object.metadata.labels) && 'team' in object.metadata.labels"},
+		{Policy: "no-latest-tag", Severity: support.WarningSev, Template: "templates/deployment.yaml", Kind: "Deployment", Namespace: "prod", Name: "web", Message: "containers must not use the latest tag"},
+		{Policy: "replicas", Severity: support.ErrorSev, Template: "templates/deployment.yaml", Kind: "Deployment", Namespace: "prod", Name: "web", Message: "deployments must run at least two replicas"},
+	}, violations)
+	assert.EqualError(t, violations[2], `policy "replicas": Deployment "prod/web": deployments must run at least two replicas`)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// SecurityRules is the name of the rule group that flags workloads that are
+// not hardened.
+const SecurityRules = "security"
+
+// RuleGroups lists the optional rule groups.
+var RuleGroups = []string{SecurityRules}
+
+// podTemplateOwner holds the pod specs of the workload kinds.
+type podTemplateOwner struct {
+	Spec struct {
+		corev1.PodSpec
+		Template    *corev1.PodTemplateSpec `json:"template"`
+		JobTemplate *struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// podSpec returns the pod spec of a workload, or nil for other kinds.
+func podSpec(kind string, doc []byte) (*corev1.PodSpec, error) {
+	var owner podTemplateOwner
+	switch kind {
+	case "Pod", "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job", "CronJob":
+	default:
+		return nil, nil
+	}
+	if err := yaml.Unmarshal(doc, &owner); err != nil {
+		return nil, err
+	}
+	switch {
+	case kind == "Pod":
+		return &owner.Spec.PodSpec, nil
+	case kind == "CronJob":
+		if owner.Spec.JobTemplate == nil {
+			return nil, nil
+		}
+		return &owner.Spec.JobTemplate.Spec.Template.Spec, nil
+	case owner.Spec.Template != nil:
+		return &owner.Spec.Template.Spec, nil
+	}
+	return nil, nil
+}
+
+// checkSecurity reports the workloads of a rendered template that are
+// privileged, share the host's namespaces or file system, lack resource
+// requests or limits, use mutable image tags or may run as root.
+func (t *templateLinter) checkSecurity(fileName, renderedContent string) {
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(strings.NewReader(renderedContent)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			// Invalid YAML is reported by the other template checks.
+			return
+		}
+		var meta k8sYamlStruct
+		if err := yaml.Unmarshal(doc, &meta); err != nil {
+			continue
+		}
+		spec, err := podSpec(meta.Kind, doc)
+		if err != nil || spec == nil {
+			continue
+		}
+		resource := fmt.Sprintf("%s %q", meta.Kind, meta.Metadata.Name)
+		for _, err := range validatePodSecurity(spec) {
+			t.linter.RunLinterRule(support.WarningSev, fileName, fmt.Errorf("%s: %w", resource, err))
+		}
+	}
+}
+
+// validatePodSecurity returns the hardening issues of a pod spec.
+func validatePodSecurity(spec *corev1.PodSpec) []error {
+	var errs []error
+	if spec.HostNetwork {
+		errs = append(errs, errors.New("uses the host network"))
+	}
+	if spec.HostPID {
+		errs = append(errs, errors.New("uses the host PID namespace"))
+	}
+	if spec.HostIPC {
+		errs = append(errs, errors.New("uses the host IPC namespace"))
+	}
+	for _, v := range spec.Volumes {
+		if v.HostPath != nil {
+			errs = append(errs, fmt.Errorf("volume %q mounts the host path %s", v.Name, v.HostPath.Path))
+		}
+	}
+
+	podNonRoot, podUser := false, (*int64)(nil)
+	if sc := spec.SecurityContext; sc != nil {
+		podNonRoot = sc.RunAsNonRoot != nil && *sc.RunAsNonRoot
+		podUser = sc.RunAsUser
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		name := fmt.Sprintf("container %q", c.Name)
+		nonRoot, user := podNonRoot, podUser
+		if sc := c.SecurityContext; sc != nil {
+			if sc.Privileged != nil && *sc.Privileged {
+				errs = append(errs, fmt.Errorf("%s is privileged", name))
+			}
+			if sc.AllowPrivilegeEscalation != nil && *sc.AllowPrivilegeEscalation {
+				errs = append(errs, fmt.Errorf("%s allows privilege escalation", name))
+			}
+			if sc.RunAsNonRoot != nil {
+				nonRoot = *sc.RunAsNonRoot
+			}
+			if sc.RunAsUser != nil {
+				user = sc.RunAsUser
+			}
+		}
+		switch {
+		case user != nil && *user == 0:
+			errs = append(errs, fmt.Errorf("%s runs as root", name))
+		case !nonRoot && user == nil:
+			errs = append(errs, fmt.Errorf("%s may run as root, set runAsNonRoot or runAsUser", name))
+		}
+
+		if tag := imageTag(c.Image); tag == "" || tag == "latest" {
+			errs = append(errs, fmt.Errorf("%s uses the mutable image %q, pin a version tag or digest", name, c.Image))
+		}
+
+		var missing []string
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := c.Resources.Requests[r]; !ok {
+				missing = append(missing, "requests."+string(r))
+			}
+			if _, ok := c.Resources.Limits[r]; !ok {
+				missing = append(missing, "limits."+string(r))
+			}
+		}
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("%s does not set resources %s", name, strings.Join(missing, ", ")))
+		}
+	}
+	return errs
+}
+
+// imageTag returns the tag of an image reference. Digests count as a tag.
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const hardenedContainer = `
+      - name: app
+        image: nginx:1.27
+        securityContext:
+          runAsNonRoot: true
+        resources:
+          requests: {cpu: 100m, memory: 64Mi}
+          limits: {cpu: 200m, memory: 128Mi}`
+
+func TestSecurityRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		expect   []string
+	}{
+		{
+			name: "hardened deployment",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels: {app: web}
+  template:
+    spec:
+      containers:` + hardenedContainer,
+		},
+		{
+			name:     "not a workload",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+		},
+		{
+			name: "insecure pod",
+			manifest: `apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  hostNetwork: true
+  hostPID: true
+  volumes:
+  - name: root
+    hostPath:
+      path: /
+  containers:
+  - name: shell
+    image: busybox
+    securityContext:
+      privileged: true
+      runAsUser: 0
+`,
+			expect: []string{
+				`Pod "debug": uses the host network`,
+				`Pod "debug": uses the host PID namespace`,
+				`Pod "debug": volume "root" mounts the host path /`,
+				`Pod "debug": container "shell" is privileged`,
+				`Pod "debug": container "shell" runs as root`,
+				`Pod "debug": container "shell" uses the mutable image "busybox", pin a version tag or digest`,
+				`Pod "debug": container "shell" does not set resources requests.cpu, limits.cpu, requests.memory, limits.memory`,
+			},
+		},
+		{
+			name: "cronjob with latest tag and pod level user",
+			manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          securityContext:
+            runAsUser: 1000
+          containers:
+          - name: backup
+            image: registry.example.com:5000/backup:latest
+            resources:
+              requests: {cpu: 100m, memory: 64Mi}
+              limits: {memory: 128Mi}
+`,
+			expect: []string{
+				`CronJob "backup": container "backup" uses the mutable image "registry.example.com:5000/backup:latest", pin a version tag or digest`,
+				`CronJob "backup": container "backup" does not set resources limits.cpu`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mychart := chart.Chart{
+				Metadata: &chart.Metadata{
+					APIVersion: "v2",
+					Name:       "security",
+					Version:    "0.1.0",
+				},
+				Templates: []*common.File{
+					{Name: "templates/workload.yaml", Data: []byte(tt.manifest)},
+				},
+			}
+			tmpdir := t.TempDir()
+			require.NoError(t, chartutil.SaveDir(&mychart, tmpdir))
+
+			linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+			Templates(&linter, namespace, values, TemplateLinterSecurityRules(true))
+
+			var got []string
+			for _, msg := range linter.Messages {
+				assert.Equal(t, support.WarningSev, msg.Severity)
+				got = append(got, msg.Err.Error())
+			}
+			assert.Equal(t, tt.expect, got)
+
+			linter = support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+			Templates(&linter, namespace, values)
+			assert.Empty(t, linter.Messages)
+		})
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	for image, tag := range map[string]string{
+		"nginx":                              "",
+		"nginx:1.27":                         "1.27",
+		"registry:5000/nginx":                "",
+		"registry:5000/team/nginx:latest":    "latest",
+		"nginx@sha256:0123456789abcdef":      "sha256:0123456789abcdef",
+		"nginx:1.27@sha256:0123456789abcdef": "sha256:0123456789abcdef",
+	} {
+		assert.Equal(t, tag, imageTag(image), image)
+	}
+}
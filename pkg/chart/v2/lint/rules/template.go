@@ -34,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
+	"helm.sh/helm/v4/internal/manifestschema"
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/common/util"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
@@ -62,6 +63,30 @@ func TemplateLinterSkipSchemaValidation(skipSchemaValidation bool) TemplateLinte
 	}
 }
 
+// TemplateLinterOfflineValidation validates the rendered manifests against
+// the schemas of their kinds, looked up in the chart's CRDs, in schemaDirs and
+// in the built-in Kubernetes types.
+func TemplateLinterOfflineValidation(validateOffline bool, schemaDirs []string) TemplateLinterOption {
+	return func(tl *templateLinter) {
+		tl.validateOffline = validateOffline
+		tl.schemaDirs = schemaDirs
+	}
+}
+
+// TemplateLinterPolicies checks the rendered manifests against policies.
+func TemplateLinterPolicies(policies []*Policy) TemplateLinterOption {
+	return func(tl *templateLinter) {
+		tl.policies = policies
+	}
+}
+
+// TemplateLinterSecurityRules enables the checks of the security rule group.
+func TemplateLinterSecurityRules(enabled bool) TemplateLinterOption {
+	return func(tl *templateLinter) {
+		tl.securityRules = enabled
+	}
+}
+
 func newTemplateLinter(linter *support.Linter, namespace string, values map[string]any, options ...TemplateLinterOption) templateLinter {
 	result := templateLinter{
 		linter:    linter,
@@ -82,6 +107,10 @@ type templateLinter struct {
 	namespace            string
 	kubeVersion          *common.KubeVersion
 	skipSchemaValidation bool
+	validateOffline      bool
+	schemaDirs           []string
+	policies             []*Policy
+	securityRules        bool
 }
 
 func (t *templateLinter) Lint() {
@@ -119,9 +148,12 @@ func (t *templateLinter) Lint() {
 
 	// lint ignores import-values
 	// See https://github.com/helm/helm/issues/9658
-	if err := chartutil.ProcessDependencies(chart, t.values); err != nil {
+	if err := chartutil.ProcessDependenciesWithoutValidation(chart, t.values); err != nil {
 		return
 	}
+	if !t.skipSchemaValidation {
+		t.linter.RunLinterRule(support.ErrorSev, "values.yaml", chartutil.ValidateGlobalValues(chart, t.values))
+	}
 
 	cvals, err := util.CoalesceValues(chart, t.values)
 	if err != nil {
@@ -135,6 +167,15 @@ func (t *templateLinter) Lint() {
 	}
 	var e engine.Engine
 	e.LintMode = true
+	e.DuplicateTemplateHandler = func(err *engine.DuplicateTemplateError) {
+		// Identical definitions, such as helpers vendored by several
+		// subcharts, behave the same whichever one is used.
+		severity := support.WarningSev
+		if err.Identical {
+			severity = support.InfoSev
+		}
+		t.linter.RunLinterRule(severity, templatesDir, err)
+	}
 	renderedContentMap, err := e.RenderWithContext(context.Background(), chart, valuesToRender)
 
 	renderOk := t.linter.RunLinterRule(support.ErrorSev, templatesDir, err)
@@ -143,6 +184,14 @@ func (t *templateLinter) Lint() {
 		return
 	}
 
+	var validator *manifestschema.Validator
+	if t.validateOffline {
+		validator = manifestschema.New(caps.KubeVersion.String(), t.schemaDirs)
+		for _, crd := range chart.CRDObjects() {
+			t.linter.RunLinterRule(support.ErrorSev, crd.Filename, validator.AddCRDFile(crd.File.Data))
+		}
+	}
+
 	/* Iterate over all the templates to check:
 	- It is a .yaml file
 	- All the values in the template file is defined
@@ -199,7 +248,30 @@ func (t *templateLinter) Lint() {
 					t.linter.RunLinterRule(support.ErrorSev, fileName, validateListAnnotations(yamlStruct, renderedContent))
 				}
 			}
+
+			if validator != nil {
+				t.validateManifests(validator, fileName, renderedContent)
+			}
+			if len(t.policies) > 0 {
+				t.checkPolicies(fileName, renderedContent)
+			}
+			if t.securityRules {
+				t.checkSecurity(fileName, renderedContent)
+			}
+		}
+	}
+}
+
+// validateManifests validates each document of a rendered template against
+// the schema of its kind. Resources without a known schema are reported as
+// information only, as their definitions may be installed by other charts.
+func (t *templateLinter) validateManifests(validator *manifestschema.Validator, fileName, renderedContent string) {
+	for _, err := range validator.ValidateManifests([]byte(renderedContent)) {
+		if errors.Is(err, manifestschema.ErrSchemaNotFound) {
+			t.linter.RunLinterRule(support.InfoSev, fileName, err)
+			continue
 		}
+		t.linter.RunLinterRule(support.ErrorSev, fileName, err)
 	}
 }
 
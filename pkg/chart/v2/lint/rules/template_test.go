@@ -24,6 +24,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"helm.sh/helm/v4/pkg/chart/common"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
@@ -241,6 +243,73 @@ func TestDeprecatedAPIFails(t *testing.T) {
 	}
 }
 
+func TestOfflineValidation(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "offline",
+			Version:    "0.1.0",
+		},
+		Templates: []*common.File{
+			{
+				Name: "templates/deployment.yaml",
+				Data: []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replica: 2\n  selector: {matchLabels: {app: web}}\n"),
+			},
+			{
+				Name: "templates/gadget.yaml",
+				Data: []byte("apiVersion: example.com/v1\nkind: Gadget\nmetadata:\n  name: g\nspec:\n  color: green\n---\napiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n"),
+			},
+		},
+		Files: []*common.File{
+			{
+				Name: "crds/gadget.yaml",
+				Data: []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.example.com
+spec:
+  group: example.com
+  names: {kind: Gadget, plural: gadgets}
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              color: {type: string, enum: [red, blue]}
+`),
+			},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, namespace, values, TemplateLinterOfflineValidation(true, nil))
+
+	var got []string
+	for _, msg := range linter.Messages {
+		got = append(got, fmt.Sprintf("%d %s %s", msg.Severity, msg.Path, msg.Err))
+	}
+	assert.ElementsMatch(t, []string{
+		fmt.Sprintf(`%d templates/deployment.yaml Deployment "web": unknown field "spec.replica"`, support.ErrorSev),
+		fmt.Sprintf(`%d templates/gadget.yaml Gadget "g": at '/spec/color': value must be one of 'red', 'blue'`, support.ErrorSev),
+		fmt.Sprintf(`%d templates/gadget.yaml Widget "w": no schema found for example.com/v1, Kind=Widget`, support.InfoSev),
+	}, got)
+
+	linter = support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, namespace, values)
+	assert.Empty(t, linter.Messages)
+}
+
 const manifest = `apiVersion: v1
 kind: ConfigMap
 metadata:
@@ -428,6 +497,41 @@ func TestEmptyWithCommentsManifests(t *testing.T) {
 		t.Fatalf("Expected 0 lint errors, got %d", l)
 	}
 }
+func TestDuplicateTemplateDefinitions(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "duplicates",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*common.File{
+			{Name: "templates/_a.tpl", ModTime: time.Now(), Data: []byte(`{{ define "name" }}a{{ end }}`)},
+			{Name: "templates/_b.tpl", ModTime: time.Now(), Data: []byte(`{{ define "name" }}b{{ end }}{{ define "same" }}s{{ end }}`)},
+			{Name: "templates/_c.tpl", ModTime: time.Now(), Data: []byte(`{{ define "same" }}s{{ end }}`)},
+		},
+	}
+	tmpdir := t.TempDir()
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, namespace, values)
+	if len(linter.Messages) != 2 {
+		t.Fatalf("Expected 2 lint messages, got %v", linter.Messages)
+	}
+	msg := linter.Messages[0]
+	if msg.Severity != support.WarningSev || !strings.Contains(msg.Err.Error(), `template "name" is defined 2 times`) {
+		t.Errorf("Unexpected lint message: %s", msg)
+	}
+	msg = linter.Messages[1]
+	if msg.Severity != support.InfoSev || !strings.Contains(msg.Err.Error(), `template "same" is defined 2 times with the same body`) {
+		t.Errorf("Unexpected lint message: %s", msg)
+	}
+}
+
 func TestValidateListAnnotations(t *testing.T) {
 	md := &k8sYamlStruct{
 		APIVersion: "v1",
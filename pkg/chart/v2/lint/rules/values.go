@@ -25,6 +25,7 @@ import (
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/common/util"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 )
 
 // ValuesWithOverrides tests the values.yaml file.
@@ -83,3 +84,49 @@ func validateValuesFile(valuesPath string, overrides map[string]any, skipSchemaV
 
 	return nil
 }
+
+// UnknownValues checks that values.yaml and the value overrides only set
+// top-level keys declared in values.schema.json.
+//
+// Unknown keys are reported as warnings, or as errors if strict is set.
+func UnknownValues(linter *support.Linter, valueOverrides map[string]any, strict bool) {
+	file := "values.yaml"
+	sev := support.WarningSev
+	if strict {
+		sev = support.ErrorSev
+	}
+	linter.RunLinterRule(sev, file, validateUnknownValues(linter.ChartDir, valueOverrides))
+}
+
+func validateUnknownValues(chartDir string, overrides map[string]any) error {
+	schema, err := os.ReadFile(filepath.Join(chartDir, "values.schema.json"))
+	if err != nil || len(schema) == 0 {
+		return nil
+	}
+	values, err := common.ReadValuesFile(filepath.Join(chartDir, "values.yaml"))
+	if err != nil {
+		// reported by ValuesWithOverrides
+		return nil
+	}
+	coalescedValues := util.CoalesceTables(make(map[string]any, len(overrides)), overrides)
+	coalescedValues = util.CoalesceTables(coalescedValues, values)
+
+	allowed := []string{common.GlobalKey}
+	if chartFile, err := chartutil.LoadChartfile(filepath.Join(chartDir, "Chart.yaml")); err == nil {
+		for _, dep := range chartFile.Dependencies {
+			allowed = append(allowed, dep.Name)
+			if dep.Alias != "" {
+				allowed = append(allowed, dep.Alias)
+			}
+		}
+		if len(chartFile.Dependencies) > 0 {
+			allowed = append(allowed, "tags")
+		}
+	}
+
+	keys, err := util.UnknownSchemaKeys(coalescedValues, schema, allowed...)
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+	return util.UnknownValuesError{Keys: keys}
+}
@@ -181,3 +181,18 @@ func createTestingSchema(t *testing.T, dir string) string {
 	}
 	return schemafile
 }
+
+func TestValidateUnknownValues(t *testing.T) {
+	tmpdir := ensure.TempFile(t, "values.yaml", []byte("username: admin\npassword: swordfish\nglobal: {}\n"))
+	schema := `{"type": "object", "properties": {"username": {}, "password": {}}}`
+	if err := os.WriteFile(filepath.Join(tmpdir, "values.schema.json"), []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := validateUnknownValues(tmpdir, map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := validateUnknownValues(tmpdir, map[string]any{"usrname": "root"})
+	assert.EqualError(t, err, "values not defined in the chart schema: usrname")
+}
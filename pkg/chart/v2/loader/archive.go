@@ -22,7 +22,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
+	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/loader/archive"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 )
@@ -72,3 +75,103 @@ func LoadArchive(in io.Reader) (*chart.Chart, error) {
 
 	return LoadFiles(files)
 }
+
+// LazyFileThreshold is the size above which LoadFileLazy does not read the
+// data of a chart file until it is needed.
+var LazyFileThreshold int64 = 1024 * 1024 // Default 1 MiB
+
+// LoadFileLazy loads a chart from an archive file like LoadFile, but only
+// indexes the large files of the chart, such as bundled binaries or model
+// files: their data is read from the archive when they are loaded with
+// common.File.Load.
+//
+// The chart metadata, values, templates, CRDs and subcharts are always read.
+// Other files larger than LazyFileThreshold are read on demand: when a
+// template accesses them through .Files, and when the chart is encoded or
+// saved. The archive must not change while the chart is in use.
+func LoadFileLazy(name string) (*chart.Chart, error) {
+	if fi, err := os.Stat(name); err != nil {
+		return nil, err
+	} else if fi.IsDir() {
+		return nil, errors.New("cannot load a directory")
+	}
+
+	raw, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer raw.Close()
+
+	if err := archive.EnsureArchive(name, raw); err != nil {
+		return nil, err
+	}
+
+	lazy := make(map[string]*common.File)
+	files, err := archive.LoadArchiveFilesLazy(raw, func(n string, size int64) bool {
+		if !isLazyFile(n, size) {
+			return false
+		}
+		lazy[n] = common.NewLazyFile(n, time.Time{}, size, openArchiveFile(name, n))
+		return true
+	})
+	if err != nil {
+		if errors.Is(err, gzip.ErrHeader) {
+			return nil, fmt.Errorf("file '%s' does not appear to be a valid chart file (details: %w)", name, err)
+		}
+		return nil, err
+	}
+	for _, f := range files {
+		if l, ok := lazy[f.Name]; ok {
+			l.ModTime = f.ModTime
+		}
+	}
+
+	c, err := LoadFiles(files)
+	if err != nil {
+		return c, err
+	}
+	for _, list := range [][]*common.File{c.Files, c.Raw} {
+		for i, f := range list {
+			if l, ok := lazy[f.Name]; ok {
+				list[i] = l
+			}
+		}
+	}
+	return c, nil
+}
+
+// isLazyFile reports whether a file of the given size is read on demand by
+// LoadFileLazy.
+func isLazyFile(name string, size int64) bool {
+	if size <= LazyFileThreshold {
+		return false
+	}
+	switch name {
+	case "Chart.yaml", "Chart.lock", "values.yaml", "values.schema.json", "requirements.yaml", "requirements.lock":
+		return false
+	}
+	for _, dir := range []string{"templates/", "charts/", "crds/"} {
+		if strings.HasPrefix(name, dir) {
+			return false
+		}
+	}
+	return true
+}
+
+func openArchiveFile(archivePath, name string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		r, err := archive.OpenArchiveFile(f, name)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{r, f}, nil
+	}
+}
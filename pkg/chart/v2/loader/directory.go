@@ -31,6 +31,41 @@ import (
 
 var utf8bom = []byte{0xEF, 0xBB, 0xBF}
 
+// originFileName mirrors util.OriginFileName, which can't be imported here.
+const originFileName = ".helm-origin.yaml"
+
+// SymlinkPolicy controls which symbolic links the directory loader follows.
+type SymlinkPolicy = sympath.Policy
+
+const (
+	// SymlinkFollowAll follows every symbolic link. This is the default.
+	SymlinkFollowAll = sympath.FollowAll
+	// SymlinkFollowWithinChart follows symbolic links that resolve to a path
+	// inside the chart directory and rejects the others.
+	SymlinkFollowWithinChart = sympath.FollowWithinRoot
+	// SymlinkDeny rejects charts that contain symbolic links.
+	SymlinkDeny = sympath.Deny
+)
+
+// SymlinkError is returned when a chart contains a symbolic link that the
+// symlink policy does not allow. It identifies the link and its target.
+type SymlinkError = sympath.LinkError
+
+// Options configures how a chart is loaded from a directory.
+type Options struct {
+	// SymlinkPolicy controls which symbolic links are followed.
+	SymlinkPolicy SymlinkPolicy
+	// OnIgnored, if set, is called for each file and directory left out by
+	// the .helmignore files, with the rule that excluded it. Directory names
+	// end with a slash.
+	OnIgnored func(name, rule string)
+	// Exclude and Include are patterns, in the .helmignore format, of files
+	// to leave out of the chart and of files to keep in it. They take
+	// precedence over the .helmignore files, and Include over Exclude.
+	Exclude []string
+	Include []string
+}
+
 // DirLoader loads a chart from a directory
 type DirLoader string
 
@@ -41,8 +76,20 @@ func (l DirLoader) Load() (*chart.Chart, error) {
 
 // LoadDir loads from a directory.
 //
-// This loads charts only from directories.
+// This loads charts only from directories. Charts larger than the loader
+// limits of the archive package, except archive.MaxDecompressedChartSize which
+// only applies to archives, are rejected with an error matching
+// archive.ErrLimitExceeded.
 func LoadDir(dir string) (*chart.Chart, error) {
+	return LoadDirWithOptions(dir, Options{})
+}
+
+// LoadDirWithOptions loads a chart from a directory like LoadDir, following
+// only the symbolic links allowed by opts.SymlinkPolicy.
+//
+// The .helmignore files of the chart directory and its subdirectories decide
+// which files are left out of the chart.
+func LoadDirWithOptions(dir string, opts Options) (*chart.Chart, error) {
 	topdir, err := filepath.Abs(dir)
 	if err != nil {
 		return nil, err
@@ -62,9 +109,34 @@ func LoadDir(dir string) (*chart.Chart, error) {
 	}
 	rules.AddDefaults()
 
+	overrides := ignore.Empty()
+	if err := overrides.AddPatterns("exclude", opts.Exclude...); err != nil {
+		return c, err
+	}
+	for _, p := range opts.Include {
+		if err := overrides.AddPatterns("include", "!"+p); err != nil {
+			return c, err
+		}
+	}
+
 	files := []*archive.BufferedFile{}
+	budget := archive.NewDirectoryBudget()
 	topdir += string(filepath.Separator)
 
+	ignored := func(n string, fi os.FileInfo) bool {
+		rule, ok := overrides.IgnoredBy(n, fi)
+		if rule == "" {
+			rule, ok = rules.IgnoredBy(n, fi)
+		}
+		if ok && opts.OnIgnored != nil {
+			if fi.IsDir() {
+				n += "/"
+			}
+			opts.OnIgnored(n, rule)
+		}
+		return ok
+	}
+
 	walk := func(name string, fi os.FileInfo, err error) error {
 		n := strings.TrimPrefix(name, topdir)
 		if n == "" {
@@ -82,14 +154,28 @@ func LoadDir(dir string) (*chart.Chart, error) {
 		if fi.IsDir() {
 			// Directory-based ignore rules should involve skipping the entire
 			// contents of that directory.
-			if rules.Ignore(n, fi) {
+			if ignored(n, fi) {
 				return filepath.SkipDir
 			}
+			// The rules of a nested .helmignore file apply to the contents
+			// of its directory.
+			ifile := filepath.Join(name, ignore.HelmIgnore)
+			if _, err := os.Stat(ifile); err == nil {
+				if err := rules.AddFile(n, ifile); err != nil {
+					return fmt.Errorf("error reading %s/%s: %w", n, ignore.HelmIgnore, err)
+				}
+			}
 			return nil
 		}
 
 		// If a .helmignore file matches, skip this file.
-		if rules.Ignore(n, fi) {
+		if ignored(n, fi) {
+			return nil
+		}
+
+		// The origin file written by 'helm pull --record-origin' describes
+		// the chart and is not part of it.
+		if n == originFileName {
 			return nil
 		}
 
@@ -100,8 +186,8 @@ func LoadDir(dir string) (*chart.Chart, error) {
 			return fmt.Errorf("cannot load irregular file %s as it has file mode type bits set", name)
 		}
 
-		if fi.Size() > archive.MaxDecompressedFileSize {
-			return fmt.Errorf("chart file %q is larger than the maximum file size %d", fi.Name(), archive.MaxDecompressedFileSize)
+		if err := budget.Add(n, fi.Size()); err != nil {
+			return err
 		}
 
 		data, err := os.ReadFile(name)
@@ -114,7 +200,7 @@ func LoadDir(dir string) (*chart.Chart, error) {
 		files = append(files, &archive.BufferedFile{Name: n, ModTime: fi.ModTime(), Data: data})
 		return nil
 	}
-	if err = sympath.Walk(topdir, walk); err != nil {
+	if err = sympath.WalkWithPolicy(topdir, opts.SymlinkPolicy, walk); err != nil {
 		return c, err
 	}
 
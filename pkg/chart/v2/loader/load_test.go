@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"io"
 	"log"
@@ -51,6 +52,79 @@ func TestLoadDir(t *testing.T) {
 	verifyDependenciesLock(t, c)
 }
 
+func TestLoadDirLimits(t *testing.T) {
+	maxFileCount := archive.MaxFileCount
+	archive.MaxFileCount = 3
+	defer func() { archive.MaxFileCount = maxFileCount }()
+
+	_, err := LoadDir("testdata/frobnitz")
+	if !errors.Is(err, archive.ErrLimitExceeded) {
+		t.Fatalf("expected the file count limit to be exceeded, got %v", err)
+	}
+	if err.Error() != "chart has more than the maximum of 3 files" {
+		t.Errorf("unexpected error %q", err)
+	}
+}
+
+func TestLoadDirHelmignore(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"Chart.yaml":          "apiVersion: v2\nname: ignored\nversion: 0.1.0\n",
+		".helmignore":         "*.md\n!README.md\nsecrets/\n",
+		"README.md":           "readme",
+		"NOTES.md":            "notes",
+		"secrets/key.pem":     "key",
+		"docs/.helmignore":    "*\n!guide.md\n!.helmignore\n",
+		"docs/guide.md":       "guide",
+		"docs/draft.txt":      "draft",
+		"templates/.hidden":   "hidden",
+		"templates/cm.yaml":   "kind: ConfigMap",
+		"templates/README.md": "readme",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ignored := map[string]string{}
+	c, err := LoadDirWithOptions(dir, Options{OnIgnored: func(name, rule string) { ignored[name] = rule }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range c.Files {
+		names = append(names, f.Name)
+	}
+	expectFiles := []string{".helmignore", "README.md", "docs/.helmignore", "docs/guide.md"}
+	if !reflect.DeepEqual(names, expectFiles) {
+		t.Errorf("expected files %v, got %v", expectFiles, names)
+	}
+	expectIgnored := map[string]string{
+		"NOTES.md":          ".helmignore:1: *.md",
+		"secrets/":          ".helmignore:3: secrets/",
+		"docs/draft.txt":    "docs/.helmignore:1: *",
+		"templates/.hidden": "templates/.?*",
+	}
+	if !reflect.DeepEqual(ignored, expectIgnored) {
+		t.Errorf("expected ignored files %v, got %v", expectIgnored, ignored)
+	}
+}
+
+func TestLoadDirIgnoresChartSizeLimit(t *testing.T) {
+	maxChartSize := archive.MaxDecompressedChartSize
+	archive.MaxDecompressedChartSize = 1
+	defer func() { archive.MaxDecompressedChartSize = maxChartSize }()
+
+	if _, err := LoadDir("testdata/albatross"); err != nil {
+		t.Fatalf("expected the chart size limit to apply to archives only, got %v", err)
+	}
+}
+
 func TestLoadDirWithDevNull(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("test only works on unix systems with /dev/null present")
@@ -90,6 +164,34 @@ func TestLoadDirWithSymlink(t *testing.T) {
 	verifyDependenciesLock(t, c)
 }
 
+func TestLoadDirSymlinkPolicy(t *testing.T) {
+	sym := filepath.Join("..", "LICENSE")
+	link := filepath.Join("testdata", "frobnitz_with_symlink", "LICENSE")
+
+	if err := os.Symlink(sym, link); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove(link)
+
+	for _, policy := range []SymlinkPolicy{SymlinkDeny, SymlinkFollowWithinChart} {
+		_, err := LoadDirWithOptions("testdata/frobnitz_with_symlink", Options{SymlinkPolicy: policy})
+		var linkErr *SymlinkError
+		if !errors.As(err, &linkErr) {
+			t.Fatalf("%s: expected a symlink error, got %v", policy, err)
+		}
+		if linkErr.Path != "LICENSE" {
+			t.Errorf("%s: expected the LICENSE link to be reported, got %q", policy, linkErr.Path)
+		}
+	}
+
+	c, err := LoadDirWithOptions("testdata/frobnitz_with_symlink", Options{SymlinkPolicy: SymlinkFollowAll})
+	if err != nil {
+		t.Fatalf("Failed to load testdata: %s", err)
+	}
+	verifyFrobnitz(t, c)
+}
+
 func TestBomTestData(t *testing.T) {
 	testFiles := []string{"frobnitz_with_bom/.helmignore", "frobnitz_with_bom/templates/template.tpl", "frobnitz_with_bom/Chart.yaml"}
 	for _, file := range testFiles {
@@ -210,6 +312,63 @@ func TestLoadFile(t *testing.T) {
 	verifyDependencies(t, c)
 }
 
+func TestLoadFileLazy(t *testing.T) {
+	defer func(threshold int64) { LazyFileThreshold = threshold }(LazyFileThreshold)
+	LazyFileThreshold = 0
+
+	c, err := LoadFileLazy("testdata/frobnitz-1.2.3.tgz")
+	if err != nil {
+		t.Fatalf("Failed to load testdata: %s", err)
+	}
+	verifyFrobnitz(t, c)
+	verifyDependencies(t, c)
+	for _, f := range c.Templates {
+		if !f.IsLoaded() {
+			t.Errorf("expected template %s to be loaded", f.Name)
+		}
+	}
+
+	var license *common.File
+	for _, f := range c.Files {
+		if f.IsLoaded() {
+			t.Errorf("expected %s to be loaded lazily", f.Name)
+		}
+		if f.Name == "LICENSE" {
+			license = f
+		}
+	}
+	if license == nil {
+		t.Fatal("expected the LICENSE file")
+	}
+	if license.ModTime.IsZero() {
+		t.Error("expected the LICENSE file to have a modification time")
+	}
+	size := license.Size()
+	if err := license.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !license.IsLoaded() || !strings.HasPrefix(string(license.Data), "LICENSE placeholder.") {
+		t.Errorf("expected the data of the LICENSE file, got %q", license.Data)
+	}
+	if size != int64(len(license.Data)) {
+		t.Errorf("expected the size of the LICENSE file to be %d before loading it, got %d", len(license.Data), size)
+	}
+
+	data, err := json.Marshal(c.Files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []*common.File
+	if err := json.Unmarshal(data, &files); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if len(f.Data) == 0 && f.Name != ".helmignore" {
+			t.Errorf("expected the data of %s to be encoded", f.Name)
+		}
+	}
+}
+
 func TestLoadFiles_BadCases(t *testing.T) {
 	for _, tt := range []struct {
 		name          string
@@ -66,6 +66,21 @@ func TestValidate(t *testing.T) {
 			&Metadata{Name: "test", APIVersion: "v2", Version: "1.0", Type: "test"},
 			ValidationError("chart.metadata.type must be application or library"),
 		},
+		{
+			"chart with custom template delimiters",
+			&Metadata{Name: "test", APIVersion: "v2", Version: "1.0", TemplateDelimiters: []string{"[[", "]]"}},
+			nil,
+		},
+		{
+			"chart with a single template delimiter",
+			&Metadata{Name: "test", APIVersion: "v2", Version: "1.0", TemplateDelimiters: []string{"[["}},
+			ValidationError("chart.metadata.templateDelimiters must be a left and a right delimiter, without spaces"),
+		},
+		{
+			"chart with an empty template delimiter",
+			&Metadata{Name: "test", APIVersion: "v2", Version: "1.0", TemplateDelimiters: []string{"[[", " "}},
+			ValidationError("chart.metadata.templateDelimiters must be a left and a right delimiter, without spaces"),
+		},
 		{
 			"chart without dependency",
 			&Metadata{Name: "test", APIVersion: "v2", Version: "1.0", Type: "application"},
@@ -97,6 +112,19 @@ func TestValidate(t *testing.T) {
 			},
 			ValidationError("dependency \"bad\" has disallowed characters in the alias"),
 		},
+		{
+			"dependency with bad characters in template alias",
+			&Metadata{
+				Name:       "test",
+				APIVersion: "v2",
+				Version:    "1.0",
+				Type:       "application",
+				Dependencies: []*Dependency{
+					{Name: "bad", TemplateAlias: "illegal.alias"},
+				},
+			},
+			ValidationError("dependency \"bad\" has disallowed characters in the template alias"),
+		},
 		{
 			"same dependency twice",
 			&Metadata{
@@ -15,13 +15,16 @@ limitations under the License.
 package util
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 )
@@ -567,3 +570,154 @@ func TestChartWithDependencyAliasedTwiceAndDoublyReferencedSubDependency(t *test
 	}
 	validateDependencyTree(t, c)
 }
+
+func TestValidateGlobalValues(t *testing.T) {
+	newChart := func(name, schema string, deps ...*chart.Chart) *chart.Chart {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: "0.1.0"},
+			Values:   map[string]any{},
+			Schema:   []byte(schema),
+		}
+		c.SetDependencies(deps...)
+		return c
+	}
+	db := newChart("db", `{"properties": {"global": {"properties": {"storageClass": {"type": "string"}}}}}`)
+	cache := newChart("cache", `{
+		"properties": {"global": {"$ref": "#/definitions/global"}},
+		"definitions": {"global": {"properties": {"replicas": {"type": "integer", "minimum": 1}}}}
+	}`)
+	web := newChart("web", `{"required": ["image"], "properties": {"image": {"type": "string"}}}`, cache)
+	umbrella := newChart("umbrella", "", db, web)
+
+	tests := []struct {
+		name   string
+		values common.Values
+		errors []string
+	}{
+		{name: "no globals", values: common.Values{}},
+		{name: "valid globals", values: common.Values{"global": map[string]any{"storageClass": "fast", "replicas": 2}}},
+		{
+			name:   "rejected by a subchart",
+			values: common.Values{"global": map[string]any{"storageClass": 1}},
+			errors: []string{"db:", "/global/storageClass"},
+		},
+		{
+			name:   "rejected by a nested subchart",
+			values: common.Values{"global": map[string]any{"replicas": 0}},
+			errors: []string{"web/cache:", "/global/replicas"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGlobalValues(umbrella, tt.values)
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, e := range tt.errors {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected %q in the error, got %s", e, err)
+				}
+			}
+		})
+	}
+
+	if err := ProcessDependencies(umbrella, common.Values{"global": map[string]any{"storageClass": 1}}); err == nil {
+		t.Error("expected ProcessDependencies to validate the global values")
+	}
+	if err := ProcessDependenciesWithoutValidation(umbrella, common.Values{"global": map[string]any{"storageClass": 1}}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestProcessDependencyExportValues(t *testing.T) {
+	newChart := func() *chart.Chart {
+		db := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "db", Version: "0.1.0"},
+			Values: map[string]any{
+				"primary": map[string]any{"host": "localhost", "port": 5432},
+				"auth":    map[string]any{"username": "postgres"},
+			},
+		}
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "app",
+				Version:    "0.1.0",
+				Dependencies: []*chart.Dependency{{
+					Name:    "db",
+					Version: "0.1.0",
+					Alias:   "database",
+					ExportValues: []chart.ExportValue{
+						{Parent: "storage.host", Child: "primary.host"},
+						{Parent: "storage.credentials", Child: "auth"},
+						{Parent: "storage.missing", Child: "primary.missing"},
+					},
+				}},
+			},
+			Values: map[string]any{
+				"storage": map[string]any{
+					"host":        "db.example.com",
+					"credentials": map[string]any{"username": "app", "database": "app"},
+				},
+			},
+		}
+		c.SetDependencies(db)
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		values common.Values
+		expect map[string]string
+	}{
+		{
+			name: "parent defaults",
+			expect: map[string]string{
+				"database.primary.host":  "db.example.com",
+				"database.primary.port":  "5432",
+				"database.auth.username": "app",
+				"database.auth.database": "app",
+			},
+		},
+		{
+			name:   "user supplied parent values",
+			values: common.Values{"storage": map[string]any{"host": "db.internal"}},
+			expect: map[string]string{"database.primary.host": "db.internal"},
+		},
+		{
+			name:   "user supplied child values win",
+			values: common.Values{"storage": map[string]any{"host": "db.internal"}, "database": map[string]any{"primary": map[string]any{"host": "override"}}},
+			expect: map[string]string{"database.primary.host": "override"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newChart()
+			if err := ProcessDependencies(c, tt.values); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			vals, err := util.CoalesceValues(c, tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range tt.expect {
+				got, err := vals.PathValue(path)
+				if err != nil {
+					t.Fatalf("%s: %s", path, err)
+				}
+				if s := fmt.Sprint(got); s != want {
+					t.Errorf("%s: expected %q, got %q", path, want, s)
+				}
+			}
+			if _, err := vals.PathValue("database.primary.missing"); err == nil {
+				t.Error("expected missing parent values not to be exported")
+			}
+		})
+	}
+}
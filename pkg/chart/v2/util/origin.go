@@ -0,0 +1,178 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/loader/archive"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// OriginFileName is the name of the file recording where an unpacked chart
+// came from. It is ignored when loading and packaging charts.
+const OriginFileName = ".helm-origin.yaml"
+
+// Origin records the source of an unpacked chart archive and the digests of
+// its files, so that the directory can later be verified or repackaged.
+type Origin struct {
+	// Source is the repository URL or OCI reference the chart was pulled from.
+	Source string `json:"source"`
+	// Name is the name of the chart.
+	Name string `json:"name"`
+	// Version is the version of the chart.
+	Version string `json:"version"`
+	// Digest is the digest of the chart archive, in the form "sha256:<hex>".
+	Digest string `json:"digest"`
+	// Archive is the file name of the chart archive.
+	Archive string `json:"archive,omitempty"`
+	// Pulled is the time the chart was pulled.
+	Pulled time.Time `json:"pulled"`
+	// Files maps the path of every file in the chart to its digest.
+	Files map[string]string `json:"files"`
+}
+
+// NewOriginFromArchive creates an Origin for the chart archive at path.
+func NewOriginFromArchive(path, source string) (*Origin, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := archive.LoadArchiveFiles(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Origin{
+		Source:  source,
+		Digest:  digestOf(data),
+		Archive: filepath.Base(path),
+		Pulled:  time.Now().UTC(),
+		Files:   make(map[string]string, len(files)),
+	}
+	for _, f := range files {
+		o.Files[f.Name] = digestOf(f.Data)
+		if f.Name == ChartfileName {
+			md := &chart.Metadata{}
+			if err := yaml.Unmarshal(f.Data, md); err != nil {
+				return nil, fmt.Errorf("cannot load Chart.yaml: %w", err)
+			}
+			o.Name, o.Version = md.Name, md.Version
+		}
+	}
+	return o, nil
+}
+
+// WriteOrigin writes the origin file into the chart directory.
+func WriteOrigin(chartDir string, o *Origin) error {
+	b, err := yaml.Marshal(o)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(chartDir, OriginFileName), b, 0644)
+}
+
+// LoadOrigin reads the origin file of the chart directory.
+func LoadOrigin(chartDir string) (*Origin, error) {
+	b, err := os.ReadFile(filepath.Join(chartDir, OriginFileName))
+	if err != nil {
+		return nil, err
+	}
+	o := &Origin{}
+	if err := yaml.Unmarshal(b, o); err != nil {
+		return nil, fmt.Errorf("cannot load %s: %w", OriginFileName, err)
+	}
+	return o, nil
+}
+
+// VerifyDir checks that the files of the chart directory match the digests
+// recorded in the origin. The error lists every modified, missing or added
+// file.
+func (o *Origin) VerifyDir(chartDir string) error {
+	seen := map[string]bool{}
+	var problems []string
+	err := filepath.WalkDir(chartDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(chartDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == OriginFileName {
+			return nil
+		}
+		seen[rel] = true
+		expected, ok := o.Files[rel]
+		if !ok {
+			problems = append(problems, "added: "+rel)
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if digestOf(data) != expected {
+			problems = append(problems, "modified: "+rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for name := range o.Files {
+		if !seen[name] {
+			problems = append(problems, "missing: "+name)
+		}
+	}
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return errors.New("chart files do not match " + OriginFileName + ":\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// FileDigest returns the digest of the file at path in the form used by
+// Origin.
+func FileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return digestOf(data), nil
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
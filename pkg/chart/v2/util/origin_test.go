@@ -0,0 +1,66 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrigin(t *testing.T) {
+	archive := "testdata/frobnitz-1.2.3.tgz"
+	o, err := NewOriginFromArchive(archive, "https://example.com/frobnitz-1.2.3.tgz")
+	require.NoError(t, err)
+
+	assert.Equal(t, "frobnitz", o.Name)
+	assert.Equal(t, "1.2.3", o.Version)
+	assert.Equal(t, "frobnitz-1.2.3.tgz", o.Archive)
+	digest, err := FileDigest(archive)
+	require.NoError(t, err)
+	assert.Equal(t, digest, o.Digest)
+	assert.Contains(t, o.Files, ChartfileName)
+
+	dest := t.TempDir()
+	require.NoError(t, ExpandFile(dest, archive))
+	chartDir := filepath.Join(dest, o.Name)
+	require.NoError(t, WriteOrigin(chartDir, o))
+
+	loaded, err := LoadOrigin(chartDir)
+	require.NoError(t, err)
+	assert.Equal(t, o.Source, loaded.Source)
+	assert.Equal(t, o.Files, loaded.Files)
+	require.NoError(t, loaded.VerifyDir(chartDir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte("changed: true\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "extra.txt"), []byte("extra\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(chartDir, "INSTALL.txt")))
+
+	err = loaded.VerifyDir(chartDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "added: extra.txt")
+	assert.Contains(t, err.Error(), "missing: INSTALL.txt")
+	assert.Contains(t, err.Error(), "modified: values.yaml")
+}
+
+func TestLoadOriginMissing(t *testing.T) {
+	_, err := LoadOrigin(t.TempDir())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
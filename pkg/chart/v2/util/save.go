@@ -31,6 +31,7 @@ import (
 
 	"helm.sh/helm/v4/pkg/chart/common"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/ignore"
 )
 
 var headerBytes = []byte("+aHR0cHM6Ly95b3V0dS5iZS96OVV6MWljandyTQo=")
@@ -79,6 +80,9 @@ func SaveDir(c *chart.Chart, dest string) error {
 	// Save templates and files
 	for _, o := range [][]*common.File{c.Templates, c.Files} {
 		for _, f := range o {
+			if err := f.Load(); err != nil {
+				return err
+			}
 			n := filepath.Join(outdir, f.Name)
 			if err := writeFile(n, f.Data); err != nil {
 				return err
@@ -154,6 +158,60 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 	return filename, nil
 }
 
+// SaveOptions configures which files of a chart SaveWithOptions archives.
+type SaveOptions struct {
+	// Exclude and Include are patterns, in the .helmignore format, of files
+	// to leave out of the archive and of files to keep in it. Include takes
+	// precedence over Exclude. Paths are relative to the chart, and the files
+	// of a dependency are matched as charts/<name>/<path>.
+	Exclude []string
+	Include []string
+}
+
+// SaveWithOptions creates an archived chart to the given directory like Save,
+// leaving out the files of the chart and its dependencies excluded by opts.
+// The chart itself is not modified.
+func SaveWithOptions(c *chart.Chart, outDir string, opts SaveOptions) (string, error) {
+	rules := ignore.Empty()
+	if err := rules.AddPatterns("exclude", opts.Exclude...); err != nil {
+		return "", err
+	}
+	for _, p := range opts.Include {
+		if err := rules.AddPatterns("include", "!"+p); err != nil {
+			return "", err
+		}
+	}
+	return Save(filterChart(c, rules, ""), outDir)
+}
+
+// filterChart returns a copy of c without the files matched by rules. The
+// names of the files are prefixed with prefix before matching.
+func filterChart(c *chart.Chart, rules *ignore.Rules, prefix string) *chart.Chart {
+	keep := func(files []*common.File) []*common.File {
+		var kept []*common.File
+		for _, f := range files {
+			if _, ignored := rules.IgnoredPath(prefix + f.Name); !ignored {
+				kept = append(kept, f)
+			}
+		}
+		return kept
+	}
+
+	filtered := *c
+	filtered.Templates = keep(c.Templates)
+	filtered.Files = keep(c.Files)
+	var deps []*chart.Chart
+	for _, dep := range c.Dependencies() {
+		depPrefix := prefix + ChartsDir + "/" + dep.Name() + "/"
+		if _, ignored := rules.IgnoredPath(depPrefix); ignored {
+			continue
+		}
+		deps = append(deps, filterChart(dep, rules, depPrefix))
+	}
+	filtered.SetDependencies(deps...)
+	return &filtered
+}
+
 func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	err := validateName(c.Name())
 	if err != nil {
@@ -222,6 +280,9 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 
 	// Save files
 	for _, f := range c.Files {
+		if err := f.Load(); err != nil {
+			return err
+		}
 		n := filepath.Join(base, f.Name)
 		if err := writeToTar(out, n, f.Data, f.ModTime); err != nil {
 			return err
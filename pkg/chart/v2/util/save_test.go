@@ -27,6 +27,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -130,6 +131,68 @@ func TestSave(t *testing.T) {
 	}
 }
 
+func TestSaveWithOptions(t *testing.T) {
+	newChart := func(name string) *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: "0.1.0"},
+			Templates: []*common.File{
+				{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment")},
+				{Name: "templates/tests/test-connection.yaml", Data: []byte("kind: Pod")},
+			},
+			Files: []*common.File{
+				{Name: "README.md", Data: []byte("readme")},
+				{Name: "docs/guide.md", Data: []byte("guide")},
+				{Name: "docs/index.md", Data: []byte("index")},
+			},
+		}
+	}
+	c := newChart("moby")
+	c.AddDependency(newChart("starbuck"), newChart("queequeg"))
+
+	where, err := SaveWithOptions(c, t.TempDir(), SaveOptions{
+		Exclude: []string{"tests/", "docs/*", "charts/queequeg/"},
+		Include: []string{"docs/index.md"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+	c2, err := loader.LoadFile(where)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(c *chart.Chart) []string {
+		var names []string
+		for _, f := range append(append([]*common.File{}, c.Templates...), c.Files...) {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+	expect := []string{"templates/deployment.yaml", "README.md", "docs/index.md"}
+	if got := names(c2); !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected files %v, got %v", expect, got)
+	}
+	if len(c2.Dependencies()) != 1 || c2.Dependencies()[0].Name() != "starbuck" {
+		t.Fatalf("Expected only the starbuck dependency, got %v", c2.Dependencies())
+	}
+	// Patterns with a slash match paths relative to the top level chart.
+	expect = []string{"templates/deployment.yaml", "README.md", "docs/guide.md", "docs/index.md"}
+	if got := names(c2.Dependencies()[0]); !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected dependency files %v, got %v", expect, got)
+	}
+
+	if len(c.Templates) != 2 || len(c.Files) != 3 || len(c.Dependencies()) != 2 {
+		t.Error("Expected the saved chart not to be modified")
+	}
+	if c.Dependencies()[0].Parent() != c {
+		t.Error("Expected the dependencies of the saved chart to keep their parent")
+	}
+
+	if _, err := SaveWithOptions(c, t.TempDir(), SaveOptions{Exclude: []string{"docs/**"}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
 // Creates a copy with a different schema; does not modify anything.
 func withSchema(chart chart.Chart, schema []byte) chart.Chart {
 	chart.Schema = schema
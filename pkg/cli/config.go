@@ -0,0 +1,263 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFileName is the name of the optional Helm config file, in the Helm
+// config home.
+const ConfigFileName = "config.yaml"
+
+// Config is the optional Helm config file. It sets the default values of
+// flags, by flag name, for every kube-context and for given kube-contexts:
+//
+//	flags:
+//	  rollback-on-failure: true
+//	  timeout: 10m
+//	contexts:
+//	  production:
+//	    flags:
+//	      timeout: 20m
+//
+// Flags that a command does not have are ignored, so that a single file can
+// hold the defaults of every command.
+type Config struct {
+	// Flags are the default values of flags, by flag name.
+	Flags FlagValues `json:"flags,omitempty"`
+	// Contexts override the default values of flags for kube-contexts, by
+	// context name.
+	Contexts map[string]ContextConfig `json:"contexts,omitempty"`
+}
+
+// ContextConfig is the part of the config file that applies to a
+// kube-context.
+type ContextConfig struct {
+	// Flags are the default values of flags, by flag name.
+	Flags FlagValues `json:"flags,omitempty"`
+}
+
+// Flag returns the default value of a flag for a kube-context.
+func (c *Config) Flag(context, name string) (string, bool) {
+	if context != "" {
+		if v, ok := c.Contexts[context].Flags[name]; ok {
+			return v, true
+		}
+	}
+	v, ok := c.Flags[name]
+	return v, ok
+}
+
+// FlagValues are values of flags, by flag name. In the config file, a value
+// is a string, a number, a boolean, or a list of these, which is joined by
+// commas.
+type FlagValues map[string]string
+
+// UnmarshalJSON decodes flag values.
+func (v *FlagValues) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	values := FlagValues{}
+	for name, r := range raw {
+		value, err := flagValue(r)
+		if err != nil {
+			return fmt.Errorf("flag %q: %w", name, err)
+		}
+		values[name] = value
+	}
+	*v = values
+	return nil
+}
+
+func flagValue(data json.RawMessage) (string, error) {
+	var value any
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&value); err != nil {
+		return "", err
+	}
+	if s, ok := scalarFlagValue(value); ok {
+		return s, nil
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return "", errors.New("the value must be a string, a number, a boolean or a list of these")
+	}
+	var values []string
+	for _, item := range list {
+		s, ok := scalarFlagValue(item)
+		if !ok {
+			return "", errors.New("a list may only hold strings, numbers and booleans")
+		}
+		values = append(values, s)
+	}
+	return strings.Join(values, ","), nil
+}
+
+func scalarFlagValue(value any) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case json.Number:
+		return value.String(), true
+	default:
+		return "", false
+	}
+}
+
+// LoadConfig loads a Helm config file. A missing file is an empty config.
+func LoadConfig(path string) (*Config, error) {
+	c := &Config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Source is where the value of a flag comes from.
+type Source string
+
+const (
+	// SourceFlag is a value set on the command line.
+	SourceFlag Source = "flag"
+	// SourceEnv is a value set by the environment variable of the flag.
+	SourceEnv Source = "env"
+	// SourceConfig is a value set by the config file.
+	SourceConfig Source = "config"
+	// SourceDefault is the default value of the flag.
+	SourceDefault Source = "default"
+)
+
+// FlagEnvVars maps the flags whose default value is read from an environment
+// variable to that variable.
+var FlagEnvVars = map[string]string{
+	"namespace":                     "HELM_NAMESPACE",
+	"kubeconfig":                    "KUBECONFIG",
+	"kube-context":                  "HELM_KUBECONTEXT",
+	"kube-token":                    "HELM_KUBETOKEN",
+	"kube-as-user":                  "HELM_KUBEASUSER",
+	"kube-as-group":                 "HELM_KUBEASGROUPS",
+	"kube-apiserver":                "HELM_KUBEAPISERVER",
+	"kube-ca-file":                  "HELM_KUBECAFILE",
+	"kube-tls-server-name":          "HELM_KUBETLS_SERVER_NAME",
+	"kube-insecure-skip-tls-verify": "HELM_KUBEINSECURE_SKIP_TLS_VERIFY",
+	"debug":                         "HELM_DEBUG",
+	"registry-config":               "HELM_REGISTRY_CONFIG",
+	"repository-config":             "HELM_REPOSITORY_CONFIG",
+	"repository-cache":              "HELM_REPOSITORY_CACHE",
+	"content-cache":                 "HELM_CONTENT_CACHE",
+	"offline":                       "HELM_OFFLINE",
+	"burst-limit":                   "HELM_BURST_LIMIT",
+	"qps":                           "HELM_QPS",
+	"color":                         "HELM_COLOR",
+	"colour":                        "HELM_COLOR",
+	"error-format":                  "HELM_ERROR_FORMAT",
+	"history-max":                   "HELM_MAX_HISTORY",
+}
+
+// Resolver resolves the values of flags. In order of precedence, a value is
+// taken from the command line, from the environment variable of the flag,
+// from the config file, and from the default value of the flag.
+type Resolver struct {
+	// Config is the config file. It may be nil.
+	Config *Config
+	// Context is the kube-context in effect. The default values of flags for
+	// it in the config file take precedence over the other default values of
+	// the config file.
+	Context string
+	// EnvVars maps flags to their environment variable. It defaults to
+	// FlagEnvVars.
+	EnvVars map[string]string
+	// LookupEnv looks up environment variables. It defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+}
+
+// Resolve returns the value of a flag and where it comes from.
+func (r *Resolver) Resolve(f *pflag.Flag) (string, Source) {
+	if f.Changed {
+		return f.Value.String(), SourceFlag
+	}
+	if name, ok := r.envVars()[f.Name]; ok {
+		if v, ok := r.lookupEnv(name); ok {
+			return v, SourceEnv
+		}
+	}
+	if r.Config != nil {
+		if v, ok := r.Config.Flag(r.Context, f.Name); ok {
+			return v, SourceConfig
+		}
+	}
+	return f.DefValue, SourceDefault
+}
+
+// Apply sets the flags of a flag set whose value comes from the config file,
+// as if they were set on the command line. It returns the names of the flags
+// it set.
+func (r *Resolver) Apply(fs *pflag.FlagSet) ([]string, error) {
+	var set []string
+	var errs []error
+	fs.VisitAll(func(f *pflag.Flag) {
+		v, source := r.Resolve(f)
+		if source != SourceConfig {
+			return
+		}
+		if err := fs.Set(f.Name, v); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q of flag %q in the config file: %w", v, f.Name, err))
+			return
+		}
+		set = append(set, f.Name)
+	})
+	sort.Strings(set)
+	return set, errors.Join(errs...)
+}
+
+func (r *Resolver) envVars() map[string]string {
+	if r.EnvVars == nil {
+		return FlagEnvVars
+	}
+	return r.EnvVars
+}
+
+func (r *Resolver) lookupEnv(key string) (string, bool) {
+	if r.LookupEnv == nil {
+		return os.LookupEnv(key)
+	}
+	return r.LookupEnv(key)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := LoadConfig(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, c.Flags)
+
+	path := filepath.Join(dir, ConfigFileName)
+	require.NoError(t, os.WriteFile(path, []byte("flags:\n  timeout: 10m\n  wait: watcher\n"), 0o644))
+	c, err = LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, FlagValues{"timeout": "10m", "wait": "watcher"}, c.Flags)
+
+	require.NoError(t, os.WriteFile(path, []byte(`flags:
+  rollback-on-failure: true
+  history-max: 20
+  set: [a=1, b=2]
+contexts:
+  production:
+    flags:
+      timeout: 20m
+`), 0o644))
+	c, err = LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, FlagValues{"rollback-on-failure": "true", "history-max": "20", "set": "a=1,b=2"}, c.Flags)
+	assert.Equal(t, FlagValues{"timeout": "20m"}, c.Contexts["production"].Flags)
+
+	require.NoError(t, os.WriteFile(path, []byte("flags:\n  set:\n    a: 1\n"), 0o644))
+	_, err = LoadConfig(path)
+	assert.ErrorContains(t, err, `flag "set"`)
+
+	require.NoError(t, os.WriteFile(path, []byte("defaults:\n  timeout: 10m\n"), 0o644))
+	_, err = LoadConfig(path)
+	assert.ErrorContains(t, err, "invalid config file")
+}
+
+func TestResolver(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		config  FlagValues
+		context string
+		timeout time.Duration
+		ns      string
+		sources map[string]Source
+		applied []string
+		wantErr string
+	}{
+		{
+			name:    "defaults",
+			timeout: 5 * time.Minute,
+			sources: map[string]Source{"timeout": SourceDefault, "namespace": SourceDefault},
+		},
+		{
+			name:    "config file",
+			config:  FlagValues{"timeout": "10m", "namespace": "fromconfig", "unknown": "ignored"},
+			timeout: 10 * time.Minute,
+			ns:      "fromconfig",
+			sources: map[string]Source{"timeout": SourceConfig, "namespace": SourceConfig},
+			applied: []string{"namespace", "timeout"},
+		},
+		{
+			name:    "kube-context of the config file",
+			config:  FlagValues{"timeout": "10m", "namespace": "fromconfig"},
+			context: "production",
+			timeout: 20 * time.Minute,
+			ns:      "fromconfig",
+			sources: map[string]Source{"timeout": SourceConfig, "namespace": SourceConfig},
+			applied: []string{"namespace", "timeout"},
+		},
+		{
+			name:    "environment variable over config file",
+			env:     map[string]string{"HELM_NAMESPACE": "fromenv"},
+			config:  FlagValues{"namespace": "fromconfig"},
+			timeout: 5 * time.Minute,
+			ns:      "fromenv",
+			sources: map[string]Source{"timeout": SourceDefault, "namespace": SourceEnv},
+		},
+		{
+			name:    "flag over config file",
+			args:    []string{"--timeout", "1m"},
+			config:  FlagValues{"timeout": "10m"},
+			timeout: time.Minute,
+			sources: map[string]Source{"timeout": SourceFlag},
+		},
+		{
+			name:    "invalid value",
+			config:  FlagValues{"timeout": "soon"},
+			wantErr: `invalid value "soon" of flag "timeout"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := tt.env["HELM_NAMESPACE"]
+			var timeout time.Duration
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.StringVar(&ns, "namespace", ns, "")
+			fs.DurationVar(&timeout, "timeout", 5*time.Minute, "")
+			require.NoError(t, fs.Parse(tt.args))
+
+			r := &Resolver{
+				Config: &Config{
+					Flags: tt.config,
+					Contexts: map[string]ContextConfig{
+						"production": {Flags: FlagValues{"timeout": "20m"}},
+					},
+				},
+				Context: tt.context,
+				LookupEnv: func(key string) (string, bool) {
+					v, ok := tt.env[key]
+					return v, ok
+				},
+			}
+			for name, want := range tt.sources {
+				_, source := r.Resolve(fs.Lookup(name))
+				assert.Equal(t, want, source, "source of %s", name)
+			}
+
+			applied, err := r.Apply(fs)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.applied, applied)
+			assert.Equal(t, tt.timeout, timeout)
+			assert.Equal(t, tt.ns, ns)
+		})
+	}
+}
@@ -24,6 +24,7 @@ These dependencies are expressed as interfaces so that alternate implementations
 package cli
 
 import (
+	"encoding/json"
 	"net/http"
 	"os"
 	"strconv"
@@ -53,45 +54,67 @@ type EnvSettings struct {
 	config    *genericclioptions.ConfigFlags
 
 	// KubeConfig is the path to the kubeconfig file
-	KubeConfig string
+	KubeConfig string `json:"kubeConfig"`
 	// KubeContext is the name of the kubeconfig context.
-	KubeContext string
+	KubeContext string `json:"kubeContext"`
 	// Bearer KubeToken used for authentication
-	KubeToken string
+	KubeToken string `json:"kubeToken"`
 	// Username to impersonate for the operation
-	KubeAsUser string
+	KubeAsUser string `json:"kubeAsUser"`
 	// Groups to impersonate for the operation, multiple groups parsed from a comma delimited list
-	KubeAsGroups []string
+	KubeAsGroups []string `json:"kubeAsGroups"`
 	// Kubernetes API Server Endpoint for authentication
-	KubeAPIServer string
+	KubeAPIServer string `json:"kubeAPIServer"`
 	// Custom certificate authority file.
-	KubeCaFile string
+	KubeCaFile string `json:"kubeCaFile"`
 	// KubeInsecureSkipTLSVerify indicates if server's certificate will not be checked for validity.
 	// This makes the HTTPS connections insecure
-	KubeInsecureSkipTLSVerify bool
+	KubeInsecureSkipTLSVerify bool `json:"kubeInsecureSkipTLSVerify"`
 	// KubeTLSServerName overrides the name to use for server certificate validation.
 	// If it is not provided, the hostname used to contact the server is used
-	KubeTLSServerName string
+	KubeTLSServerName string `json:"kubeTLSServerName"`
 	// Debug indicates whether or not Helm is running in Debug mode.
-	Debug bool
+	Debug bool `json:"debug"`
 	// RegistryConfig is the path to the registry config file.
-	RegistryConfig string
+	RegistryConfig string `json:"registryConfig"`
 	// RepositoryConfig is the path to the repositories file.
-	RepositoryConfig string
+	RepositoryConfig string `json:"repositoryConfig"`
 	// RepositoryCache is the path to the repository cache directory.
-	RepositoryCache string
+	RepositoryCache string `json:"repositoryCache"`
 	// PluginsDirectory is the path to the plugins directory.
-	PluginsDirectory string
+	PluginsDirectory string `json:"pluginsDirectory"`
 	// MaxHistory is the max release history maintained.
-	MaxHistory int
+	MaxHistory int `json:"maxHistory"`
 	// BurstLimit is the default client-side throttling limit.
-	BurstLimit int
+	BurstLimit int `json:"burstLimit"`
 	// QPS is queries per second which may be used to avoid throttling.
-	QPS float32
+	QPS float32 `json:"qps"`
+	// DownloadConnections is the number of connections used to download large
+	// files in chunks. Values lower than two disable chunked downloads.
+	DownloadConnections int `json:"downloadConnections"`
+	// DownloadResumeAttempts is the number of times an interrupted download is
+	// resumed. Zero uses the getter default and a negative value disables it.
+	DownloadResumeAttempts int `json:"downloadResumeAttempts"`
 	// ColorMode controls colorized output (never, auto, always)
-	ColorMode string
+	ColorMode string `json:"colorMode"`
+	// ErrorFormat is the format errors are reported in (text, json)
+	ErrorFormat string `json:"errorFormat"`
 	// ContentCache is the location where cached charts are stored
-	ContentCache string
+	ContentCache string `json:"contentCache"`
+	// Offline disables network access to chart repositories, registries and
+	// other remote content. Only cached content can be used.
+	Offline bool `json:"offline"`
+	// Keyring is the path to the keyring managed by 'helm keys'.
+	Keyring string `json:"keyring"`
+	// TrustPolicy is the path to the file restricting the keys trusted for
+	// each repository.
+	TrustPolicy string `json:"trustPolicy"`
+	// NotifyConfig is the path to the file configuring the sinks notified of
+	// the operations on releases.
+	NotifyConfig string `json:"notifyConfig"`
+	// ConfigFile is the path to the Helm config file, which sets the default
+	// values of flags.
+	ConfigFile string `json:"configFile"`
 }
 
 func New() *EnvSettings {
@@ -114,7 +137,15 @@ func New() *EnvSettings {
 		ContentCache:              envOr("HELM_CONTENT_CACHE", helmpath.CachePath("content")),
 		BurstLimit:                envIntOr("HELM_BURST_LIMIT", defaultBurstLimit),
 		QPS:                       envFloat32Or("HELM_QPS", defaultQPS),
+		DownloadConnections:       envIntOr("HELM_DOWNLOAD_CONNECTIONS", 0),
+		DownloadResumeAttempts:    envIntOr("HELM_DOWNLOAD_RESUME_ATTEMPTS", 0),
 		ColorMode:                 envColorMode(),
+		ErrorFormat:               envOr("HELM_ERROR_FORMAT", "text"),
+		Offline:                   envBoolOr("HELM_OFFLINE", false),
+		Keyring:                   envOr("HELM_KEYRING", helmpath.ConfigPath("keyring.gpg")),
+		TrustPolicy:               envOr("HELM_TRUST_POLICY", helmpath.ConfigPath("trust.yaml")),
+		NotifyConfig:              envOr("HELM_NOTIFY_CONFIG", helmpath.ConfigPath("notifications.yaml")),
+		ConfigFile:                envOr("HELM_CONFIG_FILE", helmpath.ConfigPath(ConfigFileName)),
 	}
 	env.Debug, _ = strconv.ParseBool(os.Getenv("HELM_DEBUG"))
 
@@ -165,10 +196,14 @@ func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.RepositoryConfig, "repository-config", s.RepositoryConfig, "path to the file containing repository names and URLs")
 	fs.StringVar(&s.RepositoryCache, "repository-cache", s.RepositoryCache, "path to the directory containing cached repository indexes")
 	fs.StringVar(&s.ContentCache, "content-cache", s.ContentCache, "path to the directory containing cached content (e.g. charts)")
+	fs.BoolVar(&s.Offline, "offline", s.Offline, "disable network access to chart repositories and registries, using only cached content")
 	fs.IntVar(&s.BurstLimit, "burst-limit", s.BurstLimit, "client-side default throttling limit")
 	fs.Float32Var(&s.QPS, "qps", s.QPS, "queries per second used when communicating with the Kubernetes API, not including bursting")
+	fs.IntVar(&s.DownloadConnections, "download-connections", s.DownloadConnections, "number of connections used to download large charts and indexes in chunks, when the server supports range requests")
+	fs.IntVar(&s.DownloadResumeAttempts, "download-resume-attempts", s.DownloadResumeAttempts, "number of times an interrupted download is resumed (0 uses the default, a negative value disables resuming)")
 	fs.StringVar(&s.ColorMode, "color", s.ColorMode, "use colored output (never, auto, always)")
 	fs.StringVar(&s.ColorMode, "colour", s.ColorMode, "use colored output (never, auto, always)")
+	fs.StringVar(&s.ErrorFormat, "error-format", s.ErrorFormat, "format errors are reported in (text, json)")
 }
 
 func envOr(name, def string) string {
@@ -241,20 +276,28 @@ func envColorMode() string {
 
 func (s *EnvSettings) EnvVars() map[string]string {
 	envvars := map[string]string{
-		"HELM_BIN":               os.Args[0],
-		"HELM_CACHE_HOME":        helmpath.CachePath(""),
-		"HELM_CONFIG_HOME":       helmpath.ConfigPath(""),
-		"HELM_DATA_HOME":         helmpath.DataPath(""),
-		"HELM_DEBUG":             strconv.FormatBool(s.Debug),
-		"HELM_PLUGINS":           s.PluginsDirectory,
-		"HELM_REGISTRY_CONFIG":   s.RegistryConfig,
-		"HELM_REPOSITORY_CACHE":  s.RepositoryCache,
-		"HELM_CONTENT_CACHE":     s.ContentCache,
-		"HELM_REPOSITORY_CONFIG": s.RepositoryConfig,
-		"HELM_NAMESPACE":         s.Namespace(),
-		"HELM_MAX_HISTORY":       strconv.Itoa(s.MaxHistory),
-		"HELM_BURST_LIMIT":       strconv.Itoa(s.BurstLimit),
-		"HELM_QPS":               strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_BIN":                      os.Args[0],
+		"HELM_CACHE_HOME":               helmpath.CachePath(""),
+		"HELM_CONFIG_HOME":              helmpath.ConfigPath(""),
+		"HELM_DATA_HOME":                helmpath.DataPath(""),
+		"HELM_DEBUG":                    strconv.FormatBool(s.Debug),
+		"HELM_PLUGINS":                  s.PluginsDirectory,
+		"HELM_REGISTRY_CONFIG":          s.RegistryConfig,
+		"HELM_REPOSITORY_CACHE":         s.RepositoryCache,
+		"HELM_CONTENT_CACHE":            s.ContentCache,
+		"HELM_REPOSITORY_CONFIG":        s.RepositoryConfig,
+		"HELM_NAMESPACE":                s.Namespace(),
+		"HELM_MAX_HISTORY":              strconv.Itoa(s.MaxHistory),
+		"HELM_BURST_LIMIT":              strconv.Itoa(s.BurstLimit),
+		"HELM_QPS":                      strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_OFFLINE":                  strconv.FormatBool(s.Offline),
+		"HELM_DOWNLOAD_CONNECTIONS":     strconv.Itoa(s.DownloadConnections),
+		"HELM_DOWNLOAD_RESUME_ATTEMPTS": strconv.Itoa(s.DownloadResumeAttempts),
+		"HELM_KEYRING":                  s.Keyring,
+		"HELM_TRUST_POLICY":             s.TrustPolicy,
+		"HELM_NOTIFY_CONFIG":            s.NotifyConfig,
+		"HELM_CONFIG_FILE":              s.ConfigFile,
+		"HELM_ERROR_FORMAT":             s.ErrorFormat,
 
 		// broken, these are populated from helm flags and not kubeconfig.
 		"HELM_KUBECONTEXT":                  s.KubeContext,
@@ -272,6 +315,31 @@ func (s *EnvSettings) EnvVars() map[string]string {
 	return envvars
 }
 
+// MarshalJSON encodes the settings, including the namespace in effect.
+func (s *EnvSettings) MarshalJSON() ([]byte, error) {
+	type settings EnvSettings
+	return json.Marshal(struct {
+		Namespace string `json:"namespace"`
+		*settings
+	}{s.Namespace(), (*settings)(s)})
+}
+
+// UnmarshalJSON decodes settings encoded by MarshalJSON. The Kubernetes
+// client configuration follows the decoded settings only for settings
+// returned by New.
+func (s *EnvSettings) UnmarshalJSON(data []byte) error {
+	type settings EnvSettings
+	v := struct {
+		Namespace string `json:"namespace"`
+		*settings
+	}{s.namespace, (*settings)(s)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	s.namespace = v.Namespace
+	return nil
+}
+
 // Namespace gets the namespace from the configuration
 func (s *EnvSettings) Namespace() string {
 	if s.config != nil {
@@ -285,6 +353,18 @@ func (s *EnvSettings) Namespace() string {
 	return "default"
 }
 
+// CurrentContext returns the kube-context in effect: the one set by the
+// settings, or else the current context of the kubeconfig.
+func (s *EnvSettings) CurrentContext() string {
+	if s.KubeContext != "" || s.config == nil {
+		return s.KubeContext
+	}
+	if raw, err := s.config.ToRawKubeConfigLoader().RawConfig(); err == nil {
+		return raw.CurrentContext
+	}
+	return ""
+}
+
 // SetNamespace sets the namespace in the configuration
 func (s *EnvSettings) SetNamespace(namespace string) {
 	s.namespace = namespace
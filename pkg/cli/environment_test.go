@@ -17,6 +17,7 @@ limitations under the License.
 package cli
 
 import (
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
@@ -145,6 +146,23 @@ func TestEnvSettings(t *testing.T) {
 	}
 }
 
+func TestEnvSettingsDownload(t *testing.T) {
+	defer resetEnv()()
+	t.Setenv("HELM_DOWNLOAD_CONNECTIONS", "4")
+	t.Setenv("HELM_DOWNLOAD_RESUME_ATTEMPTS", "5")
+
+	settings := New()
+	assert.Equal(t, 4, settings.DownloadConnections)
+	assert.Equal(t, 5, settings.DownloadResumeAttempts)
+
+	flags := pflag.NewFlagSet("testing", pflag.ContinueOnError)
+	settings.AddFlags(flags)
+	require.NoError(t, flags.Parse([]string{"--download-connections=8", "--download-resume-attempts=-1"}))
+	assert.Equal(t, 8, settings.DownloadConnections)
+	assert.Equal(t, -1, settings.DownloadResumeAttempts)
+	assert.Equal(t, "8", settings.EnvVars()["HELM_DOWNLOAD_CONNECTIONS"])
+}
+
 func TestEnvOrBool(t *testing.T) {
 	const envName = "TEST_ENV_OR_BOOL"
 	tests := []struct {
@@ -243,3 +261,35 @@ func resetEnv() func() {
 		}
 	}
 }
+
+func TestEnvOffline(t *testing.T) {
+	t.Setenv("HELM_OFFLINE", "true")
+	s := New()
+	if !s.Offline {
+		t.Error("expected HELM_OFFLINE to enable offline mode")
+	}
+	if got := s.EnvVars()["HELM_OFFLINE"]; got != "true" {
+		t.Errorf("expected HELM_OFFLINE=true in env vars, got %q", got)
+	}
+}
+
+func TestEnvSettingsJSON(t *testing.T) {
+	s := New()
+	s.SetNamespace("myns")
+	s.KubeContext = "staging"
+	s.KubeAsGroups = []string{"admins"}
+	s.QPS = 50
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"namespace":"myns"`)
+	assert.Contains(t, string(data), `"kubeContext":"staging"`)
+
+	decoded := New()
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, "myns", decoded.Namespace())
+	assert.Equal(t, "staging", decoded.KubeContext)
+	assert.Equal(t, []string{"admins"}, decoded.KubeAsGroups)
+	assert.Equal(t, float32(50), decoded.QPS)
+	assert.Equal(t, s.EnvVars(), decoded.EnvVars())
+}
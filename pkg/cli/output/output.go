@@ -33,6 +33,9 @@ const (
 	Table Format = "table"
 	JSON  Format = "json"
 	YAML  Format = "yaml"
+	// Wide is the table format with additional columns. It is only supported
+	// by the commands binding it explicitly, so ParseFormat rejects it.
+	Wide Format = "wide"
 )
 
 // Formats returns a list of the string representation of the supported formats
@@ -68,6 +71,11 @@ func (o Format) Write(out io.Writer, w Writer) error {
 		return w.WriteJSON(out)
 	case YAML:
 		return w.WriteYAML(out)
+	case Wide:
+		if ww, ok := w.(WideWriter); ok {
+			return ww.WriteWide(out)
+		}
+		return w.WriteTable(out)
 	}
 	return ErrInvalidFormatType
 }
@@ -101,6 +109,13 @@ type Writer interface {
 	WriteYAML(out io.Writer) error
 }
 
+// WideWriter is implemented by the writers supporting the wide format.
+type WideWriter interface {
+	// WriteWide will write tabular output with additional columns into the
+	// given io.Writer, returning an error if any occur
+	WriteWide(out io.Writer) error
+}
+
 // EncodeJSON is a helper function to decorate any error message with a bit more
 // context and avoid writing the same code over and over for printers.
 func EncodeJSON(out io.Writer, obj any) error {
@@ -19,10 +19,13 @@ package values
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
@@ -38,16 +41,29 @@ type Options struct {
 	FileValues    []string // --set-file
 	JSONValues    []string // --set-json
 	LiteralValues []string // --set-literal
+	EnvPrefixes   []string // --set-env
+
+	// ContentCache is the directory of the content cache. Remote values
+	// files pinned with a "#sha256=<digest>" suffix are cached in it.
+	ContentCache string
 }
 
-// MergeValues merges values from files specified via -f/--values and directly
-// via --set-json, --set, --set-string, or --set-file, marshaling them to YAML
+// MergeValues merges values from files specified via -f/--values, environment
+// variables selected via --set-env, and directly via --set-json, --set,
+// --set-string, or --set-file, marshaling them to YAML
 func (opts *Options) MergeValues(p getter.Providers) (map[string]any, error) {
 	base := map[string]any{}
 
+	stdin := false
 	// User specified a values files via -f/--values
 	for _, filePath := range opts.ValueFiles {
-		raw, err := readFile(filePath, p)
+		if strings.TrimSpace(filePath) == "-" {
+			if stdin {
+				return nil, errors.New("values can only be read from stdin once")
+			}
+			stdin = true
+		}
+		raw, err := opts.readFile(filePath, p)
 		if err != nil {
 			return nil, err
 		}
@@ -59,6 +75,13 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]any, error) {
 		base = loader.MergeMaps(base, currentMap)
 	}
 
+	// User specified environment variables via --set-env
+	for _, prefix := range opts.EnvPrefixes {
+		if err := parseEnv(prefix, os.Environ(), base); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-env data: %w", err)
+		}
+	}
+
 	// User specified a value via --set-json
 	for _, value := range opts.JSONValues {
 		trimmedValue := strings.TrimSpace(value)
@@ -94,7 +117,10 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]any, error) {
 	// User specified a value via --set-file
 	for _, value := range opts.FileValues {
 		reader := func(rs []rune) (any, error) {
-			bytes, err := readFile(string(rs), p)
+			if dir, ok := localDir(string(rs)); ok {
+				return readDir(dir)
+			}
+			bytes, err := opts.readFile(string(rs), p)
 			if err != nil {
 				return nil, err
 			}
@@ -130,9 +156,78 @@ func readFile(filePath string, p getter.Providers) ([]byte, error) {
 	if err != nil {
 		return os.ReadFile(filePath)
 	}
-	data, err := g.Get(filePath, getter.WithURL(filePath))
+	href, digest := splitDigest(filePath)
+	data, err := g.Get(href, getter.WithURL(href), getter.WithArtifactType("values"), getter.WithDigest(digest))
 	if err != nil {
 		return nil, err
 	}
+	// Not all getters verify the digest, plugins in particular.
+	if err := getter.VerifyDigest(data.Bytes(), digest); err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w", href, err)
+	}
 	return data.Bytes(), nil
 }
+
+// localDir reports whether filePath refers to a local directory.
+func localDir(filePath string) (string, bool) {
+	fi, err := os.Stat(filePath)
+	if err != nil || !fi.IsDir() {
+		return "", false
+	}
+	return filePath, true
+}
+
+// readDir loads the files of a directory into a map keyed by file name, with
+// subdirectories loaded recursively into nested maps.
+func readDir(dir string) (map[string]any, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	vals := make(map[string]any, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			sub, err := readDir(path)
+			if err != nil {
+				return nil, err
+			}
+			vals[entry.Name()] = sub
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		vals[entry.Name()] = string(data)
+	}
+	return vals, nil
+}
+
+// envValueEscaper escapes the characters of an environment variable value
+// that the --set parser would otherwise interpret.
+var envValueEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, "{", `\{`)
+
+// parseEnv sets values from the environment variables starting with prefix.
+// The prefix is removed from the variable name and '__' separates nested
+// keys, so with the prefix "APP_" the variable "APP_image__tag=1.0" sets
+// image.tag. Values are typed the same way as --set values.
+func parseEnv(prefix string, environ []string, dest map[string]any) error {
+	if prefix == "" {
+		return errors.New("prefix must not be empty")
+	}
+	environ = append([]string(nil), environ...)
+	sort.Strings(environ)
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		key, ok := strings.CutPrefix(name, prefix)
+		if !ok || key == "" {
+			continue
+		}
+		key = strings.ReplaceAll(key, "__", ".")
+		if err := strvals.ParseInto(key+"="+envValueEscaper.Replace(value), dest); err != nil {
+			return fmt.Errorf("environment variable %s: %w", name, err)
+		}
+	}
+	return nil
+}
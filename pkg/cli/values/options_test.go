@@ -18,9 +18,12 @@ package values
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -364,3 +367,115 @@ func TestMergeValuesCLI(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeValuesSetFileDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.conf"), []byte("port=80"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tls"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls", "ca.pem"), []byte("CA"), 0644))
+
+	opts := Options{FileValues: []string{"config=" + dir}}
+	got, err := opts.MergeValues(getter.Providers{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"config": map[string]any{
+			"app.conf": "port=80",
+			"tls": map[string]any{
+				"ca.pem": "CA",
+			},
+		},
+	}, got)
+}
+
+func TestParseEnv(t *testing.T) {
+	environ := []string{
+		"APP_replicaCount=3",
+		"APP_image__tag=1.0",
+		"APP_image__pullPolicy=Always",
+		"APP_enabled=true",
+		"APP_args=a,b",
+		"APP_=ignored",
+		"OTHER_name=ignored",
+	}
+	got := map[string]any{}
+	require.NoError(t, parseEnv("APP_", environ, got))
+	assert.Equal(t, map[string]any{
+		"replicaCount": int64(3),
+		"image": map[string]any{
+			"tag":        "1.0",
+			"pullPolicy": "Always",
+		},
+		"enabled": true,
+		"args":    "a,b",
+	}, got)
+
+	assert.Error(t, parseEnv("", environ, map[string]any{}))
+}
+
+func TestMergeValuesSetEnv(t *testing.T) {
+	t.Setenv("HELMTEST_name", "from-env")
+	t.Setenv("HELMTEST_port", "8080")
+
+	opts := Options{
+		EnvPrefixes: []string{"HELMTEST_"},
+		Values:      []string{"port=9090"},
+	}
+	got, err := opts.MergeValues(getter.Providers{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "from-env", "port": int64(9090)}, got)
+}
+
+func TestMergeValuesStdinOnce(t *testing.T) {
+	opts := Options{ValueFiles: []string{"-", "-"}}
+	_, err := opts.MergeValues(getter.Providers{})
+	assert.EqualError(t, err, "values can only be read from stdin once")
+}
+
+// countingGetter counts the calls made to it
+type countingGetter struct {
+	content []byte
+	calls   int
+}
+
+func (g *countingGetter) Get(_ string, _ ...getter.Option) (*bytes.Buffer, error) {
+	g.calls++
+	return bytes.NewBuffer(g.content), nil
+}
+
+func TestReadFilePinnedDigest(t *testing.T) {
+	content := []byte("replicas: 3\n")
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	g := &countingGetter{content: content}
+	p := getter.Providers{{
+		Schemes: []string{"https"},
+		New:     func(_ ...getter.Option) (getter.Getter, error) { return g, nil },
+	}}
+	opts := &Options{ContentCache: t.TempDir()}
+
+	for range 2 {
+		data, err := opts.readFile("https://example.com/values.yaml#sha256="+digest, p)
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+	}
+	assert.Equal(t, 1, g.calls, "pinned values should be read from the cache")
+
+	_, err := opts.readFile("https://example.com/values.yaml", p)
+	require.NoError(t, err)
+	assert.Equal(t, 2, g.calls, "unpinned values should not be cached")
+
+	wrong := strings.Repeat("0", len(digest))
+	_, err = opts.readFile("https://example.com/values.yaml#sha256="+wrong, p)
+	assert.ErrorContains(t, err, "digest mismatch")
+}
+
+func TestSplitDigest(t *testing.T) {
+	ref, digest := splitDigest("oci://example.com/values:prod#sha256=ABC")
+	assert.Equal(t, "oci://example.com/values:prod", ref)
+	assert.Equal(t, "abc", digest)
+
+	ref, digest = splitDigest("https://example.com/values.yaml#section")
+	assert.Equal(t, "https://example.com/values.yaml#section", ref)
+	assert.Empty(t, digest)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+// digestFragment is the URL fragment prefix used to pin the content of a
+// remote values file, e.g. https://example.com/values.yaml#sha256=<hex>.
+const digestFragment = "sha256="
+
+// readFile reads a values file like the readFile function, using the content
+// cache for remote files pinned to a digest.
+func (opts *Options) readFile(filePath string, p getter.Providers) ([]byte, error) {
+	key, ok := cacheKey(filePath, p)
+	if !ok || opts.ContentCache == "" {
+		return readFile(filePath, p)
+	}
+
+	cache := &downloader.DiskCache{Root: opts.ContentCache}
+	if path, err := cache.Get(key, downloader.CacheValues); err == nil {
+		data, err := os.ReadFile(path)
+		if err == nil && getter.VerifyDigest(data, hex.EncodeToString(key[:])) == nil {
+			return data, nil
+		}
+		slog.Debug("ignoring invalid cached values file", "path", path, slog.Any("error", err))
+	}
+
+	data, err := readFile(filePath, p)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cache.Put(key, bytes.NewReader(data), downloader.CacheValues); err != nil {
+		slog.Debug("unable to cache values file", "file", filePath, slog.Any("error", err))
+	}
+	return data, nil
+}
+
+// cacheKey returns the content cache key of a remote values file pinned to
+// a digest.
+func cacheKey(filePath string, p getter.Providers) ([sha256.Size]byte, bool) {
+	var key [sha256.Size]byte
+	u, err := url.Parse(filePath)
+	if err != nil || u.Scheme == "" {
+		return key, false
+	}
+	if _, err := p.ByScheme(u.Scheme); err != nil {
+		return key, false
+	}
+	_, digest := splitDigest(filePath)
+	b, err := hex.DecodeString(digest)
+	if err != nil || len(b) != sha256.Size {
+		return key, false
+	}
+	copy(key[:], b)
+	return key, true
+}
+
+// splitDigest splits a "#sha256=<hex>" digest suffix off a reference.
+func splitDigest(ref string) (string, string) {
+	i := strings.LastIndex(ref, "#")
+	if i < 0 {
+		return ref, ""
+	}
+	digest, ok := strings.CutPrefix(ref[i+1:], digestFragment)
+	if !ok {
+		return ref, ""
+	}
+	return ref[:i], strings.ToLower(digest)
+}
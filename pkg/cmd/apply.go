@@ -0,0 +1,130 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const applyDesc = `
+This command reconciles several releases declared in a file.
+
+Each release is installed if it does not exist, and upgraded otherwise. A
+release can list the releases it 'needs', by name or by namespace/name, which
+are reconciled before it. When a release fails, the releases that need it are
+skipped, and the others are still reconciled. The needs are recorded in the
+releases, as with 'helm upgrade --needs'.
+
+Releases without a namespace are in the namespace of the command. Relative
+paths of local charts and values files are resolved from the directory of the
+file. Values files are merged in order, and 'values' take precedence over them.
+
+    releases:
+    - name: db
+      namespace: data
+      chart: oci://registry.example.com/charts/postgresql
+      version: 12.1.0
+      valuesFiles: [db.yaml]
+    - name: app
+      chart: ./charts/app
+      needs: [data/db]
+      values:
+        replicaCount: 2
+
+    $ helm apply -f releases.yaml
+`
+
+func newApplyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewComposite(cfg)
+	var file string
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "apply -f FILE",
+		Short:             "install or upgrade the releases declared in a file",
+		Long:              applyDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			spec, err := action.LoadCompositeSpec(file)
+			if err != nil {
+				return err
+			}
+
+			client.DryRunStrategy, err = cmdGetDryRunFlagStrategy(cmd, false)
+			if err != nil {
+				return err
+			}
+			client.Namespace = settings.Namespace()
+			client.Settings = settings
+			results, err := client.Run(context.Background(), spec)
+			if results != nil {
+				if werr := outfmt.Write(out, &compositeWriter{results}); werr != nil {
+					return werr
+				}
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&file, "file", "f", "", "file that declares the releases")
+	cmd.MarkFlagRequired("file")
+	f.BoolVar(&client.CreateNamespace, "create-namespace", false, "create the namespace of the releases that are installed if not present")
+	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking a release as successful. It will wait for as long as --timeout")
+	addDryRunFlag(cmd)
+	bindOutputFlag(cmd, &outfmt)
+	AddWaitFlag(cmd, &client.WaitStrategy)
+	return cmd
+}
+
+// compositeWriter writes the results of helm apply.
+type compositeWriter struct {
+	results []action.CompositeResult
+}
+
+func (w *compositeWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("NAME", "NAMESPACE", "RESULT", "REVISION", "MESSAGE")
+	for _, r := range w.results {
+		revision := ""
+		if r.Revision > 0 {
+			revision = fmt.Sprint(r.Revision)
+		}
+		table.AddRow(r.Name, r.Namespace, r.Status, revision, r.Error)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *compositeWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.results)
+}
+
+func (w *compositeWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.results)
+}
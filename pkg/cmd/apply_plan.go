@@ -0,0 +1,158 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
+	ri "helm.sh/helm/v4/pkg/release"
+)
+
+const applyPlanDesc = `
+This command applies a plan saved with 'helm upgrade --plan -o json'.
+
+The revision planned is applied as is, without rendering the chart again. The
+plan is only applied if the release and its resources in the cluster did not
+change since the plan was made, so that the changes applied are exactly those
+reviewed. Otherwise, make a new plan.
+
+The plan does not hold the values of the release, as they may contain secrets.
+Pass the values the plan was made with again, with the same '--values' and
+'--set' flags.
+
+Use '-' as PLAN to read the plan from the standard input.
+
+    $ helm upgrade --plan -o json -f prod.yaml redis ./redis > plan.json
+    $ helm apply-plan -f prod.yaml plan.json
+`
+
+func newApplyPlanCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewUpgrade(cfg)
+	valueOpts := &values.Options{}
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "apply-plan PLAN",
+		Short:             "apply a plan made by helm upgrade --plan",
+		Long:              applyPlanDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			plan, err := readPlan(args[0])
+			if err != nil {
+				return err
+			}
+			if plan.Namespace != settings.Namespace() {
+				return fmt.Errorf("the plan is for release %q in namespace %q, use --namespace %s", plan.Release, plan.Namespace, plan.Namespace)
+			}
+			client.Namespace = plan.Namespace
+			valueOpts.ContentCache = settings.ContentCache
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			return runUpgrade(out, plan.Release, nil, client, outfmt, func(ctx context.Context) (ri.Releaser, error) {
+				return client.ApplyPlan(ctx, plan, vals)
+			})
+		},
+	}
+
+	f := cmd.Flags()
+	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
+	f.BoolVar(&client.RollbackOnFailure, "rollback-on-failure", false, "if set, Helm will rollback the upgrade to previous success release upon failure. The --wait flag will be defaulted to \"watcher\" if --rollback-on-failure is set")
+	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this upgrade when upgrade fails")
+	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in upgrade output. Does not affect presence in chart metadata")
+	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	addValueOptionsFlags(f, valueOpts)
+	bindOutputFlag(cmd, &outfmt)
+	AddWaitFlag(cmd, &client.WaitStrategy)
+	return cmd
+}
+
+// readPlan reads a plan from a file, or from the standard input for "-".
+func readPlan(name string) (*action.Plan, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan := &action.Plan{}
+	if err := yaml.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("unable to parse plan %s: %w", name, err)
+	}
+	return plan, nil
+}
+
+// planWriter writes the plan made by helm upgrade --plan.
+type planWriter struct {
+	plan *action.Plan
+}
+
+var planSymbols = map[action.PlanAction]string{
+	action.PlanCreate: "+",
+	action.PlanUpdate: "~",
+	action.PlanDelete: "-",
+}
+
+func (w *planWriter) WriteTable(out io.Writer) error {
+	p := w.plan
+	fmt.Fprintf(out, "Plan for release %q from revision %d to revision %d:\n\n", p.Release, p.CurrentRevision, p.Planned.Version)
+	changed := false
+	for _, c := range p.ResourceChanges {
+		if c.Action == action.PlanNoOp {
+			continue
+		}
+		changed = true
+		fmt.Fprintf(out, "  %s %s %s\n", planSymbols[c.Action], c.Action, c.Address)
+	}
+	if !changed {
+		fmt.Fprintln(out, "  No changes to the resources.")
+	}
+	for _, c := range p.ResourceChanges {
+		if c.Diff != "" {
+			fmt.Fprintf(out, "\n%s", c.Diff)
+		}
+	}
+	fmt.Fprintf(out, "\nPlan: %d to add, %d to change, %d to destroy.\n", p.Summary.Add, p.Summary.Change, p.Summary.Destroy)
+	return nil
+}
+
+func (w *planWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.plan)
+}
+
+func (w *planWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.plan)
+}
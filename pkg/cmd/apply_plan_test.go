@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/internal/test"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+const planChart = "testdata/testcharts/alpine"
+
+func TestUpgradePlanCmd(t *testing.T) {
+	rels := []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "funny-bunny"})}
+
+	tests := []cmdTestCase{{
+		name:   "plan an upgrade",
+		cmd:    "upgrade funny-bunny --plan " + planChart,
+		golden: "output/upgrade-plan.txt",
+		rels:   rels,
+	}, {
+		name:      "plan an upgrade with --install",
+		cmd:       "upgrade funny-bunny --plan --install " + planChart,
+		golden:    "output/upgrade-plan-install.txt",
+		wantError: true,
+		rels:      rels,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestApplyPlanCmd(t *testing.T) {
+	defer resetEnv()()
+
+	store := storageFixture()
+	require.NoError(t, store.Create(release.Mock(&release.MockReleaseOptions{Name: "funny-bunny"})))
+
+	_, out, err := executeActionCommandC(store, "upgrade funny-bunny --plan -o json "+planChart)
+	require.NoError(t, err)
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, os.WriteFile(planFile, []byte(out), 0o644))
+
+	_, out, err = executeActionCommandC(store, "apply-plan "+planFile)
+	require.NoError(t, err)
+	test.AssertGoldenString(t, out, "output/apply-plan.txt")
+
+	_, _, err = executeActionCommandC(store, "apply-plan "+planFile)
+	assert.ErrorContains(t, err, "the release changed since the plan was made")
+
+	_, _, err = executeActionCommandC(store, "apply-plan --namespace other "+planFile)
+	assert.ErrorContains(t, err, "use --namespace default")
+}
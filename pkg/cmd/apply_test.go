@@ -0,0 +1,42 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestApplyCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "install the releases",
+		cmd:    "apply -f testdata/releases.yaml",
+		golden: "output/apply.txt",
+	}, {
+		name:   "upgrade an existing release",
+		cmd:    "apply -f testdata/releases.yaml -o json",
+		golden: "output/apply-upgrade.json",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "backend"})},
+	}, {
+		name:      "missing file",
+		cmd:       "apply",
+		golden:    "output/apply-no-file.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+const cacheHelp = `
+This command consists of multiple subcommands to inspect and clean up the
+content cache.
+
+Charts and provenance files downloaded by 'helm pull', 'helm install',
+'helm upgrade' and 'helm dependency update' are stored in the content cache,
+addressed by their sha256 digest, so identical chart versions are only
+downloaded once. The location of the cache is set with --content-cache or
+$HELM_CONTENT_CACHE.
+`
+
+const cachePruneDesc = `
+Remove entries from the content cache.
+
+Entries that have not been used for longer than --older-than are removed. When
+--max-size is set, the least recently used entries are then removed until the
+cache fits in the given size. Use --all to empty the cache.
+
+    helm cache prune --older-than 720h --max-size 1Gi
+`
+
+func newCacheCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "inspect and clean up the content cache",
+		Long:  cacheHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newCacheListCmd(out))
+	cmd.AddCommand(newCachePruneCmd(out))
+
+	return cmd
+}
+
+func newCacheListCmd(out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	var noHeaders bool
+
+	cmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             "list the content cache entries",
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			c := &downloader.DiskCache{Root: settings.ContentCache}
+			entries, err := c.List()
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &cacheListWriter{entries: entries, noHeaders: noHeaders})
+		},
+	}
+
+	cmd.Flags().BoolVar(&noHeaders, "no-headers", false, "suppress headers in the output")
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+type cachePruneOptions struct {
+	olderThan time.Duration
+	maxSize   string
+	all       bool
+	root      string
+}
+
+func newCachePruneCmd(out io.Writer) *cobra.Command {
+	o := &cachePruneOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "prune",
+		Short:             "remove unused entries from the content cache",
+		Long:              cachePruneDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			o.root = settings.ContentCache
+			return o.run(out)
+		},
+	}
+
+	f := cmd.Flags()
+	f.DurationVar(&o.olderThan, "older-than", 0, "remove entries that have not been used for this long (e.g. 720h)")
+	f.StringVar(&o.maxSize, "max-size", "", "remove the least recently used entries until the cache is at most this size (e.g. 500Mi, 1Gi)")
+	f.BoolVar(&o.all, "all", false, "remove all entries")
+
+	return cmd
+}
+
+func (o *cachePruneOptions) run(out io.Writer) error {
+	var maxSize int64
+	if o.maxSize != "" {
+		q, err := resource.ParseQuantity(o.maxSize)
+		if err != nil {
+			return fmt.Errorf("invalid --max-size %q: %w", o.maxSize, err)
+		}
+		maxSize = q.Value()
+		if maxSize <= 0 {
+			return fmt.Errorf("invalid --max-size %q: must be greater than zero", o.maxSize)
+		}
+	}
+	if o.olderThan < 0 {
+		return errors.New("--older-than must not be negative")
+	}
+
+	olderThan := o.olderThan
+	if o.all {
+		// Every entry was used before now.
+		olderThan, maxSize = time.Nanosecond, 0
+	} else if olderThan == 0 && maxSize == 0 {
+		return errors.New("one of --older-than, --max-size or --all is required")
+	}
+
+	c := &downloader.DiskCache{Root: o.root}
+	removed, err := c.Prune(olderThan, maxSize)
+	var freed int64
+	for _, e := range removed {
+		freed += e.Size
+	}
+	fmt.Fprintf(out, "Removed %d cache entries, freed %s\n", len(removed), formatSize(freed))
+	return err
+}
+
+type cacheListWriter struct {
+	entries   []downloader.CacheEntry
+	noHeaders bool
+}
+
+func (w *cacheListWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	if !w.noHeaders {
+		table.AddRow("DIGEST", "TYPE", "SIZE", "LAST USED")
+	}
+	for _, e := range w.entries {
+		table.AddRow(e.Digest, cacheTypeName(e.Type), formatSize(e.Size), e.LastUsed.Format(time.RFC3339))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *cacheListWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.elements())
+}
+
+func (w *cacheListWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.elements())
+}
+
+func (w *cacheListWriter) elements() []downloader.CacheEntry {
+	// Initialize the array so no results returns an empty array instead of null
+	entries := make([]downloader.CacheEntry, 0, len(w.entries))
+	for _, e := range w.entries {
+		e.Type = cacheTypeName(e.Type)
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func cacheTypeName(cacheType string) string {
+	switch cacheType {
+	case downloader.CacheChart:
+		return "chart"
+	case downloader.CacheProv:
+		return "provenance"
+	case downloader.CacheValues:
+		return "values"
+	default:
+		return cacheType
+	}
+}
+
+func formatSize(size int64) string {
+	return resource.NewQuantity(size, resource.BinarySI).String()
+}
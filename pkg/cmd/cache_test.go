@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+func TestCacheListAndPrune(t *testing.T) {
+	defer resetEnv()()
+
+	root := t.TempDir()
+	t.Setenv("HELM_CONTENT_CACHE", root)
+
+	c := &downloader.DiskCache{Root: root}
+	key := sha256.Sum256([]byte("chart"))
+	if _, err := c.Put(key, strings.NewReader("chart"), downloader.CacheChart); err != nil {
+		t.Fatal(err)
+	}
+	digest := hex.EncodeToString(key[:])
+
+	_, out, err := executeActionCommand("cache list --content-cache " + root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "DIGEST") || !strings.Contains(out, digest) || !strings.Contains(out, "chart") {
+		t.Errorf("unexpected list output:\n%s", out)
+	}
+
+	_, out, err = executeActionCommand("cache ls -o json --content-cache " + root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"digest":"`+digest+`"`) || !strings.Contains(out, `"type":"chart"`) {
+		t.Errorf("unexpected json output:\n%s", out)
+	}
+
+	if _, _, err := executeActionCommand("cache prune --content-cache " + root); err == nil {
+		t.Error("expected an error when no prune criteria are given")
+	}
+	if _, _, err := executeActionCommand("cache prune --max-size nope --content-cache " + root); err == nil {
+		t.Error("expected an error for an invalid size")
+	}
+
+	// Recently used entries are kept
+	buf := &bytes.Buffer{}
+	o := &cachePruneOptions{olderThan: 24 * time.Hour, root: root}
+	if err := o.run(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Removed 0 cache entries, freed 0\n" {
+		t.Errorf("unexpected prune output %q", buf.String())
+	}
+
+	buf.Reset()
+	o = &cachePruneOptions{all: true, root: root}
+	if err := o.run(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Removed 1 cache entries, freed 5\n" {
+		t.Errorf("unexpected prune output %q", buf.String())
+	}
+
+	_, out, err = executeActionCommand("cache ls -o json --content-cache " + root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "[]\n" {
+		t.Errorf("expected an empty cache, got %q", out)
+	}
+}
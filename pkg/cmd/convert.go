@@ -0,0 +1,198 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/export"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+const convertHelp = `
+This command consists of multiple subcommands to convert an install command
+into the manifests of GitOps tools, so that they manage the release instead.
+
+The arguments and flags are those of 'helm install'. The chart must be a chart
+of a repository: either repo/chart, a chart name with --repo, or an OCI
+reference such as oci://example.com/charts/mychart. The values are merged from
+the values flags, and written into the manifests.
+`
+
+const convertToFluxHelp = `
+This command writes a Flux HelmRelease equivalent to installing the chart with
+'helm install', together with the HelmRepository source of the chart.
+
+    $ helm convert to-flux myrelease bitnami/nginx --version 15.0.0 -f values.yaml
+`
+
+const convertToArgoCDHelp = `
+This command writes an Argo CD Application equivalent to installing the chart
+with 'helm install'.
+
+    $ helm convert to-argocd myrelease bitnami/nginx --version 15.0.0 -f values.yaml
+`
+
+func newConvertCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert to-flux|to-argocd [ARGS]",
+		Short: "convert an install command into GitOps manifests",
+		Long:  convertHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newConvertToFluxCmd(out))
+	cmd.AddCommand(newConvertToArgoCDCmd(out))
+
+	return cmd
+}
+
+// convertOptions are the options of helm install that the GitOps tools
+// support.
+type convertOptions struct {
+	valueOpts       values.Options
+	version         string
+	repoURL         string
+	createNamespace bool
+	skipCRDs        bool
+}
+
+func (o *convertOptions) addFlags(f *pflag.FlagSet) {
+	addValueOptionsFlags(f, &o.valueOpts)
+	f.StringVar(&o.version, "version", "", "specify a version constraint for the chart version to use. If this is not specified, the latest version is used")
+	f.StringVar(&o.repoURL, "repo", "", "chart repository url where to locate the requested chart")
+	f.BoolVar(&o.createNamespace, "create-namespace", false, "create the release namespace if not present")
+	f.BoolVar(&o.skipCRDs, "skip-crds", false, "if set, no CRDs will be installed")
+}
+
+// release returns the release installed by helm install NAME CHART.
+func (o *convertOptions) release(args []string) (export.Release, error) {
+	repos, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return export.Release{}, err
+	}
+	url, chart, version, err := export.ResolveChart(args[1], o.repoURL, repos)
+	if err != nil {
+		return export.Release{}, err
+	}
+	if o.version != "" {
+		version = o.version
+	}
+	vals, err := o.valueOpts.MergeValues(getter.All(settings))
+	if err != nil {
+		return export.Release{}, err
+	}
+	return export.Release{
+		Name:            args[0],
+		Namespace:       settings.Namespace(),
+		Chart:           chart,
+		RepoURL:         url,
+		Version:         version,
+		Values:          vals,
+		CreateNamespace: o.createNamespace,
+		SkipCRDs:        o.skipCRDs,
+	}, nil
+}
+
+func compConvert(args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 1 {
+		return compListCharts(toComplete, false)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+func newConvertToFluxCmd(out io.Writer) *cobra.Command {
+	o := &convertOptions{}
+	opts := export.FluxOptions{}
+	var disableHooks bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "to-flux [NAME] [CHART]",
+		Short: "write the Flux HelmRelease of an install command",
+		Long:  convertToFluxHelp,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return compConvert(args, toComplete)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			rel, err := o.release(args)
+			if err != nil {
+				return err
+			}
+			rel.DisableHooks = disableHooks
+			rel.Timeout = timeout
+			b, err := export.Flux(rel, opts)
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(b)
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	o.addFlags(f)
+	f.BoolVar(&disableHooks, "no-hooks", false, "prevent hooks from running during install and upgrade")
+	f.DurationVar(&timeout, "timeout", 0, "time to wait for any individual Kubernetes operation. If this is not specified, the default of Flux is used")
+	f.DurationVar(&opts.Interval, "interval", export.DefaultFluxInterval, "interval at which Flux reconciles the release")
+	f.StringVar(&opts.SourceName, "source-name", "", "name of the HelmRepository source. If this is not specified, the release name is used")
+	return cmd
+}
+
+func newConvertToArgoCDCmd(out io.Writer) *cobra.Command {
+	o := &convertOptions{}
+	opts := export.ArgoCDOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "to-argocd [NAME] [CHART]",
+		Short: "write the Argo CD Application of an install command",
+		Long:  convertToArgoCDHelp,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return compConvert(args, toComplete)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			rel, err := o.release(args)
+			if err != nil {
+				return err
+			}
+			b, err := export.ArgoCD(rel, opts)
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(b)
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	o.addFlags(f)
+	f.StringVar(&opts.Namespace, "argocd-namespace", export.DefaultArgoCDNamespace, "namespace of the Application, where Argo CD runs")
+	f.StringVar(&opts.Project, "project", export.DefaultArgoCDProject, "Argo CD project of the Application")
+	f.StringVar(&opts.Server, "dest-server", export.DefaultArgoCDServer, "URL of the Kubernetes API server to deploy the release to")
+	return cmd
+}
@@ -0,0 +1,41 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestConvertCmd(t *testing.T) {
+	repoFile := "testdata/helmhome/helm/repositories.yaml"
+
+	tests := []cmdTestCase{{
+		name:   "convert to flux",
+		cmd:    "convert to-flux web testing/alpine --version 0.1.0 --set replicas=2 --create-namespace --no-hooks --timeout 5m --repository-config " + repoFile,
+		golden: "output/convert-to-flux.txt",
+	}, {
+		name:   "convert to argocd",
+		cmd:    "convert to-argocd web oci://example.com/charts/alpine:0.1.0 --set replicas=2 --create-namespace --skip-crds --project apps --repository-config " + repoFile,
+		golden: "output/convert-to-argocd.txt",
+	}, {
+		name:      "convert a local chart",
+		cmd:       "convert to-flux web testdata/testcharts/alpine --repository-config " + repoFile,
+		golden:    "output/convert-local-chart.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
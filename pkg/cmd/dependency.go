@@ -16,14 +16,21 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"path/filepath"
 
+	"github.com/Masterminds/semver/v3"
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"helm.sh/helm/v4/pkg/action"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
 )
 
 const dependencyDesc = `
@@ -81,11 +88,17 @@ This can take chart archives and chart directories as input. It will not alter
 the contents of a chart.
 
 This will produce an error if the chart cannot be loaded.
+
+With '--check-updates', the repository or registry of each dependency is
+queried for the newest version satisfying its version constraint, which is
+compared with the version locked in Chart.lock. '--output json' reports the
+locked versions and digests, available updates, and the charts missing from or
+not declared for 'charts/', for use by automated tooling.
 `
 
 func newDependencyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "dependency update|build|list",
+		Use:     "dependency update|build|list|upgrade",
 		Aliases: []string{"dep", "dependencies"},
 		Short:   "manage a chart's dependencies",
 		Long:    dependencyDesc,
@@ -95,12 +108,15 @@ func newDependencyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd.AddCommand(newDependencyListCmd(out))
 	cmd.AddCommand(newDependencyUpdateCmd(cfg, out))
 	cmd.AddCommand(newDependencyBuildCmd(out))
+	cmd.AddCommand(newDependencyUpgradeCmd(out))
 
 	return cmd
 }
 
 func newDependencyListCmd(out io.Writer) *cobra.Command {
 	client := action.NewDependency()
+	var checkUpdates bool
+	var outfmt output.Format
 	cmd := &cobra.Command{
 		Use:     "list CHART",
 		Aliases: []string{"ls"},
@@ -112,16 +128,97 @@ func newDependencyListCmd(out io.Writer) *cobra.Command {
 			if len(args) > 0 {
 				chartpath = filepath.Clean(args[0])
 			}
-			return client.List(chartpath, out)
+			if !checkUpdates && outfmt == output.Table {
+				return client.List(chartpath, out)
+			}
+
+			var versions func([]*chart.Dependency) ([][]*semver.Version, error)
+			if checkUpdates {
+				registryClient, err := newRegistryClient(out, client.CertFile, client.KeyFile, client.CaFile,
+					client.InsecureSkipTLSVerify, client.PlainHTTP, client.Username, client.Password)
+				if err != nil {
+					return fmt.Errorf("missing registry client: %w", err)
+				}
+				man := &downloader.Manager{
+					Out:              io.Discard,
+					ChartPath:        chartpath,
+					SkipUpdate:       client.SkipRefresh || settings.Offline,
+					Getters:          getter.All(settings),
+					RegistryClient:   registryClient,
+					RepositoryConfig: settings.RepositoryConfig,
+					RepositoryCache:  settings.RepositoryCache,
+					ContentCache:     settings.ContentCache,
+					Debug:            settings.Debug,
+				}
+				versions = man.AvailableVersions
+			}
+
+			report, err := client.Report(chartpath, versions)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &dependencyListWriter{report, client.ColumnWidth})
 		},
 	}
 
 	f := cmd.Flags()
 
 	f.UintVar(&client.ColumnWidth, "max-col-width", 80, "maximum column width for output table")
+	f.BoolVar(&checkUpdates, "check-updates", false, "look up newer versions of the dependencies satisfying their version constraints")
+	f.BoolVar(&client.SkipRefresh, "skip-refresh", false, "do not refresh the local repository cache when checking for updates")
+	f.StringVar(&client.CertFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&client.KeyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&client.CaFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&client.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the registry")
+	f.BoolVar(&client.PlainHTTP, "plain-http", false, "use insecure HTTP connections for the registry")
+	f.StringVar(&client.Username, "username", "", "registry username")
+	f.StringVar(&client.Password, "password", "", "registry password")
+	bindOutputFlag(cmd, &outfmt)
 	return cmd
 }
 
+type dependencyListWriter struct {
+	report      *action.DependencyReport
+	columnWidth uint
+}
+
+func (w *dependencyListWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.MaxColWidth = w.columnWidth
+	table.AddRow("NAME", "VERSION", "REPOSITORY", "STATUS", "LOCKED", "LATEST")
+	for _, dep := range w.report.Dependencies {
+		locked := dep.Locked
+		if locked == "" {
+			locked = "-"
+		}
+		latest := dep.Latest
+		if latest == "" {
+			latest = "-"
+		} else if dep.UpdateAvailable {
+			latest += " (update available)"
+		}
+		table.AddRow(dep.Name, dep.Version, dep.Repository, dep.Status, locked, latest)
+	}
+	if err := output.EncodeTable(out, table); err != nil {
+		return err
+	}
+	if w.report.LockOutOfSync {
+		fmt.Fprintln(out, "WARNING: Chart.lock is out of sync with Chart.yaml.")
+	}
+	for _, f := range w.report.Extra {
+		fmt.Fprintf(out, "WARNING: %q is not in Chart.yaml.\n", f)
+	}
+	return nil
+}
+
+func (w *dependencyListWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.report)
+}
+
+func (w *dependencyListWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.report)
+}
+
 func addDependencySubcommandFlags(f *pflag.FlagSet, client *action.Dependency) {
 	f.BoolVar(&client.Verify, "verify", false, "verify the packages against signatures")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
@@ -133,4 +230,5 @@ func addDependencySubcommandFlags(f *pflag.FlagSet, client *action.Dependency) {
 	f.BoolVar(&client.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the chart download")
 	f.BoolVar(&client.PlainHTTP, "plain-http", false, "use insecure HTTP connections for the chart download")
 	f.StringVar(&client.CaFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&client.Untar, "untar", false, "vendor dependencies as expanded chart directories under charts/ instead of archives")
 }
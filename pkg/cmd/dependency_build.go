@@ -16,11 +16,15 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/util/homedir"
@@ -40,10 +44,31 @@ does.
 
 If no lock file is found, 'helm dependency build' will mirror the behavior
 of 'helm dependency update'.
+
+The lock file records the digest of the archive of every dependency downloaded
+from a repository, and the digest of its manifest for OCI registries. Build
+fails when a dependency no longer matches these digests, for instance because
+its repository published the same version again. Run 'helm dependency update'
+to accept the change.
+
+With '--offline' (or HELM_OFFLINE=true), repositories are not refreshed and
+dependencies are only taken from the cached repository indexes and content
+cache. The build fails when a dependency would need to be downloaded.
+
+Local dependencies (those with a 'file://' repository) are repackaged on every
+build. The packaged chart records a digest of its source directory, and build
+reports the local dependencies whose sources changed since they were last
+vendored. With '--watch', the command keeps running after the first build and
+rebuilds the dependencies whenever a local dependency changes.
 `
 
+// watchInterval is how often 'helm dependency build --watch' checks local
+// dependencies for changes.
+const watchInterval = 2 * time.Second
+
 func newDependencyBuildCmd(out io.Writer) *cobra.Command {
 	client := action.NewDependency()
+	var watch bool
 
 	cmd := &cobra.Command{
 		Use:   "build CHART",
@@ -65,18 +90,26 @@ func newDependencyBuildCmd(out io.Writer) *cobra.Command {
 				Out:              out,
 				ChartPath:        chartpath,
 				Keyring:          client.Keyring,
-				SkipUpdate:       client.SkipRefresh,
+				TrustPolicy:      settings.TrustPolicy,
+				SkipUpdate:       client.SkipRefresh || settings.Offline,
 				Getters:          getter.All(settings),
 				RegistryClient:   registryClient,
 				RepositoryConfig: settings.RepositoryConfig,
 				RepositoryCache:  settings.RepositoryCache,
 				ContentCache:     settings.ContentCache,
 				Debug:            settings.Debug,
+				Untar:            client.Untar,
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyIfPossible
 			}
-			err = man.Build()
+			if watch {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				err = man.Watch(ctx, watchInterval)
+			} else {
+				err = man.Build()
+			}
 			var e downloader.ErrRepoNotFound
 			if errors.As(err, &e) {
 				return fmt.Errorf("%s. Please add the missing repos via 'helm repo add'", e.Error())
@@ -87,12 +120,22 @@ func newDependencyBuildCmd(out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	addDependencySubcommandFlags(f, client)
+	f.BoolVar(&watch, "watch", false, "rebuild the dependencies whenever a local (file://) dependency changes")
 
 	return cmd
 }
 
-// defaultKeyring returns the expanded path to the default keyring.
+// defaultKeyring returns the expanded path to the default keyring: the keyring
+// managed by 'helm keys' when it has keys, or else the GnuPG public keyring.
 func defaultKeyring() string {
+	if fi, err := os.Stat(settings.Keyring); err == nil && fi.Size() > 0 {
+		return settings.Keyring
+	}
+	return gnupgKeyring()
+}
+
+// gnupgKeyring returns the GnuPG public keyring.
+func gnupgKeyring() string {
 	if v, ok := os.LookupEnv("GNUPGHOME"); ok {
 		return filepath.Join(v, "pubring.gpg")
 	}
@@ -48,6 +48,10 @@ func TestDependencyListCmd(t *testing.T) {
 			name:   "Dependencies in chart archive",
 			cmd:    "dependency list testdata/testcharts/reqtest-0.1.0.tgz",
 			golden: "output/dependency-list-archive.txt",
+		}, {
+			name:   "Dependencies in chart dir as JSON",
+			cmd:    "dependency list testdata/testcharts/reqtest -o json",
+			golden: "output/dependency-list.json",
 		}}
 	runTestCmd(t, tests)
 }
@@ -36,11 +36,17 @@ are present in 'charts/' and are at an acceptable version. It will pull down
 the latest charts that satisfy the dependencies, and clean up old dependencies.
 
 On successful update, this will generate a lock file that can be used to
-rebuild the dependencies to an exact version.
+rebuild the dependencies to an exact version. The lock file also records the
+digests of the downloaded charts, which 'helm dependency build' verifies.
 
 Dependencies are not required to be represented in 'Chart.yaml'. For that
 reason, an update command will not remove charts unless they are (a) present
 in the Chart.yaml file, but (b) at the wrong version.
+
+With '--untar', dependencies are vendored as expanded chart directories under
+'charts/' rather than as archives, so their contents can be reviewed and
+patched in version control. Re-running the command replaces the vendored
+directories, discarding any local changes to them.
 `
 
 // newDependencyUpdateCmd creates a new dependency update command.
@@ -68,13 +74,15 @@ func newDependencyUpdateCmd(_ *action.Configuration, out io.Writer) *cobra.Comma
 				Out:              out,
 				ChartPath:        chartpath,
 				Keyring:          client.Keyring,
-				SkipUpdate:       client.SkipRefresh,
+				TrustPolicy:      settings.TrustPolicy,
+				SkipUpdate:       client.SkipRefresh || settings.Offline,
 				Getters:          getter.All(settings),
 				RegistryClient:   registryClient,
 				RepositoryConfig: settings.RepositoryConfig,
 				RepositoryCache:  settings.RepositoryCache,
 				ContentCache:     settings.ContentCache,
 				Debug:            settings.Debug,
+				Untar:            client.Untar,
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyAlways
@@ -0,0 +1,120 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const dependencyUpgradeDesc = `
+Upgrade the dependencies of a chart to their newest available versions.
+
+The version constraint of each dependency in Chart.yaml is rewritten to the
+newest version in its repository that is allowed by '--constraint':
+
+- patch: only newer patch releases of the current minor version
+- minor: newer minor and patch releases of the current major version
+- major: any newer release
+
+The current version of a dependency is the one recorded in Chart.lock. Only the
+version values in Chart.yaml are changed, keeping its formatting and comments.
+Constraints on a single version, such as '^1.2.0', keep their operator, while
+ranges and wildcards are replaced with the exact version. Pre-releases are only
+considered for dependencies currently at a pre-release.
+
+When any constraint changed, the dependencies are then updated as with
+'helm dependency update', regenerating Chart.lock and the archives in 'charts/'.
+`
+
+func newDependencyUpgradeCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	var policy string
+
+	cmd := &cobra.Command{
+		Use:   "upgrade CHART",
+		Short: "upgrade the dependency versions in Chart.yaml and update charts/",
+		Long:  dependencyUpgradeDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			registryClient, err := newRegistryClient(out, client.CertFile, client.KeyFile, client.CaFile,
+				client.InsecureSkipTLSVerify, client.PlainHTTP, client.Username, client.Password)
+			if err != nil {
+				return fmt.Errorf("missing registry client: %w", err)
+			}
+
+			man := &downloader.Manager{
+				Out:              out,
+				ChartPath:        chartpath,
+				Keyring:          client.Keyring,
+				TrustPolicy:      settings.TrustPolicy,
+				SkipUpdate:       client.SkipRefresh || settings.Offline,
+				Getters:          getter.All(settings),
+				RegistryClient:   registryClient,
+				RepositoryConfig: settings.RepositoryConfig,
+				RepositoryCache:  settings.RepositoryCache,
+				ContentCache:     settings.ContentCache,
+				Debug:            settings.Debug,
+				Untar:            client.Untar,
+			}
+			if client.Verify {
+				man.Verify = downloader.VerifyAlways
+			}
+
+			bumps, err := client.Upgrade(chartpath, policy, man.AvailableVersions)
+			if err != nil {
+				return err
+			}
+			if len(bumps) == 0 {
+				fmt.Fprintf(out, "All dependencies are at the newest %s versions.\n", policy)
+				return nil
+			}
+			for _, b := range bumps {
+				fmt.Fprintf(out, "Upgraded %s from %q to %q\n", b.Name, b.From, b.To)
+			}
+
+			// The repositories were refreshed while looking up the versions.
+			man.SkipUpdate = true
+			return man.Update()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&policy, "constraint", action.BumpMinor, "newest versions allowed, one of patch, minor or major")
+	addDependencySubcommandFlags(f, client)
+
+	err := cmd.RegisterFlagCompletionFunc("constraint", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{action.BumpPatch, action.BumpMinor, action.BumpMajor}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cmd
+}
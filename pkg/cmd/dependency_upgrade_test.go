@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestDependencyUpgradeCmd(t *testing.T) {
+	srv := setupMockRepoServer(t)
+	defer srv.Stop()
+	contentCache := t.TempDir()
+
+	dir := func(p ...string) string {
+		return filepath.Join(append([]string{srv.Root()}, p...)...)
+	}
+
+	chartname := "depupgrade"
+	if err := chartutil.SaveDir(createTestingMetadata(chartname, srv.URL()), dir()); err != nil {
+		t.Fatal(err)
+	}
+	run := func(policy string) string {
+		t.Helper()
+		_, out, err := executeActionCommand(fmt.Sprintf("dependency upgrade '%s' --constraint %s --repository-config %s --repository-cache %s --content-cache %s --plain-http",
+			dir(chartname), policy, dir("repositories.yaml"), dir(), contentCache))
+		if err != nil {
+			t.Logf("Output: %s", out)
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// compressedchart is at 0.1.0, and only 0.2.0 and 0.3.0 are newer.
+	if out := run("patch"); !strings.Contains(out, "All dependencies are at the newest patch versions.") {
+		t.Errorf("Expected no upgrades, got\n%s", out)
+	}
+	if _, err := os.Stat(dir(chartname, "Chart.lock")); err == nil {
+		t.Error("Expected no Chart.lock without upgrades")
+	}
+
+	out := run("minor")
+	if !strings.Contains(out, `Upgraded compressedchart from "0.1.0" to "0.3.0"`) {
+		t.Errorf("Expected compressedchart to be upgraded, got\n%s", out)
+	}
+
+	md, err := chartutil.LoadChartfile(dir(chartname, "Chart.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []*chart.Dependency{
+		{Name: "reqtest", Version: "0.1.0", Repository: srv.URL()},
+		{Name: "compressedchart", Version: "0.3.0", Repository: srv.URL()},
+	}
+	for i, dep := range md.Dependencies {
+		if dep.Name != expect[i].Name || dep.Version != expect[i].Version {
+			t.Errorf("Expected dependency %s %s, got %s %s", expect[i].Name, expect[i].Version, dep.Name, dep.Version)
+		}
+	}
+
+	for _, f := range []string{"Chart.lock", "charts/compressedchart-0.3.0.tgz", "charts/reqtest-0.1.0.tgz"} {
+		if _, err := os.Stat(dir(chartname, f)); err != nil {
+			t.Errorf("Expected %s: %s", f, err)
+		}
+	}
+}
+
+func TestDependencyUpgradeCmdInvalidConstraint(t *testing.T) {
+	_, _, err := executeActionCommand("dependency upgrade testdata/testcharts/reqtest --constraint latest")
+	if err == nil || !strings.Contains(err.Error(), `invalid bump policy "latest"`) {
+		t.Errorf("Expected an invalid bump policy error, got %v", err)
+	}
+}
@@ -19,18 +19,25 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 	"sort"
 
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
 )
 
 var envHelp = `
 Env prints out all the environment information in use by Helm.
+
+With '--output json' or '--output yaml', the variables are printed as a single
+object, to be read by scripts and plugins.
 `
 
 func newEnvCmd(out io.Writer) *cobra.Command {
+	var outfmt output.Format
 	cmd := &cobra.Command{
 		Use:   "env",
 		Short: "helm client environment information",
@@ -44,25 +51,44 @@ func newEnvCmd(out io.Writer) *cobra.Command {
 
 			return noMoreArgsComp()
 		},
-		Run: func(_ *cobra.Command, args []string) {
+		RunE: func(_ *cobra.Command, args []string) error {
 			envVars := settings.EnvVars()
 
-			if len(args) == 0 {
-				// Sort the variables by alphabetical order.
-				// This allows for a constant output across calls to 'helm env'.
-				keys := getSortedEnvVarKeys()
-
-				for _, k := range keys {
-					fmt.Fprintf(out, "%s=\"%s\"\n", k, envVars[k])
+			if len(args) > 0 {
+				if outfmt == output.Table {
+					fmt.Fprintf(out, "%s\n", envVars[args[0]])
+					return nil
 				}
-			} else {
-				fmt.Fprintf(out, "%s\n", envVars[args[0]])
+				envVars = map[string]string{args[0]: envVars[args[0]]}
 			}
+			return outfmt.Write(out, envWriter(envVars))
 		},
 	}
+
+	bindOutputFlag(cmd, &outfmt)
+
 	return cmd
 }
 
+type envWriter map[string]string
+
+func (w envWriter) WriteTable(out io.Writer) error {
+	// Sort the variables by alphabetical order.
+	// This allows for a constant output across calls to 'helm env'.
+	for _, k := range slices.Sorted(maps.Keys(w)) {
+		fmt.Fprintf(out, "%s=\"%s\"\n", k, w[k])
+	}
+	return nil
+}
+
+func (w envWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, map[string]string(w))
+}
+
+func (w envWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, map[string]string(w))
+}
+
 func getSortedEnvVarKeys() []string {
 	envVars := settings.EnvVars()
 
@@ -25,6 +25,14 @@ func TestEnv(t *testing.T) {
 		name:   "completion for env",
 		cmd:    "__complete env ''",
 		golden: "output/env-comp.txt",
+	}, {
+		name:   "single variable in json",
+		cmd:    "env HELM_NAMESPACE -o json",
+		golden: "output/env-json.txt",
+	}, {
+		name:   "single variable in yaml",
+		cmd:    "env HELM_NAMESPACE -o yaml",
+		golden: "output/env-yaml.txt",
 	}}
 	runTestCmd(t, tests)
 }
@@ -0,0 +1,203 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const explainDesc = `
+This command documents the values of a chart (directory, file, or URL), or of
+one of its values keys.
+
+The documentation merges the comments of values.yaml, above or beside each key,
+with the types and descriptions of values.schema.json:
+
+    $ helm explain ./mychart
+    $ helm explain ./mychart image.tag
+
+Use '--markdown' to render the documentation as a Markdown table, for example
+for the README of the chart.
+`
+
+func newExplainCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewShow(action.ShowValues, cfg)
+	var outfmt output.Format
+	var markdown bool
+
+	cmd := &cobra.Command{
+		Use:   "explain [CHART] [KEY]",
+		Short: "show the documentation of the values of a chart",
+		Long:  explainDesc,
+		Args:  require.MinimumNArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return compListCharts(toComplete, true)
+			case 1:
+				keys, directive := compSetFlag(args[0], "")
+				for i, k := range keys {
+					keys[i] = strings.Replace(k, "=", "", 1)
+				}
+				return keys, directive &^ cobra.ShellCompDirectiveNoSpace
+			}
+			return noMoreArgsComp()
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 2 {
+				return fmt.Errorf("%q accepts at most 2 arguments", "helm explain")
+			}
+			if err := addRegistryClient(out, client); err != nil {
+				return err
+			}
+			cp, err := locateShowChart(args[0], client)
+			if err != nil {
+				return err
+			}
+			docs, err := client.ValuesDocs(cp)
+			if err != nil {
+				return err
+			}
+
+			w := &valuesDocsWriter{docs: docs}
+			if len(args) == 2 {
+				w.docs = docs.Lookup(args[1])
+				if w.docs == nil {
+					return fmt.Errorf("key %q not found in the values of %s", args[1], args[0])
+				}
+				w.explain = true
+			}
+			if markdown {
+				return w.WriteMarkdown(out)
+			}
+			return outfmt.Write(out, w)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
+	f.BoolVar(&markdown, "markdown", false, "render the documentation as a Markdown table")
+	addChartPathOptionsFlags(f, &client.ChartPathOptions)
+	bindOutputFlag(cmd, &outfmt)
+	cmd.MarkFlagsMutuallyExclusive("markdown", outputFlag)
+
+	err := cmd.RegisterFlagCompletionFunc("version", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return compVersionFlag(args[0], toComplete)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cmd
+}
+
+// valuesDocsWriter writes the documentation of values. When explain is set,
+// the documented key itself is described before its nested keys.
+type valuesDocsWriter struct {
+	docs    *util.ValueDoc
+	explain bool
+}
+
+func (w *valuesDocsWriter) WriteTable(out io.Writer) error {
+	if w.explain {
+		table := uitable.New()
+		table.Wrap = true
+		table.AddRow("KEY:", w.docs.Key)
+		table.AddRow("TYPE:", w.docs.Type)
+		if w.docs.Required {
+			table.AddRow("REQUIRED:", "true")
+		}
+		if len(w.docs.Children) == 0 {
+			table.AddRow("DEFAULT:", formatValueDefault(w.docs.Default))
+		}
+		table.AddRow("DESCRIPTION:", w.docs.Description)
+		if err := output.EncodeTable(out, table); err != nil {
+			return err
+		}
+		if len(w.docs.Children) == 0 {
+			return nil
+		}
+		fmt.Fprintln(out)
+	}
+
+	table := uitable.New()
+	table.MaxColWidth = 60
+	table.AddRow("KEY", "TYPE", "DEFAULT", "DESCRIPTION")
+	for _, d := range w.docs.All() {
+		table.AddRow(d.Key, d.Type, formatValueDefault(d.Default), d.Description)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *valuesDocsWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.docs)
+}
+
+func (w *valuesDocsWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.docs)
+}
+
+// WriteMarkdown writes the documented keys as a Markdown table.
+func (w *valuesDocsWriter) WriteMarkdown(out io.Writer) error {
+	docs := w.docs.All()
+	if w.explain {
+		docs = append([]*util.ValueDoc{w.docs}, docs...)
+	}
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Description |\n")
+	b.WriteString("|-----|------|---------|-------------|\n")
+	for _, d := range docs {
+		def := formatValueDefault(d.Default)
+		if def != "" {
+			def = "`" + def + "`"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", d.Key, d.Type, escapeMarkdownCell(def), escapeMarkdownCell(d.Description))
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// formatValueDefault formats a default value as compact JSON.
+func formatValueDefault(v any) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestExplainCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "explain all values",
+		cmd:    "explain testdata/testcharts/chart-with-values-docs",
+		golden: "output/explain.txt",
+	}, {
+		name:   "explain a key with children",
+		cmd:    "explain testdata/testcharts/chart-with-values-docs image",
+		golden: "output/explain-key.txt",
+	}, {
+		name:   "explain a leaf key",
+		cmd:    "explain testdata/testcharts/chart-with-values-docs image.tag",
+		golden: "output/explain-leaf.txt",
+	}, {
+		name:   "explain a key as json",
+		cmd:    "explain testdata/testcharts/chart-with-values-docs serviceAccount -o json",
+		golden: "output/explain-key.json",
+	}, {
+		name:   "explain as markdown",
+		cmd:    "explain testdata/testcharts/chart-with-values-docs --markdown",
+		golden: "output/explain.md",
+	}, {
+		name:      "explain a missing key",
+		cmd:       "explain testdata/testcharts/chart-with-values-docs image.missing",
+		golden:    "output/explain-missing.txt",
+		wantError: true,
+	}, {
+		name:   "show values docs",
+		cmd:    "show values testdata/testcharts/chart-with-values-docs --docs",
+		golden: "output/explain.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestExplainCompletion(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "completion for explain keys",
+		cmd:    "__complete explain testdata/testcharts/chart-with-values-docs ''",
+		golden: "output/explain-comp.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestExplainFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "explain", true)
+}
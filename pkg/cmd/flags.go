@@ -17,14 +17,19 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"log/slog"
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -32,12 +37,17 @@ import (
 	"k8s.io/klog/v2"
 
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/chart/loader"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/postrenderer"
+	"helm.sh/helm/v4/pkg/registry"
+	release "helm.sh/helm/v4/pkg/release/v1"
 	"helm.sh/helm/v4/pkg/repo/v1"
 )
 
@@ -48,10 +58,11 @@ const (
 )
 
 func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
-	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
+	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML or JSON file, a URL (http(s)://, oci://, git+https://repo.git//file.yaml?ref=REF; append #sha256=DIGEST to pin and cache the content), or '-' to read from stdin (can specify multiple)")
 	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
-	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
+	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2). A directory sets a map of its files keyed by file name")
+	f.StringArrayVar(&v.EnvPrefixes, "set-env", []string{}, "set values from environment variables starting with the given prefix. The prefix is removed and '__' separates nested keys, e.g. with prefix APP_ the variable APP_image__tag=1.0 sets image.tag")
 	f.StringArrayVar(&v.JSONValues, "set-json", []string{}, "set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2 or using json format: {\"key1\": jsonval1, \"key2\": \"jsonval2\"})")
 	f.StringArrayVar(&v.LiteralValues, "set-literal", []string{}, "set a literal STRING value on the command line")
 }
@@ -85,11 +96,11 @@ func (ws *waitValue) Set(s string) error {
 		*ws = waitValue(s)
 		return nil
 	case "true":
-		slog.Warn("--wait=true is deprecated (boolean value) and can be replaced with --wait=watcher")
+		warnings.Add(action.WarningDeprecatedFlag, "--wait=true is deprecated (boolean value) and can be replaced with --wait=watcher")
 		*ws = waitValue(kube.StatusWatcherStrategy)
 		return nil
 	case "false":
-		slog.Warn("--wait=false is deprecated (boolean value) and can be replaced with --wait=hookOnly")
+		warnings.Add(action.WarningDeprecatedFlag, "--wait=false is deprecated (boolean value) and can be replaced with --wait=hookOnly")
 		*ws = waitValue(kube.HookOnlyStrategy)
 		return nil
 	default:
@@ -101,9 +112,133 @@ func (ws *waitValue) Type() string {
 	return "WaitStrategy"
 }
 
+// addAllowCrossNamespaceFlag adds the flag that allows the resources of a
+// release to be deployed outside of the release namespace. The commands set
+// RestrictNamespace to the negation of allow.
+func addAllowCrossNamespaceFlag(f *pflag.FlagSet, allow *bool) {
+	f.BoolVar(allow, "allow-cross-namespace", true, "allow the chart to deploy namespaced resources to other namespaces than the release namespace. Set to false to fail instead")
+}
+
+// addPhaseTimeoutFlags adds the flags setting the deadlines of the phases of
+// an install or an upgrade.
+func addPhaseTimeoutFlags(f *pflag.FlagSet, t *action.PhaseTimeouts) {
+	f.DurationVar(&t.Hooks, "hook-timeout", 0, "time to wait for each hook. Defaults to --timeout")
+	f.DurationVar(&t.Apply, "apply-timeout", 0, "time to wait for the resources to be created or updated, retries included. Defaults to no deadline")
+	f.DurationVar(&t.Wait, "wait-timeout", 0, "time to wait for the resources to be ready. Defaults to --timeout")
+}
+
+// addRetryFlags adds the flags configuring the retries of the apply of the
+// resources of a release.
+func addRetryFlags(cmd *cobra.Command, p *action.RetryPolicy) {
+	f := cmd.Flags()
+	f.IntVar(&p.Retries, "retry", 0, "number of times to re-apply the resources after a transient error")
+	f.DurationVar(&p.Backoff, "retry-backoff", 2*time.Second, "delay before the first retry, doubled after each retry")
+	p.On = []action.RetryClass{action.RetryAPIServer, action.RetryWebhook}
+	f.Var((*retryOnValue)(&p.On), "retry-on", fmt.Sprintf("classes of errors to retry on, one or more of: %s", strings.Join(action.RetryClassNames(), ", ")))
+
+	err := cmd.RegisterFlagCompletionFunc("retry-on", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return action.RetryClassNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// retryOnValue is a comma separated list of retry classes.
+type retryOnValue []action.RetryClass
+
+func (r *retryOnValue) String() string {
+	names := make([]string, len(*r))
+	for i, c := range *r {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ",")
+}
+
+func (r *retryOnValue) Type() string {
+	return "strings"
+}
+
+func (r *retryOnValue) Set(s string) error {
+	var classes []action.RetryClass
+	for name := range strings.SplitSeq(s, ",") {
+		c, err := action.ParseRetryClass(name)
+		if err != nil {
+			return err
+		}
+		classes = append(classes, c)
+	}
+	*r = classes
+	return nil
+}
+
+// retentionFlags holds the --history-max and --history-max-age flags. The
+// retention is only recorded on the release when one of them is given, so
+// that the retention recorded by a previous operation otherwise applies.
+type retentionFlags struct {
+	maxHistory *int
+	maxAge     time.Duration
+}
+
+func addRetentionFlags(cmd *cobra.Command, maxHistory *int) *retentionFlags {
+	r := &retentionFlags{maxHistory: maxHistory}
+	if r.maxHistory == nil {
+		r.maxHistory = new(int)
+	}
+	f := cmd.Flags()
+	f.IntVar(r.maxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit. When given, it is recorded on the release and applied by later operations")
+	f.Var((*ageValue)(&r.maxAge), "history-max-age", "remove the revisions last deployed longer ago than this age, e.g. 30d or 12h. The newest and the deployed revisions are kept. It is recorded on the release and applied by later operations")
+	return r
+}
+
+// retention returns the retention to record on the release, or nil if
+// neither flag was given.
+func (r *retentionFlags) retention(cmd *cobra.Command) *release.Retention {
+	if !cmd.Flags().Changed("history-max") && !cmd.Flags().Changed("history-max-age") {
+		return nil
+	}
+	return &release.Retention{MaxHistory: *r.maxHistory, MaxAge: r.maxAge}
+}
+
+// ageValue is a duration that also accepts a number of days, such as 30d.
+type ageValue time.Duration
+
+func (a *ageValue) String() string {
+	if *a == 0 {
+		return "0s"
+	}
+	return time.Duration(*a).String()
+}
+
+func (a *ageValue) Type() string {
+	return "duration"
+}
+
+func (a *ageValue) Set(s string) error {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number of days %q", s)
+		}
+		*a = ageValue(time.Duration(n) * 24 * time.Hour)
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid negative age %q", s)
+	}
+	*a = ageValue(d)
+	return nil
+}
+
 func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 	f.StringVar(&c.Version, "version", "", "specify a version constraint for the chart version to use. This constraint can be a specific tag (e.g. 1.1.1) or it may reference a valid range (e.g. ^2.0.0). If this is not specified, the latest version is used")
+	f.StringVar(&c.Channel, "channel", "", "use the latest version of this release channel, such as stable, beta or edge, as tagged by the chart annotation helm.sh/channels")
 	f.BoolVar(&c.Verify, "verify", false, "verify the package before using it")
+	f.BoolVar(&c.RequireDigest, "require-digest", false, "refuse charts that are not pinned to a digest, such as OCI references by tag and repository charts. Local charts are allowed")
 	f.StringVar(&c.Keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
 	f.StringVar(&c.RepoURL, "repo", "", "chart repository url where to locate the requested chart")
 	f.StringVar(&c.Username, "username", "", "chart repository username where to locate the requested chart")
@@ -116,6 +251,29 @@ func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 	f.BoolVar(&c.PassCredentialsAll, "pass-credentials", false, "pass credentials to all domains")
 }
 
+// deprecatedFlagAnnotation holds the deprecation message of a flag marked
+// with markDeprecated.
+const deprecatedFlagAnnotation = "helm.sh/deprecated"
+
+// markDeprecated hides a deprecated flag. Its use raises a warning when the
+// command runs, in place of the message printed by cobra for flags marked with
+// MarkDeprecated, so that it is included in the structured output.
+func markDeprecated(fs *pflag.FlagSet, name, message string) error {
+	if err := fs.SetAnnotation(name, deprecatedFlagAnnotation, []string{message}); err != nil {
+		return err
+	}
+	return fs.MarkHidden(name)
+}
+
+// warnDeprecatedFlags raises a warning for each deprecated flag that is set.
+func warnDeprecatedFlags(fs *pflag.FlagSet) {
+	fs.Visit(func(f *pflag.Flag) {
+		if message, ok := f.Annotations[deprecatedFlagAnnotation]; ok && len(message) > 0 {
+			warnings.Add(action.WarningDeprecatedFlag, fmt.Sprintf("Flag --%s has been deprecated, %s", f.Name, message[0]))
+		}
+	})
+}
+
 // bindOutputFlag will add the output flag to the given command and bind the
 // value to the given format pointer
 func bindOutputFlag(cmd *cobra.Command, varRef *output.Format) {
@@ -138,6 +296,30 @@ func bindOutputFlag(cmd *cobra.Command, varRef *output.Format) {
 	}
 }
 
+// bindWideOutputFlag is like bindOutputFlag, with the wide format allowed
+// too.
+func bindWideOutputFlag(cmd *cobra.Command, varRef *output.Format) {
+	formats := append(output.Formats(), output.Wide.String())
+	cmd.Flags().VarP(&wideOutputValue{newOutputValue(output.Table, varRef)}, outputFlag, "o",
+		"prints the output in the specified format. Allowed values: "+strings.Join(formats, ", "))
+
+	err := cmd.RegisterFlagCompletionFunc(outputFlag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		var formatNames []string
+		for format, desc := range output.FormatsWithDesc() {
+			formatNames = append(formatNames, fmt.Sprintf("%s\t%s", format, desc))
+		}
+		formatNames = append(formatNames, output.Wide.String()+"\tOutput result in human-readable format with additional columns")
+
+		// Sort the results to get a deterministic order for the tests
+		sort.Strings(formatNames)
+		return formatNames, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
 type outputValue output.Format
 
 func newOutputValue(defaultValue output.Format, p *output.Format) *outputValue {
@@ -165,6 +347,19 @@ func (o *outputValue) Set(s string) error {
 	return nil
 }
 
+// wideOutputValue is an outputValue accepting the wide format.
+type wideOutputValue struct {
+	*outputValue
+}
+
+func (o *wideOutputValue) Set(s string) error {
+	if s == output.Wide.String() {
+		*o.outputValue = outputValue(output.Wide)
+		return nil
+	}
+	return o.outputValue.Set(s)
+}
+
 // TODO there is probably a better way to pass cobra settings than as a param
 func bindPostRenderFlag(cmd *cobra.Command, varRef *postrenderer.PostRenderer, settings *cli.EnvSettings) {
 	p := &postRendererOptions{varRef, "", []string{}, settings}
@@ -250,6 +445,10 @@ func (p *postRendererArgsSlice) GetSlice() []string {
 }
 
 func compVersionFlag(chartRef string, _ string) ([]string, cobra.ShellCompDirective) {
+	if registry.IsOCI(chartRef) {
+		return compOCITags(chartRef), cobra.ShellCompDirectiveNoFileComp
+	}
+
 	chartInfo := strings.Split(chartRef, "/")
 	if len(chartInfo) != 2 {
 		return nil, cobra.ShellCompDirectiveNoFileComp
@@ -284,6 +483,156 @@ func compVersionFlag(chartRef string, _ string) ([]string, cobra.ShellCompDirect
 	return versions, cobra.ShellCompDirectiveNoFileComp
 }
 
+// compOCITags provides the semver tags of an OCI repository, from the highest
+// to the lowest version.
+func compOCITags(ref string) []string {
+	ref = strings.TrimPrefix(ref, registry.OCIScheme+"://")
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	client, err := registry.NewClient(registry.ClientOptCredentialsFile(settings.RegistryConfig))
+	if err != nil {
+		return nil
+	}
+	tags, err := client.Tags(ref)
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("Unable to list the tags of %s: %v", ref, err), settings.Debug)
+		return nil
+	}
+	return tags
+}
+
+// registerSetFlagCompletion completes the values keys of a local chart, found
+// with chartArg among the command arguments, for the flags setting values.
+func registerSetFlagCompletion(cmd *cobra.Command, chartArg func(args []string) string) {
+	for _, name := range []string{"set", "set-string", "set-json", "set-literal"} {
+		err := cmd.RegisterFlagCompletionFunc(name, func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			chartPath := chartArg(args)
+			if chartPath == "" {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compSetFlag(chartPath, toComplete)
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// compSetFlag provides the values keys of a local chart and of its subcharts,
+// found in its values.yaml and described by its values.schema.json, to
+// complete the key of the last key=value pair of a --set flag.
+func compSetFlag(chartPath string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		toComplete = toComplete[i+1:]
+	}
+	if strings.Contains(toComplete, "=") {
+		// The key is complete, the value cannot be completed.
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if _, err := os.Stat(chartPath); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("Unable to load the chart %s: %v", chartPath, err), settings.Debug)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	keys := map[string]string{}
+	collectValuesKeys(ch, "", keys)
+	var completions []string
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		completion := prefix + key + "="
+		if desc := keys[key]; desc != "" {
+			completion += "\t" + desc
+		}
+		completions = append(completions, completion)
+	}
+	return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// collectValuesKeys collects the values keys of a chart and its subcharts,
+// with their descriptions.
+func collectValuesKeys(ch chart.Charter, prefix string, keys map[string]string) {
+	ac, err := chart.NewAccessor(ch)
+	if err != nil {
+		return
+	}
+	collectMapKeys(ac.Values(), prefix, keys)
+	if data := ac.Schema(); len(data) > 0 {
+		var schema map[string]any
+		if err := json.Unmarshal(data, &schema); err == nil {
+			collectSchemaKeys(schema, schema, prefix, keys, 0)
+		}
+	}
+
+	aliases := map[string]string{}
+	for _, dep := range ac.MetaDependencies() {
+		if da, err := chart.NewDependencyAccessor(dep); err == nil && da.Alias() != "" {
+			aliases[da.Name()] = da.Alias()
+		}
+	}
+	for _, dep := range ac.Dependencies() {
+		dac, err := chart.NewAccessor(dep)
+		if err != nil {
+			continue
+		}
+		name := dac.Name()
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		collectValuesKeys(dep, prefix+escapeValuesKey(name)+".", keys)
+	}
+}
+
+// collectSchemaKeys collects the leaf properties of a JSON schema, resolving
+// the local references.
+func collectSchemaKeys(root, node map[string]any, prefix string, keys map[string]string, depth int) {
+	// Guard against recursive schemas.
+	if depth > 16 {
+		return
+	}
+	properties, _ := util.ResolveSchemaRef(root, node)["properties"].(map[string]any)
+	for name, property := range properties {
+		p, ok := property.(map[string]any)
+		if !ok {
+			continue
+		}
+		p = util.ResolveSchemaRef(root, p)
+		key := prefix + escapeValuesKey(name)
+		if _, ok := p["properties"].(map[string]any); ok {
+			collectSchemaKeys(root, p, key+".", keys, depth+1)
+			continue
+		}
+		desc, _ := p["description"].(string)
+		desc, _, _ = strings.Cut(desc, "\n")
+		keys[key] = strings.ReplaceAll(desc, "\t", " ")
+	}
+}
+
+// collectMapKeys collects the leaf keys of values.
+func collectMapKeys(vals map[string]any, prefix string, keys map[string]string) {
+	for name, v := range vals {
+		key := prefix + escapeValuesKey(name)
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			collectMapKeys(m, key+".", keys)
+			continue
+		}
+		keys[key] = ""
+	}
+}
+
+// escapeValuesKey escapes the dots of a values key for --set.
+func escapeValuesKey(key string) string {
+	return strings.ReplaceAll(key, ".", "\\.")
+}
+
 // addKlogFlags adds flags from k8s.io/klog
 // marks the flags as hidden to avoid polluting the help text
 func addKlogFlags(fs *pflag.FlagSet) {
@@ -121,3 +121,32 @@ func TestPostRendererFlagSetOnce(t *testing.T) {
 	err = str.Set("cat")
 	require.Error(t, err)
 }
+
+func TestAgeValue(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "12h", want: 12 * time.Hour},
+		{in: "0", want: 0},
+		{in: "-1d", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "xd", wantErr: true},
+		{in: "month", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var a ageValue
+			err := a.Set(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, time.Duration(a))
+		})
+	}
+}
@@ -30,10 +30,14 @@ import (
 var getAllHelp = `
 This command prints a human readable collection of information about the
 notes, hooks, supplied values, and generated manifest file of the given release.
+
+The values of the data and stringData fields of Secrets are masked, unless
+--show-secrets is set.
 `
 
 func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var template string
+	var showSecrets bool
 	client := action.NewGet(cfg)
 
 	cmd := &cobra.Command{
@@ -56,14 +60,22 @@ func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				data := map[string]any{
 					"Release": res,
 				}
+				if !showSecrets {
+					rel, err := releaserToV1Release(res)
+					if err != nil {
+						return err
+					}
+					data["Release"] = redactRelease(rel)
+				}
 				return tpl(template, data, out)
 			}
 			return output.Table.Write(out, &statusPrinter{
-				release:      res,
-				debug:        true,
-				showMetadata: true,
-				hideNotes:    false,
-				noColor:      settings.ShouldDisableColor(),
+				release:       res,
+				debug:         true,
+				showMetadata:  true,
+				hideNotes:     false,
+				noColor:       settings.ShouldDisableColor(),
+				redactSecrets: !showSecrets,
 			})
 		},
 	}
@@ -81,6 +93,7 @@ func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f.StringVar(&template, "template", "", "go template for formatting the output, eg: {{.Release.Name}}")
+	f.BoolVar(&showSecrets, "show-secrets", false, "show the data of Secrets in the manifests and the sensitive values instead of masking them")
 
 	return cmd
 }
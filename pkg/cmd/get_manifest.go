@@ -22,10 +22,12 @@ import (
 	"log"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/release"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
 )
 
 var getManifestHelp = `
@@ -34,9 +36,21 @@ This command fetches the generated manifest for a given release.
 A manifest is a YAML-encoded representation of the Kubernetes resources that
 were generated from this release's chart(s). If a chart is dependent on other
 charts, those resources will also be included in the manifest.
+
+The values of the data and stringData fields of Secrets are masked, unless
+--show-secrets is set.
+
+The manifest can be narrowed down to some of its resources with --kind, --name
+and --selector, for example:
+
+    $ helm get manifest my-release --kind Deployment --name web
+    $ helm get manifest my-release --selector app=web
 `
 
 func newGetManifestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var showSecrets bool
+	var filter releaseutil.ManifestFilter
+	var selector string
 	client := action.NewGet(cfg)
 
 	cmd := &cobra.Command{
@@ -51,6 +65,13 @@ func newGetManifestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if selector != "" {
+				sel, err := labels.Parse(selector)
+				if err != nil {
+					return fmt.Errorf("invalid --selector %q: %w", selector, err)
+				}
+				filter.Selector = sel
+			}
 			res, err := client.Run(args[0])
 			if err != nil {
 				return err
@@ -59,12 +80,26 @@ func newGetManifestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 			if err != nil {
 				return err
 			}
-			fmt.Fprintln(out, rac.Manifest())
+			manifest := rac.Manifest()
+			if filter.Kind != "" || filter.Name != "" || filter.Selector != nil {
+				manifest = filter.Filter(manifest)
+				if manifest == "" {
+					return fmt.Errorf("release %q has no resources matching the given filters", args[0])
+				}
+			}
+			if !showSecrets {
+				manifest = releaseutil.RedactSecrets(manifest)
+			}
+			fmt.Fprintln(out, manifest)
 			return nil
 		},
 	}
 
 	cmd.Flags().IntVar(&client.Version, "revision", 0, "get the named release with revision")
+	cmd.Flags().StringVar(&filter.Kind, "kind", "", "only show resources of this kind (e.g. Deployment)")
+	cmd.Flags().StringVar(&filter.Name, "name", "", "only show resources with this name")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "only show resources matching this label selector, supports '=', '==', '!=', 'in' and 'notin' (e.g. -l app=web)")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "show the data of Secrets in the manifest instead of masking it")
 	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 1 {
 			return compListRevisions(toComplete, cfg, args[0])
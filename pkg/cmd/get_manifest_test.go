@@ -23,11 +23,43 @@ import (
 )
 
 func TestGetManifest(t *testing.T) {
+	secretRelease := func() []*release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "juno"})
+		rel.Manifest = mockSecretManifest
+		return []*release.Release{rel}
+	}
 	tests := []cmdTestCase{{
 		name:   "get manifest with release",
 		cmd:    "get manifest juno",
 		golden: "output/get-manifest.txt",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "juno"})},
+	}, {
+		name:   "get manifest with the data of a Secret masked",
+		cmd:    "get manifest juno",
+		golden: "output/get-manifest-redacted.txt",
+		rels:   secretRelease(),
+	}, {
+		name:   "get manifest with the data of a Secret shown",
+		cmd:    "get manifest juno --show-secrets",
+		golden: "output/get-manifest-show-secrets.txt",
+		rels:   secretRelease(),
+	}, {
+		name:   "get manifest filtered by kind and name",
+		cmd:    "get manifest juno --kind secret --name credentials",
+		golden: "output/get-manifest-filtered.txt",
+		rels:   secretRelease(),
+	}, {
+		name:      "get manifest with no resources matching the filters",
+		cmd:       "get manifest juno --kind Deployment",
+		golden:    "output/get-manifest-no-match.txt",
+		rels:      secretRelease(),
+		wantError: true,
+	}, {
+		name:      "get manifest with an invalid selector",
+		cmd:       "get manifest juno --selector 'app in'",
+		golden:    "output/get-manifest-invalid-selector.txt",
+		rels:      secretRelease(),
+		wantError: true,
 	}, {
 		name:      "get manifest without args",
 		cmd:       "get manifest",
@@ -37,6 +69,18 @@ func TestGetManifest(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+const mockSecretManifest = `---
+# Source: secret/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  password: aHVudGVyMg==
+stringData:
+  username: admin
+`
+
 func TestGetManifestCompletion(t *testing.T) {
 	checkReleaseCompletion(t, "get manifest", false)
 }
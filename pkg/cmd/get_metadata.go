@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	k8sLabels "k8s.io/apimachinery/pkg/labels"
@@ -98,10 +100,30 @@ func (w metadataWriter) WriteTable(out io.Writer) error {
 	_, _ = fmt.Fprintf(out, "LABELS: %v\n", k8sLabels.Set(w.metadata.Labels).String())
 	_, _ = fmt.Fprintf(out, "DEPENDENCIES: %v\n", w.metadata.FormattedDepNames())
 	_, _ = fmt.Fprintf(out, "NAMESPACE: %v\n", w.metadata.Namespace)
+	if len(w.metadata.Needs) > 0 {
+		_, _ = fmt.Fprintf(out, "NEEDS: %v\n", strings.Join(w.metadata.Needs, ","))
+	}
+	if len(w.metadata.Namespaces) > 0 {
+		_, _ = fmt.Fprintf(out, "RESOURCE_NAMESPACES: %v\n", strings.Join(w.metadata.Namespaces, ","))
+	}
 	_, _ = fmt.Fprintf(out, "REVISION: %v\n", w.metadata.Revision)
 	_, _ = fmt.Fprintf(out, "STATUS: %v\n", w.metadata.Status)
 	_, _ = fmt.Fprintf(out, "DEPLOYED_AT: %v\n", w.metadata.DeployedAt)
 	_, _ = fmt.Fprintf(out, "APPLY_METHOD: %v\n", formatApplyMethod(w.metadata.ApplyMethod))
+	if s := w.metadata.ChartSource; s != nil {
+		_, _ = fmt.Fprintf(out, "CHART_SOURCE: %v\n", s.Ref)
+		if s.Digest != "" {
+			_, _ = fmt.Fprintf(out, "CHART_DIGEST: %v\n", s.Digest)
+		}
+		if s.ManifestDigest != "" {
+			_, _ = fmt.Fprintf(out, "MANIFEST_DIGEST: %v\n", s.ManifestDigest)
+		}
+		if v := s.Verification; v != nil {
+			_, _ = fmt.Fprintf(out, "VERIFICATION_METHOD: %v\n", v.Method)
+			_, _ = fmt.Fprintf(out, "VERIFIED_BY: %v\n", strings.Join(v.Signers, ","))
+			_, _ = fmt.Fprintf(out, "VERIFIED_AT: %v\n", v.Time.Format(time.RFC3339))
+		}
+	}
 
 	return nil
 }
@@ -18,7 +18,9 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
+	"helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
 )
 
@@ -44,10 +46,44 @@ func TestGetMetadataCmd(t *testing.T) {
 		cmd:    "get metadata thomas-guide --output yaml",
 		golden: "output/get-metadata.yaml",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Labels: map[string]string{"key1": "value1"}})},
+	}, {
+		name:   "get metadata with a chart source",
+		cmd:    "get metadata thomas-guide",
+		golden: "output/get-metadata-chart-source.txt",
+		rels:   []*release.Release{releaseWithChartSource()},
+	}, {
+		name:   "get metadata with a verified chart",
+		cmd:    "get metadata thomas-guide",
+		golden: "output/get-metadata-verified.txt",
+		rels:   []*release.Release{releaseWithVerifiedChart()},
+	}, {
+		name:   "get metadata with a verified chart to json",
+		cmd:    "get metadata thomas-guide --output json",
+		golden: "output/get-metadata-verified.json",
+		rels:   []*release.Release{releaseWithVerifiedChart()},
 	}}
 	runTestCmd(t, tests)
 }
 
+func releaseWithChartSource() *release.Release {
+	rel := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})
+	rel.ChartSource = &common.ChartSource{
+		Ref:    "oci://registry.example.com/charts/foo",
+		Digest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	}
+	return rel
+}
+
+func releaseWithVerifiedChart() *release.Release {
+	rel := releaseWithChartSource()
+	rel.ChartSource.Verification = &common.ChartVerification{
+		Method:  common.VerificationPGPDetached,
+		Signers: []string{"5E615389B53CA37F0EE60BD3843BBF981FC18762"},
+		Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	return rel
+}
+
 func TestGetMetadataCompletion(t *testing.T) {
 	checkReleaseCompletion(t, "get metadata", false)
 }
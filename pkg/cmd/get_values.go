@@ -30,6 +30,9 @@ import (
 
 var getValuesHelp = `
 This command downloads a values file for a given release.
+
+The values that the schema of the chart marks as sensitive, with
+"x-helm-sensitive": true, are redacted unless --show-sensitive is set.
 `
 
 type valuesWriter struct {
@@ -39,6 +42,7 @@ type valuesWriter struct {
 
 func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var outfmt output.Format
+	var showSensitive bool
 	client := action.NewGetValues(cfg)
 
 	cmd := &cobra.Command{
@@ -53,6 +57,7 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			client.RedactSensitive = !showSensitive
 			vals, err := client.Run(args[0])
 			if err != nil {
 				return err
@@ -75,6 +80,7 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f.BoolVarP(&client.AllValues, "all", "a", false, "dump all (computed) values")
+	f.BoolVar(&showSensitive, "show-sensitive", false, "show the values the chart schema marks as sensitive instead of redacting them")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -23,6 +23,11 @@ import (
 )
 
 func TestGetValuesCmd(t *testing.T) {
+	sensitiveRelease := func() []*release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})
+		rel.Chart.Schema = []byte(`{"properties": {"name": {"type": "string", "x-helm-sensitive": true}}}`)
+		return []*release.Release{rel}
+	}
 	tests := []cmdTestCase{{
 		name:   "get values with a release",
 		cmd:    "get values thomas-guide",
@@ -39,6 +44,16 @@ func TestGetValuesCmd(t *testing.T) {
 		cmd:    "get values thomas-guide --all",
 		golden: "output/get-values-all.txt",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:   "get values with sensitive values redacted",
+		cmd:    "get values thomas-guide --all",
+		golden: "output/get-values-sensitive.txt",
+		rels:   sensitiveRelease(),
+	}, {
+		name:   "get values with sensitive values shown",
+		cmd:    "get values thomas-guide --all --show-sensitive",
+		golden: "output/get-values-all.txt",
+		rels:   sensitiveRelease(),
 	}, {
 		name:   "get values to json",
 		cmd:    "get values thomas-guide --output json",
@@ -17,9 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"log/slog"
+	"io"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
@@ -48,7 +52,7 @@ func cmdGetDryRunFlagStrategy(cmd *cobra.Command, isTemplate bool) (action.DryRu
 
 	switch v {
 	case f.NoOptDefVal:
-		slog.Warn(`--dry-run is deprecated and should be replaced with '--dry-run=client'`)
+		warnings.Add(action.WarningDeprecatedFlag, `--dry-run is deprecated and should be replaced with '--dry-run=client'`)
 		return action.DryRunClient, nil
 	case string(action.DryRunClient):
 		return action.DryRunClient, nil
@@ -76,7 +80,36 @@ func cmdGetDryRunFlagStrategy(cmd *cobra.Command, isTemplate bool) (action.DryRu
 	if b {
 		result = action.DryRunClient
 	}
-	slog.Warn(fmt.Sprintf(`boolean '--dry-run=%v' flag is deprecated and must be replaced with '--dry-run=%s'`, v, result))
+	warnings.Add(action.WarningDeprecatedFlag, fmt.Sprintf(`boolean '--dry-run=%v' flag is deprecated and must be replaced with '--dry-run=%s'`, v, result))
 
 	return result, nil
 }
+
+// cancelOnSignal returns a context that is cancelled when the process
+// receives SIGINT or SIGTERM, so that the release being installed or upgraded
+// is marked as failed, and rolled back with --rollback-on-failure, instead of
+// being left pending. A second signal terminates the process right away. stop
+// releases the signal handler once the operation returned.
+func cancelOnSignal(out io.Writer, name string) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	// Set up channel on which to send signal notifications.
+	// We must use a buffered channel or risk missing the signal
+	// if we're not ready to receive when the signal is sent.
+	cSignal := make(chan os.Signal, 2)
+	signal.Notify(cSignal, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-cSignal:
+			// Restore the default handling of the signals for the cleanup.
+			signal.Stop(cSignal)
+			fmt.Fprintf(out, "Release %s has been cancelled. Cleaning up, send %s again to exit right away.\n", name, sig)
+			cancel(fmt.Errorf("received %s: %w", sig, context.Canceled))
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(cSignal)
+		cancel(nil)
+	}
+}
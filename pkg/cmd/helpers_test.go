@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"log/slog"
@@ -77,7 +78,10 @@ func runTestCmd(t *testing.T, tests []cmdTestCase) {
 }
 
 func storageFixture() *storage.Storage {
-	return storage.Init(driver.NewMemory())
+	s := storage.Init(driver.NewMemory())
+	// Sensitive values are only stored encrypted.
+	s.KeyProvider, _ = storage.NewLocalKeyProvider(bytes.Repeat([]byte{1}, 32))
+	return s
 }
 
 func executeActionCommandC(store *storage.Storage, cmd string) (*cobra.Command, string, error) {
@@ -273,6 +277,7 @@ func TestCmdGetDryRunFlagStrategy(t *testing.T) {
 	}
 
 	for name, tc := range testCases {
+		warnings.Reset()
 		logBuf := new(bytes.Buffer)
 		logger := slog.New(slog.NewJSONHandler(logBuf, nil))
 		slog.SetDefault(logger)
@@ -305,3 +310,24 @@ func TestCmdGetDryRunFlagStrategy(t *testing.T) {
 		})
 	}
 }
+
+func TestCancelOnSignal(t *testing.T) {
+	var out bytes.Buffer
+	ctx, stop := cancelOnSignal(&out, "myrelease")
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send an interrupt to the process: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the context was not cancelled on interrupt")
+	}
+	assert.ErrorIs(t, context.Cause(ctx), context.Canceled)
+	assert.EqualError(t, context.Cause(ctx), "received interrupt: context canceled")
+	assert.Equal(t, "Release myrelease has been cancelled. Cleaning up, send interrupt again to exit right away.\n", out.String())
+}
@@ -22,6 +22,7 @@ import (
 	"io"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gosuri/uitable"
@@ -58,12 +59,22 @@ Use '--show-rollback-revision' to include a column showing the revision that was
     2           Mon Oct 3 10:15:13 2016     superseded      alpine-0.1.0      1.0                          Upgraded successfully
     3           Mon Oct 3 10:15:13 2016     superseded      alpine-0.1.0      1.0             2            Rolled back to 2
     4           Mon Oct 3 10:15:13 2016     deployed        alpine-0.1.0      1.0                          Upgraded successfully
+
+Use '--changelog' to show what changed in every revision: the chart version and
+the keys of the user supplied values that were added, changed or removed:
+
+    $ helm history angry-bird --changelog
+    REVISION    UPDATED                     CHART                   VALUES                          DESCRIPTION
+    1           Mon Oct 3 10:15:13 2016     alpine-0.1.0            added: image.tag                Initial install
+    2           Mon Oct 3 10:15:13 2016     0.1.0 -> 0.2.0          changed: image.tag              Upgraded successfully
+    3           Mon Oct 3 10:15:13 2016     0.2.0 -> 0.1.0          changed: image.tag              Rolled back to 1
 `
 
 func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewHistory(cfg)
 	var outfmt output.Format
 	var showRollback bool
+	var changelog bool
 
 	cmd := &cobra.Command{
 		Use:     "history RELEASE_NAME",
@@ -78,6 +89,14 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if changelog {
+				entries, err := getChangelog(client, args[0])
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, entries)
+			}
+
 			history, err := getHistory(client, args[0])
 			if err != nil {
 				return err
@@ -93,6 +112,7 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.IntVar(&client.Max, "max", 256, "maximum number of revision to include in history")
 	f.BoolVar(&showRollback, "show-rollback-revision", false, "show the rollback revision column in table output")
+	f.BoolVar(&changelog, "changelog", false, "show the chart version and values changes of every revision")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -224,17 +244,11 @@ func (r releaseHistoryWithRollback) WriteTable(out io.Writer) error {
 }
 
 func getHistory(client *action.History, name string) (releaseHistory, error) {
-	histi, err := client.Run(name)
-	if err != nil {
-		return nil, err
-	}
-	hist, err := releaseListToV1List(histi)
+	hist, err := getHistoryReleases(client, name)
 	if err != nil {
 		return nil, err
 	}
 
-	releaseutil.Reverse(hist, releaseutil.SortByRevision)
-
 	var rels []*release.Release
 	for i := 0; i < min(len(hist), client.Max); i++ {
 		rels = append(rels, hist[i])
@@ -249,6 +263,95 @@ func getHistory(client *action.History, name string) (releaseHistory, error) {
 	return releaseHistory, nil
 }
 
+// getHistoryReleases returns the revisions of the release, newest first.
+func getHistoryReleases(client *action.History, name string) ([]*release.Release, error) {
+	histi, err := client.Run(name)
+	if err != nil {
+		return nil, err
+	}
+	hist, err := releaseListToV1List(histi)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseutil.Reverse(hist, releaseutil.SortByRevision)
+	return hist, nil
+}
+
+type changelogEntry struct {
+	Revision    int       `json:"revision"`
+	Updated     time.Time `json:"updated,omitzero"`
+	Chart       string    `json:"chart"`
+	Description string    `json:"description"`
+	releaseutil.Changes
+}
+
+type releaseChangelog []changelogEntry
+
+func (r releaseChangelog) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, r)
+}
+
+func (r releaseChangelog) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, r)
+}
+
+func (r releaseChangelog) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("REVISION", "UPDATED", "CHART", "VALUES", "DESCRIPTION")
+	for _, item := range r {
+		chart := item.Chart
+		if item.FromChartVersion != "" && item.ChartChanged() {
+			chart = fmt.Sprintf("%s -> %s", item.FromChartVersion, item.ToChartVersion)
+		}
+		tbl.AddRow(item.Revision, item.Updated.Format(time.ANSIC), chart, formatValuesChanges(item.Changes), item.Description)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func formatValuesChanges(c releaseutil.Changes) string {
+	if !c.ValuesChanged() {
+		return "-"
+	}
+	var parts []string
+	for _, p := range []struct {
+		name string
+		keys []string
+	}{{"added", c.AddedValues}, {"changed", c.ChangedValues}, {"removed", c.RemovedValues}} {
+		if len(p.keys) > 0 {
+			parts = append(parts, p.name+": "+strings.Join(p.keys, ", "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func getChangelog(client *action.History, name string) (releaseChangelog, error) {
+	hist, err := getHistoryReleases(client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	changelog := releaseChangelog{}
+	for i := min(len(hist), client.Max) - 1; i >= 0; i-- {
+		r := hist[i]
+		var previous *release.Release
+		if i+1 < len(hist) {
+			previous = hist[i+1]
+		}
+		entry := changelogEntry{
+			Revision:    r.Version,
+			Chart:       formatChartName(r.Chart),
+			Description: r.Info.Description,
+			Changes:     releaseutil.CompareReleases(previous, r),
+		}
+		if !r.Info.LastDeployed.IsZero() {
+			entry.Updated = r.Info.LastDeployed
+		}
+		changelog = append(changelog, entry)
+	}
+	return changelog, nil
+}
+
 func getReleaseHistory(rls []*release.Release) (history releaseHistory) {
 	for _, v := range slices.Backward(rls) {
 		r := v
@@ -77,6 +77,44 @@ func TestHistoryCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestHistoryChangelog(t *testing.T) {
+	mk := func(vers int, chartVersion string, config map[string]any, description string) *release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{
+			Name:    "angry-bird",
+			Version: vers,
+			Status:  common.StatusSuperseded,
+		})
+		rel.Chart.Metadata.Version = chartVersion
+		rel.Config = config
+		rel.Info.Description = description
+		return rel
+	}
+	rels := []*release.Release{
+		mk(1, "0.1.0", map[string]any{"image": map[string]any{"tag": "1.0"}}, "Install complete"),
+		mk(2, "0.2.0", map[string]any{"image": map[string]any{"tag": "1.0"}}, "Upgrade complete"),
+		mk(3, "0.2.0", map[string]any{"image": map[string]any{"tag": "1.1"}, "replicas": 2}, "Upgrade complete"),
+		mk(4, "0.1.0", map[string]any{"image": map[string]any{"tag": "1.0"}}, "Rollback to 1"),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "get changelog for release",
+		cmd:    "history angry-bird --changelog",
+		rels:   rels,
+		golden: "output/history-changelog.txt",
+	}, {
+		name:   "get changelog with max limit set",
+		cmd:    "history angry-bird --changelog --max 2",
+		rels:   rels,
+		golden: "output/history-changelog-limit.txt",
+	}, {
+		name:   "get changelog with json output format",
+		cmd:    "history angry-bird --changelog --output json",
+		rels:   rels,
+		golden: "output/history-changelog.json",
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestHistoryWithRollback(t *testing.T) {
 	date := time.Unix(242085845, 0).UTC()
 	ch := &chart.Chart{
@@ -17,19 +17,17 @@ limitations under the License.
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/schema"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/loader"
@@ -51,7 +49,8 @@ To override values in a chart, use either the '--values' flag and pass in a file
 or use the '--set' flag and pass configuration from the command line, to force
 a string value use '--set-string'. You can use '--set-file' to set individual
 values from a file when the value itself is too long for the command line
-or is dynamically generated. You can also use '--set-json' to set json values
+or is dynamically generated. Passing a directory to '--set-file' sets a map of
+the files in it, keyed by file name. You can also use '--set-json' to set json values
 (scalars/objects/arrays) from the command line. Additionally, you can use '--set-json' and passing json object as a string.
 
     $ helm install -f myvalues.yaml myredis ./redis
@@ -68,6 +67,10 @@ or
 
     $ helm install --set-file my_script=dothings.sh myredis ./redis
 
+or
+
+    $ helm install --set-file config=./conf/ myredis ./redis
+
 or
 
     $ helm install --set-json 'master.sidecars=[{"name":"sidecar","image":"myImage","imagePullPolicy":"Always","ports":[{"name":"portname","containerPort":1234}]}]' myredis ./redis
@@ -115,6 +118,11 @@ There are six different ways you can express the chart you want to install:
 5. By chart reference and repo url: helm install --repo https://example.com/charts/ mynginx nginx
 6. By OCI registries: helm install mynginx --version 1.2.3 oci://example.com/charts/nginx
 
+An OCI chart can be pinned to the digest of its manifest, as in
+'oci://example.com/charts/nginx@sha256:<digest>'. The digest is recorded in the
+release, see 'helm get metadata'. With --require-digest, charts that are not
+pinned to a digest are refused, apart from local charts.
+
 CHART REFERENCES
 
 A chart reference is a convenient way of referencing a chart in a chart repository.
@@ -133,6 +141,8 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewInstall(cfg)
 	valueOpts := &values.Options{}
 	var outfmt output.Format
+	var retention *retentionFlags
+	var allowCrossNamespace bool
 
 	cmd := &cobra.Command{
 		Use:   "install [NAME] [CHART]",
@@ -155,8 +165,10 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return err
 			}
 			client.DryRunStrategy = dryRunStrategy
+			client.Retention = retention.retention(cmd)
+			client.RestrictNamespace = !allowCrossNamespace
 
-			rel, err := runInstall(args, client, valueOpts, out)
+			rel, err := runInstall(args, client, valueOpts, out, true)
 			if err != nil {
 				return fmt.Errorf("INSTALLATION FAILED: %w", err)
 			}
@@ -167,16 +179,24 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				showMetadata: false,
 				hideNotes:    client.HideNotes,
 				noColor:      settings.ShouldDisableColor(),
+				warnings:     warnings.List(),
 			})
 		},
 	}
 
 	f := cmd.Flags()
 	addInstallFlags(cmd, f, client, valueOpts)
+	addAllowCrossNamespaceFlag(f, &allowCrossNamespace)
 	// hide-secret is not available in all places the install flags are used so
 	// it is added separately
 	f.BoolVar(&client.HideSecret, "hide-secret", false, "hide Kubernetes Secrets when also using the --dry-run flag")
+	// wait-for-endpoints needs the installed resources, which 'helm template'
+	// does not have.
+	f.BoolVar(&client.WaitForEndpoints, "wait-for-endpoints", false, "once installed, wait until the LoadBalancer Services and Ingresses of the release have an address, and show them. The notes can use them as .Release.Endpoints. It shares the --timeout of the wait for the resources")
 	addDryRunFlag(cmd)
+	addRetryFlags(cmd, &client.Retry)
+	retention = addRetentionFlags(cmd, nil)
+	f.StringVar(&client.IdempotencyKey, "idempotency-key", "", "record this key with the release. If the deployed revision was created with the same key, or one is still pending, wait for it and return it instead of installing again")
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 
@@ -187,42 +207,48 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.BoolVar(&client.CreateNamespace, "create-namespace", false, "create the release namespace if not present")
 	f.BoolVar(&client.ForceReplace, "force-replace", false, "force resource updates by replacement")
 	f.BoolVar(&client.ForceReplace, "force", false, "deprecated")
-	f.MarkDeprecated("force", "use --force-replace instead")
+	markDeprecated(f, "force", "use --force-replace instead")
 	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
 	f.BoolVar(&client.ServerSideApply, "server-side", true, "object updates run in the server instead of the client")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during install")
 	f.BoolVar(&client.Replace, "replace", false, "reuse the given name, only if that name is a deleted release which remains in the history. This is unsafe in production")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addPhaseTimeoutFlags(f, &client.PhaseTimeouts)
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVarP(&client.GenerateName, "generate-name", "g", false, "generate the name (and omit the NAME parameter)")
-	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release")
+	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release, e.g. '{{chart}}-{{randAlpha 5 | lower}}'. The name is re-rendered if it is already in use")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the installation process will not validate rendered templates against the Kubernetes OpenAPI Schema")
 	f.BoolVar(&client.RollbackOnFailure, "rollback-on-failure", false, "if set, Helm will rollback (uninstall) the installation upon failure. The --wait flag will be default to \"watcher\" if --rollback-on-failure is set")
 	f.BoolVar(&client.RollbackOnFailure, "atomic", false, "deprecated")
-	f.MarkDeprecated("atomic", "use --rollback-on-failure instead")
+	markDeprecated(f, "atomic", "use --rollback-on-failure instead")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed. By default, CRDs are installed if not already present")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
+	f.BoolVar(&client.StrictValuesSchema, "strict-values-schema", false, "if set, values keys that are not declared in the chart's values.schema.json fail the install instead of causing a warning")
 	f.StringToStringVarP(&client.Labels, "labels", "l", nil, "Labels that would be added to release metadata. Should be divided by comma.")
+	f.StringToStringVar(&client.Labels, "release-labels", nil, "Labels that would be added to the storage object of the release, such as its Secret, and can be selected with 'helm list --selector'. Same as --labels")
+	f.StringToStringVar(&client.Annotations, "release-annotations", nil, "Annotations that would be added to the storage object of the release, such as its Secret. Should be divided by comma.")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
+	f.StringSliceVar(&client.Needs, "needs", nil, "releases that must be deployed and healthy before the install, by name, or by namespace/name for releases in other namespaces. Can be repeated or comma separated.")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in install output. Does not affect presence in chart metadata")
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, install will ignore the check for helm annotations and take ownership of the existing resources")
+	f.BoolVar(&client.ShareClusterScoped, "share-cluster-scoped", false, "if set, cluster-scoped resources owned by other releases are shared with them instead of failing. A shared resource is deleted when its last owner is uninstalled")
 
 	// For `helm template`, these notes flags are legacy, unused, and should not show in help, but
 	// must remain accepted for backwards compatibility in Helm 4. Deprecate and hide them for now
 	// TODO remove these from template command in Helm 5
 	if cmd.Name() == "template" {
-		if err := cmd.Flags().MarkDeprecated("hide-notes", "this flag has no effect for 'helm template' and will be removed in Helm 5"); err != nil {
+		if err := markDeprecated(cmd.Flags(), "hide-notes", "this flag has no effect for 'helm template' and will be removed in Helm 5"); err != nil {
 			log.Fatal(err)
 		}
 		if err := cmd.Flags().MarkHidden("hide-notes"); err != nil {
 			log.Fatal(err)
 		}
 
-		if err := cmd.Flags().MarkDeprecated("render-subchart-notes", "this flag has no effect for 'helm template' and will be removed in Helm 5"); err != nil {
+		if err := markDeprecated(cmd.Flags(), "render-subchart-notes", "this flag has no effect for 'helm template' and will be removed in Helm 5"); err != nil {
 			log.Fatal(err)
 		}
 		if err := cmd.Flags().MarkHidden("render-subchart-notes"); err != nil {
@@ -249,9 +275,21 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	if err != nil {
 		log.Fatal(err)
 	}
+	registerSetFlagCompletion(cmd, func(args []string) string {
+		chartArg := 1
+		if client.GenerateName {
+			chartArg = 0
+		}
+		if len(args) <= chartArg {
+			return ""
+		}
+		return args[chartArg]
+	})
 }
 
-func runInstall(args []string, client *action.Install, valueOpts *values.Options, out io.Writer) (*release.Release, error) {
+// runInstall installs a release. With releaseHooks set, the plugin release
+// hooks run around the installation.
+func runInstall(args []string, client *action.Install, valueOpts *values.Options, out io.Writer, releaseHooks bool) (*release.Release, error) {
 	slog.Debug("Original chart version", "version", client.Version)
 	if client.Version == "" && client.Devel {
 		slog.Debug("setting version to >0.0.0-0")
@@ -272,13 +310,15 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	slog.Debug("Chart path", "path", cp)
 
 	p := getter.All(settings)
+	valueOpts.ContentCache = settings.ContentCache
 	vals, err := valueOpts.MergeValues(p)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check chart dependencies to make sure all are present in /charts
-	chartRequested, err := loader.Load(cp)
+	// Check chart dependencies to make sure all are present in /charts. The
+	// large files of chart archives are only read when they are needed.
+	chartRequested, err := loader.LoadLazy(cp)
 	if err != nil {
 		return nil, err
 	}
@@ -292,10 +332,6 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 		return nil, err
 	}
 
-	if ac.Deprecated() {
-		slog.Warn("this chart is deprecated")
-	}
-
 	if req := ac.MetaDependencies(); len(req) > 0 {
 		// If CheckDependencies returns an error, we have unfulfilled dependencies.
 		// As of Helm 2.4.0, this is treated as a stopping condition:
@@ -318,7 +354,7 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 					return nil, err
 				}
 				// Reload the chart with the updated Chart.lock file.
-				if chartRequested, err = loader.Load(cp); err != nil {
+				if chartRequested, err = loader.LoadLazy(cp); err != nil {
 					return nil, fmt.Errorf("failed reloading chart after repo update: %w", err)
 				}
 			} else {
@@ -329,26 +365,30 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 
 	client.Namespace = settings.Namespace()
 
-	// Create context and prepare the handle of SIGTERM
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-
-	// Set up channel on which to send signal notifications.
-	// We must use a buffered channel or risk missing the signal
-	// if we're not ready to receive when the signal is sent.
-	cSignal := make(chan os.Signal, 2)
-	signal.Notify(cSignal, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-cSignal
-		fmt.Fprintf(out, "Release %s has been cancelled.\n", args[0])
-		cancel()
-	}()
+	ctx, stop := cancelOnSignal(out, client.ReleaseName)
+	defer stop()
+
+	dryRun := client.DryRunStrategy == action.DryRunClient || client.DryRunStrategy == action.DryRunServer
+	if releaseHooks {
+		err := runReleaseHooks(ctx, &schema.InputMessageReleaseHookV1{
+			Event:   plugin.PreInstall,
+			Release: schema.ReleaseHookReleaseV1{Name: client.ReleaseName, Namespace: client.Namespace},
+			Chart:   releaseHookChart(chartRequested),
+			DryRun:  dryRun,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	ri, err := client.RunWithContext(ctx, chartRequested, vals)
 	rel, rerr := releaserToV1Release(ri)
 	if rerr != nil {
 		return nil, rerr
 	}
+	if releaseHooks && err == nil {
+		runPostReleaseHooks(ctx, plugin.PostInstall, rel, dryRun)
+	}
 	return rel, err
 }
 
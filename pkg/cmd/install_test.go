@@ -17,12 +17,15 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"reflect"
 	"testing"
 
+	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/repo/v1/repotest"
 )
 
@@ -102,6 +105,13 @@ func TestInstall(t *testing.T) {
 			cmd:    "install aeneas-take-ownership testdata/testcharts/empty --take-ownership",
 			golden: "output/install-and-take-ownership.txt",
 		},
+		// Install, with a release it needs missing
+		{
+			name:      "install with missing needs",
+			cmd:       "install aeneas testdata/testcharts/empty --needs database",
+			golden:    "output/install-with-missing-needs.txt",
+			wantError: true,
+		},
 		// Install, with timeout
 		{
 			name:   "install with a timeout",
@@ -120,6 +130,18 @@ func TestInstall(t *testing.T) {
 			cmd:    "install apollo testdata/testcharts/empty --wait --wait-for-jobs",
 			golden: "output/install-with-wait-for-jobs.txt",
 		},
+		// Install, with retries
+		{
+			name:   "install with retries",
+			cmd:    "install apollo testdata/testcharts/empty --retry 3 --retry-backoff 1s --retry-on api-server,conflict",
+			golden: "output/install-with-retries.txt",
+		},
+		{
+			name:      "install with an invalid retry class",
+			cmd:       "install apollo testdata/testcharts/empty --retry 3 --retry-on everything",
+			golden:    "output/install-invalid-retry-on.txt",
+			wantError: true,
+		},
 		// Install, using the name-template
 		{
 			name:   "install with name-template",
@@ -279,6 +301,28 @@ func TestInstall(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestInstallWarnings(t *testing.T) {
+	_, out, err := executeActionCommand("install aeneas testdata/testcharts/deprecated --namespace default --atomic -o json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		Name     string           `json:"name"`
+		Warnings []action.Warning `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid output %q: %s", out, err)
+	}
+	expected := []action.Warning{
+		{Kind: action.WarningDeprecatedFlag, Message: "Flag --atomic has been deprecated, use --rollback-on-failure instead"},
+		{Kind: action.WarningDeprecatedChart, Message: "chart deprecated is deprecated"},
+	}
+	if result.Name != "aeneas" || !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("expected release aeneas with warnings %v, got %s with %v", expected, result.Name, result.Warnings)
+	}
+}
+
 func TestInstallOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "install")
 }
@@ -317,6 +361,31 @@ func TestInstallVersionCompletion(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestInstallSetCompletion(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "completion for install set flag from the values schema",
+		cmd:    "__complete install releasename testdata/testcharts/chart-with-schema --set ''",
+		golden: "output/install-set-comp.txt",
+	}, {
+		name:   "completion for install set flag with subcharts",
+		cmd:    "__complete install --generate-name testdata/testcharts/chart-with-schema-and-subchart --set-string ''",
+		golden: "output/install-set-subchart-comp.txt",
+	}, {
+		name:   "completion for install set flag after a pair",
+		cmd:    "__complete install releasename testdata/testcharts/empty --set-json 'a=1,'",
+		golden: "output/install-set-values-comp.txt",
+	}, {
+		name:   "completion for install set flag value",
+		cmd:    "__complete install releasename testdata/testcharts/chart-with-schema --set age=",
+		golden: "output/install-set-invalid-comp.txt",
+	}, {
+		name:   "completion for install set flag without a chart",
+		cmd:    "__complete install releasename --set ''",
+		golden: "output/install-set-invalid-comp.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestInstallFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "install", false)
 	checkFileCompletion(t, "install --generate-name", true)
@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/provenance"
+)
+
+var keysHelp = `
+This command consists of multiple subcommands to manage the keyring used to
+verify charts and plugins.
+
+Keys added with 'helm keys add' are stored in a keyring managed by Helm, which
+is used by default by '--verify' when it exists, instead of the GnuPG keyring.
+Its location can be changed with the HELM_KEYRING environment variable.
+
+By default, a chart may be signed by any key of the keyring. 'helm keys trust'
+restricts the keys trusted to sign the charts of a repository, either a chart
+repository name or a URL prefix such as oci://example.com/charts. The trust
+policy is stored in the file set by the HELM_TRUST_POLICY environment variable.
+`
+
+func newKeysCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys add|list|remove|trust [ARGS]",
+		Short: "manage the keys used to verify charts",
+		Long:  keysHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newKeysAddCmd(out))
+	cmd.AddCommand(newKeysListCmd(out))
+	cmd.AddCommand(newKeysRemoveCmd(out))
+	cmd.AddCommand(newKeysTrustCmd(out))
+
+	return cmd
+}
+
+// findKey returns the only key of a keyring matching a fingerprint, key ID or
+// identity.
+func findKey(keys openpgp.EntityList, id string) (*openpgp.Entity, error) {
+	var found *openpgp.Entity
+	for _, e := range keys {
+		if !provenance.MatchKey(e, id) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("more than one key matches %q, use its fingerprint", id)
+		}
+		found = e
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no key matching %q in keyring %s", id, settings.Keyring)
+	}
+	return found, nil
+}
+
+// compListKeys provides dynamic auto-completion for the keys of the keyring.
+func compListKeys(ignored []string) []string {
+	keys, err := provenance.LoadKeyring(settings.Keyring)
+	if err != nil {
+		return nil
+	}
+	var fingerprints []string
+	for _, e := range keys {
+		fp := provenance.Fingerprint(e)
+		if !slices.Contains(ignored, fp) {
+			fingerprints = append(fingerprints, fmt.Sprintf("%s\t%s", fp, primaryIdentity(e)))
+		}
+	}
+	return fingerprints
+}
+
+// primaryIdentity returns the name of the primary identity of a key.
+func primaryIdentity(e *openpgp.Entity) string {
+	if id := e.PrimaryIdentity(); id != nil {
+		return id.Name
+	}
+	return ""
+}
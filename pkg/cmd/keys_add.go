@@ -0,0 +1,140 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/provenance"
+)
+
+const keysAddDesc = `
+Add PGP public keys to the keyring managed by Helm.
+
+Each source is a key file, ASCII armored or binary, a URL, or an OCI reference
+to an artifact holding the keys:
+
+    $ helm keys add ./signer.asc
+    $ helm keys add https://example.com/keys/signer.asc
+    $ helm keys add oci://example.com/keys/signer:1.0.0
+
+Keys already in the keyring are updated. With '--trust', the added keys are
+also trusted to sign the charts of the given repositories.
+`
+
+type keysAddOptions struct {
+	sources []string
+	trust   []string
+	keyring string
+	policy  string
+}
+
+func newKeysAddCmd(out io.Writer) *cobra.Command {
+	o := &keysAddOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "add [SOURCE...]",
+		Short: "add public keys to the keyring",
+		Long:  keysAddDesc,
+		Args:  require.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			o.sources = args
+			o.keyring = settings.Keyring
+			o.policy = settings.TrustPolicy
+			return o.run(out)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&o.trust, "trust", nil, "trust the added keys to sign the charts of a repository name or URL prefix. Can be specified multiple times")
+	return cmd
+}
+
+func (o *keysAddOptions) run(out io.Writer) error {
+	keys, err := provenance.LoadKeyring(o.keyring)
+	if err != nil {
+		return err
+	}
+
+	var added []string
+	for _, src := range o.sources {
+		data, err := readKeySource(src)
+		if err != nil {
+			return fmt.Errorf("failed to read keys from %s: %w", src, err)
+		}
+		entities, err := provenance.ReadKeys(data)
+		if err != nil {
+			return fmt.Errorf("failed to read keys from %s: %w", src, err)
+		}
+		for _, e := range entities {
+			fp := provenance.Fingerprint(e)
+			verb := "Added"
+			if i := slices.IndexFunc(keys, func(k *openpgp.Entity) bool { return provenance.Fingerprint(k) == fp }); i >= 0 {
+				keys[i] = e
+				verb = "Updated"
+			} else {
+				keys = append(keys, e)
+			}
+			added = append(added, fp)
+			fmt.Fprintf(out, "%s key %s %s\n", verb, fp, primaryIdentity(e))
+		}
+	}
+	if err := provenance.SaveKeyring(o.keyring, keys); err != nil {
+		return err
+	}
+
+	if len(o.trust) == 0 {
+		return nil
+	}
+	policy, err := provenance.LoadTrustPolicy(o.policy)
+	if err != nil {
+		return err
+	}
+	for _, repository := range o.trust {
+		for _, fp := range added {
+			policy.Trust(repository, fp)
+		}
+		fmt.Fprintf(out, "Trusted %d key(s) for %s\n", len(added), repository)
+	}
+	return policy.WriteFile(o.policy, 0644)
+}
+
+// readKeySource reads keys from a file, or from a URL with the getter of its
+// scheme.
+func readKeySource(src string) ([]byte, error) {
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme == "" {
+		return os.ReadFile(src)
+	}
+	g, err := getter.All(settings).ByScheme(u.Scheme)
+	if err != nil {
+		return os.ReadFile(src)
+	}
+	data, err := g.Get(src, getter.WithURL(src), getter.WithArtifactType("keys"))
+	if err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/provenance"
+)
+
+func newKeysListCmd(out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	var noHeaders bool
+	cmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             "list the keys of the keyring",
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			keys, err := provenance.LoadKeyring(settings.Keyring)
+			if err != nil {
+				return err
+			}
+			policy, err := provenance.LoadTrustPolicy(settings.TrustPolicy)
+			if err != nil {
+				return err
+			}
+			if len(keys) == 0 && outfmt != output.JSON && outfmt != output.YAML {
+				fmt.Fprintln(cmd.ErrOrStderr(), "no keys to show")
+				return nil
+			}
+			return outfmt.Write(out, newKeysListWriter(keys, policy, noHeaders))
+		},
+	}
+
+	cmd.Flags().BoolVar(&noHeaders, "no-headers", false, "suppress headers in the output")
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+type keyElement struct {
+	Fingerprint string   `json:"fingerprint"`
+	KeyID       string   `json:"keyID"`
+	Identities  []string `json:"identities"`
+	TrustedFor  []string `json:"trustedFor,omitempty"`
+}
+
+type keysListWriter struct {
+	keys      []keyElement
+	noHeaders bool
+}
+
+func newKeysListWriter(keys openpgp.EntityList, policy *provenance.TrustPolicy, noHeaders bool) *keysListWriter {
+	// Initialize the array so no results returns an empty array instead of null
+	elements := make([]keyElement, 0, len(keys))
+	for _, e := range keys {
+		fp := provenance.Fingerprint(e)
+		el := keyElement{
+			Fingerprint: fp,
+			KeyID:       fmt.Sprintf("%016X", e.PrimaryKey.KeyId),
+		}
+		for name := range e.Identities {
+			el.Identities = append(el.Identities, name)
+		}
+		slices.Sort(el.Identities)
+		for repository, trusted := range policy.Repositories {
+			if slices.Contains(trusted, fp) {
+				el.TrustedFor = append(el.TrustedFor, repository)
+			}
+		}
+		slices.Sort(el.TrustedFor)
+		elements = append(elements, el)
+	}
+	return &keysListWriter{keys: elements, noHeaders: noHeaders}
+}
+
+func (w *keysListWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	if !w.noHeaders {
+		table.AddRow("FINGERPRINT", "IDENTITIES", "TRUSTED FOR")
+	}
+	for _, k := range w.keys {
+		table.AddRow(k.Fingerprint, strings.Join(k.Identities, ", "), strings.Join(k.TrustedFor, ", "))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *keysListWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.keys)
+}
+
+func (w *keysListWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.keys)
+}
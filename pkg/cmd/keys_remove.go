@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/provenance"
+)
+
+func newKeysRemoveCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove [KEY...]",
+		Aliases: []string{"rm"},
+		Short:   "remove keys from the keyring",
+		Long: `
+Remove keys from the keyring managed by Helm, and from the trust policy.
+
+A key is given by its fingerprint, its key ID, or a part of one of its
+identities that matches a single key.
+`,
+		Args: require.MinimumNArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return compListKeys(args), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			keys, err := provenance.LoadKeyring(settings.Keyring)
+			if err != nil {
+				return err
+			}
+			policy, err := provenance.LoadTrustPolicy(settings.TrustPolicy)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range args {
+				e, err := findKey(keys, id)
+				if err != nil {
+					return err
+				}
+				keys = slices.DeleteFunc(keys, func(k *openpgp.Entity) bool { return k == e })
+				fp := provenance.Fingerprint(e)
+				policy.Untrust("", fp)
+				fmt.Fprintf(out, "Removed key %s %s\n", fp, primaryIdentity(e))
+			}
+
+			if err := provenance.SaveKeyring(settings.Keyring, keys); err != nil {
+				return err
+			}
+			return policy.WriteFile(settings.TrustPolicy, 0644)
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyFingerprint = "5E615389B53CA37F0EE60BD3843BBF981FC18762"
+
+func TestKeysCmd(t *testing.T) {
+	defer resetEnv()()
+	dir := t.TempDir()
+	settings.Keyring = filepath.Join(dir, "keyring.gpg")
+	settings.TrustPolicy = filepath.Join(dir, "trust.yaml")
+
+	_, out, err := executeActionCommand("keys list")
+	require.NoError(t, err)
+	assert.Equal(t, "no keys to show\n", out)
+
+	_, out, err = executeActionCommand("keys add testdata/helm-test-key.pub --trust stable")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Added key "+testKeyFingerprint)
+	assert.Contains(t, out, "Trusted 1 key(s) for stable")
+	assert.Equal(t, settings.Keyring, defaultKeyring())
+
+	_, out, err = executeActionCommand("keys add testdata/helm-test-key.pub")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Updated key "+testKeyFingerprint)
+
+	_, out, err = executeActionCommand("keys trust 1FC18762 oci://example.com/charts")
+	require.NoError(t, err)
+	assert.Equal(t, "Key "+testKeyFingerprint+" is trusted for oci://example.com/charts\n", out)
+
+	_, out, err = executeActionCommand("keys list -o json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"fingerprint": "`+testKeyFingerprint+`",
+		"keyID": "843BBF981FC18762",
+		"identities": ["Helm Testing (This key should only be used for testing. DO NOT TRUST.) <helm-testing@helm.sh>"],
+		"trustedFor": ["oci://example.com/charts", "stable"]
+	}]`, out)
+
+	_, _, err = executeActionCommand("keys trust --revoke 1FC18762 other")
+	require.ErrorContains(t, err, "is not trusted for other")
+	_, out, err = executeActionCommand("keys trust --revoke 1FC18762 stable")
+	require.NoError(t, err)
+	assert.Contains(t, out, "no longer trusted for stable")
+
+	_, _, err = executeActionCommand("keys remove DEADBEEF")
+	require.ErrorContains(t, err, `no key matching "DEADBEEF"`)
+	_, out, err = executeActionCommand("keys remove helm-testing@helm.sh")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed key "+testKeyFingerprint)
+
+	_, out, err = executeActionCommand("keys list -o yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", out)
+	assert.NotEqual(t, settings.Keyring, defaultKeyring())
+}
+
+func TestKeysAddURL(t *testing.T) {
+	defer resetEnv()()
+	dir := t.TempDir()
+	settings.Keyring = filepath.Join(dir, "keyring.gpg")
+	settings.TrustPolicy = filepath.Join(dir, "trust.yaml")
+
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer srv.Close()
+
+	_, out, err := executeActionCommand("keys add " + srv.URL + "/helm-test-key.pub")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Added key "+testKeyFingerprint)
+
+	_, _, err = executeActionCommand("keys add " + srv.URL + "/missing.pub")
+	assert.Error(t, err)
+}
+
+func TestKeysRemoveCompletion(t *testing.T) {
+	checkFileCompletion(t, "keys remove", false)
+	checkFileCompletion(t, "keys trust", false)
+	checkFileCompletion(t, "keys add", true)
+}
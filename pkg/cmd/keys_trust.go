@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/provenance"
+)
+
+const keysTrustDesc = `
+Trust a key of the keyring to sign the charts of repositories.
+
+A repository is the name of a chart repository, which applies to 'repo/chart'
+references, or a URL prefix of charts, such as oci://example.com/charts. Once a
+repository has trusted keys, '--verify' rejects its charts unless they are
+signed by one of them. Charts of other repositories may be signed by any key of
+the keyring.
+
+    $ helm keys trust 5E615389B53CA37F0EE60BD3843BBF981FC18762 bitnami oci://ghcr.io/example
+
+With '--revoke', the key is no longer trusted for the repositories.
+`
+
+func newKeysTrustCmd(out io.Writer) *cobra.Command {
+	var revoke bool
+	cmd := &cobra.Command{
+		Use:   "trust KEY [REPO...]",
+		Short: "trust a key to sign the charts of repositories",
+		Long:  keysTrustDesc,
+		Args:  require.MinimumNArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListKeys(nil), cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListRepos(toComplete, args[1:]), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			keys, err := provenance.LoadKeyring(settings.Keyring)
+			if err != nil {
+				return err
+			}
+			e, err := findKey(keys, args[0])
+			if err != nil {
+				return err
+			}
+			policy, err := provenance.LoadTrustPolicy(settings.TrustPolicy)
+			if err != nil {
+				return err
+			}
+
+			fp := provenance.Fingerprint(e)
+			for _, repository := range args[1:] {
+				if revoke {
+					if !policy.Untrust(repository, fp) {
+						return fmt.Errorf("key %s is not trusted for %s", fp, repository)
+					}
+					fmt.Fprintf(out, "Key %s is no longer trusted for %s\n", fp, repository)
+					continue
+				}
+				policy.Trust(repository, fp)
+				fmt.Fprintf(out, "Key %s is trusted for %s\n", fp, repository)
+			}
+			return policy.WriteFile(settings.TrustPolicy, 0644)
+		},
+	}
+
+	cmd.Flags().BoolVar(&revoke, "revoke", false, "stop trusting the key for the repositories")
+	return cmd
+}
@@ -22,13 +22,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/getter"
@@ -41,12 +45,41 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+To lint a chart against several sets of values, pass '--values-matrix' with a
+glob of values files, such as 'ci/*-values.yaml'. The chart is linted once per
+file, with the file merged over any '--values' files, and a summary table lists
+the result of every combination. Use '--parallel' to lint several combinations
+at the same time. The command fails if any combination fails.
+
+Use '--validate-offline' to also validate the rendered manifests against the
+schemas of their kinds without a cluster. Kinds that are neither built in nor
+defined by the chart's CRDs are looked up in the '--schema-dir' directories.
+
+Use '--policy-dir' to check the rendered manifests against the CEL policies of
+the YAML files in a directory. Each policy has a name, a severity (error,
+warning or info), an optional match on apiVersions and kinds, a rule that must
+be true for the resource bound to 'object', and a message:
+
+    name: no-latest-tag
+    severity: warning
+    match:
+      kinds: [Deployment]
+    rule: object.spec.template.spec.containers.all(c, !c.image.endsWith(':latest'))
+    message: containers must not use the latest tag
+
+Optional rule groups are enabled with '--enable-rules'. The 'security' group
+warns about workloads that run privileged containers, use the host network,
+PID or IPC namespaces or hostPath volumes, do not set CPU and memory requests
+and limits, use 'latest' or untagged images, or may run as root.
 `
 
 func newLintCmd(out io.Writer) *cobra.Command {
 	client := action.NewLint()
 	valueOpts := &values.Options{}
 	var kubeVersion string
+	var valuesMatrix []string
+	var parallel int
 
 	cmd := &cobra.Command{
 		Use:   "lint PATH",
@@ -79,7 +112,18 @@ func newLintCmd(out io.Writer) *cobra.Command {
 				}
 			}
 
+			for _, group := range client.EnabledRules {
+				if !slices.Contains(rules.RuleGroups, group) {
+					return fmt.Errorf("unknown rule group %q, available groups: %s", group, strings.Join(rules.RuleGroups, ", "))
+				}
+			}
+
 			client.Namespace = settings.Namespace()
+			valueOpts.ContentCache = settings.ContentCache
+			if len(valuesMatrix) > 0 {
+				return runLintMatrix(out, client, paths, valueOpts, valuesMatrix, parallel)
+			}
+
 			vals, err := valueOpts.MergeValues(getter.All(settings))
 			if err != nil {
 				return err
@@ -102,32 +146,11 @@ func newLintCmd(out io.Writer) *cobra.Command {
 					continue
 				}
 
-				fmt.Fprintf(&message, "==> Linting %s\n", path)
-
-				// All the Errors that are generated by a chart
-				// that failed a lint will be included in the
-				// results.Messages so we only need to print
-				// the Errors if there are no Messages.
-				if len(result.Messages) == 0 {
-					for _, err := range result.Errors {
-						fmt.Fprintf(&message, "Error %s\n", err)
-					}
-				}
-
-				for _, msg := range result.Messages {
-					if !client.Quiet || msg.Severity > support.InfoSev {
-						fmt.Fprintf(&message, "%s\n", msg)
-					}
-				}
+				writeLintResult(&message, "==> Linting "+path, result, client.Quiet)
 
 				if len(result.Errors) != 0 {
 					failed++
 				}
-
-				// Adding extra new line here to break up the
-				// results, stops this from being a big wall of
-				// text and makes it easier to follow.
-				fmt.Fprint(&message, "\n")
 			}
 
 			fmt.Fprint(out, message.String())
@@ -148,8 +171,117 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.WithSubcharts, "with-subcharts", false, "lint dependent charts")
 	f.BoolVar(&client.Quiet, "quiet", false, "print only warnings and errors")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
+	f.BoolVar(&client.StrictValuesSchema, "strict-values-schema", false, "if set, values keys that are not declared in the chart's values.schema.json are reported as errors")
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for capabilities and deprecation checks")
+	f.BoolVar(&client.ValidateOffline, "validate-offline", false, "validate the rendered manifests against the schemas of their kinds without connecting to a cluster")
+	f.StringArrayVar(&client.SchemaDirs, "schema-dir", []string{}, "directory of JSON schemas used by --validate-offline for kinds that are neither built in nor defined by the chart's CRDs (can specify multiple)")
+	f.StringVar(&client.PolicyDir, "policy-dir", "", "check the rendered manifests against the CEL policies of the YAML files in this directory")
+	f.StringSliceVar(&client.EnabledRules, "enable-rules", []string{}, "enable optional rule groups. Available groups: security")
+	f.StringArrayVar(&valuesMatrix, "values-matrix", []string{}, "lint the chart once per values file matching the given glob (can specify multiple)")
+	f.IntVar(&parallel, "parallel", 1, "number of --values-matrix files to lint at the same time")
 	addValueOptionsFlags(f, valueOpts)
+	registerSetFlagCompletion(cmd, func(args []string) string {
+		if len(args) == 0 {
+			return "."
+		}
+		return args[0]
+	})
 
 	return cmd
 }
+
+// writeLintResult writes the messages of a lint result under a header.
+func writeLintResult(w io.Writer, header string, result *action.LintResult, quiet bool) {
+	fmt.Fprintf(w, "%s\n", header)
+
+	// All the Errors that are generated by a chart
+	// that failed a lint will be included in the
+	// results.Messages so we only need to print
+	// the Errors if there are no Messages.
+	if len(result.Messages) == 0 {
+		for _, err := range result.Errors {
+			fmt.Fprintf(w, "Error %s\n", err)
+		}
+	}
+
+	for _, msg := range result.Messages {
+		if !quiet || msg.Severity > support.InfoSev {
+			fmt.Fprintf(w, "%s\n", msg)
+		}
+	}
+
+	// Adding extra new line here to break up the
+	// results, stops this from being a big wall of
+	// text and makes it easier to follow.
+	fmt.Fprint(w, "\n")
+}
+
+// runLintMatrix lints the charts once per values file matching the matrix
+// globs. Each file is merged over the values given with -f, and below the
+// values given with --set and its variants.
+func runLintMatrix(out io.Writer, client *action.Lint, paths []string, valueOpts *values.Options, matrix []string, parallel int) error {
+	files, err := expandValuesMatrix(matrix)
+	if err != nil {
+		return err
+	}
+
+	combinations := make([]action.LintCombination, 0, len(files))
+	for _, file := range files {
+		opts := *valueOpts
+		opts.ValueFiles = append(slices.Clone(valueOpts.ValueFiles), file)
+		vals, err := opts.MergeValues(getter.All(settings))
+		if err != nil {
+			return fmt.Errorf("values matrix %s: %w", file, err)
+		}
+		combinations = append(combinations, action.LintCombination{Name: file, Values: vals})
+	}
+
+	results := client.RunMatrix(paths, combinations, parallel)
+
+	var message strings.Builder
+	table := uitable.New()
+	table.AddRow("CHART", "VALUES", "RESULT", "ERRORS", "WARNINGS")
+	failed := 0
+	for _, r := range results {
+		status := "passed"
+		if r.Failed() {
+			status = "failed"
+			failed++
+		}
+		table.AddRow(r.Path, r.Combination, status, r.Count(support.ErrorSev), r.Count(support.WarningSev))
+
+		if client.Quiet && !action.HasWarningsOrErrors(r.LintResult) {
+			continue
+		}
+		writeLintResult(&message, fmt.Sprintf("==> Linting %s with %s", r.Path, r.Combination), r.LintResult, client.Quiet)
+	}
+
+	fmt.Fprint(out, message.String())
+	if err := output.EncodeTable(out, table); err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("%d combination(s) linted, %d combination(s) failed", len(results), failed)
+	if failed > 0 {
+		return errors.New(summary)
+	}
+	fmt.Fprintln(out, summary)
+	return nil
+}
+
+// expandValuesMatrix returns the sorted, unique files matching the globs.
+func expandValuesMatrix(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid values matrix pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no values files match %q", pattern)
+		}
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
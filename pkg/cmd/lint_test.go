@@ -103,3 +103,41 @@ func TestLintFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "lint", true)
 	checkFileCompletion(t, "lint mypath", true) // Multiple paths can be given
 }
+
+func TestLintCmdWithValuesMatrix(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-schema"
+	tests := []cmdTestCase{{
+		name:      "lint chart with offline validation",
+		cmd:       "lint --validate-offline --kube-version 1.31.0 --set replicasField=replica testdata/testcharts/chart-with-offline-validation",
+		golden:    "output/lint-validate-offline.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with policies",
+		cmd:       "lint --policy-dir testdata/lint-policies testdata/testcharts/chart-with-offline-validation",
+		golden:    "output/lint-policy-dir.txt",
+		wantError: true,
+	}, {
+		name:   "lint chart with security rules",
+		cmd:    "lint --enable-rules security testdata/testcharts/chart-with-offline-validation",
+		golden: "output/lint-security-rules.txt",
+	}, {
+		name:      "lint chart with an unknown rule group",
+		cmd:       "lint --enable-rules hardening testdata/testcharts/chart-with-offline-validation",
+		golden:    "output/lint-unknown-rule-group.txt",
+		wantError: true,
+	}, {
+		name:   "lint chart against a passing values matrix",
+		cmd:    "lint --values-matrix testdata/lint-matrix/a-values.yaml " + testChart,
+		golden: "output/lint-values-matrix-pass.txt",
+	}, {
+		name:      "lint chart against a values matrix with a failing combination",
+		cmd:       "lint --parallel 2 --values-matrix 'testdata/lint-matrix/*-values.yaml' " + testChart,
+		golden:    "output/lint-values-matrix-fail.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart against a values matrix without matches",
+		cmd:       "lint --values-matrix 'testdata/lint-matrix/*.json' " + testChart,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -19,12 +19,14 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"slices"
 	"strconv"
 
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
 
 	coloroutput "helm.sh/helm/v4/internal/cli/output"
 	"helm.sh/helm/v4/pkg/action"
@@ -56,6 +58,11 @@ Only items that match the filter will be returned.
 If no results are found, 'helm list' will exit 0, but with no output (or in
 the case of no '-q' flag, only headers).
 
+Use '-o wide' to also show the number of resources and container images of the
+releases, and how long their last deployment took. With '--check-health', the
+kstatus of the resources of each release is checked in the cluster, and their
+aggregated health is shown as well: healthy, progressing, missing or degraded.
+
 By default, up to 256 items may be returned. To limit this, use the '--max' flag.
 Setting '--max' to 0 will not return all results. Rather, it will return the
 server's default, which may be much higher than 256. Pairing the '--max'
@@ -65,6 +72,7 @@ flag with the '--offset' flag allows you to page through results.
 func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewList(cfg)
 	var outfmt output.Format
+	var checkHealth bool
 
 	cmd := &cobra.Command{
 		Use:               "list",
@@ -105,7 +113,7 @@ func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				case "yaml":
 					output.EncodeYAML(out, names)
 					return nil
-				case "table":
+				case "table", "wide":
 					for _, res := range results {
 						fmt.Fprintln(out, res.Name)
 					}
@@ -113,7 +121,23 @@ func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				}
 			}
 
-			return outfmt.Write(out, newReleaseListWriter(results, client.TimeFormat, client.NoHeaders, settings.ShouldDisableColor()))
+			writer := newReleaseListWriter(results, client.TimeFormat, client.NoHeaders, settings.ShouldDisableColor())
+			if checkHealth {
+				writer.checkHealth = true
+				health := action.NewHealth(cfg)
+				for i, rel := range results {
+					if rel.Info.Status == common.StatusUninstalled {
+						continue
+					}
+					h, err := health.Run(rel)
+					if err != nil {
+						cfg.Logger().Warn("unable to check the health of the release", slog.String("name", rel.Name), slog.Any("error", err))
+						h = "unknown"
+					}
+					writer.releases[i].Health = h.String()
+				}
+			}
+			return outfmt.Write(out, writer)
 		},
 	}
 
@@ -134,7 +158,8 @@ func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.IntVar(&client.Offset, "offset", 0, "next release index in the list, used to offset from start value")
 	f.StringVarP(&client.Filter, "filter", "f", "", "a regular expression (Perl compatible). Any releases that match the expression will be included in the results")
 	f.StringVarP(&client.Selector, "selector", "l", "", "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2). Works only for secret(default) and configmap storage backends.")
-	bindOutputFlag(cmd, &outfmt)
+	f.BoolVar(&checkHealth, "check-health", false, "check the health of the resources of the releases in the cluster")
+	bindWideOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
@@ -147,12 +172,20 @@ type releaseElement struct {
 	Status     string `json:"status"`
 	Chart      string `json:"chart"`
 	AppVersion string `json:"app_version"`
+	// Resources, Images and DeployDuration are recorded by the deployments
+	// of Helm versions recording them only.
+	Resources      int    `json:"resources,omitempty"`
+	Images         int    `json:"images,omitempty"`
+	DeployDuration string `json:"deploy_duration,omitempty"`
+	// Health is only set with --check-health.
+	Health string `json:"health,omitempty"`
 }
 
 type releaseListWriter struct {
-	releases  []releaseElement
-	noHeaders bool
-	noColor   bool
+	releases    []releaseElement
+	noHeaders   bool
+	noColor     bool
+	checkHealth bool
 }
 
 func newReleaseListWriter(releases []*release.Release, timeFormat string, noHeaders bool, noColor bool) *releaseListWriter {
@@ -166,6 +199,11 @@ func newReleaseListWriter(releases []*release.Release, timeFormat string, noHead
 			Status:     r.Info.Status.String(),
 			Chart:      formatChartName(r.Chart),
 			AppVersion: formatAppVersion(r.Chart),
+			Resources:  r.Info.ResourceCount,
+			Images:     r.Info.ImageCount,
+		}
+		if r.Info.DeployDuration > 0 {
+			element.DeployDuration = duration.HumanDuration(r.Info.DeployDuration)
 		}
 
 		t := "-"
@@ -180,21 +218,32 @@ func newReleaseListWriter(releases []*release.Release, timeFormat string, noHead
 
 		elements = append(elements, element)
 	}
-	return &releaseListWriter{elements, noHeaders, noColor}
+	return &releaseListWriter{releases: elements, noHeaders: noHeaders, noColor: noColor}
 }
 
 func (w *releaseListWriter) WriteTable(out io.Writer) error {
+	return w.writeTable(out, false)
+}
+
+func (w *releaseListWriter) WriteWide(out io.Writer) error {
+	return w.writeTable(out, true)
+}
+
+func (w *releaseListWriter) writeTable(out io.Writer, wide bool) error {
 	table := uitable.New()
 	if !w.noHeaders {
-		table.AddRow(
-			coloroutput.ColorizeHeader("NAME", w.noColor),
-			coloroutput.ColorizeHeader("NAMESPACE", w.noColor),
-			coloroutput.ColorizeHeader("REVISION", w.noColor),
-			coloroutput.ColorizeHeader("UPDATED", w.noColor),
-			coloroutput.ColorizeHeader("STATUS", w.noColor),
-			coloroutput.ColorizeHeader("CHART", w.noColor),
-			coloroutput.ColorizeHeader("APP VERSION", w.noColor),
-		)
+		headers := []string{"NAME", "NAMESPACE", "REVISION", "UPDATED", "STATUS", "CHART", "APP VERSION"}
+		if wide {
+			headers = append(headers, "RESOURCES", "IMAGES", "DEPLOY DURATION")
+		}
+		if w.checkHealth {
+			headers = append(headers, "HEALTH")
+		}
+		row := make([]any, 0, len(headers))
+		for _, h := range headers {
+			row = append(row, coloroutput.ColorizeHeader(h, w.noColor))
+		}
+		table.AddRow(row...)
 	}
 	for _, r := range w.releases {
 		// Parse the status string back to a release.Status to use color
@@ -221,7 +270,19 @@ func (w *releaseListWriter) WriteTable(out io.Writer) error {
 		default:
 			status = common.Status(r.Status)
 		}
-		table.AddRow(r.Name, coloroutput.ColorizeNamespace(r.Namespace, w.noColor), r.Revision, r.Updated, coloroutput.ColorizeStatus(status, w.noColor), r.Chart, r.AppVersion)
+		row := []any{r.Name, coloroutput.ColorizeNamespace(r.Namespace, w.noColor), r.Revision, r.Updated, coloroutput.ColorizeStatus(status, w.noColor), r.Chart, r.AppVersion}
+		if wide {
+			if r.DeployDuration == "" {
+				// Not recorded by the deployment of the release.
+				row = append(row, "-", "-", "-")
+			} else {
+				row = append(row, r.Resources, r.Images, r.DeployDuration)
+			}
+		}
+		if w.checkHealth {
+			row = append(row, valueOrDash(r.Health))
+		}
+		table.AddRow(row...)
 	}
 	return output.EncodeTable(out, table)
 }
@@ -234,6 +295,13 @@ func (w *releaseListWriter) WriteYAML(out io.Writer) error {
 	return output.EncodeYAML(out, w.releases)
 }
 
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 // Returns all releases from 'releases', except those with names matching 'ignoredReleases'
 func filterReleases(releases []*release.Release, ignoredReleaseNames []string) []*release.Release {
 	// if ignoredReleaseNames is nil, just return releases
@@ -238,8 +238,78 @@ func TestListCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestListWideCmd(t *testing.T) {
+	chartInfo := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "chickadee",
+			Version:    "1.0.0",
+			AppVersion: "0.0.1",
+		},
+	}
+	releaseFixture := []*release.Release{{
+		Name:      "starlord",
+		Version:   1,
+		Namespace: "default",
+		Info: &release.Info{
+			LastDeployed:   time.Unix(1452902400, 0).UTC(),
+			Status:         common.StatusDeployed,
+			ResourceCount:  4,
+			ImageCount:     2,
+			DeployDuration: 95 * time.Second,
+		},
+		Chart: chartInfo,
+	}, {
+		// Deployed by a Helm version not recording the stats.
+		Name:      "groot",
+		Version:   1,
+		Namespace: "default",
+		Info: &release.Info{
+			LastDeployed: time.Unix(1452902400, 0).UTC(),
+			Status:       common.StatusDeployed,
+		},
+		Chart: chartInfo,
+	}}
+
+	tests := []cmdTestCase{{
+		name:   "list wide",
+		cmd:    "list -o wide",
+		golden: "output/list-wide.txt",
+		rels:   releaseFixture,
+	}, {
+		name:   "list wide with health",
+		cmd:    "list -o wide --check-health",
+		golden: "output/list-wide-health.txt",
+		rels:   releaseFixture,
+	}, {
+		name:   "list with health",
+		cmd:    "list --check-health",
+		golden: "output/list-health.txt",
+		rels:   releaseFixture,
+	}, {
+		name:   "list wide in JSON",
+		cmd:    "list -o json --check-health",
+		golden: "output/list-wide.json",
+		rels:   releaseFixture,
+	}, {
+		name:   "list short wide",
+		cmd:    "list -q -o wide",
+		golden: "output/list-short-wide.txt",
+		rels:   releaseFixture,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestListOutputCompletion(t *testing.T) {
-	outputFlagCompletionTest(t, "list")
+	tests := []cmdTestCase{{
+		name:   "completion for output flag",
+		cmd:    "__complete list --output ''",
+		golden: "output/list-output-comp.txt",
+	}, {
+		name:   "completion for output flag, no filter",
+		cmd:    "__complete list -o wi",
+		golden: "output/list-output-comp.txt",
+	}}
+	runTestCmd(t, tests)
 }
 
 func TestListFileCompletion(t *testing.T) {
@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"helm.sh/helm/v4/pkg/action"
+)
+
+// metricsAddr is the value of HELM_METRICS_ADDR. An http:// or https:// URL
+// is treated as a Prometheus Pushgateway the metrics are pushed to once the
+// command finishes; any other value is a listen address /metrics is served on
+// while the command runs.
+var metricsAddr = os.Getenv("HELM_METRICS_ADDR")
+
+// setupMetrics registers the action metrics on a new registry and exposes
+// them according to addr. The returned function must be called once the
+// command finished to push the metrics or stop the server.
+func setupMetrics(cfg *action.Configuration, addr string) (func() error, error) {
+	reg := prometheus.NewRegistry()
+	m, err := action.NewMetrics(reg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Metrics = m
+
+	if strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://") {
+		return func() error {
+			if err := push.New(addr, "helm").Gatherer(reg).Add(); err != nil {
+				return fmt.Errorf("could not push metrics to %s: %w", addr, err)
+			}
+			return nil
+		}, nil
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s for metrics: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("metrics server stopped", slog.Any("error", err))
+		}
+	}()
+	return srv.Close, nil
+}
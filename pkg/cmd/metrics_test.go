@@ -0,0 +1,64 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/action"
+)
+
+func TestSetupMetricsPush(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	cfg := action.NewConfiguration()
+	stop, err := setupMetrics(cfg, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Metrics == nil {
+		t.Fatal("expected metrics to be set on the configuration")
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(path, "/metrics/job/helm") {
+		t.Errorf("unexpected push path %q", path)
+	}
+}
+
+func TestSetupMetricsListen(t *testing.T) {
+	stop, err := setupMetrics(action.NewConfiguration(), "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := setupMetrics(action.NewConfiguration(), "not-an-address"); err == nil {
+		t.Error("expected an error for an invalid listen address")
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/notify"
+)
+
+// setupNotifications sets the notifier of cfg to the sinks of the
+// notification configuration file, and to the webhooks of
+// HELM_NOTIFY_WEBHOOK and HELM_NOTIFY_SLACK_WEBHOOK. The notifier is left
+// unset when there are no sinks.
+func setupNotifications(cfg *action.Configuration, settings *cli.EnvSettings) error {
+	c, err := notify.LoadConfig(settings.NotifyConfig)
+	if err != nil {
+		return err
+	}
+	if url := os.Getenv("HELM_NOTIFY_WEBHOOK"); url != "" {
+		c.Sinks = append(c.Sinks, notify.SinkConfig{Type: notify.SinkWebhook, URL: url})
+	}
+	if url := os.Getenv("HELM_NOTIFY_SLACK_WEBHOOK"); url != "" {
+		c.Sinks = append(c.Sinks, notify.SinkConfig{Type: notify.SinkSlack, URL: url})
+	}
+	if len(c.Sinks) == 0 {
+		return nil
+	}
+	n, err := c.Notifier()
+	if err != nil {
+		return err
+	}
+	cfg.Notifier = n
+	return nil
+}
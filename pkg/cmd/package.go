@@ -25,6 +25,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	chartutilv3 "helm.sh/helm/v4/internal/chart/v3/util"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/downloader"
@@ -45,11 +46,46 @@ provide '--keyring path/to/secret/keys' and '--key keyname'.
 
 If '--keyring' is not specified, Helm usually defaults to the public keyring
 unless your environment is otherwise configured.
+
+With '--provenance-v2', the provenance file holds a detached signature of the
+chart digest instead of a clear-signed message. Signing the same chart again
+with another key adds its signature, so that several parties can sign a
+chart, and '--signature-expiry' limits how long the signatures are valid:
+
+  $ helm package --sign ./mychart --key mykey --keyring ~/.gnupg/secring.gpg --provenance-v2
+
+Files matched by the .helmignore files of the chart, including the ones in its
+subdirectories, are left out of the package. To list them along with the rule
+that excluded each of them, use '--show-ignored'.
+
+The '--exclude' and '--include' flags add patterns, in the .helmignore format,
+of files to leave out of the package and of files to keep in it. They take
+precedence over the .helmignore files, and '--include' over '--exclude', so
+that the published chart can be trimmed without changing the chart directory:
+
+  $ helm package ./mychart --exclude 'tests/' --exclude 'docs/*' --include 'docs/README.md'
+
+Charts with apiVersion v3 can declare build steps in the build section of
+Chart.yaml, which '--run-build' runs in the chart directory before packaging:
+
+  build:
+    steps:
+    - name: render CRDs
+      command: [make, crds]
+    - builtin: values-schema
+    - builtin: readme-values
+
+The built-in 'values-schema' step generates values.schema.json from the default
+values, and 'readme-values' renders a table of the default values in README.md.
+Steps that run commands execute code from the chart, so they also require
+'--allow-build-commands'. Commands run without a shell, with a minimal
+environment, and are stopped after '--build-timeout'.
 `
 
 func newPackageCmd(out io.Writer) *cobra.Command {
 	client := action.NewPackage()
 	valueOpts := &values.Options{}
+	var showIgnored bool
 
 	cmd := &cobra.Command{
 		Use:   "package [CHART_PATH] [...]",
@@ -69,7 +105,14 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 			}
 			client.RepositoryConfig = settings.RepositoryConfig
 			client.RepositoryCache = settings.RepositoryCache
+			client.BuildOut = out
+			if showIgnored {
+				client.OnIgnored = func(name, rule string) {
+					fmt.Fprintf(out, "Ignored %s by %s\n", name, rule)
+				}
+			}
 			p := getter.All(settings)
+			valueOpts.ContentCache = settings.ContentCache
 			vals, err := valueOpts.MergeValues(p)
 			if err != nil {
 				return err
@@ -108,6 +151,9 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 					}
 				}
 				p, err := client.Run(path, vals)
+				if errors.Is(err, chartutilv3.ErrBuildCommandsNotAllowed) {
+					return fmt.Errorf("%w, use --allow-build-commands to run them", err)
+				}
 				if err != nil {
 					return err
 				}
@@ -120,12 +166,21 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVar(&client.Sign, "sign", false, "use a PGP private key to sign this package")
 	f.StringVar(&client.Key, "key", "", "name of the key to use when signing. Used if --sign is true")
-	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "location of a public keyring")
+	f.StringVar(&client.Keyring, "keyring", gnupgKeyring(), "location of a public keyring")
+	f.BoolVar(&client.ProvenanceV2, "provenance-v2", false, "write a provenance file with a detached signature of the chart digest, adding to an existing one for the same chart. Used if --sign is true")
+	f.DurationVar(&client.SignatureExpiry, "signature-expiry", 0, "how long the signature stays valid, such as 8760h. Used if --provenance-v2 is true")
 	f.StringVar(&client.PassphraseFile, "passphrase-file", "", `location of a file which contains the passphrase for the signing key. Use "-" in order to read from stdin.`)
 	f.StringVar(&client.Version, "version", "", "set the version on the chart to this semver version")
 	f.StringVar(&client.AppVersion, "app-version", "", "set the appVersion on the chart to this version")
 	f.StringVarP(&client.Destination, "destination", "d", ".", "location to write the chart.")
 	f.BoolVarP(&client.DependencyUpdate, "dependency-update", "u", false, `update dependencies from "Chart.yaml" to dir "charts/" before packaging`)
+	f.BoolVar(&client.Reproduce, "reproduce", false, "verify the chart against the origin recorded by 'helm pull --record-origin' and check that the package matches the original archive")
+	f.StringArrayVar(&client.Exclude, "exclude", nil, "leave files matching this pattern, in the .helmignore format, out of the package. Can be specified multiple times")
+	f.StringArrayVar(&client.Include, "include", nil, "keep files matching this pattern, in the .helmignore format, in the package even if they are ignored or excluded. Can be specified multiple times")
+	f.BoolVar(&client.RunBuild, "run-build", false, "run the build steps of the chart before packaging it")
+	f.BoolVar(&client.AllowBuildCommands, "allow-build-commands", false, "allow the build steps of the chart to run commands. Used if --run-build is true")
+	f.DurationVar(&client.BuildTimeout, "build-timeout", chartutilv3.DefaultBuildStepTimeout, "time each build command may run. Used if --run-build is true")
+	f.BoolVar(&showIgnored, "show-ignored", false, "list the files left out of the package by .helmignore rules and the rule that excluded each of them")
 	f.StringVar(&client.Username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&client.Password, "password", "", "chart repository password where to locate the requested chart")
 	f.StringVar(&client.CertFile, "cert-file", "", "identify HTTPS client using this SSL certificate file")
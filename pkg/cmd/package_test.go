@@ -91,6 +91,13 @@ func TestPackage(t *testing.T) {
 			expect:  "",
 			hasfile: "alpine-0.1.0.tgz",
 		},
+		{
+			name:    "package --sign --provenance-v2 testdata/testcharts/alpine",
+			args:    []string{"testdata/testcharts/alpine"},
+			flags:   map[string]string{"sign": "1", "keyring": "testdata/helm-test-key.secret", "key": "helm-test", "provenance-v2": "1", "signature-expiry": "24h"},
+			expect:  "",
+			hasfile: "alpine-0.1.0.tgz",
+		},
 		{
 			name:    "package testdata/testcharts/chart-missing-deps",
 			args:    []string{"testdata/testcharts/chart-missing-deps"},
@@ -192,6 +199,83 @@ func TestSetAppVersion(t *testing.T) {
 	}
 }
 
+func TestPackageShowIgnored(t *testing.T) {
+	dir := t.TempDir()
+	cmd := fmt.Sprintf("package testdata/testcharts/chart-with-helmignore --destination=%s --show-ignored", dir)
+	_, output, err := executeActionCommand(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"Ignored NOTES.md by .helmignore:2: *.md\n",
+		"Ignored docs/draft.txt by docs/.helmignore:1: *.txt\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected output to contain %q, got %q", line, output)
+		}
+	}
+
+	ch, err := loader.Load(filepath.Join(dir, "chart-with-helmignore-0.1.0.tgz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range ch.Files {
+		names = append(names, f.Name)
+	}
+	expect := []string{".helmignore", "README.md", "docs/.helmignore", "docs/index.txt"}
+	if strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Errorf("expected files %v, got %v", expect, names)
+	}
+}
+
+func TestPackageExcludeInclude(t *testing.T) {
+	dir := t.TempDir()
+	cmd := fmt.Sprintf("package testdata/testcharts/chart-with-helmignore --destination=%s --exclude docs/ --exclude templates/* --include NOTES.md --include templates/configmap.yaml --show-ignored", dir)
+	_, output, err := executeActionCommand(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := "Ignored docs/ by exclude: docs/\n"; !strings.Contains(output, line) {
+		t.Errorf("expected output to contain %q, got %q", line, output)
+	}
+
+	ch, err := loader.Load(filepath.Join(dir, "chart-with-helmignore-0.1.0.tgz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range append(ch.Templates, ch.Files...) {
+		names = append(names, f.Name)
+	}
+	expect := []string{"templates/configmap.yaml", ".helmignore", "NOTES.md", "README.md"}
+	if strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Errorf("expected files %v, got %v", expect, names)
+	}
+
+	_, _, err = executeActionCommand("package testdata/testcharts/chart-with-helmignore --exclude docs/**")
+	if err == nil || !strings.Contains(err.Error(), "double-star (**) syntax is not supported") {
+		t.Errorf("expected an error for an invalid pattern, got %v", err)
+	}
+}
+
+func TestPackageRunBuild(t *testing.T) {
+	chartDir := t.TempDir()
+	chartfile := "apiVersion: v3\nname: built\nversion: 0.1.0\nbuild:\n  steps:\n  - builtin: readme-values\n  - command: [make, docs]\n"
+	if err := os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte(chartfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := executeActionCommand(fmt.Sprintf("package %s --destination %s --run-build", chartDir, t.TempDir()))
+	expect := `build step "make": running build commands is not allowed, use --allow-build-commands to run them`
+	if err == nil || err.Error() != expect {
+		t.Errorf("expected error %q, got %v", expect, err)
+	}
+	if _, err := os.Stat(filepath.Join(chartDir, "README.md")); err == nil {
+		t.Error("expected no build step to run")
+	}
+}
+
 func TestPackageFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "package", true)
 	checkFileCompletion(t, "package mypath", true) // Multiple paths can be given
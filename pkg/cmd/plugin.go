@@ -25,6 +25,12 @@ import (
 
 const pluginHelp = `
 Manage client-side Helm plugins.
+
+Plugins may register hooks for release lifecycle events in the platformHooks
+of their plugin.yaml: pre-install, post-install, pre-upgrade, post-upgrade,
+pre-uninstall and post-uninstall. Helm runs them around the corresponding
+commands, with the release and chart as JSON on stdin. A failing pre hook
+aborts the command, while a failing post hook only causes a warning.
 `
 
 func newPluginCmd(out io.Writer) *cobra.Command {
@@ -38,6 +44,7 @@ func newPluginCmd(out io.Writer) *cobra.Command {
 		newPluginListCmd(out),
 		newPluginUninstallCmd(out),
 		newPluginUpdateCmd(out),
+		newPluginOutdatedCmd(out),
 		newPluginPackageCmd(out),
 		newPluginVerifyCmd(out),
 	)
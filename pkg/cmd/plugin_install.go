@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"helm.sh/helm/v4/internal/plugin"
 	"helm.sh/helm/v4/internal/plugin/installer"
@@ -38,6 +39,12 @@ type pluginInstallOptions struct {
 	verify  bool
 	keyring string
 	// OCI-specific options
+	pluginRegistryOptions
+}
+
+// pluginRegistryOptions are the options to access the OCI registries plugins
+// are installed from.
+type pluginRegistryOptions struct {
 	certFile              string
 	keyFile               string
 	caFile                string
@@ -47,6 +54,31 @@ type pluginInstallOptions struct {
 	username              string
 }
 
+func (o *pluginRegistryOptions) addFlags(f *pflag.FlagSet) {
+	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the plugin download")
+	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the plugin download")
+	f.StringVar(&o.username, "username", "", "registry username")
+	f.StringVar(&o.password, "password", "", "registry password")
+}
+
+// getterOptions returns the options of the getter downloading an OCI plugin.
+func (o *pluginRegistryOptions) getterOptions() []getter.Option {
+	return []getter.Option{
+		getter.WithTLSClientConfig(o.certFile, o.keyFile, o.caFile),
+		getter.WithInsecureSkipVerifyTLS(o.insecureSkipTLSVerify),
+		getter.WithPlainHTTP(o.plainHTTP),
+		getter.WithBasicAuth(o.username, o.password),
+	}
+}
+
+// registryClient returns a client listing the versions of an OCI plugin.
+func (o *pluginRegistryOptions) registryClient(out io.Writer) (*registry.Client, error) {
+	return newRegistryClient(out, o.certFile, o.keyFile, o.caFile, o.insecureSkipTLSVerify, o.plainHTTP, o.username, o.password)
+}
+
 const pluginInstallDesc = `
 This command allows you to install a plugin from a url to a VCS repo or a local path.
 
@@ -86,13 +118,7 @@ func newPluginInstallCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
 
 	// Add OCI-specific flags
-	cmd.Flags().StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
-	cmd.Flags().StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
-	cmd.Flags().StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
-	cmd.Flags().BoolVar(&o.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the plugin download")
-	cmd.Flags().BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the plugin download")
-	cmd.Flags().StringVar(&o.username, "username", "", "registry username")
-	cmd.Flags().StringVar(&o.password, "password", "", "registry password")
+	o.addFlags(cmd.Flags())
 	return cmd
 }
 
@@ -104,15 +130,26 @@ func (o *pluginInstallOptions) complete(args []string) error {
 func (o *pluginInstallOptions) newInstallerForSource() (installer.Installer, error) {
 	// Check if source is an OCI registry reference
 	if strings.HasPrefix(o.source, registry.OCIScheme+"://") {
-		// Build getter options for OCI
-		options := []getter.Option{
-			getter.WithTLSClientConfig(o.certFile, o.keyFile, o.caFile),
-			getter.WithInsecureSkipVerifyTLS(o.insecureSkipTLSVerify),
-			getter.WithPlainHTTP(o.plainHTTP),
-			getter.WithBasicAuth(o.username, o.password),
+		if o.version != "" {
+			client, err := o.registryClient(io.Discard)
+			if err != nil {
+				return nil, err
+			}
+			versions, err := installer.AvailableVersions(o.source, client)
+			if err != nil {
+				return nil, err
+			}
+			wanted, _, err := installer.ResolveVersions(versions, o.version)
+			if err != nil {
+				return nil, err
+			}
+			if wanted == nil {
+				return nil, fmt.Errorf("no version of %s satisfies %q", o.source, o.version)
+			}
+			o.source = installer.OCIReference(o.source, wanted.Original())
 		}
 
-		return installer.NewOCIInstaller(o.source, options...)
+		return installer.NewOCIInstaller(o.source, o.getterOptions()...)
 	}
 
 	// For non-OCI sources, use the original logic
@@ -179,6 +216,28 @@ func (o *pluginInstallOptions) run(out io.Writer) error {
 		return err
 	}
 
+	if _, ok := i.(*installer.LocalInstaller); !ok {
+		if err := lockInstalledPlugin(p, o.source, o.version); err != nil {
+			slog.Warn("failed to record the plugin in the plugins lockfile", slog.Any("error", err))
+		}
+	}
+
 	fmt.Fprintf(out, "Installed plugin: %s\n", p.Metadata().Name)
 	return nil
 }
+
+// lockInstalledPlugin records an installed plugin in the plugins lockfile.
+func lockInstalledPlugin(p plugin.Plugin, source, constraint string) error {
+	path := installer.LockFilePath()
+	lock, err := installer.LoadLock(path)
+	if err != nil {
+		return err
+	}
+	lock.Set(&installer.LockedPlugin{
+		Name:       p.Metadata().Name,
+		Source:     source,
+		Constraint: constraint,
+		Version:    p.Metadata().Version,
+	})
+	return lock.WriteFile(path)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/installer"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const pluginOutdatedDesc = `
+List the installed plugins that have newer versions available.
+
+WANTED is the highest version satisfying the version constraint the plugin is
+pinned to in the plugins lockfile, and LATEST is the highest version available.
+Only plugins installed from a VCS repository or an OCI registry are checked.
+`
+
+func newPluginOutdatedCmd(out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	var noHeaders bool
+	cmd := &cobra.Command{
+		Use:               "outdated",
+		Short:             "list installed Helm plugins with newer versions available",
+		Long:              pluginOutdatedDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			plugins, err := plugin.LoadAllDir(settings.PluginsDirectory, plugin.LogIgnorePluginLoadErrorFilterFunc)
+			if err != nil {
+				return err
+			}
+			lock, err := installer.LoadLock(installer.LockFilePath())
+			if err != nil {
+				return err
+			}
+
+			var outdated []pluginOutdatedElement
+			for _, p := range plugins {
+				e, err := outdatedPlugin(p, lock.Get(p.Metadata().Name))
+				if err != nil {
+					if !errors.Is(err, installer.ErrVersionsUnsupported) {
+						fmt.Fprintf(cmd.ErrOrStderr(), "failed to check plugin %s: %v\n", p.Metadata().Name, err)
+					}
+					continue
+				}
+				if e != nil {
+					outdated = append(outdated, *e)
+				}
+			}
+			if len(outdated) == 0 && outfmt != output.JSON && outfmt != output.YAML {
+				fmt.Fprintln(cmd.ErrOrStderr(), "all plugins are up to date")
+				return nil
+			}
+
+			return outfmt.Write(out, &pluginOutdatedWriter{plugins: outdated, noHeaders: noHeaders})
+		},
+	}
+
+	cmd.Flags().BoolVar(&noHeaders, "no-headers", false, "suppress headers in the output")
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+// outdatedPlugin returns the versions of a plugin that is behind the latest
+// version of its source, or nil when it is up to date.
+func outdatedPlugin(p plugin.Plugin, locked *installer.LockedPlugin) (*pluginOutdatedElement, error) {
+	var source, constraint string
+	if locked != nil && locked.Source != "" {
+		source, constraint = locked.Source, locked.Constraint
+	} else {
+		dir, err := filepath.EvalSymlinks(p.Dir())
+		if err != nil {
+			return nil, err
+		}
+		i, err := installer.FindSource(dir)
+		if err != nil {
+			return nil, installer.ErrVersionsUnsupported
+		}
+		vi, ok := i.(*installer.VCSInstaller)
+		if !ok {
+			return nil, installer.ErrVersionsUnsupported
+		}
+		source = vi.Repo.Remote()
+	}
+
+	slog.Debug("listing plugin versions", "plugin", p.Metadata().Name, "source", source)
+	versions, err := installer.AvailableVersions(source, nil)
+	if err != nil {
+		return nil, err
+	}
+	wanted, latest, err := installer.ResolveVersions(versions, constraint)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	current, err := semver.NewVersion(p.Metadata().Version)
+	if err == nil && !current.LessThan(latest) {
+		return nil, nil
+	}
+
+	e := &pluginOutdatedElement{
+		Name:    p.Metadata().Name,
+		Current: p.Metadata().Version,
+		Latest:  latest.Original(),
+		Source:  source,
+	}
+	if wanted != nil {
+		e.Wanted = wanted.Original()
+	}
+	return e, nil
+}
+
+type pluginOutdatedElement struct {
+	Name    string `json:"name"`
+	Current string `json:"current"`
+	Wanted  string `json:"wanted"`
+	Latest  string `json:"latest"`
+	Source  string `json:"source"`
+}
+
+type pluginOutdatedWriter struct {
+	plugins   []pluginOutdatedElement
+	noHeaders bool
+}
+
+func (w *pluginOutdatedWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	if !w.noHeaders {
+		table.AddRow("NAME", "CURRENT", "WANTED", "LATEST", "SOURCE")
+	}
+	for _, p := range w.plugins {
+		table.AddRow(p.Name, p.Current, p.Wanted, p.Latest, p.Source)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *pluginOutdatedWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.elements())
+}
+
+func (w *pluginOutdatedWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.elements())
+}
+
+// elements returns the outdated plugins, as an empty list rather than null
+// when there are none.
+func (w *pluginOutdatedWriter) elements() []pluginOutdatedElement {
+	if w.plugins == nil {
+		return []pluginOutdatedElement{}
+	}
+	return w.plugins
+}
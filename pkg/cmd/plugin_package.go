@@ -67,7 +67,7 @@ func newPluginPackageCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVar(&o.sign, "sign", true, "use a PGP private key to sign this plugin")
 	f.StringVar(&o.key, "key", "", "name of the key to use when signing. Used if --sign is true")
-	f.StringVar(&o.keyring, "keyring", defaultKeyring(), "location of a public keyring")
+	f.StringVar(&o.keyring, "keyring", gnupgKeyring(), "location of a public keyring")
 	f.StringVar(&o.passphraseFile, "passphrase-file", "", "location of a file which contains the passphrase for the signing key. Use \"-\" to read from stdin.")
 	f.StringVarP(&o.destination, "destination", "d", ".", "location to write the plugin tarball.")
 
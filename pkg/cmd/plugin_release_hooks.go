@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/schema"
+	"helm.sh/helm/v4/pkg/chart"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// runReleaseHooks runs the hooks the installed plugins register for a release
+// lifecycle event, in plugin name order. Failing pre hooks abort the
+// operation, while failing post hooks, which run once the operation is done,
+// only cause a warning.
+func runReleaseHooks(ctx context.Context, input *schema.InputMessageReleaseHookV1) error {
+	// If HELM_NO_PLUGINS is set to 1, do not run plugin hooks.
+	if os.Getenv("HELM_NO_PLUGINS") == "1" {
+		return nil
+	}
+	plugins, err := plugin.FindPlugins(filepath.SplitList(settings.PluginsDirectory), plugin.Descriptor{})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range plugins {
+		h, ok := p.(plugin.ReleaseHook)
+		if !ok {
+			continue
+		}
+		slog.Debug("running plugin release hook", "plugin", p.Metadata().Name, "event", input.Event)
+		if err := h.InvokeReleaseHook(ctx, input); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s %s hook: %w", p.Metadata().Name, input.Event, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runPostReleaseHooks runs the post hooks for a release, warning when they
+// fail.
+func runPostReleaseHooks(ctx context.Context, event string, rel *release.Release, dryRun bool) {
+	if rel == nil {
+		return
+	}
+	input := &schema.InputMessageReleaseHookV1{
+		Event: event,
+		Release: schema.ReleaseHookReleaseV1{
+			Name:      rel.Name,
+			Namespace: rel.Namespace,
+			Revision:  rel.Version,
+		},
+		DryRun: dryRun,
+	}
+	if rel.Info != nil {
+		input.Release.Status = rel.Info.Status.String()
+	}
+	if rel.Chart != nil {
+		input.Chart = releaseHookChart(rel.Chart)
+	}
+	if err := runReleaseHooks(ctx, input); err != nil {
+		slog.Warn("plugin release hooks failed", slog.String("event", event), slog.Any("error", err))
+	}
+}
+
+// releaseHookChart describes a chart for release hooks.
+func releaseHookChart(ch chart.Charter) *schema.ReleaseHookChartV1 {
+	ac, err := chart.NewAccessor(ch)
+	if err != nil {
+		return nil
+	}
+	meta := ac.MetadataAsMap()
+	name, _ := meta["Name"].(string)
+	version, _ := meta["Version"].(string)
+	appVersion, _ := meta["AppVersion"].(string)
+	return &schema.ReleaseHookChartV1{Name: name, Version: version, AppVersion: appVersion}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/internal/plugin/schema"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// setupHookPlugin installs a plugin with the given release hooks in a
+// temporary HELM_PLUGINS directory.
+func setupHookPlugin(t *testing.T, hooks map[string]string) {
+	t.Helper()
+	pluginsDir := t.TempDir()
+	settings.PluginsDirectory = pluginsDir
+
+	pluginDir := filepath.Join(pluginsDir, "notify")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	b.WriteString("name: notify\nversion: 0.1.0\nusage: notify\ndescription: notify\ncommand: echo\nhooks:\n")
+	for event, command := range hooks {
+		b.WriteString("  " + event + ": " + command + "\n")
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "plugin.yaml"), []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readHookInput(t *testing.T, path string) schema.InputMessageReleaseHookV1 {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var input schema.InputMessageReleaseHookV1
+	if err := json.Unmarshal(data, &input); err != nil {
+		t.Fatal(err)
+	}
+	return input
+}
+
+func TestReleaseHooks(t *testing.T) {
+	defer resetEnv()()
+	dir := t.TempDir()
+	setupHookPlugin(t, map[string]string{
+		"pre-install":    "cat > " + filepath.Join(dir, "pre-install.json"),
+		"post-install":   "cat > " + filepath.Join(dir, "post-install.json"),
+		"post-upgrade":   "cat > " + filepath.Join(dir, "post-upgrade.json"),
+		"post-uninstall": "cat > " + filepath.Join(dir, "post-uninstall.json"),
+	})
+	store := storage.Init(driver.NewMemory())
+
+	if _, _, err := executeActionCommandC(store, "install aeneas testdata/testcharts/empty --namespace default"); err != nil {
+		t.Fatal(err)
+	}
+	pre := readHookInput(t, filepath.Join(dir, "pre-install.json"))
+	if pre.Event != "pre-install" || pre.Release.Name != "aeneas" || pre.Release.Revision != 0 || pre.Chart == nil || pre.Chart.Name != "empty" {
+		t.Errorf("unexpected pre-install input %+v", pre)
+	}
+	post := readHookInput(t, filepath.Join(dir, "post-install.json"))
+	if post.Event != "post-install" || post.Release.Revision != 1 || post.Release.Status != "deployed" {
+		t.Errorf("unexpected post-install input %+v", post)
+	}
+
+	if _, _, err := executeActionCommandC(store, "upgrade aeneas testdata/testcharts/empty --namespace default"); err != nil {
+		t.Fatal(err)
+	}
+	if post := readHookInput(t, filepath.Join(dir, "post-upgrade.json")); post.Release.Revision != 2 {
+		t.Errorf("unexpected post-upgrade input %+v", post)
+	}
+
+	if _, _, err := executeActionCommandC(store, "uninstall aeneas --namespace default"); err != nil {
+		t.Fatal(err)
+	}
+	if post := readHookInput(t, filepath.Join(dir, "post-uninstall.json")); post.Release.Name != "aeneas" || post.Release.Status != "uninstalled" {
+		t.Errorf("unexpected post-uninstall input %+v", post)
+	}
+}
+
+func TestReleaseHooksPreHookFailure(t *testing.T) {
+	defer resetEnv()()
+	setupHookPlugin(t, map[string]string{"pre-install": "exit 1"})
+
+	_, _, err := executeActionCommand("install aeneas testdata/testcharts/empty --namespace default")
+	if err == nil || !strings.Contains(err.Error(), "notify pre-install hook") {
+		t.Fatalf("expected the failing pre-install hook to abort the install, got %v", err)
+	}
+}
+
+func TestReleaseHooksDisabled(t *testing.T) {
+	defer resetEnv()()
+	t.Setenv("HELM_NO_PLUGINS", "1")
+	setupHookPlugin(t, map[string]string{"pre-install": "exit 1"})
+
+	if _, _, err := executeActionCommand("install aeneas testdata/testcharts/empty --namespace default"); err != nil {
+		t.Fatal(err)
+	}
+}
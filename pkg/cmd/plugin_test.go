@@ -426,3 +426,7 @@ func TestPluginUpdateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "plugin update", false)
 	checkFileCompletion(t, "plugin update myplugin", false)
 }
+
+func TestPluginOutdatedFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "plugin outdated", false)
+}
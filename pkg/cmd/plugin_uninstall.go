@@ -26,6 +26,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/installer"
 )
 
 type pluginUninstallOptions struct {
@@ -72,6 +73,9 @@ func (o *pluginUninstallOptions) run(out io.Writer) error {
 			if err := uninstallPlugin(found); err != nil {
 				errorPlugins = append(errorPlugins, fmt.Errorf("failed to uninstall plugin %s, got error (%w)", name, err))
 			} else {
+				if err := unlockPlugin(name); err != nil {
+					slog.Warn("failed to remove the plugin from the plugins lockfile", slog.Any("error", err))
+				}
 				fmt.Fprintf(out, "Uninstalled plugin: %s\n", name)
 			}
 		} else {
@@ -121,6 +125,19 @@ func uninstallPlugin(p plugin.Plugin) error {
 	return runHook(p, plugin.Delete)
 }
 
+// unlockPlugin removes a plugin from the plugins lockfile.
+func unlockPlugin(name string) error {
+	path := installer.LockFilePath()
+	lock, err := installer.LoadLock(path)
+	if err != nil {
+		return err
+	}
+	if !lock.Remove(name) {
+		return nil
+	}
+	return lock.WriteFile(path)
+}
+
 // TODO should this be in pkg/plugin/loader.go?
 func findPlugin(plugins []plugin.Plugin, name string) plugin.Plugin {
 	for _, p := range plugins {
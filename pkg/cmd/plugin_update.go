@@ -22,16 +22,31 @@ import (
 	"log/slog"
 	"path/filepath"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/internal/plugin"
 	"helm.sh/helm/v4/internal/plugin/installer"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 type pluginUpdateOptions struct {
-	names []string
+	names   []string
+	all     bool
+	version string
+	// OCI-specific options
+	pluginRegistryOptions
 }
 
+const pluginUpdateDesc = `
+Update Helm plugins installed from a VCS repository or an OCI registry.
+
+Plugins are updated to the highest version satisfying the version constraint
+they are pinned to in the plugins lockfile, in HELM_DATA_HOME. Use '--version'
+to change the constraint, and '--all' to update every installed plugin, skipping
+the plugins that cannot be updated.
+`
+
 func newPluginUpdateCmd(out io.Writer) *cobra.Command {
 	o := &pluginUpdateOptions{}
 
@@ -39,6 +54,7 @@ func newPluginUpdateCmd(out io.Writer) *cobra.Command {
 		Use:     "update <plugin>...",
 		Aliases: []string{"up"},
 		Short:   "update one or more Helm plugins",
+		Long:    pluginUpdateDesc,
 		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return compListPlugins(toComplete, args), cobra.ShellCompDirectiveNoFileComp
 		},
@@ -49,13 +65,24 @@ func newPluginUpdateCmd(out io.Writer) *cobra.Command {
 			return o.run(out)
 		},
 	}
+	cmd.Flags().BoolVar(&o.all, "all", false, "update all installed plugins")
+	cmd.Flags().StringVar(&o.version, "version", "", "pin the plugins to a version constraint, such as ^1.2.0, and update them within it")
+	o.addFlags(cmd.Flags())
 	return cmd
 }
 
 func (o *pluginUpdateOptions) complete(args []string) error {
-	if len(args) == 0 {
+	if o.all && len(args) > 0 {
+		return errors.New("plugin names cannot be given with --all")
+	}
+	if !o.all && len(args) == 0 {
 		return errors.New("please provide plugin name to update")
 	}
+	if o.version != "" {
+		if _, err := semver.NewConstraint(o.version); err != nil {
+			return fmt.Errorf("invalid version constraint %q: %w", o.version, err)
+		}
+	}
 	o.names = args
 	return nil
 }
@@ -66,48 +93,157 @@ func (o *pluginUpdateOptions) run(out io.Writer) error {
 	if err != nil {
 		return err
 	}
+	lock, err := installer.LoadLock(installer.LockFilePath())
+	if err != nil {
+		return err
+	}
+
+	names := o.names
+	if o.all {
+		for _, p := range plugins {
+			names = append(names, p.Metadata().Name)
+		}
+	}
+
 	var errorPlugins []error
+	for _, name := range names {
+		found := findPlugin(plugins, name)
+		if found == nil {
+			errorPlugins = append(errorPlugins, fmt.Errorf("plugin: %s not found", name))
+			continue
+		}
+		locked := lock.Get(name)
+		if o.version != "" {
+			if locked == nil {
+				locked = &installer.LockedPlugin{Name: name, Version: found.Metadata().Version}
+			}
+			locked.Constraint = o.version
+		}
 
-	for _, name := range o.names {
-		if found := findPlugin(plugins, name); found != nil {
-			if err := updatePlugin(found); err != nil {
-				errorPlugins = append(errorPlugins, fmt.Errorf("failed to update plugin %s, got error (%w)", name, err))
-			} else {
-				fmt.Fprintf(out, "Updated plugin: %s\n", name)
+		updated, err := o.updatePlugin(found, locked)
+		switch {
+		case errors.Is(err, errPluginNotUpdatable) && o.all:
+			fmt.Fprintf(out, "Skipped plugin: %s (%v)\n", name, err)
+		case err != nil:
+			errorPlugins = append(errorPlugins, fmt.Errorf("failed to update plugin %s, got error (%w)", name, err))
+		case updated == nil:
+			fmt.Fprintf(out, "Plugin %s is up to date\n", name)
+			if locked != nil {
+				lock.Set(locked)
 			}
-		} else {
-			errorPlugins = append(errorPlugins, fmt.Errorf("plugin: %s not found", name))
+		default:
+			fmt.Fprintf(out, "Updated plugin: %s\n", name)
+			lock.Set(updated)
 		}
 	}
+	if err := lock.WriteFile(installer.LockFilePath()); err != nil {
+		errorPlugins = append(errorPlugins, fmt.Errorf("failed to write the plugins lockfile: %w", err))
+	}
 	if len(errorPlugins) > 0 {
 		return errors.Join(errorPlugins...)
 	}
 	return nil
 }
 
-func updatePlugin(p plugin.Plugin) error {
+// errPluginNotUpdatable indicates that the source of a plugin does not support
+// updates.
+var errPluginNotUpdatable = errors.New("plugin source does not support updates")
+
+// updatePlugin updates a plugin within the version constraint of its locked
+// entry, if any. It returns the new locked entry of the plugin, or nil when an
+// OCI plugin is already at the wanted version.
+func (o *pluginUpdateOptions) updatePlugin(p plugin.Plugin, locked *installer.LockedPlugin) (*installer.LockedPlugin, error) {
+	if locked != nil && registry.IsOCI(locked.Source) {
+		return o.updateOCIPlugin(p, locked)
+	}
+
 	exactLocation, err := filepath.EvalSymlinks(p.Dir())
 	if err != nil {
-		return err
+		return nil, err
 	}
 	absExactLocation, err := filepath.Abs(exactLocation)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	i, err := installer.FindSource(absExactLocation)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%w: %w", errPluginNotUpdatable, err)
+	}
+	source := absExactLocation
+	if vi, ok := i.(*installer.VCSInstaller); ok {
+		source = vi.Repo.Remote()
+		if locked != nil {
+			vi.Version = locked.Constraint
+		}
 	}
 	if err := installer.Update(i); err != nil {
-		return err
+		return nil, err
 	}
 
 	slog.Debug("loading plugin", "path", i.Path())
 	updatedPlugin, err := plugin.LoadDir(i.Path())
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := runHook(updatedPlugin, plugin.Update); err != nil {
+		return nil, err
+	}
+	return lockedPlugin(updatedPlugin, source, locked), nil
+}
+
+// updateOCIPlugin reinstalls an OCI plugin at the highest tag satisfying its
+// version constraint.
+func (o *pluginUpdateOptions) updateOCIPlugin(p plugin.Plugin, locked *installer.LockedPlugin) (*installer.LockedPlugin, error) {
+	client, err := o.registryClient(io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := installer.AvailableVersions(locked.Source, client)
+	if err != nil {
+		return nil, err
+	}
+	wanted, _, err := installer.ResolveVersions(versions, locked.Constraint)
+	if err != nil {
+		return nil, err
+	}
+	if wanted == nil {
+		return nil, fmt.Errorf("no version of %s satisfies %q", locked.Source, locked.Constraint)
+	}
+	if current, err := semver.NewVersion(p.Metadata().Version); err == nil && current.Equal(wanted) {
+		return nil, nil
+	}
+
+	source := installer.OCIReference(locked.Source, wanted.Original())
+	i, err := installer.NewOCIInstaller(source, o.getterOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if err := installer.Update(i); err != nil {
+		return nil, err
 	}
 
-	return runHook(updatedPlugin, plugin.Update)
+	slog.Debug("loading plugin", "path", i.Path())
+	updatedPlugin, err := plugin.LoadDir(i.Path())
+	if err != nil {
+		return nil, err
+	}
+	if err := runHook(updatedPlugin, plugin.Update); err != nil {
+		return nil, err
+	}
+	return lockedPlugin(updatedPlugin, source, locked), nil
+}
+
+// lockedPlugin returns the locked entry of an installed plugin, keeping the
+// version constraint of its previous entry.
+func lockedPlugin(p plugin.Plugin, source string, previous *installer.LockedPlugin) *installer.LockedPlugin {
+	locked := &installer.LockedPlugin{
+		Name:    p.Metadata().Name,
+		Source:  source,
+		Version: p.Metadata().Version,
+	}
+	if previous != nil {
+		locked.Constraint = previous.Constraint
+	}
+	return locked
 }
@@ -0,0 +1,135 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/internal/plugin/installer"
+	"helm.sh/helm/v4/internal/test/ensure"
+)
+
+// setupLocalPlugin installs a plugin that is not updatable in a temporary
+// HELM_PLUGINS directory.
+func setupLocalPlugin(t *testing.T, name string) {
+	t.Helper()
+	ensure.HelmHome(t)
+	pluginsDir := t.TempDir()
+	t.Setenv("HELM_PLUGINS", pluginsDir)
+	settings.PluginsDirectory = pluginsDir
+
+	pluginDir := filepath.Join(pluginsDir, name)
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	pluginYAML := "name: " + name + "\nversion: 1.0.0\ndescription: Test plugin\ncommand: $HELM_PLUGIN_DIR/" + name + "\n"
+	if err := os.WriteFile(filepath.Join(pluginDir, "plugin.yaml"), []byte(pluginYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginUpdateArgs(t *testing.T) {
+	defer resetEnv()()
+	setupLocalPlugin(t, "local")
+
+	tests := []struct {
+		name   string
+		cmd    string
+		expect string
+	}{
+		{name: "no plugin", cmd: "plugin update", expect: "please provide plugin name to update"},
+		{name: "names with --all", cmd: "plugin update local --all", expect: "plugin names cannot be given with --all"},
+		{name: "invalid constraint", cmd: "plugin update local --version 'not a constraint'", expect: "invalid version constraint"},
+		{name: "not updatable", cmd: "plugin update local", expect: "plugin source does not support updates"},
+		{name: "missing plugin", cmd: "plugin update missing", expect: "plugin: missing not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := executeActionCommand(tt.cmd)
+			if err == nil || !strings.Contains(err.Error(), tt.expect) {
+				t.Errorf("expected error containing %q, got %v", tt.expect, err)
+			}
+		})
+	}
+}
+
+func TestPluginUpdateAllSkipsLocalPlugins(t *testing.T) {
+	defer resetEnv()()
+	setupLocalPlugin(t, "local")
+
+	_, out, err := executeActionCommand("plugin update --all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Skipped plugin: local") {
+		t.Errorf("expected the local plugin to be skipped, got %q", out)
+	}
+}
+
+func TestPluginOutdatedLocalPlugins(t *testing.T) {
+	defer resetEnv()()
+	setupLocalPlugin(t, "local")
+
+	_, out, err := executeActionCommand("plugin outdated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "all plugins are up to date" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	_, out, err = executeActionCommand("plugin outdated -o json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected an empty JSON list, got %q", out)
+	}
+}
+
+func TestPluginUninstallRemovesLockEntry(t *testing.T) {
+	defer resetEnv()()
+	setupLocalPlugin(t, "local")
+
+	path := installer.LockFilePath()
+	lock, err := installer.LoadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Set(&installer.LockedPlugin{Name: "local", Source: "https://example.com/local", Constraint: "^1.0.0", Version: "1.0.0"})
+	lock.Set(&installer.LockedPlugin{Name: "other", Source: "oci://example.com/other", Version: "2.0.0"})
+	if err := lock.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := executeActionCommand("plugin uninstall local"); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err = installer.LoadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Get("local") != nil {
+		t.Error("expected the uninstalled plugin to be removed from the lockfile")
+	}
+	if lock.Get("other") == nil {
+		t.Error("expected the other plugin to stay in the lockfile")
+	}
+}
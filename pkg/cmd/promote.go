@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const promoteDesc = `
+This command switches the Services of a blue/green release to one of its
+slots.
+
+A release gets slots when it is upgraded with '--slot blue' or '--slot green'.
+The objects of a slot are labelled with 'helm.sh/slot' set to the slot, using
+the .Release.Slot built-in value, and the Services shared by the slots select
+the active slot with the same label, using .Release.ActiveSlot. For example:
+
+    metadata:
+      name: {{ .Release.Name }}-{{ .Release.Slot }}
+      labels:
+        helm.sh/slot: {{ .Release.Slot }}
+
+Promoting a slot sets the 'helm.sh/slot' selector of these Services to the
+slot in a new revision of the release, and records the slot as active.
+`
+
+func newPromoteCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewPromote(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "promote <RELEASE> <SLOT>",
+		Short: "switch the Services of a blue/green release to a slot",
+		Long:  promoteDesc,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListReleases(toComplete, args, cfg)
+			}
+			if len(args) == 1 {
+				return []string{action.SlotBlue, action.SlotGreen}, cobra.ShellCompDirectiveNoFileComp
+			}
+			return noMoreArgsComp()
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.Slot = args[1]
+			rel, err := client.Run(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Release %q promoted: its Services select the %s slot in revision %d\n", rel.Name, rel.ActiveSlot, rel.Version)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
+
+	return cmd
+}
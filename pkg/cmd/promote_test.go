@@ -0,0 +1,58 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestPromoteCmd(t *testing.T) {
+	deployment := "---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app-green\n  labels:\n    helm.sh/slot: green\n"
+	rels := []*release.Release{{
+		Name:       "bluegreen",
+		Info:       &release.Info{Status: common.StatusDeployed},
+		Chart:      &chart.Chart{},
+		Version:    1,
+		Manifest:   "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  selector:\n    helm.sh/slot: blue\n" + deployment,
+		Slots:      map[string]string{"blue": "", "green": deployment},
+		ActiveSlot: "blue",
+	}}
+
+	tests := []cmdTestCase{{
+		name:   "promote a slot",
+		cmd:    "promote bluegreen green",
+		golden: "output/promote.txt",
+		rels:   rels,
+	}, {
+		name:      "promote an invalid slot",
+		cmd:       "promote bluegreen red",
+		golden:    "output/promote-invalid-slot.txt",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "promote without a slot",
+		cmd:       "promote bluegreen",
+		golden:    "output/promote-no-slot.txt",
+		rels:      rels,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
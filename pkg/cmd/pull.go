@@ -38,6 +38,12 @@ the chart.
 There are options for unpacking the chart after download. This will create a
 directory for the chart and uncompress into that directory.
 
+With --keep-archive the chart archive, and its provenance file, are kept next
+to the unpacked chart. With --record-origin the source of the chart, the digest
+of the archive and the digest of every file are written to '.helm-origin.yaml'
+in the chart directory. 'helm verify --origin' uses this file to detect local changes to
+the chart, and 'helm package --reproduce' to rebuild the original archive.
+
 If the --verify flag is specified, the requested chart MUST have a provenance
 file, and MUST pass the verification process. Failure in any part of this will
 result in an error, and the chart will not be saved locally.
@@ -88,6 +94,8 @@ func newPullCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Untar, "untar", false, "if set to true, will untar the chart after downloading it")
 	f.BoolVar(&client.VerifyLater, "prov", false, "fetch the provenance file, but don't perform verification")
 	f.StringVar(&client.UntarDir, "untardir", ".", "if untar is specified, this flag specifies the name of the directory into which the chart is expanded")
+	f.BoolVar(&client.KeepArchive, "keep-archive", false, "if untar is specified, keep the chart archive and provenance file in the destination directory")
+	f.BoolVar(&client.RecordOrigin, "record-origin", false, "if untar is specified, record the source and file digests of the chart in the chart directory")
 	f.StringVarP(&client.DestDir, "destination", "d", ".", "location to write the chart. If this and untardir are specified, untardir is appended to this")
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 
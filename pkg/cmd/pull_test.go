@@ -104,6 +104,16 @@ func TestPullCmd(t *testing.T) {
 			expectFile: "./signtest",
 			expectDir:  true,
 		},
+		{
+			name:       "Fetch, untar and record origin",
+			args:       "test/signtest --untar --untardir origintest --record-origin",
+			expectFile: "./origintest/signtest/.helm-origin.yaml",
+		},
+		{
+			name:       "Fetch, untar and keep archive",
+			args:       "test/signtest --prov --untar --untardir keeptest --keep-archive",
+			expectFile: "./signtest-0.1.0.tgz.prov",
+		},
 		{
 			name:         "Fetch untar when file with same name existed",
 			args:         "test/test1 --untar --untardir test1",
@@ -557,3 +567,54 @@ func TestPullOCIWithTagAndDigest(t *testing.T) {
 		}
 	}
 }
+
+func TestOCIByDigest(t *testing.T) {
+	srv := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/testcharts/*.tgz*"),
+	)
+	defer srv.Stop()
+
+	ociSrv, err := repotest.NewOCIServer(t, srv.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := ociSrv.RunWithReturn(t)
+	digest := result.PushedChart.Manifest.Digest
+
+	repoRef := fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart", ociSrv.RegistryURL)
+	flags := fmt.Sprintf("--registry-config %s --content-cache %s --plain-http --require-digest",
+		filepath.Join(srv.Root(), "config.json"), t.TempDir())
+
+	_, _, err = executeActionCommand(fmt.Sprintf("pull %s:0.1.0 -d '%s' %s", repoRef, t.TempDir(), flags))
+	if err == nil || !strings.Contains(err.Error(), "is not pinned to a digest") {
+		t.Fatalf("expected pull by tag to be refused, got %v", err)
+	}
+
+	outdir := t.TempDir()
+	_, _, err = executeActionCommand(fmt.Sprintf("pull %s@%s -d '%s' %s", repoRef, digest, outdir, flags))
+	if err != nil {
+		t.Fatalf("pull by digest failed: %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(outdir, "*.tgz"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected the chart to be pulled, got %v %v", files, err)
+	}
+
+	store := storageFixture()
+	_, _, err = executeActionCommandC(store, fmt.Sprintf("install digested %s@%s %s", repoRef, digest, flags))
+	if err != nil {
+		t.Fatalf("install by digest failed: %v", err)
+	}
+	reli, err := store.Last("digested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := releaserToV1Release(reli)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src := rel.ChartSource; src == nil || src.ManifestDigest != digest || src.Digest == "" {
+		t.Errorf("expected the release to record the manifest digest %s, got %+v", digest, src)
+	}
+}
@@ -17,14 +17,18 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/pusher"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 const pushDesc = `
@@ -32,6 +36,26 @@ Upload a chart to a registry.
 
 If the chart has an associated provenance file,
 it will also be uploaded.
+
+When pushing to an OCI registry, the chart manifest is annotated with the
+title, version, description, home, source, authors and license of the chart,
+and with the annotations given with '--annotation', such as the revision of
+the chart source:
+
+    $ helm push mychart-0.1.0.tgz oci://registry.example.com/charts \
+        --annotation org.opencontainers.image.revision=$(git rev-parse HEAD)
+
+A tag that already refers to a different chart is not overwritten unless
+'--force' is set. With '--digest-only', the chart is pushed without a tag and
+can only be pulled by its digest.
+
+Large charts can be uploaded to an OCI registry in chunks with '--chunk-size',
+so that a chunk that failed to upload is sent again instead of the whole
+chart. With '--resume', the upload is also recorded in the cache directory,
+and running the same push again after it was interrupted continues the upload
+where it stopped:
+
+    $ helm push bigchart-1.0.0.tgz oci://registry.example.com/charts --resume
 `
 
 type registryPushOptions struct {
@@ -42,6 +66,11 @@ type registryPushOptions struct {
 	plainHTTP             bool
 	password              string
 	username              string
+	force                 bool
+	digestOnly            bool
+	annotations           []string
+	chunkSize             string
+	resume                bool
 }
 
 func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -78,15 +107,38 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return fmt.Errorf("missing registry client: %w", err)
 			}
 			cfg.RegistryClient = registryClient
+			annotations, err := parseAnnotations(o.annotations)
+			if err != nil {
+				return err
+			}
+			var chunkSize int64
+			if o.chunkSize != "" {
+				q, err := resource.ParseQuantity(o.chunkSize)
+				if err != nil {
+					return fmt.Errorf("invalid --chunk-size %q: %w", o.chunkSize, err)
+				}
+				chunkSize = q.Value()
+				if chunkSize <= 0 {
+					return fmt.Errorf("invalid --chunk-size %q: must be greater than zero", o.chunkSize)
+				}
+			}
 			chartRef := args[0]
 			remote := args[1]
 			client := action.NewPushWithOpts(action.WithPushConfig(cfg),
 				action.WithTLSClientConfig(o.certFile, o.keyFile, o.caFile),
 				action.WithInsecureSkipTLSVerify(o.insecureSkipTLSVerify),
 				action.WithPlainHTTP(o.plainHTTP),
-				action.WithPushOptWriter(out))
+				action.WithPushOptWriter(out),
+				action.WithPushForce(o.force),
+				action.WithPushDigestOnly(o.digestOnly),
+				action.WithPushAnnotations(annotations),
+				action.WithPushChunkSize(chunkSize),
+				action.WithPushResume(o.resume))
 			client.Settings = settings
 			output, err := client.Run(chartRef, remote)
+			if errors.Is(err, registry.ErrTagExists) {
+				return fmt.Errorf("%w, use --force to overwrite it", err)
+			}
 			if err != nil {
 				return err
 			}
@@ -103,6 +155,27 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart upload")
 	f.StringVar(&o.username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&o.password, "password", "", "chart repository password where to locate the requested chart")
+	f.BoolVar(&o.force, "force", false, "overwrite the tag if it already refers to a different chart")
+	f.BoolVar(&o.digestOnly, "digest-only", false, "push the chart without tagging it, so that it can only be pulled by digest")
+	f.StringVar(&o.chunkSize, "chunk-size", "", "upload the chart to an OCI registry in chunks of this size (e.g. 5Mi)")
+	f.BoolVar(&o.resume, "resume", false, "make the upload to an OCI registry resumable, and resume the interrupted upload of the same chart")
+	f.StringArrayVar(&o.annotations, "annotation", nil, "add an annotation to the OCI manifest of the chart (can specify multiple): key=value")
 
 	return cmd
 }
+
+// parseAnnotations parses annotations given as key=value.
+func parseAnnotations(in []string) (map[string]string, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	annotations := make(map[string]string, len(in))
+	for _, a := range in {
+		k, v, ok := strings.Cut(a, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid annotation %q, expected key=value", a)
+		}
+		annotations[k] = v
+	}
+	return annotations, nil
+}
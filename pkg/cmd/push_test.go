@@ -17,6 +17,8 @@ limitations under the License.
 package cmd
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -25,3 +27,33 @@ func TestPushFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "push package.tgz", false)
 	checkFileCompletion(t, "push package.tgz oci://localhost:5000", false)
 }
+
+func TestParseAnnotations(t *testing.T) {
+	annotations, err := parseAnnotations([]string{"org.opencontainers.image.revision=0123abcd", "empty=", "url=https://example.com/?a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{
+		"org.opencontainers.image.revision": "0123abcd",
+		"empty":                             "",
+		"url":                               "https://example.com/?a=b",
+	}
+	if !reflect.DeepEqual(expect, annotations) {
+		t.Errorf("expected %v, got %v", expect, annotations)
+	}
+
+	for _, in := range []string{"novalue", "=value"} {
+		if _, err := parseAnnotations([]string{in}); err == nil {
+			t.Errorf("expected an error for %q", in)
+		}
+	}
+}
+
+func TestPushInvalidChunkSize(t *testing.T) {
+	for _, size := range []string{"big", "0", "-1Mi"} {
+		_, _, err := executeActionCommand("push testdata/testcharts/compressedchart-0.1.0.tgz oci://localhost:5000 --chunk-size=" + size)
+		if err == nil || !strings.Contains(err.Error(), "invalid --chunk-size") {
+			t.Errorf("expected an invalid --chunk-size error for %q, got %v", size, err)
+		}
+	}
+}
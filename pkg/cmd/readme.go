@@ -0,0 +1,128 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/chart/docs"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const readmeDesc = `
+Generate the values reference of a chart in its README.
+
+A table of the chart values, with their types, defaults and descriptions, is
+built from the comments in values.yaml and the descriptions in
+values.schema.json. It is written to README.md between the markers
+
+    ` + docs.BeginMarker + `
+    ` + docs.EndMarker + `
+
+replacing whatever was there before, so the rest of the README is kept. When
+the README has no markers, a "Values" section is appended with them.
+
+With '--check', the README is not written, and the command fails when it is
+not up to date, which is useful in CI.
+`
+
+type readmeOptions struct {
+	readme string
+	check  bool
+}
+
+func newReadmeCmd(out io.Writer) *cobra.Command {
+	o := &readmeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "readme [CHART]",
+		Short: "generate the values reference table in a chart README",
+		Long:  readmeDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			return o.run(out, chartpath)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&o.readme, "readme", "", "path of the README to update (default: README.md in the chart directory)")
+	f.BoolVar(&o.check, "check", false, "fail if the README is not up to date instead of writing it")
+
+	return cmd
+}
+
+func (o *readmeOptions) run(out io.Writer, chartpath string) error {
+	if ok, err := chartutil.IsChartDir(chartpath); !ok {
+		return err
+	}
+
+	values, err := readOptionalFile(filepath.Join(chartpath, chartutil.ValuesfileName))
+	if err != nil {
+		return err
+	}
+	schema, err := readOptionalFile(filepath.Join(chartpath, chartutil.SchemafileName))
+	if err != nil {
+		return err
+	}
+	readmePath := o.readme
+	if readmePath == "" {
+		readmePath = filepath.Join(chartpath, "README.md")
+	}
+	readme, err := readOptionalFile(readmePath)
+	if err != nil {
+		return err
+	}
+
+	generated, err := docs.Generate(readme, values, schema)
+	if err != nil {
+		return fmt.Errorf("%s: %w", readmePath, err)
+	}
+	if bytes.Equal(readme, generated) {
+		fmt.Fprintf(out, "%s is up to date\n", readmePath)
+		return nil
+	}
+	if o.check {
+		return fmt.Errorf("%s is out of date, run 'helm readme' to update it", readmePath)
+	}
+	if err := os.WriteFile(readmePath, generated, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Updated %s\n", readmePath)
+	return nil
+}
+
+// readOptionalFile returns the content of a file, or nothing when it does not
+// exist.
+func readOptionalFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
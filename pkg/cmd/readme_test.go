@@ -0,0 +1,73 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/docs"
+)
+
+func TestReadme(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: docs\nversion: 0.1.0\n",
+		"values.yaml": "# -- Number of replicas.\nreplicaCount: 1\n",
+		"README.md":   "# docs\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readme := filepath.Join(dir, "README.md")
+
+	if _, _, err := executeActionCommand("readme --check " + dir); err == nil || !strings.Contains(err.Error(), "is out of date") {
+		t.Errorf("expected an out of date error, got %v", err)
+	}
+
+	_, out, err := executeActionCommand("readme " + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Updated "+readme) {
+		t.Errorf("expected the README to be updated, got %q", out)
+	}
+	data, err := os.ReadFile(readme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{"# docs\n", docs.BeginMarker, "| `replicaCount` | integer | `1` | Number of replicas. |", docs.EndMarker} {
+		if !strings.Contains(string(data), expect) {
+			t.Errorf("expected README to contain %q, got\n%s", expect, data)
+		}
+	}
+
+	_, out, err = executeActionCommand("readme --check " + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "is up to date") {
+		t.Errorf("expected the README to be up to date, got %q", out)
+	}
+
+	if _, _, err := executeActionCommand("readme " + t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without a chart")
+	}
+}
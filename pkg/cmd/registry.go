@@ -30,12 +30,13 @@ This command consists of multiple subcommands to interact with registries.
 func newRegistryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "registry",
-		Short: "login to or logout from a registry",
+		Short: "login to, logout from or copy between registries",
 		Long:  registryHelp,
 	}
 	cmd.AddCommand(
 		newRegistryLoginCmd(cfg, out),
 		newRegistryLogoutCmd(cfg, out),
+		newRegistryCopyCmd(cfg, out),
 	)
 	return cmd
 }
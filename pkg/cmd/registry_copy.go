@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+const registryCopyDesc = `
+Copy a chart from one OCI repository to another, such as to promote a chart
+between environments or to mirror it into an air-gapped registry:
+
+    $ helm registry copy oci://staging.example.com/charts/mychart:1.2.3 \
+        oci://prod.example.com/charts/mychart:1.2.3
+
+The manifests and blobs are copied unchanged, so the chart keeps its digest,
+and the artifacts attached to the chart, such as signatures, are copied too.
+The destination defaults to the tag of the source. A chart copied by digest
+to a destination without a tag is not tagged.
+
+A destination tag that already refers to a different chart is not overwritten
+unless '--force' is set.
+`
+
+type registryCopyOptions struct {
+	certFile              string
+	keyFile               string
+	caFile                string
+	insecureSkipTLSVerify bool
+	plainHTTP             bool
+	username              string
+	password              string
+	force                 bool
+}
+
+func newRegistryCopyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	o := &registryCopyOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "copy [source] [destination]",
+		Aliases:           []string{"cp"},
+		Short:             "copy a chart between registries",
+		Long:              registryCopyDesc,
+		Args:              require.ExactArgs(2),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, args []string) error {
+			registryClient, err := newRegistryClient(
+				out, o.certFile, o.keyFile, o.caFile, o.insecureSkipTLSVerify, o.plainHTTP, o.username, o.password,
+			)
+			if err != nil {
+				return fmt.Errorf("missing registry client: %w", err)
+			}
+			cfg.RegistryClient = registryClient
+
+			_, err = action.NewRegistryCopy(cfg).Run(out, args[0], args[1], action.WithCopyForce(o.force))
+			if errors.Is(err, registry.ErrTagExists) {
+				return fmt.Errorf("%w, use --force to overwrite it", err)
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the chart copy")
+	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart copy")
+	f.StringVar(&o.username, "username", "", "registry username")
+	f.StringVar(&o.password, "password", "", "registry password or identity token")
+	f.BoolVar(&o.force, "force", false, "overwrite the destination tag if it already refers to a different chart")
+
+	return cmd
+}
@@ -30,6 +30,7 @@ import (
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/repo/v1"
 )
 
 const registryLoginDesc = `
@@ -49,6 +50,7 @@ type registryLoginOptions struct {
 	caFile               string
 	insecure             bool
 	plainHTTP            bool
+	saveConfig           bool
 }
 
 func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -68,12 +70,25 @@ func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Comman
 				return err
 			}
 
-			return action.NewRegistryLogin(cfg).Run(out, hostname, username, password,
+			if err := action.NewRegistryLogin(cfg).Run(out, hostname, username, password,
 				action.WithCertFile(o.certFile),
 				action.WithKeyFile(o.keyFile),
 				action.WithCAFile(o.caFile),
 				action.WithInsecure(o.insecure),
-				action.WithPlainHTTPLogin(o.plainHTTP))
+				action.WithPlainHTTPLogin(o.plainHTTP)); err != nil {
+				return err
+			}
+
+			if o.saveConfig {
+				return saveRegistryConfig(settings.RepositoryConfig, &repo.RegistryEntry{
+					Host:                  hostname,
+					CertFile:              o.certFile,
+					KeyFile:               o.keyFile,
+					CAFile:                o.caFile,
+					InsecureSkipTLSVerify: o.insecure,
+				})
+			}
+			return nil
 		},
 	}
 
@@ -86,10 +101,25 @@ func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Comman
 	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
 	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart upload")
+	f.BoolVar(&o.saveConfig, "save-config", false, "save the TLS settings for this registry in the repository config file so that they are used by later commands")
 
 	return cmd
 }
 
+// saveRegistryConfig adds or replaces the connection settings of a registry
+// in the repository config file.
+func saveRegistryConfig(repoFile string, entry *repo.RegistryEntry) error {
+	f, err := repo.LoadFile(repoFile)
+	if err != nil {
+		if !isNotExist(err) {
+			return err
+		}
+		f = repo.NewFile()
+	}
+	f.UpdateRegistry(entry)
+	return f.WriteFile(repoFile, 0600)
+}
+
 // Adapted from https://github.com/oras-project/oras
 func getUsernamePassword(usernameOpt string, passwordOpt string, passwordFromStdinOpt bool) (string, string, error) {
 	var err error
@@ -29,12 +29,13 @@ import (
 var repoHelm = `
 This command consists of multiple subcommands to interact with chart repositories.
 
-It can be used to add, remove, list, and index chart repositories.
+It can be used to add, remove, list, and index chart repositories, and to
+manage how their credentials are stored.
 `
 
 func newRepoCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "repo add|remove|list|index|update [ARGS]",
+		Use:   "repo add|remove|list|index|update|login [ARGS]",
 		Short: "add, list, remove, update, and index chart repositories",
 		Long:  repoHelm,
 		Args:  require.NoArgs,
@@ -45,6 +46,7 @@ func newRepoCmd(out io.Writer) *cobra.Command {
 	cmd.AddCommand(newRepoRemoveCmd(out))
 	cmd.AddCommand(newRepoIndexCmd(out))
 	cmd.AddCommand(newRepoUpdateCmd(out))
+	cmd.AddCommand(newRepoLoginCmd(out))
 
 	return cmd
 }
@@ -50,6 +50,7 @@ type repoAddOptions struct {
 	password             string
 	passwordFromStdinOpt bool
 	passCredentialsAll   bool
+	credentialHelper     string
 	forceUpdate          bool
 	allowDeprecatedRepos bool
 	timeout              time.Duration
@@ -58,6 +59,7 @@ type repoAddOptions struct {
 	keyFile               string
 	caFile                string
 	insecureSkipTLSVerify bool
+	proxy                 string
 
 	repoFile  string
 	repoCache string
@@ -95,8 +97,10 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.keyFile, "key-file", "", "identify HTTPS client using this SSL key file")
 	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 	f.BoolVar(&o.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the repository")
+	f.StringVar(&o.proxy, "proxy", "", "URL of the HTTP proxy used for the repository, overriding the proxy environment variables")
 	f.BoolVar(&o.allowDeprecatedRepos, "allow-deprecated-repos", false, "by default, this command will not allow adding official repos that have been permanently deleted. This disables that behavior")
 	f.BoolVar(&o.passCredentialsAll, "pass-credentials", false, "pass credentials to all domains")
+	f.StringVar(&o.credentialHelper, "credential-helper", "", "store the repository password using the docker-credential-<helper> program (e.g. osxkeychain, wincred, secretservice, pass) instead of the repositories file")
 	f.DurationVar(&o.timeout, "timeout", getter.DefaultHTTPTimeout*time.Second, "time to wait for the index file download to complete")
 
 	return cmd
@@ -178,6 +182,14 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		KeyFile:               o.keyFile,
 		CAFile:                o.caFile,
 		InsecureSkipTLSVerify: o.insecureSkipTLSVerify,
+		CredentialHelper:      o.credentialHelper,
+		Proxy:                 o.proxy,
+	}
+
+	if c.CredentialHelper != "" {
+		if err := repo.ValidateCredentialHelper(c.CredentialHelper); err != nil {
+			return err
+		}
 	}
 
 	// Check if the repo name is legal
@@ -189,8 +201,16 @@ func (o *repoAddOptions) run(out io.Writer) error {
 	// 1. If the configuration for the name is the same continue without error
 	// 2. When the config is different require --force-update
 	if !o.forceUpdate && f.Has(o.name) {
-		existing := f.Get(o.name)
-		if c != *existing {
+		existing := *f.Get(o.name)
+		// Passwords kept in a credential helper are not part of the file
+		cmp := c
+		if cmp.CredentialHelper != "" {
+			cmp.Password = ""
+		}
+		if existing.CredentialHelper != "" {
+			existing.Password = ""
+		}
+		if cmp != existing {
 			// The input coming in for the name is different from what is already
 			// configured. Return an error.
 			return fmt.Errorf("repository name (%s) already exists, please specify a different name", o.name)
@@ -213,6 +233,10 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		return fmt.Errorf("looks like %q is not a valid chart repository or cannot be reached: %w", o.url, err)
 	}
 
+	if err := c.StoreCredentials(context.Background()); err != nil {
+		return err
+	}
+
 	f.Update(&c)
 
 	if err := f.WriteFile(o.repoFile, 0o600); err != nil {
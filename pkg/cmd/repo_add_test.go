@@ -27,6 +27,7 @@ import (
 	"sync"
 	"testing"
 
+	"oras.land/oras-go/v2/registry/remote/credentials"
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/pkg/helmpath"
@@ -134,6 +135,43 @@ func TestRepoAdd(t *testing.T) {
 	}
 }
 
+func TestRepoAddCredentialHelperIdempotent(t *testing.T) {
+	store := credentials.NewMemoryStore()
+	orig := repo.NewCredentialStore
+	repo.NewCredentialStore = func(string) (repo.CredentialStore, error) { return store, nil }
+	defer func() { repo.NewCredentialStore = orig }()
+
+	ts := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/testserver/*.*"),
+	)
+	defer ts.Stop()
+
+	rootDir := t.TempDir()
+	t.Setenv(xdg.CacheHomeEnvVar, rootDir)
+	o := &repoAddOptions{
+		name:             "secure",
+		url:              ts.URL(),
+		username:         "user",
+		password:         "s3cret",
+		credentialHelper: "pass",
+		repoFile:         filepath.Join(rootDir, "repositories.yaml"),
+	}
+	if err := o.run(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	// The entry read back has its password in the helper, not in the file
+	o.password = "s3cret"
+	var out strings.Builder
+	if err := o.run(&out); err != nil {
+		t.Fatalf("re-adding the same repository failed: %s", err)
+	}
+	if !strings.Contains(out.String(), "already exists with the same configuration") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
 func TestRepoAddCheckLegalName(t *testing.T) {
 	ts := repotest.NewTempServer(
 		t,
@@ -23,10 +23,13 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/repo/v1"
 )
 
@@ -41,6 +44,26 @@ To merge the generated index with an existing index file, use the '--merge'
 flag. In this case, the charts found in the current directory will be merged
 into the index passed in with --merge, with local charts taking priority over
 existing charts.
+
+For repositories with many charts, '--incremental' reuses the entries of the
+existing index of the directory: only the archives that were modified since it
+was generated are hashed, and only the charts whose digest changed are read.
+Unchanged charts keep their digest and creation time, and an index whose
+entries did not change keeps its generation time, which keeps the diffs of
+indexes stored in Git small.
+
+With '--shard', the entries are written to one index file per initial of the
+chart names, such as 'index-n.yaml' for the chart 'nginx', and 'index.yaml'
+lists these shards. Clients that do not support sharded indexes see an empty
+repository.
+
+To generate the index of the charts in an OCI registry instead, for tools that
+only understand classic chart repositories, use '--from-oci' with the namespace
+of the charts. The registry must support listing its repositories:
+
+    $ helm repo index . --from-oci oci://registry.example.com/charts
+
+The charts of such an index are downloaded from the registry.
 `
 
 type repoIndexOptions struct {
@@ -48,6 +71,18 @@ type repoIndexOptions struct {
 	url   string
 	merge string
 	json  bool
+
+	incremental bool
+	shard       bool
+
+	fromOCI               string
+	certFile              string
+	keyFile               string
+	caFile                string
+	insecureSkipTLSVerify bool
+	plainHTTP             bool
+	username              string
+	password              string
 }
 
 func newRepoIndexCmd(out io.Writer) *cobra.Command {
@@ -76,42 +111,112 @@ func newRepoIndexCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.url, "url", "", "url of chart repository")
 	f.StringVar(&o.merge, "merge", "", "merge the generated index into the given index")
 	f.BoolVar(&o.json, "json", false, "output in JSON format")
+	f.BoolVar(&o.incremental, "incremental", false, "reuse the entries of the existing index for the charts that did not change")
+	f.BoolVar(&o.shard, "shard", false, "split the index into one file per chart initial, requires clients that support sharded indexes")
+	f.StringVar(&o.fromOCI, "from-oci", "", "generate the index of the charts in this OCI registry namespace instead of the directory")
+	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the registry")
+	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the registry")
+	f.StringVar(&o.username, "username", "", "registry username")
+	f.StringVar(&o.password, "password", "", "registry password or identity token")
 
 	return cmd
 }
 
-func (i *repoIndexOptions) run(_ io.Writer) error {
+func (i *repoIndexOptions) run(out io.Writer) error {
 	path, err := filepath.Abs(i.dir)
 	if err != nil {
 		return err
 	}
 
-	return index(path, i.url, i.merge, i.json)
-}
-
-func index(dir, url, mergeTo string, json bool) error {
-	out := filepath.Join(dir, "index.yaml")
+	var previous *repo.IndexFile
+	if i.incremental {
+		previous, err = repo.LoadIndexFile(filepath.Join(path, "index.yaml"))
+		if errors.Is(err, fs.ErrNotExist) {
+			previous = nil
+		} else if err != nil {
+			return err
+		}
+	}
 
-	i, err := repo.IndexDirectory(dir, url)
+	if i.fromOCI == "" {
+		idx, err := repo.IndexDirectoryIncremental(path, i.url, previous)
+		if err != nil {
+			return err
+		}
+		return i.writeIndex(idx, path, previous)
+	}
+	if !registry.IsOCI(i.fromOCI) {
+		return fmt.Errorf("--from-oci must be an OCI reference, such as oci://registry.example.com/charts, not %q", i.fromOCI)
+	}
+	if i.url != "" {
+		return errors.New("--url cannot be used with --from-oci, the charts are downloaded from the registry")
+	}
+	registryClient, err := newRegistryClient(
+		out, i.certFile, i.keyFile, i.caFile, i.insecureSkipTLSVerify, i.plainHTTP, i.username, i.password,
+	)
+	if err != nil {
+		return fmt.Errorf("missing registry client: %w", err)
+	}
+	idx, err := repo.IndexRegistry(registryClient, i.fromOCI)
 	if err != nil {
 		return err
 	}
-	if mergeTo != "" {
+	return i.writeIndex(idx, path, previous)
+}
+
+// writeIndex merges the index into the index given with --merge, if set, and
+// writes it to the index.yaml file of dir, or to its shards. An index with
+// the same entries as the previous index keeps its generation time.
+func (i *repoIndexOptions) writeIndex(idx *repo.IndexFile, dir string, previous *repo.IndexFile) error {
+	out := filepath.Join(dir, "index.yaml")
+	if i.merge != "" {
 		// if index.yaml is missing then create an empty one to merge into
 		var i2 *repo.IndexFile
-		if _, err := os.Stat(mergeTo); errors.Is(err, fs.ErrNotExist) {
+		if _, err := os.Stat(i.merge); errors.Is(err, fs.ErrNotExist) {
 			i2 = repo.NewIndexFile()
-			writeIndexFile(i2, mergeTo, json)
+			writeIndexFile(i2, i.merge, i.json)
 		} else {
-			i2, err = repo.LoadIndexFile(mergeTo)
+			i2, err = repo.LoadIndexFile(i.merge)
 			if err != nil {
 				return fmt.Errorf("merge failed: %w", err)
 			}
 		}
-		i.Merge(i2)
+		idx.Merge(i2)
+	}
+	idx.SortEntries()
+	if previous != nil && reflect.DeepEqual(idx.Entries, previous.Entries) {
+		idx.Generated = previous.Generated
+	}
+	if !i.shard {
+		return writeIndexFile(idx, out, i.json)
+	}
+
+	shards := idx.Shard()
+	written := map[string]bool{}
+	for key, shard := range shards {
+		name := repo.ShardFileName(key)
+		if err := writeIndexFile(shard, filepath.Join(dir, name), i.json); err != nil {
+			return err
+		}
+		idx.Shards = append(idx.Shards, name)
+		written[name] = true
+	}
+	sort.Strings(idx.Shards)
+	// Remove the shards of the previous index that are now empty.
+	if previous != nil {
+		for _, name := range previous.Shards {
+			if !written[name] {
+				if err := os.Remove(filepath.Join(dir, filepath.FromSlash(name))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+			}
+		}
 	}
-	i.SortEntries()
-	return writeIndexFile(i, out, json)
+	idx.Entries = map[string]repo.ChartVersions{}
+	return writeIndexFile(idx, out, i.json)
 }
 
 func writeIndexFile(i *repo.IndexFile, out string, json bool) error {
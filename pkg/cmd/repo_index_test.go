@@ -19,12 +19,15 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v4/pkg/repo/v1"
+	"helm.sh/helm/v4/pkg/repo/v1/repotest"
 )
 
 func TestRepoIndexCmd(t *testing.T) {
@@ -191,3 +194,87 @@ func TestRepoIndexFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "repo index", true)
 	checkFileCompletion(t, "repo index mydir", false)
 }
+
+func TestRepoIndexFromOCI(t *testing.T) {
+	srv := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/testcharts/*.tgz*"),
+	)
+	defer srv.Stop()
+
+	ociSrv, err := repotest.NewOCIServer(t, srv.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := ociSrv.RunWithReturn(t)
+
+	dir := t.TempDir()
+	_, _, err = executeActionCommand(fmt.Sprintf("repo index %s --from-oci oci://%s/u --plain-http --username %s --password %s",
+		dir, ociSrv.RegistryURL, ociSrv.TestUsername, ociSrv.TestPassword))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := repo.LoadIndexFile(filepath.Join(dir, "index.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv, err := index.Get("oci-dependent-chart", "0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "oci://" + ociSrv.RegistryURL + "/u/ocitestuser/oci-dependent-chart:0.1.0"; len(cv.URLs) != 1 || cv.URLs[0] != want {
+		t.Errorf("expected URLs [%s], got %v", want, cv.URLs)
+	}
+	if want := strings.TrimPrefix(result.PushedChart.Chart.Digest, "sha256:"); cv.Digest != want {
+		t.Errorf("expected digest %s, got %s", want, cv.Digest)
+	}
+
+	_, _, err = executeActionCommand(fmt.Sprintf("repo index %s --from-oci %s/u", dir, ociSrv.RegistryURL))
+	if err == nil || !strings.Contains(err.Error(), "--from-oci must be an OCI reference") {
+		t.Errorf("expected an error for a reference without the oci scheme, got %v", err)
+	}
+}
+
+func TestRepoIndexCmdIncrementalShard(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"compressedchart-0.1.0.tgz", "compressedchart-0.2.0.tgz"} {
+		if err := linkOrCopy(filepath.Join("testdata/testcharts", name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := executeActionCommand(fmt.Sprintf("repo index %s --shard --incremental", dir)); err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.ReadFile(filepath.Join(dir, "index.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index-c.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := repo.LoadIndexFile(filepath.Join(dir, "index.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Shards) != 1 || index.Shards[0] != "index-c.yaml" {
+		t.Errorf("expected the shard index-c.yaml, got %v", index.Shards)
+	}
+	if vs := index.Entries["compressedchart"]; len(vs) != 2 {
+		t.Errorf("expected 2 versions of compressedchart, got %d", len(vs))
+	}
+
+	// Indexing unchanged charts again does not change the index.
+	if _, _, err := executeActionCommand(fmt.Sprintf("repo index %s --shard --incremental", dir)); err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile(filepath.Join(dir, "index.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("expected an unchanged index, got\n%s\nthen\n%s", first, second)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+const repoLoginDesc = `
+Store the credentials of a chart repository in an OS credential store.
+
+Credentials are saved using a docker-credential-<helper> program, such as
+osxkeychain (macOS Keychain), wincred (Windows Credential Manager),
+secretservice (Linux secret service) or pass, and the password is removed from
+the repositories file.
+
+When no username or password is provided, the credentials already present in
+the repositories file are migrated to the credential store:
+
+    helm repo login myrepo --credential-helper osxkeychain
+`
+
+type repoLoginOptions struct {
+	name                 string
+	username             string
+	password             string
+	passwordFromStdinOpt bool
+	credentialHelper     string
+
+	repoFile string
+}
+
+func newRepoLoginCmd(out io.Writer) *cobra.Command {
+	o := &repoLoginOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "login [NAME]",
+		Short: "store chart repository credentials in an OS credential store",
+		Long:  repoLoginDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return noMoreArgsComp()
+			}
+			return compListRepos(toComplete, args), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			o.name = args[0]
+			o.repoFile = settings.RepositoryConfig
+			return o.run(out)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&o.username, "username", "u", "", "chart repository username")
+	f.StringVarP(&o.password, "password", "p", "", "chart repository password")
+	f.BoolVar(&o.passwordFromStdinOpt, "password-stdin", false, "read chart repository password from stdin")
+	f.StringVar(&o.credentialHelper, "credential-helper", "", "docker-credential-<helper> program used to store the credentials (e.g. osxkeychain, wincred, secretservice, pass)")
+
+	return cmd
+}
+
+func (o *repoLoginOptions) run(out io.Writer) error {
+	f, err := repo.LoadFile(o.repoFile)
+	if isNotExist(err) || len(f.Repositories) == 0 {
+		return errors.New("no repositories configured")
+	}
+	if err != nil {
+		return err
+	}
+
+	entry := f.Get(o.name)
+	if entry == nil {
+		return fmt.Errorf("no repo named %q found", o.name)
+	}
+
+	if o.credentialHelper != "" {
+		entry.CredentialHelper = o.credentialHelper
+	}
+	if entry.CredentialHelper == "" {
+		return errors.New("a credential helper is required, use --credential-helper")
+	}
+
+	if o.username != "" || o.password != "" || o.passwordFromStdinOpt {
+		username, password, err := getUsernamePassword(o.username, o.password, o.passwordFromStdinOpt)
+		if err != nil {
+			return err
+		}
+		entry.Username = username
+		entry.Password = password
+	} else if entry.Password == "" {
+		return fmt.Errorf("no credentials found for %q, use --username and --password-stdin", o.name)
+	}
+
+	if err := entry.StoreCredentials(context.Background()); err != nil {
+		return err
+	}
+	if err := f.WriteFile(o.repoFile, 0600); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Credentials for %q stored using %q\n", o.name, entry.CredentialHelper)
+	return nil
+}
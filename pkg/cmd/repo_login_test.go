@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/credentials"
+
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+func TestRepoLoginMigratesCredentials(t *testing.T) {
+	store := credentials.NewMemoryStore()
+	orig := repo.NewCredentialStore
+	repo.NewCredentialStore = func(string) (repo.CredentialStore, error) { return store, nil }
+	defer func() { repo.NewCredentialStore = orig }()
+
+	repoFile := filepath.Join(t.TempDir(), "repositories.yaml")
+	f := repo.NewFile()
+	f.Add(&repo.Entry{Name: "private", URL: "https://example.com/charts", Username: "user", Password: "s3cret"})
+	if err := f.WriteFile(repoFile, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	o := &repoLoginOptions{name: "missing", credentialHelper: "pass", repoFile: repoFile}
+	if err := o.run(&bytes.Buffer{}); err == nil {
+		t.Error("expected error for unknown repository")
+	}
+
+	o = &repoLoginOptions{name: "private", repoFile: repoFile}
+	if err := o.run(&bytes.Buffer{}); err == nil {
+		t.Error("expected error when no credential helper is set")
+	}
+
+	out := &bytes.Buffer{}
+	o = &repoLoginOptions{name: "private", credentialHelper: "pass", repoFile: repoFile}
+	if err := o.run(out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `stored using "pass"`) {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	b, err := os.ReadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "s3cret") {
+		t.Errorf("password was not removed from the repositories file:\n%s", b)
+	}
+
+	cred, err := store.Get(t.Context(), "https://example.com/charts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Username != "user" || cred.Password != "s3cret" {
+		t.Errorf("unexpected stored credentials %+v", cred)
+	}
+}
@@ -17,10 +17,12 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 
@@ -65,6 +67,7 @@ func (o *repoRemoveOptions) run(out io.Writer) error {
 	}
 
 	for _, name := range o.names {
+		entry := r.Get(name)
 		if !r.Remove(name) {
 			return fmt.Errorf("no repo named %q found", name)
 		}
@@ -75,6 +78,9 @@ func (o *repoRemoveOptions) run(out io.Writer) error {
 		if err := removeRepoCache(o.repoCache, name); err != nil {
 			return err
 		}
+		if err := entry.DeleteCredentials(context.Background()); err != nil {
+			slog.Warn("failed to remove stored repository credentials", slog.String("repo", name), slog.Any("error", err))
+		}
 		fmt.Fprintf(out, "%q has been removed from your repositories\n", name)
 	}
 
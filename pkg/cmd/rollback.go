@@ -36,10 +36,14 @@ second is a revision (version) number. If this argument is omitted or set to
 0, it will roll back to the previous release.
 
 To see revision numbers, run 'helm history RELEASE'.
+
+With '--dry-run', the changes the rollback would make to the manifest of the
+release are shown as a diff, and nothing is changed.
 `
 
 func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewRollback(cfg)
+	var retention *retentionFlags
 
 	cmd := &cobra.Command{
 		Use:   "rollback <RELEASE> [REVISION]",
@@ -71,12 +75,27 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return err
 			}
 			client.DryRunStrategy = dryRunStrategy
+			client.Retention = retention.retention(cmd)
+
+			if dryRunStrategy != action.DryRunNone {
+				diff, err := client.Diff(args[0])
+				if err != nil {
+					return err
+				}
+				if diff.Diff == "" {
+					fmt.Fprintf(out, "Rolling back %s from revision %d to %d would not change its manifest\n", diff.Name, diff.CurrentRevision, diff.TargetRevision)
+				} else {
+					fmt.Fprintf(out, "Rolling back %s from revision %d to %d would apply:\n%s", diff.Name, diff.CurrentRevision, diff.TargetRevision, diff.Diff)
+				}
+			}
 
 			if err := client.Run(args[0]); err != nil {
 				return err
 			}
 
-			fmt.Fprint(out, "Rollback was a success! Happy Helming!\n")
+			if dryRunStrategy == action.DryRunNone {
+				fmt.Fprint(out, "Rollback was a success! Happy Helming!\n")
+			}
 			return nil
 		},
 	}
@@ -84,14 +103,14 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVar(&client.ForceReplace, "force-replace", false, "force resource updates by replacement")
 	f.BoolVar(&client.ForceReplace, "force", false, "deprecated")
-	f.MarkDeprecated("force", "use --force-replace instead")
+	markDeprecated(f, "force", "use --force-replace instead")
 	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
 	f.StringVar(&client.ServerSideApply, "server-side", "auto", "must be \"true\", \"false\" or \"auto\". Object updates run in the server instead of the client (\"auto\" defaults the value from the previous chart release's method)")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during rollback")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this rollback when rollback fails")
-	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	retention = addRetentionFlags(cmd, &client.MaxHistory)
 	addDryRunFlag(cmd)
 	AddWaitFlag(cmd, &client.WaitStrategy)
 	cmd.MarkFlagsMutuallyExclusive("force-replace", "force-conflicts")
@@ -73,6 +73,31 @@ func TestRollbackCmd(t *testing.T) {
 		golden:    "output/rollback-non-existent-version.txt",
 		rels:      rels,
 		wantError: true,
+	}, {
+		name:   "rollback a release with dry-run",
+		cmd:    "rollback funny-honey 1 --dry-run",
+		golden: "output/rollback-dry-run.txt",
+		rels: []*release.Release{
+			{
+				Name:     "funny-honey",
+				Info:     &release.Info{Status: common.StatusSuperseded},
+				Chart:    &chart.Chart{},
+				Version:  1,
+				Manifest: "---\nkind: ConfigMap\ndata:\n  drink: tea\n",
+			},
+			{
+				Name:     "funny-honey",
+				Info:     &release.Info{Status: common.StatusDeployed},
+				Chart:    &chart.Chart{},
+				Version:  2,
+				Manifest: "---\nkind: ConfigMap\ndata:\n  drink: coffee\n",
+			},
+		},
+	}, {
+		name:   "rollback a release with dry-run without changes",
+		cmd:    "rollback funny-honey 1 --dry-run",
+		golden: "output/rollback-dry-run-no-changes.txt",
+		rels:   rels,
 	}, {
 		name:      "rollback a release without release name",
 		cmd:       "rollback",
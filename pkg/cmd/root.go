@@ -18,6 +18,7 @@ package cmd // import "helm.sh/helm/v4/pkg/cmd"
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -31,12 +32,14 @@ import (
 	"sigs.k8s.io/yaml"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"helm.sh/helm/v4/internal/logging"
 	"helm.sh/helm/v4/internal/tlsutil"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 	"helm.sh/helm/v4/pkg/registry"
 	ri "helm.sh/helm/v4/pkg/release"
@@ -60,17 +63,27 @@ Environment variables:
 |------------------------------------|------------------------------------------------------------------------------------------------------------|
 | $HELM_CACHE_HOME                   | set an alternative location for storing cached files.                                                      |
 | $HELM_CONFIG_HOME                  | set an alternative location for storing Helm configuration.                                                |
+| $HELM_CONFIG_FILE                  | set the path to the file setting default flag values (default: $HELM_CONFIG_HOME/config.yaml).             |
 | $HELM_DATA_HOME                    | set an alternative location for storing Helm data.                                                         |
 | $HELM_DEBUG                        | indicate whether or not Helm is running in Debug mode                                                      |
 | $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, sql.                                |
 | $HELM_DRIVER_SQL_CONNECTION_STRING | set the connection string the SQL storage driver should use.                                               |
+| $HELM_ERROR_FORMAT                 | set the format errors are reported in. Values are: text, json (default: text).                             |
 | $HELM_MAX_HISTORY                  | set the maximum number of helm release history.                                                            |
+| $HELM_METRICS_ADDR                 | expose Prometheus metrics on this listen address, or push them to this Pushgateway URL when done.          |
 | $HELM_NAMESPACE                    | set the namespace used for the helm operations.                                                            |
+| $HELM_NOTIFY_CONFIG                | set the path to the file configuring the webhooks notified of the operations on releases.                  |
+| $HELM_NOTIFY_SLACK_WEBHOOK         | post a message to this Slack incoming webhook when an operation on a release completes.                    |
+| $HELM_NOTIFY_WEBHOOK               | post an event in JSON to this URL when an operation on a release completes.                                |
 | $HELM_NO_PLUGINS                   | disable plugins. Set HELM_NO_PLUGINS=1 to disable plugins.                                                 |
 | $HELM_PLUGINS                      | set the path to the plugins directory                                                                      |
 | $HELM_REGISTRY_CONFIG              | set the path to the registry config file.                                                                  |
 | $HELM_REPOSITORY_CACHE             | set the path to the repository cache directory                                                             |
 | $HELM_REPOSITORY_CONFIG            | set the path to the repositories file.                                                                     |
+| $HELM_SENSITIVE_VALUES_KEY         | set the base64 encoded AES key used to encrypt the sensitive values of releases.                           |
+| $HELM_SENSITIVE_VALUES_KMS_PLUGIN  | set the path of the KMS plugin used to encrypt the sensitive values of releases.                           |
+| $HELM_STORAGE_DELTAS               | store the revisions other than the latest as manifest deltas, to reduce the size of the history.           |
+| $HELM_STORAGE_ENCRYPTION_KEY       | set the base64 encoded AES keys used to encrypt the release records, see 'helm storage'.                   |
 | $KUBECONFIG                        | set an alternative Kubernetes configuration file (default "~/.kube/config")                                |
 | $HELM_KUBEAPISERVER                | set the Kubernetes API Server Endpoint for authentication                                                  |
 | $HELM_KUBECAFILE                   | set the Kubernetes certificate authority file.                                                             |
@@ -85,6 +98,19 @@ Environment variables:
 | $HELM_COLOR                        | set color output mode. Allowed values: never, always, auto (default: never)                                |
 | $NO_COLOR                          | set to any non-empty value to disable all colored output (overrides $HELM_COLOR)                           |
 
+Flags take their value from the command line first, then from their environment
+variable above, then from the config file, and then from their default. The
+config file sets the default values of flags by name, for every kube-context
+and for given kube-contexts:
+
+    flags:
+      rollback-on-failure: true
+      timeout: 10m
+    contexts:
+      production:
+        flags:
+          timeout: 20m
+
 Helm stores cache, configuration, and data based on the following configuration order:
 
 - If a HELM_*_HOME environment variable is set, it will be used
@@ -102,12 +128,22 @@ By default, the default directories depend on the Operating System. The defaults
 
 var settings = cli.New()
 
+// warnings collects the warnings raised while running a command, such as the
+// use of deprecated flags. They are logged as they are raised, and included
+// in the structured output of the commands that report on a release.
+var warnings = &action.Warnings{Handler: func(w action.Warning) {
+	slog.Warn(w.Message)
+}}
+
 func NewRootCmd(out io.Writer, args []string, logSetup func(bool)) (*cobra.Command, error) {
 	actionConfig := action.NewConfiguration()
 	cmd, err := newRootCmdWithConfig(actionConfig, out, args, logSetup)
 	if err != nil {
 		return nil, err
 	}
+	// Collect the warnings of the Kubernetes API server, such as the use of
+	// deprecated APIs, with the other warnings.
+	rest.SetDefaultWarningHandler(warnings)
 	cobra.OnInitialize(func() {
 		helmDriver := os.Getenv("HELM_DRIVER")
 		if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), helmDriver); err != nil {
@@ -117,7 +153,24 @@ func NewRootCmd(out io.Writer, args []string, logSetup func(bool)) (*cobra.Comma
 			loadReleasesInMemory(actionConfig)
 		}
 		actionConfig.SetHookOutputFunc(hookOutputWriter)
+		actionConfig.ForNamespace = namespaceConfiguration(actionConfig, helmDriver)
+		// A broken notification configuration must not prevent the commands
+		// that do not notify, such as list or get, from running.
+		if err := setupNotifications(actionConfig, settings); err != nil {
+			log.Printf("Warning: notifications are disabled: %v", err)
+		}
 	})
+	if metricsAddr != "" {
+		stopMetrics, err := setupMetrics(actionConfig, metricsAddr)
+		if err != nil {
+			return nil, err
+		}
+		cobra.OnFinalize(func() {
+			if err := stopMetrics(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		})
+	}
 	return cmd, nil
 }
 
@@ -148,15 +201,21 @@ func configureColorOutput(settings *cli.EnvSettings) {
 }
 
 func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, args []string, logSetup func(bool)) (*cobra.Command, error) {
+	resolver := &cli.Resolver{}
 	cmd := &cobra.Command{
 		Use:          "helm",
 		Short:        "The Helm package manager for Kubernetes.",
 		Long:         globalUsage,
 		SilenceUsage: true,
-		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := resolver.Apply(cmd.Flags()); err != nil {
+				return err
+			}
+			warnDeprecatedFlags(cmd.Flags())
 			if err := startProfiling(); err != nil {
 				log.Printf("Warning: Failed to start profiling: %v", err)
 			}
+			return nil
 		},
 		PersistentPostRun: func(_ *cobra.Command, _ []string) {
 			if err := stopProfiling(); err != nil {
@@ -165,6 +224,9 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 		},
 	}
 
+	warnings.Reset()
+	actionConfig.Warnings = warnings
+
 	flags := cmd.PersistentFlags()
 
 	settings.AddFlags(flags)
@@ -177,6 +239,28 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 	flags.ParseErrorsAllowlist.UnknownFlags = true
 	flags.Parse(args)
 
+	// The config file sets the default values of flags. The global flags are
+	// resolved now, as they configure the client, and the flags of the
+	// command once it is parsed.
+	config, err := cli.LoadConfig(settings.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	resolver.Config = config
+	// The kube-context is resolved first, as the config file may set other
+	// defaults for it.
+	if v, source := resolver.Resolve(flags.Lookup("kube-context")); source == cli.SourceConfig {
+		if err := flags.Set("kube-context", v); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.Contexts) > 0 {
+		resolver.Context = settings.CurrentContext()
+	}
+	if _, err := resolver.Apply(flags); err != nil {
+		return nil, err
+	}
+
 	logSetup(settings.Debug)
 
 	// newRootCmdWithConfig is only called from NewRootCmd. NewRootCmd sets up
@@ -200,6 +284,15 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 	// Configure color output based on ColorMode setting
 	configureColorOutput(settings)
 
+	switch settings.ErrorFormat {
+	case "text":
+	case "json":
+		// The error is written by ReportError instead.
+		cmd.SilenceErrors = true
+	default:
+		return nil, fmt.Errorf("invalid error format %q: must be one of: text, json", settings.ErrorFormat)
+	}
+
 	// Setup shell completion for the color flag
 	_ = cmd.RegisterFlagCompletionFunc("color", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return []string{"never", "auto", "always"}, cobra.ShellCompDirectiveNoFileComp
@@ -210,8 +303,12 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 		return []string{"never", "auto", "always"}, cobra.ShellCompDirectiveNoFileComp
 	})
 
+	_ = cmd.RegisterFlagCompletionFunc("error-format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
 	// Setup shell completion for the namespace flag
-	err := cmd.RegisterFlagCompletionFunc("namespace", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	err = cmd.RegisterFlagCompletionFunc("namespace", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		if client, err := actionConfig.KubernetesClientSet(); err == nil {
 			// Choose a long enough timeout that the user notices something is not working
 			// but short enough that the user is not made to wait very long
@@ -266,24 +363,33 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 	// Add subcommands
 	cmd.AddCommand(
 		// chart commands
+		newCacheCmd(out),
+		newConvertCmd(out),
 		newCreateCmd(out),
 		newDependencyCmd(actionConfig, out),
 		newPullCmd(actionConfig, out),
 		newShowCmd(actionConfig, out),
+		newExplainCmd(actionConfig, out),
 		newLintCmd(out),
 		newPackageCmd(out),
+		newReadmeCmd(out),
 		newRepoCmd(out),
+		newKeysCmd(out),
 		newSearchCmd(out),
 		newVerifyCmd(out),
 
 		// release commands
+		newApplyCmd(actionConfig, out),
+		newApplyPlanCmd(actionConfig, out),
 		newGetCmd(actionConfig, out),
 		newHistoryCmd(actionConfig, out),
 		newInstallCmd(actionConfig, out),
 		newListCmd(actionConfig, out),
+		newPromoteCmd(actionConfig, out),
 		newReleaseTestCmd(actionConfig, out),
 		newRollbackCmd(actionConfig, out),
 		newStatusCmd(actionConfig, out),
+		newStorageCmd(actionConfig, out),
 		newTemplateCmd(actionConfig, out),
 		newUninstallCmd(actionConfig, out),
 		newUpgradeCmd(actionConfig, out),
@@ -291,7 +397,7 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 		newCompletionCmd(out),
 		newEnvCmd(out),
 		newPluginCmd(out),
-		newVersionCmd(out),
+		newVersionCmd(actionConfig, out),
 
 		// Hidden documentation generator command: 'helm docs'
 		newDocsCmd(out),
@@ -400,6 +506,25 @@ func checkForExpiredRepos(repofile string) {
 	}
 }
 
+// namespaceConfiguration returns the function that creates the action
+// configuration of other namespaces than the one of the command.
+func namespaceConfiguration(cfg *action.Configuration, helmDriver string) func(string) (*action.Configuration, error) {
+	return func(namespace string) (*action.Configuration, error) {
+		nsCfg := action.NewConfiguration()
+		if err := nsCfg.Init(settings.RESTClientGetter(), namespace, helmDriver); err != nil {
+			return nil, err
+		}
+		if kc, ok := nsCfg.KubeClient.(*kube.Client); ok {
+			kc.Namespace = namespace
+		}
+		nsCfg.SetHookOutputFunc(hookOutputWriter)
+		nsCfg.RegistryClient = cfg.RegistryClient
+		nsCfg.Notifier = cfg.Notifier
+		nsCfg.Metrics = cfg.Metrics
+		return nsCfg, nil
+	}
+}
+
 func newRegistryClient(
 	out io.Writer, certFile, keyFile, caFile string, insecureSkipTLSVerify, plainHTTP bool, username, password string,
 ) (*registry.Client, error) {
@@ -423,7 +548,9 @@ func newDefaultRegistryClient(out io.Writer, plainHTTP bool, username, password
 		registry.ClientOptEnableCache(true),
 		registry.ClientOptWriter(out),
 		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+		registry.ClientOptOffline(settings.Offline),
 		registry.ClientOptBasicAuth(username, password),
+		registry.ClientOptHostConfigs(registryHostConfigs(settings.RepositoryConfig)),
 	}
 	if plainHTTP {
 		opts = append(opts, registry.ClientOptPlainHTTP())
@@ -456,6 +583,7 @@ func newRegistryClientWithTLS(
 		registry.ClientOptEnableCache(true),
 		registry.ClientOptWriter(out),
 		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+		registry.ClientOptOffline(settings.Offline),
 		registry.ClientOptHTTPClient(&http.Client{
 			Transport: &http.Transport{
 				TLSClientConfig: tlsConf,
@@ -463,6 +591,7 @@ func newRegistryClientWithTLS(
 			},
 		}),
 		registry.ClientOptBasicAuth(username, password),
+		registry.ClientOptHostConfigs(registryHostConfigs(settings.RepositoryConfig)),
 	)
 	if err != nil {
 		return nil, err
@@ -470,11 +599,55 @@ func newRegistryClientWithTLS(
 	return registryClient, nil
 }
 
+// registryHostConfigs returns the per registry connection settings stored in
+// the repository config file. A missing or unreadable file yields no settings.
+func registryHostConfigs(repoFile string) map[string]registry.HostConfig {
+	b, err := os.ReadFile(repoFile)
+	if err != nil {
+		return nil
+	}
+	var f repo.File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		slog.Debug("unable to parse repository config", slog.String("path", repoFile), slog.Any("error", err))
+		return nil
+	}
+	if len(f.Registries) == 0 {
+		return nil
+	}
+	configs := make(map[string]registry.HostConfig, len(f.Registries))
+	for _, r := range f.Registries {
+		if r == nil || r.Host == "" {
+			continue
+		}
+		configs[r.Host] = registry.HostConfig{
+			CertFile:              r.CertFile,
+			KeyFile:               r.KeyFile,
+			CAFile:                r.CAFile,
+			InsecureSkipTLSVerify: r.InsecureSkipTLSVerify,
+			Proxy:                 r.Proxy,
+		}
+	}
+	return configs
+}
+
 type CommandError struct {
 	error
 	ExitCode int
 }
 
+// ReportError writes err to w as a JSON object holding its message and its
+// action.ErrorCode when --error-format=json is set. In the default text format
+// the error is printed by cobra and nothing is written.
+func ReportError(w io.Writer, err error) {
+	if err == nil || settings.ErrorFormat != "json" {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(struct {
+		Code  action.ErrorCode `json:"code"`
+		Error string           `json:"error"`
+	}{action.ErrorCodeOf(err), err.Error()})
+}
+
 // releaserToV1Release is a helper function to convert a v1 release passed by interface
 // into the type object.
 func releaserToV1Release(rel ri.Releaser) (*release.Release, error) {
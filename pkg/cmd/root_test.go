@@ -18,13 +18,16 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v4/internal/test/ensure"
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/helmpath/xdg"
 )
@@ -149,3 +152,101 @@ func TestRootCmdLogger(t *testing.T) {
 		t.Error("expected actionConfig logger to be the slog default logger")
 	}
 }
+
+func TestRootCmdErrorFormat(t *testing.T) {
+	defer func() { settings.ErrorFormat = "text" }()
+
+	actionConfig := action.NewConfiguration()
+	if _, err := newRootCmdWithConfig(actionConfig, new(bytes.Buffer), []string{"--error-format", "yaml"}, SetupLogging); err == nil {
+		t.Error("expected an error for an invalid error format")
+	}
+
+	cmd, err := newRootCmdWithConfig(actionConfig, new(bytes.Buffer), []string{"--error-format", "json"}, SetupLogging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmd.SilenceErrors {
+		t.Error("expected cobra not to print errors with --error-format=json")
+	}
+}
+
+func TestRootCmdConfigFile(t *testing.T) {
+	defer resetEnv()()
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, []byte(`flags:
+  namespace: fromconfig
+  output: json
+contexts:
+  staging:
+    flags:
+      namespace: fromcontext
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, args string
+		envvars    map[string]string
+		expect     string
+	}{
+		{
+			name:   "config file",
+			args:   "env HELM_NAMESPACE",
+			expect: `{"HELM_NAMESPACE":"fromconfig"}`,
+		},
+		{
+			name:   "kube-context of the config file",
+			args:   "env HELM_NAMESPACE --kube-context staging",
+			expect: `{"HELM_NAMESPACE":"fromcontext"}`,
+		},
+		{
+			name:    "environment variable over config file",
+			args:    "env HELM_NAMESPACE",
+			envvars: map[string]string{"HELM_NAMESPACE": "fromenv"},
+			expect:  `{"HELM_NAMESPACE":"fromenv"}`,
+		},
+		{
+			name:    "flag over environment variable",
+			args:    "env HELM_NAMESPACE --namespace fromflag --output table",
+			envvars: map[string]string{"HELM_NAMESPACE": "fromenv"},
+			expect:  "fromflag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HELM_CONFIG_FILE", config)
+			for k, v := range tt.envvars {
+				t.Setenv(k, v)
+			}
+			settings = cli.New()
+
+			_, out, err := executeActionCommand(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := strings.TrimSpace(out); got != tt.expect {
+				t.Errorf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestReportError(t *testing.T) {
+	defer func() { settings.ErrorFormat = "text" }()
+	err := fmt.Errorf("UPGRADE FAILED: %w", action.ErrWaitTimeout)
+
+	buf := new(bytes.Buffer)
+	ReportError(buf, err)
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written in the text format, got %q", buf.String())
+	}
+
+	settings.ErrorFormat = "json"
+	ReportError(buf, err)
+	expect := `{"code":"WAIT_TIMEOUT","error":"UPGRADE FAILED: timed out waiting for resources"}` + "\n"
+	if buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+}
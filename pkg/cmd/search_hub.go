@@ -56,6 +56,7 @@ type searchHubOptions struct {
 	maxColWidth    uint
 	outputFormat   output.Format
 	listRepoURL    bool
+	listSecurity   bool
 	failOnNoResult bool
 }
 
@@ -75,6 +76,7 @@ func newSearchHubCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.searchEndpoint, "endpoint", "https://hub.helm.sh", "Hub instance to query for charts")
 	f.UintVar(&o.maxColWidth, "max-col-width", 50, "maximum column width for output table")
 	f.BoolVar(&o.listRepoURL, "list-repo-url", false, "print charts repository URL")
+	f.BoolVar(&o.listSecurity, "list-security", false, "print verified publisher, deprecation and security report information")
 	f.BoolVar(&o.failOnNoResult, "fail-on-no-result", false, "search fails if no results are found")
 
 	bindOutputFlag(cmd, &o.outputFormat)
@@ -95,7 +97,7 @@ func (o *searchHubOptions) run(ctx context.Context, out io.Writer, args []string
 		return fmt.Errorf("unable to perform search against %q", o.searchEndpoint)
 	}
 
-	return o.outputFormat.Write(out, newHubSearchWriter(results, o.searchEndpoint, o.maxColWidth, o.listRepoURL, o.failOnNoResult))
+	return o.outputFormat.Write(out, newHubSearchWriter(results, o.searchEndpoint, o.maxColWidth, o.listRepoURL, o.listSecurity, o.failOnNoResult))
 }
 
 type hubChartRepo struct {
@@ -103,22 +105,37 @@ type hubChartRepo struct {
 	Name string `json:"name"`
 }
 
+type hubSecurityReport struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
 type hubChartElement struct {
-	URL         string       `json:"url"`
-	Version     string       `json:"version"`
-	AppVersion  string       `json:"app_version"`
-	Description string       `json:"description"`
-	Repository  hubChartRepo `json:"repository"`
+	URL               string             `json:"url"`
+	Version           string             `json:"version"`
+	AppVersion        string             `json:"app_version"`
+	Description       string             `json:"description"`
+	Repository        hubChartRepo       `json:"repository"`
+	Stars             int                `json:"stars"`
+	Deprecated        bool               `json:"deprecated"`
+	Signed            bool               `json:"signed"`
+	VerifiedPublisher bool               `json:"verified_publisher"`
+	Official          bool               `json:"official"`
+	SecurityReport    *hubSecurityReport `json:"security_report,omitempty"`
 }
 
 type hubSearchWriter struct {
 	elements       []hubChartElement
 	columnWidth    uint
 	listRepoURL    bool
+	listSecurity   bool
 	failOnNoResult bool
 }
 
-func newHubSearchWriter(results []monocular.SearchResult, endpoint string, columnWidth uint, listRepoURL, failOnNoResult bool) *hubSearchWriter {
+func newHubSearchWriter(results []monocular.SearchResult, endpoint string, columnWidth uint, listRepoURL, listSecurity, failOnNoResult bool) *hubSearchWriter {
 	var elements []hubChartElement
 	for _, r := range results {
 		// Backwards compatibility for Monocular
@@ -129,9 +146,34 @@ func newHubSearchWriter(results []monocular.SearchResult, endpoint string, colum
 			url = r.ArtifactHub.PackageURL
 		}
 
-		elements = append(elements, hubChartElement{url, r.Relationships.LatestChartVersion.Data.Version, r.Relationships.LatestChartVersion.Data.AppVersion, r.Attributes.Description, hubChartRepo{URL: r.Attributes.Repo.URL, Name: r.Attributes.Repo.Name}})
+		e := hubChartElement{
+			URL:               url,
+			Version:           r.Relationships.LatestChartVersion.Data.Version,
+			AppVersion:        r.Relationships.LatestChartVersion.Data.AppVersion,
+			Description:       r.Attributes.Description,
+			Repository:        hubChartRepo{URL: r.Attributes.Repo.URL, Name: r.Attributes.Repo.Name},
+			Stars:             r.ArtifactHub.Stars,
+			Deprecated:        r.ArtifactHub.Deprecated,
+			Signed:            r.ArtifactHub.Signed,
+			VerifiedPublisher: r.ArtifactHub.VerifiedPublisher,
+			Official:          r.ArtifactHub.Official,
+		}
+		if s := r.ArtifactHub.SecurityReportSummary; s != nil {
+			e.SecurityReport = &hubSecurityReport{s.Critical, s.High, s.Medium, s.Low, s.Unknown}
+		}
+		elements = append(elements, e)
 	}
-	return &hubSearchWriter{elements, columnWidth, listRepoURL, failOnNoResult}
+	return &hubSearchWriter{elements, columnWidth, listRepoURL, listSecurity, failOnNoResult}
+}
+
+// securitySummary renders the security report as a compact string for table
+// output. Charts without a report are shown as "-".
+func (r hubChartElement) securitySummary() string {
+	if r.SecurityReport == nil {
+		return "-"
+	}
+	s := r.SecurityReport
+	return fmt.Sprintf("C:%d H:%d M:%d L:%d U:%d", s.Critical, s.High, s.Medium, s.Low, s.Unknown)
 }
 
 func (h *hubSearchWriter) WriteTable(out io.Writer) error {
@@ -150,18 +192,24 @@ func (h *hubSearchWriter) WriteTable(out io.Writer) error {
 	table := uitable.New()
 	table.MaxColWidth = h.columnWidth
 
+	header := []any{"URL", "CHART VERSION", "APP VERSION", "DESCRIPTION"}
 	if h.listRepoURL {
-		table.AddRow("URL", "CHART VERSION", "APP VERSION", "DESCRIPTION", "REPO URL")
-	} else {
-		table.AddRow("URL", "CHART VERSION", "APP VERSION", "DESCRIPTION")
+		header = append(header, "REPO URL")
+	}
+	if h.listSecurity {
+		header = append(header, "VERIFIED", "DEPRECATED", "VULNERABILITIES")
 	}
+	table.AddRow(header...)
 
 	for _, r := range h.elements {
+		row := []any{r.URL, r.Version, r.AppVersion, r.Description}
 		if h.listRepoURL {
-			table.AddRow(r.URL, r.Version, r.AppVersion, r.Description, r.Repository.URL)
-		} else {
-			table.AddRow(r.URL, r.Version, r.AppVersion, r.Description)
+			row = append(row, r.Repository.URL)
+		}
+		if h.listSecurity {
+			row = append(row, r.VerifiedPublisher, r.Deprecated, r.securitySummary())
 		}
+		table.AddRow(row...)
 	}
 	return output.EncodeTable(out, table)
 }
@@ -183,9 +231,7 @@ func (h *hubSearchWriter) encodeByFormat(out io.Writer, format output.Format) er
 	// Initialize the array so no results returns an empty array instead of null
 	chartList := make([]hubChartElement, 0, len(h.elements))
 
-	for _, r := range h.elements {
-		chartList = append(chartList, hubChartElement{r.URL, r.Version, r.AppVersion, r.Description, r.Repository})
-	}
+	chartList = append(chartList, h.elements...)
 
 	switch format {
 	case output.JSON:
@@ -183,3 +183,45 @@ func TestSearchHubCmd_FailOnNoResponseTests(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchHubListSecurityCmd(t *testing.T) {
+	// Setup a mock search service returning Artifact Hub specific data
+	var searchResult = `{"data":[{"id":"stable/phpmyadmin","artifactHub":{"packageUrl":"https://artifacthub.io/packages/helm/stable/phpmyadmin","stars":3,"deprecated":true,"verifiedPublisher":false},"type":"chart","attributes":{"name":"phpmyadmin","repo":{"name":"stable","url":"https://charts.helm.sh/stable"},"description":"phpMyAdmin"},"relationships":{"latestChartVersion":{"data":{"version":"3.0.0","app_version":"4.9.0-1"}}}},{"id":"bitnami/phpmyadmin","artifactHub":{"packageUrl":"https://artifacthub.io/packages/helm/bitnami/phpmyadmin","stars":10,"signed":true,"verifiedPublisher":true,"securityReportSummary":{"critical":1,"high":2,"medium":3,"low":4,"unknown":0}},"type":"chart","attributes":{"name":"phpmyadmin","repo":{"name":"bitnami","url":"https://charts.bitnami.com"},"description":"phpMyAdmin"},"relationships":{"latestChartVersion":{"data":{"version":"3.0.0","app_version":"4.9.0-1"}}}}]}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, searchResult)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		cmd      string
+		expected string
+	}{
+		{
+			name: "table output",
+			cmd:  "search hub --list-security --max-col-width 60 --endpoint " + ts.URL + " maria",
+			// Trailing spaces are necessary to preserve as the uitable package adds them during printing.
+			expected: `URL                                                    	CHART VERSION	APP VERSION	DESCRIPTION	VERIFIED	DEPRECATED	VULNERABILITIES    
+https://artifacthub.io/packages/helm/stable/phpmyadmin 	3.0.0        	4.9.0-1    	phpMyAdmin 	false   	true      	-                  
+https://artifacthub.io/packages/helm/bitnami/phpmyadmin	3.0.0        	4.9.0-1    	phpMyAdmin 	true    	false     	C:1 H:2 M:3 L:4 U:0
+`,
+		},
+		{
+			name:     "json output",
+			cmd:      "search hub --endpoint " + ts.URL + " maria -o json",
+			expected: `[{"url":"https://artifacthub.io/packages/helm/stable/phpmyadmin","version":"3.0.0","app_version":"4.9.0-1","description":"phpMyAdmin","repository":{"url":"https://charts.helm.sh/stable","name":"stable"},"stars":3,"deprecated":true,"signed":false,"verified_publisher":false,"official":false},{"url":"https://artifacthub.io/packages/helm/bitnami/phpmyadmin","version":"3.0.0","app_version":"4.9.0-1","description":"phpMyAdmin","repository":{"url":"https://charts.bitnami.com","name":"bitnami"},"stars":10,"deprecated":false,"signed":true,"verified_publisher":true,"official":false,"security_report":{"critical":1,"high":2,"medium":3,"low":4,"unknown":0}}]` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out, err := executeActionCommandC(storageFixture(), tt.cmd)
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			if out != tt.expected {
+				t.Errorf("expected and actual output did not match\nexpected: %q\nactual  : %q", tt.expected, out)
+			}
+		})
+	}
+}
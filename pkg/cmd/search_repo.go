@@ -43,8 +43,16 @@ looks for matches. Search of these repositories uses the metadata stored on
 the system.
 
 It will display the latest stable versions of the charts found. If you
-specify the --devel flag, the output will include pre-release versions.
-If you want to search using a version constraint, use --version.
+specify the --devel or --include-prereleases flag, the output will include
+pre-release versions. If you want to search using a version constraint, use
+--version.
+
+With --channel, the output shows the latest versions of a release channel,
+such as stable, beta or edge. Chart versions are tagged with their channels by
+the 'helm.sh/channels' annotation of their Chart.yaml. Untagged versions are
+stable, or edge when they are pre-releases, and a version of a channel is also
+a version of the riskier channels: the beta channel includes the stable
+versions.
 
 Examples:
 
@@ -57,6 +65,9 @@ Examples:
     # Search for the latest stable release for nginx-ingress with a major version of 1
     $ helm search repo nginx-ingress --version ^1.0.0
 
+    # Search for the latest releases of the beta channel matching the keyword "nginx"
+    $ helm search repo nginx --channel beta
+
 Repositories are managed with 'helm repo' commands.
 `
 
@@ -68,6 +79,7 @@ type searchRepoOptions struct {
 	regexp         bool
 	devel          bool
 	version        string
+	channel        string
 	maxColWidth    uint
 	repoFile       string
 	repoCacheDir   string
@@ -93,7 +105,9 @@ func newSearchRepoCmd(out io.Writer) *cobra.Command {
 	f.BoolVarP(&o.regexp, "regexp", "r", false, "use regular expressions for searching repositories you have added")
 	f.BoolVarP(&o.versions, "versions", "l", false, "show the long listing, with each version of each chart on its own line, for repositories you have added")
 	f.BoolVar(&o.devel, "devel", false, "use development versions (alpha, beta, and release candidate releases), too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
+	f.BoolVar(&o.devel, "include-prereleases", false, "include the pre-release versions, the same as --devel")
 	f.StringVar(&o.version, "version", "", "search using semantic versioning constraints on repositories you have added")
+	f.StringVar(&o.channel, "channel", "", "search the versions of this release channel, such as stable, beta or edge")
 	f.UintVar(&o.maxColWidth, "max-col-width", 50, "maximum column width for output table")
 	f.BoolVar(&o.failOnNoResult, "fail-on-no-result", false, "search fails if no results are found")
 
@@ -137,7 +151,7 @@ func (o *searchRepoOptions) setupSearchedVersion() {
 		return
 	}
 
-	if o.devel { // search for releases and prereleases (alpha, beta, and release candidate releases).
+	if o.devel || o.channel != "" { // search for releases and prereleases (alpha, beta, and release candidate releases).
 		slog.Debug("setting version to >0.0.0-0")
 		o.version = ">0.0.0-0"
 	} else { // search only for stable releases, prerelease versions will be skipped
@@ -168,7 +182,7 @@ func (o *searchRepoOptions) applyConstraint(res []*search.Result) ([]*search.Res
 		if err != nil {
 			continue
 		}
-		if constraint.Check(v) {
+		if constraint.Check(v) && (o.channel == "" || r.Chart.InChannel(o.channel)) {
 			data = append(data, r)
 			foundNames[r.Name] = true
 		}
@@ -338,6 +352,16 @@ func compListCharts(toComplete string, includeFiles bool) ([]string, cobra.Shell
 	}
 	cobra.CompDebugln(fmt.Sprintf("Completions after repos: %v", completions), settings.Debug)
 
+	// Complete the tags of an OCI repository
+	if strings.HasPrefix(toComplete, "oci://") {
+		if i := strings.LastIndex(toComplete, ":"); i > strings.LastIndex(toComplete, "/") {
+			for _, tag := range compOCITags(toComplete[:i]) {
+				completions = append(completions, toComplete[:i+1]+tag)
+			}
+		}
+	}
+	cobra.CompDebugln(fmt.Sprintf("Completions after OCI tags: %v", completions), settings.Debug)
+
 	// Now handle completions for url prefixes
 	for _, url := range []string{"oci://\tChart OCI prefix", "https://\tChart URL prefix", "http://\tChart URL prefix", "file://\tChart local URL prefix"} {
 		if strings.HasPrefix(toComplete, url) {
@@ -32,6 +32,18 @@ func TestSearchRepositoriesCmd(t *testing.T) {
 		name:   "search for 'alpine', expect one match with newest development version",
 		cmd:    "search repo alpine --devel",
 		golden: "output/search-multiple-devel-release.txt",
+	}, {
+		name:   "search for 'alpine' with --include-prereleases, expect one match with newest development version",
+		cmd:    "search repo alpine --include-prereleases",
+		golden: "output/search-multiple-devel-release.txt",
+	}, {
+		name:   "search for 'alpine' in the edge channel, expect the untagged release candidate",
+		cmd:    "search repo alpine --channel edge",
+		golden: "output/search-multiple-devel-release.txt",
+	}, {
+		name:   "search for 'alpine' in the beta channel, expect the latest stable version",
+		cmd:    "search repo alpine --channel beta",
+		golden: "output/search-multiple-stable-release.txt",
 	}, {
 		name:   "search for 'alpine' with versions, expect three matches",
 		cmd:    "search repo alpine --versions",
@@ -22,9 +22,11 @@ import (
 	"log"
 	"log/slog"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
 )
 
@@ -57,8 +59,21 @@ This command inspects a chart (directory, file, or URL) and displays the content
 of the CustomResourceDefinition files
 `
 
+const showVersionsDesc = `
+This command lists the versions of a chart in an OCI registry, from the highest
+to the lowest, like the versions listed in the index.yaml of a chart repository:
+
+    $ helm show versions oci://registry.example.com/charts/mychart
+
+Only the tags that are semantic versions are listed. Prereleases are listed
+with '--devel'. The versions can be filtered with a constraint:
+
+    $ helm show versions oci://registry.example.com/charts/mychart --version '^1.2.0'
+`
+
 func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewShow(action.ShowAll, cfg)
+	var valuesDocs bool
 
 	showCommand := &cobra.Command{
 		Use:     "show",
@@ -109,6 +124,17 @@ func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if valuesDocs {
+				cp, err := locateShowChart(args[0], client)
+				if err != nil {
+					return err
+				}
+				docs, err := client.ValuesDocs(cp)
+				if err != nil {
+					return err
+				}
+				return output.Table.Write(out, &valuesDocsWriter{docs: docs})
+			}
 			output, err := runShow(args, client)
 			if err != nil {
 				return err
@@ -181,11 +207,14 @@ func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		},
 	}
 
+	valuesSubCmd.Flags().BoolVar(&valuesDocs, "docs", false, "show the documentation of the values, from the comments of values.yaml and the descriptions of values.schema.json, instead of values.yaml")
 	cmds := []*cobra.Command{all, readmeSubCmd, valuesSubCmd, chartSubCmd, crdsSubCmd}
 	for _, subCmd := range cmds {
 		addShowFlags(subCmd, client)
 		showCommand.AddCommand(subCmd)
 	}
+	valuesSubCmd.MarkFlagsMutuallyExclusive("docs", "jsonpath")
+	showCommand.AddCommand(newShowVersionsCmd(out))
 
 	return showCommand
 }
@@ -212,17 +241,22 @@ func addShowFlags(subCmd *cobra.Command, client *action.Show) {
 }
 
 func runShow(args []string, client *action.Show) (string, error) {
+	cp, err := locateShowChart(args[0], client)
+	if err != nil {
+		return "", err
+	}
+	return client.Run(cp)
+}
+
+// locateShowChart returns the path of the chart to show, downloading it if
+// needed.
+func locateShowChart(chartRef string, client *action.Show) (string, error) {
 	slog.Debug("original chart version", "version", client.Version)
 	if client.Version == "" && client.Devel {
 		slog.Debug("setting version to >0.0.0-0")
 		client.Version = ">0.0.0-0"
 	}
-
-	cp, err := client.LocateChart(args[0], settings)
-	if err != nil {
-		return "", err
-	}
-	return client.Run(cp)
+	return client.LocateChart(chartRef, settings)
 }
 
 func addRegistryClient(out io.Writer, client *action.Show) error {
@@ -234,3 +268,70 @@ func addRegistryClient(out io.Writer, client *action.Show) error {
 	client.SetRegistryClient(registryClient)
 	return nil
 }
+
+func newShowVersionsCmd(out io.Writer) *cobra.Command {
+	client := action.NewShow(action.ShowAll, &action.Configuration{})
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "versions [CHART]",
+		Short:             "show the versions of a chart in an OCI registry",
+		Long:              showVersionsDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := addRegistryClient(out, client); err != nil {
+				return err
+			}
+			versions, err := client.Versions(args[0])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &versionsWriter{versions: versions})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.Version, "version", "", "list only the versions that satisfy this version constraint")
+	f.BoolVar(&client.Devel, "devel", false, "list development versions, too. Equivalent to version '>=0.0.0-0'. If --version is set, this is ignored")
+	f.IntVar(&client.MaxVersions, "max", 0, "maximum number of versions to list, the highest first")
+	f.StringVar(&client.CertFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&client.KeyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&client.CaFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&client.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the registry")
+	f.BoolVar(&client.PlainHTTP, "plain-http", false, "use insecure HTTP connections for the registry")
+	f.StringVar(&client.Username, "username", "", "registry username")
+	f.StringVar(&client.Password, "password", "", "registry password")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type versionsWriter struct {
+	versions []string
+}
+
+func (w *versionsWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("VERSION")
+	for _, v := range w.versions {
+		table.AddRow(v)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *versionsWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.list())
+}
+
+func (w *versionsWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.list())
+}
+
+// list returns the versions, with an empty list instead of null.
+func (w *versionsWriter) list() []string {
+	if w.versions == nil {
+		return []string{}
+	}
+	return w.versions
+}
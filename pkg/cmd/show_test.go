@@ -17,11 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/repo/v1/repotest"
 )
 
@@ -156,3 +158,39 @@ func TestShowValuesFileCompletion(t *testing.T) {
 func TestShowCRDsFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "show crds", true)
 }
+
+func TestShowVersionsFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "show versions", false)
+}
+
+func TestShowVersionsNotOCI(t *testing.T) {
+	_, _, err := executeActionCommand("show versions testing/alpine")
+	if err == nil || !strings.Contains(err.Error(), "only supported for OCI references") {
+		t.Errorf("expected an error for a non-OCI reference, got %v", err)
+	}
+}
+
+func TestVersionsWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		format   output.Format
+		want     string
+	}{
+		{"table", []string{"1.1.0", "1.0.0"}, output.Table, "VERSION\n1.1.0  \n1.0.0  \n"},
+		{"json", []string{"1.1.0", "1.0.0"}, output.JSON, "[\"1.1.0\",\"1.0.0\"]\n"},
+		{"yaml", []string{"1.1.0", "1.0.0"}, output.YAML, "- 1.1.0\n- 1.0.0\n"},
+		{"empty json", nil, output.JSON, "[]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.format.Write(&buf, &versionsWriter{versions: tt.versions}); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, buf.String())
+			}
+		})
+	}
+}
@@ -21,11 +21,15 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/kubectl/pkg/cmd/get"
 
 	coloroutput "helm.sh/helm/v4/internal/cli/output"
@@ -35,6 +39,7 @@ import (
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/release"
 	releasev1 "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
 )
 
 // NOTE: Keep the list of statuses up-to-date with pkg/release/status.go.
@@ -49,11 +54,17 @@ The status consists of:
 - list of resources that this release consists of
 - details on last test suite run, if applicable
 - additional notes provided by the chart
+
+With --resources, only a table of the resources of the release is shown, with
+their readiness, status and age as reported by the cluster, like
+'kubectl get' does.
 `
 
 func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewStatus(cfg)
 	var outfmt output.Format
+	var showSecrets bool
+	var showResources bool
 
 	cmd := &cobra.Command{
 		Use:   "status RELEASE_NAME",
@@ -72,6 +83,8 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			// returned. This mirrors the handling in kubectl.
 			if outfmt == output.Table {
 				client.ShowResourcesTable = true
+			} else if showResources {
+				return fmt.Errorf("--resources can only be used with the %s output format", output.Table)
 			}
 			reli, err := client.Run(args[0])
 			if err != nil {
@@ -81,6 +94,13 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if showResources {
+				return printResourcesTable(out, rel.Info.Resources, rel.Manifest, time.Now())
+			}
+
+			if !showSecrets {
+				rel = redactRelease(rel)
+			}
 
 			// strip chart metadata from the output
 			rel.Chart = nil
@@ -91,6 +111,7 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				showMetadata: false,
 				hideNotes:    false,
 				noColor:      settings.ShouldDisableColor(),
+				warnings:     warnings.List(),
 			})
 		},
 	}
@@ -109,35 +130,125 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		log.Fatal(err)
 	}
 
+	f.BoolVar(&showResources, "resources", false, "only show a table of the resources of the release with their readiness, status and age")
+	f.BoolVar(&showSecrets, "show-secrets", false, "show the data of Secrets in the manifests and the sensitive values instead of masking them")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
 
+// endpointAddresses describes where an endpoint can be reached, as its
+// addresses with the ports of a Service, followed by the hosts of an Ingress.
+func endpointAddresses(e releasev1.Endpoint) string {
+	if len(e.Addresses) == 0 {
+		return "<pending>"
+	}
+	var addresses []string
+	for _, a := range e.Addresses {
+		if len(e.Ports) == 0 {
+			addresses = append(addresses, a)
+		}
+		for _, p := range e.Ports {
+			addresses = append(addresses, net.JoinHostPort(a, strconv.Itoa(int(p))))
+		}
+	}
+	s := strings.Join(addresses, ", ")
+	if len(e.Hosts) > 0 {
+		s += " (hosts: " + strings.Join(e.Hosts, ", ") + ")"
+	}
+	return s
+}
+
+// resourceKeys returns the keys of the resources of a release in the order
+// their kinds first appear in the manifest. Other keys, like those of related
+// pods, follow in alphabetical order.
+func resourceKeys(resources map[string][]runtime.Object, manifest string) []string {
+	var keys []string
+	// An unparsable manifest leaves every key in alphabetical order.
+	objects, _ := releaseutil.ParseManifestObjects(manifest)
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		key := gvk.Version + "/" + gvk.Kind
+		if _, ok := resources[key]; ok && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range resources {
+		if !slices.Contains(keys, key) {
+			rest = append(rest, key)
+		}
+	}
+	slices.Sort(rest)
+	return append(keys, rest...)
+}
+
 type statusPrinter struct {
 	release      release.Releaser
 	debug        bool
 	showMetadata bool
 	hideNotes    bool
 	noColor      bool
+	// redactSecrets masks the data of the Secrets in the manifests, and the
+	// values the chart schema marks as sensitive.
+	redactSecrets bool
+	// warnings are included in the structured output.
+	warnings []action.Warning
 }
 
 func (s statusPrinter) getV1Release() *releasev1.Release {
-	switch rel := s.release.(type) {
+	var rel *releasev1.Release
+	switch r := s.release.(type) {
 	case releasev1.Release:
-		return &rel
+		rel = &r
 	case *releasev1.Release:
-		return rel
+		rel = r
+	default:
+		return &releasev1.Release{}
+	}
+	if s.redactSecrets {
+		return redactRelease(rel)
+	}
+	return rel
+}
+
+// redactRelease returns a copy of the release with the data of the Secrets in
+// its manifest and hooks masked, as well as the values its chart marks as
+// sensitive.
+func redactRelease(rel *releasev1.Release) *releasev1.Release {
+	if rel == nil {
+		return nil
 	}
-	return &releasev1.Release{}
+	out := *rel
+	out.Config = redactSensitiveValues(rel, rel.Config)
+	out.Manifest = releaseutil.RedactSecrets(rel.Manifest)
+	out.Hooks = make([]*releasev1.Hook, len(rel.Hooks))
+	for i, h := range rel.Hooks {
+		hook := *h
+		hook.Manifest = releaseutil.RedactSecrets(h.Manifest)
+		out.Hooks[i] = &hook
+	}
+	return &out
 }
 
 func (s statusPrinter) WriteJSON(out io.Writer) error {
-	return output.EncodeJSON(out, s.getV1Release())
+	return output.EncodeJSON(out, s.structured())
 }
 
 func (s statusPrinter) WriteYAML(out io.Writer) error {
-	return output.EncodeYAML(out, s.getV1Release())
+	return output.EncodeYAML(out, s.structured())
+}
+
+// structured returns the release, with the warnings when there are any.
+func (s statusPrinter) structured() any {
+	rel := s.getV1Release()
+	if len(s.warnings) == 0 {
+		return rel
+	}
+	return struct {
+		*releasev1.Release
+		Warnings []action.Warning `json:"warnings"`
+	}{rel, s.warnings}
 }
 
 func (s statusPrinter) WriteTable(out io.Writer) error {
@@ -158,6 +269,10 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		_, _ = fmt.Fprintf(out, "APP_VERSION: %s\n", rel.Chart.Metadata.AppVersion)
 	}
 	_, _ = fmt.Fprintf(out, "DESCRIPTION: %s\n", rel.Info.Description)
+	if src := rel.ChartSource; src != nil && src.Verification != nil {
+		v := src.Verification
+		_, _ = fmt.Fprintf(out, "VERIFIED: %s by %s at %s\n", v.Method, strings.Join(v.Signers, ", "), v.Time.Format(time.ANSIC))
+	}
 
 	if len(rel.Info.Resources) > 0 {
 		buf := new(bytes.Buffer)
@@ -165,12 +280,7 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		typePrinter, _ := printFlags.ToPrinter("")
 		printer := &get.TablePrinter{Delegate: typePrinter}
 
-		var keys []string
-		for key := range rel.Info.Resources {
-			keys = append(keys, key)
-		}
-
-		for _, t := range keys {
+		for _, t := range resourceKeys(rel.Info.Resources, rel.Manifest) {
 			_, _ = fmt.Fprintf(buf, "==> %s\n", t)
 
 			vk := rel.Info.Resources[t]
@@ -186,6 +296,13 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		_, _ = fmt.Fprintf(out, "RESOURCES:\n%s\n", buf.String())
 	}
 
+	if len(rel.Info.Endpoints) > 0 {
+		_, _ = fmt.Fprintln(out, "ENDPOINTS:")
+		for _, e := range rel.Info.Endpoints {
+			_, _ = fmt.Fprintf(out, "  %s %s/%s: %s\n", e.Kind, e.Namespace, e.Name, endpointAddresses(e))
+		}
+	}
+
 	executions := executionsByHookEvent(rel)
 	if tests, ok := executions[releasev1.HookTest]; !ok || len(tests) == 0 {
 		_, _ = fmt.Fprintln(out, "TEST SUITE: None")
@@ -217,6 +334,9 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		if err != nil {
 			return err
 		}
+		if s.redactSecrets {
+			cfg = redactSensitiveValues(rel, cfg)
+		}
 
 		_, _ = fmt.Fprintln(out, "COMPUTED VALUES:")
 		err = output.EncodeYAML(out, cfg.AsMap())
@@ -242,6 +362,19 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 	return nil
 }
 
+// redactSensitiveValues redacts the values of a release that its chart marks
+// as sensitive.
+func redactSensitiveValues(rel *releasev1.Release, vals map[string]any) map[string]any {
+	if rel.Chart == nil {
+		return vals
+	}
+	paths, err := util.SensitivePaths(rel.Chart)
+	if err != nil || len(paths) == 0 {
+		return vals
+	}
+	return util.RedactSensitiveValues(vals, paths)
+}
+
 func executionsByHookEvent(rel *releasev1.Release) map[releasev1.HookEvent][]*releasev1.Hook {
 	result := make(map[releasev1.HookEvent][]*releasev1.Hook)
 	for _, h := range rel.Hooks {
@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// resourceColumns are the columns of the table printed by
+// 'helm status --resources'.
+var resourceColumns = []metav1.TableColumnDefinition{
+	{Name: "Namespace", Type: "string"},
+	{Name: "Name", Type: "string"},
+	{Name: "Ready", Type: "string"},
+	{Name: "Status", Type: "string"},
+	{Name: "Age", Type: "string"},
+}
+
+// printResourcesTable prints a row for every object of the resources of a
+// release, in the order of resourceKeys. The ready, status and age columns
+// are taken from the tables the API server returns for the objects, like
+// 'kubectl get' does.
+func printResourcesTable(out io.Writer, resources map[string][]runtime.Object, manifest string, now time.Time) error {
+	table := &metav1.Table{ColumnDefinitions: resourceColumns}
+	for _, key := range resourceKeys(resources, manifest) {
+		for _, obj := range resources[key] {
+			table.Rows = append(table.Rows, resourceRows(obj, now)...)
+		}
+	}
+	if len(table.Rows) == 0 {
+		_, err := fmt.Fprintln(out, "No resources found")
+		return err
+	}
+	return printers.NewTablePrinter(printers.PrintOptions{}).PrintObj(table, out)
+}
+
+// resourceRows returns the rows of the resources table for an object, which
+// is a table when the API server returned one.
+func resourceRows(obj runtime.Object, now time.Time) []metav1.TableRow {
+	table, ok := asTable(obj)
+	if !ok {
+		return []metav1.TableRow{resourceRow(obj, "", "", "", now)}
+	}
+
+	column := func(name string) int {
+		for i, c := range table.ColumnDefinitions {
+			if strings.EqualFold(c.Name, name) {
+				return i
+			}
+		}
+		return -1
+	}
+	cell := func(row metav1.TableRow, i int) string {
+		if i < 0 || i >= len(row.Cells) {
+			return ""
+		}
+		return fmt.Sprint(row.Cells[i])
+	}
+	ready, status, age := column("Ready"), column("Status"), column("Age")
+
+	var rows []metav1.TableRow
+	for _, row := range table.Rows {
+		rowObj := row.Object.Object
+		if rowObj == nil && row.Object.Raw != nil {
+			rowObj, _ = runtime.Decode(unstructured.UnstructuredJSONScheme, row.Object.Raw)
+		}
+		if rowObj == nil {
+			continue
+		}
+		rows = append(rows, resourceRow(rowObj, cell(row, ready), cell(row, status), cell(row, age), now))
+	}
+	return rows
+}
+
+// resourceRow returns the row of an object. The name is prefixed with the
+// kind and group of the object, and the age is computed from the creation
+// timestamp when the server did not return it.
+func resourceRow(obj runtime.Object, ready, status, age string, now time.Time) metav1.TableRow {
+	var namespace, name string
+	if m, err := meta.Accessor(obj); err == nil {
+		namespace, name = m.GetNamespace(), m.GetName()
+		if created := m.GetCreationTimestamp(); age == "" && !created.IsZero() {
+			age = duration.HumanDuration(now.Sub(created.Time))
+		}
+	}
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if gk.Kind != "" {
+		name = strings.ToLower(gk.String()) + "/" + name
+	}
+	return metav1.TableRow{Cells: []any{
+		orNone(namespace), name, orNone(ready), orNone(status), orNone(age),
+	}}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// asTable decodes an object the API server returned as a table.
+func asTable(obj runtime.Object) (*metav1.Table, bool) {
+	if t, ok := obj.(*metav1.Table); ok {
+		return t, true
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false
+	}
+	gvk := u.GroupVersionKind()
+	if gvk.Kind != "Table" || (gvk.GroupVersion() != metav1.SchemeGroupVersion && gvk.GroupVersion() != (schema.GroupVersion{Group: metav1.GroupName, Version: "v1beta1"})) {
+		return nil, false
+	}
+	table := &metav1.Table{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, table); err != nil {
+		return nil, false
+	}
+	return table, true
+}
@@ -17,9 +17,14 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"slices"
 	"testing"
 	"time"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
@@ -44,6 +49,42 @@ func TestStatusCmd(t *testing.T) {
 		rels: releasesMockWithStatus(&release.Info{
 			Status: common.StatusDeployed,
 		}),
+	}, {
+		name:   "get status of a release with endpoints",
+		cmd:    "status flummoxed-chickadee",
+		golden: "output/status-with-endpoints.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: common.StatusDeployed,
+			Endpoints: []release.Endpoint{
+				{Kind: "Service", Namespace: "default", Name: "web", Addresses: []string{"203.0.113.10"}, Ports: []int32{80, 443}},
+				{Kind: "Ingress", Namespace: "default", Name: "web", Addresses: []string{"lb.example.net"}, Hosts: []string{"web.example.com"}},
+				{Kind: "Service", Namespace: "default", Name: "api", Ports: []int32{8080}},
+			},
+		}),
+	}, {
+		name:   "get the resources of a release without resources",
+		cmd:    "status flummoxed-chickadee --resources",
+		golden: "output/status-resources-none.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: common.StatusDeployed,
+		}),
+	}, {
+		name:      "get the resources of a release as JSON",
+		cmd:       "status flummoxed-chickadee --resources -o json",
+		golden:    "output/status-resources-json.txt",
+		wantError: true,
+		rels: releasesMockWithStatus(&release.Info{
+			Status: common.StatusDeployed,
+		}),
+	}, {
+		name:   "get status of a deployed release in YAML with the data of a Secret masked",
+		cmd:    "status flummoxed-chickadee -o yaml",
+		golden: "output/status-redacted.yaml",
+		rels: func() []*release.Release {
+			rels := releasesMockWithStatus(&release.Info{Status: common.StatusDeployed})
+			rels[0].Manifest = mockSecretManifest
+			return rels
+		}(),
 	}, {
 		name:   "get status of a deployed release, with desc",
 		cmd:    "status flummoxed-chickadee",
@@ -52,6 +93,22 @@ func TestStatusCmd(t *testing.T) {
 			Status:      common.StatusDeployed,
 			Description: "Mock description",
 		}),
+	}, {
+		name:   "get status of a release installed from a verified chart",
+		cmd:    "status flummoxed-chickadee",
+		golden: "output/status-verified.txt",
+		rels: func() []*release.Release {
+			rels := releasesMockWithStatus(&release.Info{Status: common.StatusDeployed})
+			rels[0].ChartSource = &common.ChartSource{
+				Ref: "repo/name",
+				Verification: &common.ChartVerification{
+					Method:  common.VerificationPGPClearSign,
+					Signers: []string{"5E615389B53CA37F0EE60BD3843BBF981FC18762"},
+					Time:    time.Unix(1452902400, 0).UTC(),
+				},
+			}
+			return rels
+		}(),
 	}, {
 		name:   "get status of a deployed release with notes",
 		cmd:    "status flummoxed-chickadee",
@@ -68,6 +125,21 @@ func TestStatusCmd(t *testing.T) {
 			Status: common.StatusDeployed,
 			Notes:  "release notes",
 		}),
+	}, {
+		name:   "get status of a deployed release with timings in json",
+		cmd:    "status flummoxed-chickadee -o json",
+		golden: "output/status-timings.json",
+		rels: releasesMockWithStatus(&release.Info{
+			Status:         common.StatusDeployed,
+			DeployDuration: 12 * time.Second,
+			Timings: release.Timings{
+				Render:   200 * time.Millisecond,
+				Validate: 300 * time.Millisecond,
+				Apply:    time.Second,
+				Hooks:    500 * time.Millisecond,
+				Wait:     10 * time.Second,
+			},
+		}),
 	}, {
 		name:   "get status of a deployed release with resources",
 		cmd:    "status flummoxed-chickadee",
@@ -218,3 +290,78 @@ func TestStatusFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "status", false)
 	checkFileCompletion(t, "status myrelease", false)
 }
+
+func TestResourceKeys(t *testing.T) {
+	manifest := `---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`
+	resources := map[string][]runtime.Object{
+		"v1/Pod(related)": nil,
+		"v1/Deployment":   nil,
+		"v1/Service":      nil,
+		"v1/ConfigMap":    nil,
+	}
+	got := resourceKeys(resources, manifest)
+	want := []string{"v1/Service", "v1/Deployment", "v1/ConfigMap", "v1/Pod(related)"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestPrintResourcesTable(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	deployments := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "meta.k8s.io/v1",
+		"kind":       "Table",
+		"columnDefinitions": []any{
+			map[string]any{"name": "Name", "type": "string"},
+			map[string]any{"name": "Ready", "type": "string"},
+			map[string]any{"name": "Age", "type": "string"},
+		},
+		"rows": []any{
+			map[string]any{
+				"cells": []any{"web", "2/3", "5m"},
+				"object": map[string]any{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata":   map[string]any{"name": "web", "namespace": "default"},
+				},
+			},
+		},
+	}}
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":              "web-1",
+			"namespace":         "default",
+			"creationTimestamp": now.Add(-2 * time.Hour).Format(time.RFC3339),
+		},
+	}}
+	resources := map[string][]runtime.Object{
+		"v1/Pod(related)": {pod},
+		"v1/Deployment":   {deployments},
+	}
+
+	var out bytes.Buffer
+	if err := printResourcesTable(&out, resources, "", now); err != nil {
+		t.Fatal(err)
+	}
+	want := `NAMESPACE   NAME                  READY   STATUS   AGE
+default     deployment.apps/web   2/3     -        5m
+default     pod/web-1             -       -        120m
+`
+	if out.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, out.String())
+	}
+}
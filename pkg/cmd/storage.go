@@ -0,0 +1,61 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+var storageHelp = `
+This command consists of multiple subcommands to maintain the records in which
+the storage driver stores the releases: list and inspect them, migrate them to
+another driver, and re-encrypt them.
+
+The Secret, ConfigMap and SQL drivers encrypt the records when one of the
+following environment variables is set:
+
+- HELM_STORAGE_ENCRYPTION_KEY: a comma-separated list of base64 encoded AES
+  keys. The first key encrypts the records, and all of them decrypt them.
+- HELM_STORAGE_ENCRYPTION_KMS_PLUGIN: the path of a KMS plugin, which encrypts
+  the random key encrypting each record. It is run as "<plugin> encrypt" or
+  "<plugin> decrypt" with the key on its standard input, and writes the result
+  on its standard output.
+- HELM_STORAGE_ENCRYPTION_COMMAND: the path of an executable encrypting the
+  records, run like a KMS plugin with the whole record.
+
+Records stored before the encryption is enabled are still read.
+`
+
+func newStorageCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "maintain the release records",
+		Long:  storageHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newStorageListCmd(cfg, out))
+	cmd.AddCommand(newStorageInspectCmd(cfg, out))
+	cmd.AddCommand(newStorageMigrateCmd(cfg, out))
+	cmd.AddCommand(newStorageRotateKeyCmd(cfg, out))
+
+	return cmd
+}
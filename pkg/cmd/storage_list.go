@@ -0,0 +1,160 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+const storageListDesc = `
+List the records in which the storage driver stores the releases of the
+namespace, with their size and encoding.
+
+The records that cannot be decoded, because they are corrupted or encrypted
+with a key that is not configured, are listed as corrupted. 'helm storage
+inspect' shows why.
+`
+
+func newStorageListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewStorageList(cfg)
+	var outfmt output.Format
+	var noHeaders bool
+
+	cmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             "list the release records",
+		Long:              storageListDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			recs, err := client.Run("")
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &storageRecordsWriter{records: recs, noHeaders: noHeaders})
+		},
+	}
+
+	cmd.Flags().BoolVar(&noHeaders, "no-headers", false, "suppress headers in the output")
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+// storageRecordElement is the JSON and YAML representation of a record.
+type storageRecordElement struct {
+	*driver.Record
+	Ratio float64 `json:"ratio,omitempty"`
+	Error string  `json:"error,omitempty"`
+}
+
+type storageRecordsWriter struct {
+	records   []*driver.Record
+	noHeaders bool
+	// details writes every field of the records, for 'helm storage inspect'.
+	details bool
+}
+
+func (w *storageRecordsWriter) WriteTable(out io.Writer) error {
+	if w.details {
+		return w.writeDetails(out)
+	}
+	table := uitable.New()
+	if !w.noHeaders {
+		table.AddRow("KEY", "NAMESPACE", "DRIVER", "SIZE", "RATIO", "ENCRYPTED", "STATE")
+	}
+	for _, r := range w.records {
+		table.AddRow(r.Key, r.Namespace, r.Driver, r.Size, formatRatio(r), r.Encrypted, recordState(r))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *storageRecordsWriter) writeDetails(out io.Writer) error {
+	for i, r := range w.records {
+		if i > 0 {
+			fmt.Fprintln(out, "---")
+		}
+		fmt.Fprintf(out, "KEY: %s\n", r.Key)
+		fmt.Fprintf(out, "NAMESPACE: %s\n", r.Namespace)
+		fmt.Fprintf(out, "DRIVER: %s\n", r.Driver)
+		fmt.Fprintf(out, "SIZE: %d bytes\n", r.Size)
+		if r.ReleaseSize > 0 {
+			fmt.Fprintf(out, "RELEASE SIZE: %d bytes\n", r.ReleaseSize)
+			fmt.Fprintf(out, "COMPRESSION RATIO: %s\n", formatRatio(r))
+		}
+		fmt.Fprintf(out, "COMPRESSED: %t\n", r.Compressed)
+		fmt.Fprintf(out, "ENCRYPTED: %t\n", r.Encrypted)
+		if len(r.Labels) > 0 {
+			fmt.Fprintln(out, "LABELS:")
+			for _, k := range slices.Sorted(maps.Keys(r.Labels)) {
+				fmt.Fprintf(out, "  %s=%s\n", k, r.Labels[k])
+			}
+		}
+		if r.Err != nil {
+			fmt.Fprintf(out, "STATE: corrupted: %s\n", r.Err)
+		} else {
+			fmt.Fprintln(out, "STATE: ok")
+		}
+	}
+	return nil
+}
+
+func (w *storageRecordsWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.elements())
+}
+
+func (w *storageRecordsWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.elements())
+}
+
+func (w *storageRecordsWriter) elements() []storageRecordElement {
+	// Initialize the array so no results returns an empty array instead of null
+	elems := make([]storageRecordElement, 0, len(w.records))
+	for _, r := range w.records {
+		e := storageRecordElement{Record: r, Ratio: r.CompressionRatio()}
+		if r.Err != nil {
+			e.Error = r.Err.Error()
+		}
+		elems = append(elems, e)
+	}
+	return elems
+}
+
+func formatRatio(r *driver.Record) string {
+	if ratio := r.CompressionRatio(); ratio > 0 {
+		return strings.TrimSuffix(fmt.Sprintf("%.2f", ratio), "0") + "x"
+	}
+	return "-"
+}
+
+func recordState(r *driver.Record) string {
+	if r.Err != nil {
+		return "corrupted"
+	}
+	return "ok"
+}
@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+const storageMigrateDesc = `
+Copy the release records of the namespace from a storage driver to another,
+for instance from Secrets to a SQL database:
+
+    $ helm storage migrate --from secrets --to sql
+
+All the revisions of the releases are copied and re-encoded by the destination
+driver, with the current encryption settings. Each copied release is read back
+from the destination driver to verify it, unless --verify=false is set.
+Releases that already exist in the destination driver are skipped, unless
+--overwrite is set.
+
+With --delete-source, the records are deleted from the source driver once they
+are copied and verified. Once the migration succeeds, set HELM_DRIVER to the
+destination driver.
+`
+
+var storageDrivers = []string{"secrets", "configmaps", "sql", "memory"}
+
+func newStorageMigrateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewMigrateStorage(cfg)
+
+	cmd := &cobra.Command{
+		Use:               "migrate --from DRIVER --to DRIVER",
+		Short:             "copy the release records to another storage driver",
+		Long:              storageMigrateDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			client.Namespace = settings.Namespace()
+			migrated, err := client.Run()
+			for _, m := range migrated {
+				rac, aerr := release.NewAccessor(m.Release)
+				if aerr != nil {
+					return aerr
+				}
+				switch {
+				case m.Skipped:
+					fmt.Fprintf(out, "Skipped release %q revision %d: it already exists in the %s driver\n", rac.Name(), rac.Version(), client.To)
+				case client.DryRun:
+					fmt.Fprintf(out, "Would migrate release %q revision %d\n", rac.Name(), rac.Version())
+				default:
+					fmt.Fprintf(out, "Migrated release %q revision %d\n", rac.Name(), rac.Version())
+				}
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.From, "from", "", "the storage driver to copy the records from: secrets, configmaps, sql or memory")
+	f.StringVar(&client.To, "to", "", "the storage driver to copy the records to: secrets, configmaps, sql or memory")
+	f.BoolVar(&client.Overwrite, "overwrite", false, "replace the releases that already exist in the destination driver")
+	f.BoolVar(&client.Verify, "verify", client.Verify, "read back every copied release from the destination driver")
+	f.BoolVar(&client.DeleteSource, "delete-source", false, "delete the records from the source driver once copied and verified")
+	f.BoolVar(&client.DryRun, "dry-run", false, "list the releases to migrate without copying them")
+	if err := cmd.MarkFlagRequired("from"); err != nil {
+		log.Fatal(err)
+	}
+	if err := cmd.MarkFlagRequired("to"); err != nil {
+		log.Fatal(err)
+	}
+	for _, name := range []string{"from", "to"} {
+		err := cmd.RegisterFlagCompletionFunc(name, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return storageDrivers, cobra.ShellCompDirectiveNoFileComp
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	return cmd
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+const storageRotateKeyDesc = `
+Re-encrypt the release records of the namespace with the current encryption
+settings.
+
+To rotate the key of HELM_STORAGE_ENCRYPTION_KEY, put the new key first and
+keep the old key after it, run this command, then remove the old key:
+
+    $ HELM_STORAGE_ENCRYPTION_KEY=$NEW_KEY,$OLD_KEY helm storage rotate-key
+
+With a KMS plugin, this command encrypts every record with a new data key.
+Unsetting the encryption variables and running this command decrypts the
+records.
+`
+
+func newStorageRotateKeyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewStorageRotateKey(cfg)
+
+	return &cobra.Command{
+		Use:               "rotate-key",
+		Short:             "re-encrypt the release records with the current key",
+		Long:              storageRotateKeyDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ls, err := client.Run()
+			if err != nil {
+				return err
+			}
+			for _, rel := range ls {
+				rac, err := release.NewAccessor(rel)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Re-encrypted release %q revision %d\n", rac.Name(), rac.Version())
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestStorageListCmd(t *testing.T) {
+	releases := []*release.Release{
+		release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 1}),
+		release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 2}),
+		release.Mock(&release.MockReleaseOptions{Name: "atlas-guide", Version: 1}),
+	}
+	// The size of the records depends on the modification time of the
+	// templates of the mock chart, so pin it.
+	for _, rel := range releases {
+		for _, f := range rel.Chart.Templates {
+			f.ModTime = time.Time{}
+		}
+	}
+	tests := []cmdTestCase{{
+		name:   "list the release records",
+		cmd:    "storage ls",
+		golden: "output/storage-list.txt",
+		rels:   releases,
+	}, {
+		name:   "list the release records without headers",
+		cmd:    "storage ls --no-headers",
+		golden: "output/storage-list-no-headers.txt",
+		rels:   releases,
+	}, {
+		name:   "list the release records in JSON",
+		cmd:    "storage ls -o json",
+		golden: "output/storage-list.json",
+		rels:   releases,
+	}, {
+		name:   "inspect the records of a release",
+		cmd:    "storage inspect thomas-guide",
+		golden: "output/storage-inspect.txt",
+		rels:   releases,
+	}, {
+		name:   "inspect the record of a revision",
+		cmd:    "storage inspect thomas-guide --revision 2",
+		golden: "output/storage-inspect-revision.txt",
+		rels:   releases,
+	}, {
+		name:      "inspect a missing release",
+		cmd:       "storage inspect nope",
+		golden:    "output/storage-inspect-missing.txt",
+		rels:      releases,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestStorageMigrateCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:      "migrate requires the drivers",
+		cmd:       "storage migrate",
+		golden:    "output/storage-migrate-no-flags.txt",
+		wantError: true,
+	}, {
+		name:      "migrate rejects an unknown driver",
+		cmd:       "storage migrate --from secrets --to floppy",
+		golden:    "output/storage-migrate-unknown-driver.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestStorageInspectCompletion(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "completion for storage inspect",
+		cmd:    "__complete storage inspect ''",
+		golden: "output/storage-inspect-comp.txt",
+		rels: []*release.Release{
+			release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 1}),
+		},
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestStorageRotateKeyCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "re-encrypt the release records",
+		cmd:    "storage rotate-key",
+		golden: "output/storage-rotate-key.txt",
+		rels: []*release.Release{
+			release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 1}),
+			release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 2}),
+		},
+	}, {
+		name:      "rotate-key takes no arguments",
+		cmd:       "storage rotate-key foo",
+		golden:    "output/storage-rotate-key-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestStorageFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "storage ls", false)
+	checkFileCompletion(t, "storage inspect", false)
+	checkFileCompletion(t, "storage inspect thomas-guide", false)
+	checkFileCompletion(t, "storage migrate", false)
+}
+
+func TestStorageRotateKeyFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "storage rotate-key", false)
+}
@@ -33,6 +33,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/internal/manifestschema"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/cli/values"
@@ -47,6 +48,12 @@ Any values that would normally be looked up or retrieved in-cluster will be
 faked locally. Additionally, none of the server-side testing of chart validity
 (e.g. whether an API is supported) is done.
 
+With '--use-cluster', the chart is still rendered client-side, but
+Capabilities and the 'lookup' function are served read-only by the cluster of
+the current kube context (see '--kube-context'). This renders accurate
+manifests with read-only credentials, such as in CI: no release is read and
+nothing is validated against or written to the cluster.
+
 To specify the Kubernetes API versions used for Capabilities.APIVersions, use
 the '--api-versions' flag. This flag can be specified multiple times or as a
 comma-separated list:
@@ -56,6 +63,28 @@ comma-separated list:
 or
 
     $ helm template --api-versions networking.k8s.io/v1,cert-manager.io/v1 mychart ./mychart
+
+Capabilities.Features describes the CRDs, Pod Security admission levels and
+default StorageClass of the cluster. To render for a cluster with given
+features, pass a YAML file to '--cluster-features':
+
+    crds:
+    - servicemonitors.monitoring.coreos.com
+    podSecurity:
+      default: restricted
+    defaultStorageClass: standard
+
+To find out which template produced a line of the output, use
+'--debug-source-map' to write a JSON source map. For each rendered file it
+lists, line by line, the template file and line the output came from.
+
+To validate the rendered manifests without a cluster, use '--validate-offline'.
+Built-in kinds are checked against the Kubernetes types Helm was built with,
+and custom resources against the CRDs of the chart. Schemas of other kinds are
+looked up in the directories given with '--schema-dir', laid out like the
+kubeconform schema catalogs (for example
+'v1.31.0-standalone-strict/deployment-apps-v1.json'). Resources without a
+known schema are reported as warnings.
 `
 
 func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -67,6 +96,10 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var kubeVersion string
 	var extraAPIs []string
 	var showFiles []string
+	var validateOffline bool
+	var schemaDirs []string
+	var clusterFeaturesFile string
+	var allowCrossNamespace bool
 
 	cmd := &cobra.Command{
 		Use:   "template [NAME] [CHART]",
@@ -77,6 +110,7 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compInstall(args, toComplete, client)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			client.RestrictNamespace = !allowCrossNamespace
 			if kubeVersion != "" {
 				parsedKubeVersion, err := common.ParseKubeVersion(kubeVersion)
 				if err != nil {
@@ -84,6 +118,13 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				}
 				client.KubeVersion = parsedKubeVersion
 			}
+			if clusterFeaturesFile != "" {
+				features, err := common.LoadClusterFeatures(clusterFeaturesFile)
+				if err != nil {
+					return err
+				}
+				cfg.ClusterFeatures = features
+			}
 
 			registryClient, err := newRegistryClient(out, client.CertFile, client.KeyFile, client.CaFile,
 				client.InsecureSkipTLSVerify, client.PlainHTTP, client.Username, client.Password)
@@ -105,7 +146,7 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			client.Replace = true // Skip the name check
 			client.APIVersions = common.VersionSet(extraAPIs)
 			client.IncludeCRDs = includeCrds
-			rel, err := runInstall(args, client, valueOpts, out)
+			rel, err := runInstall(args, client, valueOpts, out, false)
 
 			if err != nil && !settings.Debug {
 				if rel != nil {
@@ -115,6 +156,12 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 			installErr := err
 
+			if validateOffline && rel != nil && installErr == nil {
+				if err := validateManifestsOffline(cmd.ErrOrStderr(), rel, client, schemaDirs); err != nil {
+					return err
+				}
+			}
+
 			// We ignore a potential error here because, when the --debug flag was specified,
 			// we always want to print the YAML, even if it is not valid. The error is still returned afterwards.
 			if rel != nil {
@@ -208,16 +255,22 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	addInstallFlags(cmd, f, client, valueOpts)
+	addAllowCrossNamespaceFlag(f, &allowCrossNamespace)
 	f.StringArrayVarP(&showFiles, "show-only", "s", []string{}, "only show manifests rendered from the given templates")
 	f.StringVar(&client.OutputDir, "output-dir", "", "writes the executed templates to files in output-dir instead of stdout")
 	f.BoolVar(&validate, "validate", false, "deprecated")
-	f.MarkDeprecated("validate", "use '--dry-run=server' instead")
+	markDeprecated(f, "validate", "use '--dry-run=server' instead")
 	f.BoolVar(&includeCrds, "include-crds", false, "include CRDs in the templated output")
 	f.BoolVar(&skipTests, "skip-tests", false, "skip tests from templated output")
 	f.BoolVar(&client.IsUpgrade, "is-upgrade", false, "set .Release.IsUpgrade instead of .Release.IsInstall")
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion")
 	f.StringSliceVarP(&extraAPIs, "api-versions", "a", []string{}, "Kubernetes api versions used for Capabilities.APIVersions (multiple can be specified)")
+	f.BoolVar(&client.UseCluster, "use-cluster", false, "read Capabilities and serve the 'lookup' function from the cluster, while still rendering client-side")
+	f.StringVar(&clusterFeaturesFile, "cluster-features", "", "YAML file of the cluster features used for Capabilities.Features")
 	f.BoolVar(&client.UseReleaseName, "release-name", false, "use release name in the output-dir path.")
+	f.BoolVar(&validateOffline, "validate-offline", false, "validate the rendered manifests against the schemas of their kinds without connecting to a cluster")
+	f.StringArrayVar(&schemaDirs, "schema-dir", []string{}, "directory of JSON schemas used by --validate-offline for kinds that are neither built in nor defined by the chart's CRDs (can specify multiple)")
+	f.StringVar(&client.SourceMapFile, "debug-source-map", "", "write a JSON source map from rendered lines to the template files and lines that produced them to the given file")
 	f.String(
 		"dry-run",
 		"client",
@@ -229,6 +282,41 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	return cmd
 }
 
+// validateManifestsOffline validates the manifests and hooks of a rendered
+// release against the schemas of their kinds. Resources without a known schema
+// are written to errOut as warnings.
+func validateManifestsOffline(errOut io.Writer, rel *release.Release, client *action.Install, schemaDirs []string) error {
+	kubeVersion := common.DefaultCapabilities.KubeVersion
+	if client.KubeVersion != nil {
+		kubeVersion = *client.KubeVersion
+	}
+	validator := manifestschema.New(kubeVersion.String(), schemaDirs)
+	for _, crd := range rel.Chart.CRDObjects() {
+		if err := validator.AddCRDFile(crd.File.Data); err != nil {
+			return fmt.Errorf("%s: %w", crd.Filename, err)
+		}
+	}
+
+	manifests := []string{rel.Manifest}
+	for _, h := range rel.Hooks {
+		manifests = append(manifests, h.Manifest)
+	}
+	var invalid []string
+	for _, m := range manifests {
+		for _, err := range validator.ValidateManifests([]byte(m)) {
+			if errors.Is(err, manifestschema.ErrSchemaNotFound) {
+				fmt.Fprintf(errOut, "WARNING: %s\n", err)
+				continue
+			}
+			invalid = append(invalid, err.Error())
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("offline validation failed:\n%s", strings.Join(invalid, "\n"))
+	}
+	return nil
+}
+
 func isTestHook(h *release.Hook) bool {
 	return slices.Contains(h.Events, release.HookTest)
 }
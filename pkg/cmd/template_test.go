@@ -17,9 +17,16 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/engine"
 )
 
 var chartPath = "testdata/testcharts/subchart"
@@ -48,6 +55,22 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf(`template '%s' --name-template='foobar-{{ b64enc "abc" | lower }}-baz'`, chartPath),
 			golden: "output/template-name-template.txt",
 		},
+		{
+			name:   "check cluster features",
+			cmd:    "template features testdata/testcharts/chart-with-cluster-features --namespace default --cluster-features testdata/cluster-features.yaml",
+			golden: "output/template-cluster-features.txt",
+		},
+		{
+			name:   "check cluster features unknown",
+			cmd:    "template features testdata/testcharts/chart-with-cluster-features",
+			golden: "output/template-cluster-features-unknown.txt",
+		},
+		{
+			name:      "check missing required CRDs",
+			cmd:       "template features testdata/testcharts/chart-with-cluster-features --cluster-features testdata/cluster-features-empty.yaml",
+			wantError: true,
+			golden:    "output/template-cluster-features-missing-crds.txt",
+		},
 		{
 			name:      "check no args",
 			cmd:       "template",
@@ -121,6 +144,17 @@ func TestTemplateCmd(t *testing.T) {
 			// don't accidentally get the expected result.
 			repeat: 10,
 		},
+		{
+			name:      "check offline validation",
+			cmd:       fmt.Sprintf("template '%s' --validate-offline --kube-version 1.31.0 --set replicasField=replica --set color=green", "testdata/testcharts/chart-with-offline-validation"),
+			wantError: true,
+			golden:    "output/template-validate-offline-fail.txt",
+		},
+		{
+			name:   "check offline validation with schema dir",
+			cmd:    fmt.Sprintf("template '%s' --validate-offline --kube-version 1.31.0 --schema-dir testdata/schemas", "testdata/testcharts/chart-with-offline-validation"),
+			golden: "output/template-validate-offline.txt",
+		},
 		{
 			name:      "chart with template with invalid yaml",
 			cmd:       fmt.Sprintf("template '%s'", "testdata/testcharts/chart-with-template-with-invalid-yaml"),
@@ -212,3 +246,25 @@ func TestTemplateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "template myname", true)
 	checkFileCompletion(t, "template myname mychart", false)
 }
+
+func TestTemplateDebugSourceMap(t *testing.T) {
+	smFile := filepath.Join(t.TempDir(), "sourcemap.json")
+	_, out, err := executeActionCommand(fmt.Sprintf("template '%s' --debug-source-map %s", chartPath, smFile))
+	require.NoError(t, err)
+	assert.Contains(t, out, "kind: Service")
+
+	data, err := os.ReadFile(smFile)
+	require.NoError(t, err)
+	var sm engine.SourceMap
+	require.NoError(t, json.Unmarshal(data, &sm))
+
+	const service = "subchart/templates/service.yaml"
+	loc, ok := sm.Lookup(service, 4)
+	require.True(t, ok)
+	assert.Equal(t, engine.SourceLocation{Template: service, Line: 4}, loc)
+
+	// Lines removed by whitespace trimming shift the output against the template.
+	loc, ok = sm.Lookup(service, 11)
+	require.True(t, ok)
+	assert.Equal(t, engine.SourceLocation{Template: service, Line: 17}, loc)
+}
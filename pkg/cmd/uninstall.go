@@ -17,12 +17,15 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/schema"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cmd/require"
 )
@@ -58,7 +61,16 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if validationErr != nil {
 				return validationErr
 			}
+			ctx := context.Background()
 			for i := range args {
+				err := runReleaseHooks(ctx, &schema.InputMessageReleaseHookV1{
+					Event:   plugin.PreUninstall,
+					Release: schema.ReleaseHookReleaseV1{Name: args[i], Namespace: settings.Namespace()},
+					DryRun:  client.DryRun,
+				})
+				if err != nil {
+					return err
+				}
 				res, err := client.Run(args[i])
 				if err != nil {
 					return err
@@ -66,6 +78,11 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				if res != nil && res.Info != "" {
 					fmt.Fprintln(out, res.Info)
 				}
+				if res != nil && res.Release != nil {
+					if rel, err := releaserToV1Release(res.Release); err == nil {
+						runPostReleaseHooks(ctx, plugin.PostUninstall, rel, client.DryRun)
+					}
+				}
 
 				fmt.Fprintf(out, "release \"%s\" uninstalled\n", args[i])
 			}
@@ -23,13 +23,14 @@ import (
 	"io"
 	"log"
 	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/internal/gates"
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/schema"
 	"helm.sh/helm/v4/pkg/action"
 	ci "helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/loader"
@@ -55,7 +56,8 @@ To override values in a chart, use either the '--values' flag and pass in a file
 or use the '--set' flag and pass configuration from the command line, to force string
 values, use '--set-string'. You can use '--set-file' to set individual
 values from a file when the value itself is too long for the command line
-or is dynamically generated. You can also use '--set-json' to set json values
+or is dynamically generated. Passing a directory to '--set-file' sets a map of
+the files in it, keyed by file name. You can also use '--set-json' to set json values
 (scalars/objects/arrays) from the command line. Additionally, you can use '--set-json' and passing json object as a string.
 
 You can specify the '--values'/'-f' flag multiple times. The priority will be given to the
@@ -77,6 +79,23 @@ or '--set' flags. Priority is given to new values.
 
     $ helm upgrade --reuse-values --set foo=bar --set foo=newbar redis ./redis
 
+To change only the values of a release without access to the chart repository,
+use '--reuse-chart' and omit the 'CHART' argument. The chart stored in the
+deployed release is rendered again with the new values:
+
+    $ helm upgrade --reuse-chart --reuse-values --set foo=bar redis
+
+The '--values-only' flag works like '--reuse-chart', but only the resources
+whose rendered manifests changed are updated in the cluster.
+
+To review an upgrade before applying it, '--plan' prints the changes it would
+make to the resources of the release instead of upgrading. With '-o json', the
+plan can be saved and applied later with 'helm apply-plan', which applies
+exactly the planned revision if the release did not change in the meantime:
+
+    $ helm upgrade --plan -o json redis ./redis > plan.json
+    $ helm apply-plan plan.json
+
 The --dry-run flag will output all generated chart manifests, including Secrets
 which can contain sensitive values. To hide Kubernetes Secrets use the
 --hide-secret flag. Please carefully consider how and when these flags are used.
@@ -87,23 +106,44 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	valueOpts := &values.Options{}
 	var outfmt output.Format
 	var createNamespace bool
+	var valuesOnly bool
+	var retention *retentionFlags
+	var plan bool
+	var staged bool
+	var allowCrossNamespace bool
+	staging := &action.StagedRollout{}
 
 	cmd := &cobra.Command{
 		Use:   "upgrade [RELEASE] [CHART]",
 		Short: "upgrade a release",
 		Long:  upgradeDesc,
-		Args:  require.ExactArgs(2),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if client.ReuseChart || valuesOnly {
+				return require.ExactArgs(1)(cmd, args)
+			}
+			return require.ExactArgs(2)(cmd, args)
+		},
 		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) == 0 {
 				return compListReleases(toComplete, args, cfg)
 			}
-			if len(args) == 1 {
+			if len(args) == 1 && !client.ReuseChart && !valuesOnly {
 				return compListCharts(toComplete, true)
 			}
 			return noMoreArgsComp()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client.Namespace = settings.Namespace()
+			client.RestrictNamespace = !allowCrossNamespace
+			if staged {
+				if !gates.StagedUpgrade.IsEnabled() {
+					return gates.StagedUpgrade.Error()
+				}
+				staging.Progress = func(s action.StageStatus) {
+					fmt.Fprintln(cmd.ErrOrStderr(), stageMessage(s))
+				}
+				client.Staged = staging
+			}
 
 			registryClient, err := newRegistryClient(out, client.CertFile, client.KeyFile, client.CaFile,
 				client.InsecureSkipTLSVerify, client.PlainHTTP, client.Username, client.Password)
@@ -117,6 +157,31 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return err
 			}
 			client.DryRunStrategy = dryRunStrategy
+			client.Retention = retention.retention(cmd)
+
+			if plan && (client.Install || client.ReuseChart || valuesOnly) {
+				return errors.New("--plan cannot be used with --install, --reuse-chart or --values-only")
+			}
+
+			if client.ReuseChart || valuesOnly {
+				if client.Install {
+					return errors.New("--reuse-chart and --values-only cannot be used with --install")
+				}
+				valueOpts.ContentCache = settings.ContentCache
+				vals, err := valueOpts.MergeValues(getter.All(settings))
+				if err != nil {
+					return err
+				}
+				if valuesOnly {
+					upgradeValues := &action.UpgradeValues{Upgrade: client}
+					return runUpgrade(out, args[0], nil, client, outfmt, func(ctx context.Context) (ri.Releaser, error) {
+						return upgradeValues.RunWithContext(ctx, args[0], vals)
+					})
+				}
+				return runUpgrade(out, args[0], nil, client, outfmt, func(ctx context.Context) (ri.Releaser, error) {
+					return client.RunWithContext(ctx, args[0], nil, vals)
+				})
+			}
 
 			// Fixes #7002 - Support reading values from STDIN for `upgrade` command
 			// Must load values AFTER determining if we have to call install so that values loaded from stdin are not read twice
@@ -138,6 +203,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					instClient.DisableHooks = client.DisableHooks
 					instClient.SkipCRDs = client.SkipCRDs
 					instClient.Timeout = client.Timeout
+					instClient.PhaseTimeouts = client.PhaseTimeouts
 					instClient.WaitStrategy = client.WaitStrategy
 					instClient.WaitForJobs = client.WaitForJobs
 					instClient.Devel = client.Devel
@@ -148,20 +214,29 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					instClient.SubNotes = client.SubNotes
 					instClient.HideNotes = client.HideNotes
 					instClient.SkipSchemaValidation = client.SkipSchemaValidation
+					instClient.StrictValuesSchema = client.StrictValuesSchema
 					instClient.Description = client.Description
 					instClient.DependencyUpdate = client.DependencyUpdate
 					instClient.Labels = client.Labels
+					instClient.Annotations = client.Annotations
 					instClient.EnableDNS = client.EnableDNS
+					instClient.RestrictNamespace = client.RestrictNamespace
+					instClient.Needs = client.Needs
 					instClient.HideSecret = client.HideSecret
 					instClient.TakeOwnership = client.TakeOwnership
+					instClient.ShareClusterScoped = client.ShareClusterScoped
 					instClient.ForceConflicts = client.ForceConflicts
 					instClient.ServerSideApply = client.ServerSideApply != "false"
+					instClient.Retry = client.Retry
+					instClient.IdempotencyKey = client.IdempotencyKey
+					instClient.Retention = client.Retention
+					instClient.Slot = client.Slot
 
 					if isReleaseUninstalled(versions) {
 						instClient.Replace = true
 					}
 
-					rel, err := runInstall(args, instClient, valueOpts, out)
+					rel, err := runInstall(args, instClient, valueOpts, out, true)
 					if err != nil {
 						return err
 					}
@@ -171,6 +246,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 						showMetadata: false,
 						hideNotes:    instClient.HideNotes,
 						noColor:      settings.ShouldDisableColor(),
+						warnings:     warnings.List(),
 					})
 				} else if err != nil {
 					return err
@@ -188,13 +264,15 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 
 			p := getter.All(settings)
+			valueOpts.ContentCache = settings.ContentCache
 			vals, err := valueOpts.MergeValues(p)
 			if err != nil {
 				return err
 			}
 
-			// Check chart dependencies to make sure all are present in /charts
-			ch, err := loader.Load(chartPath)
+			// Check chart dependencies to make sure all are present in /charts. The
+			// large files of chart archives are only read when they are needed.
+			ch, err := loader.LoadLazy(chartPath)
 			if err != nil {
 				return err
 			}
@@ -222,7 +300,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 							return err
 						}
 						// Reload the chart with the updated Chart.lock file.
-						if ch, err = loader.Load(chartPath); err != nil {
+						if ch, err = loader.LoadLazy(chartPath); err != nil {
 							return fmt.Errorf("failed reloading chart after repo update: %w", err)
 						}
 					} else {
@@ -231,40 +309,16 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				}
 			}
 
-			if ac.Deprecated() {
-				slog.Warn("this chart is deprecated")
-			}
-
-			// Create context and prepare the handle of SIGTERM
-			ctx := context.Background()
-			ctx, cancel := context.WithCancel(ctx)
-
-			// Set up channel on which to send signal notifications.
-			// We must use a buffered channel or risk missing the signal
-			// if we're not ready to receive when the signal is sent.
-			cSignal := make(chan os.Signal, 2)
-			signal.Notify(cSignal, os.Interrupt, syscall.SIGTERM)
-			go func() {
-				<-cSignal
-				fmt.Fprintf(out, "Release %s has been cancelled.\n", args[0])
-				cancel()
-			}()
-
-			rel, err := client.RunWithContext(ctx, args[0], ch, vals)
-			if err != nil {
-				return fmt.Errorf("UPGRADE FAILED: %w", err)
-			}
-
-			if outfmt == output.Table {
-				fmt.Fprintf(out, "Release %q has been upgraded. Happy Helming!\n", args[0])
+			if plan {
+				p, err := client.Plan(context.Background(), args[0], ch, vals)
+				if err != nil {
+					return fmt.Errorf("PLAN FAILED: %w", err)
+				}
+				return outfmt.Write(out, &planWriter{plan: p})
 			}
 
-			return outfmt.Write(out, &statusPrinter{
-				release:      rel,
-				debug:        settings.Debug,
-				showMetadata: false,
-				hideNotes:    client.HideNotes,
-				noColor:      settings.ShouldDisableColor(),
+			return runUpgrade(out, args[0], ch, client, outfmt, func(ctx context.Context) (ri.Releaser, error) {
+				return client.RunWithContext(ctx, args[0], ch, vals)
 			})
 		},
 	}
@@ -276,36 +330,52 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.HideSecret, "hide-secret", false, "hide Kubernetes Secrets when also using the --dry-run flag")
 	f.BoolVar(&client.ForceReplace, "force-replace", false, "force resource updates by replacement")
 	f.BoolVar(&client.ForceReplace, "force", false, "deprecated")
-	f.MarkDeprecated("force", "use --force-replace instead")
+	markDeprecated(f, "force", "use --force-replace instead")
 	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
 	f.StringVar(&client.ServerSideApply, "server-side", "auto", "must be \"true\", \"false\" or \"auto\". Object updates run in the server instead of the client (\"auto\" defaults the value from the previous chart release's method)")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "disable pre/post upgrade hooks")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the upgrade process will not validate rendered templates against the Kubernetes OpenAPI Schema")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed when an upgrade is performed with install flag enabled. By default, CRDs are installed if not already present, when an upgrade is performed with install flag enabled")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addPhaseTimeoutFlags(f, &client.PhaseTimeouts)
 	f.BoolVar(&client.ResetValues, "reset-values", false, "when upgrading, reset the values to the ones built into the chart")
 	f.BoolVar(&client.ReuseValues, "reuse-values", false, "when upgrading, reuse the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' is specified, this is ignored")
 	f.BoolVar(&client.ResetThenReuseValues, "reset-then-reuse-values", false, "when upgrading, reset the values to the ones built into the chart, apply the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' or '--reuse-values' is specified, this is ignored")
+	f.BoolVar(&client.ReuseChart, "reuse-chart", false, "when upgrading, reuse the chart stored in the deployed release instead of a CHART argument")
+	f.BoolVar(&valuesOnly, "values-only", false, "when upgrading, reuse the chart stored in the deployed release and only update the resources changed by the new values")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.RollbackOnFailure, "rollback-on-failure", false, "if set, Helm will rollback the upgrade to previous success release upon failure. The --wait flag will be defaulted to \"watcher\" if --rollback-on-failure is set")
 	f.BoolVar(&client.RollbackOnFailure, "atomic", false, "deprecated")
-	f.MarkDeprecated("atomic", "use --rollback-on-failure instead")
-	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	markDeprecated(f, "atomic", "use --rollback-on-failure instead")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this upgrade when upgrade fails")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in upgrade output. Does not affect presence in chart metadata")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
+	f.BoolVar(&client.StrictValuesSchema, "strict-values-schema", false, "if set, values keys that are not declared in the chart's values.schema.json fail the upgrade instead of causing a warning")
 	f.StringToStringVarP(&client.Labels, "labels", "l", nil, "Labels that would be added to release metadata. Should be separated by comma. Original release labels will be merged with upgrade labels. You can unset label using null.")
+	f.StringToStringVar(&client.Labels, "release-labels", nil, "Labels that would be added to the storage object of the release, such as its Secret, and can be selected with 'helm list --selector'. Same as --labels")
+	f.StringToStringVar(&client.Annotations, "release-annotations", nil, "Annotations that would be added to the storage object of the release, such as its Secret. Should be separated by comma. Original annotations will be merged with upgrade annotations. You can unset an annotation using null.")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
+	f.StringSliceVar(&client.Needs, "needs", nil, "releases that must be deployed and healthy before the upgrade, by name, or by namespace/name for releases in other namespaces. Can be repeated or comma separated. If not set, the releases needed by the current release are kept")
+	addAllowCrossNamespaceFlag(f, &allowCrossNamespace)
+	f.StringVar(&client.Slot, "slot", "", "deploy the chart to the \"blue\" or \"green\" slot of the release. The objects labelled with "+action.SlotLabel+" set to the slot replace those of the slot, and the objects of the other slot are kept. Use 'helm promote' to switch the Services to the slot")
+	f.BoolVar(&staged, "staged", false, "apply the canary resources first, annotated with "+action.CanaryAnnotation+"=true or selected with --canary-percent, and the others once the canary resources are healthy. A failed staged upgrade is rolled back. Experimental")
+	f.IntVar(&staging.DeploymentPercent, "canary-percent", 0, "with --staged, the percentage of the Deployments that are applied with the canary resources")
+	f.DurationVar(&staging.Hold, "canary-hold", 0, "with --staged, how long the canary resources must stay healthy before the others are applied")
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, upgrade will ignore the check for helm annotations and take ownership of the existing resources")
+	f.BoolVar(&client.ShareClusterScoped, "share-cluster-scoped", false, "if set, cluster-scoped resources owned by other releases are shared with them instead of failing. A shared resource is deleted when its last owner is uninstalled")
 	addDryRunFlag(cmd)
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 	addValueOptionsFlags(f, valueOpts)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 	AddWaitFlag(cmd, &client.WaitStrategy)
+	addRetryFlags(cmd, &client.Retry)
+	retention = addRetentionFlags(cmd, &client.MaxHistory)
+	f.StringVar(&client.IdempotencyKey, "idempotency-key", "", "record this key with the new revision. If the deployed revision was created with the same key, or one is still pending, wait for it and return it instead of creating another revision")
+	f.BoolVar(&plan, "plan", false, "print the changes the upgrade would make to the resources instead of upgrading. With -o json, the plan can be saved and applied later with 'helm apply-plan'")
 	cmd.MarkFlagsMutuallyExclusive("force-replace", "force-conflicts")
 	cmd.MarkFlagsMutuallyExclusive("force", "force-conflicts")
 
@@ -318,10 +388,59 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	if err != nil {
 		log.Fatal(err)
 	}
+	registerSetFlagCompletion(cmd, func(args []string) string {
+		if len(args) < 2 {
+			return ""
+		}
+		return args[1]
+	})
 
 	return cmd
 }
 
+// runUpgrade runs the upgrade of the release, cancelling it on SIGINT or
+// SIGTERM, and prints the upgraded release. The plugin release hooks run
+// around the upgrade, with the chart to upgrade to when it is known
+// beforehand.
+func runUpgrade(out io.Writer, name string, ch ci.Charter, client *action.Upgrade, outfmt output.Format, run func(context.Context) (ri.Releaser, error)) error {
+	ctx, stop := cancelOnSignal(out, name)
+	defer stop()
+
+	dryRun := client.DryRunStrategy == action.DryRunClient || client.DryRunStrategy == action.DryRunServer
+	pre := &schema.InputMessageReleaseHookV1{
+		Event:   plugin.PreUpgrade,
+		Release: schema.ReleaseHookReleaseV1{Name: name, Namespace: client.Namespace},
+		DryRun:  dryRun,
+	}
+	if ch != nil {
+		pre.Chart = releaseHookChart(ch)
+	}
+	if err := runReleaseHooks(ctx, pre); err != nil {
+		return err
+	}
+
+	rel, err := run(ctx)
+	if err != nil {
+		return fmt.Errorf("UPGRADE FAILED: %w", err)
+	}
+	if v1rel, err := releaserToV1Release(rel); err == nil {
+		runPostReleaseHooks(ctx, plugin.PostUpgrade, v1rel, dryRun)
+	}
+
+	if outfmt == output.Table {
+		fmt.Fprintf(out, "Release %q has been upgraded. Happy Helming!\n", name)
+	}
+
+	return outfmt.Write(out, &statusPrinter{
+		release:      rel,
+		debug:        settings.Debug,
+		showMetadata: false,
+		hideNotes:    client.HideNotes,
+		noColor:      settings.ShouldDisableColor(),
+		warnings:     warnings.List(),
+	})
+}
+
 func isReleaseUninstalled(versionsi []ri.Releaser) bool {
 	versions, err := releaseListToV1List(versionsi)
 	if err != nil {
@@ -330,3 +449,14 @@ func isReleaseUninstalled(versionsi []ri.Releaser) bool {
 	}
 	return len(versions) > 0 && versions[len(versions)-1].Info.Status == common.StatusUninstalled
 }
+
+// stageMessage describes the progress of a staged upgrade.
+func stageMessage(s action.StageStatus) string {
+	if s.Err != nil {
+		return fmt.Sprintf("%s stage %s: %s", s.Stage, s.Phase, s.Err)
+	}
+	if len(s.Resources) == 0 {
+		return fmt.Sprintf("%s stage %s", s.Stage, s.Phase)
+	}
+	return fmt.Sprintf("%s stage %s: %s", s.Stage, s.Phase, strings.Join(s.Resources, ", "))
+}
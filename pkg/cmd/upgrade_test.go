@@ -140,6 +140,16 @@ func TestUpgradeCmd(t *testing.T) {
 			golden: "output/upgrade-with-wait.txt",
 			rels:   []*release.Release{relMock("crazy-bunny", 2, ch2)},
 		},
+		{
+			name:   "upgrade a release already upgraded with the same idempotency key",
+			cmd:    fmt.Sprintf("upgrade gentle-bunny --idempotency-key ci-run-1 '%s'", chartPath),
+			golden: "output/upgrade-idempotency-key.txt",
+			rels: func() []*release.Release {
+				rel := relMock("gentle-bunny", 2, ch2)
+				rel.Info.IdempotencyKey = "ci-run-1"
+				return []*release.Release{rel}
+			}(),
+		},
 		{
 			name:   "upgrade a release with wait-for-jobs",
 			cmd:    fmt.Sprintf("upgrade crazy-bunny --wait --wait-for-jobs '%s'", chartPath),
@@ -170,6 +180,13 @@ func TestUpgradeCmd(t *testing.T) {
 			golden:    "output/upgrade-with-bad-or-missing-existing-release.txt",
 			wantError: true,
 		},
+		{
+			name:      "staged upgrade without the feature gate",
+			cmd:       fmt.Sprintf("upgrade funny-bunny '%s' --staged", chartPath),
+			golden:    "output/upgrade-staged-disabled.txt",
+			wantError: true,
+			rels:      []*release.Release{relMock("funny-bunny", 2, ch)},
+		},
 		{
 			name:   "upgrade a failed release",
 			cmd:    fmt.Sprintf("upgrade funny-bunny '%s'", chartPath),
@@ -223,6 +240,65 @@ func TestUpgradeWithValue(t *testing.T) {
 	}
 }
 
+func TestUpgradeReuseChart(t *testing.T) {
+	releaseName := "funny-bunny-reuse-chart"
+	relMock, ch, chartPath := prepareMockRelease(t, releaseName)
+
+	defer resetEnv()()
+
+	store := storageFixture()
+
+	store.Create(relMock(releaseName, 3, ch))
+
+	// The chart must not be needed when reusing the stored chart.
+	if err := os.RemoveAll(chartPath); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := fmt.Sprintf("upgrade %s --reuse-chart --set favoriteDrink=coffee", releaseName)
+	if _, _, err := executeActionCommandC(store, cmd); err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+
+	updatedReli, err := store.Get(releaseName, 4)
+	if err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+	updatedRel, err := releaserToV1Release(updatedReli)
+	if err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+	if !strings.Contains(updatedRel.Manifest, "drink: coffee") {
+		t.Errorf("The value is not set correctly. manifest: %s", updatedRel.Manifest)
+	}
+
+	cmd = fmt.Sprintf("upgrade %s --values-only --set favoriteDrink=tea", releaseName)
+	if _, _, err := executeActionCommandC(store, cmd); err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+	updatedReli, err = store.Get(releaseName, 5)
+	if err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+	updatedRel, err = releaserToV1Release(updatedReli)
+	if err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+	if !strings.Contains(updatedRel.Manifest, "drink: tea") {
+		t.Errorf("The value is not set correctly. manifest: %s", updatedRel.Manifest)
+	}
+
+	for _, cmd := range []string{
+		fmt.Sprintf("upgrade %s --reuse-chart '%s'", releaseName, chartPath),
+		fmt.Sprintf("upgrade %s --reuse-chart --install", releaseName),
+		fmt.Sprintf("upgrade %s --values-only '%s'", releaseName, chartPath),
+	} {
+		if _, _, err := executeActionCommandC(store, cmd); err == nil {
+			t.Errorf("expected an error for %q", cmd)
+		}
+	}
+}
+
 func TestUpgradeWithStringValue(t *testing.T) {
 	releaseName := "funny-bunny-v3"
 	relMock, ch, chartPath := prepareMockRelease(t, releaseName)
@@ -462,34 +538,38 @@ func TestUpgradeFileCompletion(t *testing.T) {
 }
 
 func TestUpgradeInstallWithLabels(t *testing.T) {
-	releaseName := "funny-bunny-labels"
-	_, _, chartPath := prepareMockRelease(t, releaseName)
+	for _, flag := range []string{"--labels", "--release-labels"} {
+		t.Run(flag, func(t *testing.T) {
+			releaseName := "funny-bunny-labels"
+			_, _, chartPath := prepareMockRelease(t, releaseName)
 
-	defer resetEnv()()
+			defer resetEnv()()
 
-	store := storageFixture()
+			store := storageFixture()
 
-	expectedLabels := map[string]string{
-		"key1": "val1",
-		"key2": "val2",
-	}
-	cmd := fmt.Sprintf("upgrade %s --install --labels key1=val1,key2=val2 '%s'", releaseName, chartPath)
-	_, _, err := executeActionCommandC(store, cmd)
-	if err != nil {
-		t.Errorf("unexpected error, got '%v'", err)
-	}
+			expectedLabels := map[string]string{
+				"key1": "val1",
+				"key2": "val2",
+			}
+			cmd := fmt.Sprintf("upgrade %s --install %s key1=val1,key2=val2 '%s'", releaseName, flag, chartPath)
+			_, _, err := executeActionCommandC(store, cmd)
+			if err != nil {
+				t.Errorf("unexpected error, got '%v'", err)
+			}
 
-	updatedReli, err := store.Get(releaseName, 1)
-	if err != nil {
-		t.Errorf("unexpected error, got '%v'", err)
-	}
-	updatedRel, err := releaserToV1Release(updatedReli)
-	if err != nil {
-		t.Errorf("unexpected error, got '%v'", err)
-	}
+			updatedReli, err := store.Get(releaseName, 1)
+			if err != nil {
+				t.Errorf("unexpected error, got '%v'", err)
+			}
+			updatedRel, err := releaserToV1Release(updatedReli)
+			if err != nil {
+				t.Errorf("unexpected error, got '%v'", err)
+			}
 
-	if !reflect.DeepEqual(updatedRel.Labels, expectedLabels) {
-		t.Errorf("Expected {%v}, got {%v}", expectedLabels, updatedRel.Labels)
+			if !reflect.DeepEqual(updatedRel.Labels, expectedLabels) {
+				t.Errorf("Expected {%v}, got {%v}", expectedLabels, updatedRel.Labels)
+			}
+		})
 	}
 }
 
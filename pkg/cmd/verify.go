@@ -34,6 +34,20 @@ tampered with, and was packaged by a trusted provider.
 This command can be used to verify a local chart. Several other commands provide
 '--verify' flags that run the same validation. To generate a signed package, use
 the 'helm package --sign' command.
+
+With '--origin', PATH is a chart directory unpacked with
+'helm pull --untar --record-origin', and its files are compared with the
+digests recorded at pull time. The origin file is not signed: this only detects
+local changes to the chart and cannot be combined with '--keyring' or
+'--rekor-url'.
+
+With '--rekor-url', at least one trusted signature of the chart must also be
+recorded in the given Rekor transparency log, such as https://rekor.sigstore.dev.
+The signature is looked up by the digest of the signed content, and its log
+entry must be signed by the log: '--rekor-key' sets the PEM encoded public key
+of the log, which defaults to the key of https://rekor.sigstore.dev. The
+inclusion proof of the entry is also checked. The log index of every recorded
+signature is included in the output.
 `
 
 func newVerifyCmd(out io.Writer) *cobra.Command {
@@ -52,7 +66,10 @@ func newVerifyCmd(out io.Writer) *cobra.Command {
 			// No more completions, so disable file completion
 			return noMoreArgsComp()
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if client.Origin && !cmd.Flags().Changed("keyring") {
+				client.Keyring = ""
+			}
 			result, err := client.Run(args[0])
 			if err != nil {
 				return err
@@ -65,6 +82,9 @@ func newVerifyCmd(out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	cmd.Flags().StringVar(&client.RekorURL, "rekor-url", "", "verify that the signature is recorded in the Rekor transparency log at this URL")
+	cmd.Flags().StringVar(&client.RekorKey, "rekor-key", "", "path to the PEM encoded public key of the Rekor transparency log. Defaults to the key of the public Rekor log")
+	cmd.Flags().BoolVar(&client.Origin, "origin", false, "compare the files of an unpacked chart directory with its unsigned origin file instead of verifying a signature")
 
 	return cmd
 }
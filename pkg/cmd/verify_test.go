@@ -17,7 +17,10 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -94,3 +97,18 @@ func TestVerifyFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "verify", true)
 	checkFileCompletion(t, "verify mypath", false)
 }
+
+func TestVerifyCmdRekor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "[]")
+	}))
+	defer srv.Close()
+
+	_, _, err := executeActionCommand("verify testdata/testcharts/signtest-0.1.0.tgz --keyring testdata/helm-test-key.pub --rekor-url " + srv.URL)
+	if err == nil {
+		t.Fatal("expected an error for a signature that is not in the transparency log")
+	}
+	if !strings.Contains(err.Error(), "no trusted signature of signtest-0.1.0.tgz is recorded in the transparency log") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -19,11 +19,14 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"text/template"
 
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/internal/version"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
 )
 
@@ -40,6 +43,12 @@ version.BuildInfo{Version:"v3.2.1", GitCommit:"fe51cd1e31e6a202cba7dead9552a6d41
 - GitTreeState is "clean" if there are no local code changes when this binary was
   built, and "dirty" if the binary was built from locally modified code.
 - GoVersion is the version of Go that was used to compile Helm.
+- KubeClientVersion is the version of Kubernetes that Helm's client libraries
+  were built for.
+
+With '--output json' or '--output yaml', the version also lists the chart
+apiVersions and the features that Helm supports, for tools to gate features
+on. Set '--server' to look up the version of the Kubernetes cluster as well.
 
 When using the --template flag the following properties are available to use in
 the template:
@@ -48,6 +57,9 @@ the template:
 - .GitCommit is the git commit
 - .GitTreeState is the state of the git tree when Helm was built
 - .GoVersion contains the version of Go that Helm was compiled with
+- .SemVer is the version of Helm as a full semantic version
+- .ChartAPIVersions are the apiVersions of the charts Helm supports
+- .Features are the features of Helm that tools may gate on
 
 For example, --template='Version: {{.Version}}' outputs 'Version: v3.2.1'.
 `
@@ -55,9 +67,11 @@ For example, --template='Version: {{.Version}}' outputs 'Version: v3.2.1'.
 type versionOptions struct {
 	short    bool
 	template string
+	server   bool
+	outfmt   output.Format
 }
 
-func newVersionCmd(out io.Writer) *cobra.Command {
+func newVersionCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	o := &versionOptions{}
 
 	cmd := &cobra.Command{
@@ -67,23 +81,34 @@ func newVersionCmd(out io.Writer) *cobra.Command {
 		Args:              require.NoArgs,
 		ValidArgsFunction: noMoreArgsCompFunc,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return o.run(out)
+			return o.run(cfg, out)
 		},
 	}
 	f := cmd.Flags()
 	f.BoolVar(&o.short, "short", false, "print the version number")
 	f.StringVar(&o.template, "template", "", "template for version string format")
+	f.BoolVar(&o.server, "server", false, "with --output json or yaml, also look up the version of the Kubernetes cluster")
+	bindOutputFlag(cmd, &o.outfmt)
 
 	return cmd
 }
 
-func (o *versionOptions) run(out io.Writer) error {
+func (o *versionOptions) run(cfg *action.Configuration, out io.Writer) error {
+	if o.outfmt != output.Table {
+		client := action.NewVersion(cfg)
+		client.Server = o.server
+		info, err := client.Run()
+		if err != nil {
+			slog.Warn("unable to get the version of the Kubernetes cluster", slog.Any("error", err))
+		}
+		return o.outfmt.Write(out, &versionWriter{info})
+	}
 	if o.template != "" {
 		tt, err := template.New("_").Parse(o.template)
 		if err != nil {
 			return err
 		}
-		return tt.Execute(out, version.Get())
+		return tt.Execute(out, version.GetInfo())
 	}
 	fmt.Fprintln(out, formatVersion(o.short))
 	return nil
@@ -99,3 +124,20 @@ func formatVersion(short bool) string {
 	}
 	return fmt.Sprintf("%#v", v)
 }
+
+type versionWriter struct {
+	info *action.VersionInfo
+}
+
+func (w *versionWriter) WriteTable(out io.Writer) error {
+	_, err := fmt.Fprintf(out, "%#v\n", w.info.BuildInfo)
+	return err
+}
+
+func (w *versionWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.info)
+}
+
+func (w *versionWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.info)
+}
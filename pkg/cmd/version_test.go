@@ -32,6 +32,18 @@ func TestVersion(t *testing.T) {
 		name:   "template",
 		cmd:    "version --template='Version: {{.Version}}'",
 		golden: "output/version-template.txt",
+	}, {
+		name:   "json",
+		cmd:    "version -o json",
+		golden: "output/version-json.txt",
+	}, {
+		name:   "json with the cluster",
+		cmd:    "version -o json --server",
+		golden: "output/version-json-server.txt",
+	}, {
+		name:   "yaml",
+		cmd:    "version -o yaml",
+		golden: "output/version-yaml.txt",
 	}}
 	runTestCmd(t, tests)
 }
@@ -24,6 +24,9 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"helm.sh/helm/v4/internal/fileutil"
 )
@@ -44,6 +47,9 @@ var CacheChart = ".chart"
 // CacheProv specifies the content is a provenance file
 var CacheProv = ".prov"
 
+// CacheValues specifies the content is a values file
+var CacheValues = ".values"
+
 // TODO: The cache assumes files because much of Helm assumes files. Convert
 // Helm to pass content around instead of file locations.
 
@@ -70,6 +76,12 @@ func (c *DiskCache) Get(key [sha256.Size]byte, cacheType string) (string, error)
 	if fi.Size() == 0 {
 		return p, os.ErrNotExist
 	}
+	// Record the access so that pruning removes the least recently used
+	// content first. Failing to do so is not fatal.
+	now := time.Now()
+	if err := os.Chtimes(p, now, now); err != nil {
+		slog.Debug("unable to update cache access time", "path", p, slog.Any("error", err))
+	}
 	return p, nil
 }
 
@@ -90,3 +102,104 @@ func (c *DiskCache) Put(key [sha256.Size]byte, data io.Reader, cacheType string)
 func (c *DiskCache) fileName(id [sha256.Size]byte, cacheType string) string {
 	return filepath.Join(c.Root, fmt.Sprintf("%02x", id[0]), hex.EncodeToString(id[:])+cacheType)
 }
+
+// CacheEntry describes a file stored in a DiskCache.
+type CacheEntry struct {
+	// Digest is the hex encoded sha256 key of the entry.
+	Digest string `json:"digest"`
+	// Type is the kind of content, CacheChart, CacheProv or CacheValues.
+	Type string `json:"type"`
+	// Path is the location of the file on disk.
+	Path string `json:"path"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// LastUsed is the last time the entry was stored or read.
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// List returns the entries in the cache, ordered from the least to the most
+// recently used. Files that were not written by the cache are ignored.
+func (c *DiskCache) List() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(c.Root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && p == c.Root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		digest, cacheType, ok := parseCacheFileName(d.Name())
+		if !ok || filepath.Base(filepath.Dir(p)) != digest[:2] {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, CacheEntry{
+			Digest:   digest,
+			Type:     cacheType,
+			Path:     p,
+			Size:     fi.Size(),
+			LastUsed: fi.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(entries, func(a, b CacheEntry) int {
+		return a.LastUsed.Compare(b.LastUsed)
+	})
+	return entries, nil
+}
+
+// Prune removes entries that have not been used since olderThan and then, if
+// maxSize is greater than zero, removes the least recently used entries until
+// the cache is no larger than maxSize bytes. A zero olderThan disables the age
+// check. It returns the removed entries.
+func (c *DiskCache) Prune(olderThan time.Duration, maxSize int64) ([]CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+
+	var removed []CacheEntry
+	cutoff := time.Now().Add(-olderThan)
+	for _, e := range entries {
+		expired := olderThan > 0 && e.LastUsed.Before(cutoff)
+		oversized := maxSize > 0 && total > maxSize
+		if !expired && !oversized {
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("unable to remove cached file %s: %w", e.Path, err)
+		}
+		total -= e.Size
+		removed = append(removed, e)
+	}
+	return removed, nil
+}
+
+// parseCacheFileName splits a cache file name into the digest and cache type.
+func parseCacheFileName(name string) (string, string, bool) {
+	for _, t := range []string{CacheChart, CacheProv, CacheValues} {
+		digest, ok := strings.CutSuffix(name, t)
+		if !ok || len(digest) != hex.EncodedLen(sha256.Size) {
+			continue
+		}
+		if _, err := hex.DecodeString(digest); err != nil {
+			return "", "", false
+		}
+		return digest, t, true
+	}
+	return "", "", false
+}
@@ -19,9 +19,12 @@ package downloader
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -120,3 +123,59 @@ func TestDiskCache_fileName(t *testing.T) {
 	assert.Equal(t, filepath.Join("/tmp/cache", "13", "1307990e6ba5ca145eb35e99182a9bec46531bc54ddf656a602c780fa0240dee.chart"), cache.fileName(key, CacheChart))
 	assert.Equal(t, filepath.Join("/tmp/cache", "13", "1307990e6ba5ca145eb35e99182a9bec46531bc54ddf656a602c780fa0240dee.prov"), cache.fileName(key, CacheProv))
 }
+
+func TestDiskCache_ListAndPrune(t *testing.T) {
+	cache := &DiskCache{Root: t.TempDir()}
+
+	put := func(content string, cacheType string, age time.Duration) CacheEntry {
+		key := sha256.Sum256([]byte(content))
+		p, err := cache.Put(key, strings.NewReader(content), cacheType)
+		require.NoError(t, err)
+		mtime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(p, mtime, mtime))
+		return CacheEntry{Digest: hex.EncodeToString(key[:]), Type: cacheType, Path: p, Size: int64(len(content))}
+	}
+
+	old := put("old chart", CacheChart, 48*time.Hour)
+	oldProv := put("old provenance", CacheProv, 47*time.Hour)
+	recent := put("recent chart content", CacheChart, time.Hour)
+
+	// Files not written by the cache are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(cache.Root, "README"), []byte("x"), 0644))
+
+	entries, err := cache.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{old.Digest, oldProv.Digest, recent.Digest},
+		[]string{entries[0].Digest, entries[1].Digest, entries[2].Digest}, "entries should be ordered by last use")
+	assert.Equal(t, CacheProv, entries[1].Type)
+	assert.Equal(t, recent.Size, entries[2].Size)
+
+	// Reading an entry marks it as recently used
+	key := sha256.Sum256([]byte("old chart"))
+	_, err = cache.Get(key, CacheChart)
+	require.NoError(t, err)
+
+	removed, err := cache.Prune(24*time.Hour, 0)
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	assert.Equal(t, oldProv.Digest, removed[0].Digest)
+
+	// Keep only what fits in the size limit, dropping the least recently used
+	removed, err = cache.Prune(0, recent.Size)
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	assert.Equal(t, recent.Digest, removed[0].Digest)
+
+	entries, err = cache.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, old.Digest, entries[0].Digest)
+}
+
+func TestDiskCache_ListMissingRoot(t *testing.T) {
+	cache := &DiskCache{Root: filepath.Join(t.TempDir(), "missing")}
+	entries, err := cache.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -26,9 +26,13 @@ import (
 	"log/slog"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
+
 	"helm.sh/helm/v4/internal/fileutil"
 	ifs "helm.sh/helm/v4/internal/third_party/dep/fs"
 	"helm.sh/helm/v4/internal/urlutil"
@@ -70,6 +74,9 @@ type ChartDownloader struct {
 	Verify VerificationStrategy
 	// Keyring is the keyring file used for verification.
 	Keyring string
+	// TrustPolicy is the trust policy file restricting the keys trusted to
+	// sign the charts of each repository. It is ignored when empty.
+	TrustPolicy string
 	// Getter collection for the operation
 	Getters getter.Providers
 	// Options provide parameters to be passed along to the Getter being initialized.
@@ -85,6 +92,15 @@ type ChartDownloader struct {
 
 	// Cache specifies the cache implementation to use.
 	Cache Cache
+
+	// Channel is the release channel the latest version is resolved in,
+	// such as stable or beta. See repo.Channels. It is ignored when empty.
+	Channel string
+
+	// ResolvedURL is set by DownloadTo and DownloadToCache to the location
+	// the chart was downloaded from: the chart URL found in the repository
+	// index, or the OCI reference with its resolved tag.
+	ResolvedURL string
 }
 
 // DownloadTo retrieves a chart. Depending on the settings, it may also download a provenance file.
@@ -110,15 +126,15 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 	if err != nil {
 		return "", nil, err
 	}
+	c.ResolvedURL = u.String()
 
 	g, err := c.Getters.ByScheme(u.Scheme)
 	if err != nil {
 		return "", nil, err
 	}
 
-	// Check the cache for the content. Otherwise download it.
-	// Note, this process will pull from the cache but does not automatically populate
-	// the cache with the file it downloads.
+	// Check the cache for the content. Otherwise download it and, when the
+	// digest is known, store it in the cache for later use.
 	var data *bytes.Buffer
 	var found bool
 	var digest []byte
@@ -148,10 +164,18 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 	if !found {
 		c.Options = append(c.Options, getter.WithAcceptHeader("application/gzip,application/octet-stream"))
 
-		data, err = g.Get(u.String(), c.Options...)
+		data, err = g.Get(u.String(), c.chartOptions(u, hash)...)
 		if err != nil {
 			return "", nil, err
 		}
+
+		if hash != "" {
+			if _, err := c.Cache.Put(digest32, bytes.NewReader(data.Bytes()), CacheChart); err != nil {
+				slog.Warn("unable to store chart in cache", "id", hash, slog.Any("error", err))
+			} else {
+				slog.Debug("put downloaded chart in cache", "id", hash)
+			}
+		}
 	}
 
 	name := filepath.Base(u.Path)
@@ -208,6 +232,9 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 				// failed.
 				return destfile, ver, err
 			}
+			if err := c.checkTrust(ref, ver); err != nil {
+				return destfile, ver, err
+			}
 		}
 	}
 	return destfile, ver, nil
@@ -227,6 +254,7 @@ func (c *ChartDownloader) DownloadToCache(ref, version string) (string, *provena
 	if err != nil {
 		return "", nil, err
 	}
+	c.ResolvedURL = u.String()
 
 	g, err := c.Getters.ByScheme(u.Scheme)
 	if err != nil {
@@ -262,7 +290,7 @@ func (c *ChartDownloader) DownloadToCache(ref, version string) (string, *provena
 		}
 
 		// Get file not in the cache
-		data, gerr := g.Get(u.String(), c.Options...)
+		data, gerr := g.Get(u.String(), c.chartOptions(u, digestString)...)
 		if gerr != nil {
 			return "", nil, gerr
 		}
@@ -338,6 +366,9 @@ func (c *ChartDownloader) DownloadToCache(ref, version string) (string, *provena
 				// failed.
 				return pth, ver, err
 			}
+			if err := c.checkTrust(ref, ver); err != nil {
+				return pth, ver, err
+			}
 		}
 	}
 	return pth, ver, nil
@@ -373,6 +404,11 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (string, *url
 			return "", nil, fmt.Errorf("unable to lookup ref %s at version '%s', missing registry client", ref, version)
 		}
 
+		if c.Channel != "" {
+			if version, err = c.resolveOCIChannel(ref, version); err != nil {
+				return "", nil, err
+			}
+		}
 		digest, OCIref, err := c.RegistryClient.ValidateReference(ref, version, u)
 		return digest, OCIref, err
 	}
@@ -410,10 +446,16 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (string, *url
 		if rc.CertFile != "" || rc.KeyFile != "" || rc.CAFile != "" {
 			c.Options = append(c.Options, getter.WithTLSClientConfig(rc.CertFile, rc.KeyFile, rc.CAFile))
 		}
-		if rc.Username != "" && rc.Password != "" {
+		if rc.InsecureSkipTLSVerify {
+			c.Options = append(c.Options, getter.WithInsecureSkipVerifyTLS(true))
+		}
+		if rc.Proxy != "" {
+			c.Options = append(c.Options, getter.WithProxy(rc.Proxy))
+		}
+		if username, password := rc.BasicAuth(); username != "" && password != "" {
 			c.Options = append(
 				c.Options,
-				getter.WithBasicAuth(rc.Username, rc.Password),
+				getter.WithBasicAuth(username, password),
 				getter.WithPassCredentialsAll(rc.PassCredentialsAll),
 			)
 		}
@@ -446,9 +488,15 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (string, *url
 		if r.Config.CertFile != "" || r.Config.KeyFile != "" || r.Config.CAFile != "" {
 			c.Options = append(c.Options, getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile))
 		}
-		if r.Config.Username != "" && r.Config.Password != "" {
+		if r.Config.InsecureSkipTLSVerify {
+			c.Options = append(c.Options, getter.WithInsecureSkipVerifyTLS(true))
+		}
+		if r.Config.Proxy != "" {
+			c.Options = append(c.Options, getter.WithProxy(r.Config.Proxy))
+		}
+		if username, password := r.Config.BasicAuth(); username != "" && password != "" {
 			c.Options = append(c.Options,
-				getter.WithBasicAuth(r.Config.Username, r.Config.Password),
+				getter.WithBasicAuth(username, password),
 				getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
 			)
 		}
@@ -461,7 +509,12 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (string, *url
 		return "", u, fmt.Errorf("no cached repo found. (try 'helm repo update'): %w", err)
 	}
 
-	cv, err := i.Get(chartName, version)
+	var cv *repo.ChartVersion
+	if c.Channel != "" {
+		cv, err = i.GetInChannel(chartName, version, c.Channel)
+	} else {
+		cv, err = i.Get(chartName, version)
+	}
 	if err != nil {
 		return "", u, fmt.Errorf("chart %q matching %s not found in %s index. (try 'helm repo update'): %w", chartName, version, r.Config.Name, err)
 	}
@@ -480,6 +533,38 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (string, *url
 	return cv.Digest, loc, err
 }
 
+// resolveOCIChannel returns the latest tag of the OCI repository ref in the
+// channel of the downloader that satisfies the version constraint. The
+// channels are read from the annotations of the manifests, from the latest
+// tag down.
+func (c *ChartDownloader) resolveOCIChannel(ref, version string) (string, error) {
+	name := strings.TrimPrefix(ref, registry.OCIScheme+"://")
+	if strings.ContainsAny(path.Base(name), ":@") {
+		return "", fmt.Errorf("a release channel cannot be used with the tagged reference %s", ref)
+	}
+	if version == "" {
+		version = ">0.0.0-0"
+	}
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return "", err
+	}
+	tags, err := c.RegistryClient.ListTags(name, registry.TagsOptConstraint(constraint))
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		res, err := c.RegistryClient.Inspect(name + ":" + strings.ReplaceAll(tag, "+", "_"))
+		if err != nil {
+			return "", err
+		}
+		if repo.InChannel(tag, res.Annotations, c.Channel) {
+			return tag, nil
+		}
+	}
+	return "", fmt.Errorf("%w for %s-%s in channel %q", repo.ErrNoChartVersion, ref, version, c.Channel)
+}
+
 // VerifyChart takes a path to a chart archive and a keyring, and verifies the chart.
 //
 // It assumes that a chart archive file is accompanied by a provenance file whose
@@ -517,6 +602,19 @@ func VerifyChart(path, provfile, keyring string) (*provenance.Verification, erro
 	return sig.Verify(archiveData, provData, filepath.Base(path))
 }
 
+// checkTrust verifies that a verified chart is signed by a key the trust policy
+// trusts for its repository.
+func (c *ChartDownloader) checkTrust(ref string, ver *provenance.Verification) error {
+	if c.TrustPolicy == "" || ver.Report == nil {
+		return nil
+	}
+	policy, err := provenance.LoadTrustPolicy(c.TrustPolicy)
+	if err != nil {
+		return err
+	}
+	return policy.Check(ref, ver.Report)
+}
+
 // isTar tests whether the given file is a tar file.
 //
 // Currently, this simply checks extension, since a subsequent function will
@@ -592,6 +690,17 @@ func loadRepoConfig(file string) (*repo.File, error) {
 	return r, nil
 }
 
+// chartOptions returns the getter options used to fetch a chart archive. When
+// the repository index provides a digest for a chart served over HTTP the
+// downloaded content is verified against it. OCI digests refer to manifests
+// rather than archives and are not used.
+func (c *ChartDownloader) chartOptions(u *url.URL, digest string) []getter.Option {
+	if digest == "" || u.Scheme == registry.OCIScheme {
+		return c.Options
+	}
+	return append(slices.Clip(c.Options), getter.WithDigest(digest))
+}
+
 // stripDigestAlgorithm removes the algorithm prefix (e.g., "sha256:") from a digest string.
 // If no prefix is present, the original string is returned unchanged.
 func stripDigestAlgorithm(digest string) string {
@@ -29,6 +29,7 @@ import (
 	"helm.sh/helm/v4/internal/test/ensure"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/provenance"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/repo/v1"
 	"helm.sh/helm/v4/pkg/repo/v1/repotest"
@@ -301,6 +302,19 @@ func TestDownloadTo_TLS(t *testing.T) {
 	if _, err := os.Stat(target); err != nil {
 		t.Error(err)
 	}
+
+	// A trust policy for the repository restricts the keys it accepts.
+	policy := &provenance.TrustPolicy{}
+	policy.Trust("test", "0000000000000000000000000000000000000000")
+	c.TrustPolicy = filepath.Join(t.TempDir(), "trust.yaml")
+	require.NoError(t, policy.WriteFile(c.TrustPolicy, 0644))
+	_, _, err = c.DownloadTo(cname, "", dest)
+	require.ErrorContains(t, err, "not signed by a key trusted for its repository")
+
+	policy.Trust("test", "5E615389B53CA37F0EE60BD3843BBF981FC18762")
+	require.NoError(t, policy.WriteFile(c.TrustPolicy, 0644))
+	_, _, err = c.DownloadTo(cname, "", dest)
+	require.NoError(t, err)
 }
 
 func TestDownloadTo_VerifyLater(t *testing.T) {
@@ -0,0 +1,175 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v4/internal/resolver"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// SourceDigestAnnotation is the Chart.yaml annotation recording the digest of
+// the directory a local (file://) dependency was packaged from.
+const SourceDigestAnnotation = "helm.sh/source-digest"
+
+// chartDigest computes a digest over the files of a chart loaded from a
+// directory. Files excluded by .helmignore do not contribute to it.
+func chartDigest(ch *chart.Chart) string {
+	files := make([]string, 0, len(ch.Raw))
+	data := make(map[string][]byte, len(ch.Raw))
+	for _, f := range ch.Raw {
+		files = append(files, f.Name)
+		data[f.Name] = f.Data
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, name := range files {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(data[name])))
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write(size[:])
+		h.Write(data[name])
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// vendoredDigest returns the source digest recorded on the copy of the named
+// chart currently vendored in dest, or an empty string if there is none.
+func vendoredDigest(dest, name string) string {
+	entries, err := os.ReadDir(dest)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		var md *chart.Metadata
+		p := filepath.Join(dest, e.Name())
+		switch {
+		case e.IsDir():
+			md, err = chartutil.LoadChartfile(filepath.Join(p, chartutil.ChartfileName))
+		case strings.HasSuffix(e.Name(), ".tgz"):
+			var ch *chart.Chart
+			if ch, err = loader.LoadFile(p); err == nil {
+				md = ch.Metadata
+			}
+		default:
+			continue
+		}
+		if err != nil || md.Name != name {
+			continue
+		}
+		return md.Annotations[SourceDigestAnnotation]
+	}
+	return ""
+}
+
+// localDigests returns the source digest of every local (file://) dependency
+// of the chart, keyed by dependency name.
+func (m *Manager) localDigests() (map[string]string, error) {
+	c, err := m.loadChartDir()
+	if err != nil {
+		return nil, err
+	}
+	digests := map[string]string{}
+	for _, dep := range c.Metadata.Dependencies {
+		if !strings.HasPrefix(dep.Repository, "file://") {
+			continue
+		}
+		p, err := resolver.GetLocalPath(dep.Repository, m.ChartPath)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := loader.LoadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		digests[dep.Name] = chartDigest(ch)
+	}
+	return digests, nil
+}
+
+// Watch runs Build and then rebuilds the dependencies every time one of the
+// chart's local (file://) dependencies changes. The sources are checked every
+// interval until ctx is done.
+//
+// Errors from rebuilding are reported to Out rather than returned, so a
+// temporarily broken dependency does not end the watch. A failed rebuild is
+// retried on the next change.
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) error {
+	if err := m.Build(); err != nil {
+		return err
+	}
+	last, err := m.localDigests()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(m.Out, "Watching %d local dependencies for changes\n", len(last))
+
+	// Repositories were refreshed by the first build; only local sources
+	// change between rebuilds.
+	skipUpdate := m.SkipUpdate
+	m.SkipUpdate = true
+	defer func() { m.SkipUpdate = skipUpdate }()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := m.localDigests()
+		if err != nil {
+			fmt.Fprintf(m.Out, "Unable to check local dependencies: %s\n", err)
+			continue
+		}
+		var changed []string
+		for name, digest := range current {
+			if last[name] != digest {
+				changed = append(changed, name)
+			}
+		}
+		if len(changed) == 0 && len(current) == len(last) {
+			continue
+		}
+		sort.Strings(changed)
+		what := strings.Join(changed, ", ")
+		if what == "" {
+			what = "local dependencies"
+		}
+		fmt.Fprintf(m.Out, "Detected changes in %s, rebuilding\n", what)
+		last = current
+		if err := m.Build(); err != nil {
+			fmt.Fprintf(m.Out, "Rebuild failed: %s\n", err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// syncBuffer is a bytes.Buffer that is safe to read while Watch writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// localDependencyFixture creates a chart "parent" with a file:// dependency on
+// a sibling chart "dep" and returns the manager and the dependency directory.
+func localDependencyFixture(t *testing.T) (*Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	dep := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "dep", Version: "0.1.0", APIVersion: chart.APIVersionV2},
+	}
+	require.NoError(t, chartutil.SaveDir(dep, dir))
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "parent",
+			Version:    "0.1.0",
+			APIVersion: chart.APIVersionV2,
+			Dependencies: []*chart.Dependency{{
+				Name:       "dep",
+				Version:    ">=0.1.0",
+				Repository: "file://../dep",
+			}},
+		},
+	}
+	require.NoError(t, chartutil.SaveDir(parent, dir))
+
+	m := &Manager{
+		Out:              new(bytes.Buffer),
+		ChartPath:        filepath.Join(dir, "parent"),
+		SkipUpdate:       true,
+		RepositoryConfig: repoConfig,
+		RepositoryCache:  repoCache,
+	}
+	return m, filepath.Join(dir, "dep")
+}
+
+func TestLocalDependencyDigest(t *testing.T) {
+	m, depDir := localDependencyFixture(t)
+	out := new(bytes.Buffer)
+	m.Out = out
+
+	require.NoError(t, m.Update())
+	charts := filepath.Join(m.ChartPath, "charts")
+	first := vendoredDigest(charts, "dep")
+	assert.True(t, strings.HasPrefix(first, "sha256:"), "expected a recorded source digest, got %q", first)
+	assert.NotContains(t, out.String(), "has changed")
+
+	// Rebuilding an unchanged source keeps the digest.
+	require.NoError(t, m.Build())
+	assert.Equal(t, first, vendoredDigest(charts, "dep"))
+	assert.NotContains(t, out.String(), "has changed")
+
+	require.NoError(t, os.WriteFile(filepath.Join(depDir, "values.yaml"), []byte("replicas: 2\n"), 0644))
+	require.NoError(t, m.Build())
+	assert.NotEqual(t, first, vendoredDigest(charts, "dep"))
+	assert.Contains(t, out.String(), "Local dependency dep has changed, re-vendoring")
+}
+
+func TestWatch(t *testing.T) {
+	m, depDir := localDependencyFixture(t)
+	out := new(syncBuffer)
+	m.Out = out
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- m.Watch(ctx, 10*time.Millisecond) }()
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "Watching 1 local dependencies")
+	}, 5*time.Second, 10*time.Millisecond)
+	first := vendoredDigest(filepath.Join(m.ChartPath, "charts"), "dep")
+
+	require.NoError(t, os.WriteFile(filepath.Join(depDir, "values.yaml"), []byte("replicas: 2\n"), 0644))
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "Detected changes in dep, rebuilding")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.NotEqual(t, first, vendoredDigest(filepath.Join(m.ChartPath, "charts"), "dep"))
+	assert.True(t, m.SkipUpdate, "expected SkipUpdate to be restored")
+}
@@ -27,6 +27,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
@@ -68,6 +69,9 @@ type Manager struct {
 	Debug bool
 	// Keyring is the key ring file.
 	Keyring string
+	// TrustPolicy is the trust policy file restricting the keys trusted to
+	// sign the charts of each repository.
+	TrustPolicy string
 	// SkipUpdate indicates that the repository should not be updated first.
 	SkipUpdate bool
 	// Getter collection for the operation
@@ -78,6 +82,8 @@ type Manager struct {
 
 	// ContentCache is a location where a cache of charts can be stored
 	ContentCache string
+	// Untar vendors dependencies as expanded chart directories instead of archives.
+	Untar bool
 }
 
 // Build rebuilds a local charts directory from a lockfile.
@@ -238,6 +244,73 @@ func (m *Manager) resolve(req []*chart.Dependency, repoNames map[string]string)
 	return res.Resolve(req, repoNames)
 }
 
+// AvailableVersions returns the versions of each dependency available in its
+// repository, from the newest to the oldest, in the order of deps. Local
+// dependencies have no versions. The repository indexes are updated first,
+// unless SkipUpdate is set.
+//
+// Aliased repositories of deps are replaced with their URLs.
+func (m *Manager) AvailableVersions(deps []*chart.Dependency) ([][]*semver.Version, error) {
+	var remote []*chart.Dependency
+	for _, dep := range deps {
+		if dep.Repository != "" && !strings.HasPrefix(dep.Repository, "file://") {
+			remote = append(remote, dep)
+		}
+	}
+	versions := make([][]*semver.Version, len(deps))
+	if len(remote) == 0 {
+		return versions, nil
+	}
+
+	repoNames, err := m.resolveRepoNames(remote)
+	if err != nil {
+		return nil, err
+	}
+	repoNames, err = m.ensureMissingRepos(repoNames, remote)
+	if err != nil {
+		return nil, err
+	}
+	if !m.SkipUpdate {
+		if err := m.UpdateRepositories(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, dep := range deps {
+		if dep.Repository == "" || strings.HasPrefix(dep.Repository, "file://") {
+			continue
+		}
+		var tags []string
+		if registry.IsOCI(dep.Repository) {
+			if m.RegistryClient == nil {
+				return nil, fmt.Errorf("no registry client to list the versions of %s", dep.Name)
+			}
+			ref := fmt.Sprintf("%s/%s", strings.TrimPrefix(dep.Repository, registry.OCIScheme+"://"), dep.Name)
+			tags, err = m.RegistryClient.Tags(ref)
+			if err != nil {
+				return nil, fmt.Errorf("could not retrieve list of tags for repository %s: %w", dep.Repository, err)
+			}
+		} else {
+			index, err := repo.LoadIndexFile(filepath.Join(m.RepositoryCache, helmpath.CacheIndexFile(repoNames[dep.Name])))
+			if err != nil {
+				return nil, fmt.Errorf("no cached repository for %s found. (try 'helm repo update'): %w", dep.Repository, err)
+			}
+			for _, cv := range index.Entries[dep.Name] {
+				if len(cv.URLs) > 0 {
+					tags = append(tags, cv.Version)
+				}
+			}
+		}
+		for _, t := range tags {
+			if v, err := semver.NewVersion(t); err == nil {
+				versions[i] = append(versions[i], v)
+			}
+		}
+		sort.Sort(sort.Reverse(semver.Collection(versions[i])))
+	}
+	return versions, nil
+}
+
 // downloadAll takes a list of dependencies and downloads them into charts/
 //
 // It will delete versions of the chart that exist on disk and might cause
@@ -272,7 +345,7 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 
 	fmt.Fprintf(m.Out, "Saving %d charts\n", len(deps))
 	var saveError error
-	churls := make(map[string]struct{})
+	churls := make(map[string]*chart.Dependency)
 	for _, dep := range deps {
 		// No repository means the chart is in charts directory
 		if dep.Repository == "" {
@@ -304,11 +377,16 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 			if m.Debug {
 				fmt.Fprintf(m.Out, "Archiving %s from repo %s\n", dep.Name, dep.Repository)
 			}
-			ver, err := tarFromLocalDir(m.ChartPath, dep.Name, dep.Repository, dep.Version, tmpPath)
+			ver, digest, err := tarFromLocalDir(m.ChartPath, dep.Name, dep.Repository, dep.Version, tmpPath)
 			if err != nil {
 				saveError = err
 				break
 			}
+			if prev := vendoredDigest(destPath, dep.Name); prev != "" && prev != digest {
+				fmt.Fprintf(m.Out, "Local dependency %s has changed, re-vendoring\n", dep.Name)
+			} else if m.Debug {
+				fmt.Fprintf(m.Out, "Local dependency %s is unchanged\n", dep.Name)
+			}
 			dep.Version = ver
 			continue
 		}
@@ -321,8 +399,12 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 			break
 		}
 
-		if _, ok := churls[churl]; ok {
+		if prev, ok := churls[churl]; ok {
 			fmt.Fprintf(m.Out, "Already downloaded %s from repo %s\n", dep.Name, dep.Repository)
+			if err := pinDigests(dep, prev.Digest, prev.ManifestDigest); err != nil {
+				saveError = err
+				break
+			}
 			continue
 		}
 
@@ -332,6 +414,7 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 			Out:              m.Out,
 			Verify:           m.Verify,
 			Keyring:          m.Keyring,
+			TrustPolicy:      m.TrustPolicy,
 			RepositoryConfig: m.RepositoryConfig,
 			RepositoryCache:  m.RepositoryCache,
 			ContentCache:     m.ContentCache,
@@ -356,12 +439,31 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 				getter.WithTagName(version))
 		}
 
-		if _, _, err = dl.DownloadTo(churl, version, tmpPath); err != nil {
+		pth, _, err := dl.DownloadTo(churl, version, tmpPath)
+		if err != nil {
 			saveError = fmt.Errorf("could not download %s: %w", churl, err)
 			break
 		}
+		digest, err := chartutil.FileDigest(pth)
+		if err != nil {
+			saveError = err
+			break
+		}
+		var manifestDigest string
+		if registry.IsOCI(churl) && m.RegistryClient != nil {
+			desc, err := m.RegistryClient.Resolve(strings.TrimPrefix(churl, registry.OCIScheme+"://") + ":" + version)
+			if err != nil {
+				saveError = fmt.Errorf("could not resolve the digest of %s: %w", churl, err)
+				break
+			}
+			manifestDigest = desc.Digest.String()
+		}
+		if err := pinDigests(dep, digest, manifestDigest); err != nil {
+			saveError = err
+			break
+		}
 
-		churls[churl] = struct{}{}
+		churls[churl] = dep
 	}
 
 	// TODO: this should probably be refactored to be a []error, so we can capture and provide more information rather than "last error wins".
@@ -377,6 +479,24 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 	return nil
 }
 
+// pinDigests records the digests a dependency was downloaded with, or checks
+// them against the digests recorded in the lock file. A dependency whose
+// digests changed since the lock file was written was re-published, and is
+// refused.
+func pinDigests(dep *chart.Dependency, digest, manifestDigest string) error {
+	if dep.Digest != "" && dep.Digest != digest {
+		return fmt.Errorf("dependency %s-%s has the digest %s, but the lock file records %s. The chart changed since the lock file was written, run 'helm dependency update' to accept the change", dep.Name, dep.Version, digest, dep.Digest)
+	}
+	if dep.ManifestDigest != "" && manifestDigest != "" && dep.ManifestDigest != manifestDigest {
+		return fmt.Errorf("dependency %s-%s has the manifest digest %s, but the lock file records %s. The chart changed since the lock file was written, run 'helm dependency update' to accept the change", dep.Name, dep.Version, manifestDigest, dep.ManifestDigest)
+	}
+	dep.Digest = digest
+	if manifestDigest != "" {
+		dep.ManifestDigest = manifestDigest
+	}
+	return nil
+}
+
 func parseOCIRef(chartRef string) (string, string, error) {
 	refTagRegexp := regexp.MustCompile(`^(oci://[^:]+(:[0-9]{1,5})?[^:]+):(.*)$`)
 	caps := refTagRegexp.FindStringSubmatch(chartRef)
@@ -396,6 +516,11 @@ func parseOCIRef(chartRef string) (string, string, error) {
 //
 // Any charts in dest that do not exist in source are removed (barring local dependencies)
 //
+// If Untar is set, each chart is expanded into a directory named after the chart
+// and the archive is removed. A directory holding a managed dependency that was
+// not vendored is removed as well, so switching between archives and directories
+// never leaves two copies of the same subchart behind.
+//
 // Because it requires tar file introspection, it is more intensive than a basic move.
 //
 // This will only return errors that should stop processing entirely. Other errors
@@ -403,6 +528,8 @@ func parseOCIRef(chartRef string) (string, string, error) {
 func (m *Manager) safeMoveDeps(deps []*chart.Dependency, source, dest string) error {
 	existsInSourceDirectory := map[string]bool{}
 	isLocalDependency := map[string]bool{}
+	isManagedDependency := map[string]bool{}
+	vendored := map[string]bool{}
 	sourceFiles, err := os.ReadDir(source)
 	if err != nil {
 		return err
@@ -416,6 +543,8 @@ func (m *Manager) safeMoveDeps(deps []*chart.Dependency, source, dest string) er
 	for _, dep := range deps {
 		if dep.Repository == "" {
 			isLocalDependency[dep.Name] = true
+		} else {
+			isManagedDependency[dep.Name] = true
 		}
 	}
 
@@ -427,7 +556,8 @@ func (m *Manager) safeMoveDeps(deps []*chart.Dependency, source, dest string) er
 		sourcefile := filepath.Join(source, filename)
 		destfile := filepath.Join(dest, filename)
 		existsInSourceDirectory[filename] = true
-		if _, err := loader.LoadFile(sourcefile); err != nil {
+		ch, err := loader.LoadFile(sourcefile)
+		if err != nil {
 			fmt.Fprintf(m.Out, "Could not verify %s for moving: %s (Skipping)", sourcefile, err)
 			continue
 		}
@@ -436,12 +566,26 @@ func (m *Manager) safeMoveDeps(deps []*chart.Dependency, source, dest string) er
 			fmt.Fprintf(m.Out, "Unable to move %s to charts dir %s (Skipping)", sourcefile, err)
 			continue
 		}
+		if !m.Untar {
+			continue
+		}
+		if vendored[ch.Name()] {
+			return fmt.Errorf("cannot vendor more than one version of %q as a directory", ch.Name())
+		}
+		if err := untarDependency(ch.Name(), destfile, dest); err != nil {
+			return fmt.Errorf("unable to vendor %s: %w", filename, err)
+		}
+		vendored[ch.Name()] = true
 	}
 
 	fmt.Fprintln(m.Out, "Deleting outdated charts")
 	// find all files that exist in dest that do not exist in source; delete them (outdated dependencies)
 	for _, file := range destFiles {
-		if !file.IsDir() && !existsInSourceDirectory[file.Name()] {
+		if file.IsDir() {
+			m.removeOutdatedChartDir(filepath.Join(dest, file.Name()), vendored, isManagedDependency)
+			continue
+		}
+		if !existsInSourceDirectory[file.Name()] {
 			fname := filepath.Join(dest, file.Name())
 			ch, err := loader.LoadFile(fname)
 			if err != nil {
@@ -462,6 +606,34 @@ func (m *Manager) safeMoveDeps(deps []*chart.Dependency, source, dest string) er
 	return nil
 }
 
+// untarDependency replaces the vendored directory of the named chart in dest
+// with the contents of archive, then removes the archive.
+func untarDependency(name, archive, dest string) error {
+	if err := os.RemoveAll(filepath.Join(dest, name)); err != nil {
+		return err
+	}
+	if err := chartutil.ExpandFile(dest, archive); err != nil {
+		return err
+	}
+	return os.Remove(archive)
+}
+
+// removeOutdatedChartDir removes an expanded chart directory in charts/ when it
+// holds a managed dependency that was not vendored by this run. Directories
+// that are not charts or belong to local dependencies are left alone.
+func (m *Manager) removeOutdatedChartDir(dir string, vendored, managed map[string]bool) {
+	md, err := chartutil.LoadChartfile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return
+	}
+	if vendored[md.Name] || !managed[md.Name] {
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		fmt.Fprintf(m.Out, "Could not delete %s: %s (Skipping)\n", dir, err)
+	}
+}
+
 // hasAllRepos ensures that all of the referenced deps are in the local repo cache.
 func (m *Manager) hasAllRepos(deps []*chart.Dependency) error {
 	rf, err := loadRepoConfig(m.RepositoryConfig)
@@ -749,8 +921,7 @@ func (m *Manager) findChartURL(name, version, repoURL string, repos map[string]*
 				//nolint:nakedret
 				return
 			}
-			username = cr.Config.Username
-			password = cr.Config.Password
+			username, password = cr.Config.BasicAuth()
 			passCredentialsAll = cr.Config.PassCredentialsAll
 			insecureSkipTLSVerify = cr.Config.InsecureSkipTLSVerify
 			caFile = cr.Config.CAFile
@@ -870,37 +1041,44 @@ func writeLock(chartpath string, lock *chart.Lock, legacyLockfile bool) error {
 }
 
 // archive a dep chart from local directory and save it into destPath
-func tarFromLocalDir(chartpath, name, repo, version, destPath string) (string, error) {
+func tarFromLocalDir(chartpath, name, repo, version, destPath string) (string, string, error) {
 	if !strings.HasPrefix(repo, "file://") {
-		return "", fmt.Errorf("wrong format: chart %s repository %s", name, repo)
+		return "", "", fmt.Errorf("wrong format: chart %s repository %s", name, repo)
 	}
 
 	origPath, err := resolver.GetLocalPath(repo, chartpath)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	ch, err := loader.LoadDir(origPath)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	constraint, err := semver.NewConstraint(version)
 	if err != nil {
-		return "", fmt.Errorf("dependency %s has an invalid version/constraint format: %w", name, err)
+		return "", "", fmt.Errorf("dependency %s has an invalid version/constraint format: %w", name, err)
 	}
 
 	v, err := semver.NewVersion(ch.Metadata.Version)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	if constraint.Check(v) {
+		// Record where the package came from so later builds can tell
+		// whether the source directory has changed since.
+		digest := chartDigest(ch)
+		if ch.Metadata.Annotations == nil {
+			ch.Metadata.Annotations = map[string]string{}
+		}
+		ch.Metadata.Annotations[SourceDigestAnnotation] = digest
 		_, err = chartutil.Save(ch, destPath)
-		return ch.Metadata.Version, err
+		return ch.Metadata.Version, digest, err
 	}
 
-	return "", fmt.Errorf("can't get a valid version for dependency %s", name)
+	return "", "", fmt.Errorf("can't get a valid version for dependency %s", name)
 }
 
 // The prefix to use for cache keys created by the manager for repo names
@@ -302,6 +302,64 @@ version: 0.1.0`
 	}
 }
 
+func TestDownloadAllUntar(t *testing.T) {
+	chartPath := t.TempDir()
+	m := &Manager{
+		Out:              new(bytes.Buffer),
+		RepositoryConfig: repoConfig,
+		RepositoryCache:  repoCache,
+		ChartPath:        chartPath,
+		Untar:            true,
+	}
+	signtest, err := loader.LoadDir(filepath.Join("testdata", "signtest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chartutil.SaveDir(signtest, filepath.Join(chartPath, "testdata")); err != nil {
+		t.Fatal(err)
+	}
+	signDep := &chart.Dependency{
+		Name:       signtest.Name(),
+		Repository: "file://./testdata/signtest",
+		Version:    signtest.Metadata.Version,
+	}
+
+	// A stale file in a previously vendored directory must not survive.
+	stale := filepath.Join(chartPath, "charts", "signtest", "stale.txt")
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.downloadAll([]*chart.Dependency{signDep}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(chartPath, "charts", "signtest", "Chart.yaml")); err != nil {
+		t.Errorf("expected vendored chart directory: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(chartPath, "charts", "signtest-0.1.0.tgz")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected archive to be removed, got %v", err)
+	}
+	if _, err := os.Stat(stale); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected stale file to be removed, got %v", err)
+	}
+
+	// Switching back to archives removes the vendored directory.
+	m.Untar = false
+	if err := m.downloadAll([]*chart.Dependency{signDep}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(chartPath, "charts", "signtest-0.1.0.tgz")); err != nil {
+		t.Errorf("expected archive: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(chartPath, "charts", "signtest")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected vendored directory to be removed, got %v", err)
+	}
+}
+
 func TestUpdateBeforeBuild(t *testing.T) {
 	// Set up a fake repo
 	srv := repotest.NewTempServer(
@@ -509,6 +567,86 @@ func checkBuildWithOptionalFields(t *testing.T, chartName string, dep chart.Depe
 	}
 }
 
+func TestBuild_PinsDigests(t *testing.T) {
+	srv := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/*.tgz*"),
+	)
+	defer srv.Stop()
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+	dir := func(p ...string) string {
+		return filepath.Join(append([]string{srv.Root()}, p...)...)
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "pinned",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{{
+				Name:       "local-subchart",
+				Version:    "0.1.0",
+				Repository: srv.URL(),
+			}},
+		},
+	}
+	if err := chartutil.SaveDir(c, dir()); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{
+		ChartPath: dir("pinned"),
+		Out:       bytes.NewBuffer(nil),
+		Getters: getter.Providers{getter.Provider{
+			Schemes: []string{"http", "https"},
+			New:     getter.NewHTTPGetter,
+		}},
+		RepositoryConfig: dir("repositories.yaml"),
+		RepositoryCache:  dir(),
+		ContentCache:     t.TempDir(),
+	}
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := chartutil.FileDigest("testdata/local-subchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := os.ReadFile(dir("pinned", "Chart.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(lock), "digest: "+want)
+
+	// The same version is published again with a different content.
+	sub, err := loader.Load("testdata/local-subchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub.Metadata.Description = "republished"
+	if _, err := chartutil.Save(sub, srv.Root()); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.CreateIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.ContentCache = t.TempDir()
+	err = m.Build()
+	assert.ErrorContains(t, err, "dependency local-subchart-0.1.0 has the digest")
+	assert.ErrorContains(t, err, "the lock file records "+want)
+
+	// Updating the dependencies accepts the new content.
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Build(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestBuild_WithoutOptionalFields(t *testing.T) {
 	// Dependency has main fields only (name/version/repository)
 	checkBuildWithOptionalFields(t, "without-optional-fields", chart.Dependency{})
@@ -767,3 +905,34 @@ func TestWriteLock(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestAvailableVersions(t *testing.T) {
+	m := &Manager{
+		Out:              new(bytes.Buffer),
+		SkipUpdate:       true,
+		RepositoryConfig: repoConfig,
+		RepositoryCache:  repoCache,
+	}
+	deps := []*chart.Dependency{
+		{Name: "alpine", Repository: "http://example.com"},
+		{Name: "local-dep", Repository: "file://./testdata/signtest"},
+		{Name: "foo", Repository: "@testing"},
+	}
+
+	versions, err := m.AvailableVersions(deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, vs := range versions {
+		var s []string
+		for _, v := range vs {
+			s = append(s, v.Original())
+		}
+		got = append(got, s)
+	}
+	expect := [][]string{{"1.2.3", "0.2.0"}, nil, {"1.2.3"}}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected versions %v, got %v", expect, got)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// DuplicateTemplateError reports a named template that is defined more than
+// once across a chart and its dependencies. Templates share a single
+// namespace, so only the last definition is used.
+type DuplicateTemplateError struct {
+	// Name is the name of the template.
+	Name string
+	// Locations are the locations of the definitions, as "file:line:column",
+	// in the order they are parsed. The last one is used.
+	Locations []string
+	// Identical is set when every definition has the same body, as happens
+	// when several subcharts vendor the same helpers. Which one is used then
+	// makes no difference.
+	Identical bool
+}
+
+func (e *DuplicateTemplateError) Error() string {
+	if e.Identical {
+		return fmt.Sprintf("template %q is defined %d times with the same body, at %s",
+			e.Name, len(e.Locations), strings.Join(e.Locations, ", "))
+	}
+	return fmt.Sprintf("template %q is defined %d times, at %s; the definition at %s is used",
+		e.Name, len(e.Locations), strings.Join(e.Locations, ", "), e.Locations[len(e.Locations)-1])
+}
+
+// templateDefinition is a definition of a named template.
+type templateDefinition struct {
+	location string
+	body     string
+}
+
+// templateDefinitions records where named templates are defined while the
+// template files are parsed.
+type templateDefinitions map[string][]templateDefinition
+
+// add records the templates defined by a file that was just parsed into t.
+// Empty redefinitions do not replace a template, so they are not recorded.
+func (d templateDefinitions) add(t *template.Template, filename string) {
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil || tmpl.Tree.ParseName != filename || tmpl.Name() == filename {
+			continue
+		}
+		location, _ := tmpl.Tree.ErrorContext(tmpl.Tree.Root)
+		d[tmpl.Name()] = append(d[tmpl.Name()], templateDefinition{location: location, body: tmpl.Tree.Root.String()})
+	}
+}
+
+// duplicates returns the templates defined more than once, sorted by name.
+func (d templateDefinitions) duplicates() []*DuplicateTemplateError {
+	var dups []*DuplicateTemplateError
+	for _, name := range slices.Sorted(func(yield func(string) bool) {
+		for name, defs := range d {
+			if len(defs) > 1 && !yield(name) {
+				return
+			}
+		}
+	}) {
+		dup := &DuplicateTemplateError{Name: name, Identical: true}
+		for _, def := range d[name] {
+			dup.Locations = append(dup.Locations, def.location)
+			if def.body != d[name][0].body {
+				dup.Identical = false
+			}
+		}
+		dups = append(dups, dup)
+	}
+	return dups
+}
+
+// reportDuplicateTemplates hands the duplicate templates to the handler of
+// the engine, if any.
+func (e Engine) reportDuplicateTemplates(defs templateDefinitions) {
+	if e.DuplicateTemplateHandler == nil {
+		return
+	}
+	for _, dup := range defs.duplicates() {
+		e.DuplicateTemplateHandler(dup)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestDuplicateTemplates(t *testing.T) {
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent", Version: "0.1.0"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte("{{ define \"labels\" }}parent{{ end }}\n{{ define \"empty\" }}{{ end }}\n{{ define \"same\" }}{{ .Chart.Name }}{{ end }}")},
+			{Name: "templates/cm.yaml", Data: []byte(`{{ include "labels" . }}`)},
+		},
+	}
+	parent.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub", Version: "0.1.0"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte("{{ define \"labels\" }}sub{{ end }}\n{{ define \"empty\" }}sub{{ end }}\n{{ define \"same\" }}{{.Chart.Name}}{{ end }}")},
+			{Name: "templates/_other.tpl", Data: []byte("\n{{ define \"labels\" }}other{{ end }}")},
+		},
+	})
+
+	var dups []*DuplicateTemplateError
+	e := Engine{DuplicateTemplateHandler: func(err *DuplicateTemplateError) { dups = append(dups, err) }}
+	out, err := e.Render(parent, map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, "parent", out["parent/templates/cm.yaml"])
+
+	// The empty redefinition of "empty" does not replace it, so it is not a
+	// duplicate. Deeper charts are parsed first, so the parent wins.
+	require.Len(t, dups, 2)
+	assert.Equal(t, &DuplicateTemplateError{
+		Name: "labels",
+		Locations: []string{
+			"parent/charts/sub/templates/_other.tpl:2:21",
+			"parent/charts/sub/templates/_helpers.tpl:1:21",
+			"parent/templates/_helpers.tpl:1:21",
+		},
+	}, dups[0])
+	assert.EqualError(t, dups[0], `template "labels" is defined 3 times, at parent/charts/sub/templates/_other.tpl:2:21, `+
+		`parent/charts/sub/templates/_helpers.tpl:1:21, parent/templates/_helpers.tpl:1:21; the definition at parent/templates/_helpers.tpl:1:21 is used`)
+
+	// Definitions that only differ in spacing inside actions are identical.
+	assert.Equal(t, &DuplicateTemplateError{
+		Name:      "same",
+		Locations: []string{"parent/charts/sub/templates/_helpers.tpl:3:19", "parent/templates/_helpers.tpl:3:19"},
+		Identical: true,
+	}, dups[1])
+	assert.EqualError(t, dups[1], `template "same" is defined 2 times with the same body, at `+
+		`parent/charts/sub/templates/_helpers.tpl:3:19, parent/templates/_helpers.tpl:3:19`)
+}
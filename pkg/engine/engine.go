@@ -48,6 +48,14 @@ type Engine struct {
 	EnableDNS bool
 	// CustomTemplateFuncs is defined by users to provide custom template funcs
 	CustomTemplateFuncs template.FuncMap
+	// FuncPolicy restricts the template functions that charts may call, when
+	// set.
+	FuncPolicy *FuncPolicy
+	// DuplicateTemplateHandler, when set, is called with each named template
+	// defined more than once across the rendered charts, as the linter does.
+	DuplicateTemplateHandler func(*DuplicateTemplateError)
+	// sourceMap instruments the templates with location markers
+	sourceMap bool
 }
 
 // New creates a new instance of Engine using the passed in rest config.
@@ -102,9 +110,28 @@ func (e Engine) Render(chrt ci.Charter, values common.Values) (map[string]string
 // that section of the values will be passed into the "foo" chart. And if that
 // section contains a value named "bar", that value will be passed on to the
 // bar chart during render time.
+//
+// The files a chart that sets rawManifests ships under 'manifests/' are added
+// to the output as they are. Once rendered, the patches a chart that sets
+// subchartPatches ships under 'patches/<subchart>/' are applied to the output
+// of its subcharts.
 func (e Engine) RenderWithContext(ctx context.Context, chrt ci.Charter, values common.Values) (map[string]string, error) {
+	imports, err := templateImports(chrt)
+	if err != nil {
+		return map[string]string{}, err
+	}
 	tmap := allTemplates(chrt, values)
-	return e.render(ctx, tmap)
+	rendered, err := e.render(ctx, tmap, imports)
+	if err != nil {
+		return rendered, err
+	}
+	if err := addRawManifests(chrt, rendered); err != nil {
+		return map[string]string{}, err
+	}
+	if err := applySubchartPatches(chrt, rendered); err != nil {
+		return map[string]string{}, fmt.Errorf("patching subcharts: %w", err)
+	}
+	return rendered, nil
 }
 
 // Render takes a chart, optional values, and value overrides, and attempts to
@@ -141,6 +168,9 @@ type renderable struct {
 	vals common.Values
 	// namespace prefix to the templates of the current chart
 	basePath string
+	// leftDelim and rightDelim are the template delimiters of the chart, or
+	// empty for the default ones.
+	leftDelim, rightDelim string
 }
 
 const warnStartDelim = "HELM_ERR_START"
@@ -176,7 +206,7 @@ func includeFun(t *template.Template, includedNames map[string]int) func(string,
 
 // As does 'tpl', so that nested calls to 'tpl' see the templates
 // defined by their enclosing contexts.
-func tplFun(parent *template.Template, includedNames map[string]int, strict bool) func(string, any) (string, error) {
+func tplFun(parent *template.Template, includedNames map[string]int, strict bool, policy *FuncPolicy) func(string, any) (string, error) {
 	return func(tpl string, vals any) (string, error) {
 		t, err := parent.Clone()
 		if err != nil {
@@ -194,10 +224,12 @@ func tplFun(parent *template.Template, includedNames map[string]int, strict bool
 
 		// Re-inject 'include' so that it can close over our clone of t;
 		// this lets any 'define's inside tpl be 'include'd.
-		t.Funcs(template.FuncMap{
+		funcs := template.FuncMap{
 			"include": includeFun(t, includedNames),
-			"tpl":     tplFun(t, includedNames, strict),
-		})
+			"tpl":     tplFun(t, includedNames, strict, policy),
+		}
+		policy.apply(funcs)
+		t.Funcs(funcs)
 
 		// We need a .New template, as template text which is just blanks
 		// or comments after parsing out defines just adds new named
@@ -227,7 +259,7 @@ func (e Engine) initFunMap(ctx context.Context, t *template.Template) {
 
 	// Add the template-rendering functions here so we can close over t.
 	funcMap["include"] = includeFun(t, includedNames)
-	funcMap["tpl"] = tplFun(t, includedNames, e.Strict)
+	funcMap["tpl"] = tplFun(t, includedNames, e.Strict, e.FuncPolicy)
 
 	// Add the `required` function here so we can use lintMode
 	funcMap["required"] = func(warn string, val any) (any, error) {
@@ -278,11 +310,15 @@ func (e Engine) initFunMap(ctx context.Context, t *template.Template) {
 	// Set custom template funcs
 	maps.Copy(funcMap, e.CustomTemplateFuncs)
 
+	// Restrict the functions last, so that the policy also covers the
+	// custom template funcs.
+	e.FuncPolicy.apply(funcMap)
+
 	t.Funcs(funcMap)
 }
 
 // render takes a map of templates/values and renders them.
-func (e Engine) render(ctx context.Context, tpls map[string]renderable) (rendered map[string]string, err error) {
+func (e Engine) render(ctx context.Context, tpls map[string]renderable, imports []templateImport) (rendered map[string]string, err error) {
 	// Basically, what we do here is start with an empty parent template and then
 	// build up a list of templates -- one for each file. Once all of the templates
 	// have been parsed, we loop through again and execute every template.
@@ -310,11 +346,21 @@ func (e Engine) render(ctx context.Context, tpls map[string]renderable) (rendere
 	// higher-level (in file system) templates over deeply nested templates.
 	keys := sortTemplates(tpls)
 
+	defs := templateDefinitions{}
 	for _, filename := range keys {
 		r := tpls[filename]
-		if _, err := t.New(filename).Parse(r.tpl); err != nil {
+		if _, err := t.New(filename).Delims(r.leftDelim, r.rightDelim).Parse(r.tpl); err != nil {
 			return map[string]string{}, cleanupParseError(filename, err)
 		}
+		defs.add(t, filename)
+	}
+	if e.sourceMap {
+		instrumentTemplates(t)
+	} else {
+		e.reportDuplicateTemplates(defs)
+	}
+	if err := addTemplateImports(t, imports, tpls); err != nil {
+		return map[string]string{}, err
 	}
 
 	rendered = make(map[string]string, len(keys))
@@ -329,7 +375,7 @@ func (e Engine) render(ctx context.Context, tpls map[string]renderable) (rendere
 		vals["Template"] = common.Values{"Name": filename, "BasePath": tpls[filename].basePath}
 		var buf strings.Builder
 		if err := t.ExecuteTemplate(&buf, filename, vals); err != nil {
-			return map[string]string{}, reformatExecErrorMsg(filename, err)
+			return map[string]string{}, reformatExecErrorMsg(filename, err, tpls)
 		}
 
 		// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
@@ -474,8 +520,9 @@ func parseTemplateExecutingAtErrorType(remainder string) (TraceableError, bool)
 }
 
 // reformatExecErrorMsg takes an error message for template rendering and formats it into a formatted
-// multi-line error string
-func reformatExecErrorMsg(filename string, err error) error {
+// multi-line error string. The innermost failing location is followed by a snippet of its template
+// and the values the failing action refers to.
+func reformatExecErrorMsg(filename string, err error, tpls map[string]renderable) error {
 	// This function parses the error message produced by text/template package.
 	// If it can parse out details from that error message such as the line number, template it failed on,
 	// and error description, then it will construct a new error that displays these details in a structured way.
@@ -516,8 +563,13 @@ func reformatExecErrorMsg(filename string, err error) error {
 	for _, fileLocation := range fileLocations {
 		_, _ = fmt.Fprintf(&finalErrorString, "%s", fileLocation.String())
 	}
+	msg := strings.TrimSpace(finalErrorString.String())
 
-	return errors.New(strings.TrimSpace(finalErrorString.String()))
+	innermost := fileLocations[len(fileLocations)-1]
+	if snippet := errorContext(innermost.location, innermost.executedFunction, filename, tpls); snippet != "" {
+		msg += "\n\n" + strings.TrimRight(snippet, "\n")
+	}
+	return errors.New(msg)
 }
 
 func sortTemplates(tpls map[string]renderable) []string {
@@ -590,6 +642,7 @@ func recAllTpls(c ci.Charter, templates map[string]renderable, values common.Val
 		subCharts[sub.Name()] = recAllTpls(child, templates, next)
 	}
 
+	leftDelim, rightDelim := templateDelims(chartMetaData)
 	newParentID := accessor.ChartFullPath()
 	for _, t := range accessor.Templates() {
 		if t == nil {
@@ -599,15 +652,29 @@ func recAllTpls(c ci.Charter, templates map[string]renderable, values common.Val
 			continue
 		}
 		templates[path.Join(newParentID, t.Name)] = renderable{
-			tpl:      string(t.Data),
-			vals:     next,
-			basePath: path.Join(newParentID, "templates"),
+			tpl:        string(t.Data),
+			vals:       next,
+			basePath:   path.Join(newParentID, "templates"),
+			leftDelim:  leftDelim,
+			rightDelim: rightDelim,
 		}
 	}
 
 	return next
 }
 
+// templateDelims returns the template delimiters declared by the metadata of
+// a chart, or empty strings for the default ones.
+func templateDelims(metadata map[string]any) (string, string) {
+	delims, _ := metadata["TemplateDelimiters"].([]any)
+	if len(delims) != 2 {
+		return "", ""
+	}
+	left, _ := delims[0].(string)
+	right, _ := delims[1].(string)
+	return left, right
+}
+
 // isTemplateValid returns true if the template is valid for the chart type
 func isTemplateValid(accessor ci.Accessor, templateName string) bool {
 	if accessor.IsLibraryChart() {
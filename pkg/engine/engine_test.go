@@ -197,7 +197,7 @@ func TestRenderInternals(t *testing.T) {
 		"three": {tpl: `{{template "two" dict "Value" "three"}}`, vals: vals},
 	}
 
-	out, err := new(Engine).render(t.Context(), tpls)
+	out, err := new(Engine).render(t.Context(), tpls, nil)
 	if err != nil {
 		t.Fatalf("Failed template rendering: %s", err)
 	}
@@ -443,7 +443,7 @@ func TestParallelRenderInternals(t *testing.T) {
 					vals: map[string]any{"val": tt},
 				},
 			}
-			out, err := e.render(t.Context(), tpls)
+			out, err := e.render(t.Context(), tpls, nil)
 			if err != nil {
 				t.Errorf("Failed to render %s: %s", tt, err)
 			}
@@ -462,7 +462,7 @@ func TestParseErrors(t *testing.T) {
 	tplsUndefinedFunction := map[string]renderable{
 		"undefined_function": {tpl: `{{foo}}`, vals: vals},
 	}
-	_, err := new(Engine).render(t.Context(), tplsUndefinedFunction)
+	_, err := new(Engine).render(t.Context(), tplsUndefinedFunction, nil)
 	if err == nil {
 		t.Fatalf("Expected failures while rendering: %s", err)
 	}
@@ -525,7 +525,7 @@ linebreak`,
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := new(Engine).render(t.Context(), tt.tpls)
+			_, err := new(Engine).render(t.Context(), tt.tpls, nil)
 			if err == nil {
 				t.Fatalf("Expected failures while rendering: %s", err)
 			}
@@ -543,7 +543,7 @@ func TestFailErrors(t *testing.T) {
 	tplsFailed := map[string]renderable{
 		"failtpl": {tpl: failtpl, vals: vals},
 	}
-	_, err := new(Engine).render(t.Context(), tplsFailed)
+	_, err := new(Engine).render(t.Context(), tplsFailed, nil)
 	if err == nil {
 		t.Fatalf("Expected failures while rendering: %s", err)
 	}
@@ -554,7 +554,7 @@ func TestFailErrors(t *testing.T) {
 
 	var e Engine
 	e.LintMode = true
-	out, err := e.render(t.Context(), tplsFailed)
+	out, err := e.render(t.Context(), tplsFailed, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -660,6 +660,40 @@ func TestRenderDependency(t *testing.T) {
 	}
 }
 
+func TestRenderTemplateDelimiters(t *testing.T) {
+	modTime := time.Now()
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "outerchart", TemplateDelimiters: []string{"[[", "]]"}},
+		Templates: []*common.File{
+			{Name: "templates/rules", ModTime: modTime, Data: []byte(`summary: "{{ $labels.instance }} of [[ .Values.who ]] is down"`)},
+			{Name: "templates/include", ModTime: modTime, Data: []byte(`[[ include "myblock" . ]] [[ tpl "{{ .Values.who }}" . ]]`)},
+		},
+		Values: map[string]any{"who": "world"},
+	}
+	ch.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "innerchart"},
+		Templates: []*common.File{
+			{Name: "templates/inner", ModTime: modTime, Data: []byte(`{{define "myblock"}}Hello{{end}}[[ not a template ]]`)},
+		},
+	})
+
+	out, err := Render(ch, map[string]any{"Values": map[string]any{"who": "world"}})
+	if err != nil {
+		t.Fatalf("failed to render chart: %s", err)
+	}
+
+	expect := map[string]string{
+		"outerchart/templates/rules":                   `summary: "{{ $labels.instance }} of world is down"`,
+		"outerchart/templates/include":                 "Hello world",
+		"outerchart/charts/innerchart/templates/inner": "[[ not a template ]]",
+	}
+	for name, data := range expect {
+		if out[name] != data {
+			t.Errorf("Expected %s to be %q, got %q", name, data, out[name])
+		}
+	}
+}
+
 func TestRenderNestedValues(t *testing.T) {
 	innerpath := "templates/inner.tpl"
 	outerpath := "templates/outer.tpl"
@@ -1343,7 +1377,12 @@ NestedHelperFunctions/templates/_helpers_1.tpl:1:39
     error calling include:
 NestedHelperFunctions/charts/common/templates/_helpers_2.tpl:1:49
   executing "common.names.get_name" at <.Values.nonexistant.key>:
-    nil pointer evaluating interface {}.key`
+    nil pointer evaluating interface {}.key
+
+  1 | {{- define "common.names.get_name" -}}{{- .Values.nonexistant.key | trunc 63 | trimSuffix "-" -}}{{- end -}}
+    |                                                  ^
+  .Values.nonexistant.key is not set; .Values is:
+    {}`
 
 	v := common.Values{}
 
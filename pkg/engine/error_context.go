@@ -0,0 +1,207 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+const (
+	// snippetContextLines is the number of template lines shown before the
+	// failing line.
+	snippetContextLines = 2
+	// maxValuesLines limits the values shown for a failing template.
+	maxValuesLines = 15
+	// redacted replaces values whose keys look like they hold secrets.
+	redacted = "<redacted>"
+)
+
+// valuesReference matches a reference to the chart values in a template action.
+var valuesReference = regexp.MustCompile(`\.Values((?:\.[A-Za-z_][A-Za-z0-9_]*)*)`)
+
+// sensitiveKey matches values keys that are not shown in error messages.
+var sensitiveKey = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api[-_]?key|private[-_]?key|credential|auth)`)
+
+// errorContext describes where a template failed: the template source around
+// the failing position, with a marker under the failing column, and the
+// values the failing action refers to.
+//
+// location is a "name:line:col" template location and action the failing
+// action as reported by text/template. The values are those of filename, the
+// template being rendered, as helpers are usually included with its context.
+// An empty string is returned when the template source is not available.
+func errorContext(location, action, filename string, tpls map[string]renderable) string {
+	name, line, col, ok := splitLocationColumn(location)
+	if !ok {
+		return ""
+	}
+	r, ok := tpls[name]
+	if !ok {
+		return ""
+	}
+	lines := strings.Split(r.tpl, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	var b strings.Builder
+	width := len(strconv.Itoa(line))
+	for n := max(1, line-snippetContextLines); n <= line; n++ {
+		fmt.Fprintf(&b, "  %*d | %s\n", width, n, lines[n-1])
+	}
+	fmt.Fprintf(&b, "  %*s | %s^\n", width, "", caretIndent(lines[line-1], col))
+
+	if vals, ok := tpls[filename].vals["Values"]; ok {
+		if ref, ok := failingValuesReference(action, lines[line-1], col); ok {
+			b.WriteString(valuesContext(ref, vals))
+		}
+	}
+	return b.String()
+}
+
+// failingValuesReference returns the values path the failing action refers
+// to. When the action is a function, the values reference closest before the
+// failing column of the line is used instead, as in '.Values.x | b64enc'.
+func failingValuesReference(action, line string, col int) (string, bool) {
+	if m := valuesReference.FindStringSubmatch(action); m != nil {
+		return m[1], true
+	}
+	ref, found := "", false
+	for _, m := range valuesReference.FindAllStringSubmatchIndex(line, -1) {
+		if m[0] > col {
+			break
+		}
+		ref, found = line[m[2]:m[3]], true
+	}
+	return ref, found
+}
+
+// caretIndent returns the whitespace that puts a marker under byte offset col
+// of line, keeping tabs so that the marker lines up.
+func caretIndent(line string, col int) string {
+	col = min(max(col, 0), len(line))
+	var b strings.Builder
+	for _, c := range line[:col] {
+		if c == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
+
+// valuesContext shows the deepest part of path that is set in vals.
+func valuesContext(path string, vals any) string {
+	keys := strings.Split(strings.TrimPrefix(path, "."), ".")
+	if path == "" {
+		keys = nil
+	}
+	current := asMap(vals)
+	var found []string
+	var value any = current
+	for _, k := range keys {
+		if current == nil {
+			break
+		}
+		v, ok := current[k]
+		if !ok {
+			break
+		}
+		found = append(found, k)
+		value = v
+		current = asMap(v)
+	}
+
+	ref := strings.Join(append([]string{".Values"}, found...), ".")
+	var b strings.Builder
+	if len(found) < len(keys) {
+		fmt.Fprintf(&b, "  .Values.%s is not set; %s is:\n", strings.Join(keys, "."), ref)
+	} else {
+		fmt.Fprintf(&b, "  %s is:\n", ref)
+	}
+	if len(found) > 0 && sensitiveKey.MatchString(found[len(found)-1]) {
+		value = redacted
+	}
+	data, err := yaml.Marshal(redact(value))
+	if err != nil {
+		return ""
+	}
+	out := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(out) > maxValuesLines {
+		out = append(out[:maxValuesLines], "...")
+	}
+	for _, l := range out {
+		fmt.Fprintf(&b, "    %s\n", l)
+	}
+	return b.String()
+}
+
+// redact returns a copy of v in which the values of sensitive keys are replaced.
+func redact(v any) any {
+	m := asMap(v)
+	if m == nil {
+		if list, ok := v.([]any); ok {
+			out := make([]any, len(list))
+			for i, item := range list {
+				out[i] = redact(item)
+			}
+			return out
+		}
+		return v
+	}
+	out := make(map[string]any, len(m))
+	for k, item := range m {
+		if sensitiveKey.MatchString(k) {
+			out[k] = redacted
+			continue
+		}
+		out[k] = redact(item)
+	}
+	return out
+}
+
+func asMap(v any) map[string]any {
+	switch m := v.(type) {
+	case map[string]any:
+		return m
+	case common.Values:
+		return m
+	}
+	return nil
+}
+
+// splitLocationColumn splits a "name:line:col" location.
+func splitLocationColumn(location string) (string, int, int, bool) {
+	i := strings.LastIndexByte(location, ':')
+	if i < 0 {
+		return "", 0, 0, false
+	}
+	col, err := strconv.Atoi(location[i+1:])
+	if err != nil {
+		return "", 0, 0, false
+	}
+	name, line, ok := splitLocation(location)
+	return name, line, col, ok
+}
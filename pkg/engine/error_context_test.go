@@ -0,0 +1,132 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestRenderErrorContext(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/secret.yaml", Data: []byte(`apiVersion: v1
+kind: Secret
+data:
+  user: {{ .Values.db.user | b64enc }}
+	port: {{ .Values.db.port | b64enc }}
+`)},
+		},
+	}
+	vals := common.Values{"Values": map[string]any{
+		"db": map[string]any{
+			"user":     "admin",
+			"password": "hunter2",
+			"port":     5432,
+			"auth":     map[string]any{"token": "abc"},
+		},
+	}}
+
+	_, err := Render(c, vals)
+	require.Error(t, err)
+	assert.Equal(t, `moby/templates/secret.yaml:5:28
+  executing "moby/templates/secret.yaml" at <b64enc>:
+    wrong type for value; expected string; got int
+
+  3 | data:
+  4 |   user: {{ .Values.db.user | b64enc }}
+  5 | 	port: {{ .Values.db.port | b64enc }}
+    | 	                           ^
+  .Values.db.port is:
+    5432`, err.Error())
+}
+
+func TestValuesContext(t *testing.T) {
+	vals := map[string]any{
+		"db": map[string]any{
+			"user":     "admin",
+			"password": "hunter2",
+			"auth":     map[string]any{"token": "abc"},
+			"hosts":    []any{map[string]any{"name": "a", "apiKey": "xyz"}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		expect string
+	}{
+		{
+			name: "missing key shows the deepest set parent with secrets redacted",
+			path: ".db.port",
+			expect: `  .Values.db.port is not set; .Values.db is:
+    auth: <redacted>
+    hosts:
+    - apiKey: <redacted>
+      name: a
+    password: <redacted>
+    user: admin
+`,
+		},
+		{
+			name:   "sensitive leaf",
+			path:   ".db.password",
+			expect: "  .Values.db.password is:\n    <redacted>\n",
+		},
+		{
+			name:   "set leaf",
+			path:   ".db.user",
+			expect: "  .Values.db.user is:\n    admin\n",
+		},
+		{
+			name:   "below a scalar",
+			path:   ".db.user.name",
+			expect: "  .Values.db.user.name is not set; .Values.db.user is:\n    admin\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, valuesContext(tt.path, vals))
+		})
+	}
+}
+
+func TestRenderErrorContextValues(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/cm.yaml", Data: []byte(`value: {{ .Values.db.conn.host }}`)},
+		},
+	}
+	vals := common.Values{"Values": map[string]any{
+		"db": map[string]any{"password": "hunter2"},
+	}}
+
+	_, err := Render(c, vals)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `  1 | value: {{ .Values.db.conn.host }}
+    |                  ^
+  .Values.db.conn.host is not set; .Values.db is:
+    password: <redacted>`)
+	assert.NotContains(t, err.Error(), "hunter2")
+}
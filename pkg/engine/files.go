@@ -17,7 +17,10 @@ limitations under the License.
 package engine
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"io"
 	"path"
 	"strings"
 
@@ -27,30 +30,60 @@ import (
 )
 
 // files is a map of files in a chart that can be accessed from a template.
-type files map[string][]byte
+//
+// The data of lazily loaded files is read when a template accesses them.
+type files map[string]fileData
+
+// fileData is a file of a chart as seen by a template. It prints as the data
+// of the file, so that {{ index .Files $path }} keeps working.
+type fileData struct {
+	file *common.File
+}
+
+// String returns the data of the file, or an empty string if it cannot be
+// loaded.
+func (d fileData) String() string {
+	if d.file == nil || d.file.Load() != nil {
+		return ""
+	}
+	return string(d.file.Data)
+}
 
 // NewFiles creates a new files from chart files.
 // Given an []*chart.File (the format for files in a chart.Chart), extract a map of files.
 func newFiles(from []*common.File) files {
-	files := make(map[string][]byte)
+	files := make(files, len(from))
 	for _, f := range from {
-		files[f.Name] = f.Data
+		files[f.Name] = fileData{file: f}
 	}
 	return files
 }
 
+// data returns the data of a file, loading it if needed. ok is false if the
+// file does not exist.
+func (f files) data(name string) (data []byte, ok bool, err error) {
+	d, ok := f[name]
+	if !ok || d.file == nil {
+		return nil, false, nil
+	}
+	if err := d.file.Load(); err != nil {
+		return nil, true, err
+	}
+	return d.file.Data, true, nil
+}
+
 // GetBytes gets a file by path.
 //
-// The returned data is raw. In a template context, this is identical to calling
-// {{index .Files $path}}.
+// The returned data is raw.
 //
 // This is intended to be accessed from within a template, so a missed key returns
 // an empty []byte.
-func (f files) GetBytes(name string) []byte {
-	if v, ok := f[name]; ok {
-		return v
+func (f files) GetBytes(name string) ([]byte, error) {
+	data, ok, err := f.data(name)
+	if !ok || err != nil {
+		return []byte{}, err
 	}
-	return []byte{}
+	return data, nil
 }
 
 // Get returns a string representation of the given file.
@@ -59,27 +92,38 @@ func (f files) GetBytes(name string) []byte {
 // template.
 //
 //	{{.Files.Get "foo"}}
-func (f files) Get(name string) string {
-	return string(f.GetBytes(name))
+func (f files) Get(name string) (string, error) {
+	data, err := f.GetBytes(name)
+	return string(data), err
 }
 
-// Glob takes a glob pattern and returns another files object only containing
-// matched files.
+// Glob takes glob patterns and returns another files object only containing
+// matched files. Patterns that start with '!' exclude the files they match.
+// When every pattern is an exclusion, the other files are matched.
 //
 // This is designed to be called from a template.
 //
-// {{ range $name, $content := .Files.Glob("foo/**") }}
+// {{ range $name, $content := .Files.Glob "foo/**" "!foo/*.bak" }}
 // {{ $name }}: |
-// {{ .Files.Get($name) | indent 4 }}{{ end }}
-func (f files) Glob(pattern string) files {
-	g, err := glob.Compile(pattern, '/')
-	if err != nil {
-		g, _ = glob.Compile("**")
+// {{ $.Files.Get $name | indent 4 }}{{ end }}
+func (f files) Glob(pattern string, more ...string) files {
+	var include, exclude []glob.Glob
+	for _, p := range append([]string{pattern}, more...) {
+		negated := strings.HasPrefix(p, "!")
+		g, err := glob.Compile(strings.TrimPrefix(p, "!"), '/')
+		if err != nil {
+			g, _ = glob.Compile("**")
+		}
+		if negated {
+			exclude = append(exclude, g)
+		} else {
+			include = append(include, g)
+		}
 	}
 
 	nf := newFiles(nil)
 	for name, contents := range f {
-		if g.Match(name) {
+		if matchesGlobs(include, name, len(include) == 0) && !matchesGlobs(exclude, name, false) {
 			nf[name] = contents
 		}
 	}
@@ -87,6 +131,92 @@ func (f files) Glob(pattern string) files {
 	return nf
 }
 
+// matchesGlobs reports whether name matches any of the globs, or returns
+// empty when there are none.
+func matchesGlobs(globs []glob.Glob, name string, empty bool) bool {
+	if len(globs) == 0 {
+		return empty
+	}
+	for _, g := range globs {
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBytesRange returns length bytes of a file from offset. The range is
+// clamped to the size of the file, so the result may be shorter than length,
+// and a negative length reads to the end of the file. Only the requested
+// range of lazily loaded files is read.
+//
+// The returned data is raw, so that binary files can be read in parts. It is
+// designed to be called in a template.
+//
+//	{{ .Files.GetBytesRange "data.bin" 0 512 | b64enc }}
+func (f files) GetBytesRange(name string, offset, length int) ([]byte, error) {
+	d, ok := f[name]
+	if !ok || d.file == nil || offset < 0 || int64(offset) >= d.file.Size() {
+		return []byte{}, nil
+	}
+	r, err := d.file.Open()
+	if err != nil {
+		return []byte{}, err
+	}
+	defer r.Close()
+	if _, err := io.CopyN(io.Discard, r, int64(offset)); err != nil {
+		return []byte{}, err
+	}
+	var in io.Reader = r
+	if length >= 0 {
+		in = io.LimitReader(r, int64(length))
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+// Digest returns the SHA-256 digest of a file, prepended with the scheme, or
+// an empty string if the file does not exist. Lazily loaded files are hashed
+// as they are read, without being loaded.
+//
+// This is designed to be called from a template, for example to roll
+// deployments when a file changes.
+//
+//	checksum/config: {{ .Files.Digest "config.yaml" | quote }}
+func (f files) Digest(name string) (string, error) {
+	d, ok := f[name]
+	if !ok || d.file == nil {
+		return "", nil
+	}
+	r, err := d.file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Size returns the size of a file in bytes, or 0 if the file does not exist.
+// Lazily loaded files are not loaded.
+//
+// This is designed to be called from a template.
+//
+//	{{ if gt (.Files.Size "large.bin") 1048576 }}...{{ end }}
+func (f files) Size(name string) int {
+	d, ok := f[name]
+	if !ok || d.file == nil {
+		return 0
+	}
+	return int(d.file.Size())
+}
+
 // AsConfig turns a Files group and flattens it to a YAML map suitable for
 // including in the 'data' section of a Kubernetes ConfigMap definition.
 // Duplicate keys will be overwritten, so be aware that your file names
@@ -102,19 +232,23 @@ func (f files) Glob(pattern string) files {
 //	data:
 //
 // {{ .Files.Glob("config/**").AsConfig() | indent 4 }}
-func (f files) AsConfig() string {
+func (f files) AsConfig() (string, error) {
 	if f == nil {
-		return ""
+		return "", nil
 	}
 
 	m := make(map[string]string)
 
 	// Explicitly convert to strings, and file names
-	for k, v := range f {
+	for k := range f {
+		v, _, err := f.data(k)
+		if err != nil {
+			return "", err
+		}
 		m[path.Base(k)] = string(v)
 	}
 
-	return toYAML(m)
+	return toYAML(m), nil
 }
 
 // AsSecrets returns the base64-encoded value of a Files object suitable for
@@ -132,18 +266,22 @@ func (f files) AsConfig() string {
 //	data:
 //
 // {{ .Files.Glob("secrets/*").AsSecrets() | indent 4 }}
-func (f files) AsSecrets() string {
+func (f files) AsSecrets() (string, error) {
 	if f == nil {
-		return ""
+		return "", nil
 	}
 
 	m := make(map[string]string)
 
-	for k, v := range f {
+	for k := range f {
+		v, _, err := f.data(k)
+		if err != nil {
+			return "", err
+		}
 		m[path.Base(k)] = base64.StdEncoding.EncodeToString(v)
 	}
 
-	return toYAML(m)
+	return toYAML(m), nil
 }
 
 // Lines returns each line of a named file (split by "\n") as a slice, so it can
@@ -153,13 +291,14 @@ func (f files) AsSecrets() string {
 //
 // {{ range .Files.Lines "foo/bar.html" }}
 // {{ . }}{{ end }}
-func (f files) Lines(path string) []string {
-	if f == nil || f[path] == nil {
-		return []string{}
+func (f files) Lines(path string) ([]string, error) {
+	data, _, err := f.data(path)
+	if err != nil || len(data) == 0 {
+		return []string{}, err
 	}
-	s := string(f[path])
+	s := string(data)
 	if s[len(s)-1] == '\n' {
 		s = s[:len(s)-1]
 	}
-	return strings.Split(s, "\n")
+	return strings.Split(s, "\n"), nil
 }
@@ -16,9 +16,18 @@ limitations under the License.
 package engine
 
 import (
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
 )
 
 var cases = []struct {
@@ -35,7 +44,7 @@ var cases = []struct {
 func getTestFiles() files {
 	a := make(files, len(cases))
 	for _, c := range cases {
-		a[c.path] = []byte(c.data)
+		a[c.path] = fileData{file: &common.File{Name: c.path, Data: []byte(c.data)}}
 	}
 	return a
 }
@@ -47,10 +56,10 @@ func TestNewFiles(t *testing.T) {
 	}
 
 	for i, f := range cases {
-		if got := string(files.GetBytes(f.path)); got != f.data {
+		if got, _ := files.GetBytes(f.path); string(got) != f.data {
 			t.Errorf("%d: expected %q, got %q", i, f.data, got)
 		}
-		if got := files.Get(f.path); got != f.data {
+		if got, _ := files.Get(f.path); got != f.data {
 			t.Errorf("%d: expected %q, got %q", i, f.data, got)
 		}
 	}
@@ -64,17 +73,65 @@ func TestFileGlob(t *testing.T) {
 	matched := f.Glob("story/**")
 
 	as.Len(matched, 2, "Should be two files in glob story/**")
-	as.Equal("Joseph Conrad", matched.Get("story/author.txt"))
+	author, err := matched.Get("story/author.txt")
+	as.NoError(err)
+	as.Equal("Joseph Conrad", author)
+}
+
+func TestFileGlobExclusions(t *testing.T) {
+	f := getTestFiles()
+
+	tests := []struct {
+		patterns []string
+		expect   []string
+	}{
+		{[]string{"ship/**", "!ship/stowaway.txt"}, []string{"ship/captain.txt"}},
+		{[]string{"ship/**", "story/**", "!**/name.txt", "!**/author.txt"}, []string{"ship/captain.txt", "ship/stowaway.txt"}},
+		{[]string{"!multiline/**", "!ship/**"}, []string{"story/author.txt", "story/name.txt"}},
+	}
+	for _, tt := range tests {
+		matched := f.Glob(tt.patterns[0], tt.patterns[1:]...)
+		assert.ElementsMatch(t, tt.expect, slices.Collect(maps.Keys(matched)), "patterns %v", tt.patterns)
+	}
+}
+
+func TestFileRangeDigestSize(t *testing.T) {
+	f := newFiles([]*common.File{{Name: "data.bin", Data: []byte{0x00, 0x01, 0xff, 0xfe, 0x0a}}})
+
+	tests := []struct {
+		offset, length int
+		expect         []byte
+	}{
+		{0, 2, []byte{0x00, 0x01}},
+		{2, 10, []byte{0xff, 0xfe, 0x0a}},
+		{3, -1, []byte{0xfe, 0x0a}},
+		{5, 1, []byte{}},
+		{-1, 1, []byte{}},
+	}
+	for _, tt := range tests {
+		got, err := f.GetBytesRange("data.bin", tt.offset, tt.length)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expect, got, "range %d+%d", tt.offset, tt.length)
+	}
+	got, _ := f.GetBytesRange("missing", 0, 1)
+	assert.Equal(t, []byte{}, got)
+
+	assert.Equal(t, 5, f.Size("data.bin"))
+	assert.Equal(t, 0, f.Size("missing"))
+	digest, _ := f.Digest("data.bin")
+	assert.Equal(t, "sha256:72123f8f36efb3587d8d1ce5c2c28c6ad15ccdd8b9b56a17db1f4af6bfe61399", digest)
+	digest, _ = f.Digest("missing")
+	assert.Empty(t, digest)
 }
 
 func TestToConfig(t *testing.T) {
 	as := assert.New(t)
 
 	f := getTestFiles()
-	out := f.Glob("**/captain.txt").AsConfig()
+	out, _ := f.Glob("**/captain.txt").AsConfig()
 	as.Equal("captain.txt: The Captain", out)
 
-	out = f.Glob("ship/**").AsConfig()
+	out, _ = f.Glob("ship/**").AsConfig()
 	as.Equal("captain.txt: The Captain\nstowaway.txt: Legatt", out)
 }
 
@@ -83,7 +140,7 @@ func TestToSecret(t *testing.T) {
 
 	f := getTestFiles()
 
-	out := f.Glob("ship/**").AsSecrets()
+	out, _ := f.Glob("ship/**").AsSecrets()
 	as.Equal("captain.txt: VGhlIENhcHRhaW4=\nstowaway.txt: TGVnYXR0", out)
 }
 
@@ -92,7 +149,7 @@ func TestLines(t *testing.T) {
 
 	f := getTestFiles()
 
-	out := f.Lines("multiline/test.txt")
+	out, _ := f.Lines("multiline/test.txt")
 	as.Len(out, 2)
 
 	as.Equal("bar", out[0])
@@ -103,9 +160,68 @@ func TestBlankLines(t *testing.T) {
 
 	f := getTestFiles()
 
-	out := f.Lines("multiline/test_with_blank_lines.txt")
+	out, _ := f.Lines("multiline/test_with_blank_lines.txt")
 	as.Len(out, 4)
 
 	as.Equal("bar", out[0])
 	as.Empty(out[3])
 }
+
+func lazy(name, data string) *common.File {
+	return common.NewLazyFile(name, time.Time{}, int64(len(data)), func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(data)), nil
+	})
+}
+
+func TestLazyFileRangeDigestSize(t *testing.T) {
+	file := lazy("data.bin", "\x00\x01\xff\xfe\x0a")
+	f := newFiles([]*common.File{file})
+
+	got, err := f.GetBytesRange("data.bin", 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xfe}, got)
+	assert.Equal(t, 5, f.Size("data.bin"))
+	digest, err := f.Digest("data.bin")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:72123f8f36efb3587d8d1ce5c2c28c6ad15ccdd8b9b56a17db1f4af6bfe61399", digest)
+	assert.False(t, file.IsLoaded(), "ranges, digests and sizes do not load the file")
+}
+
+func TestRenderLazyFiles(t *testing.T) {
+	used, unused := lazy("data/used.txt", "used"), lazy("data/unused.txt", "unused")
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "lazy", Version: "0.1.0"},
+		Templates: []*common.File{
+			{Name: "templates/cm.yaml", Data: []byte(`data: {{ .Files.Get (printf "data/%s" .Values.file) }}`)},
+		},
+		Files: []*common.File{used, unused},
+	}
+	out, err := Render(c, common.Values{"Values": map[string]any{"file": "used.txt"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "data: used", out["lazy/templates/cm.yaml"])
+	assert.True(t, used.IsLoaded())
+	assert.False(t, unused.IsLoaded(), "files that are not accessed are not loaded")
+
+	c.Files = []*common.File{common.NewLazyFile("data/used.txt", time.Time{}, 4, func() (io.ReadCloser, error) {
+		return nil, errors.New("archive changed")
+	})}
+	_, err = Render(c, common.Values{"Values": map[string]any{"file": "used.txt"}})
+	assert.ErrorContains(t, err, "archive changed")
+}
+
+func TestRenderFilesAPI(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/cm.yaml", Data: []byte(`{{ .Files.GetBytesRange "ship/captain.txt" 4 7 | printf "%s" }} {{ .Files.Size "ship/captain.txt" }}
+{{- range $name, $_ := .Files.Glob "ship/*" "!**/captain.txt" }} {{ $name }}{{ end }}`)},
+		},
+		Files: []*common.File{
+			{Name: "ship/captain.txt", Data: []byte("The Captain")},
+			{Name: "ship/stowaway.txt", Data: []byte("Legatt")},
+		},
+	}
+	out, err := Render(c, common.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Captain 11 ship/stowaway.txt", out["moby/templates/cm.yaml"])
+}
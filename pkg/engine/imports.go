@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+)
+
+// templateImport makes a template exported by a library chart available to a
+// chart depending on it, under the template alias of the dependency.
+type templateImport struct {
+	// name is the name the template is imported as.
+	name string
+	// source is the name of the exported template.
+	source string
+	// importer and exporter are the full paths of the charts.
+	importer, exporter string
+}
+
+// templateImports returns the templates imported by a chart and its
+// dependencies from the charts they depend on.
+func templateImports(c ci.Charter) ([]templateImport, error) {
+	accessor, err := ci.NewAccessor(c)
+	if err != nil {
+		return nil, err
+	}
+	aliases := map[string]string{}
+	deps, _ := accessor.MetadataAsMap()["Dependencies"].([]any)
+	for _, d := range deps {
+		dep, _ := d.(map[string]any)
+		alias, _ := dep["TemplateAlias"].(string)
+		if alias == "" {
+			continue
+		}
+		name, _ := dep["Alias"].(string)
+		if name == "" {
+			name, _ = dep["Name"].(string)
+		}
+		aliases[name] = alias
+	}
+
+	var imports []templateImport
+	for _, child := range accessor.Dependencies() {
+		sub, err := ci.NewAccessor(child)
+		if err != nil {
+			return nil, err
+		}
+		if alias, ok := aliases[sub.Name()]; ok {
+			exports, _ := sub.MetadataAsMap()["ExportTemplates"].([]any)
+			if len(exports) == 0 {
+				return nil, fmt.Errorf("chart %q imports the templates of chart %q, which exports none", accessor.ChartFullPath(), sub.ChartFullPath())
+			}
+			for _, e := range exports {
+				source, _ := e.(string)
+				imports = append(imports, templateImport{
+					name:     alias + "." + source,
+					source:   source,
+					importer: accessor.ChartFullPath(),
+					exporter: sub.ChartFullPath(),
+				})
+			}
+		}
+		childImports, err := templateImports(child)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, childImports...)
+	}
+	return imports, nil
+}
+
+// addTemplateImports adds the imported templates to the parsed templates. An
+// exported template must be defined by the chart exporting it, and an
+// imported template must not have the name of another template.
+func addTemplateImports(t *template.Template, imports []templateImport, tpls map[string]renderable) error {
+	for _, imp := range imports {
+		src := t.Lookup(imp.source)
+		if src == nil || src.Tree == nil {
+			return fmt.Errorf("chart %q exports template %q, which it does not define", imp.exporter, imp.source)
+		}
+		if definer := templateChart(src, tpls); definer != imp.exporter {
+			return fmt.Errorf("template %q exported by chart %q is overridden by chart %q", imp.source, imp.exporter, definer)
+		}
+		if existing := t.Lookup(imp.name); existing != nil && existing.Tree != nil {
+			return fmt.Errorf("template %q imported by chart %q from chart %q is already defined by chart %q", imp.name, imp.importer, imp.exporter, templateChart(existing, tpls))
+		}
+		if _, err := t.AddParseTree(imp.name, src.Tree); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateChart returns the full path of the chart that defines a template.
+func templateChart(t *template.Template, tpls map[string]renderable) string {
+	file := t.Tree.ParseName
+	if r, ok := tpls[file]; ok && r.basePath != "" {
+		return strings.TrimSuffix(r.basePath, "/templates")
+	}
+	return file
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func chartWithImports(parentTemplates map[string]string, libraries ...*chart.Chart) *chart.Chart {
+	parent := &chart.Chart{Metadata: &chart.Metadata{Name: "parent", Version: "0.1.0"}}
+	for name, data := range parentTemplates {
+		parent.Templates = append(parent.Templates, &common.File{Name: "templates/" + name, Data: []byte(data)})
+	}
+	for _, lib := range libraries {
+		parent.Metadata.Dependencies = append(parent.Metadata.Dependencies, &chart.Dependency{Name: lib.Name(), TemplateAlias: "c"})
+		parent.AddDependency(lib)
+	}
+	return parent
+}
+
+func library(name string, exports []string, helpers string) *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{Name: name, Version: "0.1.0", Type: "library", ExportTemplates: exports},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(helpers)},
+		},
+	}
+}
+
+func TestTemplateImports(t *testing.T) {
+	common := library("common", []string{"common.labels"}, `{{ define "common.labels" }}app: {{ .Chart.Name }}{{ end }}`)
+
+	out, err := Render(chartWithImports(map[string]string{
+		"cm.yaml": `{{ include "c.common.labels" . }} {{ include "common.labels" . }}`,
+	}, common), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, "app: parent app: parent", out["parent/templates/cm.yaml"])
+}
+
+func TestTemplateImportsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		chart  *chart.Chart
+		expect string
+	}{
+		{
+			name:   "not exported",
+			chart:  chartWithImports(nil, library("common", nil, "")),
+			expect: `chart "parent" imports the templates of chart "parent/charts/common", which exports none`,
+		},
+		{
+			name:   "not defined",
+			chart:  chartWithImports(nil, library("common", []string{"common.labels"}, "")),
+			expect: `chart "parent/charts/common" exports template "common.labels", which it does not define`,
+		},
+		{
+			name: "overridden",
+			chart: chartWithImports(map[string]string{
+				"_helpers.tpl": `{{ define "common.labels" }}parent{{ end }}`,
+			}, library("common", []string{"common.labels"}, `{{ define "common.labels" }}{{ end }}`)),
+			expect: `template "common.labels" exported by chart "parent/charts/common" is overridden by chart "parent"`,
+		},
+		{
+			name: "already defined",
+			chart: chartWithImports(map[string]string{
+				"_helpers.tpl": `{{ define "c.common.labels" }}{{ end }}`,
+			}, library("common", []string{"common.labels"}, `{{ define "common.labels" }}{{ end }}`)),
+			expect: `template "c.common.labels" imported by chart "parent" from chart "parent/charts/common" is already defined by chart "parent"`,
+		},
+		{
+			name: "exported by two charts",
+			chart: chartWithImports(nil,
+				library("one", []string{"labels"}, `{{ define "labels" }}one{{ end }}`),
+				library("two", []string{"labels"}, `{{ define "labels" }}two{{ end }}`),
+			),
+			expect: `template "labels" exported by chart "parent/charts/two" is overridden by chart "parent/charts/one"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Render(tt.chart, map[string]any{})
+			assert.ErrorContains(t, err, tt.expect)
+		})
+	}
+}
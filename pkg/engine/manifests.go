@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+)
+
+// ManifestsDir is the directory of a chart holding manifests that are
+// included in the release verbatim, without being rendered as templates. It
+// lets charts ship manifests generated upstream that contain '{{' sequences
+// of their own. Charts opt in with the rawManifests field of Chart.yaml.
+const ManifestsDir = "manifests"
+
+// addRawManifests adds the YAML and JSON files of the manifests directories
+// of a chart and its dependencies to the rendered output, named like the
+// templates of the charts, for example 'mychart/manifests/crds.yaml'. Only
+// the charts that set rawManifests are considered. Every document of the
+// files must be a Kubernetes object with an apiVersion, a kind and a name.
+func addRawManifests(c ci.Charter, rendered map[string]string) error {
+	accessor, err := ci.NewAccessor(c)
+	if err != nil {
+		return err
+	}
+	for _, child := range accessor.Dependencies() {
+		if err := addRawManifests(child, rendered); err != nil {
+			return err
+		}
+	}
+	if accessor.IsLibraryChart() {
+		return nil
+	}
+	if enabled, _ := accessor.MetadataAsMap()["RawManifests"].(bool); !enabled {
+		return nil
+	}
+
+	for _, f := range accessor.Files() {
+		if !strings.HasPrefix(f.Name, ManifestsDir+"/") {
+			continue
+		}
+		switch path.Ext(f.Name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if err := f.Load(); err != nil {
+			return err
+		}
+		for _, doc := range docSeparator.Split(string(f.Data), -1) {
+			if err := validateRawManifest(doc); err != nil {
+				return fmt.Errorf("%s: %s: %w", accessor.Name(), f.Name, err)
+			}
+		}
+		rendered[path.Join(accessor.ChartFullPath(), f.Name)] = string(f.Data)
+	}
+	return nil
+}
+
+// validateRawManifest checks that a document of a raw manifest is empty or a
+// Kubernetes object.
+func validateRawManifest(doc string) error {
+	var obj struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal([]byte(doc), &raw); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+	switch {
+	case obj.APIVersion == "":
+		return errors.New("manifest has no apiVersion")
+	case obj.Kind == "":
+		return errors.New("manifest has no kind")
+	case obj.Metadata.Name == "":
+		return fmt.Errorf("%s manifest has no metadata.name", obj.Kind)
+	}
+	return nil
+}
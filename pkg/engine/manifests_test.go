@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+const rawRule = `apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  name: alerts
+spec:
+  groups:
+  - name: up
+    rules:
+    - alert: Down
+      annotations:
+        summary: "{{ $labels.instance }} is down"
+`
+
+func TestRawManifests(t *testing.T) {
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent", Version: "0.1.0", RawManifests: true},
+		Templates: []*common.File{
+			{Name: "templates/cm.yaml", Data: []byte("name: {{ .Chart.Name }}\n")},
+		},
+		Files: []*common.File{
+			{Name: "manifests/rule.yaml", Data: []byte(rawRule)},
+			{Name: "manifests/README.md", Data: []byte("{{ not yaml")},
+			{Name: "files/other.yaml", Data: []byte("other: true\n")},
+		},
+	}
+	parent.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub", Version: "0.1.0", RawManifests: true},
+		Files: []*common.File{
+			lazy("manifests/crds/crd.json", `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "crd"}}`),
+		},
+	})
+	parent.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "other", Version: "0.1.0"},
+		Files:    []*common.File{{Name: "manifests/skipped.yaml", Data: []byte("kind: ConfigMap\n")}},
+	})
+	parent.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "lib", Version: "0.1.0", Type: "library", RawManifests: true},
+		Files:    []*common.File{{Name: "manifests/lib.yaml", Data: []byte("kind: ConfigMap\n")}},
+	})
+
+	out, err := Render(parent, map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"parent/templates/cm.yaml":                  "name: parent\n",
+		"parent/manifests/rule.yaml":                rawRule,
+		"parent/charts/sub/manifests/crds/crd.json": `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "crd"}}`,
+	}, out)
+}
+
+func TestRawManifestsInvalid(t *testing.T) {
+	const cm = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n"
+	tests := []struct {
+		name, data, expect string
+	}{
+		{"invalid YAML", cm + "---\nkind: [\n", "broken: manifests/bad.yaml: invalid YAML"},
+		{"no apiVersion", "kind: ConfigMap\nmetadata:\n  name: cm\n", "broken: manifests/bad.yaml: manifest has no apiVersion"},
+		{"no kind", "apiVersion: v1\nmetadata:\n  name: cm\n", "broken: manifests/bad.yaml: manifest has no kind"},
+		{"no name", "apiVersion: v1\nkind: ConfigMap\n", "broken: manifests/bad.yaml: ConfigMap manifest has no metadata.name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &chart.Chart{
+				Metadata: &chart.Metadata{Name: "broken", Version: "0.1.0", RawManifests: true},
+				Files: []*common.File{
+					{Name: "manifests/ok.yaml", Data: []byte(cm + "---\n# only a comment\n")},
+					{Name: "manifests/bad.yaml", Data: []byte(tt.data)},
+				},
+			}
+			_, err := Render(c, map[string]any{})
+			assert.ErrorContains(t, err, tt.expect)
+		})
+	}
+}
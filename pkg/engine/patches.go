@@ -0,0 +1,305 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+)
+
+// PatchesDir is the directory of a chart holding patches for the rendered
+// output of its subcharts. Patches for a subchart live in a directory named
+// after it, for example 'patches/postgresql/'. Charts opt in with the
+// subchartPatches field of Chart.yaml.
+const PatchesDir = "patches"
+
+// docSeparator splits a rendered template into YAML documents.
+var docSeparator = regexp.MustCompile(`(?m)^---[ \t]*(?:#.*)?$`)
+
+// patchTarget selects the resource a patch applies to. Empty fields match
+// anything; Kind and Name are required.
+type patchTarget struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// subchartPatch is a single patch shipped by a parent chart. It is either a
+// strategic merge patch (a partial manifest) or a JSON 6902 patch with an
+// explicit target.
+type subchartPatch struct {
+	// source is the chart file the patch came from, used in error messages.
+	source string
+	target patchTarget
+	// strategic is the strategic merge patch, as JSON.
+	strategic []byte
+	// operations is the JSON 6902 patch, as JSON.
+	operations []byte
+}
+
+func (p subchartPatch) matches(obj map[string]any) bool {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	md, _ := obj["metadata"].(map[string]any)
+	name, _ := md["name"].(string)
+	namespace, _ := md["namespace"].(string)
+	gv, _ := schema.ParseGroupVersion(apiVersion)
+
+	t := p.target
+	return t.Kind == kind && t.Name == name &&
+		(t.Group == "" || t.Group == gv.Group) &&
+		(t.Version == "" || t.Version == gv.Version) &&
+		(t.Namespace == "" || t.Namespace == namespace)
+}
+
+// apply applies the patch to a resource given as JSON.
+func (p subchartPatch) apply(doc []byte, gvk schema.GroupVersionKind) ([]byte, error) {
+	if p.operations != nil {
+		ops, err := jsonpatch.DecodePatch(p.operations)
+		if err != nil {
+			return nil, err
+		}
+		return ops.Apply(doc)
+	}
+	// Strategic merge needs the Go type to know how lists are merged. Custom
+	// resources fall back to a JSON merge patch.
+	if obj, err := scheme.Scheme.New(gvk); err == nil {
+		return strategicpatch.StrategicMergePatch(doc, p.strategic, obj)
+	}
+	return jsonpatch.MergePatch(doc, p.strategic)
+}
+
+// loadSubchartPatches reads the patches a chart ships for the named subchart.
+func loadSubchartPatches(accessor ci.Accessor, subchart string) ([]subchartPatch, error) {
+	prefix := path.Join(PatchesDir, subchart) + "/"
+	var patches []subchartPatch
+	for _, f := range accessor.Files() {
+		if !strings.HasPrefix(f.Name, prefix) {
+			continue
+		}
+		switch path.Ext(f.Name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		for _, doc := range docSeparator.Split(string(f.Data), -1) {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			p, err := parseSubchartPatch(f.Name, []byte(doc))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", accessor.Name(), f.Name, err)
+			}
+			patches = append(patches, p)
+		}
+	}
+	return patches, nil
+}
+
+func parseSubchartPatch(source string, doc []byte) (subchartPatch, error) {
+	var raw map[string]json.RawMessage
+	if err := yaml.Unmarshal(doc, &raw); err != nil {
+		return subchartPatch{}, err
+	}
+	p := subchartPatch{source: source}
+
+	if _, ok := raw["target"]; ok {
+		if err := json.Unmarshal(raw["target"], &p.target); err != nil {
+			return p, fmt.Errorf("invalid patch target: %w", err)
+		}
+		if _, ok := raw["patch"]; !ok {
+			return p, fmt.Errorf("patch with a target must set 'patch' to a list of JSON 6902 operations")
+		}
+		p.operations = raw["patch"]
+	} else {
+		var obj struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		strategic, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return p, err
+		}
+		if err := json.Unmarshal(strategic, &obj); err != nil {
+			return p, err
+		}
+		gv, err := schema.ParseGroupVersion(obj.APIVersion)
+		if err != nil {
+			return p, err
+		}
+		p.strategic = strategic
+		p.target = patchTarget{
+			Group:     gv.Group,
+			Version:   gv.Version,
+			Kind:      obj.Kind,
+			Name:      obj.Metadata.Name,
+			Namespace: obj.Metadata.Namespace,
+		}
+	}
+	if p.target.Kind == "" || p.target.Name == "" {
+		return p, fmt.Errorf("patch must identify the resource it applies to by kind and name")
+	}
+	return p, nil
+}
+
+// applySubchartPatches is the post-processing stage of rendering. Every chart
+// in the tree that sets subchartPatches may patch the rendered output of its
+// direct subcharts, which includes the output of their own subcharts. Patches
+// of deeper charts are applied first so that a parent chart has the last word.
+//
+// A patch that matches no rendered resource is an error, so that patches do
+// not silently stop working when a subchart changes.
+func applySubchartPatches(c ci.Charter, rendered map[string]string) error {
+	accessor, err := ci.NewAccessor(c)
+	if err != nil {
+		return err
+	}
+	enabled, _ := accessor.MetadataAsMap()["SubchartPatches"].(bool)
+	for _, child := range accessor.Dependencies() {
+		if err := applySubchartPatches(child, rendered); err != nil {
+			return err
+		}
+		if !enabled {
+			continue
+		}
+		sub, err := ci.NewAccessor(child)
+		if err != nil {
+			return err
+		}
+		patches, err := loadSubchartPatches(accessor, sub.Name())
+		if err != nil {
+			return err
+		}
+		if len(patches) == 0 {
+			continue
+		}
+		if err := patchRendered(sub.ChartFullPath()+"/", patches, rendered); err != nil {
+			return fmt.Errorf("%s: %w", accessor.Name(), err)
+		}
+	}
+	return nil
+}
+
+// patchRendered applies patches to the rendered manifests whose template name
+// starts with prefix. Only the patched documents are re-encoded; the text of
+// the other documents is kept as it is.
+func patchRendered(prefix string, patches []subchartPatch, rendered map[string]string) error {
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	applied := make([]bool, len(patches))
+	for _, name := range names {
+		switch path.Ext(name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		docs := docSeparator.Split(rendered[name], -1)
+		seps := docSeparator.FindAllString(rendered[name], -1)
+		changed := false
+		for i, doc := range docs {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			var obj map[string]any
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || obj == nil {
+				// Invalid manifests are reported when the release is built.
+				continue
+			}
+			var (
+				data []byte
+				err  error
+			)
+			for j, p := range patches {
+				if !p.matches(obj) {
+					continue
+				}
+				if data == nil {
+					if data, err = json.Marshal(obj); err != nil {
+						return err
+					}
+				}
+				apiVersion, _ := obj["apiVersion"].(string)
+				gvk := schema.FromAPIVersionAndKind(apiVersion, p.target.Kind)
+				if data, err = p.apply(data, gvk); err != nil {
+					return fmt.Errorf("applying %s to %s: %w", p.source, name, err)
+				}
+				applied[j] = true
+			}
+			if data == nil {
+				continue
+			}
+			out, err := yaml.JSONToYAML(data)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				docs[i] = "\n" + string(out)
+			} else {
+				docs[i] = string(out)
+			}
+			changed = true
+		}
+		if changed {
+			rendered[name] = joinDocs(docs, seps)
+		}
+	}
+
+	for j, p := range patches {
+		if !applied[j] {
+			return fmt.Errorf("%s: no rendered %s named %q to patch", p.source, p.target.Kind, p.target.Name)
+		}
+	}
+	return nil
+}
+
+// joinDocs reassembles documents split by docSeparator with the separators
+// found between them.
+func joinDocs(docs, seps []string) string {
+	var b bytes.Buffer
+	for i, doc := range docs {
+		if i > 0 {
+			b.WriteString(seps[i-1])
+		}
+		b.WriteString(doc)
+	}
+	return b.String()
+}
@@ -0,0 +1,197 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+const patchedDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0
+      - name: sidecar
+        image: sidecar:1.0
+`
+
+const patchedWidget = `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+spec:
+  size: small
+  colors: [red]
+`
+
+// untouchedConfigMap is not matched by the patches, so its text is kept.
+const untouchedConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata: {name: other}
+data:
+  z: "1"
+  a: "2"
+`
+
+func umbrellaWithPatches(patches ...*common.File) *chart.Chart {
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "umbrella", Version: "0.1.0", SubchartPatches: true},
+		Files:    patches,
+	}
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web", Version: "0.1.0"},
+		Templates: []*common.File{
+			{Name: "templates/deployment.yaml", Data: []byte(patchedDeployment)},
+			{Name: "templates/widget.yaml", Data: []byte("# leading comment\n---\n" + patchedWidget + "--- # other\n" + untouchedConfigMap + "---\n" + patchedWidget)},
+			{Name: "templates/NOTES.txt", Data: []byte("kind: Deployment\nmetadata:\n  name: web\n")},
+		},
+	}
+	parent.AddDependency(sub)
+	return parent
+}
+
+func TestRenderSubchartPatches(t *testing.T) {
+	c := umbrellaWithPatches(
+		&common.File{Name: "patches/web/deployment.yaml", Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:2.0
+`)},
+		&common.File{Name: "patches/web/widget.yaml", Data: []byte(`target:
+  group: example.com
+  kind: Widget
+  name: gadget
+patch:
+- op: replace
+  path: /spec/size
+  value: large
+- op: add
+  path: /spec/colors/-
+  value: blue
+`)},
+	)
+
+	out, err := Render(c, common.Values{})
+	require.NoError(t, err)
+
+	// Strategic merge keeps the sidecar and only updates the matching container.
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: web:2.0
+        name: web
+      - image: sidecar:1.0
+        name: sidecar
+`, out["umbrella/charts/web/templates/deployment.yaml"])
+
+	patched := `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+spec:
+  colors:
+  - red
+  - blue
+  size: large
+`
+	assert.Equal(t, "# leading comment\n---\n"+patched+"--- # other\n"+untouchedConfigMap+"---\n"+patched, out["umbrella/charts/web/templates/widget.yaml"])
+	assert.Equal(t, "kind: Deployment\nmetadata:\n  name: web\n", out["umbrella/charts/web/templates/NOTES.txt"])
+}
+
+func TestRenderSubchartPatchesOptIn(t *testing.T) {
+	c := umbrellaWithPatches(&common.File{Name: "patches/web/p.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: missing\n")})
+	c.Metadata.SubchartPatches = false
+
+	out, err := Render(c, common.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, patchedDeployment, out["umbrella/charts/web/templates/deployment.yaml"])
+}
+
+func TestRenderSubchartPatchesCustomResourceMerge(t *testing.T) {
+	c := umbrellaWithPatches(&common.File{Name: "patches/web/widget.yaml", Data: []byte(`apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+spec:
+  colors: [green]
+`)})
+
+	out, err := Render(c, common.Values{})
+	require.NoError(t, err)
+	assert.Contains(t, out["umbrella/charts/web/templates/widget.yaml"], "colors:\n  - green\n  size: small\n")
+}
+
+func TestRenderSubchartPatchesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		err   string
+	}{
+		{
+			name:  "no match",
+			patch: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: missing\n",
+			err:   `patches/web/p.yaml: no rendered ConfigMap named "missing" to patch`,
+		},
+		{
+			name:  "no target",
+			patch: "spec:\n  replicas: 2\n",
+			err:   "patch must identify the resource it applies to by kind and name",
+		},
+		{
+			name:  "target without operations",
+			patch: "target:\n  kind: Deployment\n  name: web\n",
+			err:   "patch with a target must set 'patch'",
+		},
+		{
+			name:  "failing operation",
+			patch: "target:\n  kind: Deployment\n  name: web\npatch:\n- op: test\n  path: /spec/replicas\n  value: 3\n",
+			err:   "applying patches/web/p.yaml to umbrella/charts/web/templates/deployment.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := umbrellaWithPatches(&common.File{Name: "patches/web/p.yaml", Data: []byte(tt.patch)})
+			_, err := Render(c, common.Values{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
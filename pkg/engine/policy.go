@@ -0,0 +1,77 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"text/template"
+)
+
+// FuncPolicy restricts the template functions that charts may call. It is
+// meant for services that render untrusted charts, such as multi-tenant
+// rendering services.
+//
+// A function that the policy does not allow stays defined, so that templates
+// still parse, but calling it fails the rendering. The policy also applies to
+// the custom template functions of the Engine and to the functions of
+// templates rendered with 'tpl'.
+type FuncPolicy struct {
+	// Allow lists the only functions that charts may call. When empty, every
+	// function not in Deny may be called.
+	Allow []string
+	// Deny lists functions that charts may not call.
+	Deny []string
+}
+
+// UntrustedFuncPolicy returns a policy that denies the functions that reach
+// outside of the chart and its values: 'lookup', which reads resources from
+// the cluster, 'getHostByName', which resolves host names, and 'env' and
+// 'expandenv', which read the environment of the process should custom
+// template functions provide them.
+func UntrustedFuncPolicy() *FuncPolicy {
+	return &FuncPolicy{
+		Deny: []string{"env", "expandenv", "getHostByName", "lookup"},
+	}
+}
+
+// Allows reports whether the policy allows charts to call the function. A nil
+// policy allows every function.
+func (p *FuncPolicy) Allows(name string) bool {
+	if p == nil {
+		return true
+	}
+	if slices.Contains(p.Deny, name) {
+		return false
+	}
+	return len(p.Allow) == 0 || slices.Contains(p.Allow, name)
+}
+
+// apply replaces the functions of funcs that the policy does not allow.
+func (p *FuncPolicy) apply(funcs template.FuncMap) {
+	for name := range funcs {
+		if !p.Allows(name) {
+			funcs[name] = deniedFunc(name)
+		}
+	}
+}
+
+// deniedFunc returns a template function that fails when called.
+func deniedFunc(name string) func(...any) (any, error) {
+	return func(...any) (any, error) {
+		return nil, fmt.Errorf("template function %q is not allowed by the rendering policy", name)
+	}
+}
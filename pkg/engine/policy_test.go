@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"os"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestFuncPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		tpl     string
+		policy  *FuncPolicy
+		expect  string
+		wantErr string
+	}{
+		{
+			name:   "no policy",
+			tpl:    `{{ upper "moby" }}`,
+			expect: "MOBY",
+		},
+		{
+			name:   "allowed function",
+			tpl:    `{{ upper "moby" }}`,
+			policy: UntrustedFuncPolicy(),
+			expect: "MOBY",
+		},
+		{
+			name:    "denied function",
+			tpl:     `{{ lookup "v1" "Secret" "default" "token" }}`,
+			policy:  UntrustedFuncPolicy(),
+			wantErr: `template function "lookup" is not allowed by the rendering policy`,
+		},
+		{
+			name:    "denied custom function",
+			tpl:     `{{ env "HOME" }}`,
+			policy:  UntrustedFuncPolicy(),
+			wantErr: `template function "env" is not allowed by the rendering policy`,
+		},
+		{
+			name:    "function outside the allow list",
+			tpl:     `{{ lower "MOBY" }}`,
+			policy:  &FuncPolicy{Allow: []string{"upper"}},
+			wantErr: `template function "lower" is not allowed by the rendering policy`,
+		},
+		{
+			name:   "function in the allow list",
+			tpl:    `{{ upper "moby" }}`,
+			policy: &FuncPolicy{Allow: []string{"upper"}},
+			expect: "MOBY",
+		},
+		{
+			name:    "denied function in tpl",
+			tpl:     `{{ tpl "{{ getHostByName \"helm.sh\" }}" . }}`,
+			policy:  UntrustedFuncPolicy(),
+			wantErr: `template function "getHostByName" is not allowed by the rendering policy`,
+		},
+		{
+			name:    "denied include in tpl",
+			tpl:     `{{ define "moby.name" }}moby{{ end }}{{ tpl "{{ include \"moby.name\" . }}" . }}`,
+			policy:  &FuncPolicy{Deny: []string{"include"}},
+			wantErr: `template function "include" is not allowed by the rendering policy`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &chart.Chart{
+				Metadata:  &chart.Metadata{Name: "moby", Version: "1.2.3"},
+				Templates: []*common.File{{Name: "templates/test", Data: []byte(tt.tpl)}},
+			}
+			e := Engine{
+				CustomTemplateFuncs: template.FuncMap{"env": os.Getenv},
+				FuncPolicy:          tt.policy,
+			}
+			out, err := e.Render(c, common.Values{})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, out["moby/templates/test"])
+		})
+	}
+}
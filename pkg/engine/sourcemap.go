@@ -0,0 +1,195 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// Source map markers are private use characters that bracket a template
+// location in the instrumented output.
+const (
+	markerStart = "\uE000"
+	markerEnd   = "\uE001"
+)
+
+// SourceLocation is a line in a template file.
+type SourceLocation struct {
+	Template string `json:"template"`
+	Line     int    `json:"line"`
+}
+
+// SourceMap maps the lines of rendered files to the template lines that
+// produced them. The entry at index i of a file is the location of line i+1
+// of its rendered output.
+type SourceMap map[string][]SourceLocation
+
+// Lookup returns the template location of a 1-based line of a rendered file.
+func (m SourceMap) Lookup(file string, line int) (SourceLocation, bool) {
+	locs := m[file]
+	if line < 1 || line > len(locs) || locs[line-1].Template == "" {
+		return SourceLocation{}, false
+	}
+	return locs[line-1], true
+}
+
+// RenderSourceMap renders a chart like RenderWithContext and returns a source
+// map of the output.
+//
+// Lines written by template actions are attributed to the line of the action,
+// or to the template text of an included template. The map is built from a
+// separate, instrumented render; functions that transform included template
+// output (for example fromYaml or sha256sum) see the instrumentation, so their
+// lines may be attributed less precisely. Subchart patches are not applied.
+func (e Engine) RenderSourceMap(ctx context.Context, chrt ci.Charter, values common.Values) (SourceMap, error) {
+	e.sourceMap = true
+	imports, err := templateImports(chrt)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := e.render(ctx, allTemplates(chrt, values), imports)
+	if err != nil {
+		return nil, err
+	}
+	m := make(SourceMap, len(rendered))
+	for name, out := range rendered {
+		_, m[name] = stripMarkers(out)
+	}
+	return m, nil
+}
+
+// instrumentTemplates inserts a location marker at the start of every text
+// node and after every newline within it.
+func instrumentTemplates(t *template.Template) {
+	for _, tpl := range t.Templates() {
+		if tpl.Tree == nil || tpl.Root == nil {
+			continue
+		}
+		instrumentNode(tpl.Tree, tpl.Root)
+	}
+}
+
+func instrumentNode(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			instrumentNode(tree, c)
+		}
+	case *parse.IfNode:
+		instrumentNode(tree, n.List)
+		instrumentNode(tree, n.ElseList)
+	case *parse.RangeNode:
+		instrumentNode(tree, n.List)
+		instrumentNode(tree, n.ElseList)
+	case *parse.WithNode:
+		instrumentNode(tree, n.List)
+		instrumentNode(tree, n.ElseList)
+	case *parse.TextNode:
+		location, _ := tree.ErrorContext(n)
+		name, line, ok := splitLocation(location)
+		if !ok {
+			return
+		}
+		var b bytes.Buffer
+		writeMarker(&b, name, line)
+		for _, c := range n.Text {
+			b.WriteByte(c)
+			if c == '\n' {
+				line++
+				writeMarker(&b, name, line)
+			}
+		}
+		n.Text = b.Bytes()
+	}
+}
+
+func writeMarker(b *bytes.Buffer, name string, line int) {
+	b.WriteString(markerStart)
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(line))
+	b.WriteString(markerEnd)
+}
+
+// splitLocation splits a "name:line:col" location as returned by
+// parse.Tree.ErrorContext.
+func splitLocation(location string) (string, int, bool) {
+	i := strings.LastIndexByte(location, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	location = location[:i]
+	i = strings.LastIndexByte(location, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(location[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return location[:i], line, true
+}
+
+// stripMarkers removes the location markers from instrumented output and
+// returns the location of every output line. A line takes the location of
+// its first marker, or the location in effect at its start if it has none.
+func stripMarkers(s string) (string, []SourceLocation) {
+	lines := strings.Split(s, "\n")
+	locs := make([]SourceLocation, len(lines))
+	var current SourceLocation
+	for i, line := range lines {
+		var clean strings.Builder
+		first := true
+		for {
+			start := strings.Index(line, markerStart)
+			if start < 0 {
+				break
+			}
+			end := strings.Index(line[start:], markerEnd)
+			if end < 0 {
+				break
+			}
+			end += start
+			if name, n, ok := splitLocation(line[start+len(markerStart):end] + ":0"); ok {
+				if first {
+					locs[i] = SourceLocation{Template: name, Line: n}
+					first = false
+				}
+				current = SourceLocation{Template: name, Line: n}
+			}
+			clean.WriteString(line[:start])
+			line = line[end+len(markerEnd):]
+		}
+		clean.WriteString(line)
+		if first {
+			locs[i] = current
+		}
+		lines[i] = clean.String()
+	}
+	return strings.Join(lines, "\n"), locs
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestRenderSourceMap(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{/* labels */}}
+{{- define "moby.labels" -}}
+app: moby
+tier: {{ .Values.tier }}
+{{- end }}
+`)},
+			{Name: "templates/cm.yaml", Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    {{- include "moby.labels" . | nindent 4 }}
+data:
+{{- range $k, $v := .Values.data }}
+  {{ $k }}: {{ $v | quote }}
+{{- end }}
+`)},
+		},
+	}
+	vals := common.Values{"Values": map[string]any{
+		"tier": "web",
+		"data": map[string]any{"a": "1", "b": "2"},
+	}}
+
+	rendered, err := Render(c, vals)
+	require.NoError(t, err)
+	sm, err := new(Engine).RenderSourceMap(t.Context(), c, vals)
+	require.NoError(t, err)
+
+	const cm = "moby/templates/cm.yaml"
+	const helpers = "moby/templates/_helpers.tpl"
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  labels:\n    app: moby\n    tier: web\ndata:\n  a: \"1\"\n  b: \"2\"\n", rendered[cm])
+	assert.Len(t, sm[cm], 10)
+
+	expected := []SourceLocation{
+		{cm, 1}, {cm, 2}, {cm, 3}, {cm, 4},
+		{helpers, 3}, {helpers, 4},
+		{cm, 6},
+		{cm, 8}, {cm, 8},
+	}
+	for i, want := range expected {
+		got, ok := sm.Lookup(cm, i+1)
+		require.True(t, ok, "line %d", i+1)
+		assert.Equal(t, want, got, "line %d", i+1)
+	}
+
+	_, ok := sm.Lookup(cm, 0)
+	assert.False(t, ok)
+	_, ok = sm.Lookup("moby/templates/missing.yaml", 1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export // import "helm.sh/helm/v4/pkg/export"
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Defaults of the Argo CD Application.
+const (
+	DefaultArgoCDNamespace = "argocd"
+	DefaultArgoCDProject   = "default"
+	DefaultArgoCDServer    = "https://kubernetes.default.svc"
+)
+
+// ArgoCDOptions are the options of the Argo CD Application.
+type ArgoCDOptions struct {
+	// Namespace is the namespace of the Application, where Argo CD runs.
+	// DefaultArgoCDNamespace is used when it is empty.
+	Namespace string
+	// Project is the Argo CD project of the Application.
+	// DefaultArgoCDProject is used when it is empty.
+	Project string
+	// Server is the URL of the Kubernetes API server to deploy to.
+	// DefaultArgoCDServer, the cluster of Argo CD, is used when it is empty.
+	Server string
+}
+
+type argoCDApplicationSpec struct {
+	Project     string            `json:"project"`
+	Source      argoCDSource      `json:"source"`
+	Destination argoCDDestination `json:"destination"`
+	SyncPolicy  *argoCDSyncPolicy `json:"syncPolicy,omitempty"`
+}
+
+type argoCDSource struct {
+	RepoURL        string     `json:"repoURL"`
+	Chart          string     `json:"chart"`
+	TargetRevision string     `json:"targetRevision"`
+	Helm           argoCDHelm `json:"helm"`
+}
+
+type argoCDHelm struct {
+	ReleaseName  string         `json:"releaseName"`
+	SkipCrds     bool           `json:"skipCrds,omitempty"`
+	ValuesObject map[string]any `json:"valuesObject,omitempty"`
+}
+
+type argoCDDestination struct {
+	Server    string `json:"server"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type argoCDSyncPolicy struct {
+	SyncOptions []string `json:"syncOptions,omitempty"`
+}
+
+// ArgoCD returns the manifest of an Argo CD Application equivalent to the
+// release. Argo CD has no equivalent of DisableHooks and Timeout: an error is
+// returned when DisableHooks is set, and Timeout is ignored.
+func ArgoCD(r Release, opts ArgoCDOptions) ([]byte, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	if r.DisableHooks {
+		return nil, fmt.Errorf("cannot disable the hooks of release %q with Argo CD", r.Name)
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = DefaultArgoCDNamespace
+	}
+	project := opts.Project
+	if project == "" {
+		project = DefaultArgoCDProject
+	}
+	server := opts.Server
+	if server == "" {
+		server = DefaultArgoCDServer
+	}
+	version := r.Version
+	if version == "" {
+		version = "*"
+	}
+
+	spec := argoCDApplicationSpec{
+		Project: project,
+		Source: argoCDSource{
+			// Argo CD expects the OCI registry paths without scheme.
+			RepoURL:        strings.TrimPrefix(r.RepoURL, "oci://"),
+			Chart:          r.Chart,
+			TargetRevision: version,
+			Helm: argoCDHelm{
+				ReleaseName:  r.Name,
+				SkipCrds:     r.SkipCRDs,
+				ValuesObject: r.Values,
+			},
+		},
+		Destination: argoCDDestination{Server: server, Namespace: r.Namespace},
+	}
+	if r.CreateNamespace {
+		spec.SyncPolicy = &argoCDSyncPolicy{SyncOptions: []string{"CreateNamespace=true"}}
+	}
+
+	return encode(object{
+		APIVersion: "argoproj.io/v1alpha1",
+		Kind:       "Application",
+		Metadata:   metadata{Name: r.Name, Namespace: namespace},
+		Spec:       spec,
+	})
+}
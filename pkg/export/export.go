@@ -0,0 +1,129 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export converts the options of a Helm install into the manifests of
+// GitOps tools, such as a Flux HelmRelease or an Argo CD Application, so that
+// the release can be managed by these tools instead.
+package export // import "helm.sh/helm/v4/pkg/export"
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+// Release describes a release of a chart of a repository.
+type Release struct {
+	// Name is the name of the release.
+	Name string
+	// Namespace is the namespace of the release.
+	Namespace string
+	// Chart is the name of the chart in its repository.
+	Chart string
+	// RepoURL is the URL of the chart repository, or the OCI registry path
+	// holding the chart, such as oci://example.com/charts.
+	RepoURL string
+	// Version is the version, or version constraint, of the chart. The
+	// latest version is used when it is empty.
+	Version string
+	// Values are the values of the release.
+	Values map[string]any
+	// CreateNamespace creates the namespace of the release if needed.
+	CreateNamespace bool
+	// SkipCRDs skips the installation of the CRDs of the chart.
+	SkipCRDs bool
+	// DisableHooks disables the hooks of the chart.
+	DisableHooks bool
+	// Timeout is the time to wait for the Kubernetes operations. Zero means
+	// the default of the tool.
+	Timeout time.Duration
+}
+
+// ResolveChart returns the repository and the name of a chart reference, as
+// given to helm install: a chart name together with the URL of its
+// repository, a chart of a repository of repos such as stable/mychart, or an
+// OCI reference such as oci://example.com/charts/mychart. The version is
+// returned when the OCI reference has a tag.
+func ResolveChart(ref, repoURL string, repos *repo.File) (url, chart, version string, err error) {
+	switch {
+	case repoURL != "":
+		return repoURL, ref, "", nil
+	case registry.IsOCI(ref):
+		i := strings.LastIndex(ref, "/")
+		url, chart = ref[:i], ref[i+1:]
+		if name, tag, ok := strings.Cut(chart, ":"); ok {
+			chart, version = name, tag
+		}
+		if chart == "" || url == "oci:/" {
+			return "", "", "", fmt.Errorf("invalid OCI chart reference %q", ref)
+		}
+		return url, chart, version, nil
+	}
+	name, chart, ok := strings.Cut(ref, "/")
+	if !ok || name == "" || name == "." || name == ".." || chart == "" || strings.Contains(chart, "/") {
+		return "", "", "", fmt.Errorf("chart %q is not a chart of a repository, such as repo/chart or oci://example.com/charts/chart", ref)
+	}
+	if repos != nil {
+		if e := repos.Get(name); e != nil {
+			return e.URL, chart, "", nil
+		}
+	}
+	return "", "", "", fmt.Errorf("no repository named %q, add it with 'helm repo add' or use a repository URL", name)
+}
+
+// object is a Kubernetes object in the manifests.
+type object struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   metadata `json:"metadata"`
+	Spec       any      `json:"spec"`
+}
+
+type metadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (r Release) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("the release has no name")
+	}
+	if r.Chart == "" || r.RepoURL == "" {
+		return fmt.Errorf("release %q has no chart repository", r.Name)
+	}
+	return nil
+}
+
+// encode returns the YAML documents of the objects.
+func encode(objects ...object) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, o := range objects {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		b, err := yaml.Marshal(o)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+func TestResolveChart(t *testing.T) {
+	repos := repo.NewFile()
+	repos.Add(&repo.Entry{Name: "stable", URL: "https://charts.example.com/stable"})
+
+	tests := []struct {
+		name        string
+		ref         string
+		repoURL     string
+		wantURL     string
+		wantChart   string
+		wantVersion string
+		wantErr     string
+	}{
+		{name: "repository URL", ref: "nginx", repoURL: "https://example.com", wantURL: "https://example.com", wantChart: "nginx"},
+		{name: "repository name", ref: "stable/nginx", wantURL: "https://charts.example.com/stable", wantChart: "nginx"},
+		{name: "OCI", ref: "oci://example.com/charts/nginx", wantURL: "oci://example.com/charts", wantChart: "nginx"},
+		{name: "OCI with tag", ref: "oci://example.com/charts/nginx:1.2.3", wantURL: "oci://example.com/charts", wantChart: "nginx", wantVersion: "1.2.3"},
+		{name: "unknown repository", ref: "unknown/nginx", wantErr: `no repository named "unknown"`},
+		{name: "local path", ref: "./nginx", wantErr: "is not a chart of a repository"},
+		{name: "chart name", ref: "nginx", wantErr: "is not a chart of a repository"},
+		{name: "invalid OCI", ref: "oci://nginx", wantErr: "invalid OCI chart reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, chart, version, err := ResolveChart(tt.ref, tt.repoURL, repos)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantURL, url)
+			assert.Equal(t, tt.wantChart, chart)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
+
+func TestFlux(t *testing.T) {
+	rel := Release{
+		Name:            "web",
+		Namespace:       "apps",
+		Chart:           "nginx",
+		RepoURL:         "oci://example.com/charts",
+		Version:         "1.2.3",
+		Values:          map[string]any{"replicas": 2},
+		CreateNamespace: true,
+		SkipCRDs:        true,
+		Timeout:         5 * time.Minute,
+	}
+	b, err := Flux(rel, FluxOptions{Interval: time.Hour, SourceName: "charts"})
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: source.toolkit.fluxcd.io/v1
+kind: HelmRepository
+metadata:
+  name: charts
+  namespace: apps
+spec:
+  interval: 1h0m0s
+  type: oci
+  url: oci://example.com/charts
+---
+apiVersion: helm.toolkit.fluxcd.io/v2
+kind: HelmRelease
+metadata:
+  name: web
+  namespace: apps
+spec:
+  chart:
+    spec:
+      chart: nginx
+      sourceRef:
+        kind: HelmRepository
+        name: charts
+      version: 1.2.3
+  install:
+    crds: Skip
+    createNamespace: true
+  interval: 1h0m0s
+  timeout: 5m0s
+  values:
+    replicas: 2
+`, string(b))
+
+	_, err = Flux(Release{Name: "web"}, FluxOptions{})
+	assert.ErrorContains(t, err, "has no chart repository")
+}
+
+func TestArgoCD(t *testing.T) {
+	rel := Release{
+		Name:      "web",
+		Namespace: "apps",
+		Chart:     "nginx",
+		RepoURL:   "oci://example.com/charts",
+		Values:    map[string]any{"replicas": 2},
+		SkipCRDs:  true,
+	}
+	b, err := ArgoCD(rel, ArgoCDOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: web
+  namespace: argocd
+spec:
+  destination:
+    namespace: apps
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    chart: nginx
+    helm:
+      releaseName: web
+      skipCrds: true
+      valuesObject:
+        replicas: 2
+    repoURL: example.com/charts
+    targetRevision: '*'
+`, string(b))
+
+	rel.DisableHooks = true
+	_, err = ArgoCD(rel, ArgoCDOptions{})
+	assert.ErrorContains(t, err, "cannot disable the hooks")
+}
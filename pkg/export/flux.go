@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export // import "helm.sh/helm/v4/pkg/export"
+
+import (
+	"time"
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// DefaultFluxInterval is the default interval at which Flux reconciles the
+// release.
+const DefaultFluxInterval = 10 * time.Minute
+
+// FluxOptions are the options of the Flux objects.
+type FluxOptions struct {
+	// Interval is the interval at which Flux reconciles the release and
+	// fetches the repository. DefaultFluxInterval is used when it is zero.
+	Interval time.Duration
+	// SourceName is the name of the HelmRepository source. The name of the
+	// release is used when it is empty.
+	SourceName string
+}
+
+type fluxHelmRepositorySpec struct {
+	Interval string `json:"interval"`
+	URL      string `json:"url"`
+	Type     string `json:"type,omitempty"`
+}
+
+type fluxHelmReleaseSpec struct {
+	Interval string         `json:"interval"`
+	Chart    fluxChart      `json:"chart"`
+	Timeout  string         `json:"timeout,omitempty"`
+	Install  *fluxInstall   `json:"install,omitempty"`
+	Upgrade  *fluxUpgrade   `json:"upgrade,omitempty"`
+	Values   map[string]any `json:"values,omitempty"`
+}
+
+type fluxChart struct {
+	Spec fluxChartSpec `json:"spec"`
+}
+
+type fluxChartSpec struct {
+	Chart     string        `json:"chart"`
+	Version   string        `json:"version,omitempty"`
+	SourceRef fluxSourceRef `json:"sourceRef"`
+}
+
+type fluxSourceRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type fluxInstall struct {
+	CreateNamespace bool   `json:"createNamespace,omitempty"`
+	CRDs            string `json:"crds,omitempty"`
+	DisableHooks    bool   `json:"disableHooks,omitempty"`
+}
+
+type fluxUpgrade struct {
+	DisableHooks bool `json:"disableHooks,omitempty"`
+}
+
+// Flux returns the manifests of a Flux HelmRelease equivalent to the release,
+// together with the HelmRepository source of its chart.
+func Flux(r Release, opts FluxOptions) ([]byte, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	interval := opts.Interval
+	if interval == 0 {
+		interval = DefaultFluxInterval
+	}
+	source := opts.SourceName
+	if source == "" {
+		source = r.Name
+	}
+
+	repository := fluxHelmRepositorySpec{Interval: interval.String(), URL: r.RepoURL}
+	if registry.IsOCI(r.RepoURL) {
+		repository.Type = "oci"
+	}
+
+	spec := fluxHelmReleaseSpec{
+		Interval: interval.String(),
+		Chart: fluxChart{Spec: fluxChartSpec{
+			Chart:     r.Chart,
+			Version:   r.Version,
+			SourceRef: fluxSourceRef{Kind: "HelmRepository", Name: source},
+		}},
+		Values: r.Values,
+	}
+	if r.Timeout > 0 {
+		spec.Timeout = r.Timeout.String()
+	}
+	if r.CreateNamespace || r.SkipCRDs || r.DisableHooks {
+		spec.Install = &fluxInstall{CreateNamespace: r.CreateNamespace, DisableHooks: r.DisableHooks}
+		if r.SkipCRDs {
+			spec.Install.CRDs = "Skip"
+		}
+	}
+	if r.DisableHooks {
+		spec.Upgrade = &fluxUpgrade{DisableHooks: true}
+	}
+
+	return encode(
+		object{
+			APIVersion: "source.toolkit.fluxcd.io/v1",
+			Kind:       "HelmRepository",
+			Metadata:   metadata{Name: source, Namespace: r.Namespace},
+			Spec:       repository,
+		},
+		object{
+			APIVersion: "helm.toolkit.fluxcd.io/v2",
+			Kind:       "HelmRelease",
+			Metadata:   metadata{Name: r.Name, Namespace: r.Namespace},
+			Spec:       spec,
+		},
+	)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake implements a getter serving content from memory, so that the
+// actions downloading charts can be unit tested without a chart repository.
+package fake
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sync"
+
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+// Getter implements getter.Getter, serving the content added with Serve by
+// URL. It is safe for concurrent use.
+type Getter struct {
+	// Err, when set, is returned by all the gets.
+	Err error
+
+	mu        sync.Mutex
+	content   map[string][]byte
+	requested []string
+}
+
+var _ getter.Getter = &Getter{}
+
+// Serve makes the getter serve content at a URL.
+func (g *Getter) Serve(url string, content []byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.content == nil {
+		g.content = map[string][]byte{}
+	}
+	g.content[url] = content
+}
+
+// Get returns the content served at the URL. The options are ignored.
+func (g *Getter) Get(url string, _ ...getter.Option) (*bytes.Buffer, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.requested = append(g.requested, url)
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	content, ok := g.content[url]
+	if !ok {
+		return nil, fmt.Errorf("failed to fetch %s : 404 Not Found", url)
+	}
+	return bytes.NewBuffer(slices.Clone(content)), nil
+}
+
+// Requested returns the URLs requested from the getter, in order.
+func (g *Getter) Requested() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.requested)
+}
+
+// Providers returns providers of the getter for the given schemes, or for
+// "http" and "https" if none is given. They can be set as the getters of
+// action.ChartPathOptions or of a downloader.ChartDownloader.
+func (g *Getter) Providers(schemes ...string) getter.Providers {
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	return getter.Providers{{
+		Schemes: schemes,
+		New: func(...getter.Option) (getter.Getter, error) {
+			return g, nil
+		},
+	}}
+}
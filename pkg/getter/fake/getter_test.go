@@ -0,0 +1,48 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetter(t *testing.T) {
+	g := &Getter{}
+	g.Serve("https://example.com/index.yaml", []byte("apiVersion: v1"))
+
+	providers := g.Providers()
+	https, err := providers.ByScheme("https")
+	require.NoError(t, err)
+	content, err := https.Get("https://example.com/index.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1", content.String())
+
+	_, err = https.Get("https://example.com/missing.tgz")
+	assert.ErrorContains(t, err, "404 Not Found")
+	assert.Equal(t, []string{"https://example.com/index.yaml", "https://example.com/missing.tgz"}, g.Requested())
+
+	_, err = providers.ByScheme("oci")
+	assert.Error(t, err)
+
+	g.Err = errors.New("offline")
+	_, err = https.Get("https://example.com/index.yaml")
+	assert.EqualError(t, err, "offline")
+}
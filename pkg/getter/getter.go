@@ -18,9 +18,12 @@ package getter
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 	"time"
 
 	"helm.sh/helm/v4/pkg/cli"
@@ -49,6 +52,10 @@ type getterOptions struct {
 	timeout               time.Duration
 	transport             *http.Transport
 	artifactType          string
+	proxy                 string
+	digest                string
+	resumeAttempts        int
+	connections           int
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -107,6 +114,62 @@ func WithTLSClientConfig(certFile, keyFile, caFile string) Option {
 	}
 }
 
+// WithProxy sets the URL of the HTTP proxy used for requests, overriding the
+// proxy configured through the environment. An empty value keeps the
+// environment based configuration.
+func WithProxy(proxy string) Option {
+	return func(opts *getterOptions) {
+		opts.proxy = proxy
+	}
+}
+
+// WithDigest sets the expected digest of the downloaded content, in the form
+// "sha256:<hex>" or a bare hex encoded SHA-256 sum. The getter returns an error
+// when the content does not match.
+func WithDigest(digest string) Option {
+	return func(opts *getterOptions) {
+		opts.digest = digest
+	}
+}
+
+// VerifyDigest checks data against a SHA-256 digest, in the form
+// "sha256:<hex>" or a bare hex encoded sum. An empty digest is always
+// satisfied.
+func VerifyDigest(data []byte, digest string) error {
+	if digest == "" {
+		return nil
+	}
+	algo, expected, ok := strings.Cut(digest, ":")
+	if !ok {
+		algo, expected = "sha256", digest
+	}
+	if algo != "sha256" {
+		return fmt.Errorf("unsupported digest algorithm %q", algo)
+	}
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("digest mismatch: expected sha256:%s, got sha256:%s", expected, actual)
+	}
+	return nil
+}
+
+// WithResumeAttempts sets how many times an interrupted download is resumed
+// using HTTP range requests. A negative value disables resuming.
+func WithResumeAttempts(attempts int) Option {
+	return func(opts *getterOptions) {
+		opts.resumeAttempts = attempts
+	}
+}
+
+// WithParallelConnections sets the number of connections used to fetch large
+// files in chunks when the server supports range requests. Values lower than
+// two disable chunked downloads.
+func WithParallelConnections(connections int) Option {
+	return func(opts *getterOptions) {
+		opts.connections = connections
+	}
+}
+
 func WithPlainHTTP(plainHTTP bool) Option {
 	return func(opts *getterOptions) {
 		opts.plainHTTP = plainHTTP
@@ -145,7 +208,7 @@ func WithTransport(transport *http.Transport) Option {
 	}
 }
 
-// WithArtifactType sets the type of OCI artifact ("chart" or "plugin")
+// WithArtifactType sets the type of OCI artifact ("chart", "plugin", "values" or "keys")
 func WithArtifactType(artifactType string) Option {
 	return func(opts *getterOptions) {
 		opts.artifactType = artifactType
@@ -218,15 +281,59 @@ func Getters(extraOpts ...Option) Providers {
 				return NewOCIGetter(options...)
 			},
 		},
+		Provider{
+			Schemes: gitSchemes,
+			New: func(options ...Option) (Getter, error) {
+				options = append(options, defaultOptions...)
+				options = append(options, extraOpts...)
+				return NewGitGetter(options...)
+			},
+		},
 	}
 }
 
 // All finds all of the registered getters as a list of Provider instances.
 // Currently, the built-in getters and the discovered plugins with downloader
 // notations are collected.
+//
+// The download settings are applied to the getters before the given options.
+// In offline mode, every getter fails with an error wrapping ErrOffline.
 func All(settings *cli.EnvSettings, opts ...Option) Providers {
+	opts = append([]Option{
+		WithParallelConnections(settings.DownloadConnections),
+		WithResumeAttempts(settings.DownloadResumeAttempts),
+	}, opts...)
 	result := Getters(opts...)
 	pluginDownloaders, _ := collectGetterPlugins(settings)
 	result = append(result, pluginDownloaders...)
+	if settings.Offline {
+		return offlineProviders(result)
+	}
 	return result
 }
+
+// ErrOffline indicates that content had to be downloaded while Helm is
+// running in offline mode.
+var ErrOffline = registry.ErrOffline
+
+// offlineGetter is a Getter failing every request with ErrOffline.
+type offlineGetter struct{}
+
+// Get implements Getter.
+func (offlineGetter) Get(href string, _ ...Option) (*bytes.Buffer, error) {
+	return nil, fmt.Errorf("cannot download %s: %w", href, ErrOffline)
+}
+
+// offlineProviders replaces the getters of providers with offline getters.
+func offlineProviders(providers Providers) Providers {
+	offline := make(Providers, len(providers))
+	for i, p := range providers {
+		offline[i] = Provider{
+			Schemes: p.Schemes,
+			New: func(_ ...Option) (Getter, error) {
+				return offlineGetter{}, nil
+			},
+		}
+	}
+	return offline
+}
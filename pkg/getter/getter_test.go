@@ -16,6 +16,7 @@ limitations under the License.
 package getter
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -76,8 +77,8 @@ func TestAll(t *testing.T) {
 	env.PluginsDirectory = pluginDir
 
 	all := All(env)
-	if len(all) != 4 {
-		t.Errorf("expected 4 providers (default plus three plugins), got %d", len(all))
+	if len(all) != 5 {
+		t.Errorf("expected 5 providers (built-in getters plus plugins), got %d", len(all))
 	}
 
 	if _, err := all.ByScheme("test2"); err != nil {
@@ -97,3 +98,19 @@ func TestByScheme(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestAllOffline(t *testing.T) {
+	env := cli.New()
+	env.PluginsDirectory = pluginDir
+	env.Offline = true
+
+	for _, scheme := range []string{"https", "oci", "test2"} {
+		g, err := All(env).ByScheme(scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.Get(scheme + "://example.com/chart.tgz"); !errors.Is(err, ErrOffline) {
+			t.Errorf("expected %s getter to fail with ErrOffline, got %v", scheme, err)
+		}
+	}
+}
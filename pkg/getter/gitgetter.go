@@ -0,0 +1,178 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// gitSchemes are the URL schemes handled by the GitGetter.
+var gitSchemes = []string{"git+https", "git+http", "git+ssh", "git+file"}
+
+// gitSHAPattern matches abbreviated and full commit SHAs.
+var gitSHAPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+// GitGetter fetches single files from git repositories using the git
+// command line client.
+//
+// References have the form
+//
+//	git+https://example.com/org/repo.git//path/to/file.yaml?ref=v1.0.0
+//
+// where the part after '//' is the path of the file in the repository and
+// ref is a branch, tag or commit. Without a ref the default branch is used.
+type GitGetter struct {
+	opts getterOptions
+}
+
+// Get fetches the file referenced by href.
+func (g *GitGetter) Get(href string, options ...Option) (*bytes.Buffer, error) {
+	opts := g.opts
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	repo, file, ref, err := parseGitRef(href)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	if err := checkGitRef(ctx, ref); err != nil {
+		return nil, fmt.Errorf("invalid git reference %q: %w", href, err)
+	}
+
+	dir, err := os.MkdirTemp("", "helm-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := runGit(ctx, dir, "init", "--quiet"); err != nil {
+		return nil, err
+	}
+	if _, err := runGit(ctx, dir, "fetch", "--quiet", "--depth", "1", "--", repo, ref); err != nil {
+		return nil, err
+	}
+	data, err := runGit(ctx, dir, "show", "FETCH_HEAD:"+file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifyDigest(data, opts.digest); err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w", href, err)
+	}
+	return bytes.NewBuffer(data), nil
+}
+
+// parseGitRef splits a git reference into the repository URL, the path of
+// the file in the repository and the ref to fetch.
+func parseGitRef(href string) (repo, file, ref string, err error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", "", "", err
+	}
+	scheme, ok := strings.CutPrefix(u.Scheme, "git+")
+	if !ok {
+		return "", "", "", fmt.Errorf("invalid git reference %q: scheme must start with git+", href)
+	}
+
+	repoPath, file, ok := strings.Cut(u.Path, "//")
+	if !ok || file == "" {
+		return "", "", "", fmt.Errorf("invalid git reference %q: missing '//' followed by the path of the file", href)
+	}
+
+	if strings.HasPrefix(file, "-") || path.IsAbs(file) || slices.Contains(strings.Split(file, "/"), "..") {
+		return "", "", "", fmt.Errorf("invalid git reference %q: file path must be relative to the repository", href)
+	}
+
+	ref = u.Query().Get("ref")
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	r := *u
+	r.Scheme = scheme
+	r.Path = repoPath
+	r.RawPath = ""
+	r.RawQuery = ""
+	r.Fragment = ""
+	return r.String(), file, ref, nil
+}
+
+// checkGitRef checks that ref is a commit SHA or a well-formed ref name, so
+// that it cannot be mistaken for an option by git.
+func checkGitRef(ctx context.Context, ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("ref %q must not start with '-'", ref)
+	}
+	if gitSHAPattern.MatchString(ref) {
+		return nil
+	}
+	if _, err := runGit(ctx, "", "check-ref-format", "--allow-onelevel", ref); err != nil {
+		return fmt.Errorf("ref %q is not a valid ref name", ref)
+	}
+	return nil
+}
+
+// runGit runs a git command in dir and returns its standard output.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// never prompt for credentials, fail instead
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("git is required to fetch git references: %w", err)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// NewGitGetter constructs a Getter for git references.
+func NewGitGetter(options ...Option) (Getter, error) {
+	var client GitGetter
+
+	for _, opt := range options {
+		opt(&client.opts)
+	}
+
+	return &client, nil
+}
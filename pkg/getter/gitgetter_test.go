@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitRef(t *testing.T) {
+	tests := []struct {
+		href    string
+		repo    string
+		file    string
+		ref     string
+		wantErr bool
+	}{
+		{
+			href: "git+https://example.com/org/values.git//prod/values.yaml?ref=v1.0.0",
+			repo: "https://example.com/org/values.git",
+			file: "prod/values.yaml",
+			ref:  "v1.0.0",
+		},
+		{
+			href: "git+ssh://git@example.com/org/values.git//values.yaml",
+			repo: "ssh://git@example.com/org/values.git",
+			file: "values.yaml",
+			ref:  "HEAD",
+		},
+		{href: "git+https://example.com/org/values.git", wantErr: true},
+		{href: "https://example.com/org/values.git//values.yaml", wantErr: true},
+		{href: "git+https://example.com/org/values.git//-values.yaml", wantErr: true},
+		{href: "git+https://example.com/org/values.git///etc/passwd", wantErr: true},
+		{href: "git+https://example.com/org/values.git//prod/../../values.yaml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.href, func(t *testing.T) {
+			repo, file, ref, err := parseGitRef(tt.href)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.repo, repo)
+			assert.Equal(t, tt.file, file)
+			assert.Equal(t, tt.ref, ref)
+		})
+	}
+}
+
+func TestCheckGitRef(t *testing.T) {
+	assert.NoError(t, checkGitRef(context.Background(), "0123456789abcdef0123456789abcdef01234567"))
+	assert.Error(t, checkGitRef(context.Background(), "--upload-pack=touch /tmp/pwned"))
+	assert.Error(t, checkGitRef(context.Background(), "-q"))
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	assert.NoError(t, checkGitRef(context.Background(), "HEAD"))
+	assert.NoError(t, checkGitRef(context.Background(), "v1.0.0"))
+	assert.NoError(t, checkGitRef(context.Background(), "refs/heads/main"))
+	assert.Error(t, checkGitRef(context.Background(), "main..other"))
+}
+
+func TestGitGetter(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=helm", "-c", "user.email=helm@example.com"}, args...)
+		_, err := runGit(context.Background(), repo, args...)
+		require.NoError(t, err)
+	}
+	git("init", "--quiet")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "prod"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "prod", "values.yaml"), []byte("replicas: 1\n"), 0644))
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "prod", "values.yaml"), []byte("replicas: 2\n"), 0644))
+	git("commit", "--quiet", "-am", "v2")
+
+	g, err := NewGitGetter()
+	require.NoError(t, err)
+
+	buf, err := g.Get("git+file://" + repo + "//prod/values.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "replicas: 2\n", buf.String())
+
+	buf, err = g.Get("git+file://" + repo + "//prod/values.yaml?ref=v1")
+	require.NoError(t, err)
+	assert.Equal(t, "replicas: 1\n", buf.String())
+
+	sum := sha256.Sum256([]byte("replicas: 1\n"))
+	_, err = g.Get("git+file://"+repo+"//prod/values.yaml?ref=v1", WithDigest(hex.EncodeToString(sum[:])))
+	require.NoError(t, err)
+	_, err = g.Get("git+file://"+repo+"//prod/values.yaml", WithDigest(hex.EncodeToString(sum[:])))
+	assert.ErrorContains(t, err, "digest mismatch")
+
+	_, err = g.Get("git+file://" + repo + "//missing.yaml")
+	assert.Error(t, err)
+
+	_, err = g.Get("git+file://" + repo + "//prod/values.yaml?ref=--upload-pack=false")
+	assert.ErrorContains(t, err, "must not start with '-'")
+}
@@ -19,17 +19,30 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 
 	"helm.sh/helm/v4/internal/tlsutil"
 	"helm.sh/helm/v4/internal/version"
 )
 
+const (
+	// DefaultResumeAttempts is the number of times an interrupted download is
+	// resumed when no other value is configured.
+	DefaultResumeAttempts = 3
+
+	// chunkedDownloadMinSize is the minimum content length for which parallel
+	// chunked downloads are used.
+	chunkedDownloadMinSize = 4 << 20
+)
+
 // HTTPGetter is the default HTTP(/S) backend handler
 type HTTPGetter struct {
 	opts      getterOptions
@@ -48,9 +61,37 @@ func (g *HTTPGetter) Get(href string, options ...Option) (*bytes.Buffer, error)
 }
 
 func (g *HTTPGetter) get(href string, opts getterOptions) (*bytes.Buffer, error) {
+	client, err := g.httpClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf *bytes.Buffer
+	if opts.connections > 1 {
+		buf, err = g.getChunked(client, href, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if buf == nil {
+		buf, err = g.download(client, href, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := VerifyDigest(buf.Bytes(), opts.digest); err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w", href, err)
+	}
+	return buf, nil
+}
+
+// newRequest creates a request for href with the Helm specific headers and,
+// when allowed for the host, the basic auth credentials.
+func (g *HTTPGetter) newRequest(method, href string, opts getterOptions) (*http.Request, error) {
 	// Set a helm specific user agent so that a repo server and metrics can
 	// separate helm calls from other tools interacting with repos.
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, href, http.NoBody)
+	req, err := http.NewRequestWithContext(context.Background(), method, href, http.NoBody)
 	if err != nil {
 		return nil, err
 	}
@@ -83,26 +124,138 @@ func (g *HTTPGetter) get(href string, opts getterOptions) (*bytes.Buffer, error)
 			req.SetBasicAuth(opts.username, opts.password)
 		}
 	}
+	return req, nil
+}
 
-	client, err := g.httpClient(opts)
+// download fetches href, resuming the transfer with range requests when the
+// connection is interrupted after part of the body was received.
+func (g *HTTPGetter) download(client *http.Client, href string, opts getterOptions) (*bytes.Buffer, error) {
+	attempts := opts.resumeAttempts
+	if attempts == 0 {
+		attempts = DefaultResumeAttempts
+	}
+
+	buf := bytes.NewBuffer(nil)
+	for attempt := 0; ; attempt++ {
+		req, err := g.newRequest(http.MethodGet, href, opts)
+		if err != nil {
+			return nil, err
+		}
+		offset := int64(buf.Len())
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+
+		slog.Debug("fetching", "url", href, "offset", offset)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		slog.Debug("fetch complete", "url", href, "status", resp.Status, "content-length", resp.ContentLength)
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			// The server sent the whole content, either because this is the
+			// first request or because it ignored the range.
+			buf.Reset()
+		case resp.StatusCode == http.StatusPartialContent && offset > 0 && contentRangeStart(resp.Header.Get("Content-Range")) == offset:
+		default:
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to fetch %s : %s", href, resp.Status)
+		}
+
+		_, err = io.Copy(buf, resp.Body)
+		resp.Body.Close()
+		if err == nil {
+			return buf, nil
+		}
+		if attempt >= attempts || buf.Len() == 0 {
+			return buf, err
+		}
+		slog.Debug("download interrupted, resuming", "url", href, "received", buf.Len(), slog.Any("error", err))
+	}
+}
+
+// getChunked fetches href using multiple concurrent range requests. It
+// returns a nil buffer when the server does not support range requests or
+// the content is too small to benefit from chunking.
+func (g *HTTPGetter) getChunked(client *http.Client, href string, opts getterOptions) (*bytes.Buffer, error) {
+	req, err := g.newRequest(http.MethodHead, href, opts)
 	if err != nil {
 		return nil, err
 	}
-
-	slog.Debug("fetching", "url", href)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Accept-Ranges") != "bytes" || resp.ContentLength < chunkedDownloadMinSize {
+		return nil, nil
+	}
+
+	size := resp.ContentLength
+	chunkSize := (size + int64(opts.connections) - 1) / int64(opts.connections)
+	data := make([]byte, size)
+
+	var wg sync.WaitGroup
+	errs := make([]error, opts.connections)
+	for i := range opts.connections {
+		start := int64(i) * chunkSize
+		if start >= size {
+			break
+		}
+		end := min(start+chunkSize, size) - 1
+		wg.Go(func() {
+			errs[i] = g.getRange(client, href, opts, data[start:end+1], start, end)
+		})
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	slog.Debug("chunked fetch complete", "url", href, "size", size, "connections", opts.connections)
+	return bytes.NewBuffer(data), nil
+}
+
+// getRange fetches the inclusive byte range start-end of href into dst.
+func (g *HTTPGetter) getRange(client *http.Client, href string, opts getterOptions, dst []byte, start, end int64) error {
+	req, err := g.newRequest(http.MethodGet, href, opts)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
-	slog.Debug("fetch complete", "url", href, "status", resp.Status, "content-length", resp.ContentLength)
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch %s : %s", href, resp.Status)
+	if resp.StatusCode != http.StatusPartialContent || contentRangeStart(resp.Header.Get("Content-Range")) != start {
+		return fmt.Errorf("failed to fetch %s (bytes %d-%d) : %s", href, start, end, resp.Status)
 	}
+	if _, err := io.ReadFull(resp.Body, dst); err != nil {
+		return fmt.Errorf("failed to fetch %s (bytes %d-%d) : %w", href, start, end, err)
+	}
+	return nil
+}
 
-	buf := bytes.NewBuffer(nil)
-	_, err = io.Copy(buf, resp.Body)
-	return buf, err
+// contentRangeStart returns the first byte position of a Content-Range
+// header value such as "bytes 100-199/1000", or -1 when it can't be parsed.
+func contentRangeStart(v string) int64 {
+	v, ok := strings.CutPrefix(v, "bytes ")
+	if !ok {
+		return -1
+	}
+	first, _, ok := strings.Cut(v, "-")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
 }
 
 // NewHTTPGetter constructs a valid http/https client as a Getter
@@ -124,26 +277,34 @@ func (g *HTTPGetter) httpClient(opts getterOptions) (*http.Client, error) {
 		}, nil
 	}
 
-	// Check if we need custom TLS configuration
+	// Check if we need custom TLS or proxy configuration
 	needsCustomTLS := (opts.certFile != "" && opts.keyFile != "") || opts.caFile != "" || opts.insecureSkipVerifyTLS
 
-	if needsCustomTLS {
-		// Create a new transport for custom TLS to avoid race conditions
+	if needsCustomTLS || opts.proxy != "" {
+		proxy, err := proxyFunc(opts.proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create a new transport for custom settings to avoid race conditions
 		transport := &http.Transport{
 			DisableCompression: true,
-			Proxy:              http.ProxyFromEnvironment,
+			Proxy:              proxy,
+			TLSClientConfig:    &tls.Config{},
 		}
 
-		tlsConf, err := tlsutil.NewTLSConfig(
-			tlsutil.WithInsecureSkipVerify(opts.insecureSkipVerifyTLS),
-			tlsutil.WithCertKeyPairFiles(opts.certFile, opts.keyFile),
-			tlsutil.WithCAFile(opts.caFile),
-		)
-		if err != nil {
-			return nil, fmt.Errorf("can't create TLS config for client: %w", err)
-		}
+		if needsCustomTLS {
+			tlsConf, err := tlsutil.NewTLSConfig(
+				tlsutil.WithInsecureSkipVerify(opts.insecureSkipVerifyTLS),
+				tlsutil.WithCertKeyPairFiles(opts.certFile, opts.keyFile),
+				tlsutil.WithCAFile(opts.caFile),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("can't create TLS config for client: %w", err)
+			}
 
-		transport.TLSClientConfig = tlsConf
+			transport.TLSClientConfig = tlsConf
+		}
 
 		return &http.Client{
 			Transport: transport,
@@ -165,3 +326,17 @@ func (g *HTTPGetter) httpClient(opts getterOptions) (*http.Client, error) {
 		Timeout:   opts.timeout,
 	}, nil
 }
+
+// proxyFunc returns the proxy selection function for a transport. When no
+// proxy URL is given the environment (HTTP_PROXY, HTTPS_PROXY, NO_PROXY) is
+// used.
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", proxy, err)
+	}
+	return http.ProxyURL(u), nil
+}
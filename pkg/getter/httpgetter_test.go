@@ -16,6 +16,9 @@ limitations under the License.
 package getter
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,6 +28,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -677,3 +681,167 @@ func TestHTTPTransportOption(t *testing.T) {
 		t.Fatal("transport.TLSClientConfig should not be set")
 	}
 }
+
+func TestDownloadWithProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		fmt.Fprint(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	g, err := NewHTTPGetter(WithURL("http://charts.example.invalid"), WithProxy(proxy.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.Get("http://charts.example.invalid/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "via proxy" {
+		t.Errorf("expected response from the proxy, got %q", got.String())
+	}
+	if proxied != "http://charts.example.invalid/index.yaml" {
+		t.Errorf("expected the proxy to receive the absolute URL, got %q", proxied)
+	}
+
+	if _, err := g.Get("http://charts.example.invalid/index.yaml", WithProxy("://bad")); err == nil {
+		t.Error("expected an error for an invalid proxy URL")
+	}
+}
+
+func TestDownloadDigestVerification(t *testing.T) {
+	content := "chart archive content"
+	sum := sha256.Sum256([]byte(content))
+	digest := hex.EncodeToString(sum[:])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, content)
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []string{"", digest, "sha256:" + digest, strings.ToUpper(digest)} {
+		if _, err := g.Get(srv.URL, WithDigest(d)); err != nil {
+			t.Errorf("unexpected error for digest %q: %s", d, err)
+		}
+	}
+
+	_, err = g.Get(srv.URL, WithDigest("sha256:"+strings.Repeat("0", 64)))
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("expected digest mismatch error, got %v", err)
+	}
+
+	_, err = g.Get(srv.URL, WithDigest("md5:abc"))
+	if err == nil || !strings.Contains(err.Error(), "unsupported digest algorithm") {
+		t.Errorf("expected unsupported algorithm error, got %v", err)
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 1000)
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng := r.Header.Get("Range")
+		ranges = append(ranges, rng)
+		if rng == "" {
+			// Announce the full length but only send half of the body so the
+			// client sees an unexpected EOF.
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(content[:len(content)/2]))
+			return
+		}
+		var start int
+		if _, err := fmt.Sscanf(rng, "bytes=%d-", &start); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(content[start:]))
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != content {
+		t.Errorf("expected resumed download to return the full content, got %d bytes", got.Len())
+	}
+	if len(ranges) != 2 || ranges[1] != fmt.Sprintf("bytes=%d-", len(content)/2) {
+		t.Errorf("unexpected range requests %q", ranges)
+	}
+
+	ranges = nil
+	if _, err := g.Get(srv.URL, WithResumeAttempts(-1)); err == nil {
+		t.Error("expected an error when resuming is disabled")
+	}
+	if len(ranges) != 1 {
+		t.Errorf("expected a single request when resuming is disabled, got %q", ranges)
+	}
+}
+
+func TestDownloadParallelConnections(t *testing.T) {
+	content := make([]byte, chunkedDownloadMinSize+123)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	var mu sync.Mutex
+	var rangeRequests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			mu.Lock()
+			rangeRequests++
+			mu.Unlock()
+		}
+		http.ServeContent(w, r, "chart.tgz", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL), WithParallelConnections(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(content)
+	got, err := g.Get(srv.URL, WithDigest(hex.EncodeToString(sum[:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), content) {
+		t.Error("chunked download returned different content")
+	}
+	if rangeRequests != 4 {
+		t.Errorf("expected 4 range requests, got %d", rangeRequests)
+	}
+
+	// Small files are fetched with a single request
+	rangeRequests = 0
+	small := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			rangeRequests++
+		}
+		http.ServeContent(w, r, "chart.tgz", time.Time{}, strings.NewReader("small"))
+	}))
+	defer small.Close()
+	got, err = g.Get(small.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "small" || rangeRequests != 0 {
+		t.Errorf("unexpected result %q with %d range requests", got.String(), rangeRequests)
+	}
+}
@@ -67,6 +67,12 @@ func (g *OCIGetter) get(href string) (*bytes.Buffer, error) {
 	if g.opts.artifactType == "plugin" {
 		return g.getPlugin(client, ref)
 	}
+	if g.opts.artifactType == "values" {
+		return g.getValues(client, ref)
+	}
+	if g.opts.artifactType == "keys" {
+		return g.getKeys(client, ref)
+	}
 
 	// Default to chart behavior for backward compatibility
 	var pullOpts []registry.PullOption
@@ -211,3 +217,27 @@ func (g *OCIGetter) getPlugin(client *registry.Client, ref string) (*bytes.Buffe
 	}
 	return bytes.NewBuffer(result.PluginData), nil
 }
+
+// getValues handles pulls of values files stored as OCI artifacts
+func (g *OCIGetter) getValues(client *registry.Client, ref string) (*bytes.Buffer, error) {
+	result, err := client.PullValues(ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyDigest(result.Data, g.opts.digest); err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w", ref, err)
+	}
+	return bytes.NewBuffer(result.Data), nil
+}
+
+// getKeys handles pulls of PGP public keys stored as OCI artifacts
+func (g *OCIGetter) getKeys(client *registry.Client, ref string) (*bytes.Buffer, error) {
+	result, err := client.PullKeys(ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyDigest(result.Data, g.opts.digest); err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w", ref, err)
+	}
+	return bytes.NewBuffer(result.Data), nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"fmt"
+	"reflect"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+)
+
+// Chart is a chart loaded from a directory or an archive.
+type Chart struct {
+	chart chart.Charter
+}
+
+// LoadChart loads a chart from a directory or a packaged chart archive.
+func LoadChart(path string) (*Chart, error) {
+	c, err := loader.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Chart{chart: c}, nil
+}
+
+// Name returns the name of the chart.
+func (c *Chart) Name() string {
+	return c.metadata("Name")
+}
+
+// Version returns the version of the chart.
+func (c *Chart) Version() string {
+	return c.metadata("Version")
+}
+
+// AppVersion returns the version of the application of the chart.
+func (c *Chart) AppVersion() string {
+	return c.metadata("AppVersion")
+}
+
+func (c *Chart) metadata(field string) string {
+	return chartMetadata(c.chart, field)
+}
+
+// chartMetadata returns a field of the metadata of a chart, or an empty
+// string if the chart has no metadata.
+func chartMetadata(c chart.Charter, field string) string {
+	if v := reflect.ValueOf(c); c == nil || v.Kind() == reflect.Pointer && v.IsNil() {
+		return ""
+	}
+	accessor, err := chart.NewAccessor(c)
+	if err != nil {
+		return ""
+	}
+	value, ok := accessor.MetadataAsMap()[field]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"log/slog"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"helm.sh/helm/v4/pkg/action"
+)
+
+// Installer installs charts as new releases.
+type Installer interface {
+	Install(ctx context.Context, name string, chart *Chart, values map[string]any, options ...InstallOption) (*Release, error)
+}
+
+// Upgrader upgrades releases to a new chart or new values.
+type Upgrader interface {
+	Upgrade(ctx context.Context, name string, chart *Chart, values map[string]any, options ...UpgradeOption) (*Release, error)
+}
+
+// Lister lists the releases of a namespace.
+type Lister interface {
+	List(ctx context.Context, options ...ListOption) ([]*Release, error)
+}
+
+// Getter gets a revision of a release.
+type Getter interface {
+	Get(ctx context.Context, name string, options ...GetOption) (*Release, error)
+}
+
+var (
+	_ Installer = (*Client)(nil)
+	_ Upgrader  = (*Client)(nil)
+	_ Lister    = (*Client)(nil)
+	_ Getter    = (*Client)(nil)
+)
+
+// Client manages the releases of a namespace. It implements Installer,
+// Upgrader, Lister and Getter.
+type Client struct {
+	cfg *action.Configuration
+
+	namespace     string
+	kubeConfig    string
+	kubeContext   string
+	storageDriver string
+	logger        *slog.Logger
+}
+
+// ClientOption allows specifying various settings of a client.
+type ClientOption func(*Client)
+
+// ClientOptNamespace sets the namespace of the releases. It defaults to the
+// namespace of the kubeconfig context.
+func ClientOptNamespace(namespace string) ClientOption {
+	return func(c *Client) {
+		c.namespace = namespace
+	}
+}
+
+// ClientOptKubeConfig sets the path of the kubeconfig file. It defaults to
+// the KUBECONFIG environment variable, then to ~/.kube/config, and to the
+// in-cluster configuration when running in a pod.
+func ClientOptKubeConfig(path string) ClientOption {
+	return func(c *Client) {
+		c.kubeConfig = path
+	}
+}
+
+// ClientOptKubeContext sets the kubeconfig context to use.
+func ClientOptKubeContext(context string) ClientOption {
+	return func(c *Client) {
+		c.kubeContext = context
+	}
+}
+
+// ClientOptStorageDriver sets the storage driver of the releases: "secret",
+// the default, "configmap", "memory" or "sql".
+func ClientOptStorageDriver(driver string) ClientOption {
+	return func(c *Client) {
+		c.storageDriver = driver
+	}
+}
+
+// ClientOptLogger sets the logger of the client.
+func ClientOptLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// ClientOptConfiguration makes the client use an action configuration
+// rather than connect to a cluster, e.g. one with fake clients in tests. The
+// kubeconfig, context and storage driver options are then ignored.
+func ClientOptConfiguration(cfg *action.Configuration) ClientOption {
+	return func(c *Client) {
+		c.cfg = cfg
+	}
+}
+
+// NewClient returns a client connected to the cluster of the kubeconfig.
+func NewClient(options ...ClientOption) (*Client, error) {
+	c := &Client{}
+	for _, option := range options {
+		option(c)
+	}
+	if c.cfg != nil {
+		if c.logger != nil {
+			c.cfg.SetLogger(c.logger.Handler())
+		}
+		return c, nil
+	}
+
+	flags := genericclioptions.NewConfigFlags(true)
+	if c.kubeConfig != "" {
+		flags.KubeConfig = &c.kubeConfig
+	}
+	if c.kubeContext != "" {
+		flags.Context = &c.kubeContext
+	}
+	if c.namespace == "" {
+		namespace, _, err := flags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return nil, err
+		}
+		c.namespace = namespace
+	}
+	flags.Namespace = &c.namespace
+
+	cfg := action.NewConfiguration()
+	if c.logger != nil {
+		cfg.SetLogger(c.logger.Handler())
+	}
+	if err := cfg.Init(flags, c.namespace, c.storageDriver); err != nil {
+		return nil, err
+	}
+	c.cfg = cfg
+	return c, nil
+}
+
+// Namespace returns the namespace of the releases of the client.
+func (c *Client) Namespace() string {
+	return c.namespace
+}
@@ -0,0 +1,130 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func testClient(t *testing.T) *Client {
+	t.Helper()
+	client, err := NewClient(
+		ClientOptNamespace("default"),
+		ClientOptConfiguration(&action.Configuration{
+			Releases:     storage.Init(driver.NewMemory()),
+			KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+			Capabilities: common.DefaultCapabilities,
+		}))
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient(t *testing.T) {
+	ctx := t.Context()
+	client := testClient(t)
+	chart, err := LoadChart("testdata/hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", chart.Name())
+
+	_, err = client.Upgrade(ctx, "myapp", chart, nil)
+	assert.ErrorIs(t, err, driver.ErrNoDeployedReleases)
+
+	rel, err := client.Upgrade(ctx, "myapp", chart, nil, UpgradeOptInstall(), UpgradeOptDescription("first"))
+	require.NoError(t, err)
+	assert.Equal(t, "myapp", rel.Name)
+	assert.Equal(t, "default", rel.Namespace)
+	assert.Equal(t, 1, rel.Revision)
+	assert.Equal(t, "deployed", rel.Status)
+	assert.Equal(t, "first", rel.Description)
+	assert.Equal(t, "0.1.0", rel.ChartVersion)
+	assert.Equal(t, "1.0", rel.AppVersion)
+
+	rel, err = client.Upgrade(ctx, "myapp", chart, map[string]any{"greeting": "hi"}, UpgradeOptInstall())
+	require.NoError(t, err)
+	assert.Equal(t, 2, rel.Revision)
+	assert.Equal(t, map[string]any{"greeting": "hi"}, rel.Values)
+	assert.Contains(t, rel.Manifest, "greeting: hi")
+
+	_, err = client.Install(ctx, "other", chart, nil, InstallOptLabels(map[string]string{"team": "payments"}))
+	require.NoError(t, err)
+
+	rels, err := client.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, rels, 2)
+	assert.Equal(t, "myapp", rels[0].Name)
+	assert.Equal(t, 2, rels[0].Revision)
+
+	rels, err = client.List(ctx, ListOptSelector("team=payments"))
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, "other", rels[0].Name)
+
+	rel, err = client.Get(ctx, "myapp", GetOptRevision(1))
+	require.NoError(t, err)
+	assert.Equal(t, 1, rel.Revision)
+	assert.Equal(t, "superseded", rel.Status)
+
+	_, err = client.Get(ctx, "missing")
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+}
+
+func TestClientScriptedKubeClient(t *testing.T) {
+	ctx := t.Context()
+	kubeClient := &kubefake.ScriptedKubeClient{Namespace: "default"}
+	client, err := NewClient(
+		ClientOptNamespace("default"),
+		ClientOptConfiguration(&action.Configuration{
+			Releases:     storage.Init(driver.NewMemory()),
+			KubeClient:   kubeClient,
+			Capabilities: common.DefaultCapabilities,
+		}))
+	require.NoError(t, err)
+	chart, err := LoadChart("testdata/hello")
+	require.NoError(t, err)
+
+	_, err = client.Install(ctx, "myapp", chart, nil)
+	require.NoError(t, err)
+	creates := kubeClient.CallsTo("Create")
+	require.Len(t, creates, 1)
+	require.Len(t, creates[0].Resources, 1)
+	assert.Equal(t, "ConfigMap", creates[0].Resources[0].Mapping.GroupVersionKind.Kind)
+
+	kubeClient.WaitFunc = func(kube.ResourceList, time.Duration) error {
+		return errors.New("timed out waiting for the condition")
+	}
+	_, err = client.Install(ctx, "waited", chart, nil, InstallOptWait(time.Second))
+	assert.ErrorContains(t, err, "timed out waiting for the condition")
+	waits := kubeClient.CallsTo("Wait")
+	require.Len(t, waits, 2)
+	assert.Equal(t, "waited", waits[1].Resources[0].Name)
+	rel, err := client.Get(ctx, "waited")
+	require.NoError(t, err)
+	assert.Equal(t, "failed", rel.Status)
+}
@@ -0,0 +1,37 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package helm is a small, stable API for installing, upgrading, listing and
+// getting releases from Go programs, such as Kubernetes operators.
+//
+// The interfaces of the package, Installer, Upgrader, Lister and Getter, are
+// implemented by Client and only use the types of this package, so that
+// programs built against them are not affected by the changes of the types of
+// pkg/action and of the Kubernetes client libraries between minor releases.
+// Programs that need more control can use pkg/action directly.
+//
+//	client, err := helm.NewClient(helm.ClientOptNamespace("apps"))
+//	if err != nil {
+//		return err
+//	}
+//	chart, err := helm.LoadChart("./mychart")
+//	if err != nil {
+//		return err
+//	}
+//	rel, err := client.Upgrade(ctx, "myapp", chart, values,
+//		helm.UpgradeOptInstall(),
+//		helm.UpgradeOptWait(5*time.Minute))
+package helm
@@ -0,0 +1,55 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+
+	"helm.sh/helm/v4/pkg/action"
+)
+
+type (
+	// GetOption allows specifying various settings on get.
+	GetOption func(*getOperation)
+
+	getOperation struct {
+		revision int
+	}
+)
+
+// GetOptRevision gets a revision of the release rather than the latest.
+func GetOptRevision(revision int) GetOption {
+	return func(operation *getOperation) {
+		operation.revision = revision
+	}
+}
+
+// Get gets the latest revision of a release.
+func (c *Client) Get(_ context.Context, name string, options ...GetOption) (*Release, error) {
+	operation := &getOperation{}
+	for _, option := range options {
+		option(operation)
+	}
+
+	client := action.NewGet(c.cfg)
+	client.Version = operation.revision
+	rel, err := client.Run(name)
+	if err != nil {
+		return nil, err
+	}
+	return newRelease(rel)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"time"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// defaultTimeout is how long an operation waits for the resources and the
+// hooks, matching the default of the helm command.
+const defaultTimeout = 5 * time.Minute
+
+type (
+	// InstallOption allows specifying various settings on install.
+	InstallOption func(*installOperation)
+
+	installOperation struct {
+		wait              bool
+		timeout           time.Duration
+		createNamespace   bool
+		rollbackOnFailure bool
+		description       string
+		labels            map[string]string
+		dryRun            bool
+	}
+)
+
+// InstallOptWait waits until the resources of the release are ready, for at
+// most timeout.
+func InstallOptWait(timeout time.Duration) InstallOption {
+	return func(operation *installOperation) {
+		operation.wait = true
+		operation.timeout = timeout
+	}
+}
+
+// InstallOptCreateNamespace creates the namespace of the release if it does
+// not exist.
+func InstallOptCreateNamespace() InstallOption {
+	return func(operation *installOperation) {
+		operation.createNamespace = true
+	}
+}
+
+// InstallOptRollbackOnFailure uninstalls the release if the install fails.
+// It implies waiting for the resources.
+func InstallOptRollbackOnFailure() InstallOption {
+	return func(operation *installOperation) {
+		operation.rollbackOnFailure = true
+	}
+}
+
+// InstallOptDescription sets the description of the release.
+func InstallOptDescription(description string) InstallOption {
+	return func(operation *installOperation) {
+		operation.description = description
+	}
+}
+
+// InstallOptLabels sets labels on the release.
+func InstallOptLabels(labels map[string]string) InstallOption {
+	return func(operation *installOperation) {
+		operation.labels = labels
+	}
+}
+
+// InstallOptDryRun renders the release without installing it.
+func InstallOptDryRun() InstallOption {
+	return func(operation *installOperation) {
+		operation.dryRun = true
+	}
+}
+
+// Install installs a chart as a new release.
+func (c *Client) Install(ctx context.Context, name string, chart *Chart, values map[string]any, options ...InstallOption) (*Release, error) {
+	operation := &installOperation{timeout: defaultTimeout}
+	for _, option := range options {
+		option(operation)
+	}
+
+	client := action.NewInstall(c.cfg)
+	client.ReleaseName = name
+	client.Namespace = c.namespace
+	client.CreateNamespace = operation.createNamespace
+	client.Timeout = operation.timeout
+	client.WaitStrategy = waitStrategy(operation.wait)
+	client.RollbackOnFailure = operation.rollbackOnFailure
+	client.Description = operation.description
+	client.Labels = operation.labels
+	if operation.dryRun {
+		client.DryRunStrategy = action.DryRunClient
+	}
+
+	rel, err := client.RunWithContext(ctx, chart.chart, values)
+	if err != nil {
+		return nil, err
+	}
+	return newRelease(rel)
+}
+
+func waitStrategy(wait bool) kube.WaitStrategy {
+	if wait {
+		return kube.StatusWatcherStrategy
+	}
+	return kube.HookOnlyStrategy
+}
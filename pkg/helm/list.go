@@ -0,0 +1,81 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+
+	"helm.sh/helm/v4/pkg/action"
+)
+
+type (
+	// ListOption allows specifying various settings on list.
+	ListOption func(*listOperation)
+
+	listOperation struct {
+		filter    string
+		selector  string
+		allStates bool
+	}
+)
+
+// ListOptFilter only lists the releases whose name matches a regular
+// expression.
+func ListOptFilter(filter string) ListOption {
+	return func(operation *listOperation) {
+		operation.filter = filter
+	}
+}
+
+// ListOptSelector only lists the releases whose labels match a label
+// selector, such as "team=payments".
+func ListOptSelector(selector string) ListOption {
+	return func(operation *listOperation) {
+		operation.selector = selector
+	}
+}
+
+// ListOptAllStates lists the releases in any state. By default only the
+// deployed and failed releases are listed.
+func ListOptAllStates() ListOption {
+	return func(operation *listOperation) {
+		operation.allStates = true
+	}
+}
+
+// List lists the latest revision of the releases of the namespace of the
+// client, sorted by name.
+func (c *Client) List(_ context.Context, options ...ListOption) ([]*Release, error) {
+	operation := &listOperation{}
+	for _, option := range options {
+		option(operation)
+	}
+
+	client := action.NewList(c.cfg)
+	client.All = operation.allStates
+	client.Deployed = true
+	client.Failed = true
+	client.Filter = operation.filter
+	client.Selector = operation.selector
+	client.SetStateMask()
+
+	rels, err := client.Run()
+	if err != nil {
+		return nil, err
+	}
+	return newReleases(rels)
+}
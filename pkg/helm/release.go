@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"time"
+
+	"helm.sh/helm/v4/pkg/release"
+	v1 "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// Release is a revision of a release.
+type Release struct {
+	// Name is the name of the release.
+	Name string
+	// Namespace is the namespace of the release.
+	Namespace string
+	// Revision is the revision of the release, starting at 1.
+	Revision int
+	// Status is the status of the revision, such as "deployed" or "failed".
+	Status string
+	// Description describes the last operation on the revision.
+	Description string
+	// ChartName, ChartVersion and AppVersion describe the chart of the
+	// revision.
+	ChartName    string
+	ChartVersion string
+	AppVersion   string
+	// Values are the values the revision was deployed with, without the
+	// defaults of the chart.
+	Values map[string]any
+	// Manifest is the rendered manifest of the revision, hooks excluded.
+	Manifest string
+	// Notes are the rendered notes of the chart.
+	Notes string
+	// Labels are the labels of the release.
+	Labels map[string]string
+	// DeployedAt is when the revision was deployed.
+	DeployedAt time.Time
+}
+
+// newRelease converts a release returned by an action.
+func newRelease(rel release.Releaser) (*Release, error) {
+	accessor, err := release.NewAccessor(rel)
+	if err != nil {
+		return nil, err
+	}
+	r := &Release{
+		Name:         accessor.Name(),
+		Namespace:    accessor.Namespace(),
+		Revision:     accessor.Version(),
+		Status:       accessor.Status(),
+		ChartName:    chartMetadata(accessor.Chart(), "Name"),
+		ChartVersion: chartMetadata(accessor.Chart(), "Version"),
+		AppVersion:   chartMetadata(accessor.Chart(), "AppVersion"),
+		Manifest:     accessor.Manifest(),
+		Notes:        accessor.Notes(),
+		Labels:       accessor.Labels(),
+		DeployedAt:   accessor.DeployedAt(),
+	}
+	if v1rel, ok := rel.(*v1.Release); ok {
+		r.Values = v1rel.Config
+		if v1rel.Info != nil {
+			r.Description = v1rel.Info.Description
+		}
+	}
+	return r, nil
+}
+
+// newReleases converts the releases returned by an action.
+func newReleases(rels []release.Releaser) ([]*Release, error) {
+	out := make([]*Release, 0, len(rels))
+	for _, rel := range rels {
+		r, err := newRelease(rel)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+type (
+	// UpgradeOption allows specifying various settings on upgrade.
+	UpgradeOption func(*upgradeOperation)
+
+	upgradeOperation struct {
+		install           bool
+		wait              bool
+		timeout           time.Duration
+		rollbackOnFailure bool
+		reuseValues       bool
+		resetValues       bool
+		description       string
+		labels            map[string]string
+		dryRun            bool
+	}
+)
+
+// UpgradeOptInstall installs the release if it does not exist.
+func UpgradeOptInstall() UpgradeOption {
+	return func(operation *upgradeOperation) {
+		operation.install = true
+	}
+}
+
+// UpgradeOptWait waits until the resources of the release are ready, for at
+// most timeout.
+func UpgradeOptWait(timeout time.Duration) UpgradeOption {
+	return func(operation *upgradeOperation) {
+		operation.wait = true
+		operation.timeout = timeout
+	}
+}
+
+// UpgradeOptRollbackOnFailure rolls the release back to the previous
+// successful revision if the upgrade fails. It implies waiting for the
+// resources.
+func UpgradeOptRollbackOnFailure() UpgradeOption {
+	return func(operation *upgradeOperation) {
+		operation.rollbackOnFailure = true
+	}
+}
+
+// UpgradeOptReuseValues merges the values with those of the current
+// revision.
+func UpgradeOptReuseValues() UpgradeOption {
+	return func(operation *upgradeOperation) {
+		operation.reuseValues = true
+	}
+}
+
+// UpgradeOptResetValues ignores the values of the current revision and only
+// uses the given values and those of the chart.
+func UpgradeOptResetValues() UpgradeOption {
+	return func(operation *upgradeOperation) {
+		operation.resetValues = true
+	}
+}
+
+// UpgradeOptDescription sets the description of the new revision.
+func UpgradeOptDescription(description string) UpgradeOption {
+	return func(operation *upgradeOperation) {
+		operation.description = description
+	}
+}
+
+// UpgradeOptLabels sets labels on the release. They are merged with the
+// labels of the current revision.
+func UpgradeOptLabels(labels map[string]string) UpgradeOption {
+	return func(operation *upgradeOperation) {
+		operation.labels = labels
+	}
+}
+
+// UpgradeOptDryRun renders the new revision without upgrading the release.
+func UpgradeOptDryRun() UpgradeOption {
+	return func(operation *upgradeOperation) {
+		operation.dryRun = true
+	}
+}
+
+// Upgrade upgrades a release to a chart and values.
+func (c *Client) Upgrade(ctx context.Context, name string, chart *Chart, values map[string]any, options ...UpgradeOption) (*Release, error) {
+	operation := &upgradeOperation{timeout: defaultTimeout}
+	for _, option := range options {
+		option(operation)
+	}
+
+	if operation.install {
+		history := action.NewHistory(c.cfg)
+		history.Max = 1
+		if _, err := history.Run(name); errors.Is(err, driver.ErrReleaseNotFound) {
+			return c.Install(ctx, name, chart, values, operation.installOptions()...)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	client := action.NewUpgrade(c.cfg)
+	client.Namespace = c.namespace
+	client.Timeout = operation.timeout
+	client.WaitStrategy = waitStrategy(operation.wait)
+	client.RollbackOnFailure = operation.rollbackOnFailure
+	client.ReuseValues = operation.reuseValues
+	client.ResetValues = operation.resetValues
+	client.Description = operation.description
+	client.Labels = operation.labels
+	if operation.dryRun {
+		client.DryRunStrategy = action.DryRunClient
+	}
+
+	rel, err := client.RunWithContext(ctx, name, chart.chart, values)
+	if err != nil {
+		return nil, err
+	}
+	return newRelease(rel)
+}
+
+// installOptions returns the options of the install an upgrade falls back to
+// when the release does not exist.
+func (operation *upgradeOperation) installOptions() []InstallOption {
+	options := []InstallOption{
+		InstallOptDescription(operation.description),
+		InstallOptLabels(operation.labels),
+	}
+	if operation.wait {
+		options = append(options, InstallOptWait(operation.timeout))
+	}
+	if operation.rollbackOnFailure {
+		options = append(options, InstallOptRollbackOnFailure())
+	}
+	if operation.dryRun {
+		options = append(options, InstallOptDryRun())
+	}
+	return options
+}
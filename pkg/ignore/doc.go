@@ -31,7 +31,11 @@ The formatting rules are as follows:
   - Inline comments are NOT supported ('foo* # Any foo' does not contain a comment)
   - There is no support for multi-line patterns
   - Shell glob patterns are supported. See Go's "path/filepath".Match
-  - If a pattern begins with a leading !, the match will be negated.
+  - Patterns are evaluated in order and the last matching pattern decides
+    whether a path is ignored.
+  - If a pattern begins with a leading !, paths it matches are included again,
+    even if an earlier pattern ignored them. A file cannot be included again
+    if its parent directory is ignored.
   - If a pattern begins with a leading /, only paths relatively rooted will match.
   - If the pattern ends with a trailing /, only directories will match
   - If a pattern contains no slashes, file basenames are tested (not paths)
@@ -58,6 +62,15 @@ Example:
 	# Match any file named ab.txt, ac.txt, or ad.txt
 	a[b-d].txt
 
+	# Match any markdown file except README.md
+	*.md
+	!README.md
+
+Ignore files may also be placed in subdirectories of a chart. Their patterns
+apply only to the contents of their directory, match paths relative to it and
+take precedence over the patterns of the ignore files of parent directories,
+as with nested .gitignore files.
+
 Notable differences from .gitignore:
   - The '**' syntax is not supported.
   - The globbing library is Go's 'filepath.Match', not fnmatch(3)
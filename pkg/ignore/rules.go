@@ -20,9 +20,11 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -46,8 +48,13 @@ func Empty() *Rules {
 // AddDefaults adds default ignore patterns.
 //
 // Ignore all dotfiles in "templates/"
+//
+// The default patterns are evaluated before the patterns of the ignore files,
+// so that the ignore files can re-include the files they match.
 func (r *Rules) AddDefaults() {
-	r.parseRule(`templates/.?*`)
+	defaults := &Rules{}
+	defaults.parseRule(`templates/.?*`)
+	r.patterns = append(defaults.patterns, r.patterns...)
 }
 
 // ParseFile parses a helmignore file and returns the *Rules.
@@ -57,7 +64,11 @@ func ParseFile(file string) (*Rules, error) {
 		return nil, err
 	}
 	defer f.Close()
-	return Parse(f)
+	r, err := Parse(f)
+	if r != nil {
+		r.setSource(filepath.Base(file), "")
+	}
+	return r, err
 }
 
 // Parse parses a rules file
@@ -76,45 +87,89 @@ func Parse(file io.Reader) (*Rules, error) {
 		line := string(scannedBytes)
 		currentLine++
 
+		n := len(r.patterns)
 		if err := r.parseRule(line); err != nil {
 			return r, err
 		}
+		if len(r.patterns) > n {
+			r.patterns[n].line = currentLine
+		}
 	}
 	return r, s.Err()
 }
 
+// AddFile parses the ignore file of the subdirectory dir, a slash separated
+// path relative to the root of the rules, and adds its patterns to r.
+//
+// Like the patterns of a nested .gitignore file, the patterns match paths
+// relative to dir, only apply to the contents of dir and take precedence over
+// the patterns already in r.
+func (r *Rules) AddFile(dir, file string) error {
+	nested, err := ParseFile(file)
+	if err != nil {
+		return err
+	}
+	dir = strings.Trim(dir, "/")
+	nested.setSource(path.Join(dir, filepath.Base(file)), dir)
+	r.patterns = append(r.patterns, nested.patterns...)
+	return nil
+}
+
+// AddPatterns adds patterns given outside of an ignore file, such as on the
+// command line, to r. The source names where the patterns come from in the
+// rules returned by IgnoredBy.
+func (r *Rules) AddPatterns(source string, patterns ...string) error {
+	for _, rule := range patterns {
+		n := len(r.patterns)
+		if err := r.parseRule(rule); err != nil {
+			return fmt.Errorf("%s %q: %w", source, rule, err)
+		}
+		for _, p := range r.patterns[n:] {
+			p.source = source
+		}
+	}
+	return nil
+}
+
+// setSource records the ignore file the patterns were read from and the
+// directory they apply to.
+func (r *Rules) setSource(source, base string) {
+	for _, p := range r.patterns {
+		p.source = source
+		p.base = base
+	}
+}
+
 // Ignore evaluates the file at the given path, and returns true if it should be ignored.
 //
-// Ignore evaluates path against the rules in order. Evaluation stops when a match
-// is found. Matching a negative rule will stop evaluation.
+// Ignore evaluates path against the rules in order and the last matching rule
+// decides, as in .gitignore files: a path is ignored if it matches a rule, and
+// included again if it matches a later negative rule.
 func (r *Rules) Ignore(path string, fi os.FileInfo) bool {
+	_, ignored := r.IgnoredBy(path, fi)
+	return ignored
+}
+
+// IgnoredBy is like Ignore, but also returns the rule that decided whether the
+// path is ignored, prefixed by the ignore file and line it was read from, such
+// as ".helmignore:3: *.txt". The rule is empty if no rule matched.
+func (r *Rules) IgnoredBy(path string, fi os.FileInfo) (string, bool) {
 	// Don't match on empty dirs.
 	if path == "" {
-		return false
+		return "", false
 	}
 
 	// Disallow ignoring the current working directory.
 	// See issue:
 	// 1776 (New York City) Hamilton: "Pardon me, are you Aaron Burr, sir?"
 	if path == "." || path == "./" {
-		return false
+		return "", false
 	}
-	for _, p := range r.patterns {
+	for i := len(r.patterns) - 1; i >= 0; i-- {
+		p := r.patterns[i]
 		if p.match == nil {
 			slog.Info("this will be ignored no matcher supplied", "patterns", p.raw)
-			return false
-		}
-
-		// For negative rules, we need to capture and return non-matches,
-		// and continue for matches.
-		if p.negate {
-			if p.mustDir && !fi.IsDir() {
-				return true
-			}
-			if !p.match(path, fi) {
-				return true
-			}
-			continue
+			return "", false
 		}
 
 		// If the rule is looking for directories, and this is not a directory,
@@ -122,13 +177,44 @@ func (r *Rules) Ignore(path string, fi os.FileInfo) bool {
 		if p.mustDir && !fi.IsDir() {
 			continue
 		}
-		if p.match(path, fi) {
-			return true
+		n := path
+		if p.base != "" {
+			var ok bool
+			if n, ok = strings.CutPrefix(path, p.base+"/"); !ok || n == "" {
+				continue
+			}
+		}
+		if p.match(n, fi) {
+			return p.String(), !p.negate
+		}
+	}
+	return "", false
+}
+
+// IgnoredPath is like IgnoredBy for a slash separated path that may not exist
+// on disk, such as the name of a file of a loaded chart. Paths ending with a
+// slash are directories. The path is ignored if it, or one of its parent
+// directories, is ignored.
+func (r *Rules) IgnoredPath(name string) (string, bool) {
+	isDir := strings.HasSuffix(name, "/")
+	name = strings.Trim(name, "/")
+	dirs := strings.Split(name, "/")
+	for i := 1; i < len(dirs); i++ {
+		if rule, ok := r.IgnoredBy(strings.Join(dirs[:i], "/"), pathInfo{dir: true}); ok {
+			return rule, ok
 		}
 	}
-	return false
+	return r.IgnoredBy(name, pathInfo{dir: isDir})
+}
+
+// pathInfo is the os.FileInfo of a path that may not exist on disk.
+type pathInfo struct {
+	os.FileInfo
+	dir bool
 }
 
+func (fi pathInfo) IsDir() bool { return fi.dir }
+
 // parseRule parses a rule string and creates a pattern, which is then stored in the Rules object.
 func (r *Rules) parseRule(rule string) error {
 	rule = strings.TrimSpace(rule)
@@ -228,4 +314,21 @@ type pattern struct {
 	negate bool
 	// mustDir indicates that the matched file must be a directory.
 	mustDir bool
+	// base is the directory of the ignore file the rule was read from,
+	// relative to the root of the rules. Only paths within it are matched.
+	base string
+	// source and line locate the rule in its ignore file.
+	source string
+	line   int
+}
+
+// String returns the rule with its location.
+func (p *pattern) String() string {
+	if p.source == "" {
+		return p.raw
+	}
+	if p.line == 0 {
+		return p.source + ": " + p.raw
+	}
+	return fmt.Sprintf("%s:%d: %s", p.source, p.line, p.raw)
 }
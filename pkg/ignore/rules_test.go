@@ -102,9 +102,15 @@ func TestIgnore(t *testing.T) {
 
 		// Negation tests
 		{`!helm.txt`, "helm.txt", false},
-		{`!helm.txt`, "tiller.txt", true},
-		{`!*.txt`, "cargo", true},
-		{`!cargo/`, "mast/", true},
+		{`!helm.txt`, "tiller.txt", false},
+		{`!*.txt`, "cargo", false},
+		{`!cargo/`, "mast/", false},
+		{"*.txt\n!helm.txt", "helm.txt", false},
+		{"*.txt\n!helm.txt", "tiller.txt", true},
+		{"!helm.txt\n*.txt", "helm.txt", true},
+		{"cargo/*\n!cargo/a.txt", "cargo/a.txt", false},
+		{"cargo/*\n!cargo/a.txt", "cargo/b.txt", true},
+		{"*.txt\n!cargo/", "cargo/a.txt", true},
 
 		// Absolute path tests
 		{`/a.txt`, "a.txt", true},
@@ -122,6 +128,76 @@ func TestIgnore(t *testing.T) {
 	}
 }
 
+func TestAddFile(t *testing.T) {
+	r, err := parseString("*.txt\n!a.txt\n")
+	require.NoError(t, err)
+	require.NoError(t, r.AddFile("cargo", filepath.Join(testdata, "cargo", HelmIgnore)))
+
+	tests := []struct {
+		name   string
+		expect bool
+		rule   string
+	}{
+		{"a.txt", false, "!a.txt"},
+		{"helm.txt", true, "*.txt"},
+		{"cargo/a.txt", true, "cargo/.helmignore:2: /a.txt"},
+		{"cargo/b.txt", false, "cargo/.helmignore:3: !b.txt"},
+		{"cargo/c.txt", true, "*.txt"},
+		{"mast/a.txt", false, "!a.txt"},
+		{"mast/b.txt", true, "*.txt"},
+		{"cargo", false, ""},
+	}
+	for _, tt := range tests {
+		fi, err := os.Stat(filepath.Join(testdata, tt.name))
+		require.NoError(t, err, "Fixture missing: %s", tt.name)
+
+		rule, ignored := r.IgnoredBy(tt.name, fi)
+		assert.Equal(t, tt.expect, ignored, "Expected %q to be %v", tt.name, tt.expect)
+		assert.Equal(t, tt.rule, rule, "Unexpected rule for %q", tt.name)
+		assert.Equal(t, tt.expect, r.Ignore(tt.name, fi))
+	}
+}
+
+func TestParseFileSource(t *testing.T) {
+	r, err := ParseFile(filepath.Join(testdata, HelmIgnore))
+	require.NoError(t, err)
+	fi, err := os.Stat(filepath.Join(testdata, "mast/a.txt"))
+	require.NoError(t, err)
+
+	rule, ignored := r.IgnoredBy("mast/a.txt", fi)
+	assert.True(t, ignored)
+	assert.Equal(t, ".helmignore:1: mast/a.txt", rule)
+}
+
+func TestIgnoredPath(t *testing.T) {
+	r, err := parseString("*.md\n")
+	require.NoError(t, err)
+	require.NoError(t, r.AddPatterns("--exclude", "tests/", "docs/*"))
+	require.NoError(t, r.AddPatterns("--include", "!docs/index.md"))
+
+	tests := []struct {
+		name   string
+		expect bool
+		rule   string
+	}{
+		{"README.md", true, "*.md"},
+		{"values.yaml", false, ""},
+		{"tests/values.yaml", true, "--exclude: tests/"},
+		{"templates/tests/pod.yaml", true, "--exclude: tests/"},
+		{"tests", false, ""},
+		{"tests/", true, "--exclude: tests/"},
+		{"docs/guide.txt", true, "--exclude: docs/*"},
+		{"docs/index.md", false, "--include: !docs/index.md"},
+	}
+	for _, tt := range tests {
+		rule, ignored := r.IgnoredPath(tt.name)
+		assert.Equal(t, tt.expect, ignored, "Expected %q to be %v", tt.name, tt.expect)
+		assert.Equal(t, tt.rule, rule, "Unexpected rule for %q", tt.name)
+	}
+
+	assert.EqualError(t, r.AddPatterns("--exclude", "docs/**"), `--exclude "docs/**": double-star (**) syntax is not supported`)
+}
+
 func TestAddDefaults(t *testing.T) {
 	r := Rules{}
 	r.AddDefaults()
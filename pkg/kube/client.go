@@ -30,6 +30,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	jsonpatch "github.com/evanphx/json-patch/v5"
 	v1 "k8s.io/api/core/v1"
@@ -268,6 +269,7 @@ type clientCreateOptions struct {
 	forceConflicts           bool
 	dryRun                   bool
 	fieldValidationDirective FieldValidationDirective
+	deadline                 time.Time
 }
 
 type ClientCreateOption func(*clientCreateOptions) error
@@ -312,6 +314,17 @@ func ClientCreateOptionFieldValidationDirective(fieldValidationDirective FieldVa
 	}
 }
 
+// ClientCreateOptionDeadline bounds the requests made to create the resources
+// by a deadline: the requests running when it passes are cancelled, and the
+// later ones fail. A zero deadline sets no bound.
+func ClientCreateOptionDeadline(deadline time.Time) ClientCreateOption {
+	return func(o *clientCreateOptions) error {
+		o.deadline = deadline
+
+		return nil
+	}
+}
+
 func (c *Client) makeCreateApplyFunc(serverSideApply, forceConflicts, dryRun bool, fieldValidationDirective FieldValidationDirective) CreateApplyFunc {
 	if serverSideApply {
 		c.Logger().Debug(
@@ -342,7 +355,8 @@ func (c *Client) makeCreateApplyFunc(serverSideApply, forceConflicts, dryRun boo
 	return createResource
 }
 
-// Create creates Kubernetes resources specified in the resource list.
+// Create creates Kubernetes resources specified in the resource list. When it
+// fails, the returned Result lists the resources that were created anyway.
 func (c *Client) Create(resources ResourceList, options ...ClientCreateOption) (*Result, error) {
 	c.Logger().Debug("creating resource(s)", "resources", len(resources))
 
@@ -364,8 +378,22 @@ func (c *Client) Create(resources ResourceList, options ...ClientCreateOption) (
 		createOptions.forceConflicts,
 		createOptions.dryRun,
 		createOptions.fieldValidationDirective)
-	if err := perform(resources, createApplyFunc); err != nil {
-		return nil, err
+	defer withDeadline(createOptions.deadline, resources)()
+	var mu sync.Mutex
+	var created ResourceList
+	err := perform(resources, func(info *resource.Info) error {
+		if err := createApplyFunc(info); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, info)
+		return nil
+	})
+	if err != nil {
+		// Report the resources that were created before the failure so that
+		// the caller can update them when it retries.
+		return &Result{Created: created}, applyError(err)
 	}
 	return &Result{Created: resources}, nil
 }
@@ -706,6 +734,7 @@ type clientUpdateOptions struct {
 	dryRun                        bool
 	fieldValidationDirective      FieldValidationDirective
 	upgradeClientSideFieldManager bool
+	deadline                      time.Time
 }
 
 type ClientUpdateOption func(*clientUpdateOptions) error
@@ -789,6 +818,17 @@ func ClientUpdateOptionUpgradeClientSideFieldManager(upgradeClientSideFieldManag
 	}
 }
 
+// ClientUpdateOptionDeadline bounds the requests made to update the resources
+// by a deadline: the requests running when it passes are cancelled, and the
+// later ones fail. A zero deadline sets no bound.
+func ClientUpdateOptionDeadline(deadline time.Time) ClientUpdateOption {
+	return func(o *clientUpdateOptions) error {
+		o.deadline = deadline
+
+		return nil
+	}
+}
+
 // Update takes the current list of objects and target list of objects and
 // creates resources that don't already exist, updates resources that have been
 // modified in the target configuration, and deletes resources from the current
@@ -895,7 +935,9 @@ func (c *Client) Update(originals, targets ResourceList, options ...ClientUpdate
 		}
 	}
 
-	return c.update(originals, targets, createApplyFunc, makeUpdateApplyFunc())
+	defer withDeadline(updateOptions.deadline, originals, targets)()
+	res, err := c.update(originals, targets, createApplyFunc, makeUpdateApplyFunc())
+	return res, applyError(err)
 }
 
 // Delete deletes Kubernetes resources specified in the resources list with
@@ -2385,3 +2385,30 @@ func createManifest(t *testing.T, manifest string,
 	err = fakeClient.Tracker().Create(mapping.Resource, obj, obj.GetNamespace())
 	require.NoError(t, err)
 }
+
+func TestCreateDeadline(t *testing.T) {
+	c := newTestClient(t)
+	pods := newPodList("starfish")
+	client := NewRequestResponseLogClient(t, func(_ []RequestResponseAction, req *http.Request) (*http.Response, error) {
+		if _, ok := req.Context().Deadline(); !ok {
+			t.Errorf("request %s %s has no deadline", req.Method, req.URL.Path)
+		}
+		return newResponse(http.StatusOK, &pods.Items[0])
+	})
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client:               fake.CreateHTTPClient(client.Do),
+	}
+
+	list, err := c.Build(objBody(&pods), false)
+	require.NoError(t, err)
+	_, err = c.Create(list, ClientCreateOptionDeadline(time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	require.Len(t, client.Actions, 1)
+	_, wrapped := list[0].Client.(*deadlineClient)
+	assert.False(t, wrapped, "the client of the resource is restored")
+
+	_, err = c.Create(list, ClientCreateOptionDeadline(time.Now().Add(-time.Second)))
+	require.Error(t, err)
+	assert.Len(t, client.Actions, 1, "no request is sent after the deadline")
+}
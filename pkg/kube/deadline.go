@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest"
+)
+
+// deadlineClient bounds the requests of a REST client by a deadline, so that
+// the requests still running when it passes are cancelled and the ones made
+// after it fail.
+type deadlineClient struct {
+	resource.RESTClient
+	deadline time.Time
+}
+
+func (c *deadlineClient) Get() *rest.Request    { return c.bound(c.RESTClient.Get()) }
+func (c *deadlineClient) Post() *rest.Request   { return c.bound(c.RESTClient.Post()) }
+func (c *deadlineClient) Delete() *rest.Request { return c.bound(c.RESTClient.Delete()) }
+func (c *deadlineClient) Put() *rest.Request    { return c.bound(c.RESTClient.Put()) }
+
+func (c *deadlineClient) Patch(pt types.PatchType) *rest.Request {
+	return c.bound(c.RESTClient.Patch(pt))
+}
+
+func (c *deadlineClient) bound(r *rest.Request) *rest.Request {
+	remaining := time.Until(c.deadline)
+	if remaining <= 0 {
+		// A zero timeout disables the timeout of the request.
+		remaining = time.Nanosecond
+	}
+	return r.Timeout(remaining)
+}
+
+// withDeadline bounds the requests made for the resources by the deadline,
+// until the returned function restores their clients. A zero deadline leaves
+// the resources unchanged.
+func withDeadline(deadline time.Time, lists ...ResourceList) (restore func()) {
+	if deadline.IsZero() {
+		return func() {}
+	}
+	clients := map[*resource.Info]resource.RESTClient{}
+	for _, list := range lists {
+		for _, info := range list {
+			if _, ok := clients[info]; ok || info.Client == nil {
+				continue
+			}
+			clients[info] = info.Client
+			info.Client = &deadlineClient{RESTClient: info.Client, deadline: deadline}
+		}
+	}
+	return func() {
+		for info, client := range clients {
+			info.Client = client
+		}
+	}
+}
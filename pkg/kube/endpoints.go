@@ -0,0 +1,180 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+// endpointsPollInterval is how often WaitForEndpoints checks the addresses.
+var endpointsPollInterval = 2 * time.Second
+
+// Endpoint is where a Service of type LoadBalancer or an Ingress can be
+// reached from outside of the cluster.
+type Endpoint struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Addresses are the hostnames and IP addresses of the load balancer.
+	Addresses []string `json:"addresses,omitempty"`
+	// Ports are the ports of a Service.
+	Ports []int32 `json:"ports,omitempty"`
+	// Hosts are the hosts of the rules of an Ingress.
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// WaitForEndpoints waits until every Service of type LoadBalancer and every
+// Ingress of the resources has been given an address, and returns their
+// endpoints in the order of the resources. Other resources are ignored.
+//
+// When the timeout expires first, the endpoints are returned with an error
+// naming those that have no address yet.
+func WaitForEndpoints(ctx context.Context, cl kubernetes.Interface, resources ResourceList, timeout time.Duration) ([]Endpoint, error) {
+	var infos []*resource.Info
+	for _, info := range resources {
+		if endpointKind(info) != "" {
+			infos = append(infos, info)
+		}
+	}
+	if len(infos) == 0 {
+		return nil, nil
+	}
+
+	endpoints := make([]*Endpoint, len(infos))
+	err := wait.PollUntilContextTimeout(ctx, endpointsPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		done := true
+		for i, info := range infos {
+			if endpoints[i] != nil && len(endpoints[i].Addresses) > 0 {
+				continue
+			}
+			endpoint, err := getEndpoint(ctx, cl, info)
+			if err != nil {
+				return false, err
+			}
+			endpoints[i] = endpoint
+			if endpoint != nil && len(endpoint.Addresses) == 0 {
+				done = false
+			}
+		}
+		return done, nil
+	})
+
+	var result []Endpoint
+	var pending []string
+	for i, endpoint := range endpoints {
+		switch {
+		case endpoint == nil:
+			// Services that are not load balancers.
+			if err != nil {
+				pending = append(pending, fmt.Sprintf("%s %s/%s", endpointKind(infos[i]), infos[i].Namespace, infos[i].Name))
+			}
+		case len(endpoint.Addresses) == 0:
+			pending = append(pending, fmt.Sprintf("%s %s/%s", endpoint.Kind, endpoint.Namespace, endpoint.Name))
+			result = append(result, *endpoint)
+		default:
+			result = append(result, *endpoint)
+		}
+	}
+	if err != nil {
+		if wait.Interrupted(err) || errors.Is(err, context.DeadlineExceeded) {
+			return result, fmt.Errorf("timed out waiting for the address of %s", strings.Join(pending, ", "))
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+// getEndpoint returns the endpoint of a Service or an Ingress, or nil for a
+// Service that is not a load balancer.
+func getEndpoint(ctx context.Context, cl kubernetes.Interface, info *resource.Info) (*Endpoint, error) {
+	endpoint := &Endpoint{Namespace: info.Namespace, Name: info.Name}
+	var lb []corev1.LoadBalancerIngress
+	if endpointKind(info) == "Service" {
+		svc, err := cl.CoreV1().Services(info.Namespace).Get(ctx, info.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			return nil, nil
+		}
+		endpoint.Kind = "Service"
+		for _, p := range svc.Spec.Ports {
+			endpoint.Ports = append(endpoint.Ports, p.Port)
+		}
+		lb = svc.Status.LoadBalancer.Ingress
+	} else {
+		ing, err := cl.NetworkingV1().Ingresses(info.Namespace).Get(ctx, info.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Kind = "Ingress"
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host != "" {
+				endpoint.Hosts = append(endpoint.Hosts, rule.Host)
+			}
+		}
+		for _, in := range ing.Status.LoadBalancer.Ingress {
+			lb = append(lb, corev1.LoadBalancerIngress{IP: in.IP, Hostname: in.Hostname})
+		}
+	}
+	for _, in := range lb {
+		if in.Hostname != "" {
+			endpoint.Addresses = append(endpoint.Addresses, in.Hostname)
+		}
+		if in.IP != "" {
+			endpoint.Addresses = append(endpoint.Addresses, in.IP)
+		}
+	}
+	return endpoint, nil
+}
+
+// endpointKind returns "Service" or "Ingress" for the resources that can have
+// an endpoint, and an empty string for the others.
+func endpointKind(info *resource.Info) string {
+	var gk schema.GroupKind
+	switch {
+	case info.Mapping != nil:
+		gk = info.Mapping.GroupVersionKind.GroupKind()
+	case info.Object != nil:
+		switch info.Object.(type) {
+		case *corev1.Service:
+			return "Service"
+		case *networkingv1.Ingress:
+			return "Ingress"
+		}
+		gk = info.Object.GetObjectKind().GroupVersionKind().GroupKind()
+	}
+	switch gk {
+	case schema.GroupKind{Kind: "Service"}:
+		return "Service"
+	case schema.GroupKind{Group: networkingv1.GroupName, Kind: "Ingress"}:
+		return "Ingress"
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWaitForEndpoints(t *testing.T) {
+	defer func(interval time.Duration) { endpointsPollInterval = interval }(endpointsPollInterval)
+	endpointsPollInterval = 10 * time.Millisecond
+
+	lb := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: defaultNamespace},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeLoadBalancer,
+			Ports: []corev1.ServicePort{{Port: 80}, {Port: 443}},
+		},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+			Ingress: []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}},
+		}},
+	}
+	internal := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: defaultNamespace},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+	}
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: defaultNamespace},
+		Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{
+			{Host: "web.example.com"},
+		}},
+		Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+			Ingress: []networkingv1.IngressLoadBalancerIngress{{Hostname: "lb.example.net"}},
+		}},
+	}
+	pending := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: defaultNamespace},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+	info := func(obj runtime.Object) *resource.Info {
+		m := obj.(metav1.Object)
+		return &resource.Info{Object: obj, Name: m.GetName(), Namespace: m.GetNamespace()}
+	}
+
+	t.Run("addresses assigned", func(t *testing.T) {
+		cl := fake.NewClientset(lb, internal, ing)
+		endpoints, err := WaitForEndpoints(context.Background(), cl, ResourceList{info(lb), info(internal), info(ing), info(&corev1.ConfigMap{})}, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, []Endpoint{
+			{Kind: "Service", Namespace: defaultNamespace, Name: "web", Addresses: []string{"203.0.113.10"}, Ports: []int32{80, 443}},
+			{Kind: "Ingress", Namespace: defaultNamespace, Name: "web", Addresses: []string{"lb.example.net"}, Hosts: []string{"web.example.com"}},
+		}, endpoints)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		cl := fake.NewClientset(lb, pending)
+		endpoints, err := WaitForEndpoints(context.Background(), cl, ResourceList{info(lb), info(pending)}, 50*time.Millisecond)
+		require.EqualError(t, err, "timed out waiting for the address of Service default/pending")
+		assert.Len(t, endpoints, 2)
+		assert.Empty(t, endpoints[1].Addresses)
+	})
+
+	t.Run("no endpoints", func(t *testing.T) {
+		endpoints, err := WaitForEndpoints(context.Background(), fake.NewClientset(), ResourceList{info(&corev1.ConfigMap{})}, time.Second)
+		require.NoError(t, err)
+		assert.Empty(t, endpoints)
+	})
+}
@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	// ErrApplyConflict classifies errors returned by Create and Update when a
+	// resource conflicts with an object that already exists in the cluster.
+	ErrApplyConflict = errors.New("conflict applying resources")
+	// ErrWaitTimeout classifies errors returned by the waiters when the
+	// resources did not reach the desired state before the timeout.
+	ErrWaitTimeout = errors.New("timed out waiting for resources")
+)
+
+// classifiedError marks an error as belonging to a class of failures, so that
+// errors.Is matches the class, without changing the message of the error.
+type classifiedError struct {
+	err   error
+	class error
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+
+func (e *classifiedError) Unwrap() []error { return []error{e.err, e.class} }
+
+// Classify marks err as belonging to class, so that errors.Is matches the
+// class, without changing the message of err. A nil err stays nil.
+func Classify(err, class error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, class: class}
+}
+
+// applyError classifies the errors of Create and Update.
+func applyError(err error) error {
+	if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+		return Classify(err, ErrApplyConflict)
+	}
+	return err
+}
+
+// waitError classifies the errors of the waiters. Cancellations of the
+// context by the caller are not considered a timeout.
+func waitError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || (wait.Interrupted(err) && !errors.Is(err, context.Canceled)) {
+		return Classify(err, ErrWaitTimeout)
+	}
+	return err
+}
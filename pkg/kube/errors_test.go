@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestErrorClasses(t *testing.T) {
+	gr := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		name     string
+		err      error
+		classify func(error) error
+		class    error
+	}{
+		{"conflict", apierrors.NewConflict(gr, "foo", errors.New("boom")), applyError, ErrApplyConflict},
+		{"already exists", fmt.Errorf("create: %w", apierrors.NewAlreadyExists(gr, "foo")), applyError, ErrApplyConflict},
+		{"other apply error", apierrors.NewBadRequest("boom"), applyError, nil},
+		{"deadline", errors.Join(errors.New("not ready"), context.DeadlineExceeded), waitError, ErrWaitTimeout},
+		{"watch timeout", wait.ErrorInterrupted(errors.New("timed out waiting for the condition")), waitError, ErrWaitTimeout},
+		{"canceled", context.Canceled, waitError, nil},
+		{"nil", nil, waitError, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.classify(tt.err)
+			if tt.class == nil {
+				assert.Equal(t, tt.err, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.class)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.err.Error(), err.Error())
+		})
+	}
+}
@@ -0,0 +1,300 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/scheme"
+	restfake "k8s.io/client-go/rest/fake"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// Call is a call made to a ScriptedKubeClient or to one of its waiters.
+type Call struct {
+	// Method is the name of the method called, such as "Create" or "Wait".
+	Method string
+	// Resources are the resources the method was called with. For Update,
+	// they are the target resources.
+	Resources kube.ResourceList
+}
+
+// ScriptedKubeClient implements kube.Interface without a cluster, so that the
+// actions can be unit tested. Build parses manifests into unstructured
+// objects. The other methods succeed and report the resources as created,
+// updated or deleted, unless the function scripting the method is set, in
+// which case its result is returned. All the calls are recorded.
+type ScriptedKubeClient struct {
+	// Namespace is set on the namespaced objects built without a namespace.
+	Namespace string
+
+	IsReachableFunc     func() error
+	BuildFunc           func(r io.Reader, validate bool) (kube.ResourceList, error)
+	GetFunc             func(resources kube.ResourceList, related bool) (map[string][]runtime.Object, error)
+	CreateFunc          func(resources kube.ResourceList) (*kube.Result, error)
+	UpdateFunc          func(original, target kube.ResourceList) (*kube.Result, error)
+	DeleteFunc          func(resources kube.ResourceList) (*kube.Result, []error)
+	WaitFunc            func(resources kube.ResourceList, timeout time.Duration) error
+	WaitForDeleteFunc   func(resources kube.ResourceList, timeout time.Duration) error
+	WatchUntilReadyFunc func(resources kube.ResourceList, timeout time.Duration) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ kube.Interface = &ScriptedKubeClient{}
+
+// Calls returns the calls made to the client and its waiters, in order.
+func (c *ScriptedKubeClient) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.calls)
+}
+
+// CallsTo returns the calls made to a method.
+func (c *ScriptedKubeClient) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range c.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (c *ScriptedKubeClient) record(method string, resources kube.ResourceList) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Resources: resources})
+}
+
+// IsReachable returns the result of IsReachableFunc, or nil.
+func (c *ScriptedKubeClient) IsReachable() error {
+	c.record("IsReachable", nil)
+	if c.IsReachableFunc != nil {
+		return c.IsReachableFunc()
+	}
+	return nil
+}
+
+// Build returns the result of BuildFunc, or the objects of the manifest.
+func (c *ScriptedKubeClient) Build(r io.Reader, validate bool) (kube.ResourceList, error) {
+	if c.BuildFunc != nil {
+		resources, err := c.BuildFunc(r, validate)
+		c.record("Build", resources)
+		return resources, err
+	}
+	resources, err := c.build(r)
+	c.record("Build", resources)
+	return resources, err
+}
+
+// BuildTable builds the resources like Build.
+func (c *ScriptedKubeClient) BuildTable(r io.Reader, validate bool) (kube.ResourceList, error) {
+	return c.Build(r, validate)
+}
+
+// Get returns the result of GetFunc, or no objects.
+func (c *ScriptedKubeClient) Get(resources kube.ResourceList, related bool) (map[string][]runtime.Object, error) {
+	c.record("Get", resources)
+	if c.GetFunc != nil {
+		return c.GetFunc(resources, related)
+	}
+	return map[string][]runtime.Object{}, nil
+}
+
+// Create returns the result of CreateFunc, or reports the resources as
+// created.
+func (c *ScriptedKubeClient) Create(resources kube.ResourceList, _ ...kube.ClientCreateOption) (*kube.Result, error) {
+	c.record("Create", resources)
+	if c.CreateFunc != nil {
+		return c.CreateFunc(resources)
+	}
+	return &kube.Result{Created: resources}, nil
+}
+
+// Update returns the result of UpdateFunc, or reports the target resources
+// that are not in original as created, the others as updated, and the
+// original resources that are not in target as deleted.
+func (c *ScriptedKubeClient) Update(original, target kube.ResourceList, _ ...kube.ClientUpdateOption) (*kube.Result, error) {
+	c.record("Update", target)
+	if c.UpdateFunc != nil {
+		return c.UpdateFunc(original, target)
+	}
+	return &kube.Result{
+		Created: target.Difference(original),
+		Updated: target.Intersect(original),
+		Deleted: original.Difference(target),
+	}, nil
+}
+
+// Delete returns the result of DeleteFunc, or reports the resources as
+// deleted.
+func (c *ScriptedKubeClient) Delete(resources kube.ResourceList, _ metav1.DeletionPropagation) (*kube.Result, []error) {
+	c.record("Delete", resources)
+	if c.DeleteFunc != nil {
+		return c.DeleteFunc(resources)
+	}
+	return &kube.Result{Deleted: resources}, nil
+}
+
+// GetPodList returns no pods.
+func (c *ScriptedKubeClient) GetPodList(_ string, _ metav1.ListOptions) (*v1.PodList, error) {
+	c.record("GetPodList", nil)
+	return &v1.PodList{}, nil
+}
+
+// OutputContainerLogsForPodList outputs nothing.
+func (c *ScriptedKubeClient) OutputContainerLogsForPodList(_ *v1.PodList, _ string, _ func(namespace, pod, container string) io.Writer) error {
+	return nil
+}
+
+// GetWaiter returns a waiter that records its calls to the client.
+func (c *ScriptedKubeClient) GetWaiter(ws kube.WaitStrategy) (kube.Waiter, error) {
+	return c.GetWaiterWithOptions(ws)
+}
+
+// GetWaiterWithOptions returns a waiter that records its calls to the client.
+func (c *ScriptedKubeClient) GetWaiterWithOptions(_ kube.WaitStrategy, _ ...kube.WaitOption) (kube.Waiter, error) {
+	return &scriptedWaiter{client: c}, nil
+}
+
+// scriptedWaiter implements kube.Waiter for a ScriptedKubeClient.
+type scriptedWaiter struct {
+	client *ScriptedKubeClient
+}
+
+func (w *scriptedWaiter) wait(method string, fn func(kube.ResourceList, time.Duration) error, resources kube.ResourceList, timeout time.Duration) error {
+	w.client.record(method, resources)
+	if fn != nil {
+		return fn(resources, timeout)
+	}
+	return nil
+}
+
+func (w *scriptedWaiter) Wait(resources kube.ResourceList, timeout time.Duration) error {
+	return w.wait("Wait", w.client.WaitFunc, resources, timeout)
+}
+
+func (w *scriptedWaiter) WaitWithJobs(resources kube.ResourceList, timeout time.Duration) error {
+	return w.wait("WaitWithJobs", w.client.WaitFunc, resources, timeout)
+}
+
+func (w *scriptedWaiter) WaitForDelete(resources kube.ResourceList, timeout time.Duration) error {
+	return w.wait("WaitForDelete", w.client.WaitForDeleteFunc, resources, timeout)
+}
+
+func (w *scriptedWaiter) WatchUntilReady(resources kube.ResourceList, timeout time.Duration) error {
+	return w.wait("WatchUntilReady", w.client.WatchUntilReadyFunc, resources, timeout)
+}
+
+// clusterScopedKinds are the built-in kinds that Build does not set a
+// namespace on.
+var clusterScopedKinds = []string{
+	"APIService",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"CustomResourceDefinition",
+	"IngressClass",
+	"MutatingWebhookConfiguration",
+	"Namespace",
+	"Node",
+	"PersistentVolume",
+	"PriorityClass",
+	"RuntimeClass",
+	"StorageClass",
+	"ValidatingAdmissionPolicy",
+	"ValidatingAdmissionPolicyBinding",
+	"ValidatingWebhookConfiguration",
+}
+
+// build parses the objects of a manifest. Their mappings are guessed from
+// their kinds, as there is no cluster to discover them from, and their REST
+// clients find no object, as if none of them existed yet.
+func (c *ScriptedKubeClient) build(r io.Reader) (kube.ResourceList, error) {
+	var resources kube.ResourceList
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return resources, nil
+			}
+			return nil, fmt.Errorf("unable to parse the manifest: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		if gvk.Kind == "" {
+			return nil, fmt.Errorf("object %q has no kind", obj.GetName())
+		}
+		scope := meta.RESTScopeNamespace
+		if slices.Contains(clusterScopedKinds, gvk.Kind) {
+			scope = meta.RESTScopeRoot
+		} else if obj.GetNamespace() == "" {
+			obj.SetNamespace(c.Namespace)
+		}
+		plural, _ := meta.UnsafeGuessKindToResource(gvk)
+		resources.Append(&resource.Info{
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Object:    obj,
+			Client:    notFoundClient(gvk.GroupVersion()),
+			Mapping: &meta.RESTMapping{
+				Resource:         plural,
+				GroupVersionKind: gvk,
+				Scope:            scope,
+			},
+		})
+	}
+}
+
+// notFoundClient returns a REST client answering every request with a
+// NotFound status.
+func notFoundClient(gv schema.GroupVersion) *restfake.RESTClient {
+	return &restfake.RESTClient{
+		GroupVersion:         gv,
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("Content-Type", runtime.ContentTypeJSON)
+			body := fmt.Sprintf(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404,"message":"%s not found"}`, req.URL.Path)
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     header,
+				Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		}),
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+const scriptedManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: other
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: team
+`
+
+func TestScriptedKubeClientBuild(t *testing.T) {
+	c := &ScriptedKubeClient{Namespace: "default"}
+	resources, err := c.Build(strings.NewReader(scriptedManifest), true)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "settings", resources[0].Name)
+	assert.Equal(t, "default", resources[0].Namespace)
+	assert.Equal(t, "configmaps", resources[0].Mapping.Resource.Resource)
+	assert.Equal(t, "other", resources[1].Namespace)
+	assert.Equal(t, "apps", resources[1].Mapping.GroupVersionKind.Group)
+	assert.Empty(t, resources[2].Namespace)
+
+	_, err = c.Build(strings.NewReader("metadata:\n  name: nokind\n"), false)
+	assert.ErrorContains(t, err, "has no kind")
+}
+
+func TestScriptedKubeClientScripts(t *testing.T) {
+	c := &ScriptedKubeClient{Namespace: "default"}
+	resources, err := c.Build(strings.NewReader(scriptedManifest), false)
+	require.NoError(t, err)
+
+	result, err := c.Update(resources[:2], resources[1:])
+	require.NoError(t, err)
+	assert.Equal(t, kube.ResourceList{resources[2]}, result.Created)
+	assert.Equal(t, kube.ResourceList{resources[1]}, result.Updated)
+	assert.Equal(t, kube.ResourceList{resources[0]}, result.Deleted)
+
+	c.WaitFunc = func(kube.ResourceList, time.Duration) error {
+		return errors.New("not ready")
+	}
+	waiter, err := c.GetWaiter(kube.StatusWatcherStrategy)
+	require.NoError(t, err)
+	assert.EqualError(t, waiter.Wait(resources, time.Second), "not ready")
+
+	var methods []string
+	for _, call := range c.Calls() {
+		methods = append(methods, call.Method)
+	}
+	assert.Equal(t, []string{"Build", "Update", "Wait"}, methods)
+	assert.Len(t, c.CallsTo("Wait")[0].Resources, 3)
+}
@@ -170,7 +170,7 @@ func (w *statusWaiter) waitForDelete(ctx context.Context, resourceList ResourceL
 		errs = append(errs, err)
 	}
 	if len(errs) > 0 {
-		return errors.Join(errs...)
+		return waitError(errors.Join(errs...))
 	}
 	return nil
 }
@@ -217,7 +217,7 @@ func (w *statusWaiter) wait(ctx context.Context, resourceList ResourceList, sw w
 		errs = append(errs, err)
 	}
 	if len(errs) > 0 {
-		return errors.Join(errs...)
+		return waitError(errors.Join(errs...))
 	}
 	return nil
 }
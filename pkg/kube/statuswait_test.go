@@ -440,6 +440,7 @@ func TestStatusWait(t *testing.T) {
 			err := statusWaiter.Wait(resourceList, time.Second*3)
 			if tt.expectErrStrs != nil {
 				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrWaitTimeout)
 				for _, expectedErrStr := range tt.expectErrStrs {
 					assert.Contains(t, err.Error(), expectedErrStr)
 				}
@@ -76,7 +76,7 @@ func (hw *legacyWaiter) waitForResources(created ResourceList, timeout time.Dura
 		numberOfErrors[i] = 0
 	}
 
-	return wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+	err := wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
 		waitRetries := 30
 		for i, v := range created {
 			ready, err := hw.c.IsReady(ctx, v)
@@ -97,6 +97,7 @@ func (hw *legacyWaiter) waitForResources(created ResourceList, timeout time.Dura
 		}
 		return true, nil
 	})
+	return waitError(err)
 }
 
 func (hw *legacyWaiter) isRetryableError(err error, resource *resource.Info) bool {
@@ -153,7 +154,7 @@ func (hw *legacyWaiter) WaitForDelete(deleted ResourceList, timeout time.Duratio
 		slog.Debug("wait for resources succeeded", slog.Duration("elapsed", elapsed))
 	}
 
-	return err
+	return waitError(err)
 }
 
 // SelectorsForObject returns the pod label selector for a given object
@@ -290,7 +291,7 @@ func (hw *legacyWaiter) watchUntilReady(timeout time.Duration, info *resource.In
 			return false, nil
 		}
 	})
-	return err
+	return waitError(err)
 }
 
 // waitForJob is a helper that waits for a job to complete.
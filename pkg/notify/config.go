@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The types of sinks of SinkConfig.
+const (
+	SinkWebhook = "webhook"
+	SinkSlack   = "slack"
+)
+
+// Config is the notification configuration file of Helm.
+//
+//	sinks:
+//	- type: slack
+//	  url: https://hooks.slack.com/services/...
+//	  failuresOnly: true
+//	- type: webhook
+//	  url: https://deploys.example.com/helm
+//	  headers:
+//	    Authorization: Bearer ...
+//	  operations: [install, upgrade]
+type Config struct {
+	Sinks []SinkConfig `json:"sinks,omitempty"`
+}
+
+// SinkConfig configures a sink of Config.
+type SinkConfig struct {
+	// Type is SinkWebhook or SinkSlack.
+	Type string `json:"type"`
+	// URL is the URL the notifications are posted to.
+	URL string `json:"url"`
+	// Headers are added to the requests of webhook sinks.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Operations restricts the sink to these operations.
+	Operations []string `json:"operations,omitempty"`
+	// FailuresOnly restricts the sink to the failed operations.
+	FailuresOnly bool `json:"failuresOnly,omitempty"`
+}
+
+// LoadConfig reads the configuration file at path. A missing file is an
+// empty configuration.
+func LoadConfig(path string) (*Config, error) {
+	c := &Config{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Notifier creates the notifier sending to the sinks of c.
+func (c *Config) Notifier() (*Notifier, error) {
+	n := New()
+	for i, sc := range c.Sinks {
+		if sc.URL == "" {
+			return nil, fmt.Errorf("sink %d: url is required", i)
+		}
+		var s Sink
+		switch sc.Type {
+		case SinkWebhook, "":
+			s = &Webhook{URL: sc.URL, Headers: sc.Headers}
+		case SinkSlack:
+			s = &Slack{URL: sc.URL}
+		default:
+			return nil, fmt.Errorf("sink %d: unknown type %q", i, sc.Type)
+		}
+		if len(sc.Operations) > 0 || sc.FailuresOnly {
+			s = &Filter{Sink: s, Operations: sc.Operations, FailuresOnly: sc.FailuresOnly}
+		}
+		n.Sinks = append(n.Sinks, s)
+	}
+	return n, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notify sends notifications about the operations run on releases,
+// such as installs and upgrades, to sinks like generic webhooks and Slack
+// incoming webhooks.
+package notify // import "helm.sh/helm/v4/pkg/notify"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// The outcomes of an operation.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// DefaultTimeout is how long a sink is given to accept a notification.
+const DefaultTimeout = 10 * time.Second
+
+// Event describes an operation that completed on a release.
+type Event struct {
+	// Operation is the operation, such as install or upgrade.
+	Operation string `json:"operation"`
+	// Outcome is OutcomeSuccess or OutcomeFailure.
+	Outcome string `json:"outcome"`
+	// Error is the error of a failed operation.
+	Error string `json:"error,omitempty"`
+	// Release is the name of the release.
+	Release string `json:"release"`
+	// Namespace is the namespace of the release.
+	Namespace string `json:"namespace,omitempty"`
+	// Revision is the revision the operation created or removed.
+	Revision int `json:"revision,omitempty"`
+	// Status is the status of the revision once the operation completed.
+	Status string `json:"status,omitempty"`
+	// Chart is the name of the chart of the release.
+	Chart string `json:"chart,omitempty"`
+	// ChartVersion is the version of the chart of the release.
+	ChartVersion string `json:"chart_version,omitempty"`
+	// AppVersion is the version of the application of the chart.
+	AppVersion string `json:"app_version,omitempty"`
+	// Duration is how long the operation took.
+	Duration time.Duration `json:"duration"`
+	// Time is when the operation completed.
+	Time time.Time `json:"time"`
+}
+
+// Sink receives the notifications.
+type Sink interface {
+	// Notify sends the event. It returns when the sink accepted it.
+	Notify(ctx context.Context, e Event) error
+}
+
+// Notifier fans the events out to its sinks.
+type Notifier struct {
+	// Sinks are the sinks the events are sent to.
+	Sinks []Sink
+	// Timeout limits how long each sink is given. DefaultTimeout is used
+	// when it is zero.
+	Timeout time.Duration
+}
+
+// New creates a Notifier sending to sinks.
+func New(sinks ...Sink) *Notifier {
+	return &Notifier{Sinks: sinks}
+}
+
+// Notify sends e to every sink. A failing sink does not prevent the others
+// from being notified; the errors of all sinks are returned together.
+//
+// It is a no-op on a nil Notifier.
+func (n *Notifier) Notify(ctx context.Context, e Event) error {
+	if n == nil {
+		return nil
+	}
+	timeout := n.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	var errs []error
+	for _, s := range n.Sinks {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		if err := s.Notify(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// Filter only passes the events of the given operations to Sink. All
+// events are passed when Operations is empty.
+type Filter struct {
+	Sink
+	// Operations are the operations passed to Sink.
+	Operations []string
+	// FailuresOnly only passes the failed operations to Sink.
+	FailuresOnly bool
+}
+
+// Notify sends e to the filtered sink when it matches the filter.
+func (f *Filter) Notify(ctx context.Context, e Event) error {
+	if len(f.Operations) > 0 && !slices.Contains(f.Operations, e.Operation) {
+		return nil
+	}
+	if f.FailuresOnly && e.Outcome != OutcomeFailure {
+		return nil
+	}
+	return f.Sink.Notify(ctx, e)
+}
+
+// summary is a one line description of e for the chat sinks.
+func summary(e Event) string {
+	name := e.Release
+	if e.Namespace != "" {
+		name = e.Namespace + "/" + name
+	}
+	s := fmt.Sprintf("Helm %s of %s", e.Operation, name)
+	if e.Revision > 0 {
+		s += fmt.Sprintf(" (revision %d)", e.Revision)
+	}
+	if e.Chart != "" {
+		s += fmt.Sprintf(" with chart %s-%s", e.Chart, e.ChartVersion)
+	}
+	if e.Outcome == OutcomeFailure {
+		return s + " failed: " + e.Error
+	}
+	return s + " succeeded"
+}
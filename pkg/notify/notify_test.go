@@ -0,0 +1,206 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(outcome string) Event {
+	e := Event{
+		Operation:    "upgrade",
+		Outcome:      outcome,
+		Release:      "web",
+		Namespace:    "prod",
+		Revision:     3,
+		Status:       "deployed",
+		Chart:        "nginx",
+		ChartVersion: "1.2.3",
+		Duration:     2 * time.Second,
+		Time:         time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if outcome == OutcomeFailure {
+		e.Status = "failed"
+		e.Error = "timed out"
+	}
+	return e
+}
+
+// receiver records the bodies and headers posted to it.
+func receiver(t *testing.T, status int) (*httptest.Server, *[]*http.Request, *[][]byte) {
+	t.Helper()
+	var reqs []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&b))
+		reqs = append(reqs, r)
+		bodies = append(bodies, b)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reqs, &bodies
+}
+
+func TestWebhook(t *testing.T) {
+	srv, reqs, bodies := receiver(t, http.StatusNoContent)
+	w := &Webhook{URL: srv.URL + "/hook", Headers: map[string]string{"Authorization": "Bearer token"}}
+	require.NoError(t, w.Notify(t.Context(), testEvent(OutcomeSuccess)))
+
+	require.Len(t, *reqs, 1)
+	r := (*reqs)[0]
+	assert.Equal(t, http.MethodPost, r.Method)
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+	var got Event
+	require.NoError(t, json.Unmarshal((*bodies)[0], &got))
+	assert.Equal(t, testEvent(OutcomeSuccess), got)
+}
+
+func TestSlack(t *testing.T) {
+	srv, _, bodies := receiver(t, http.StatusOK)
+	s := &Slack{URL: srv.URL}
+	require.NoError(t, s.Notify(t.Context(), testEvent(OutcomeSuccess)))
+	require.NoError(t, s.Notify(t.Context(), testEvent(OutcomeFailure)))
+
+	var msg slackMessage
+	require.NoError(t, json.Unmarshal((*bodies)[0], &msg))
+	assert.Equal(t, ":white_check_mark: Helm upgrade of prod/web (revision 3) with chart nginx-1.2.3 succeeded", msg.Text)
+	require.NoError(t, json.Unmarshal((*bodies)[1], &msg))
+	assert.Equal(t, ":x: Helm upgrade of prod/web (revision 3) with chart nginx-1.2.3 failed: timed out", msg.Text)
+}
+
+func TestWebhookRejected(t *testing.T) {
+	srv, _, _ := receiver(t, http.StatusInternalServerError)
+	w := &Webhook{URL: srv.URL + "/services/secret"}
+	err := w.Notify(t.Context(), testEvent(OutcomeSuccess))
+	require.ErrorContains(t, err, "500 Internal Server Error")
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+type sinkFunc func(context.Context, Event) error
+
+func (f sinkFunc) Notify(ctx context.Context, e Event) error { return f(ctx, e) }
+
+func TestNotifier(t *testing.T) {
+	var got []string
+	record := func(name string, err error) Sink {
+		return sinkFunc(func(_ context.Context, e Event) error {
+			got = append(got, name+":"+e.Operation)
+			return err
+		})
+	}
+	n := New(
+		record("a", errors.New("a failed")),
+		&Filter{Sink: record("b", nil), Operations: []string{"install"}},
+		&Filter{Sink: record("c", nil), FailuresOnly: true},
+		record("d", errors.New("d failed")),
+	)
+
+	err := n.Notify(t.Context(), testEvent(OutcomeSuccess))
+	require.ErrorContains(t, err, "a failed")
+	require.ErrorContains(t, err, "d failed")
+	assert.Equal(t, []string{"a:upgrade", "d:upgrade"}, got)
+
+	got = nil
+	e := testEvent(OutcomeFailure)
+	e.Operation = "install"
+	_ = n.Notify(t.Context(), e)
+	assert.Equal(t, []string{"a:install", "b:install", "c:install", "d:install"}, got)
+
+	var nilNotifier *Notifier
+	assert.NoError(t, nilNotifier.Notify(t.Context(), e))
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := LoadConfig(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, c.Sinks)
+
+	tests := []struct {
+		name    string
+		config  string
+		sinks   []Sink
+		wantErr string
+	}{
+		{
+			name: "sinks",
+			config: `sinks:
+- type: slack
+  url: https://hooks.example.com/slack
+  failuresOnly: true
+- url: https://deploys.example.com/helm
+  headers:
+    Authorization: Bearer token
+  operations: [install]
+`,
+			sinks: []Sink{
+				&Filter{Sink: &Slack{URL: "https://hooks.example.com/slack"}, FailuresOnly: true},
+				&Filter{
+					Sink:       &Webhook{URL: "https://deploys.example.com/helm", Headers: map[string]string{"Authorization": "Bearer token"}},
+					Operations: []string{"install"},
+				},
+			},
+		},
+		{
+			name:    "unknown type",
+			config:  "sinks:\n- type: pager\n  url: https://example.com\n",
+			wantErr: `sink 0: unknown type "pager"`,
+		},
+		{
+			name:    "missing url",
+			config:  "sinks:\n- type: slack\n",
+			wantErr: "sink 0: url is required",
+		},
+		{
+			name:    "unknown field",
+			config:  "sinks:\n- type: slack\n  uri: https://example.com\n",
+			wantErr: "could not parse",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "notifications.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.config), 0o644))
+			c, err := LoadConfig(path)
+			var n *Notifier
+			if err == nil {
+				n, err = c.Notifier()
+			}
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.sinks, n.Sinks)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"helm.sh/helm/v4/internal/version"
+)
+
+// Webhook posts the events as JSON to a URL.
+type Webhook struct {
+	// URL is the URL the events are posted to.
+	URL string
+	// Headers are added to the requests, for instance to authenticate.
+	Headers map[string]string
+	// Client sends the requests. http.DefaultClient is used when it is nil.
+	Client *http.Client
+}
+
+// Notify posts e to the webhook.
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	return post(ctx, w.Client, w.URL, w.Headers, e)
+}
+
+// Slack posts the events as messages to a Slack incoming webhook, or to any
+// chat service accepting its payload.
+type Slack struct {
+	// URL is the URL of the incoming webhook.
+	URL string
+	// Client sends the requests. http.DefaultClient is used when it is nil.
+	Client *http.Client
+}
+
+// slackMessage is the payload of a Slack incoming webhook.
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Notify posts a message describing e to the incoming webhook.
+func (s *Slack) Notify(ctx context.Context, e Event) error {
+	text := summary(e)
+	if e.Outcome == OutcomeFailure {
+		text = ":x: " + text
+	} else {
+		text = ":white_check_mark: " + text
+	}
+	return post(ctx, s.Client, s.URL, nil, slackMessage{Text: text})
+}
+
+// post sends payload as JSON to url and fails unless it is accepted.
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.GetUserAgent())
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// The URLs of the webhooks often embed their secret, so only the host
+	// is reported.
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not notify %s: %w", req.URL.Host, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("could not notify %s: %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
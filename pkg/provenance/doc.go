@@ -34,5 +34,11 @@ and using `gpg --verify`, `keybase pgp verify`, or similar:
 	$  gpg --verify some.sig
 	gpg: Signature made Mon Jul 25 17:23:44 2016 MDT using RSA key ID 1FC18762
 	gpg: Good signature from "Helm Testing (This key should only be used for testing. DO NOT TRUST.) <helm-testing@helm.sh>" [ultimate]
+
+Provenance files of APIVersionV2 instead hold a statement naming the package by
+the digest of its archive, and any number of detached signatures of that
+statement, so that several parties can sign a package. The statement may have
+an expiry. VerifyReport verifies both kinds of provenance files and lists every
+signer with its trust status.
 */
 package provenance // import "helm.sh/helm/v4/pkg/provenance"
@@ -0,0 +1,219 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"sigs.k8s.io/yaml"
+)
+
+// ReadKeys reads public keys, either ASCII armored or binary.
+func ReadKeys(data []byte) (openpgp.EntityList, error) {
+	var (
+		keys openpgp.EntityList
+		err  error
+	)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		keys, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		keys, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("invalid keys: no key found")
+	}
+	return keys, nil
+}
+
+// LoadKeyring loads the public keys of a keyring file. A keyring that does not
+// exist yet is empty.
+func LoadKeyring(path string) (openpgp.EntityList, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && len(data) == 0) {
+		return openpgp.EntityList{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ReadKeys(data)
+}
+
+// SaveKeyring writes the public parts of keys to a binary keyring file, as
+// GnuPG does, creating its directory if needed.
+func SaveKeyring(path string, keys openpgp.EntityList) error {
+	var buf bytes.Buffer
+	for _, e := range keys {
+		if err := e.Serialize(&buf); err != nil {
+			return fmt.Errorf("failed to serialize key %s: %w", Fingerprint(e), err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// Fingerprint returns the fingerprint of a key in upper case hexadecimal.
+func Fingerprint(e *openpgp.Entity) string {
+	return fmt.Sprintf("%X", e.PrimaryKey.Fingerprint)
+}
+
+// MatchKey reports whether a key has the given fingerprint or key ID, in
+// hexadecimal, or an identity containing it.
+func MatchKey(e *openpgp.Entity, id string) bool {
+	hexID := strings.ToUpper(strings.TrimPrefix(strings.ReplaceAll(id, " ", ""), "0x"))
+	if len(hexID) >= 8 && strings.HasSuffix(Fingerprint(e), hexID) {
+		return true
+	}
+	for name := range e.Identities {
+		if strings.Contains(name, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrustPolicyAPIVersion is the API version of trust policy files.
+const TrustPolicyAPIVersion = "v1"
+
+// TrustPolicy restricts the keys trusted to sign the charts of repositories.
+//
+// Repositories maps a repository to the fingerprints of the keys trusted for
+// it. A repository is either the name of a chart repository, used in
+// 'repo/chart' references, or a prefix of chart URLs, such as
+// oci://example.com/charts. Charts of other repositories may be signed by any
+// key of the keyring.
+type TrustPolicy struct {
+	APIVersion   string              `json:"apiVersion"`
+	Repositories map[string][]string `json:"repositories,omitempty"`
+}
+
+// LoadTrustPolicy loads a trust policy file. A trust policy that does not
+// exist yet is empty.
+func LoadTrustPolicy(path string) (*TrustPolicy, error) {
+	t := &TrustPolicy{APIVersion: TrustPolicyAPIVersion}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(data, t); err != nil {
+		return nil, fmt.Errorf("invalid trust policy %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// WriteFile writes the trust policy to a file, creating its directory if
+// needed.
+func (t *TrustPolicy) WriteFile(path string, perm os.FileMode) error {
+	if t.APIVersion == "" {
+		t.APIVersion = TrustPolicyAPIVersion
+	}
+	data, err := yaml.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// Trust adds a key to the keys trusted for a repository.
+func (t *TrustPolicy) Trust(repository, fingerprint string) {
+	if t.Repositories == nil {
+		t.Repositories = map[string][]string{}
+	}
+	if !slices.Contains(t.Repositories[repository], fingerprint) {
+		t.Repositories[repository] = append(t.Repositories[repository], fingerprint)
+		slices.Sort(t.Repositories[repository])
+	}
+}
+
+// Untrust removes a key from the keys trusted for a repository. With an empty
+// repository, the key is removed from all repositories. It reports whether the
+// key was trusted.
+func (t *TrustPolicy) Untrust(repository, fingerprint string) bool {
+	found := false
+	for name, keys := range t.Repositories {
+		if repository != "" && name != repository {
+			continue
+		}
+		if i := slices.Index(keys, fingerprint); i >= 0 {
+			found = true
+			keys = slices.Delete(keys, i, i+1)
+			if len(keys) == 0 {
+				delete(t.Repositories, name)
+			} else {
+				t.Repositories[name] = keys
+			}
+		}
+	}
+	return found
+}
+
+// Keys returns the fingerprints of the keys trusted for a chart reference, and
+// whether the trust policy restricts them. A repository name matches
+// 'name/chart' references, and a URL matches the chart URLs it prefixes.
+func (t *TrustPolicy) Keys(ref string) ([]string, bool) {
+	if name, _, ok := strings.Cut(ref, "/"); ok && !strings.Contains(name, ":") {
+		if keys, ok := t.Repositories[name]; ok {
+			return keys, true
+		}
+	}
+	var (
+		longest string
+		keys    []string
+	)
+	for prefix, k := range t.Repositories {
+		p := strings.TrimSuffix(prefix, "/")
+		if !strings.Contains(p, "://") || len(p) <= len(longest) {
+			continue
+		}
+		if ref == p || strings.HasPrefix(ref, p+"/") {
+			longest, keys = p, k
+		}
+	}
+	return keys, longest != ""
+}
+
+// Check verifies that a verified chart reference is signed by a key the trust
+// policy trusts for its repository.
+func (t *TrustPolicy) Check(ref string, report *Report) error {
+	keys, ok := t.Keys(ref)
+	if !ok {
+		return nil
+	}
+	for _, s := range report.Trusted() {
+		if slices.Contains(keys, s.Fingerprint) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not signed by a key trusted for its repository by the trust policy", ref)
+}
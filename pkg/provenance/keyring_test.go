@@ -0,0 +1,136 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFingerprint = "5E615389B53CA37F0EE60BD3843BBF981FC18762"
+
+func TestKeyring(t *testing.T) {
+	data, err := os.ReadFile(testPubfile)
+	require.NoError(t, err)
+	keys, err := ReadKeys(data)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, testFingerprint, Fingerprint(keys[0]))
+
+	_, err = ReadKeys([]byte("not a key"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "helm", "keyring.gpg")
+	empty, err := LoadKeyring(path)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	require.NoError(t, SaveKeyring(path, keys))
+	loaded, err := LoadKeyring(path)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, testFingerprint, Fingerprint(loaded[0]))
+
+	// Saved keyrings can verify charts.
+	signer, err := NewFromKeyring(path, "")
+	require.NoError(t, err)
+	archiveData, err := os.ReadFile(testChartfile)
+	require.NoError(t, err)
+	sigData, err := os.ReadFile(testSigBlock)
+	require.NoError(t, err)
+	_, err = signer.Verify(archiveData, sigData, filepath.Base(testChartfile))
+	require.NoError(t, err)
+}
+
+func TestMatchKey(t *testing.T) {
+	key, err := loadKey(testPubfile)
+	require.NoError(t, err)
+
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: testFingerprint, want: true},
+		{id: "1fc18762", want: true},
+		{id: "843BBF981FC18762", want: true},
+		{id: "0x1FC18762", want: true},
+		{id: "helm-testing@helm.sh", want: true},
+		{id: "1FC1", want: false},
+		{id: "someone@example.com", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchKey(key, tt.id))
+		})
+	}
+}
+
+func TestTrustPolicy(t *testing.T) {
+	p := &TrustPolicy{}
+	p.Trust("stable", "AAAA")
+	p.Trust("stable", "BBBB")
+	p.Trust("stable", "AAAA")
+	p.Trust("oci://example.com/charts", "CCCC")
+	p.Trust("oci://example.com/charts/team/", "DDDD")
+	assert.Equal(t, []string{"AAAA", "BBBB"}, p.Repositories["stable"])
+
+	tests := []struct {
+		ref        string
+		keys       []string
+		restricted bool
+	}{
+		{ref: "stable/mariadb", keys: []string{"AAAA", "BBBB"}, restricted: true},
+		{ref: "other/mariadb"},
+		{ref: "oci://example.com/charts/nginx", keys: []string{"CCCC"}, restricted: true},
+		{ref: "oci://example.com/charts/team/nginx", keys: []string{"DDDD"}, restricted: true},
+		{ref: "oci://example.com/charts2/nginx"},
+		{ref: "https://example.com/charts/nginx-1.0.0.tgz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			keys, restricted := p.Keys(tt.ref)
+			assert.Equal(t, tt.restricted, restricted)
+			assert.Equal(t, tt.keys, keys)
+		})
+	}
+
+	report := &Report{Signers: []Signer{
+		{Fingerprint: "BBBB", Status: SignerUnknown},
+		{Fingerprint: "CCCC", Status: SignerTrusted},
+	}}
+	assert.Error(t, p.Check("stable/mariadb", report))
+	assert.NoError(t, p.Check("oci://example.com/charts/nginx", report))
+	assert.NoError(t, p.Check("other/mariadb", report))
+
+	assert.True(t, p.Untrust("", "AAAA"))
+	assert.False(t, p.Untrust("stable", "AAAA"))
+	assert.True(t, p.Untrust("stable", "BBBB"))
+	assert.NotContains(t, p.Repositories, "stable")
+
+	path := filepath.Join(t.TempDir(), "trust.yaml")
+	require.NoError(t, p.WriteFile(path, 0644))
+	loaded, err := LoadTrustPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, p.Repositories, loaded.Repositories)
+
+	missing, err := LoadTrustPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, missing.Repositories)
+}
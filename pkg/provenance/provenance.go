@@ -0,0 +1,323 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"sigs.k8s.io/yaml"
+)
+
+// APIVersionV2 is the API version of provenance files with detached
+// signatures.
+const APIVersionV2 = "provenance.helm.sh/v2"
+
+// Statement is the content signed in a provenance file of APIVersionV2. It
+// names a package by the digest of its archive, so that the signatures do not
+// depend on how the archive is stored.
+type Statement struct {
+	// Name and Version are the name and version of the package.
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// FileName is the name of the archive of the package.
+	FileName string `json:"fileName"`
+	// Digest is the digest of the archive, prepended with the scheme.
+	Digest string `json:"digest"`
+	// Created is the time the statement was created.
+	Created time.Time `json:"created"`
+	// Expires is the time after which the signatures of the statement are no
+	// longer valid. Statements without it do not expire.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// NewStatement returns the statement for a package archive.
+func NewStatement(archiveData []byte, filename, name, version string) (*Statement, error) {
+	sum, err := Digest(bytes.NewReader(archiveData))
+	if err != nil {
+		return nil, err
+	}
+	return &Statement{
+		Name:     name,
+		Version:  version,
+		FileName: filename,
+		Digest:   "sha256:" + sum,
+		Created:  time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// Provenance is a provenance file of APIVersionV2. It holds a statement and
+// any number of detached, ASCII armored PGP signatures of it, so that several
+// parties can sign the same package.
+type Provenance struct {
+	APIVersion string `json:"apiVersion"`
+	// Statement is the marshalled Statement. The signatures are computed over
+	// its exact bytes.
+	Statement  string   `json:"statement"`
+	Signatures []string `json:"signatures"`
+}
+
+// NewProvenance returns an unsigned provenance file for a statement.
+func NewProvenance(statement *Statement) (*Provenance, error) {
+	data, err := yaml.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+	return &Provenance{
+		APIVersion: APIVersionV2,
+		Statement:  string(data),
+	}, nil
+}
+
+// ParseProvenance parses a provenance file of APIVersionV2.
+func ParseProvenance(data []byte) (*Provenance, error) {
+	p := &Provenance{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("invalid provenance file: %w", err)
+	}
+	if p.APIVersion != APIVersionV2 {
+		return nil, fmt.Errorf("unsupported provenance apiVersion %q", p.APIVersion)
+	}
+	return p, nil
+}
+
+// IsProvenanceV2 reports whether data is a provenance file of APIVersionV2,
+// rather than a legacy clear-signed provenance file.
+func IsProvenanceV2(data []byte) bool {
+	return !bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN PGP SIGNED MESSAGE-----")) &&
+		bytes.Contains(data, []byte(APIVersionV2))
+}
+
+// Marshal returns the provenance file.
+func (p *Provenance) Marshal() ([]byte, error) {
+	return yaml.Marshal(p)
+}
+
+// ParseStatement parses the signed statement.
+func (p *Provenance) ParseStatement() (*Statement, error) {
+	st := &Statement{}
+	if err := yaml.UnmarshalStrict([]byte(p.Statement), st); err != nil {
+		return nil, fmt.Errorf("invalid provenance statement: %w", err)
+	}
+	return st, nil
+}
+
+// Sign adds a detached signature of the statement by the signing key. A
+// lifetime greater than zero sets the expiry of the signature.
+func (s *Signatory) Sign(p *Provenance, lifetime time.Duration) error {
+	if s.Entity == nil {
+		return errors.New("private key not found")
+	} else if s.Entity.PrivateKey == nil {
+		return errors.New("provided key is not a private key. Try providing a keyring with secret keys")
+	}
+	for _, sig := range p.Signatures {
+		if info, err := parseSignature(sig); err == nil && info.IssuerKeyId != nil && *info.IssuerKeyId == s.Entity.PrimaryKey.KeyId {
+			return fmt.Errorf("provenance is already signed by key %X", s.Entity.PrimaryKey.Fingerprint)
+		}
+	}
+
+	config := defaultPGPConfig
+	if lifetime > 0 {
+		config.SigLifetimeSecs = uint32(lifetime / time.Second)
+	}
+	var out strings.Builder
+	if err := openpgp.ArmoredDetachSign(&out, s.Entity, strings.NewReader(p.Statement), &config); err != nil {
+		return fmt.Errorf("failed to sign provenance statement: %w", err)
+	}
+	p.Signatures = append(p.Signatures, out.String())
+	return nil
+}
+
+// SignerStatus is the trust status of a signature.
+type SignerStatus string
+
+const (
+	// SignerTrusted is the status of a valid signature by a key of the keyring.
+	SignerTrusted SignerStatus = "trusted"
+	// SignerUnknown is the status of a signature by a key that is not in the
+	// keyring.
+	SignerUnknown SignerStatus = "unknown"
+	// SignerExpired is the status of an expired signature or a signature by
+	// an expired key.
+	SignerExpired SignerStatus = "expired"
+	// SignerInvalid is the status of a signature that does not match the
+	// statement.
+	SignerInvalid SignerStatus = "invalid"
+)
+
+// Signer describes a signature of a provenance file.
+type Signer struct {
+	// Entity is the key of the keyring that made the signature, if any.
+	Entity *openpgp.Entity `json:"-"`
+	// KeyID is the ID of the signing key, in hexadecimal.
+	KeyID string `json:"keyID"`
+	// Fingerprint is the fingerprint of the signing key, in hexadecimal,
+	// when the key is in the keyring.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Identities are the identities of the signing key, when the key is in
+	// the keyring.
+	Identities []string `json:"identities,omitempty"`
+	// Signed is the time of the signature.
+	Signed time.Time `json:"signed,omitzero"`
+	// Status is the trust status of the signature.
+	Status SignerStatus `json:"status"`
+	// Error explains why a signature is not trusted.
+	Error string `json:"error,omitempty"`
+	// Rekor is the transparency log entry that records the signature, when
+	// the signature was looked up with Rekor.Verify.
+	Rekor *RekorEntry `json:"rekor,omitempty"`
+}
+
+// Report is the result of the verification of a provenance file.
+type Report struct {
+	// FileName is the name of the verified archive.
+	FileName string `json:"fileName"`
+	// FileHash is the digest, prepended with the scheme, of the archive.
+	FileHash string `json:"fileHash"`
+	// Legacy is set for clear-signed provenance files, which have a single
+	// signature.
+	Legacy bool `json:"legacy,omitempty"`
+	// Expires is the expiry of the statement, if any.
+	Expires *time.Time `json:"expires,omitempty"`
+	// Signers lists every signature of the provenance file.
+	Signers []Signer `json:"signers"`
+}
+
+// Trusted returns the signers with a trusted signature.
+func (r *Report) Trusted() []Signer {
+	var trusted []Signer
+	for _, s := range r.Signers {
+		if s.Status == SignerTrusted {
+			trusted = append(trusted, s)
+		}
+	}
+	return trusted
+}
+
+// VerifyReport verifies a provenance file, either a legacy clear-signed file
+// or a file of APIVersionV2, for package data.
+//
+// The archive must match the digest of the provenance file, and at least one
+// signature must be made by a key of the keyring. The report lists every
+// signature with its trust status. It is returned with the error when the
+// verification fails after the provenance file was parsed.
+func (s *Signatory) VerifyReport(archiveData, provData []byte, filename string) (*Report, error) {
+	if !IsProvenanceV2(provData) {
+		ver, err := s.Verify(archiveData, provData, filename)
+		if err != nil {
+			return nil, err
+		}
+		return ver.Report, nil
+	}
+
+	p, err := ParseProvenance(provData)
+	if err != nil {
+		return nil, err
+	}
+	st, err := p.ParseStatement()
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{FileName: filename, Expires: st.Expires}
+
+	sum, err := Digest(bytes.NewReader(archiveData))
+	if err != nil {
+		return report, err
+	}
+	sum = "sha256:" + sum
+	if st.FileName != filename {
+		return report, fmt.Errorf("provenance does not contain a SHA for a file named %q", filename)
+	}
+	if st.Digest != sum {
+		return report, fmt.Errorf("sha256 sum does not match for %s: %q != %q", filename, st.Digest, sum)
+	}
+	report.FileHash = sum
+
+	now := time.Now()
+	for _, sig := range p.Signatures {
+		report.Signers = append(report.Signers, s.verifyDetached(p.Statement, sig, now))
+	}
+	if st.Expires != nil && now.After(*st.Expires) {
+		return report, fmt.Errorf("provenance of %s expired at %s", filename, st.Expires.Format(time.RFC3339))
+	}
+	if len(report.Trusted()) == 0 {
+		return report, fmt.Errorf("provenance of %s has no trusted signature out of %d", filename, len(report.Signers))
+	}
+	return report, nil
+}
+
+// verifyDetached checks a detached signature of a statement against the
+// keyring.
+func (s *Signatory) verifyDetached(statement, signature string, now time.Time) Signer {
+	signer := Signer{Status: SignerInvalid}
+	info, err := parseSignature(signature)
+	if err != nil {
+		signer.Error = err.Error()
+		return signer
+	}
+	signer.Signed = info.CreationTime
+	if info.IssuerKeyId != nil {
+		signer.KeyID = fmt.Sprintf("%016X", *info.IssuerKeyId)
+	}
+
+	config := defaultPGPConfig
+	config.Time = func() time.Time { return now }
+	entity, err := openpgp.CheckArmoredDetachedSignature(s.KeyRing, strings.NewReader(statement), strings.NewReader(signature), &config)
+	if entity != nil {
+		signer.Entity = entity
+		signer.Fingerprint = fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+		signer.Identities = slices.Sorted(maps.Keys(entity.Identities))
+	}
+	switch {
+	case err == nil:
+		signer.Status = SignerTrusted
+	case errors.Is(err, pgperrors.ErrUnknownIssuer):
+		signer.Status = SignerUnknown
+		signer.Error = "the signing key is not in the keyring"
+	case errors.Is(err, pgperrors.ErrSignatureExpired), errors.Is(err, pgperrors.ErrKeyExpired):
+		signer.Status = SignerExpired
+		signer.Error = err.Error()
+	default:
+		signer.Error = err.Error()
+	}
+	return signer
+}
+
+// parseSignature parses an ASCII armored signature packet.
+func parseSignature(signature string) (*packet.Signature, error) {
+	block, err := armor.Decode(strings.NewReader(signature))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok {
+		return nil, errors.New("invalid signature: not a signature packet")
+	}
+	return sig, nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvenance(t *testing.T, archiveData []byte, expires *time.Time) *Provenance {
+	t.Helper()
+	st, err := NewStatement(archiveData, filepath.Base(testChartfile), "hashtest", "1.2.3")
+	require.NoError(t, err)
+	st.Expires = expires
+	p, err := NewProvenance(st)
+	require.NoError(t, err)
+	return p
+}
+
+func TestProvenanceV2(t *testing.T) {
+	archiveData, err := os.ReadFile(testChartfile)
+	require.NoError(t, err)
+	filename := filepath.Base(testChartfile)
+
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	require.NoError(t, err)
+	other, err := openpgp.NewEntity("Other Signer", "", "other@example.com", nil)
+	require.NoError(t, err)
+	otherSigner := &Signatory{Entity: other, KeyRing: openpgp.EntityList{other}}
+
+	p := newTestProvenance(t, archiveData, nil)
+	require.NoError(t, signer.Sign(p, 0))
+	require.NoError(t, otherSigner.Sign(p, 0))
+	require.ErrorContains(t, signer.Sign(p, 0), "already signed")
+	require.Len(t, p.Signatures, 2)
+
+	data, err := p.Marshal()
+	require.NoError(t, err)
+	require.True(t, IsProvenanceV2(data))
+
+	parsed, err := ParseProvenance(data)
+	require.NoError(t, err)
+	st, err := parsed.ParseStatement()
+	require.NoError(t, err)
+	assert.Equal(t, "hashtest", st.Name)
+	assert.Equal(t, filename, st.FileName)
+	assert.Equal(t, "sha256:c6841b3a895f1444a6738b5d04564a57e860ce42f8519c3be807fb6d9bee7888", st.Digest)
+
+	t.Run("one trusted signer", func(t *testing.T) {
+		report, err := signer.VerifyReport(archiveData, data, filename)
+		require.NoError(t, err)
+		assert.False(t, report.Legacy)
+		assert.Equal(t, st.Digest, report.FileHash)
+		require.Len(t, report.Signers, 2)
+		assert.Equal(t, SignerTrusted, report.Signers[0].Status)
+		assert.Equal(t, []string{testKeyName}, report.Signers[0].Identities)
+		assert.Equal(t, SignerUnknown, report.Signers[1].Status)
+		assert.Equal(t, strings.ToUpper(other.PrimaryKey.KeyIdString()), report.Signers[1].KeyID)
+		assert.Empty(t, report.Signers[1].Fingerprint)
+		assert.Len(t, report.Trusted(), 1)
+
+		ver, err := signer.Verify(archiveData, data, filename)
+		require.NoError(t, err)
+		assert.Equal(t, signer.Entity.PrimaryKey.Fingerprint, ver.SignedBy.PrimaryKey.Fingerprint)
+		assert.Equal(t, st.Digest, ver.FileHash)
+		assert.Same(t, ver.SignedBy, ver.Report.Signers[0].Entity)
+	})
+
+	t.Run("all signers trusted", func(t *testing.T) {
+		both := &Signatory{KeyRing: append(openpgp.EntityList{other}, signer.KeyRing...)}
+		report, err := both.VerifyReport(archiveData, data, filename)
+		require.NoError(t, err)
+		assert.Len(t, report.Trusted(), 2)
+	})
+
+	t.Run("no trusted signer", func(t *testing.T) {
+		report, err := otherSigner.VerifyReport(archiveData, data, filename)
+		require.NoError(t, err)
+		assert.Len(t, report.Trusted(), 1)
+
+		only := newTestProvenance(t, archiveData, nil)
+		require.NoError(t, otherSigner.Sign(only, 0))
+		onlyData, err := only.Marshal()
+		require.NoError(t, err)
+		report, err = signer.VerifyReport(archiveData, onlyData, filename)
+		require.ErrorContains(t, err, "no trusted signature")
+		require.Len(t, report.Signers, 1)
+		assert.Equal(t, SignerUnknown, report.Signers[0].Status)
+	})
+
+	t.Run("wrong archive", func(t *testing.T) {
+		_, err := signer.VerifyReport([]byte("not the chart"), data, filename)
+		assert.ErrorContains(t, err, "sha256 sum does not match")
+		_, err = signer.VerifyReport(archiveData, data, "other-1.2.3.tgz")
+		assert.ErrorContains(t, err, "does not contain a SHA")
+	})
+
+	t.Run("tampered statement", func(t *testing.T) {
+		tampered := *parsed
+		tampered.Statement = strings.Replace(tampered.Statement, "version: 1.2.3", "version: 1.2.4", 1)
+		require.NotEqual(t, parsed.Statement, tampered.Statement)
+		tamperedData, err := tampered.Marshal()
+		require.NoError(t, err)
+		report, err := signer.VerifyReport(archiveData, tamperedData, filename)
+		require.Error(t, err)
+		assert.Equal(t, SignerInvalid, report.Signers[0].Status)
+		assert.NotEmpty(t, report.Signers[0].Error)
+	})
+
+	t.Run("expired statement", func(t *testing.T) {
+		expires := time.Now().Add(-time.Hour).UTC()
+		expired := newTestProvenance(t, archiveData, &expires)
+		require.NoError(t, signer.Sign(expired, 0))
+		expiredData, err := expired.Marshal()
+		require.NoError(t, err)
+		_, err = signer.VerifyReport(archiveData, expiredData, filename)
+		assert.ErrorContains(t, err, "expired")
+	})
+}
+
+func TestVerifyReportLegacy(t *testing.T) {
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	require.NoError(t, err)
+	archiveData, err := os.ReadFile(testChartfile)
+	require.NoError(t, err)
+	sigData, err := os.ReadFile(testSigBlock)
+	require.NoError(t, err)
+	require.False(t, IsProvenanceV2(sigData))
+
+	report, err := signer.VerifyReport(archiveData, sigData, filepath.Base(testChartfile))
+	require.NoError(t, err)
+	assert.True(t, report.Legacy)
+	require.Len(t, report.Signers, 1)
+	assert.Equal(t, SignerTrusted, report.Signers[0].Status)
+	assert.NotEmpty(t, report.Signers[0].Fingerprint)
+}
+
+func TestParseProvenance(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "wrong apiVersion", data: "apiVersion: v1\nstatement: x\n", err: "unsupported provenance apiVersion"},
+		{name: "unknown field", data: "apiVersion: " + APIVersionV2 + "\nfoo: bar\n", err: "invalid provenance file"},
+		{name: "valid", data: "apiVersion: " + APIVersionV2 + "\nstatement: x\nsignatures: []\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseProvenance([]byte(tt.data))
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,474 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+)
+
+// DefaultRekorURL is the URL of the public Rekor transparency log.
+const DefaultRekorURL = "https://rekor.sigstore.dev"
+
+// DefaultRekorPublicKey is the PEM encoded public key of the public Rekor
+// transparency log.
+const DefaultRekorPublicKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE2G2Y+2tabdTV5BcGiBIx0a9fAFwr
+kBbmLSGtks4L3qX6yYY0zufBnhC8Ur/iy55GhWP/9A/bY2LhC30M9+RYtw==
+-----END PUBLIC KEY-----
+`
+
+// RekorEntry is the entry of a transparency log that records a signature.
+type RekorEntry struct {
+	// UUID identifies the entry in the log.
+	UUID string `json:"uuid"`
+	// LogIndex is the index of the entry in the log.
+	LogIndex int64 `json:"logIndex"`
+	// IntegratedTime is the time the entry was added to the log.
+	IntegratedTime time.Time `json:"integratedTime"`
+}
+
+// Rekor checks that the signatures of provenance files are recorded in a
+// Rekor transparency log.
+//
+// A signature is recorded by a "rekord" entry of PGP format whose data is the
+// signed content: the statement of a provenance file of APIVersionV2, or the
+// signed message of a legacy clear-signed provenance file. An entry is only
+// trusted when its signed entry timestamp, the promise of the log to include
+// it, is signed by the public key of the log. The UUID and inclusion proof of
+// the entry are also checked against its body.
+type Rekor struct {
+	// URL is the base URL of the log. It defaults to DefaultRekorURL.
+	URL string
+	// PublicKey is the public key of the log. It defaults to the key parsed
+	// from DefaultRekorPublicKey.
+	PublicKey crypto.PublicKey
+	// Client is the HTTP client used to query the log. It defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// ParseRekorPublicKey parses a PEM encoded public key of a Rekor log.
+func ParseRekorPublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// Verify looks up the trusted signatures of a verified provenance file in the
+// log, and sets the Rekor entry of the signers that are recorded. It fails
+// when none of the trusted signatures is recorded.
+func (r *Rekor) Verify(ctx context.Context, provData []byte, report *Report) error {
+	signed, signatures, err := signedContent(provData)
+	if err != nil {
+		return err
+	}
+	key, err := r.publicKey()
+	if err != nil {
+		return err
+	}
+	if len(signatures) != len(report.Signers) {
+		return errors.New("the report does not match the provenance file")
+	}
+
+	var errs []error
+	recorded := 0
+	for i := range report.Signers {
+		s := &report.Signers[i]
+		if s.Status != SignerTrusted {
+			continue
+		}
+		entry, err := r.lookup(ctx, key, signed, signatures[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("signature by key %s: %w", s.KeyID, err))
+			continue
+		}
+		s.Rekor = entry
+		recorded++
+	}
+	if recorded == 0 {
+		errs = append([]error{fmt.Errorf("no trusted signature of %s is recorded in the transparency log %s", report.FileName, r.url())}, errs...)
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func (r *Rekor) url() string {
+	if r.URL == "" {
+		return DefaultRekorURL
+	}
+	return strings.TrimSuffix(r.URL, "/")
+}
+
+func (r *Rekor) publicKey() (*rekorKey, error) {
+	pub := r.PublicKey
+	if pub == nil {
+		var err error
+		if pub, err = ParseRekorPublicKey([]byte(DefaultRekorPublicKey)); err != nil {
+			return nil, err
+		}
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transparency log public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return &rekorKey{pub: pub, logID: hex.EncodeToString(sum[:])}, nil
+}
+
+func (r *Rekor) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+	return r.Client
+}
+
+// signedContent returns the signed content of a provenance file, and its
+// signatures in binary form, in the order of the signers of its report.
+func signedContent(provData []byte) ([]byte, [][]byte, error) {
+	if IsProvenanceV2(provData) {
+		p, err := ParseProvenance(provData)
+		if err != nil {
+			return nil, nil, err
+		}
+		var signatures [][]byte
+		for _, sig := range p.Signatures {
+			raw, err := dearmor([]byte(sig))
+			if err != nil {
+				// Invalid signatures are never trusted, so they are never
+				// looked up.
+				raw = nil
+			}
+			signatures = append(signatures, raw)
+		}
+		return []byte(p.Statement), signatures, nil
+	}
+
+	block, _ := clearsign.Decode(provData)
+	if block == nil {
+		return nil, nil, errors.New("signature block not found")
+	}
+	raw, err := io.ReadAll(block.ArmoredSignature.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	return block.Bytes, [][]byte{raw}, nil
+}
+
+// dearmor returns the binary form of an ASCII armored signature. Binary
+// signatures are returned as is.
+func dearmor(sig []byte) ([]byte, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(sig), []byte("-----BEGIN")) {
+		return sig, nil
+	}
+	block, err := armor.Decode(bytes.NewReader(sig))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(block.Body)
+}
+
+// rekorKey is the public key of a log, and the log ID derived from it.
+type rekorKey struct {
+	pub   crypto.PublicKey
+	logID string
+}
+
+// rekorLogEntry is an entry as returned by the Rekor API.
+type rekorLogEntry struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+	Verification   *struct {
+		InclusionProof *struct {
+			Hashes   []string `json:"hashes"`
+			LogIndex int64    `json:"logIndex"`
+			RootHash string   `json:"rootHash"`
+			TreeSize int64    `json:"treeSize"`
+		} `json:"inclusionProof"`
+		SignedEntryTimestamp string `json:"signedEntryTimestamp"`
+	} `json:"verification"`
+}
+
+// rekorEntryPayload is the payload signed by the signed entry timestamp of an
+// entry. Its fields are in the order of its canonical JSON encoding.
+type rekorEntryPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// rekordBody is the body of a "rekord" entry.
+type rekordBody struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Signature struct {
+			Format  string `json:"format"`
+			Content string `json:"content"`
+		} `json:"signature"`
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+	} `json:"spec"`
+}
+
+// lookup returns the entry of the log that records a signature of the signed
+// content.
+func (r *Rekor) lookup(ctx context.Context, key *rekorKey, signed, signature []byte) (*RekorEntry, error) {
+	sum := sha256.Sum256(signed)
+	digest := hex.EncodeToString(sum[:])
+
+	var uuids []string
+	query, _ := json.Marshal(map[string]string{"hash": "sha256:" + digest})
+	if err := r.do(ctx, http.MethodPost, "/api/v1/index/retrieve", query, &uuids); err != nil {
+		return nil, err
+	}
+
+	for _, uuid := range uuids {
+		var entries map[string]rekorLogEntry
+		if err := r.do(ctx, http.MethodGet, "/api/v1/log/entries/"+url.PathEscape(uuid), nil, &entries); err != nil {
+			return nil, err
+		}
+		for id, e := range entries {
+			ok, err := e.records(digest, signature)
+			if err != nil {
+				return nil, fmt.Errorf("invalid log entry %s: %w", id, err)
+			}
+			if !ok {
+				continue
+			}
+			if err := e.verify(id, key); err != nil {
+				return nil, fmt.Errorf("log entry %s: %w", id, err)
+			}
+			return &RekorEntry{
+				UUID:           id,
+				LogIndex:       e.LogIndex,
+				IntegratedTime: time.Unix(e.IntegratedTime, 0).UTC(),
+			}, nil
+		}
+	}
+	return nil, errors.New("signature not found in the transparency log")
+}
+
+// records reports whether the entry records the signature of content with
+// the given SHA-256 digest.
+func (e *rekorLogEntry) records(digest string, signature []byte) (bool, error) {
+	data, err := base64.StdEncoding.DecodeString(e.Body)
+	if err != nil {
+		return false, err
+	}
+	body := &rekordBody{}
+	if err := json.Unmarshal(data, body); err != nil {
+		return false, err
+	}
+	if body.Kind != "rekord" || body.Spec.Signature.Format != "pgp" {
+		return false, nil
+	}
+	if body.Spec.Data.Hash.Algorithm != "sha256" || body.Spec.Data.Hash.Value != digest {
+		return false, nil
+	}
+	content, err := base64.StdEncoding.DecodeString(body.Spec.Signature.Content)
+	if err != nil {
+		return false, err
+	}
+	raw, err := dearmor(content)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(raw, signature), nil
+}
+
+// verify checks that the entry is the entry with the given UUID, that its
+// signed entry timestamp is signed by the log, and that its inclusion proof
+// is valid.
+func (e *rekorLogEntry) verify(uuid string, key *rekorKey) error {
+	body, err := base64.StdEncoding.DecodeString(e.Body)
+	if err != nil {
+		return err
+	}
+	// UUIDs are the hex encoded leaf hash of the entry, optionally prefixed
+	// with the 16 hex digits of the tree ID.
+	leaf := hex.EncodeToString(leafHash(body))
+	if (len(uuid) != len(leaf) && len(uuid) != len(leaf)+16) || !strings.EqualFold(uuid[len(uuid)-len(leaf):], leaf) {
+		return errors.New("the UUID does not match the entry")
+	}
+	if err := e.verifySignedEntryTimestamp(key); err != nil {
+		return err
+	}
+	return e.verifyInclusion()
+}
+
+// verifySignedEntryTimestamp checks that the signed entry timestamp of the
+// entry is signed by the public key of the log.
+func (e *rekorLogEntry) verifySignedEntryTimestamp(key *rekorKey) error {
+	if e.Verification == nil || e.Verification.SignedEntryTimestamp == "" {
+		return errors.New("no signed entry timestamp")
+	}
+	if e.LogID != key.logID {
+		return fmt.Errorf("the entry is from log %s, not from the log of the public key %s", e.LogID, key.logID)
+	}
+	sig, err := base64.StdEncoding.DecodeString(e.Verification.SignedEntryTimestamp)
+	if err != nil {
+		return fmt.Errorf("invalid signed entry timestamp: %w", err)
+	}
+	payload, err := json.Marshal(rekorEntryPayload{
+		Body:           e.Body,
+		IntegratedTime: e.IntegratedTime,
+		LogID:          e.LogID,
+		LogIndex:       e.LogIndex,
+	})
+	if err != nil {
+		return err
+	}
+	var ok bool
+	switch pub := key.pub.(type) {
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(payload)
+		ok = ecdsa.VerifyASN1(pub, sum[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(pub, payload, sig)
+	default:
+		return fmt.Errorf("unsupported public key type %T", key.pub)
+	}
+	if !ok {
+		return errors.New("the signed entry timestamp is not signed by the transparency log")
+	}
+	return nil
+}
+
+// verifyInclusion checks the inclusion proof of the entry, as described in
+// RFC 9162, section 2.1.3.2.
+func (e *rekorLogEntry) verifyInclusion() error {
+	if e.Verification == nil || e.Verification.InclusionProof == nil {
+		return errors.New("no inclusion proof")
+	}
+	proof := e.Verification.InclusionProof
+	body, err := base64.StdEncoding.DecodeString(e.Body)
+	if err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(proof.RootHash)
+	if err != nil {
+		return fmt.Errorf("invalid root hash: %w", err)
+	}
+
+	index, size := proof.LogIndex, proof.TreeSize
+	if index < 0 || index >= size {
+		return fmt.Errorf("invalid inclusion proof: index %d is not in a tree of size %d", index, size)
+	}
+	hash := leafHash(body)
+	fn, sn := index, size-1
+	for _, h := range proof.Hashes {
+		p, err := hex.DecodeString(h)
+		if err != nil {
+			return fmt.Errorf("invalid inclusion proof: %w", err)
+		}
+		if sn == 0 {
+			return errors.New("invalid inclusion proof: too many hashes")
+		}
+		if fn&1 == 1 || fn == sn {
+			hash = nodeHash(p, hash)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			hash = nodeHash(hash, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(hash, root) {
+		return errors.New("inclusion proof does not match the root hash")
+	}
+	return nil
+}
+
+func leafHash(data []byte) []byte {
+	sum := sha256.Sum256(append([]byte{0}, data...))
+	return sum[:]
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// do sends a request to the log and decodes the JSON response into v.
+func (r *Rekor) do(ctx context.Context, method, path string, body []byte, v any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.url()+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query the transparency log: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to query the transparency log: %s %s returned %s", method, path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response from the transparency log: %w", err)
+	}
+	return nil
+}
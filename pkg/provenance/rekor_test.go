@@ -0,0 +1,409 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRekor is a transparency log that serves the subset of the Rekor API
+// used by Rekor.Verify.
+type fakeRekor struct {
+	key      *ecdsa.PrivateKey
+	setKey   *ecdsa.PrivateKey
+	bodies   [][]byte
+	rootHash string
+	uuids    map[int]string
+}
+
+func newFakeRekor(t *testing.T) *fakeRekor {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &fakeRekor{key: key}
+}
+
+// add records a PGP signature of content, and returns the index of the entry.
+func (f *fakeRekor) add(t *testing.T, content, signature []byte) int {
+	t.Helper()
+	sum := sha256.Sum256(content)
+	body, err := json.Marshal(map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "rekord",
+		"spec": map[string]any{
+			"signature": map[string]any{
+				"format":  "pgp",
+				"content": base64.StdEncoding.EncodeToString(signature),
+			},
+			"data": map[string]any{
+				"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(sum[:])},
+			},
+		},
+	})
+	require.NoError(t, err)
+	f.bodies = append(f.bodies, body)
+	return len(f.bodies) - 1
+}
+
+func (f *fakeRekor) uuid(i int) string {
+	if uuid, ok := f.uuids[i]; ok {
+		return uuid
+	}
+	sum := leafHash(f.bodies[i])
+	return hex.EncodeToString(sum)
+}
+
+// signedEntryTimestamp signs an entry with the key of the log, or setKey
+// when it is set.
+func (f *fakeRekor) signedEntryTimestamp(p rekorEntryPayload) (string, error) {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	key := f.key
+	if f.setKey != nil {
+		key = f.setKey
+	}
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func (f *fakeRekor) logID() string {
+	der, _ := x509.MarshalPKIXPublicKey(&f.key.PublicKey)
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *fakeRekor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var leaves [][]byte
+	for _, b := range f.bodies {
+		leaves = append(leaves, leafHash(b))
+	}
+	root := hex.EncodeToString(merkleRoot(leaves))
+	if f.rootHash != "" {
+		root = f.rootHash
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/index/retrieve":
+		var query struct {
+			Hash string `json:"hash"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		uuids := []string{}
+		for i, b := range f.bodies {
+			if strings.Contains(string(b), strings.TrimPrefix(query.Hash, "sha256:")) {
+				uuids = append(uuids, f.uuid(i))
+			}
+		}
+		_ = json.NewEncoder(w).Encode(uuids)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v1/log/entries/"):
+		uuid := strings.TrimPrefix(r.URL.Path, "/api/v1/log/entries/")
+		for i := range f.bodies {
+			if f.uuid(i) != uuid {
+				continue
+			}
+			var hashes []string
+			for _, h := range merklePath(i, leaves) {
+				hashes = append(hashes, hex.EncodeToString(h))
+			}
+			payload := rekorEntryPayload{
+				Body:           base64.StdEncoding.EncodeToString(f.bodies[i]),
+				IntegratedTime: int64(1700000000 + i),
+				LogID:          f.logID(),
+				LogIndex:       int64(1000 + i),
+			}
+			set, err := f.signedEntryTimestamp(payload)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				uuid: map[string]any{
+					"body":           payload.Body,
+					"integratedTime": payload.IntegratedTime,
+					"logID":          payload.LogID,
+					"logIndex":       payload.LogIndex,
+					"verification": map[string]any{
+						"inclusionProof": map[string]any{
+							"hashes":   hashes,
+							"logIndex": i,
+							"rootHash": root,
+							"treeSize": len(leaves),
+						},
+						"signedEntryTimestamp": set,
+					},
+				},
+			})
+			return
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// merkleRoot computes the Merkle tree hash of RFC 6962, section 2.1.
+func merkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(merkleRoot(leaves[:k]), merkleRoot(leaves[k:]))
+}
+
+// merklePath computes the audit path of RFC 6962, section 2.1.1.
+func merklePath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if m < k {
+		return append(merklePath(m, leaves[:k]), merkleRoot(leaves[k:]))
+	}
+	return append(merklePath(m-k, leaves[k:]), merkleRoot(leaves[:k]))
+}
+
+// split returns the largest power of two smaller than n.
+func split(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+func TestRekorVerify(t *testing.T) {
+	archiveData, err := os.ReadFile(testChartfile)
+	require.NoError(t, err)
+	filename := filepath.Base(testChartfile)
+
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	require.NoError(t, err)
+	other, err := openpgp.NewEntity("Other Signer", "", "other@example.com", nil)
+	require.NoError(t, err)
+	otherSigner := &Signatory{Entity: other, KeyRing: openpgp.EntityList{other}}
+
+	p := newTestProvenance(t, archiveData, nil)
+	require.NoError(t, otherSigner.Sign(p, 0))
+	require.NoError(t, signer.Sign(p, 0))
+	provData, err := p.Marshal()
+	require.NoError(t, err)
+
+	legacyData, err := os.ReadFile(testChartfile + ".prov")
+	require.NoError(t, err)
+	legacyContent, legacySignatures, err := signedContent(legacyData)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		provData  []byte
+		setup     func(t *testing.T, f *fakeRekor) int
+		publicKey any
+		wantErr   string
+	}{
+		{
+			name:     "trusted signature recorded",
+			provData: provData,
+			setup: func(t *testing.T, f *fakeRekor) int {
+				t.Helper()
+				f.add(t, []byte("unrelated"), []byte("signature"))
+				f.add(t, []byte(p.Statement), []byte(p.Signatures[0]))
+				return f.add(t, []byte(p.Statement), []byte(p.Signatures[1]))
+			},
+		},
+		{
+			name:     "legacy signature recorded",
+			provData: legacyData,
+			setup: func(t *testing.T, f *fakeRekor) int {
+				t.Helper()
+				f.add(t, []byte("unrelated"), []byte("signature"))
+				return f.add(t, legacyContent, legacySignatures[0])
+			},
+		},
+		{
+			name:     "only untrusted signature recorded",
+			provData: provData,
+			setup: func(t *testing.T, f *fakeRekor) int {
+				t.Helper()
+				f.add(t, []byte(p.Statement), []byte(p.Signatures[0]))
+				return -1
+			},
+			wantErr: "no trusted signature of hashtest-1.2.3.tgz is recorded",
+		},
+		{
+			name:     "inclusion proof does not match",
+			provData: provData,
+			setup: func(t *testing.T, f *fakeRekor) int {
+				t.Helper()
+				f.add(t, []byte("unrelated"), []byte("signature"))
+				f.add(t, []byte(p.Statement), []byte(p.Signatures[1]))
+				f.rootHash = hex.EncodeToString(make([]byte, sha256.Size))
+				return -1
+			},
+			wantErr: "inclusion proof does not match the root hash",
+		},
+		{
+			name:     "entry not signed by the log",
+			provData: provData,
+			setup: func(t *testing.T, f *fakeRekor) int {
+				t.Helper()
+				f.add(t, []byte(p.Statement), []byte(p.Signatures[1]))
+				f.setKey = otherKey
+				return -1
+			},
+			wantErr: "the signed entry timestamp is not signed by the transparency log",
+		},
+		{
+			name:     "entry from another log",
+			provData: provData,
+			setup: func(t *testing.T, f *fakeRekor) int {
+				t.Helper()
+				f.add(t, []byte(p.Statement), []byte(p.Signatures[1]))
+				return -1
+			},
+			publicKey: &otherKey.PublicKey,
+			wantErr:   "the entry is from log",
+		},
+		{
+			name:     "UUID does not match the entry",
+			provData: provData,
+			setup: func(t *testing.T, f *fakeRekor) int {
+				t.Helper()
+				i := f.add(t, []byte(p.Statement), []byte(p.Signatures[1]))
+				f.uuids = map[int]string{i: hex.EncodeToString(make([]byte, sha256.Size))}
+				return -1
+			},
+			wantErr: "the UUID does not match the entry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRekor(t)
+			var publicKey any = &f.key.PublicKey
+			index := tt.setup(t, f)
+			if tt.publicKey != nil {
+				publicKey = tt.publicKey
+			}
+			srv := httptest.NewServer(f)
+			defer srv.Close()
+
+			report, err := signer.VerifyReport(archiveData, tt.provData, filename)
+			require.NoError(t, err)
+
+			rekor := &Rekor{URL: srv.URL, Client: srv.Client(), PublicKey: publicKey}
+			err = rekor.Verify(t.Context(), tt.provData, report)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				for _, s := range report.Signers {
+					assert.Nil(t, s.Rekor)
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			trusted := report.Signers[len(report.Signers)-1]
+			require.NotNil(t, trusted.Rekor, "trusted signer has no log entry")
+			assert.Equal(t, f.uuid(index), trusted.Rekor.UUID)
+			assert.Equal(t, int64(1000+index), trusted.Rekor.LogIndex)
+			assert.Equal(t, int64(1700000000+index), trusted.Rekor.IntegratedTime.Unix())
+		})
+	}
+}
+
+func TestParseRekorPublicKey(t *testing.T) {
+	key, err := ParseRekorPublicKey([]byte(DefaultRekorPublicKey))
+	require.NoError(t, err)
+	r := &Rekor{PublicKey: key}
+	k, err := r.publicKey()
+	require.NoError(t, err)
+	assert.Equal(t, "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d", k.logID)
+
+	_, err = ParseRekorPublicKey([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestVerifyInclusion(t *testing.T) {
+	for size := 1; size <= 9; size++ {
+		var bodies [][]byte
+		var leaves [][]byte
+		for i := range size {
+			body := fmt.Appendf(nil, "entry %d", i)
+			bodies = append(bodies, body)
+			leaves = append(leaves, leafHash(body))
+		}
+		root := hex.EncodeToString(merkleRoot(leaves))
+		for i := range size {
+			e := &rekorLogEntry{Body: base64.StdEncoding.EncodeToString(bodies[i])}
+			data, err := json.Marshal(map[string]any{
+				"body": e.Body,
+				"verification": map[string]any{
+					"inclusionProof": map[string]any{
+						"hashes":   hexAll(merklePath(i, leaves)),
+						"logIndex": i,
+						"rootHash": root,
+						"treeSize": size,
+					},
+				},
+			})
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, e))
+			assert.NoError(t, e.verifyInclusion(), "leaf %d of %d", i, size)
+
+			e.Verification.InclusionProof.LogIndex = int64((i + 1) % size)
+			if size > 1 {
+				assert.Error(t, e.verifyInclusion(), "leaf %d of %d at wrong index", i, size)
+			}
+		}
+	}
+}
+
+func hexAll(hashes [][]byte) []string {
+	out := []string{}
+	for _, h := range hashes {
+		out = append(out, hex.EncodeToString(h))
+	}
+	return out
+}
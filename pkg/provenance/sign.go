@@ -22,7 +22,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
@@ -60,6 +62,9 @@ type Verification struct {
 	FileHash string
 	// FileName is the name of the file that FileHash verifies.
 	FileName string
+	// Report lists the signatures of the provenance file and their trust
+	// status.
+	Report *Report
 }
 
 // Signatory signs things.
@@ -238,9 +243,24 @@ func (s *Signatory) ClearSign(archiveData []byte, filename string, metadataBytes
 
 // Verify checks a signature and verifies that it is legit for package data.
 // This is the core verification method that works with data in memory.
+//
+// Provenance files of APIVersionV2 are verified with VerifyReport, and
+// SignedBy is set to the first trusted signer.
 func (s *Signatory) Verify(archiveData, provData []byte, filename string) (*Verification, error) {
 	ver := &Verification{}
 
+	if IsProvenanceV2(provData) {
+		report, err := s.VerifyReport(archiveData, provData, filename)
+		ver.Report = report
+		if err != nil {
+			return ver, err
+		}
+		ver.SignedBy = report.Trusted()[0].Entity
+		ver.FileHash = report.FileHash
+		ver.FileName = filename
+		return ver, nil
+	}
+
 	// First verify the signature
 	block, _ := clearsign.Decode(provData)
 	if block == nil {
@@ -271,12 +291,30 @@ func (s *Signatory) Verify(archiveData, provData []byte, filename string) (*Veri
 	}
 	ver.FileHash = sum
 	ver.FileName = filename
+	ver.Report = legacyReport(ver)
 
 	// TODO: when image signing is added, verify that here.
 
 	return ver, nil
 }
 
+// legacyReport returns the report of a verified clear-signed provenance file.
+func legacyReport(ver *Verification) *Report {
+	signer := Signer{
+		Entity:      ver.SignedBy,
+		KeyID:       fmt.Sprintf("%016X", ver.SignedBy.PrimaryKey.KeyId),
+		Fingerprint: fmt.Sprintf("%X", ver.SignedBy.PrimaryKey.Fingerprint),
+		Identities:  slices.Sorted(maps.Keys(ver.SignedBy.Identities)),
+		Status:      SignerTrusted,
+	}
+	return &Report{
+		FileName: ver.FileName,
+		FileHash: ver.FileHash,
+		Legacy:   true,
+		Signers:  []Signer{signer},
+	}
+}
+
 // verifySignature verifies that the given block is validly signed, and returns the signer.
 func (s *Signatory) verifySignature(block *clearsign.Block) (*openpgp.Entity, error) {
 	return openpgp.CheckDetachedSignature(
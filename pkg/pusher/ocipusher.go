@@ -91,7 +91,14 @@ func (pusher *OCIPusher) push(chartRef, href string) error {
 
 	// The time the chart was "created" is semantically the time the chart archive file was last written(modified)
 	chartArchiveFileCreatedTime := stat.ModTime()
-	pushOpts = append(pushOpts, registry.PushOptCreationTime(chartArchiveFileCreatedTime.Format(time.RFC3339)))
+	pushOpts = append(pushOpts,
+		registry.PushOptCreationTime(chartArchiveFileCreatedTime.Format(time.RFC3339)),
+		registry.PushOptAnnotations(pusher.opts.annotations),
+		registry.PushOptImmutableTag(pusher.opts.immutableTag),
+		registry.PushOptDigestOnly(pusher.opts.digestOnly),
+		registry.PushOptChunkSize(pusher.opts.chunkSize),
+		registry.PushOptResumeDir(pusher.opts.resumeDir),
+	)
 
 	_, err = client.Push(chartBytes, ref, pushOpts...)
 	return err
@@ -34,6 +34,11 @@ type options struct {
 	caFile                string
 	insecureSkipTLSVerify bool
 	plainHTTP             bool
+	annotations           map[string]string
+	immutableTag          bool
+	digestOnly            bool
+	chunkSize             int64
+	resumeDir             string
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -69,6 +74,43 @@ func WithPlainHTTP(plainHTTP bool) Option {
 	}
 }
 
+// WithAnnotations sets annotations to add to the pushed chart.
+func WithAnnotations(annotations map[string]string) Option {
+	return func(opts *options) {
+		opts.annotations = annotations
+	}
+}
+
+// WithImmutableTag determines if pushing to a tag that already refers to
+// different content fails.
+func WithImmutableTag(immutableTag bool) Option {
+	return func(opts *options) {
+		opts.immutableTag = immutableTag
+	}
+}
+
+// WithDigestOnly determines if the chart is pushed without tagging it.
+func WithDigestOnly(digestOnly bool) Option {
+	return func(opts *options) {
+		opts.digestOnly = digestOnly
+	}
+}
+
+// WithChunkSize sets the size of the chunks the chart is uploaded in.
+func WithChunkSize(size int64) Option {
+	return func(opts *options) {
+		opts.chunkSize = size
+	}
+}
+
+// WithResumeDir makes the upload of the chart resumable, with the upload
+// session recorded in dir.
+func WithResumeDir(dir string) Option {
+	return func(opts *options) {
+		opts.resumeDir = dir
+	}
+}
+
 // Pusher is an interface to support upload to the specified URL.
 type Pusher interface {
 	// Push file content by url string
@@ -27,6 +27,10 @@ import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+// licenseAnnotation is the chart annotation, as used by Artifact Hub, holding
+// the SPDX license expression of a chart.
+const licenseAnnotation = "artifacthub.io/license"
+
 var immutableOciAnnotations = []string{
 	ocispec.AnnotationVersion,
 	ocispec.AnnotationTitle,
@@ -82,6 +86,8 @@ func generateChartOCIAnnotations(meta *chart.Metadata, creationTime string) map[
 		chartOCIAnnotations = addToMap(chartOCIAnnotations, ocispec.AnnotationSource, meta.Sources[0])
 	}
 
+	chartOCIAnnotations = addToMap(chartOCIAnnotations, ocispec.AnnotationLicenses, meta.Annotations[licenseAnnotation])
+
 	if len(meta.Maintainers) > 0 {
 		var maintainerSb strings.Builder
 
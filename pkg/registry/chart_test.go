@@ -144,6 +144,20 @@ func TestGenerateOCIChartAnnotations(t *testing.T) {
 				"org.opencontainers.image.source":      "https://github.com/helm/helm",
 			},
 		},
+		{
+			"Chart with license",
+			&chart.Metadata{
+				Name:        "oci",
+				Version:     "0.0.1",
+				Annotations: map[string]string{"artifacthub.io/license": "Apache-2.0"},
+			},
+			map[string]string{
+				"org.opencontainers.image.title":    "oci",
+				"org.opencontainers.image.version":  "0.0.1",
+				"org.opencontainers.image.created":  nowString,
+				"org.opencontainers.image.licenses": "Apache-2.0",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -28,14 +28,18 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
@@ -76,6 +80,8 @@ type (
 		credentialsStore   credentials.Store
 		httpClient         *http.Client
 		plainHTTP          bool
+		hostConfigs        map[string]HostConfig
+		offline            bool
 	}
 
 	// ClientOption allows specifying various settings configurable by the user for overriding the defaults
@@ -100,6 +106,20 @@ func NewClient(options ...ClientOption) (*Client, error) {
 			Transport: NewTransport(client.debug),
 		}
 	}
+	if len(client.hostConfigs) > 0 {
+		transport, err := newHostTransport(client.httpClient.Transport, client.hostConfigs, client.debug)
+		if err != nil {
+			return nil, err
+		}
+		httpClient := *client.httpClient
+		httpClient.Transport = transport
+		client.httpClient = &httpClient
+	}
+	if client.offline {
+		httpClient := *client.httpClient
+		httpClient.Transport = offlineTransport{}
+		client.httpClient = &httpClient
+	}
 
 	storeOptions := credentials.StoreOptions{
 		AllowPlaintextPut:        true,
@@ -118,6 +138,9 @@ func NewClient(options ...ClientOption) (*Client, error) {
 		client.credentialsStore = credentials.NewStoreWithFallbacks(store, dockerStore)
 	}
 
+	if client.authorizer != nil && client.offline {
+		client.authorizer.Client = client.httpClient
+	}
 	if client.authorizer == nil {
 		authorizer := auth.Client{
 			Client: client.httpClient,
@@ -146,6 +169,15 @@ func (c *Client) Generic() *GenericClient {
 	return NewGenericClient(c)
 }
 
+// ClientOptOffline returns a function that disables network access of the
+// client. Operations needing the registry fail with an error wrapping
+// ErrOffline.
+func ClientOptOffline(offline bool) ClientOption {
+	return func(client *Client) {
+		client.offline = offline
+	}
+}
+
 // ClientOptDebug returns a function that sets the debug setting on client options set
 func ClientOptDebug(debug bool) ClientOption {
 	return func(client *Client) {
@@ -641,9 +673,18 @@ type (
 		provData     []byte
 		strictMode   bool
 		creationTime string
+		annotations  map[string]string
+		immutableTag bool
+		digestOnly   bool
+		chunkSize    int64
+		resumeDir    string
 	}
 )
 
+// ErrTagExists is returned by Push when the tag of an immutable push already
+// refers to different content.
+var ErrTagExists = errors.New("tag already exists")
+
 // Push uploads a chart to a registry.
 func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResult, error) {
 	parsedRef, err := newReference(ref)
@@ -703,6 +744,12 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 	})
 
 	ociAnnotations := generateOCIAnnotations(meta, operation.creationTime)
+	for k, v := range operation.annotations {
+		if slices.Contains(immutableOciAnnotations, k) {
+			return nil, fmt.Errorf("annotation %q is set from the chart and cannot be overridden", k)
+		}
+		ociAnnotations[k] = v
+	}
 
 	manifestDescriptor, err := c.tagManifest(ctx, memoryStore, configDescriptor,
 		layers, ociAnnotations, parsedRef)
@@ -717,9 +764,39 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 	repository.PlainHTTP = c.plainHTTP
 	repository.Client = c.authorizer
 
-	manifestDescriptor, err = oras.ExtendedCopy(ctx, memoryStore, parsedRef.String(), repository, parsedRef.String(), oras.DefaultExtendedCopyOptions)
-	if err != nil {
-		return nil, err
+	// An immutable tag is checked before anything is uploaded, so that a
+	// refused push leaves no blobs behind.
+	if operation.immutableTag && !operation.digestOnly {
+		existing, err := repository.Resolve(ctx, parsedRef.String())
+		switch {
+		case err == nil && existing.Digest != manifestDescriptor.Digest:
+			return nil, fmt.Errorf("%s: %w with digest %s", parsedRef.String(), ErrTagExists, existing.Digest)
+		case err != nil && !errors.Is(err, errdef.ErrNotFound):
+			return nil, err
+		}
+	}
+
+	if operation.chunkSize > 0 || operation.resumeDir != "" {
+		// The chart layer is the only large blob, so it is the only one
+		// uploaded in chunks. The copy below skips it once uploaded.
+		if err := c.pushChunked(ctx, repository, chartDescriptor, data, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	resultRef := parsedRef.String()
+	switch {
+	case operation.digestOnly:
+		// Push the manifest and its blobs without tagging it.
+		if err := oras.ExtendedCopyGraph(ctx, memoryStore, repository, manifestDescriptor, oras.DefaultExtendedCopyGraphOptions); err != nil {
+			return nil, err
+		}
+		resultRef = fmt.Sprintf("%s/%s@%s", parsedRef.Registry, parsedRef.Repository, manifestDescriptor.Digest)
+	default:
+		manifestDescriptor, err = oras.ExtendedCopy(ctx, memoryStore, parsedRef.String(), repository, parsedRef.String(), oras.DefaultExtendedCopyOptions)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	chartSummary := &descriptorPushSummaryWithMeta{
@@ -738,7 +815,7 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 		},
 		Chart: chartSummary,
 		Prov:  &descriptorPushSummary{}, // prevent nil references
-		Ref:   parsedRef.String(),
+		Ref:   resultRef,
 	}
 	if operation.provData != nil {
 		result.Prov = &descriptorPushSummary{
@@ -777,8 +854,195 @@ func PushOptCreationTime(creationTime string) PushOption {
 	}
 }
 
+// PushOptAnnotations returns a function that adds annotations to the manifest
+// on push, such as the org.opencontainers.image.revision of the chart source.
+// The title and version annotations cannot be overridden.
+func PushOptAnnotations(annotations map[string]string) PushOption {
+	return func(operation *pushOperation) {
+		operation.annotations = annotations
+	}
+}
+
+// PushOptImmutableTag returns a function that sets whether push refuses to
+// move a tag that already refers to different content, with an error
+// matching ErrTagExists.
+func PushOptImmutableTag(immutableTag bool) PushOption {
+	return func(operation *pushOperation) {
+		operation.immutableTag = immutableTag
+	}
+}
+
+// PushOptDigestOnly returns a function that sets whether the chart is pushed
+// without tagging it. The reference of the result then has the digest of the
+// manifest instead of the tag.
+func PushOptDigestOnly(digestOnly bool) PushOption {
+	return func(operation *pushOperation) {
+		operation.digestOnly = digestOnly
+	}
+}
+
+// PushOptChunkSize returns a function that sets the size of the chunks the
+// chart is uploaded in. A failed chunk is uploaded again from the last byte
+// received by the registry. A size of zero uploads the chart at once, unless
+// the push is resumable.
+func PushOptChunkSize(size int64) PushOption {
+	return func(operation *pushOperation) {
+		operation.chunkSize = size
+	}
+}
+
+// PushOptResumeDir returns a function that makes the upload of the chart
+// resumable: the upload session is recorded in dir, so that a push of the
+// same chart interrupted before it completed continues where it stopped. The
+// chart is uploaded in chunks of DefaultChunkSize unless PushOptChunkSize is
+// set.
+func PushOptResumeDir(dir string) PushOption {
+	return func(operation *pushOperation) {
+		operation.resumeDir = dir
+	}
+}
+
+type (
+	// CopyOption allows specifying various settings on copy
+	CopyOption func(*copyOperation)
+
+	// CopyResult is the result returned upon successful copy.
+	CopyResult struct {
+		Digest string `json:"digest"`
+		Size   int64  `json:"size"`
+		Ref    string `json:"ref"`
+	}
+
+	copyOperation struct {
+		immutableTag bool
+	}
+)
+
+// Copy copies a chart, and the artifacts attached to it such as signatures,
+// from one repository to another, which can be on a different registry. The
+// manifests and blobs are copied unchanged, so the chart keeps its digest.
+//
+// The source reference must have a tag or a digest. The destination
+// reference defaults to the tag of the source. When it has neither, such as
+// for a source referenced by digest, the chart is copied without tagging it.
+func (c *Client) Copy(src, dst string, options ...CopyOption) (*CopyResult, error) {
+	srcRef, err := newReference(src)
+	if err != nil {
+		return nil, err
+	}
+	if srcRef.Tag == "" && srcRef.Digest == "" {
+		return nil, fmt.Errorf("source reference %q must have a tag or a digest", src)
+	}
+	dstRef, err := newReference(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := &copyOperation{}
+	for _, option := range options {
+		option(operation)
+	}
+
+	srcRepository, err := c.repository(srcRef)
+	if err != nil {
+		return nil, err
+	}
+	dstRepository, err := c.repository(dstRef)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	desc, err := srcRepository.Resolve(ctx, srcRef.String())
+	if err != nil {
+		return nil, err
+	}
+	if srcRef.Digest != "" && desc.Digest.String() != srcRef.Digest {
+		return nil, fmt.Errorf("chart reference digest mismatch: %s is not %s", desc.Digest.String(), srcRef.Digest)
+	}
+	if dstRef.Digest != "" && desc.Digest.String() != dstRef.Digest {
+		return nil, fmt.Errorf("cannot copy %s with digest %s to %s", src, desc.Digest.String(), dst)
+	}
+
+	tag := dstRef.Tag
+	if tag == "" && dstRef.Digest == "" {
+		tag = srcRef.Tag
+	}
+	if tag != "" && operation.immutableTag {
+		existing, err := dstRepository.Resolve(ctx, tag)
+		switch {
+		case err == nil && existing.Digest != desc.Digest:
+			return nil, fmt.Errorf("%s/%s:%s: %w with digest %s", dstRef.Registry, dstRef.Repository, tag, ErrTagExists, existing.Digest)
+		case err != nil && !errors.Is(err, errdef.ErrNotFound):
+			return nil, err
+		}
+	}
+
+	if err := oras.ExtendedCopyGraph(ctx, srcRepository, dstRepository, desc, oras.DefaultExtendedCopyGraphOptions); err != nil {
+		return nil, err
+	}
+	result := &CopyResult{
+		Digest: desc.Digest.String(),
+		Size:   desc.Size,
+		Ref:    fmt.Sprintf("%s/%s@%s", dstRef.Registry, dstRef.Repository, desc.Digest),
+	}
+	if tag != "" {
+		if err := dstRepository.Tag(ctx, desc, tag); err != nil {
+			return nil, err
+		}
+		result.Ref = fmt.Sprintf("%s/%s:%s", dstRef.Registry, dstRef.Repository, tag)
+	}
+
+	_, _ = fmt.Fprintf(c.out, "Copied: %s\n", result.Ref)
+	_, _ = fmt.Fprintf(c.out, "Digest: %s\n", result.Digest)
+	return result, nil
+}
+
+// CopyOptImmutableTag returns a function that sets whether copy refuses to
+// move a tag that already refers to different content, with an error
+// matching ErrTagExists.
+func CopyOptImmutableTag(immutableTag bool) CopyOption {
+	return func(operation *copyOperation) {
+		operation.immutableTag = immutableTag
+	}
+}
+
+// repository returns the remote repository of a reference.
+func (c *Client) repository(ref reference) (*remote.Repository, error) {
+	repository, err := remote.NewRepository(ref.Registry + "/" + ref.Repository)
+	if err != nil {
+		return nil, err
+	}
+	repository.PlainHTTP = c.plainHTTP
+	repository.Client = c.authorizer
+	return repository, nil
+}
+
+type (
+	// TagsOption allows specifying various settings on listing tags
+	TagsOption func(*tagsOperation)
+
+	tagsOperation struct {
+		pageSize   int
+		constraint *semver.Constraints
+		limit      int
+	}
+)
+
 // Tags provides a sorted list all semver compliant tags for a given repository
 func (c *Client) Tags(ref string) ([]string, error) {
+	return c.ListTags(ref)
+}
+
+// ListTags provides the semver compliant tags of a repository, sorted from
+// the highest to the lowest version. The tag list is read page by page, and
+// the tags can be filtered with a version constraint.
+func (c *Client) ListTags(ref string, options ...TagsOption) ([]string, error) {
+	operation := &tagsOperation{}
+	for _, option := range options {
+		option(operation)
+	}
+
 	parsedReference, err := registry.ParseReference(ref)
 	if err != nil {
 		return nil, err
@@ -791,6 +1055,9 @@ func (c *Client) Tags(ref string) ([]string, error) {
 	}
 	repository.PlainHTTP = c.plainHTTP
 	repository.Client = c.authorizer
+	if operation.pageSize > 0 {
+		repository.TagListPageSize = operation.pageSize
+	}
 
 	var tagVersions []*semver.Version
 	err = repository.Tags(ctx, "", func(tags []string) error {
@@ -798,9 +1065,13 @@ func (c *Client) Tags(ref string) ([]string, error) {
 			// Change underscore (_) back to plus (+) for Helm
 			// See https://github.com/helm/helm/issues/10166
 			tagVersion, err := semver.StrictNewVersion(strings.ReplaceAll(tag, "_", "+"))
-			if err == nil {
-				tagVersions = append(tagVersions, tagVersion)
+			if err != nil {
+				continue
 			}
+			if operation.constraint != nil && !operation.constraint.Check(tagVersion) {
+				continue
+			}
+			tagVersions = append(tagVersions, tagVersion)
 		}
 
 		return nil
@@ -811,6 +1082,9 @@ func (c *Client) Tags(ref string) ([]string, error) {
 
 	// Sort the collection
 	sort.Sort(sort.Reverse(semver.Collection(tagVersions)))
+	if operation.limit > 0 && len(tagVersions) > operation.limit {
+		tagVersions = tagVersions[:operation.limit]
+	}
 
 	tags := make([]string, len(tagVersions))
 
@@ -821,6 +1095,141 @@ func (c *Client) Tags(ref string) ([]string, error) {
 	return tags, nil
 }
 
+// TagsOptPageSize returns a function that sets the number of tags requested
+// from the registry per page. The registry default is used when it is not set.
+func TagsOptPageSize(pageSize int) TagsOption {
+	return func(operation *tagsOperation) {
+		operation.pageSize = pageSize
+	}
+}
+
+// TagsOptConstraint returns a function that sets the version constraint the
+// listed tags must satisfy. Prereleases only satisfy constraints that have a
+// prerelease, such as ">0.0.0-0".
+func TagsOptConstraint(constraint *semver.Constraints) TagsOption {
+	return func(operation *tagsOperation) {
+		operation.constraint = constraint
+	}
+}
+
+// TagsOptLimit returns a function that sets the maximum number of tags
+// listed, keeping the highest versions.
+func TagsOptLimit(limit int) TagsOption {
+	return func(operation *tagsOperation) {
+		operation.limit = limit
+	}
+}
+
+// ErrNotChart is returned by Inspect for artifacts that are not charts.
+var ErrNotChart = errors.New("artifact is not a Helm chart")
+
+// Repositories lists the repositories of a registry in the namespace of ref,
+// such as registry.example.com/charts, using the catalog API of the registry.
+// The repositories are returned as sorted references without the oci://
+// prefix. Some registries do not implement the catalog API.
+func (c *Client) Repositories(ref string) ([]string, error) {
+	host, namespace, _ := strings.Cut(strings.TrimPrefix(ref, OCIScheme+"://"), "/")
+	namespace = strings.Trim(namespace, "/")
+
+	reg, err := remote.NewRegistry(host)
+	if err != nil {
+		return nil, err
+	}
+	reg.PlainHTTP = c.plainHTTP
+	reg.Client = c.authorizer
+
+	var repositories []string
+	err = reg.Repositories(context.Background(), "", func(names []string) error {
+		for _, name := range names {
+			if namespace == "" || name == namespace || strings.HasPrefix(name, namespace+"/") {
+				repositories = append(repositories, host+"/"+name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing the repositories of %s: %w", host, err)
+	}
+	sort.Strings(repositories)
+	return repositories, nil
+}
+
+type (
+	// InspectResult describes a chart in a registry without its content.
+	InspectResult struct {
+		Manifest    *descriptorPushSummary         `json:"manifest"`
+		Chart       *descriptorPushSummaryWithMeta `json:"chart"`
+		Prov        *descriptorPushSummary         `json:"prov,omitempty"`
+		Annotations map[string]string              `json:"annotations,omitempty"`
+		Ref         string                         `json:"ref"`
+	}
+)
+
+// Inspect reads the manifest and the metadata of a chart in a registry
+// without downloading the chart. It returns an error matching ErrNotChart
+// for other artifacts.
+func (c *Client) Inspect(ref string) (*InspectResult, error) {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	repository, err := c.repository(parsedRef)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	manifestDescriptor, manifestData, err := oras.FetchBytes(ctx, repository, parsedRef.String(), oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return nil, err
+	}
+	if manifestDescriptor.MediaType != ocispec.MediaTypeImageManifest {
+		return nil, fmt.Errorf("%s: %w, it has the media type %s", parsedRef.String(), ErrNotChart, manifestDescriptor.MediaType)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Config.MediaType != ConfigMediaType {
+		return nil, fmt.Errorf("%s: %w, its config has the media type %s", parsedRef.String(), ErrNotChart, manifest.Config.MediaType)
+	}
+
+	configData, err := content.FetchAll(ctx, repository, manifest.Config)
+	if err != nil {
+		return nil, err
+	}
+	meta := &chart.Metadata{}
+	if err := json.Unmarshal(configData, meta); err != nil {
+		return nil, fmt.Errorf("%s: invalid chart metadata: %w", parsedRef.String(), err)
+	}
+
+	result := &InspectResult{
+		Manifest: &descriptorPushSummary{
+			Digest: manifestDescriptor.Digest.String(),
+			Size:   manifestDescriptor.Size,
+		},
+		Annotations: manifest.Annotations,
+		Ref:         parsedRef.String(),
+	}
+	for _, layer := range manifest.Layers {
+		switch layer.MediaType {
+		case ChartLayerMediaType, LegacyChartLayerMediaType:
+			result.Chart = &descriptorPushSummaryWithMeta{Meta: meta}
+			result.Chart.Digest = layer.Digest.String()
+			result.Chart.Size = layer.Size
+		case ProvLayerMediaType:
+			result.Prov = &descriptorPushSummary{
+				Digest: layer.Digest.String(),
+				Size:   layer.Size,
+			}
+		}
+	}
+	if result.Chart == nil {
+		return nil, fmt.Errorf("%s: %w, it has no chart layer", parsedRef.String(), ErrNotChart)
+	}
+	return result, nil
+}
+
 // Resolve a reference to a descriptor.
 func (c *Client) Resolve(ref string) (desc ocispec.Descriptor, err error) {
 	remoteRepository, err := remote.NewRepository(ref)
@@ -859,9 +1268,13 @@ func (c *Client) ValidateReference(ref, version string, u *url.URL) (string, *ur
 	}
 
 	if registryReference.Digest != "" {
+		if _, err := digest.Parse(registryReference.Digest); err != nil {
+			return "", nil, fmt.Errorf("invalid digest %q in reference %s: %w", registryReference.Digest, ref, err)
+		}
 		if version == "" {
-			// Install by digest only
-			return "", u, nil
+			// Install by digest only. The manifest digest identifies the
+			// chart, so it is the key of the chart in the cache.
+			return registryReference.Digest, u, nil
 		}
 		u.Path = fmt.Sprintf("%s@%s", registryReference.Repository, registryReference.Digest)
 
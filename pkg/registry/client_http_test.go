@@ -78,6 +78,18 @@ func (suite *HTTPRegistryClientTestSuite) Test_5_ImageIndex() {
 	suite.Require().NoError(err)
 }
 
+func (suite *HTTPRegistryClientTestSuite) Test_6_Copy() {
+	testCopy(&suite.TestRegistry)
+}
+
+func (suite *HTTPRegistryClientTestSuite) Test_7_Inspect() {
+	testInspect(&suite.TestRegistry)
+}
+
+func (suite *HTTPRegistryClientTestSuite) Test_8_PushChunked() {
+	testPushChunked(&suite.TestRegistry)
+}
+
 func TestHTTPRegistryClientTestSuite(t *testing.T) {
 	suite.Run(t, new(HTTPRegistryClientTestSuite))
 }
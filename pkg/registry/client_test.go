@@ -153,3 +153,26 @@ func TestWarnIfHostHasPath(t *testing.T) {
 		})
 	}
 }
+
+func TestClientOffline(t *testing.T) {
+	requested := false
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		requested = true
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	c, err := NewClient(
+		ClientOptWriter(io.Discard),
+		ClientOptCredentialsFile(filepath.Join(t.TempDir(), "config.json")),
+		ClientOptPlainHTTP(),
+		ClientOptOffline(true),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Tags(host + "/charts/mychart")
+	require.ErrorIs(t, err, ErrOffline)
+	_, err = c.Pull(host + "/charts/mychart:1.0.0")
+	require.ErrorIs(t, err, ErrOffline)
+	assert.False(t, requested, "no request should reach the registry")
+}
@@ -0,0 +1,98 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake implements an in-memory OCI registry, so that the actions
+// pulling charts from registries can be unit tested without a remote registry.
+package fake
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/distribution/distribution/v3/registry/handlers"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory" // the storage of the registry
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// Registry is an OCI registry storing its content in memory, served over
+// plain HTTP on the loopback interface. It requires no authentication.
+type Registry struct {
+	// Host is the host and port of the registry, e.g. to build the
+	// references of the charts as oci://<Host>/<repository>.
+	Host string
+
+	server *httptest.Server
+	client *registry.Client
+	dir    string
+}
+
+// NewRegistry starts a registry. It must be closed once done.
+func NewRegistry() (*Registry, error) {
+	config := &configuration.Configuration{}
+	config.Storage = configuration.Storage{"inmemory": configuration.Parameters{}}
+	config.Log.Level = "error"
+	config.Log.AccessLog.Disabled = true
+	server := httptest.NewServer(handlers.NewApp(context.Background(), config))
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	// The credentials file is kept away from the one of the user.
+	dir, err := os.MkdirTemp("", "helm-fake-registry-")
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	client, err := registry.NewClient(
+		registry.ClientOptPlainHTTP(),
+		registry.ClientOptCredentialsFile(filepath.Join(dir, "config.json")))
+	if err != nil {
+		server.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Registry{Host: u.Host, server: server, client: client, dir: dir}, nil
+}
+
+// Client returns a registry client using the registry. It can be set as the
+// registry client of an action.Configuration.
+func (r *Registry) Client() *registry.Client {
+	return r.client
+}
+
+// PushChart pushes a chart archive to the repository of the registry named
+// after the chart, e.g. charts/mychart for the "charts" path, and returns its
+// reference without the oci:// prefix.
+func (r *Registry) PushChart(archive []byte, path, name, version string) (string, error) {
+	ref := r.Host + "/" + path + "/" + name + ":" + version
+	if _, err := r.client.Push(archive, ref); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// Close stops the registry and discards its content.
+func (r *Registry) Close() {
+	r.server.Close()
+	os.RemoveAll(r.dir)
+}
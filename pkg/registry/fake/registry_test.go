@@ -0,0 +1,53 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestRegistry(t *testing.T) {
+	r, err := NewRegistry()
+	require.NoError(t, err)
+	defer r.Close()
+
+	path, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "hello", Version: "0.1.0"},
+	}, t.TempDir())
+	require.NoError(t, err)
+	archive, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	ref, err := r.PushChart(archive, "charts", "hello", "0.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, r.Host+"/charts/hello:0.1.0", ref)
+
+	result, err := r.Client().Pull(ref)
+	require.NoError(t, err)
+	assert.Equal(t, archive, result.Chart.Data)
+
+	tags, err := r.Client().Tags(r.Host + "/charts/hello")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.1.0"}, tags)
+}
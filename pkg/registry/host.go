@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v4/pkg/registry"
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"oras.land/oras-go/v2/registry/remote/retry"
+
+	"helm.sh/helm/v4/internal/tlsutil"
+)
+
+// HostConfig contains the connection settings used for a single registry
+// host, overriding the settings of the client.
+type HostConfig struct {
+	CertFile              string
+	KeyFile               string
+	CAFile                string
+	InsecureSkipTLSVerify bool
+	// Proxy is the URL of the HTTP proxy to use for the host. When empty the
+	// proxy is configured through the environment.
+	Proxy string
+}
+
+// ClientOptHostConfigs returns a function that sets per host connection
+// settings on a client options set. Hosts are matched on "host:port" first and
+// on the host name second.
+func ClientOptHostConfigs(configs map[string]HostConfig) ClientOption {
+	return func(client *Client) {
+		client.hostConfigs = configs
+	}
+}
+
+// hostTransport dispatches requests to a transport configured for the
+// requested registry host, falling back to a default transport.
+type hostTransport struct {
+	fallback http.RoundTripper
+	hosts    map[string]http.RoundTripper
+}
+
+func newHostTransport(fallback http.RoundTripper, configs map[string]HostConfig, debug bool) (*hostTransport, error) {
+	if fallback == nil {
+		fallback = http.DefaultTransport
+	}
+	t := &hostTransport{
+		fallback: fallback,
+		hosts:    make(map[string]http.RoundTripper, len(configs)),
+	}
+	for host, cfg := range configs {
+		tlsConf, err := tlsutil.NewTLSConfig(
+			tlsutil.WithInsecureSkipVerify(cfg.InsecureSkipTLSVerify),
+			tlsutil.WithCertKeyPairFiles(cfg.CertFile, cfg.KeyFile),
+			tlsutil.WithCAFile(cfg.CAFile),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("can't create TLS config for registry %q: %w", host, err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConf
+		if cfg.Proxy != "" {
+			u, err := url.Parse(cfg.Proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy URL for registry %q: %w", host, err)
+			}
+			transport.Proxy = http.ProxyURL(u)
+		}
+
+		var rt http.RoundTripper = transport
+		if debug {
+			rt = &LoggingTransport{RoundTripper: rt}
+		}
+		t.hosts[host] = retry.NewTransport(rt)
+	}
+	return t, nil
+}
+
+// RoundTrip sends the request using the transport configured for its host.
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt, ok := t.hosts[req.URL.Host]; ok {
+		return rt.RoundTrip(req)
+	}
+	if rt, ok := t.hosts[req.URL.Hostname()]; ok {
+		return rt.RoundTrip(req)
+	}
+	return t.fallback.RoundTrip(req)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingRoundTripper struct {
+	name  string
+	calls *[]string
+}
+
+func (r recordingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	*r.calls = append(*r.calls, r.name)
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestHostTransportDispatch(t *testing.T) {
+	var calls []string
+	ht := &hostTransport{
+		fallback: recordingRoundTripper{"default", &calls},
+		hosts: map[string]http.RoundTripper{
+			"registry.example.com:5000": recordingRoundTripper{"with-port", &calls},
+			"other.example.com":         recordingRoundTripper{"hostname", &calls},
+		},
+	}
+
+	for _, u := range []string{
+		"https://registry.example.com:5000/v2/",
+		"https://other.example.com:443/v2/",
+		"https://registry.example.com/v2/",
+	} {
+		req, err := http.NewRequest(http.MethodGet, u, http.NoBody)
+		require.NoError(t, err)
+		resp, err := ht.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, []string{"with-port", "hostname", "default"}, calls)
+}
+
+func TestNewClientWithHostConfigs(t *testing.T) {
+	_, err := NewClient(
+		ClientOptCredentialsFile(t.TempDir()+"/config.json"),
+		ClientOptHostConfigs(map[string]HostConfig{"localhost:5000": {InsecureSkipTLSVerify: true}}),
+	)
+	require.NoError(t, err)
+
+	_, err = NewClient(
+		ClientOptCredentialsFile(t.TempDir()+"/config.json"),
+		ClientOptHostConfigs(map[string]HostConfig{"localhost:5000": {Proxy: "://bad"}}),
+	)
+	assert.Error(t, err)
+
+	_, err = NewClient(
+		ClientOptCredentialsFile(t.TempDir()+"/config.json"),
+		ClientOptHostConfigs(map[string]HostConfig{"localhost:5000": {CAFile: "does-not-exist.pem"}}),
+	)
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysLayer(t *testing.T) {
+	titled := func(mediaType, title string) ocispec.Descriptor {
+		return ocispec.Descriptor{
+			MediaType:   mediaType,
+			Annotations: map[string]string{ocispec.AnnotationTitle: title},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		layers []ocispec.Descriptor
+		want   string
+		found  bool
+	}{
+		{
+			name:   "keys media type",
+			layers: []ocispec.Descriptor{titled("text/plain", "signer.asc"), titled(KeysLayerMediaType, "keys")},
+			want:   "keys",
+			found:  true,
+		},
+		{
+			name:   "titled key file",
+			layers: []ocispec.Descriptor{titled("text/plain", "README.md"), titled("application/octet-stream", "pubring.gpg")},
+			want:   "pubring.gpg",
+			found:  true,
+		},
+		{
+			name:   "no keys",
+			layers: []ocispec.Descriptor{titled(ChartLayerMediaType, "mychart-0.1.0.tgz")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, ok := keysLayer(tt.layers)
+			assert.Equal(t, tt.found, ok)
+			if ok {
+				assert.Equal(t, tt.want, l.Annotations[ocispec.AnnotationTitle])
+			}
+		})
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package registry
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/opencontainers/go-digest"
 	"oras.land/oras-go/v2/registry"
 )
 
@@ -77,6 +79,23 @@ func (r *reference) String() string {
 	return r.orasReference.String()
 }
 
+// ReferenceDigest returns the manifest digest an OCI reference is pinned to,
+// such as "sha256:..." for oci://registry/chart@sha256:..., or an empty
+// string for a reference by tag.
+func ReferenceDigest(ref string) (string, error) {
+	r, err := newReference(ref)
+	if err != nil {
+		return "", err
+	}
+	if r.Digest == "" {
+		return "", nil
+	}
+	if _, err := digest.Parse(r.Digest); err != nil {
+		return "", fmt.Errorf("invalid digest %q in reference %s: %w", r.Digest, ref, err)
+	}
+	return r.Digest, nil
+}
+
 // IsOCI determines whether a URL is to be treated as an OCI URL
 func IsOCI(url string) bool {
 	return strings.HasPrefix(url, OCIScheme+"://")
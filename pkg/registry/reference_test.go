@@ -72,3 +72,27 @@ func TestNewReference(t *testing.T) {
 	assert.NoError(t, err)
 	verify(t, actual, "registry.example.com", "the/repository", "", "sha256:c6841b3a895f1444a6738b5d04564a57e860ce42f8519c3be807fb6d9bee7888")
 }
+
+func TestReferenceDigest(t *testing.T) {
+	const sum = "sha256:ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "oci://example.com/charts/app:1.0.0"},
+		{ref: "oci://example.com/charts/app@" + sum, want: sum},
+		{ref: "example.com/charts/app:1.0.0@" + sum, want: sum},
+		{ref: "oci://example.com/charts/app@sha256:abc", wantErr: true},
+		{ref: "oci://example.com/charts/app@md5:ca978112ca1bbdcafac231b39a23dc4d", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ReferenceDigest(tt.ref)
+		if tt.wantErr {
+			assert.Error(t, err, tt.ref)
+			continue
+		}
+		assert.NoError(t, err, tt.ref)
+		assert.Equal(t, tt.want, got, tt.ref)
+	}
+}
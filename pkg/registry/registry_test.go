@@ -30,6 +30,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/distribution/distribution/v3/configuration"
 	"github.com/distribution/distribution/v3/registry"
 	_ "github.com/distribution/distribution/v3/registry/auth/htpasswd"
@@ -138,6 +139,7 @@ func setup(suite *TestRegistry, tlsEnabled, insecure bool) {
 	config.HTTP.Addr = ln.Addr().String()
 	config.HTTP.DrainTimeout = time.Duration(10) * time.Second
 	config.Storage = map[string]configuration.Parameters{"inmemory": map[string]any{}}
+	config.Catalog.MaxEntries = 1000
 
 	config.Auth = configuration.Auth{
 		"htpasswd": configuration.Parameters{
@@ -463,6 +465,31 @@ func testPush(suite *TestRegistry) {
 	suite.Equal(
 		"sha256:b0a02b7412f78ae93324d48df8fcc316d8482e5ad7827b5b238657a29a22f256",
 		result.Prov.Digest)
+
+	// immutable tag, pushing the same content again is allowed
+	_, err = suite.RegistryClient.Push(chartData, ref, PushOptProvData(provData), PushOptCreationTime(testingChartCreationTime), PushOptImmutableTag(true))
+	suite.Require().NoError(err, "no error pushing the same content to an immutable tag")
+
+	// immutable tag, different content is refused
+	_, err = suite.RegistryClient.Push(chartData, ref, PushOptCreationTime("2000-01-01T00:00:00Z"), PushOptImmutableTag(true))
+	suite.Require().ErrorIs(err, ErrTagExists, "error moving an immutable tag")
+
+	// annotations set from the chart cannot be overridden
+	_, err = suite.RegistryClient.Push(chartData, ref, PushOptAnnotations(map[string]string{"org.opencontainers.image.version": "9.9.9"}))
+	suite.Require().Error(err, "error overriding the version annotation")
+
+	// push by digest only, with an extra annotation
+	result, err = suite.RegistryClient.Push(chartData, ref,
+		PushOptCreationTime("2000-01-01T00:00:00Z"),
+		PushOptDigestOnly(true),
+		PushOptAnnotations(map[string]string{"org.opencontainers.image.revision": "0123abcd"}))
+	suite.Require().NoError(err, "no error pushing by digest only")
+	suite.Equal(fmt.Sprintf("%s/testrepo/%s@%s", suite.DockerRegistryHost, meta.Name, result.Manifest.Digest), result.Ref)
+	desc, err := suite.RegistryClient.Resolve(ref)
+	suite.Require().NoError(err)
+	suite.Equal("sha256:fbbade96da6050f68f94f122881e3b80051a18f13ab5f4081868dd494538f5c2", desc.Digest.String(), "tag not moved by a push by digest")
+	_, err = suite.RegistryClient.Pull(result.Ref)
+	suite.Require().NoError(err, "no error pulling a chart pushed by digest")
 }
 
 func testPull(suite *TestRegistry) {
@@ -544,6 +571,97 @@ func testPull(suite *TestRegistry) {
 	suite.Equal(provData, result.Prov.Data)
 }
 
+func testCopy(suite *TestRegistry) {
+	chartData, err := os.ReadFile("../downloader/testdata/signtest-0.1.0.tgz")
+	suite.Require().NoError(err, "no error loading test chart")
+	meta, err := extractChartMeta(chartData)
+	suite.Require().NoError(err, "no error extracting chart meta")
+	src := fmt.Sprintf("%s/testrepo/%s:%s", suite.DockerRegistryHost, meta.Name, meta.Version)
+	srcDesc, err := suite.RegistryClient.Resolve(src)
+	suite.Require().NoError(err)
+
+	// source without a tag or digest
+	_, err = suite.RegistryClient.Copy(fmt.Sprintf("%s/testrepo/%s", suite.DockerRegistryHost, meta.Name), src)
+	suite.Require().Error(err, "error copying a source without a tag or digest")
+
+	// destination defaults to the source tag, digests are preserved
+	dst := fmt.Sprintf("%s/promoted/%s", suite.DockerRegistryHost, meta.Name)
+	result, err := suite.RegistryClient.Copy(src, dst, CopyOptImmutableTag(true))
+	suite.Require().NoError(err, "no error copying a chart")
+	suite.Equal(dst+":"+meta.Version, result.Ref)
+	suite.Equal(srcDesc.Digest.String(), result.Digest)
+	pulled, err := suite.RegistryClient.Pull(result.Ref, PullOptWithProv(true))
+	suite.Require().NoError(err, "no error pulling a copied chart")
+	suite.Equal(srcDesc.Digest.String(), pulled.Manifest.Digest)
+	suite.Equal(chartData, pulled.Chart.Data)
+
+	// copying the same chart to an immutable tag again is allowed
+	_, err = suite.RegistryClient.Copy(src, result.Ref, CopyOptImmutableTag(true))
+	suite.Require().NoError(err, "no error copying the same chart to an immutable tag")
+
+	// a different chart does not move an immutable tag
+	other := fmt.Sprintf("%s/testrepo/local-subchart:0.1.0", suite.DockerRegistryHost)
+	_, err = suite.RegistryClient.Copy(other, result.Ref, CopyOptImmutableTag(true))
+	suite.Require().ErrorIs(err, ErrTagExists, "error moving an immutable tag")
+	_, err = suite.RegistryClient.Copy(other, result.Ref)
+	suite.Require().NoError(err, "no error moving a mutable tag")
+
+	// copy by digest without tagging
+	byDigest := fmt.Sprintf("%s/testrepo/%s@%s", suite.DockerRegistryHost, meta.Name, srcDesc.Digest)
+	result, err = suite.RegistryClient.Copy(byDigest, fmt.Sprintf("%s/mirror/%s", suite.DockerRegistryHost, meta.Name))
+	suite.Require().NoError(err, "no error copying a chart by digest")
+	suite.Equal(fmt.Sprintf("%s/mirror/%s@%s", suite.DockerRegistryHost, meta.Name, srcDesc.Digest), result.Ref)
+	_, err = suite.RegistryClient.Pull(result.Ref)
+	suite.Require().NoError(err, "no error pulling a chart copied by digest")
+
+	// destination digest must match
+	_, err = suite.RegistryClient.Copy(byDigest, fmt.Sprintf("%s/mirror/%s@sha256:%064d", suite.DockerRegistryHost, meta.Name, 0))
+	suite.Require().Error(err, "error copying to a different digest")
+}
+
+func testPushChunked(suite *TestRegistry) {
+	chartData, err := os.ReadFile("../repo/v1/repotest/testdata/examplechart-0.1.0.tgz")
+	suite.Require().NoError(err, "no error loading test chart")
+	meta, err := extractChartMeta(chartData)
+	suite.Require().NoError(err, "no error extracting chart meta")
+
+	resumeDir := filepath.Join(suite.WorkspaceDir, "uploads")
+	ref := fmt.Sprintf("%s/chunked/%s:%s", suite.DockerRegistryHost, meta.Name, meta.Version)
+	result, err := suite.RegistryClient.Push(chartData, ref, PushOptChunkSize(256), PushOptResumeDir(resumeDir))
+	suite.Require().NoError(err, "no error pushing a chart in chunks")
+	suite.Equal(int64(len(chartData)), result.Chart.Size)
+
+	entries, err := os.ReadDir(resumeDir)
+	suite.Require().NoError(err)
+	suite.Empty(entries, "the state of a completed upload is removed")
+
+	pulled, err := suite.RegistryClient.Pull(ref)
+	suite.Require().NoError(err, "no error pulling a chart pushed in chunks")
+	suite.Equal(chartData, pulled.Chart.Data)
+}
+
+func testInspect(suite *TestRegistry) {
+	repositories, err := suite.RegistryClient.Repositories(suite.DockerRegistryHost + "/testrepo")
+	suite.Require().NoError(err, "no error listing repositories")
+	suite.Contains(repositories, suite.DockerRegistryHost+"/testrepo/signtest")
+	for _, r := range repositories {
+		suite.True(strings.HasPrefix(r, suite.DockerRegistryHost+"/testrepo/"), "repository %s in namespace", r)
+	}
+
+	ref := suite.DockerRegistryHost + "/testrepo/signtest:0.1.0"
+	result, err := suite.RegistryClient.Inspect(ref)
+	suite.Require().NoError(err, "no error inspecting a chart")
+	suite.Equal(ref, result.Ref)
+	suite.Equal("signtest", result.Chart.Meta.Name)
+	suite.Equal("sha256:e5ef611620fb97704d8751c16bab17fedb68883bfb0edc76f78a70e9173f9b55", result.Chart.Digest)
+	suite.Require().NotNil(result.Prov)
+	suite.Equal(int64(695), result.Prov.Size)
+	suite.Equal("0.1.0", result.Annotations[ocispec.AnnotationVersion])
+
+	_, err = suite.RegistryClient.Inspect(suite.FakeRegistryHost + "/testrepo/image-index:0.1.0")
+	suite.Require().ErrorIs(err, ErrNotChart, "error inspecting an image index")
+}
+
 func testTags(suite *TestRegistry) {
 	// Load test chart (to build ref pushed in previous test)
 	chartData, err := os.ReadFile("../downloader/testdata/local-subchart-0.1.0.tgz")
@@ -556,4 +674,54 @@ func testTags(suite *TestRegistry) {
 	tags, err := suite.RegistryClient.Tags(ref)
 	suite.Require().NoError(err, "no error retrieving tags")
 	suite.Len(tags, 1)
+
+	// Push several versions to a repository
+	ref = fmt.Sprintf("%s/testrepo/versions", suite.DockerRegistryHost)
+	for _, tag := range []string{"0.1.0", "1.0.0-rc.1", "0.2.0", "latest", "1.0.0", "1.1.0"} {
+		_, err = suite.RegistryClient.Push(chartData, ref+":"+tag, PushOptStrictMode(false))
+		suite.Require().NoError(err, "no error pushing %s", tag)
+	}
+
+	tests := []struct {
+		name       string
+		constraint string
+		options    []TagsOption
+		want       []string
+	}{
+		{
+			name: "all versions",
+			want: []string{"1.1.0", "1.0.0", "1.0.0-rc.1", "0.2.0", "0.1.0"},
+		},
+		{
+			name:    "paginated",
+			options: []TagsOption{TagsOptPageSize(2)},
+			want:    []string{"1.1.0", "1.0.0", "1.0.0-rc.1", "0.2.0", "0.1.0"},
+		},
+		{
+			name:       "constraint",
+			constraint: "^1.0.0",
+			want:       []string{"1.1.0", "1.0.0"},
+		},
+		{
+			name:       "constraint with prereleases",
+			constraint: ">=1.0.0-0",
+			want:       []string{"1.1.0", "1.0.0", "1.0.0-rc.1"},
+		},
+		{
+			name:    "limit",
+			options: []TagsOption{TagsOptLimit(2), TagsOptPageSize(1)},
+			want:    []string{"1.1.0", "1.0.0"},
+		},
+	}
+	for _, tt := range tests {
+		options := tt.options
+		if tt.constraint != "" {
+			constraint, err := semver.NewConstraint(tt.constraint)
+			suite.Require().NoError(err)
+			options = append(options, TagsOptConstraint(constraint))
+		}
+		tags, err := suite.RegistryClient.ListTags(ref, options...)
+		suite.Require().NoError(err, tt.name)
+		suite.Equal(tt.want, tags, tt.name)
+	}
 }
@@ -18,6 +18,7 @@ package registry
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -161,3 +162,16 @@ func isPrintableContentType(contentType string) bool {
 func containsCredentials(body string) bool {
 	return strings.Contains(body, `"token"`) || strings.Contains(body, `"access_token"`)
 }
+
+// ErrOffline indicates that an operation needed network access while Helm is
+// running in offline mode.
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+// offlineTransport is an http.RoundTripper failing every request with
+// ErrOffline.
+type offlineTransport struct{}
+
+// RoundTrip implements http.RoundTripper.
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), ErrOffline)
+}
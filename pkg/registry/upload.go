@@ -0,0 +1,326 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// DefaultChunkSize is the size of the chunks of a resumable upload when no
+// chunk size is set.
+const DefaultChunkSize = 8 << 20
+
+// maxChunkRetries is the number of times the upload of a chunk is retried
+// after a failure, from the offset reported by the registry.
+const maxChunkRetries = 3
+
+// chunkedUpload uploads a blob in chunks with the chunked upload API of the
+// distribution spec, so that an upload interrupted by a network failure
+// continues from the last chunk received by the registry.
+//
+// When stateDir is set, the upload session is recorded in it after every
+// chunk, so that a later push of the same blob resumes the session.
+type chunkedUpload struct {
+	client    RemoteClient
+	ref       registry.Reference
+	plainHTTP bool
+	desc      ocispec.Descriptor
+	data      []byte
+	chunkSize int64
+	stateDir  string
+
+	location *url.URL
+}
+
+// uploadState is the recorded session of an interrupted upload.
+type uploadState struct {
+	Repository string        `json:"repository"`
+	Digest     digest.Digest `json:"digest"`
+	Location   string        `json:"location"`
+}
+
+// pushChunked uploads the blob desc with data to the repository in chunks,
+// unless the repository already has it.
+func (c *Client) pushChunked(ctx context.Context, repository *remote.Repository, desc ocispec.Descriptor, data []byte, operation *pushOperation) error {
+	exists, err := repository.Blobs().Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	u := &chunkedUpload{
+		client:    repository.Client,
+		ref:       repository.Reference,
+		plainHTTP: repository.PlainHTTP,
+		desc:      desc,
+		data:      data,
+		chunkSize: operation.chunkSize,
+		stateDir:  operation.resumeDir,
+	}
+	if exists {
+		u.removeState()
+		return nil
+	}
+	return u.run(ctx)
+}
+
+func (u *chunkedUpload) run(ctx context.Context) error {
+	// Pushing usually requires both the pull and the push actions.
+	ctx = auth.AppendRepositoryScope(ctx, u.ref, auth.ActionPull, auth.ActionPush)
+	chunkSize := u.chunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	size := int64(len(u.data))
+
+	var offset int64
+	if location := u.loadState(); location != nil {
+		u.location = location
+		o, err := u.status(ctx)
+		if err != nil {
+			// The session expired, or was cancelled by the registry.
+			u.location = nil
+		} else {
+			offset = o
+		}
+	}
+	if u.location == nil {
+		if err := u.start(ctx); err != nil {
+			return err
+		}
+		u.saveState()
+	}
+
+	retries := 0
+	for offset < size {
+		end := min(offset+chunkSize, size)
+		received, err := u.patch(ctx, offset, end)
+		if err != nil {
+			if ctx.Err() != nil || retries >= maxChunkRetries {
+				return fmt.Errorf("upload of %s interrupted after %d of %d bytes: %w", u.desc.Digest, offset, size, err)
+			}
+			retries++
+			// Part of the chunk may have been received.
+			if o, serr := u.status(ctx); serr == nil {
+				offset = o
+			}
+			continue
+		}
+		retries = 0
+		offset = received
+		u.saveState()
+	}
+
+	if err := u.complete(ctx); err != nil {
+		return err
+	}
+	u.removeState()
+	return nil
+}
+
+// start opens an upload session.
+func (u *chunkedUpload) start(ctx context.Context) error {
+	uploadURL := fmt.Sprintf("%s://%s/v2/%s/blobs/uploads/", scheme(u.plainHTTP), u.ref.Host(), u.ref.Repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := u.do(req, http.StatusAccepted)
+	if err != nil {
+		return err
+	}
+	return u.setLocation(req, resp)
+}
+
+// status returns the offset of the next byte to upload in the session.
+func (u *chunkedUpload) status(ctx context.Context) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.location.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := u.do(req, http.StatusNoContent)
+	if err != nil {
+		return 0, err
+	}
+	if err := u.setLocation(req, resp); err != nil {
+		return 0, err
+	}
+	return receivedBytes(resp.Header.Get("Range")), nil
+}
+
+// patch uploads the bytes of the blob from offset to end, and returns the
+// offset of the next byte to upload.
+func (u *chunkedUpload) patch(ctx context.Context, offset, end int64) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, u.location.String(), bytes.NewReader(u.data[offset:end]))
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = end - offset
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Range", fmt.Sprintf("%d-%d", offset, end-1))
+	resp, err := u.do(req, http.StatusAccepted)
+	if err != nil {
+		return 0, err
+	}
+	if err := u.setLocation(req, resp); err != nil {
+		return 0, err
+	}
+	if r := resp.Header.Get("Range"); r != "" {
+		return receivedBytes(r), nil
+	}
+	return end, nil
+}
+
+// complete closes the upload session, which makes the blob available.
+func (u *chunkedUpload) complete(ctx context.Context) error {
+	completeURL := *u.location
+	q := completeURL.Query()
+	q.Set("digest", u.desc.Digest.String())
+	completeURL.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, completeURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	_, err = u.do(req, http.StatusCreated)
+	return err
+}
+
+func (u *chunkedUpload) do(req *http.Request, status int) (*http.Response, error) {
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		return nil, fmt.Errorf("%s %q: unexpected status code %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// setLocation sets the URL of the session from the Location header of the
+// response. As for monolithic uploads, the location must stay on the host of
+// the registry, so that the credentials are not sent elsewhere.
+func (u *chunkedUpload) setLocation(req *http.Request, resp *http.Response) error {
+	location, err := resp.Location()
+	if errors.Is(err, http.ErrNoLocation) && u.location != nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if location.Hostname() == req.URL.Hostname() && location.Port() == "" && req.URL.Port() == "443" {
+		location.Host = req.URL.Host
+	}
+	if location.Host != req.URL.Host {
+		return fmt.Errorf("blob upload Location %q is on a different host than the registry %q", location.Host, req.URL.Host)
+	}
+	if req.URL.Scheme == "https" && location.Scheme != "https" {
+		return fmt.Errorf("blob upload Location %q downgrades scheme from https", location.Host)
+	}
+	u.location = location
+	return nil
+}
+
+// receivedBytes returns the number of bytes received by the registry, from
+// the Range header of an upload session, e.g. "0-1023". Some registries
+// report an empty session as "0-0", so it is taken as empty: at worst, the
+// first byte is uploaded again and the registry rejects the chunk.
+func receivedBytes(r string) int64 {
+	_, last, ok := strings.Cut(strings.TrimPrefix(r, "bytes="), "-")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n + 1
+}
+
+func scheme(plainHTTP bool) string {
+	if plainHTTP {
+		return "http"
+	}
+	return "https"
+}
+
+func (u *chunkedUpload) stateFile() string {
+	key := digest.FromString(u.ref.Registry + "/" + u.ref.Repository + "@" + u.desc.Digest.String())
+	return filepath.Join(u.stateDir, key.Encoded()+".json")
+}
+
+// loadState returns the location of the recorded session of the upload, if
+// any.
+func (u *chunkedUpload) loadState() *url.URL {
+	if u.stateDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(u.stateFile())
+	if err != nil {
+		return nil
+	}
+	state := &uploadState{}
+	if err := json.Unmarshal(data, state); err != nil || state.Digest != u.desc.Digest {
+		return nil
+	}
+	location, err := url.Parse(state.Location)
+	if err != nil {
+		return nil
+	}
+	return location
+}
+
+// saveState records the session of the upload. The upload goes on when the
+// state cannot be recorded, it is then not resumable.
+func (u *chunkedUpload) saveState() {
+	if u.stateDir == "" {
+		return
+	}
+	data, err := json.Marshal(&uploadState{
+		Repository: u.ref.Registry + "/" + u.ref.Repository,
+		Digest:     u.desc.Digest,
+		Location:   u.location.String(),
+	})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(u.stateDir, 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(u.stateFile(), data, 0o600)
+}
+
+func (u *chunkedUpload) removeState() {
+	if u.stateDir == "" {
+		return
+	}
+	_ = os.Remove(u.stateFile())
+}
@@ -0,0 +1,206 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry"
+)
+
+// fakeUploads serves the chunked upload API of a registry, and fails the
+// PATCH requests for which fail returns true.
+type fakeUploads struct {
+	mu       sync.Mutex
+	sessions map[string][]byte
+	blobs    map[digest.Digest][]byte
+	patches  int
+	received int
+	fail     func(patch int) bool
+}
+
+func (f *fakeUploads) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const prefix = "/v2/repo/blobs/uploads/"
+	id := strings.TrimPrefix(r.URL.Path, prefix)
+	session, ok := f.sessions[id]
+	location := func() {
+		w.Header().Set("Location", prefix+id)
+		w.Header().Set("Range", fmt.Sprintf("0-%d", len(f.sessions[id])-1))
+	}
+
+	switch {
+	case r.Method == http.MethodPost && id == "":
+		id = strconv.Itoa(len(f.sessions) + 1)
+		f.sessions[id] = []byte{}
+		location()
+		w.WriteHeader(http.StatusAccepted)
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodGet:
+		location()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPatch:
+		f.patches++
+		if f.fail != nil && f.fail(f.patches) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Content-Range"), fmt.Sprintf("%d-", len(session))) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.received += len(data)
+		f.sessions[id] = append(session, data...)
+		location()
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut:
+		d := digest.Digest(r.URL.Query().Get("digest"))
+		if d != digest.FromBytes(session) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.blobs[d] = session
+		delete(f.sessions, id)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newFakeUploads(t *testing.T) (*fakeUploads, *httptest.Server) {
+	t.Helper()
+	f := &fakeUploads{sessions: map[string][]byte{}, blobs: map[digest.Digest][]byte{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func newChunkedUpload(srv *httptest.Server, data []byte, stateDir string) *chunkedUpload {
+	return &chunkedUpload{
+		client:    srv.Client(),
+		ref:       registry.Reference{Registry: strings.TrimPrefix(srv.URL, "http://"), Repository: "repo"},
+		plainHTTP: true,
+		desc:      ocispec.Descriptor{Digest: digest.FromBytes(data), Size: int64(len(data))},
+		data:      data,
+		chunkSize: 10,
+		stateDir:  stateDir,
+	}
+}
+
+func TestChunkedUploadRetry(t *testing.T) {
+	f, srv := newFakeUploads(t)
+	f.fail = func(patch int) bool { return patch == 2 }
+	data := []byte(strings.Repeat("0123456789", 3) + "01234")
+
+	require.NoError(t, newChunkedUpload(srv, data, "").run(t.Context()))
+	assert.Equal(t, data, f.blobs[digest.FromBytes(data)])
+	assert.Equal(t, 5, f.patches, "the failed chunk is uploaded again")
+	assert.Equal(t, len(data), f.received)
+}
+
+func TestChunkedUploadResume(t *testing.T) {
+	f, srv := newFakeUploads(t)
+	f.fail = func(patch int) bool { return patch > 1 }
+	data := []byte(strings.Repeat("0123456789", 3) + "01234")
+	stateDir := t.TempDir()
+
+	u := newChunkedUpload(srv, data, stateDir)
+	err := u.run(t.Context())
+	require.ErrorContains(t, err, "interrupted after 10 of 35 bytes")
+	_, err = os.Stat(u.stateFile())
+	require.NoError(t, err, "the state of the interrupted upload is recorded")
+
+	f.fail = nil
+	f.received = 0
+	u = newChunkedUpload(srv, data, stateDir)
+	require.NoError(t, u.run(t.Context()))
+	assert.Equal(t, data, f.blobs[digest.FromBytes(data)])
+	assert.Equal(t, len(data)-10, f.received, "the upload resumes after the received bytes")
+	assert.NoFileExists(t, u.stateFile())
+
+	// A session that no longer exists is started over.
+	delete(f.blobs, digest.FromBytes(data))
+	u = newChunkedUpload(srv, data, stateDir)
+	u.location = nil
+	require.NoError(t, os.WriteFile(u.stateFile(), fmt.Appendf(nil, `{"digest":%q,"location":%q}`, digest.FromBytes(data), srv.URL+"/v2/repo/blobs/uploads/gone"), 0o600))
+	require.NoError(t, u.run(t.Context()))
+	assert.Equal(t, data, f.blobs[digest.FromBytes(data)])
+}
+
+func TestReceivedBytes(t *testing.T) {
+	tests := map[string]int64{
+		"":            0,
+		"0-0":         0,
+		"0-1":         2,
+		"0-1023":      1024,
+		"bytes=0-9":   10,
+		"0--1":        0,
+		"not a range": 0,
+	}
+	for r, want := range tests {
+		assert.Equal(t, want, receivedBytes(r), "range %q", r)
+	}
+}
+
+func TestPushImmutableTagBeforeUpload(t *testing.T) {
+	existing := digest.FromString("existing manifest")
+	var mu sync.Mutex
+	var uploads int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.Contains(r.URL.Path, "/blobs/uploads/"):
+			uploads++
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.Method == http.MethodHead && strings.Contains(r.URL.Path, "/manifests/"):
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", existing.String())
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(ClientOptPlainHTTP(), ClientOptWriter(io.Discard), ClientOptCredentialsFile(t.TempDir()+"/config.json"))
+	require.NoError(t, err)
+	data, err := os.ReadFile("../downloader/testdata/local-subchart-0.1.0.tgz")
+	require.NoError(t, err)
+
+	ref := strings.TrimPrefix(srv.URL, "http://") + "/repo/local-subchart:0.1.0"
+	_, err = client.Push(data, ref, PushOptImmutableTag(true), PushOptChunkSize(10))
+	require.ErrorIs(t, err, ErrTagExists)
+	assert.Zero(t, uploads, "nothing is uploaded to a refused immutable tag")
+}
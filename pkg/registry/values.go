@@ -0,0 +1,87 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ValuesLayerMediaType is the media type for a layer holding a Helm values file
+const ValuesLayerMediaType = "application/vnd.cncf.helm.values.v1+yaml"
+
+// ValuesPullResult contains the result of a values pull operation
+type ValuesPullResult struct {
+	Manifest ocispec.Descriptor
+	Data     []byte
+	Ref      string
+}
+
+// PullValues downloads a values file stored as an OCI artifact. The values are
+// read from the first layer with the ValuesLayerMediaType, or failing that,
+// the first layer titled with a .yaml, .yml or .json file name.
+func (c *Client) PullValues(ref string) (*ValuesPullResult, error) {
+	genericClient := c.Generic()
+	genericResult, err := genericClient.PullGeneric(ref, GenericPullOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	manifestData, err := genericClient.GetDescriptorData(genericResult.MemoryStore, genericResult.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve manifest: %w", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest: %w", err)
+	}
+
+	layer, ok := valuesLayer(manifest.Layers)
+	if !ok {
+		return nil, fmt.Errorf("manifest does not contain a layer of type %s or a YAML or JSON file", ValuesLayerMediaType)
+	}
+
+	data, err := genericClient.GetDescriptorData(genericResult.MemoryStore, layer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve values with digest %s: %w", layer.Digest, err)
+	}
+
+	return &ValuesPullResult{
+		Manifest: genericResult.Manifest,
+		Data:     data,
+		Ref:      genericResult.Ref,
+	}, nil
+}
+
+// valuesLayer finds the layer holding the values in a manifest.
+func valuesLayer(layers []ocispec.Descriptor) (ocispec.Descriptor, bool) {
+	for _, l := range layers {
+		if l.MediaType == ValuesLayerMediaType {
+			return l, true
+		}
+	}
+	for _, l := range layers {
+		switch path.Ext(l.Annotations[ocispec.AnnotationTitle]) {
+		case ".yaml", ".yml", ".json":
+			return l, true
+		}
+	}
+	return ocispec.Descriptor{}, false
+}
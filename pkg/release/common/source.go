@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import "time"
+
+// ChartSource describes where the chart of a release came from.
+type ChartSource struct {
+	// Ref is the location the chart was installed from: the chart URL
+	// resolved from a repository index, an OCI reference with its resolved
+	// tag, or a local path.
+	Ref string `json:"ref"`
+	// Version is the resolved version of a chart downloaded from a
+	// repository or a registry.
+	Version string `json:"version,omitempty"`
+	// Digest is the digest of the chart archive, in the form "sha256:<hex>".
+	// It is empty when the chart was installed from an unpacked directory.
+	Digest string `json:"digest,omitempty"`
+	// ManifestDigest is the digest of the OCI manifest of the chart when it
+	// was installed from an OCI reference pinned to a digest.
+	ManifestDigest string `json:"manifest_digest,omitempty"`
+	// Verification records the verification of the chart provenance when the
+	// chart was installed with --verify.
+	Verification *ChartVerification `json:"verification,omitempty"`
+}
+
+// Verification methods of ChartVerification.
+const (
+	// VerificationPGPClearSign is the method of legacy clear-signed
+	// provenance files.
+	VerificationPGPClearSign = "pgp-clearsign"
+	// VerificationPGPDetached is the method of provenance files with detached
+	// signatures of the chart digest.
+	VerificationPGPDetached = "pgp-detached"
+)
+
+// ChartVerification describes how the provenance of a chart was verified.
+type ChartVerification struct {
+	// Method is how the provenance was verified, such as
+	// VerificationPGPClearSign.
+	Method string `json:"method"`
+	// Signers are the fingerprints of the trusted keys that signed the chart.
+	Signers []string `json:"signers"`
+	// Time is when the chart was verified.
+	Time time.Time `json:"time"`
+}
@@ -42,6 +42,54 @@ type Info struct {
 	Notes string `json:"notes,omitempty"`
 	// Contains the deployed resources information
 	Resources map[string][]runtime.Object `json:"resources,omitempty"`
+	// ResourceCount is the number of resources of the manifest, recorded
+	// when the release is deployed.
+	ResourceCount int `json:"resource_count,omitempty"`
+	// ImageCount is the number of distinct container images of the manifest,
+	// recorded when the release is deployed.
+	ImageCount int `json:"image_count,omitempty"`
+	// DeployDuration is how long the last deployment took, hooks and waiting
+	// for the resources included.
+	DeployDuration time.Duration `json:"deploy_duration,omitempty"`
+	// Timings records how long the phases of the last deployment took.
+	Timings Timings `json:"timings,omitzero"`
+	// IdempotencyKey is the key given by the client to the operation that
+	// created the revision, so that retrying the operation does not create
+	// another revision.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// Endpoints are the addresses the Services of type LoadBalancer and the
+	// Ingresses of the release were given, when they were waited for.
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+}
+
+// Endpoint is where a Service of type LoadBalancer or an Ingress of a release
+// can be reached from outside of the cluster.
+type Endpoint struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Addresses are the hostnames and IP addresses of the load balancer.
+	Addresses []string `json:"addresses,omitempty"`
+	// Ports are the ports of a Service.
+	Ports []int32 `json:"ports,omitempty"`
+	// Hosts are the hosts of the rules of an Ingress.
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// Timings records how long the phases of a deployment took. Each phase is
+// zero when it did not run, such as the hooks when they are disabled.
+type Timings struct {
+	// Render is the time spent rendering the templates of the chart.
+	Render time.Duration `json:"render,omitempty"`
+	// Validate is the time spent validating the rendered manifest against the
+	// cluster.
+	Validate time.Duration `json:"validate,omitempty"`
+	// Apply is the time spent creating and updating the resources.
+	Apply time.Duration `json:"apply,omitempty"`
+	// Hooks is the time spent running the hooks, waiting for them included.
+	Hooks time.Duration `json:"hooks,omitempty"`
+	// Wait is the time spent waiting for the resources to be ready.
+	Wait time.Duration `json:"wait,omitempty"`
 }
 
 // infoJSON is used for custom JSON marshaling/unmarshaling
@@ -54,6 +102,12 @@ type infoJSON struct {
 	RollbackRevision int                         `json:"rollback_revision,omitempty"`
 	Notes            string                      `json:"notes,omitempty"`
 	Resources        map[string][]runtime.Object `json:"resources,omitempty"`
+	ResourceCount    int                         `json:"resource_count,omitempty"`
+	ImageCount       int                         `json:"image_count,omitempty"`
+	DeployDuration   time.Duration               `json:"deploy_duration,omitempty"`
+	Timings          Timings                     `json:"timings,omitzero"`
+	IdempotencyKey   string                      `json:"idempotency_key,omitempty"`
+	Endpoints        []Endpoint                  `json:"endpoints,omitempty"`
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
@@ -101,6 +155,12 @@ func (i *Info) UnmarshalJSON(data []byte) error {
 	i.RollbackRevision = tmp.RollbackRevision
 	i.Notes = tmp.Notes
 	i.Resources = tmp.Resources
+	i.ResourceCount = tmp.ResourceCount
+	i.ImageCount = tmp.ImageCount
+	i.DeployDuration = tmp.DeployDuration
+	i.Timings = tmp.Timings
+	i.IdempotencyKey = tmp.IdempotencyKey
+	i.Endpoints = tmp.Endpoints
 
 	return nil
 }
@@ -114,6 +174,12 @@ func (i Info) MarshalJSON() ([]byte, error) {
 		RollbackRevision: i.RollbackRevision,
 		Notes:            i.Notes,
 		Resources:        i.Resources,
+		ResourceCount:    i.ResourceCount,
+		ImageCount:       i.ImageCount,
+		DeployDuration:   i.DeployDuration,
+		Timings:          i.Timings,
+		IdempotencyKey:   i.IdempotencyKey,
+		Endpoints:        i.Endpoints,
 	}
 
 	if !i.FirstDeployed.IsZero() {
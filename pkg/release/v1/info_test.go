@@ -295,6 +295,34 @@ func TestInfoRoundTrip(t *testing.T) {
 	assert.Equal(t, original.Notes, decoded.Notes)
 }
 
+func TestInfoTimingsRoundTrip(t *testing.T) {
+	original := Info{
+		Status:         common.StatusDeployed,
+		ResourceCount:  3,
+		ImageCount:     1,
+		DeployDuration: 12 * time.Second,
+		Timings: Timings{
+			Render:   200 * time.Millisecond,
+			Validate: 300 * time.Millisecond,
+			Apply:    time.Second,
+			Hooks:    500 * time.Millisecond,
+			Wait:     10 * time.Second,
+		},
+	}
+
+	data, err := json.Marshal(&original)
+	require.NoError(t, err)
+
+	var decoded Info
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, original, decoded)
+
+	// Releases deployed without timings do not show them.
+	data, err = json.Marshal(&Info{Status: common.StatusDeployed})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "timings")
+}
+
 func TestInfoRollbackRevisionRoundTrip(t *testing.T) {
 	now := time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)
 	later := time.Date(2025, 10, 8, 13, 0, 0, 0, time.UTC)
@@ -381,3 +409,15 @@ func TestInfoEmptyStringRoundTrip(t *testing.T) {
 	assert.Equal(t, "deployed", result["status"])
 	assert.Equal(t, "test", result["description"])
 }
+
+func TestInfoIdempotencyKeyRoundTrip(t *testing.T) {
+	original := Info{Status: common.StatusDeployed, IdempotencyKey: "ci-run-1234"}
+
+	data, err := json.Marshal(&original)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"idempotency_key":"ci-run-1234"`)
+
+	var decoded Info
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, original, decoded)
+}
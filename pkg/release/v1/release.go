@@ -16,6 +16,9 @@ limitations under the License.
 package v1
 
 import (
+	"slices"
+	"time"
+
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/release/common"
 )
@@ -48,9 +51,103 @@ type Release struct {
 	// Labels of the release.
 	// Disabled encoding into Json cause labels are stored in storage driver metadata field.
 	Labels map[string]string `json:"-"`
+	// Annotations that Helm sets on the storage object of the release, such as
+	// its Secret. They are recorded in the release too, so that the annotations
+	// other tools add to the storage object are not carried forward.
+	Annotations map[string]string `json:"annotations,omitempty"`
 	// ApplyMethod stores whether server-side or client-side apply was used for the release
 	// Unset (empty string) should be treated as the default of client-side apply
 	ApplyMethod string `json:"apply_method,omitempty"` // "ssa" | "csa"
+	// ChartSource records where the chart was installed from. It is nil for
+	// releases created by older versions of Helm or from in-memory charts.
+	ChartSource *common.ChartSource `json:"chart_source,omitempty"`
+	// SensitiveValues holds the values of Config that the chart schema marks
+	// as sensitive, encrypted. It is only set in the stored release records:
+	// the storage moves the values back to Config when reading a release.
+	SensitiveValues *SensitiveValues `json:"sensitive_values,omitempty"`
+	// Retention limits the revisions kept in the history of the release. It
+	// is carried over to the later revisions, so that all the operations on
+	// the release apply it.
+	Retention *Retention `json:"retention,omitempty"`
+	// ManifestDelta, when set, stores Manifest as the changes from the
+	// manifest of a later revision. It is only set in the stored release
+	// records: the storage rebuilds Manifest when reading a release.
+	ManifestDelta *ManifestDelta `json:"manifest_delta,omitempty"`
+	// Resources lists the resources of the manifest with the namespaces they
+	// were deployed to, which may differ from the release namespace. It is
+	// empty for releases created by older versions of Helm.
+	Resources []ResourceRef `json:"resources,omitempty"`
+	// Needs lists the releases that the release depends on, as
+	// namespace/name.
+	Needs []string `json:"needs,omitempty"`
+	// Slots holds the manifests of the objects of each slot of a blue/green
+	// release, by slot. Manifest includes them, together with the objects
+	// shared by the slots.
+	Slots map[string]string `json:"slots,omitempty"`
+	// ActiveSlot is the slot the Services of a blue/green release select.
+	ActiveSlot string `json:"active_slot,omitempty"`
+}
+
+// ResourceRef identifies a resource of a release.
+type ResourceRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	// Namespace is empty for cluster-scoped resources.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Namespaces returns the sorted namespaces of the resources of the release.
+func (r *Release) Namespaces() []string {
+	var namespaces []string
+	for _, ref := range r.Resources {
+		if ref.Namespace != "" && !slices.Contains(namespaces, ref.Namespace) {
+			namespaces = append(namespaces, ref.Namespace)
+		}
+	}
+	slices.Sort(namespaces)
+	return namespaces
+}
+
+// ManifestDelta stores a manifest as the changes from the manifest of another
+// revision of the release, its base.
+type ManifestDelta struct {
+	// Base is the revision the manifest is rebuilt from.
+	Base int `json:"base"`
+	// Ops rebuild the manifest, line by line, from the manifest of Base.
+	Ops []ManifestDeltaOp `json:"ops"`
+}
+
+// ManifestDeltaOp either copies Count lines of the base manifest from line
+// Start, or inserts the lines of Insert.
+type ManifestDeltaOp struct {
+	Start  int    `json:"start,omitempty"`
+	Count  int    `json:"count,omitempty"`
+	Insert string `json:"insert,omitempty"`
+}
+
+// Retention limits the revisions kept in the history of a release. The
+// newest and the deployed revisions are always kept.
+type Retention struct {
+	// MaxHistory is the maximum number of revisions kept. Zero or less means
+	// no limit.
+	MaxHistory int `json:"max_history,omitempty"`
+	// MaxAge is the age after which revisions are removed, based on when they
+	// were last deployed. Zero means no limit.
+	MaxAge time.Duration `json:"max_age,omitempty"`
+}
+
+// SensitiveValues holds sensitive values encrypted with envelope encryption:
+// the values are encrypted with a random data key, which is in turn encrypted
+// by a key provider.
+type SensitiveValues struct {
+	// Provider is the name of the key provider that encrypted the data key.
+	Provider string `json:"provider"`
+	// Key is the encrypted data key.
+	Key []byte `json:"key"`
+	// Data is the JSON encoding of the sensitive values, encrypted with the
+	// data key.
+	Data []byte `json:"data"`
 }
 
 // SetStatus is a helper for setting the status on a release.
@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"sort"
+
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// Changes describes what changed between two revisions of a release.
+type Changes struct {
+	// FromChartVersion is the chart version of the earlier revision. It is
+	// empty when there is no earlier revision.
+	FromChartVersion string `json:"fromChartVersion,omitempty"`
+	// ToChartVersion is the chart version of the later revision.
+	ToChartVersion string `json:"toChartVersion"`
+	// AddedValues lists the user supplied values that were added, as
+	// dot-separated key paths.
+	AddedValues []string `json:"addedValues,omitempty"`
+	// RemovedValues lists the user supplied values that were removed.
+	RemovedValues []string `json:"removedValues,omitempty"`
+	// ChangedValues lists the user supplied values that were changed.
+	ChangedValues []string `json:"changedValues,omitempty"`
+}
+
+// ChartChanged reports whether the chart version changed.
+func (c Changes) ChartChanged() bool {
+	return c.FromChartVersion != c.ToChartVersion
+}
+
+// ValuesChanged reports whether any user supplied value changed.
+func (c Changes) ValuesChanged() bool {
+	return len(c.AddedValues)+len(c.RemovedValues)+len(c.ChangedValues) > 0
+}
+
+// CompareReleases returns the changes from one revision of a release to
+// another. from may be nil, in which case every value of to is reported as
+// added.
+func CompareReleases(from, to *rspb.Release) Changes {
+	c := Changes{ToChartVersion: chartVersion(to)}
+	var fromValues map[string]any
+	if from != nil {
+		c.FromChartVersion = chartVersion(from)
+		fromValues = from.Config
+	}
+
+	old := flattenValues("", fromValues, map[string]any{})
+	cur := flattenValues("", to.Config, map[string]any{})
+	for k, v := range cur {
+		ov, ok := old[k]
+		switch {
+		case !ok:
+			c.AddedValues = append(c.AddedValues, k)
+		case !reflect.DeepEqual(ov, v):
+			c.ChangedValues = append(c.ChangedValues, k)
+		}
+	}
+	for k := range old {
+		if _, ok := cur[k]; !ok {
+			c.RemovedValues = append(c.RemovedValues, k)
+		}
+	}
+	sort.Strings(c.AddedValues)
+	sort.Strings(c.RemovedValues)
+	sort.Strings(c.ChangedValues)
+	return c
+}
+
+func chartVersion(r *rspb.Release) string {
+	if r.Chart == nil || r.Chart.Metadata == nil {
+		return ""
+	}
+	return r.Chart.Metadata.Version
+}
+
+// flattenValues maps the dot-separated path of every leaf value to the value.
+// Lists are treated as leaves.
+func flattenValues(prefix string, values map[string]any, out map[string]any) map[string]any {
+	for k, v := range values {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			flattenValues(path, m, out)
+			continue
+		}
+		out[path] = v
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestCompareReleases(t *testing.T) {
+	rel := func(version string, config map[string]any) *rspb.Release {
+		return &rspb.Release{
+			Chart:  &chart.Chart{Metadata: &chart.Metadata{Name: "foo", Version: version}},
+			Config: config,
+		}
+	}
+
+	from := rel("0.1.0", map[string]any{
+		"image":    map[string]any{"tag": "1.0", "pullPolicy": "Always"},
+		"replicas": 1,
+		"hosts":    []any{"a"},
+	})
+	to := rel("0.2.0", map[string]any{
+		"image":     map[string]any{"tag": "1.1", "pullPolicy": "Always"},
+		"hosts":     []any{"a", "b"},
+		"resources": map[string]any{"limits": map[string]any{"cpu": "1"}},
+	})
+
+	c := CompareReleases(from, to)
+	expect := Changes{
+		FromChartVersion: "0.1.0",
+		ToChartVersion:   "0.2.0",
+		AddedValues:      []string{"resources.limits.cpu"},
+		RemovedValues:    []string{"replicas"},
+		ChangedValues:    []string{"hosts", "image.tag"},
+	}
+	if !reflect.DeepEqual(expect, c) {
+		t.Errorf("expected %+v, got %+v", expect, c)
+	}
+	if !c.ChartChanged() || !c.ValuesChanged() {
+		t.Error("expected chart and values to be changed")
+	}
+
+	c = CompareReleases(nil, to)
+	if c.FromChartVersion != "" || len(c.AddedValues) != 4 {
+		t.Errorf("expected every value to be added, got %+v", c)
+	}
+
+	c = CompareReleases(to, to)
+	if c.ChartChanged() || c.ValuesChanged() {
+		t.Errorf("expected no changes, got %+v", c)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func TestDiffManifests(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expect   string
+	}{
+		{
+			name:   "equal",
+			from:   "a: 1\n",
+			to:     "a: 1\n",
+			expect: "",
+		},
+		{
+			name:   "changed",
+			from:   "a: 1\nb: 1\n",
+			to:     "a: 1\nb: 2",
+			expect: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a: 1\n-b: 1\n+b: 2\n",
+		},
+		{
+			name:   "added",
+			from:   "",
+			to:     "a: 1\n",
+			expect: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a: 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := DiffManifests(tt.from, tt.to, "old", "new")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff != tt.expect {
+				t.Errorf("expected diff %q, got %q", tt.expect, diff)
+			}
+		})
+	}
+}
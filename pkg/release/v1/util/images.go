@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"slices"
+
+	"sigs.k8s.io/yaml"
+)
+
+// containerFields are the fields of a pod spec listing containers.
+var containerFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// ManifestImages returns the sorted, distinct container images of the
+// workloads of a stream of manifests. The pod specs are looked up anywhere in
+// the manifests, so the images of custom resources embedding a pod template
+// are found too.
+func ManifestImages(manifest string) []string {
+	seen := map[string]bool{}
+	for _, doc := range SplitManifests(manifest) {
+		var obj map[string]any
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			continue
+		}
+		collectImages(obj, seen)
+	}
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	slices.Sort(images)
+	return images
+}
+
+func collectImages(v any, seen map[string]bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if slices.Contains(containerFields, key) {
+				if containers, ok := val.([]any); ok {
+					for _, c := range containers {
+						if c, ok := c.(map[string]any); ok {
+							if image, ok := c["image"].(string); ok && image != "" {
+								seen[image] = true
+							}
+						}
+					}
+				}
+			}
+			collectImages(val, seen)
+		}
+	case []any:
+		for _, val := range v {
+			collectImages(val, seen)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ManifestFilter selects objects from the manifest of a release. Empty
+// fields match any object.
+type ManifestFilter struct {
+	// Kind matches the kind of the objects, ignoring case.
+	Kind string
+	// Name matches the name of the objects.
+	Name string
+	// Selector matches the labels of the objects.
+	Selector labels.Selector
+}
+
+// Filter returns the manifests of a stream that match the filter, in their
+// original order, each preceded by a "---" separator. Manifests that cannot
+// be parsed do not match.
+func (f ManifestFilter) Filter(manifest string) string {
+	docs := SplitManifests(manifest)
+	keys := make([]string, 0, len(docs))
+	for k := range docs {
+		keys = append(keys, k)
+	}
+	sort.Sort(BySplitManifestsOrder(keys))
+
+	var b strings.Builder
+	for _, k := range keys {
+		obj, err := parseManifestObject(docs[k])
+		if err == nil && f.Matches(obj) {
+			b.WriteString("---\n")
+			b.WriteString(obj.Manifest)
+		}
+	}
+	return b.String()
+}
+
+// Matches reports whether an object matches the filter.
+func (f ManifestFilter) Matches(obj ManifestObject) bool {
+	if obj.Kind == "" {
+		return false
+	}
+	if f.Kind != "" && !strings.EqualFold(f.Kind, obj.Kind) {
+		return false
+	}
+	if f.Name != "" && f.Name != obj.Name {
+		return false
+	}
+	return f.Selector == nil || f.Selector.Matches(labels.Set(obj.Labels))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const filterManifest = `---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    app: web
+---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+---
+# Source: app/templates/worker.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  labels:
+    app: worker
+`
+
+func TestManifestFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   ManifestFilter
+		expected []string
+	}{
+		{
+			name:     "empty filter matches everything",
+			expected: []string{"service.yaml", "deployment.yaml", "worker.yaml"},
+		},
+		{
+			name:     "kind ignores case",
+			filter:   ManifestFilter{Kind: "deployment"},
+			expected: []string{"deployment.yaml", "worker.yaml"},
+		},
+		{
+			name:     "kind and name",
+			filter:   ManifestFilter{Kind: "Deployment", Name: "web"},
+			expected: []string{"deployment.yaml"},
+		},
+		{
+			name:     "selector",
+			filter:   ManifestFilter{Selector: labels.SelectorFromSet(labels.Set{"app": "web"})},
+			expected: []string{"service.yaml", "deployment.yaml"},
+		},
+		{
+			name:   "no match",
+			filter: ManifestFilter{Kind: "ConfigMap"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.filter.Filter(filterManifest)
+			docs := SplitManifests(out)
+			assert.Len(t, docs, len(tt.expected))
+			for i, file := range tt.expected {
+				assert.Contains(t, docs[fmt.Sprintf("manifest-%d", i)], "app/templates/"+file)
+			}
+		})
+	}
+}
@@ -37,6 +37,24 @@ type Manifest struct {
 	Head    *SimpleHead
 }
 
+// ManifestParseError is returned by SortManifests when a rendered manifest is
+// not valid YAML.
+type ManifestParseError struct {
+	// Path is the name of the rendered file.
+	Path string
+	// Manifest is the invalid manifest, as split from the rendered file.
+	Manifest string
+	Err      error
+}
+
+func (e *ManifestParseError) Error() string {
+	return fmt.Sprintf("YAML parse error on %s: %s", e.Path, e.Err)
+}
+
+func (e *ManifestParseError) Unwrap() error {
+	return e.Err
+}
+
 // manifestFile represents a file that contains a manifest.
 type manifestFile struct {
 	entries map[string]string
@@ -149,7 +167,7 @@ func (file *manifestFile) sort(result *result) error {
 
 		var entry SimpleHead
 		if err := yaml.Unmarshal([]byte(m), &entry); err != nil {
-			return fmt.Errorf("YAML parse error on %s: %w", file.path, err)
+			return &ManifestParseError{Path: file.path, Manifest: m, Err: err}
 		}
 
 		if !hasAnyAnnotation(entry) {
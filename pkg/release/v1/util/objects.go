@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// ObjectRef identifies an object of a release manifest.
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace is empty when the manifest does not set it.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Source is the chart template the object was rendered from, as recorded
+	// by the "# Source:" comment of the manifest.
+	Source string `json:"source,omitempty"`
+}
+
+// GroupVersionKind returns the group, version and kind of the object.
+func (r ObjectRef) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(r.APIVersion, r.Kind)
+}
+
+// String returns the object as apiVersion/kind/namespace/name.
+func (r ObjectRef) String() string {
+	return fmt.Sprintf("%s/%s/%s/%s", r.APIVersion, r.Kind, r.Namespace, r.Name)
+}
+
+// ManifestObject is an object of a release manifest.
+type ManifestObject struct {
+	ObjectRef
+	// Labels are the labels of the object.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Manifest is the YAML document of the object, including the "# Source:"
+	// comment.
+	Manifest string `json:"manifest"`
+}
+
+// objectHead is the part of a manifest ParseManifestObjects reads.
+type objectHead struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name      string            `json:"name"`
+		Namespace string            `json:"namespace"`
+		Labels    map[string]string `json:"labels"`
+	} `json:"metadata"`
+}
+
+// ParseManifestObjects splits a manifest into its objects, in the order they
+// appear in the manifest. Documents that only hold comments, or that have no
+// kind, are skipped.
+func ParseManifestObjects(manifest string) ([]ManifestObject, error) {
+	docs := SplitManifests(manifest)
+	keys := make([]string, 0, len(docs))
+	for k := range docs {
+		keys = append(keys, k)
+	}
+	sort.Sort(BySplitManifestsOrder(keys))
+
+	objects := make([]ManifestObject, 0, len(keys))
+	for i, k := range keys {
+		obj, err := parseManifestObject(docs[k])
+		if err != nil {
+			if obj.Source != "" {
+				return nil, fmt.Errorf("document %d (%s): %w", i+1, obj.Source, err)
+			}
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		if obj.Kind == "" {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// parseManifestObject parses a single document of a manifest. The source is
+// set even when the document cannot be parsed.
+func parseManifestObject(doc string) (ManifestObject, error) {
+	obj := ManifestObject{
+		ObjectRef: ObjectRef{Source: manifestSource(doc)},
+		Manifest:  strings.TrimSpace(doc) + "\n",
+	}
+	var head objectHead
+	if err := yaml.Unmarshal([]byte(doc), &head); err != nil {
+		return obj, err
+	}
+	obj.APIVersion = head.APIVersion
+	obj.Kind = head.Kind
+	obj.Namespace = head.Metadata.Namespace
+	obj.Name = head.Metadata.Name
+	obj.Labels = head.Metadata.Labels
+	return obj, nil
+}
+
+// manifestSource returns the template named by the "# Source:" comment at
+// the top of a document.
+func manifestSource(doc string) string {
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if src, ok := strings.CutPrefix(line, "# Source:"); ok {
+			return strings.TrimSpace(src)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseManifestObjects(t *testing.T) {
+	manifest := `---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  labels:
+    app: web
+---
+# Source: app/templates/empty.yaml
+# nothing rendered here
+---
+# Source: app/templates/clusterrole.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`
+	objects, err := ParseManifestObjects(manifest)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	assert.Equal(t, ObjectRef{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "prod",
+		Name:       "web",
+		Source:     "app/templates/deployment.yaml",
+	}, objects[0].ObjectRef)
+	assert.Equal(t, map[string]string{"app": "web"}, objects[0].Labels)
+	assert.Equal(t, "apps/v1/Deployment/prod/web", objects[0].String())
+	assert.Equal(t, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, objects[0].GroupVersionKind())
+
+	assert.Equal(t, "rbac.authorization.k8s.io/v1/ClusterRole//reader", objects[1].String())
+	assert.Equal(t, "app/templates/clusterrole.yaml", objects[1].Source)
+	assert.Equal(t, `# Source: app/templates/clusterrole.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`, objects[1].Manifest)
+}
+
+func TestParseManifestObjectsInvalid(t *testing.T) {
+	manifest := `---
+# Source: app/templates/ok.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ok
+---
+# Source: app/templates/broken.yaml
+kind: [ConfigMap
+`
+	_, err := ParseManifestObjects(manifest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "document 2 (app/templates/broken.yaml)")
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"bytes"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// RedactedValue replaces the values of Secret data in redacted manifests.
+const RedactedValue = "REDACTED"
+
+// RedactSecrets masks the values of the data and stringData fields of the
+// Secrets in a stream of manifests, so the manifests can be shown without
+// leaking credentials. The keys of the Secrets, and the manifests of other
+// kinds, are kept as is.
+func RedactSecrets(manifest string) string {
+	if !strings.Contains(manifest, "Secret") {
+		return manifest
+	}
+	redacted := manifest
+	for _, doc := range SplitManifests(manifest) {
+		if r, ok := redactSecret(doc); ok {
+			redacted = strings.Replace(redacted, doc, r, 1)
+		}
+	}
+	return redacted
+}
+
+// redactSecret redacts a single manifest. It reports false when the manifest
+// is not a Secret, or has no data to redact.
+func redactSecret(doc string) (string, bool) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &node); err != nil || len(node.Content) == 0 {
+		return doc, false
+	}
+	root := node.Content[0]
+	if root.Kind != yaml.MappingNode || mappingValue(root, "kind") == nil || mappingValue(root, "kind").Value != "Secret" {
+		return doc, false
+	}
+
+	var changed bool
+	for _, field := range []string{"data", "stringData"} {
+		data := mappingValue(root, field)
+		if data == nil || data.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 1; i < len(data.Content); i += 2 {
+			data.Content[i] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: RedactedValue}
+			changed = true
+		}
+	}
+	if !changed {
+		return doc, false
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return doc, false
+	}
+	out := buf.String()
+	if !strings.HasSuffix(doc, "\n") {
+		out = strings.TrimSuffix(out, "\n")
+	}
+	return out, true
+}
+
+// mappingValue returns the value of key in a YAML mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "no secrets",
+			input: `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+data:
+  password: not-a-secret
+`,
+			expected: `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+data:
+  password: not-a-secret
+`,
+		},
+		{
+			name: "secret data and stringData",
+			input: `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+data:
+  key: value
+---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+type: Opaque
+data:
+  password: aHVudGVyMg==
+stringData:
+  token: "s3cr3t"
+`,
+			expected: `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+data:
+  key: value
+---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+type: Opaque
+data:
+  password: REDACTED
+stringData:
+  token: REDACTED
+`,
+		},
+		{
+			name: "secret without data",
+			input: `apiVersion: v1
+kind: Secret
+metadata:
+  name:   empty
+`,
+			expected: `apiVersion: v1
+kind: Secret
+metadata:
+  name:   empty
+`,
+		},
+		{
+			name: "invalid yaml",
+			input: `kind: Secret
+data: [
+`,
+			expected: `kind: Secret
+data: [
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RedactSecrets(tt.input))
+		})
+	}
+}
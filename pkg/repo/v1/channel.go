@@ -0,0 +1,113 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ChannelAnnotation is the annotation tagging a chart version with the
+// release channels it belongs to, as a comma separated list. Being a chart
+// annotation, it is set in Chart.yaml and ends up both in the entries of
+// the repository indexes and in the annotations of the OCI manifests.
+const ChannelAnnotation = "helm.sh/channels"
+
+// The well known release channels, from the least to the most risky. A
+// version of a channel is also a version of the riskier channels, so the
+// latest beta version is the latest version tagged beta or stable.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+	ChannelEdge   = "edge"
+)
+
+var channelRisks = []string{ChannelStable, ChannelBeta, ChannelEdge}
+
+// Channels returns the release channels of a chart version with the given
+// annotations. Untagged versions are stable, or edge when they are
+// prereleases.
+func Channels(version string, annotations map[string]string) []string {
+	var channels []string
+	for c := range strings.SplitSeq(annotations[ChannelAnnotation], ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			channels = append(channels, c)
+		}
+	}
+	if len(channels) > 0 {
+		return channels
+	}
+	if v, err := semver.NewVersion(version); err == nil && v.Prerelease() == "" {
+		return []string{ChannelStable}
+	}
+	return []string{ChannelEdge}
+}
+
+// InChannel reports whether a chart version with the given annotations is
+// a version of channel, either because it is tagged with channel or with a
+// less risky well known channel.
+func InChannel(version string, annotations map[string]string, channel string) bool {
+	risk := slices.Index(channelRisks, channel)
+	for _, c := range Channels(version, annotations) {
+		if c == channel {
+			return true
+		}
+		if r := slices.Index(channelRisks, c); r >= 0 && r < risk {
+			return true
+		}
+	}
+	return false
+}
+
+// InChannel reports whether the chart version is a version of channel.
+func (c *ChartVersion) InChannel(channel string) bool {
+	var annotations map[string]string
+	if c.Metadata != nil {
+		annotations = c.Annotations
+	}
+	return InChannel(c.Version, annotations, channel)
+}
+
+// GetInChannel returns the latest version of the chart name in channel that
+// satisfies the version constraint. All versions satisfy an empty
+// constraint, prereleases included, since the channel decides on them.
+func (i IndexFile) GetInChannel(name, version, channel string) (*ChartVersion, error) {
+	vs, ok := i.Entries[name]
+	if !ok {
+		return nil, ErrNoChartName
+	}
+	if version == "" {
+		version = ">0.0.0-0"
+	}
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return nil, err
+	}
+	for _, ver := range vs {
+		test, err := semver.NewVersion(ver.Version)
+		if err != nil {
+			continue
+		}
+		if constraint.Check(test) && ver.InChannel(channel) {
+			return ver, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s-%s in channel %q", ErrNoChartVersion, name, version, channel)
+}
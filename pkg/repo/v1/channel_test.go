@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"errors"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestInChannel(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		channels string
+		want     map[string]bool
+	}{
+		{
+			name:    "untagged release",
+			version: "1.0.0",
+			want:    map[string]bool{ChannelStable: true, ChannelBeta: true, ChannelEdge: true, "nightly": false},
+		},
+		{
+			name:    "untagged prerelease",
+			version: "1.1.0-rc.1",
+			want:    map[string]bool{ChannelStable: false, ChannelBeta: false, ChannelEdge: true},
+		},
+		{
+			name:     "tagged beta",
+			version:  "1.1.0-beta.1",
+			channels: "beta",
+			want:     map[string]bool{ChannelStable: false, ChannelBeta: true, ChannelEdge: true},
+		},
+		{
+			name:     "tagged custom channels",
+			version:  "1.2.0",
+			channels: " nightly , lts",
+			want:     map[string]bool{ChannelStable: false, ChannelEdge: false, "nightly": true, "lts": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.channels != "" {
+				annotations[ChannelAnnotation] = tt.channels
+			}
+			for channel, want := range tt.want {
+				if got := InChannel(tt.version, annotations, channel); got != want {
+					t.Errorf("InChannel(%q, %q) = %t, want %t", tt.version, channel, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetInChannel(t *testing.T) {
+	i := NewIndexFile()
+	for _, v := range []struct{ version, channels string }{
+		{"1.0.0", ""},
+		{"1.1.0", "lts"},
+		{"2.0.0-beta.1", "beta"},
+		{"2.0.0-rc.1", ""},
+	} {
+		md := &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "app", Version: v.version}
+		if v.channels != "" {
+			md.Annotations = map[string]string{ChannelAnnotation: v.channels}
+		}
+		if err := i.MustAdd(md, "app-"+v.version+".tgz", "https://example.com/charts", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	i.SortEntries()
+
+	tests := []struct {
+		version, channel, want string
+	}{
+		{"", ChannelStable, "1.0.0"},
+		{"", ChannelBeta, "2.0.0-beta.1"},
+		{"", ChannelEdge, "2.0.0-rc.1"},
+		{"", "lts", "1.1.0"},
+		{"<2.0.0-0", ChannelEdge, "1.0.0"},
+		{"", "nightly", ""},
+	}
+	for _, tt := range tests {
+		cv, err := i.GetInChannel("app", tt.version, tt.channel)
+		if tt.want == "" {
+			if !errors.Is(err, ErrNoChartVersion) {
+				t.Errorf("%s: expected ErrNoChartVersion, got %v", tt.channel, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.channel, err)
+			continue
+		}
+		if cv.Version != tt.want {
+			t.Errorf("%s %s: expected %s, got %s", tt.channel, tt.version, tt.want, cv.Version)
+		}
+	}
+
+	if _, err := i.GetInChannel("missing", "", ChannelStable); !errors.Is(err, ErrNoChartName) {
+		t.Errorf("expected ErrNoChartName, got %v", err)
+	}
+}
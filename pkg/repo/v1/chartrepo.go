@@ -29,6 +29,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"sigs.k8s.io/yaml"
+
 	"helm.sh/helm/v4/internal/fileutil"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/helmpath"
@@ -45,6 +47,12 @@ type Entry struct {
 	CAFile                string `json:"caFile"`
 	InsecureSkipTLSVerify bool   `json:"insecure_skip_tls_verify"`
 	PassCredentialsAll    bool   `json:"pass_credentials_all"`
+	// CredentialHelper names the docker-credential-<name> helper used to
+	// keep the password out of the repositories file.
+	CredentialHelper string `json:"credentialHelper,omitempty"`
+	// Proxy is the URL of the HTTP proxy used for this repository. When
+	// empty the proxy is configured through the environment.
+	Proxy string `json:"proxy,omitempty"`
 }
 
 // ChartRepository represents a chart repository
@@ -82,13 +90,16 @@ func (r *ChartRepository) DownloadIndexFile() (string, error) {
 		return "", err
 	}
 
-	resp, err := r.Client.Get(indexURL,
+	username, password := r.Config.BasicAuth()
+	options := []getter.Option{
 		getter.WithURL(r.Config.URL),
 		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSVerify),
 		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
-		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
+		getter.WithBasicAuth(username, password),
 		getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
-	)
+		getter.WithProxy(r.Config.Proxy),
+	}
+	resp, err := r.Client.Get(indexURL, options...)
 	if err != nil {
 		return "", err
 	}
@@ -103,6 +114,28 @@ func (r *ChartRepository) DownloadIndexFile() (string, error) {
 		return "", err
 	}
 
+	// Cache sharded indexes with all their entries.
+	if len(indexFile.Shards) > 0 {
+		err := indexFile.loadShards(indexURL, func(shard string) ([]byte, error) {
+			shardURL, err := ResolveReferenceURL(r.Config.URL, shard)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := r.Client.Get(shardURL, options...)
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(resp)
+		})
+		if err != nil {
+			return "", err
+		}
+		indexFile.Shards = nil
+		if index, err = yaml.Marshal(indexFile); err != nil {
+			return "", err
+		}
+	}
+
 	// Create the chart list file in the cache directory
 	var charts strings.Builder
 	for name := range indexFile.Entries {
@@ -128,6 +161,7 @@ type findChartInRepoURLOptions struct {
 	KeyFile               string
 	CAFile                string
 	ChartVersion          string
+	ChartChannel          string
 }
 
 type FindChartInRepoURLOption func(*findChartInRepoURLOptions)
@@ -139,6 +173,14 @@ func WithChartVersion(chartVersion string) FindChartInRepoURLOption {
 	}
 }
 
+// WithChartChannel specifies the release channel the chart version is
+// resolved in. See Channels.
+func WithChartChannel(channel string) FindChartInRepoURLOption {
+	return func(options *findChartInRepoURLOptions) {
+		options.ChartChannel = channel
+	}
+}
+
 // WithUsernamePassword specifies the username/password credntials for the repository
 func WithUsernamePassword(username, password string) FindChartInRepoURLOption {
 	return func(options *findChartInRepoURLOptions) {
@@ -217,7 +259,13 @@ func FindChartInRepoURL(repoURL string, chartName string, getters getter.Provide
 	if opts.ChartVersion != "" {
 		errMsg = fmt.Sprintf("%s version %q", errMsg, opts.ChartVersion)
 	}
-	cv, err := repoIndex.Get(chartName, opts.ChartVersion)
+	var cv *ChartVersion
+	if opts.ChartChannel != "" {
+		errMsg = fmt.Sprintf("%s in channel %q", errMsg, opts.ChartChannel)
+		cv, err = repoIndex.GetInChannel(chartName, opts.ChartVersion, opts.ChartChannel)
+	} else {
+		cv, err = repoIndex.Get(chartName, opts.ChartVersion)
+	}
 	if err != nil {
 		return "", ChartNotFoundError{
 			Chart:   errMsg,
@@ -0,0 +1,154 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo // import "helm.sh/helm/v4/pkg/repo/v1"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// Well known credential helpers. Any program on the PATH that implements the
+// docker-credential-helpers protocol and is named docker-credential-<name>
+// may be used.
+const (
+	// CredentialHelperOSXKeychain stores credentials in the macOS Keychain.
+	CredentialHelperOSXKeychain = "osxkeychain"
+	// CredentialHelperWinCred stores credentials in the Windows Credential Manager.
+	CredentialHelperWinCred = "wincred"
+	// CredentialHelperSecretService stores credentials using the Linux secret service.
+	CredentialHelperSecretService = "secretservice"
+	// CredentialHelperPass stores credentials using pass.
+	CredentialHelperPass = "pass"
+)
+
+var credentialHelperNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ErrInvalidCredentialHelper indicates that a credential helper name is not valid.
+var ErrInvalidCredentialHelper = errors.New("invalid credential helper name")
+
+// CredentialStore is the store used to keep repository credentials outside of
+// the repositories file.
+type CredentialStore = credentials.Store
+
+// NewCredentialStore returns the credential store for the given helper name.
+//
+// It is a variable so that it can be replaced, for example in tests or by SDK
+// users that want to plug in their own secret storage.
+var NewCredentialStore = func(helper string) (CredentialStore, error) {
+	if err := ValidateCredentialHelper(helper); err != nil {
+		return nil, err
+	}
+	return credentials.NewNativeStore(helper), nil
+}
+
+// ValidateCredentialHelper checks that the helper name can safely be used to
+// build the docker-credential-<name> program name.
+func ValidateCredentialHelper(helper string) error {
+	if !credentialHelperNameRegex.MatchString(helper) {
+		return fmt.Errorf("%w: %q", ErrInvalidCredentialHelper, helper)
+	}
+	return nil
+}
+
+// StoreCredentials saves the username and password of the entry in the
+// credential store named by CredentialHelper and clears the password from the
+// entry so that it is not persisted in plain text.
+func (e *Entry) StoreCredentials(ctx context.Context) error {
+	if e.CredentialHelper == "" {
+		return nil
+	}
+	store, err := NewCredentialStore(e.CredentialHelper)
+	if err != nil {
+		return err
+	}
+	cred := auth.Credential{Username: e.Username, Password: e.Password}
+	if err := store.Put(ctx, e.URL, cred); err != nil {
+		return fmt.Errorf("unable to store credentials for %q using %q: %w", e.Name, e.CredentialHelper, err)
+	}
+	e.Password = ""
+	return nil
+}
+
+// LoadCredentials populates the username and password of the entry from the
+// credential store named by CredentialHelper.
+func (e *Entry) LoadCredentials(ctx context.Context) error {
+	if e.CredentialHelper == "" {
+		return nil
+	}
+	store, err := NewCredentialStore(e.CredentialHelper)
+	if err != nil {
+		return err
+	}
+	cred, err := store.Get(ctx, e.URL)
+	if err != nil {
+		return fmt.Errorf("unable to load credentials for %q using %q: %w", e.Name, e.CredentialHelper, err)
+	}
+	if cred.Username != "" {
+		e.Username = cred.Username
+	}
+	e.Password = cred.Password
+	return nil
+}
+
+// DeleteCredentials removes the credentials of the entry from the credential
+// store named by CredentialHelper.
+func (e *Entry) DeleteCredentials(ctx context.Context) error {
+	if e.CredentialHelper == "" {
+		return nil
+	}
+	store, err := NewCredentialStore(e.CredentialHelper)
+	if err != nil {
+		return err
+	}
+	return store.Delete(ctx, e.URL)
+}
+
+// BasicAuth returns the username and password of the entry. When the entry
+// uses a credential helper and has no password, they are loaded from the
+// helper on first use, so that reading the repositories file does not run
+// the helpers. Failures are logged rather than returned so that a missing
+// helper does not prevent using unauthenticated repositories.
+func (e *Entry) BasicAuth() (username, password string) {
+	if e.CredentialHelper != "" && e.Password == "" {
+		if err := e.LoadCredentials(context.Background()); err != nil {
+			slog.Warn("failed to load repository credentials", slog.String("repo", e.Name), slog.Any("error", err))
+		}
+	}
+	return e.Username, e.Password
+}
+
+// withoutStoredPasswords returns a copy of the file in which the passwords of
+// entries using a credential helper are cleared.
+func (r *File) withoutStoredPasswords() *File {
+	cp := *r
+	cp.Repositories = make([]*Entry, 0, len(r.Repositories))
+	for _, e := range r.Repositories {
+		if e != nil && e.CredentialHelper != "" && e.Password != "" {
+			ec := *e
+			ec.Password = ""
+			e = &ec
+		}
+		cp.Repositories = append(cp.Repositories, e)
+	}
+	return &cp
+}
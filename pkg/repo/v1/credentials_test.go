@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+func TestValidateCredentialHelper(t *testing.T) {
+	tests := []struct {
+		helper  string
+		wantErr bool
+	}{
+		{helper: CredentialHelperOSXKeychain},
+		{helper: CredentialHelperPass},
+		{helper: "ecr-login"},
+		{helper: "", wantErr: true},
+		{helper: "../evil", wantErr: true},
+		{helper: "a b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.helper, func(t *testing.T) {
+			err := ValidateCredentialHelper(tt.helper)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCredentialHelper)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFileCredentialHelperRoundTrip(t *testing.T) {
+	store := credentials.NewMemoryStore()
+	orig := NewCredentialStore
+	NewCredentialStore = func(string) (CredentialStore, error) { return store, nil }
+	defer func() { NewCredentialStore = orig }()
+
+	e := &Entry{
+		Name:             "secure",
+		URL:              "https://example.com/charts",
+		Username:         "user",
+		Password:         "s3cret",
+		CredentialHelper: CredentialHelperPass,
+	}
+	require.NoError(t, e.StoreCredentials(t.Context()))
+	assert.Empty(t, e.Password, "password should be cleared after it is stored")
+
+	// Simulate an entry that still has a password in memory when writing
+	e.Password = "s3cret"
+	f := NewFile()
+	f.Add(e, &Entry{Name: "plain", URL: "https://example.com/plain", Password: "visible"})
+
+	path := filepath.Join(t.TempDir(), "repositories.yaml")
+	require.NoError(t, f.WriteFile(path, 0600))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(b), "s3cret"), "stored password must not be written to the repositories file")
+	assert.Contains(t, string(b), "visible")
+	assert.Equal(t, "s3cret", e.Password, "writing the file must not modify the entry")
+
+	loaded, err := LoadFile(path)
+	require.NoError(t, err)
+	got := loaded.Get("secure")
+	require.NotNil(t, got)
+	assert.Empty(t, got.Password, "credentials must not be loaded when the file is read")
+	username, password := got.BasicAuth()
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "s3cret", password)
+
+	require.NoError(t, got.DeleteCredentials(t.Context()))
+	cred, err := store.Get(t.Context(), e.URL)
+	require.NoError(t, err)
+	assert.Empty(t, cred.Password)
+}
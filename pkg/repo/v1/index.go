@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/internal/fileutil"
@@ -38,6 +39,7 @@ import (
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/provenance"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 // APIVersionV1 is the v1 API version for index and repository files.
@@ -90,6 +92,11 @@ type IndexFile struct {
 	// Annotations are additional mappings uninterpreted by Helm. They are made available for
 	// other applications to add information to the index file.
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Shards are the paths, relative to the index file, of the index files
+	// holding more entries of the repository. Large repositories split their
+	// entries by the initial of the chart name, see Shard.
+	Shards []string `json:"shards,omitempty"`
 }
 
 // NewIndexFile initializes an index.
@@ -112,9 +119,91 @@ func LoadIndexFile(path string) (*IndexFile, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error loading %s: %w", path, err)
 	}
+	err = i.loadShards(path, func(shard string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(filepath.Dir(path), filepath.FromSlash(shard)))
+	})
+	if err != nil {
+		return nil, err
+	}
 	return i, nil
 }
 
+// loadShards merges the entries of the shards of the index, read with
+// readShard, into the index.
+func (i *IndexFile) loadShards(source string, readShard func(shard string) ([]byte, error)) error {
+	for _, shard := range i.Shards {
+		if !isLocalShard(shard) {
+			return fmt.Errorf("error loading %s: invalid shard %q, shards must be relative paths in the repository", source, shard)
+		}
+		data, err := readShard(shard)
+		if err != nil {
+			return fmt.Errorf("error loading shard %s of %s: %w", shard, source, err)
+		}
+		f, err := loadIndex(data, source+": "+shard)
+		if err != nil {
+			return fmt.Errorf("error loading shard %s of %s: %w", shard, source, err)
+		}
+		i.Merge(f)
+	}
+	if len(i.Shards) > 0 {
+		i.SortEntries()
+	}
+	return nil
+}
+
+// isLocalShard reports whether a shard is a relative path that does not
+// leave the directory of the index.
+func isLocalShard(shard string) bool {
+	return shard != "" && !strings.Contains(shard, ":") && !strings.Contains(shard, "\\") &&
+		!path.IsAbs(shard) && path.Clean(shard) == shard && shard != ".." && !strings.HasPrefix(shard, "../")
+}
+
+// ShardKey returns the key of the shard of a chart, the lower case initial of
+// its name, or "_" for names that do not start with a letter or a digit.
+func ShardKey(name string) string {
+	if name != "" {
+		c := name[0] | 0x20
+		if c >= 'a' && c <= 'z' {
+			return string(c)
+		}
+		if name[0] >= '0' && name[0] <= '9' {
+			return name[:1]
+		}
+	}
+	return "_"
+}
+
+// ShardFileName returns the name of the index file of a shard.
+func ShardFileName(key string) string {
+	return "index-" + key + ".yaml"
+}
+
+// Shard splits the entries of the index by ShardKey. The generation time of
+// each shard is the latest creation time of its entries, so that the shards
+// of unchanged charts do not change when the index is regenerated.
+func (i IndexFile) Shard() map[string]*IndexFile {
+	shards := map[string]*IndexFile{}
+	for name, cvs := range i.Entries {
+		key := ShardKey(name)
+		shard, ok := shards[key]
+		if !ok {
+			shard = &IndexFile{
+				APIVersion: APIVersionV1,
+				Entries:    map[string]ChartVersions{},
+				PublicKeys: []string{},
+			}
+			shards[key] = shard
+		}
+		shard.Entries[name] = cvs
+		for _, cv := range cvs {
+			if cv.Created.After(shard.Generated) {
+				shard.Generated = cv.Created
+			}
+		}
+	}
+	return shards
+}
+
 // MustAdd adds a file to the index
 // This can leave the index in an unsorted state
 func (i IndexFile) MustAdd(md *chart.Metadata, filename, baseURL, digest string) error {
@@ -129,17 +218,8 @@ func (i IndexFile) MustAdd(md *chart.Metadata, filename, baseURL, digest string)
 		return fmt.Errorf("validate failed for %s: %w", filename, err)
 	}
 
-	u := filename
-	if baseURL != "" {
-		_, file := filepath.Split(filename)
-		var err error
-		u, err = urlutil.URLJoin(baseURL, file)
-		if err != nil {
-			u = path.Join(baseURL, file)
-		}
-	}
 	cr := &ChartVersion{
-		URLs:     []string{u},
+		URLs:     []string{chartURL(filename, baseURL)},
 		Metadata: md,
 		Digest:   digest,
 		Created:  time.Now(),
@@ -149,6 +229,19 @@ func (i IndexFile) MustAdd(md *chart.Metadata, filename, baseURL, digest string)
 	return nil
 }
 
+// chartURL returns the URL of a chart archive in the index.
+func chartURL(filename, baseURL string) string {
+	if baseURL == "" {
+		return filename
+	}
+	_, file := filepath.Split(filename)
+	u, err := urlutil.URLJoin(baseURL, file)
+	if err != nil {
+		u = path.Join(baseURL, file)
+	}
+	return u
+}
+
 // Add adds a file to the index and logs an error.
 //
 // Deprecated: Use index.MustAdd instead.
@@ -172,7 +265,7 @@ func (i IndexFile) Has(name, version string) bool {
 // version without needing to parse SemVers.
 func (i IndexFile) SortEntries() {
 	for _, versions := range i.Entries {
-		sort.Sort(sort.Reverse(versions))
+		sort.Stable(sort.Reverse(versions))
 	}
 }
 
@@ -314,6 +407,20 @@ type ChartVersion struct {
 //
 // The index returned will be in an unsorted state
 func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
+	return IndexDirectoryIncremental(dir, baseURL, nil)
+}
+
+// IndexDirectoryIncremental reads a (flat) directory and generates an index,
+// reusing the entries of a previous index of the directory for the charts
+// that did not change.
+//
+// An archive is only hashed when it was modified after the previous index
+// was generated or has no entry in it, and only loaded when its digest
+// differs from the digest of its previous entry. The entries of unchanged
+// charts keep their digest and creation time. The previous index may be nil.
+//
+// The index returned will be in an unsorted state
+func IndexDirectoryIncremental(dir, baseURL string, previous *IndexFile) (*IndexFile, error) {
 	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
 	if err != nil {
 		return nil, err
@@ -324,6 +431,18 @@ func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
 	}
 	archives = append(archives, moreArchives...)
 
+	// Index the previous entries by URL.
+	previousEntries := map[string]*ChartVersion{}
+	if previous != nil {
+		for _, cvs := range previous.Entries {
+			for _, cv := range cvs {
+				if len(cv.URLs) > 0 {
+					previousEntries[cv.URLs[0]] = cv
+				}
+			}
+		}
+	}
+
 	index := NewIndexFile()
 	for _, arch := range archives {
 		fname, err := filepath.Rel(dir, arch)
@@ -340,14 +459,34 @@ func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
 			parentURL = path.Join(baseURL, parentDir)
 		}
 
+		var hash string
+		if cv, ok := previousEntries[chartURL(fname, parentURL)]; ok {
+			fi, err := os.Stat(arch)
+			if err != nil {
+				return index, err
+			}
+			if fi.ModTime().Before(previous.Generated) {
+				index.Entries[cv.Name] = append(index.Entries[cv.Name], cv)
+				continue
+			}
+			if hash, err = provenance.DigestFile(arch); err != nil {
+				return index, err
+			}
+			if hash == cv.Digest {
+				index.Entries[cv.Name] = append(index.Entries[cv.Name], cv)
+				continue
+			}
+		}
+
 		c, err := loader.Load(arch)
 		if err != nil {
 			// Assume this is not a chart.
 			continue
 		}
-		hash, err := provenance.DigestFile(arch)
-		if err != nil {
-			return index, err
+		if hash == "" {
+			if hash, err = provenance.DigestFile(arch); err != nil {
+				return index, err
+			}
 		}
 		if err := index.MustAdd(c.Metadata, fname, parentURL, hash); err != nil {
 			return index, fmt.Errorf("failed adding to %s to index: %w", fname, err)
@@ -356,6 +495,49 @@ func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
 	return index, nil
 }
 
+// IndexRegistry lists the charts in the repositories of an OCI registry under
+// the namespace of ref, such as oci://registry.example.com/charts, and
+// generates an index of them. The URLs of the chart versions are OCI
+// references with the version as tag, and their digests are the digests of
+// the chart archives. Artifacts that are not charts are skipped.
+//
+// The registry must implement the catalog API. The index returned will be in
+// an unsorted state.
+func IndexRegistry(client *registry.Client, ref string) (*IndexFile, error) {
+	repositories, err := client.Repositories(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	index := NewIndexFile()
+	for _, repository := range repositories {
+		tags, err := client.Tags(repository)
+		if err != nil {
+			return index, err
+		}
+		for _, tag := range tags {
+			chartRef := repository + ":" + tag
+			result, err := client.Inspect(chartRef)
+			if errors.Is(err, registry.ErrNotChart) {
+				slog.Warn("skipping artifact that is not a chart", "ref", chartRef, "error", err)
+				continue
+			}
+			if err != nil {
+				return index, err
+			}
+			digest := strings.TrimPrefix(result.Chart.Digest, "sha256:")
+			if err := index.MustAdd(result.Chart.Meta, registry.OCIScheme+"://"+chartRef, "", digest); err != nil {
+				return index, fmt.Errorf("failed adding %s to index: %w", chartRef, err)
+			}
+			if created, err := time.Parse(time.RFC3339, result.Annotations[ocispec.AnnotationCreated]); err == nil {
+				versions := index.Entries[result.Chart.Meta.Name]
+				versions[len(versions)-1].Created = created
+			}
+		}
+	}
+	return index, nil
+}
+
 // loadIndex loads an index file and does minimal validity checking.
 //
 // The source parameter is only used for logging.
@@ -24,9 +24,13 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
 
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/cli"
@@ -347,6 +351,63 @@ func TestDownloadIndexFile(t *testing.T) {
 		}
 		verifyLocalChartsFile(t, b, i)
 	})
+
+	t.Run("should download the shards of a sharded index", func(t *testing.T) {
+		full, err := LoadIndexFile(testfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := map[string][]byte{}
+		top := NewIndexFile()
+		for key, shard := range full.Shard() {
+			b, err := yaml.Marshal(shard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files["/"+ShardFileName(key)] = b
+			top.Shards = append(top.Shards, ShardFileName(key))
+		}
+		b, err := yaml.Marshal(top)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files["/index.yaml"] = b
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, ok := files[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(b)
+		})
+		srv, err := startLocalServerForTests(handler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer srv.Close()
+
+		r, err := NewChartRepository(&Entry{
+			Name: testRepo,
+			URL:  srv.URL,
+		}, getter.All(&cli.EnvSettings{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.CachePath = t.TempDir()
+
+		idx, err := r.DownloadIndexFile()
+		if err != nil {
+			t.Fatalf("Failed to download index file to %s: %#v", idx, err)
+		}
+		i, err := LoadIndexFile(idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(i.Shards) != 0 {
+			t.Errorf("expected the cached index to have no shards, got %v", i.Shards)
+		}
+		verifyLocalIndex(t, i)
+	})
 }
 
 func verifyLocalIndex(t *testing.T, i *IndexFile) {
@@ -757,3 +818,140 @@ func TestIsVersionRange(t *testing.T) {
 		})
 	}
 }
+
+func TestIndexDirectoryIncremental(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"frobnitz-1.2.3.tgz", "sprocket-1.1.0.tgz", "sprocket-1.2.0.tgz"} {
+		data, err := os.ReadFile(filepath.Join("testdata/repository", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		past := time.Now().Add(-time.Hour)
+		if err := os.Chtimes(filepath.Join(dir, name), past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous, err := IndexDirectory(dir, "http://localhost:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous.SortEntries()
+	frobnitz := previous.Entries["frobnitz"][0]
+	sprocket := previous.Entries["sprocket"][1]
+
+	// An archive older than the index is not hashed or loaded.
+	past := time.Now().Add(-time.Hour)
+	if err := os.WriteFile(filepath.Join(dir, "sprocket-1.1.0.tgz"), []byte("not a chart"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "sprocket-1.1.0.tgz"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	// A newer archive with the same digest keeps its entry.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "frobnitz-1.2.3.tgz"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	// A newer archive with a different digest is loaded again.
+	if err := os.WriteFile(filepath.Join(dir, "sprocket-1.2.0.tgz"), []byte("not a chart"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "sprocket-1.2.0.tgz"), future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := IndexDirectoryIncremental(dir, "http://localhost:8080", previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := index.Entries["frobnitz"]; len(got) != 1 || got[0] != frobnitz {
+		t.Errorf("expected the previous frobnitz entry, got %v", got)
+	}
+	if got := index.Entries["sprocket"]; len(got) != 1 || got[0] != sprocket {
+		t.Errorf("expected only the previous sprocket 1.1.0 entry, got %v", got)
+	}
+}
+
+func TestIndexShard(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	i := NewIndexFile()
+	for n, name := range []string{"alpine", "Apache", "nginx", "1password", "_internal"} {
+		if err := i.MustAdd(&chart.Metadata{APIVersion: "v2", Name: name, Version: "1.0.0"}, name+"-1.0.0.tgz", "", ""); err != nil {
+			t.Fatal(err)
+		}
+		i.Entries[name][0].Created = created.Add(time.Duration(n) * time.Hour)
+	}
+
+	shards := i.Shard()
+	var keys []string
+	for key := range shards {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if want := []string{"1", "_", "a", "n"}; !slices.Equal(keys, want) {
+		t.Fatalf("expected shards %v, got %v", want, keys)
+	}
+	a := shards["a"]
+	if len(a.Entries) != 2 || !a.Has("alpine", "1.0.0") || !a.Has("Apache", "1.0.0") {
+		t.Errorf("unexpected entries of shard a: %v", a.Entries)
+	}
+	if !a.Generated.Equal(created.Add(time.Hour)) {
+		t.Errorf("expected shard a to be generated at the latest creation time, got %s", a.Generated)
+	}
+	if got := ShardFileName("a"); got != "index-a.yaml" {
+		t.Errorf("unexpected shard file name %q", got)
+	}
+}
+
+func TestLoadIndexFileShards(t *testing.T) {
+	dir := t.TempDir()
+	shard := `apiVersion: v1
+entries:
+  alpine:
+  - name: alpine
+    version: 1.0.0
+    urls:
+    - alpine-1.0.0.tgz
+generated: "2024-01-02T03:04:05Z"
+`
+	if err := os.WriteFile(filepath.Join(dir, "index-a.yaml"), []byte(shard), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		shards string
+		err    string
+	}{
+		{name: "shard", shards: "[index-a.yaml]"},
+		{name: "missing shard", shards: "[index-b.yaml]", err: "error loading shard index-b.yaml"},
+		{name: "shard outside the repository", shards: "[../index-a.yaml]", err: `invalid shard "../index-a.yaml"`},
+		{name: "absolute shard", shards: "[/index-a.yaml]", err: `invalid shard "/index-a.yaml"`},
+		{name: "shard URL", shards: "['https://example.com/index-a.yaml']", err: "invalid shard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := "apiVersion: v1\nentries: {}\nshards: " + tt.shards + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "index.yaml"), []byte(index), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			i, err := LoadIndexFile(filepath.Join(dir, "index.yaml"))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !i.Has("alpine", "1.0.0") {
+				t.Errorf("expected the entries of the shard, got %v", i.Entries)
+			}
+		})
+	}
+}
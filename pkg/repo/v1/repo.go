@@ -30,6 +30,18 @@ type File struct {
 	APIVersion   string    `json:"apiVersion"`
 	Generated    time.Time `json:"generated"`
 	Repositories []*Entry  `json:"repositories"`
+	// Registries contains per host connection settings for OCI registries.
+	Registries []*RegistryEntry `json:"registries,omitempty"`
+}
+
+// RegistryEntry represents the connection settings of an OCI registry host
+type RegistryEntry struct {
+	Host                  string `json:"host"`
+	CertFile              string `json:"certFile,omitempty"`
+	KeyFile               string `json:"keyFile,omitempty"`
+	CAFile                string `json:"caFile,omitempty"`
+	InsecureSkipTLSVerify bool   `json:"insecure_skip_tls_verify,omitempty"`
+	Proxy                 string `json:"proxy,omitempty"`
 }
 
 // NewFile generates an empty repositories file.
@@ -51,8 +63,10 @@ func LoadFile(path string) (*File, error) {
 		return r, fmt.Errorf("couldn't load repositories file (%s): %w", path, err)
 	}
 
-	err = yaml.Unmarshal(b, r)
-	return r, err
+	if err := yaml.Unmarshal(b, r); err != nil {
+		return r, err
+	}
+	return r, nil
 }
 
 // Add adds one or more repo entries to a repo file.
@@ -94,6 +108,28 @@ func (r *File) Get(name string) *Entry {
 	return nil
 }
 
+// GetRegistry returns the registry entry for the given host if it exists,
+// otherwise returns nil
+func (r *File) GetRegistry(host string) *RegistryEntry {
+	for _, entry := range r.Registries {
+		if entry != nil && entry.Host == host {
+			return entry
+		}
+	}
+	return nil
+}
+
+// UpdateRegistry replaces the registry entry with the same host or adds it.
+func (r *File) UpdateRegistry(e *RegistryEntry) {
+	for i, entry := range r.Registries {
+		if entry != nil && entry.Host == e.Host {
+			r.Registries[i] = e
+			return
+		}
+	}
+	r.Registries = append(r.Registries, e)
+}
+
 // Remove removes the entry from the list of repositories.
 func (r *File) Remove(name string) bool {
 	cp := []*Entry{}
@@ -113,8 +149,10 @@ func (r *File) Remove(name string) bool {
 }
 
 // WriteFile writes a repositories file to the given path.
+//
+// Passwords of entries that use a credential helper are not written.
 func (r *File) WriteFile(path string, perm os.FileMode) error {
-	data, err := yaml.Marshal(r)
+	data, err := yaml.Marshal(r.withoutStoredPasswords())
 	if err != nil {
 		return err
 	}
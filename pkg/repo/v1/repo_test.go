@@ -18,6 +18,7 @@ package repo
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -255,3 +256,35 @@ func TestRemoveRepositoryInvalidEntries(t *testing.T) {
 		t.Errorf("repository %s not deleted", removeRepository)
 	}
 }
+
+func TestRegistryEntries(t *testing.T) {
+	f := NewFile()
+	if f.GetRegistry("ghcr.io") != nil {
+		t.Fatal("expected no registry entry in a new file")
+	}
+
+	f.UpdateRegistry(&RegistryEntry{Host: "ghcr.io", CAFile: "ca.pem"})
+	f.UpdateRegistry(&RegistryEntry{Host: "localhost:5000", InsecureSkipTLSVerify: true})
+	f.UpdateRegistry(&RegistryEntry{Host: "ghcr.io", Proxy: "http://proxy:3128"})
+
+	if len(f.Registries) != 2 {
+		t.Fatalf("expected 2 registry entries, got %d", len(f.Registries))
+	}
+
+	path := filepath.Join(t.TempDir(), "repositories.yaml")
+	if err := f.WriteFile(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ghcr := loaded.GetRegistry("ghcr.io")
+	if ghcr == nil || ghcr.Proxy != "http://proxy:3128" || ghcr.CAFile != "" {
+		t.Errorf("unexpected ghcr.io entry %+v", ghcr)
+	}
+	if local := loaded.GetRegistry("localhost:5000"); local == nil || !local.InsecureSkipTLSVerify {
+		t.Errorf("unexpected localhost:5000 entry %+v", local)
+	}
+}
@@ -181,6 +181,7 @@ func NewOCIServer(t *testing.T, dir string) (*OCIServer, error) {
 	config.HTTP.Addr = ln.Addr().String()
 	config.HTTP.DrainTimeout = time.Duration(10) * time.Second
 	config.Storage = map[string]configuration.Parameters{"inmemory": map[string]any{}}
+	config.Catalog.MaxEntries = 1000
 	config.Auth = configuration.Auth{
 		"htpasswd": configuration.Parameters{
 			"realm": "localhost",
@@ -215,6 +216,15 @@ func (srv *OCIServer) RunWithReturn(t *testing.T, opts ...OCIServerOpt) *OCIServ
 	}
 
 	go srv.ListenAndServe()
+	// Wait for the registry to accept connections before logging in.
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", srv.RegistryURL)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 10*time.Second, 10*time.Millisecond, "registry not listening on %s", srv.RegistryURL)
 
 	credentialsFile := filepath.Join(srv.Dir, "config.json")
 
@@ -0,0 +1,290 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"helm.sh/helm/v4/pkg/release"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// deltaOpOverhead approximates the size of an encoded delta operation, to
+// tell whether a delta is smaller than the manifest it replaces.
+const deltaOpOverhead = 32
+
+// encodeManifestDelta returns a copy of the release with its manifest stored
+// as a delta against the next revision of the release. The release is
+// returned as is when ManifestDeltas is not set, when it is the latest
+// revision, or when the delta would not be smaller than its manifest.
+func (s *Storage) encodeManifestDelta(rls release.Releaser) (release.Releaser, error) {
+	if !s.ManifestDeltas {
+		return rls, nil
+	}
+	rel, err := releaserToV1Release(rls)
+	if err != nil || rel == nil || rel.ManifestDelta != nil || rel.Manifest == "" {
+		return rls, nil
+	}
+	h, err := s.Driver.Query(map[string]string{"name": rel.Name, "owner": "helm"})
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return rls, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	records, err := releaseListToV1List(h)
+	if err != nil {
+		return nil, err
+	}
+	var next *rspb.Release
+	for _, r := range records {
+		if r.Version > rel.Version && (next == nil || r.Version < next.Version) {
+			next = r
+		}
+	}
+	if next == nil {
+		return rls, nil
+	}
+	base, err := s.manifestOf(next, manifestCache(records))
+	if err != nil {
+		return nil, err
+	}
+	ops, ok := diffManifest(base, rel.Manifest)
+	if !ok {
+		return rls, nil
+	}
+	out := *rel
+	out.Manifest = ""
+	out.ManifestDelta = &rspb.ManifestDelta{Base: next.Version, Ops: ops}
+	return &out, nil
+}
+
+// rebaseManifestDeltas stores the previous head revision of the release as a
+// delta against rel, its new latest revision. Earlier revisions are deltas
+// against their next revision already, so they are left untouched.
+func (s *Storage) rebaseManifestDeltas(rel *rspb.Release) error {
+	h, err := s.Driver.Query(map[string]string{"name": rel.Name, "owner": "helm"})
+	if err != nil {
+		return err
+	}
+	records, err := releaseListToV1List(h)
+	if err != nil {
+		return err
+	}
+	var prev *rspb.Release
+	for _, r := range records {
+		if r.Version > rel.Version {
+			return nil
+		}
+		if r.Version < rel.Version && (prev == nil || r.Version > prev.Version) {
+			prev = r
+		}
+	}
+	if prev == nil || prev.ManifestDelta != nil || prev.Manifest == "" {
+		return nil
+	}
+	ops, ok := diffManifest(rel.Manifest, prev.Manifest)
+	if !ok {
+		return nil
+	}
+	out := *prev
+	out.Manifest = ""
+	out.ManifestDelta = &rspb.ManifestDelta{Base: rel.Version, Ops: ops}
+	if err := s.Driver.Update(makeKey(out.Name, out.Version), &out); err != nil {
+		return fmt.Errorf("revision %d: %w", out.Version, err)
+	}
+	return nil
+}
+
+// detachManifestDeltas stores the revisions of the release that are deltas
+// against the given revision with their full manifest, so that they can still
+// be read once the revision is deleted.
+func (s *Storage) detachManifestDeltas(name string, version int) error {
+	h, err := s.Driver.Query(map[string]string{"name": name, "owner": "helm"})
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	records, err := releaseListToV1List(h)
+	if err != nil {
+		return err
+	}
+	manifests := manifestCache(records)
+	for _, r := range records {
+		if r.ManifestDelta == nil || r.ManifestDelta.Base != version {
+			continue
+		}
+		manifest, err := s.manifestOf(r, manifests)
+		if err != nil {
+			return fmt.Errorf("unable to rebuild the manifest of release %q revision %d: %w", r.Name, r.Version, err)
+		}
+		out := *r
+		out.Manifest = manifest
+		out.ManifestDelta = nil
+		if err := s.Driver.Update(makeKey(out.Name, out.Version), &out); err != nil {
+			return fmt.Errorf("revision %d: %w", out.Version, err)
+		}
+	}
+	return nil
+}
+
+// expandManifest returns a copy of the release with its manifest rebuilt
+// when it is stored as a delta. manifests caches the manifests of the
+// revisions, by manifestKey.
+func (s *Storage) expandManifest(rls release.Releaser, manifests map[string]string) (release.Releaser, error) {
+	rel, err := releaserToV1Release(rls)
+	if err != nil {
+		return nil, err
+	}
+	if rel == nil || rel.ManifestDelta == nil {
+		return rls, nil
+	}
+	manifest, err := s.manifestOf(rel, manifests)
+	if err != nil {
+		return nil, fmt.Errorf("unable to rebuild the manifest of release %q revision %d: %w", rel.Name, rel.Version, err)
+	}
+	out := *rel
+	out.Manifest = manifest
+	out.ManifestDelta = nil
+	return &out, nil
+}
+
+func (s *Storage) expandManifestList(ls []release.Releaser) ([]release.Releaser, error) {
+	records, err := releaseListToV1List(ls)
+	if err != nil {
+		return ls, nil
+	}
+	manifests := manifestCache(records)
+	for i, rls := range ls {
+		exp, err := s.expandManifest(rls, manifests)
+		if err != nil {
+			return nil, err
+		}
+		ls[i] = exp
+	}
+	return ls, nil
+}
+
+// manifestOf returns the manifest of the revision, rebuilt from the manifest
+// of its base revision when it is stored as a delta.
+func (s *Storage) manifestOf(rel *rspb.Release, manifests map[string]string) (string, error) {
+	d := rel.ManifestDelta
+	if d == nil {
+		return rel.Manifest, nil
+	}
+	// Bases are later revisions, which guarantees that rebuilding ends.
+	if d.Base <= rel.Version {
+		return "", fmt.Errorf("invalid base revision %d", d.Base)
+	}
+	key := manifestKey(rel.Namespace, rel.Name, d.Base)
+	base, ok := manifests[key]
+	if !ok {
+		rls, err := s.Driver.Get(makeKey(rel.Name, d.Base))
+		if err != nil {
+			return "", err
+		}
+		brel, err := releaserToV1Release(rls)
+		if err != nil {
+			return "", err
+		}
+		if base, err = s.manifestOf(brel, manifests); err != nil {
+			return "", err
+		}
+		manifests[key] = base
+	}
+	return applyManifestDelta(base, d.Ops)
+}
+
+// manifestCache returns the manifests of the records that are not stored as
+// deltas, by manifestKey.
+func manifestCache(records []*rspb.Release) map[string]string {
+	manifests := make(map[string]string, len(records))
+	for _, r := range records {
+		if r != nil && r.ManifestDelta == nil {
+			manifests[manifestKey(r.Namespace, r.Name, r.Version)] = r.Manifest
+		}
+	}
+	return manifests
+}
+
+func manifestKey(namespace, name string, version int) string {
+	return namespace + "/" + makeKey(name, version)
+}
+
+// diffManifest returns the operations that rebuild manifest from base, and
+// whether they are smaller than manifest.
+func diffManifest(base, manifest string) ([]rspb.ManifestDeltaOp, bool) {
+	a, b := manifestLines(base), manifestLines(manifest)
+	var ops []rspb.ManifestDeltaOp
+	size := 0
+	for _, c := range difflib.NewMatcher(a, b).GetOpCodes() {
+		switch c.Tag {
+		case 'e':
+			ops = append(ops, rspb.ManifestDeltaOp{Start: c.I1, Count: c.I2 - c.I1})
+			size += deltaOpOverhead
+		case 'r', 'i':
+			insert := strings.Join(b[c.J1:c.J2], "")
+			if n := len(ops); n > 0 && ops[n-1].Count == 0 {
+				ops[n-1].Insert += insert
+			} else {
+				ops = append(ops, rspb.ManifestDeltaOp{Insert: insert})
+				size += deltaOpOverhead
+			}
+			size += len(insert)
+		}
+	}
+	return ops, size < len(manifest)
+}
+
+// applyManifestDelta rebuilds a manifest from the lines of base.
+func applyManifestDelta(base string, ops []rspb.ManifestDeltaOp) (string, error) {
+	lines := manifestLines(base)
+	var sb strings.Builder
+	for _, op := range ops {
+		if op.Count == 0 {
+			sb.WriteString(op.Insert)
+			continue
+		}
+		if op.Start < 0 || op.Count < 0 || op.Start+op.Count > len(lines) {
+			return "", fmt.Errorf("invalid delta operation copying lines %d to %d of %d", op.Start, op.Start+op.Count, len(lines))
+		}
+		for _, l := range lines[op.Start : op.Start+op.Count] {
+			sb.WriteString(l)
+		}
+	}
+	return sb.String(), nil
+}
+
+// manifestLines splits a manifest into lines that keep their line endings, so
+// that joining them returns the manifest.
+func manifestLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
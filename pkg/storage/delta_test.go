@@ -0,0 +1,177 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release/common"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// testManifest returns a manifest of n ConfigMaps, the data of which depends
+// on revision.
+func testManifest(n, revision int) string {
+	var sb strings.Builder
+	for i := range n {
+		fmt.Fprintf(&sb, "---\n# Source: chart/templates/cm%d.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm%d\ndata:\n  key: value\n", i, i)
+	}
+	fmt.Fprintf(&sb, "  revision: \"%d\"\n", revision)
+	return sb.String()
+}
+
+func TestDiffManifest(t *testing.T) {
+	large := testManifest(20, 1)
+
+	tests := []struct {
+		name     string
+		base     string
+		manifest string
+		smaller  bool
+	}{
+		{name: "equal", base: large, manifest: large, smaller: true},
+		{name: "changed line", base: large, manifest: testManifest(20, 2), smaller: true},
+		{name: "missing final newline", base: large, manifest: strings.TrimSuffix(testManifest(20, 2), "\n"), smaller: true},
+		{name: "removed lines", base: testManifest(30, 1), manifest: large, smaller: true},
+		{name: "empty base", base: "", manifest: large},
+		{name: "empty manifest", base: large, manifest: ""},
+		{name: "unrelated", base: large, manifest: "kind: Secret\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, smaller := diffManifest(tt.base, tt.manifest)
+			assert.Equal(t, tt.smaller, smaller)
+			got, err := applyManifestDelta(tt.base, ops)
+			require.NoError(t, err)
+			assert.Equal(t, tt.manifest, got)
+		})
+	}
+}
+
+func TestApplyManifestDeltaInvalid(t *testing.T) {
+	_, err := applyManifestDelta("a\nb\n", []rspb.ManifestDeltaOp{{Start: 1, Count: 2}})
+	assert.Error(t, err)
+}
+
+func TestStorageManifestDeltas(t *testing.T) {
+	const name = "angry-bird"
+	s := Init(driver.NewMemory())
+	s.ManifestDeltas = true
+
+	for v := 1; v <= 3; v++ {
+		rls := ReleaseTestData{Name: name, Version: v, Status: common.StatusDeployed}.ToRelease()
+		rls.Manifest = testManifest(20, v)
+		require.NoError(t, s.Create(rls))
+		if v > 1 {
+			// Supersede the previous revision, like an upgrade does.
+			prev, err := s.Get(name, v-1)
+			require.NoError(t, err)
+			prel, err := releaserToV1Release(prev)
+			require.NoError(t, err)
+			superseded := *prel
+			superseded.Info = &rspb.Info{Status: common.StatusSuperseded}
+			require.NoError(t, s.Update(&superseded))
+		}
+	}
+
+	for v := 1; v <= 3; v++ {
+		stored, err := s.Driver.Get(makeKey(name, v))
+		require.NoError(t, err)
+		srel, err := releaserToV1Release(stored)
+		require.NoError(t, err)
+		if v == 3 {
+			assert.Nil(t, srel.ManifestDelta, "the latest revision is stored in full")
+			assert.Equal(t, testManifest(20, v), srel.Manifest)
+		} else {
+			require.NotNil(t, srel.ManifestDelta, "revision %d is stored as a delta", v)
+			assert.Equal(t, v+1, srel.ManifestDelta.Base)
+			assert.Empty(t, srel.Manifest)
+		}
+
+		rls, err := s.Get(name, v)
+		require.NoError(t, err)
+		rel, err := releaserToV1Release(rls)
+		require.NoError(t, err)
+		assert.Equal(t, testManifest(20, v), rel.Manifest)
+		assert.Nil(t, rel.ManifestDelta)
+	}
+
+	// The history can be read whether or not deltas are enabled.
+	s.ManifestDeltas = false
+	h, err := s.History(name)
+	require.NoError(t, err)
+	rls, err := releaseListToV1List(h)
+	require.NoError(t, err)
+	require.Len(t, rls, 3)
+	for _, rel := range rls {
+		assert.Equal(t, testManifest(20, rel.Version), rel.Manifest)
+	}
+
+	// A revision whose base is missing cannot be read, but can be removed.
+	_, err = s.Driver.Delete(makeKey(name, 2))
+	require.NoError(t, err)
+	_, err = s.Get(name, 1)
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+	_, err = s.History(name)
+	assert.Error(t, err)
+	_, err = s.Delete(name, 1)
+	require.NoError(t, err)
+}
+
+func TestStorageManifestDeltasPrune(t *testing.T) {
+	const name = "angry-bird"
+	s := Init(driver.NewMemory())
+	s.ManifestDeltas = true
+	s.MaxHistory = 3
+
+	for v := 1; v <= 4; v++ {
+		status := common.StatusFailed
+		if v == 1 {
+			status = common.StatusDeployed
+		}
+		rls := ReleaseTestData{Name: name, Version: v, Status: status}.ToRelease()
+		rls.Manifest = testManifest(20, v)
+		require.NoError(t, s.Create(rls))
+	}
+
+	// Revision 2, the base of the deployed revision, is pruned.
+	_, err := s.Get(name, 2)
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+
+	h, err := s.History(name)
+	require.NoError(t, err)
+	rls, err := releaseListToV1List(h)
+	require.NoError(t, err)
+	require.Len(t, rls, 3)
+	for _, rel := range rls {
+		assert.Equal(t, testManifest(20, rel.Version), rel.Manifest)
+	}
+
+	deployed, err := s.Deployed(name)
+	require.NoError(t, err)
+	rel, err := releaserToV1Release(deployed)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rel.Version)
+	assert.Equal(t, testManifest(20, 1), rel.Manifest)
+}
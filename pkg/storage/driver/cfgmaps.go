@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"strconv"
 	"strings"
 	"time"
@@ -48,6 +49,8 @@ type ConfigMaps struct {
 
 	// Embed a LogHolder to provide logger functionality
 	logging.LogHolder
+	// Embed an EncryptionHolder to encrypt the stored releases
+	EncryptionHolder
 }
 
 // NewConfigMaps initializes a new ConfigMaps wrapping an implementation of
@@ -79,7 +82,7 @@ func (cfgmaps *ConfigMaps) Get(key string) (release.Releaser, error) {
 		return nil, err
 	}
 	// found the configmap, decode the base64 data string
-	r, err := decodeRelease(obj.Data["release"])
+	r, err := cfgmaps.decodeRelease(obj.Data["release"])
 	if err != nil {
 		cfgmaps.Logger().Debug("failed to decode data", slog.String("key", key), slog.Any("error", err))
 		return nil, err
@@ -107,7 +110,7 @@ func (cfgmaps *ConfigMaps) List(filter func(release.Releaser) bool) ([]release.R
 	// iterate over the configmaps object list
 	// and decode each release
 	for _, item := range list.Items {
-		rls, err := decodeRelease(item.Data["release"])
+		rls, err := cfgmaps.decodeRelease(item.Data["release"])
 		if err != nil {
 			cfgmaps.Logger().Debug("failed to decode release", slog.Any("item", item), slog.Any("error", err))
 			continue
@@ -147,7 +150,7 @@ func (cfgmaps *ConfigMaps) Query(labels map[string]string) ([]release.Releaser,
 
 	var results []release.Releaser
 	for _, item := range list.Items {
-		rls, err := decodeRelease(item.Data["release"])
+		rls, err := cfgmaps.decodeRelease(item.Data["release"])
 		if err != nil {
 			cfgmaps.Logger().Debug("failed to decode release", slog.Any("error", err))
 			continue
@@ -179,7 +182,7 @@ func (cfgmaps *ConfigMaps) Create(key string, rls release.Releaser) error {
 	}
 
 	// create a new configmap to hold the release
-	obj, err := newConfigMapsObject(key, rel, lbs)
+	obj, err := newConfigMapsObject(key, rel, lbs, cfgmaps.EncryptionProvider())
 	if err != nil {
 		cfgmaps.Logger().Debug("failed to encode release", slog.String("name", rac.Name()), slog.Any("error", err))
 		return err
@@ -212,7 +215,7 @@ func (cfgmaps *ConfigMaps) Update(key string, rel release.Releaser) error {
 	lbs.set("modifiedAt", strconv.FormatInt(time.Now().Unix(), 10))
 
 	// create a new configmap object to hold the release
-	obj, err := newConfigMapsObject(key, rls, lbs)
+	obj, err := newConfigMapsObject(key, rls, lbs, cfgmaps.EncryptionProvider())
 	if err != nil {
 		cfgmaps.Logger().Debug(
 			"failed to encode release",
@@ -255,11 +258,11 @@ func (cfgmaps *ConfigMaps) Delete(key string) (rls release.Releaser, err error)
 //	"status"         - status of the release (see pkg/release/status.go for variants)
 //	"owner"          - owner of the configmap, currently "helm".
 //	"name"           - name of the release.
-func newConfigMapsObject(key string, rls *rspb.Release, lbs labels) (*v1.ConfigMap, error) {
+func newConfigMapsObject(key string, rls *rspb.Release, lbs labels, enc EncryptionProvider) (*v1.ConfigMap, error) {
 	const owner = "helm"
 
 	// encode the release
-	s, err := encodeRelease(rls)
+	s, err := encodeEncryptedRelease(rls, enc)
 	if err != nil {
 		return nil, err
 	}
@@ -280,8 +283,9 @@ func newConfigMapsObject(key string, rls *rspb.Release, lbs labels) (*v1.ConfigM
 	// create and return configmap object
 	return &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   key,
-			Labels: lbs.toMap(),
+			Name:        key,
+			Labels:      lbs.toMap(),
+			Annotations: maps.Clone(rls.Annotations),
 		},
 		Data: map[string]string{"release": s},
 	}, nil
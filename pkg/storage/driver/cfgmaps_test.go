@@ -62,7 +62,7 @@ func TestUncompressedConfigMapGet(t *testing.T) {
 	rel := releaseStub(name, vers, namespace, common.StatusDeployed)
 
 	// Create a test fixture which contains an uncompressed release
-	cfgmap, err := newConfigMapsObject(key, rel, nil)
+	cfgmap, err := newConfigMapsObject(key, rel, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to create configmap: %s", err)
 	}
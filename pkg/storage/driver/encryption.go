@@ -0,0 +1,290 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package driver // import "helm.sh/helm/v4/pkg/storage/driver"
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var (
+	_ EncryptionSetter = (*Secrets)(nil)
+	_ EncryptionSetter = (*ConfigMaps)(nil)
+	_ EncryptionSetter = (*SQL)(nil)
+)
+
+// EncryptionProvider encrypts and decrypts the payloads of release records.
+//
+// The Secrets, ConfigMaps and SQL drivers encrypt the releases they store
+// with the provider set by SetEncryptionProvider, and still read the records
+// stored unencrypted.
+type EncryptionProvider interface {
+	// Name identifies the provider in the encrypted records.
+	Name() string
+	// Encrypt encrypts a payload.
+	Encrypt(plaintext []byte) ([]byte, error)
+	// Decrypt decrypts a payload encrypted by Encrypt.
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// EncryptionSetter is implemented by the drivers that can encrypt the
+// releases they store.
+type EncryptionSetter interface {
+	SetEncryptionProvider(p EncryptionProvider)
+}
+
+// EncryptionHolder is embedded by the drivers to hold their encryption
+// provider.
+type EncryptionHolder struct {
+	provider EncryptionProvider
+}
+
+// SetEncryptionProvider sets the provider encrypting the records. A nil
+// provider stores the records unencrypted.
+func (h *EncryptionHolder) SetEncryptionProvider(p EncryptionProvider) {
+	h.provider = p
+}
+
+// EncryptionProvider returns the provider encrypting the records, or nil.
+func (h *EncryptionHolder) EncryptionProvider() EncryptionProvider {
+	return h.provider
+}
+
+// EncryptionProviderFromEnv returns the encryption provider configured by the
+// environment:
+//
+//   - HELM_STORAGE_ENCRYPTION_KEY holds a comma-separated list of base64
+//     encoded AES keys, see NewAESGCMProvider. The first key encrypts the
+//     records, the others are only used to decrypt records encrypted before a
+//     key rotation.
+//   - HELM_STORAGE_ENCRYPTION_KMS_PLUGIN holds the path of a KMS plugin that
+//     encrypts the data keys of envelope encryption, see NewKMSProvider.
+//   - HELM_STORAGE_ENCRYPTION_COMMAND holds the path of an executable that
+//     encrypts the payloads, see NewExecProvider.
+//
+// It returns nil when none is set.
+func EncryptionProviderFromEnv() (EncryptionProvider, error) {
+	vars := map[string]string{}
+	for _, name := range []string{"HELM_STORAGE_ENCRYPTION_KEY", "HELM_STORAGE_ENCRYPTION_KMS_PLUGIN", "HELM_STORAGE_ENCRYPTION_COMMAND"} {
+		if v := os.Getenv(name); v != "" {
+			vars[name] = v
+		}
+	}
+	if len(vars) > 1 {
+		return nil, errors.New("only one of HELM_STORAGE_ENCRYPTION_KEY, HELM_STORAGE_ENCRYPTION_KMS_PLUGIN and HELM_STORAGE_ENCRYPTION_COMMAND can be set")
+	}
+	if v, ok := vars["HELM_STORAGE_ENCRYPTION_KEY"]; ok {
+		var keys [][]byte
+		for k := range strings.SplitSeq(v, ",") {
+			key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(k))
+			if err != nil {
+				return nil, fmt.Errorf("invalid HELM_STORAGE_ENCRYPTION_KEY: %w", err)
+			}
+			keys = append(keys, key)
+		}
+		return NewAESGCMProvider(keys...)
+	}
+	if v, ok := vars["HELM_STORAGE_ENCRYPTION_KMS_PLUGIN"]; ok {
+		return NewKMSProvider(NewExecProvider(v)), nil
+	}
+	if v, ok := vars["HELM_STORAGE_ENCRYPTION_COMMAND"]; ok {
+		return NewExecProvider(v), nil
+	}
+	return nil, nil
+}
+
+// aesKeyIDLen is the length of the key IDs prefixing the AES-GCM payloads.
+const aesKeyIDLen = 4
+
+type aesGCMProvider struct {
+	primary []byte
+	keys    map[string]cipher.AEAD
+}
+
+// NewAESGCMProvider returns a provider that encrypts the payloads with AES-GCM
+// using local AES keys of 16, 24 or 32 bytes. The first key encrypts the
+// payloads. All the keys decrypt them: the payloads are prefixed with the ID
+// of their key, so keys can be rotated by adding a new key first and keeping
+// the old ones until every record is encrypted with the new key.
+func NewAESGCMProvider(keys ...[]byte) (EncryptionProvider, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption key")
+	}
+	p := &aesGCMProvider{keys: map[string]cipher.AEAD{}}
+	for i, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %d: %w", i+1, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		id := aesKeyID(key)
+		if i == 0 {
+			p.primary = id
+		}
+		p.keys[string(id)] = aead
+	}
+	return p, nil
+}
+
+func aesKeyID(key []byte) []byte {
+	sum := sha256.Sum256(key)
+	return sum[:aesKeyIDLen]
+}
+
+func (p *aesGCMProvider) Name() string { return "aes-gcm" }
+
+func (p *aesGCMProvider) Encrypt(plaintext []byte) ([]byte, error) {
+	aead := p.keys[string(p.primary)]
+	out := make([]byte, aesKeyIDLen+aead.NonceSize(), aesKeyIDLen+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	copy(out, p.primary)
+	if _, err := rand.Read(out[aesKeyIDLen:]); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, out[aesKeyIDLen:], plaintext, nil), nil
+}
+
+func (p *aesGCMProvider) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < aesKeyIDLen {
+		return nil, errors.New("encrypted payload is too short")
+	}
+	aead, ok := p.keys[string(ciphertext[:aesKeyIDLen])]
+	if !ok {
+		return nil, fmt.Errorf("payload encrypted with unknown key %x", ciphertext[:aesKeyIDLen])
+	}
+	ciphertext = ciphertext[aesKeyIDLen:]
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("encrypted payload is too short")
+	}
+	return aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+}
+
+type kmsProvider struct {
+	kms EncryptionProvider
+}
+
+// NewKMSProvider returns a provider that uses envelope encryption: each
+// payload is encrypted with a random data key using AES-GCM, and the data key
+// is encrypted by the kms provider and stored along the payload. The kms
+// provider, typically a KMS plugin run by NewExecProvider, only ever sees the
+// data keys.
+func NewKMSProvider(kms EncryptionProvider) EncryptionProvider {
+	return &kmsProvider{kms: kms}
+}
+
+func (p *kmsProvider) Name() string { return "kms" }
+
+func (p *kmsProvider) Encrypt(plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	encryptedKey, err := p.kms.Encrypt(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := NewAESGCMProvider(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if len(encryptedKey) > 0xffff {
+		return nil, errors.New("encrypted data key is too long")
+	}
+	out := make([]byte, 0, 2+len(encryptedKey)+len(data))
+	out = append(out, byte(len(encryptedKey)>>8), byte(len(encryptedKey)))
+	out = append(out, encryptedKey...)
+	return append(out, data...), nil
+}
+
+func (p *kmsProvider) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 2 {
+		return nil, errors.New("encrypted payload is too short")
+	}
+	n := int(ciphertext[0])<<8 | int(ciphertext[1])
+	if len(ciphertext) < 2+n {
+		return nil, errors.New("encrypted payload is too short")
+	}
+	dataKey, err := p.kms.Decrypt(ciphertext[2 : 2+n])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := NewAESGCMProvider(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Decrypt(ciphertext[2+n:])
+}
+
+// ExecProviderTimeout bounds each run of the executable of the providers
+// returned by NewExecProvider, so that a hung KMS plugin does not block the
+// storage forever.
+var ExecProviderTimeout = 30 * time.Second
+
+type execProvider struct {
+	command string
+}
+
+// NewExecProvider returns a provider that delegates the encryption to an
+// external process. The executable is run as "<command> encrypt" or
+// "<command> decrypt", reads the payload on its standard input and writes the
+// encrypted or decrypted payload on its standard output.
+func NewExecProvider(command string) EncryptionProvider {
+	return &execProvider{command: command}
+}
+
+func (p *execProvider) Name() string { return "exec" }
+
+func (p *execProvider) Encrypt(plaintext []byte) ([]byte, error) {
+	return p.run("encrypt", plaintext)
+}
+
+func (p *execProvider) Decrypt(ciphertext []byte) ([]byte, error) {
+	return p.run("decrypt", ciphertext)
+}
+
+func (p *execProvider) run(op string, in []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ExecProviderTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, op)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s %s: did not finish within %s", p.command, op, ExecProviderTimeout)
+		}
+		return nil, fmt.Errorf("%s %s: %w: %s", p.command, op, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return stdout.Bytes(), nil
+}
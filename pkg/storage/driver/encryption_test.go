@@ -0,0 +1,229 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package driver
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v4/pkg/release/common"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestAESGCMProviderKeyRotation(t *testing.T) {
+	oldKey, newKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 16)
+
+	old, err := NewAESGCMProvider(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := old.Encrypt([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted, []byte("payload")) {
+		t.Fatal("expected the payload to be encrypted")
+	}
+
+	rotated, err := NewAESGCMProvider(newKey, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := rotated.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("expected the old key to decrypt the payload: %s", err)
+	}
+	if string(decrypted) != "payload" {
+		t.Errorf("expected %q, got %q", "payload", decrypted)
+	}
+
+	reencrypted, err := rotated.Encrypt(decrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Decrypt(reencrypted); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("expected the payload to be encrypted with the new key, got %v", err)
+	}
+
+	if _, err := NewAESGCMProvider([]byte("short")); err == nil {
+		t.Error("expected an error for an invalid key size")
+	}
+}
+
+func TestKMSProvider(t *testing.T) {
+	kms, err := NewAESGCMProvider(bytes.Repeat([]byte{3}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewKMSProvider(kms)
+	a, err := p.Encrypt([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.Encrypt([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a, b) {
+		t.Error("expected each payload to be encrypted with a new data key")
+	}
+	decrypted, err := p.Decrypt(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decrypted) != "payload" {
+		t.Errorf("expected %q, got %q", "payload", decrypted)
+	}
+}
+
+func TestExecProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test provider is a shell script")
+	}
+	// The test provider reverses the case of the payload.
+	script := filepath.Join(t.TempDir(), "provider")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncase $1 in\nencrypt) tr a-z A-Z ;;\ndecrypt) tr A-Z a-z ;;\n*) echo unknown >&2; exit 1 ;;\nesac\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p := NewExecProvider(script)
+	encrypted, err := p.Encrypt([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encrypted) != "PAYLOAD" {
+		t.Errorf("expected %q, got %q", "PAYLOAD", encrypted)
+	}
+	decrypted, err := p.Decrypt(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decrypted) != "payload" {
+		t.Errorf("expected %q, got %q", "payload", decrypted)
+	}
+
+	if _, err := NewExecProvider(filepath.Join(t.TempDir(), "missing")).Encrypt(nil); err == nil {
+		t.Error("expected an error for a missing executable")
+	}
+
+	hung := filepath.Join(t.TempDir(), "hung")
+	if err := os.WriteFile(hung, []byte("#!/bin/sh\nexec sleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	timeout := ExecProviderTimeout
+	ExecProviderTimeout = 50 * time.Millisecond
+	defer func() { ExecProviderTimeout = timeout }()
+	if _, err := NewExecProvider(hung).Encrypt(nil); err == nil || !strings.Contains(err.Error(), "did not finish") {
+		t.Errorf("expected a timeout error for a hung executable, got %v", err)
+	}
+}
+
+func TestEncodeEncryptedRelease(t *testing.T) {
+	p, err := NewAESGCMProvider(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rls := releaseStub("smug-pigeon", 1, "default", common.StatusDeployed)
+	// The labels are stored in the metadata of the records.
+	rls.Labels = nil
+
+	data, err := encodeEncryptedRelease(rls, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeRelease(data); err == nil || !strings.Contains(err.Error(), "no encryption provider is configured") {
+		t.Errorf("expected an error decoding an encrypted release without provider, got %v", err)
+	}
+	if _, err := decodeEncryptedRelease(data, NewKMSProvider(p)); err == nil || !strings.Contains(err.Error(), `encrypted by the "aes-gcm" encryption provider`) {
+		t.Errorf("expected an error decoding with another provider, got %v", err)
+	}
+	got, err := decodeEncryptedRelease(data, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rls, got) {
+		t.Errorf("expected %v, got %v", rls, got)
+	}
+
+	// Records stored unencrypted are still read.
+	plain, err := encodeRelease(rls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := decodeEncryptedRelease(plain, p); err != nil || !reflect.DeepEqual(rls, got) {
+		t.Errorf("expected to decode the unencrypted release, got %v, %v", got, err)
+	}
+}
+
+func TestSecretsEncryption(t *testing.T) {
+	p, err := NewAESGCMProvider(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := newTestFixtureSecrets(t)
+	secrets.SetEncryptionProvider(p)
+
+	rls := releaseStub("smug-pigeon", 1, "default", common.StatusDeployed)
+	key := testKey(rls.Name, rls.Version)
+	if err := secrets.Create(key, rls); err != nil {
+		t.Fatal(err)
+	}
+
+	obj := secrets.impl.(*MockSecretsInterface).objects[key]
+	if _, err := decodeRelease(string(obj.Data["release"])); err == nil {
+		t.Error("expected the record to be encrypted")
+	}
+	got, err := secrets.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.(*rspb.Release).Name != rls.Name {
+		t.Errorf("expected release %q, got %v", rls.Name, got)
+	}
+}
+
+func TestEncryptionProviderFromEnv(t *testing.T) {
+	p, err := EncryptionProviderFromEnv()
+	if err != nil || p != nil {
+		t.Fatalf("expected no provider, got %v, %v", p, err)
+	}
+
+	t.Setenv("HELM_STORAGE_ENCRYPTION_KEY", "AgICAgICAgICAgICAgICAg==, AQEBAQEBAQEBAQEBAQEBAQ==")
+	if p, err = EncryptionProviderFromEnv(); err != nil || p.Name() != "aes-gcm" {
+		t.Errorf("expected the AES-GCM provider, got %v, %v", p, err)
+	}
+
+	t.Setenv("HELM_STORAGE_ENCRYPTION_COMMAND", "/usr/local/bin/encrypt")
+	if _, err = EncryptionProviderFromEnv(); err == nil {
+		t.Error("expected an error when several providers are configured")
+	}
+
+	t.Setenv("HELM_STORAGE_ENCRYPTION_KEY", "")
+	if p, err = EncryptionProviderFromEnv(); err != nil || p.Name() != "exec" {
+		t.Errorf("expected the exec provider, got %v, %v", p, err)
+	}
+
+	t.Setenv("HELM_STORAGE_ENCRYPTION_COMMAND", "")
+	t.Setenv("HELM_STORAGE_ENCRYPTION_KMS_PLUGIN", "/usr/local/bin/kms")
+	if p, err = EncryptionProviderFromEnv(); err != nil || p.Name() != "kms" {
+		t.Errorf("expected the KMS provider, got %v, %v", p, err)
+	}
+}
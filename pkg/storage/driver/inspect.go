@@ -0,0 +1,179 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package driver // import "helm.sh/helm/v4/pkg/storage/driver"
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+
+	sq "github.com/Masterminds/squirrel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kblabels "k8s.io/apimachinery/pkg/labels"
+
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+)
+
+var (
+	_ RecordLister = (*Secrets)(nil)
+	_ RecordLister = (*ConfigMaps)(nil)
+	_ RecordLister = (*SQL)(nil)
+	_ RecordLister = (*Memory)(nil)
+)
+
+// Record describes how a driver stores a release.
+type Record struct {
+	// Key is the key of the record, such as the name of its Secret.
+	Key string `json:"key"`
+	// Namespace is the namespace of the release.
+	Namespace string `json:"namespace,omitempty"`
+	// Driver is the name of the driver storing the record.
+	Driver string `json:"driver"`
+	// Labels are the labels of the record.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Size is the size of the encoded release in the record, in bytes.
+	Size int `json:"size"`
+	// ReleaseSize is the size of the decoded release, in bytes.
+	ReleaseSize int `json:"releaseSize,omitempty"`
+	// Compressed is set when the release is gzipped.
+	Compressed bool `json:"compressed"`
+	// Encrypted is set when the release is encrypted.
+	Encrypted bool `json:"encrypted"`
+	// Release is the decoded release. It is nil when the record is corrupted.
+	Release *rspb.Release `json:"-"`
+	// Err is the error decoding the release of a corrupted record.
+	Err error `json:"-"`
+}
+
+// CompressionRatio returns the ratio between the size of the decoded release
+// and the size of the record, or 0 when the record is corrupted.
+func (r *Record) CompressionRatio() float64 {
+	if r.Size == 0 || r.ReleaseSize == 0 {
+		return 0
+	}
+	return float64(r.ReleaseSize) / float64(r.Size)
+}
+
+// RecordLister is implemented by the drivers that can list their records,
+// including the records they cannot decode.
+type RecordLister interface {
+	// ListRecords lists the records of the releases of the namespace of the
+	// driver, or of all namespaces.
+	ListRecords() ([]*Record, error)
+}
+
+// inspectRecord decodes the data of a record with the encryption provider.
+func inspectRecord(driver, key, namespace, data string, lbs map[string]string, p EncryptionProvider) *Record {
+	rec := &Record{Key: key, Namespace: namespace, Driver: driver, Labels: lbs}
+	rec.Release, rec.Err = decodeRecord(data, p, rec)
+	return rec
+}
+
+// ListRecords lists the Secrets of the releases.
+func (secrets *Secrets) ListRecords() ([]*Record, error) {
+	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
+	list, err := secrets.impl.List(context.Background(), metav1.ListOptions{LabelSelector: lsel.String()})
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to list: %w", err)
+	}
+	recs := make([]*Record, 0, len(list.Items))
+	for _, item := range list.Items {
+		recs = append(recs, inspectRecord(secrets.Name(), item.Name, item.Namespace, string(item.Data["release"]), item.Labels, secrets.EncryptionProvider()))
+	}
+	return recs, nil
+}
+
+// ListRecords lists the ConfigMaps of the releases.
+func (cfgmaps *ConfigMaps) ListRecords() ([]*Record, error) {
+	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
+	list, err := cfgmaps.impl.List(context.Background(), metav1.ListOptions{LabelSelector: lsel.String()})
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to list: %w", err)
+	}
+	recs := make([]*Record, 0, len(list.Items))
+	for _, item := range list.Items {
+		recs = append(recs, inspectRecord(cfgmaps.Name(), item.Name, item.Namespace, item.Data["release"], item.Labels, cfgmaps.EncryptionProvider()))
+	}
+	return recs, nil
+}
+
+// ListRecords lists the records of the releases of the memory driver. As the
+// releases are not encoded in memory, the records describe the releases as
+// the Secrets driver would store them.
+func (mem *Memory) ListRecords() ([]*Record, error) {
+	defer unlock(mem.rlock())
+
+	var recs []*Record
+	for namespace, rels := range mem.cache {
+		if mem.namespace != "" && namespace != mem.namespace {
+			continue
+		}
+		for _, rs := range rels {
+			rs.Iter(func(_ int, r *record) bool {
+				data, err := encodeRelease(r.rls)
+				rec := inspectRecord(mem.Name(), r.key, namespace, data, maps.Clone(r.lbs), nil)
+				if err != nil {
+					rec.Err = err
+				}
+				recs = append(recs, rec)
+				return true
+			})
+		}
+	}
+	return recs, nil
+}
+
+// ListRecords lists the rows of the releases.
+func (s *SQL) ListRecords() ([]*Record, error) {
+	sb := s.statementBuilder.
+		Select(sqlReleaseTableKeyColumn, sqlReleaseTableNamespaceColumn, sqlReleaseTableBodyColumn,
+			sqlReleaseTableNameColumn, sqlReleaseTableVersionColumn, sqlReleaseTableStatusColumn, sqlReleaseTableOwnerColumn).
+		From(sqlReleaseTableName).
+		Where(sq.Eq{sqlReleaseTableOwnerColumn: sqlReleaseDefaultOwner})
+	if s.namespace != "" {
+		sb = sb.Where(sq.Eq{sqlReleaseTableNamespaceColumn: s.namespace})
+	}
+	query, args, err := sb.ToSql()
+	if err != nil {
+		s.Logger().Debug("failed to build query", slog.Any("error", err))
+		return nil, err
+	}
+
+	var rows = []SQLReleaseWrapper{}
+	if err := s.db.Select(&rows, query, args...); err != nil {
+		s.Logger().Debug("failed to list", slog.Any("error", err))
+		return nil, err
+	}
+
+	recs := make([]*Record, 0, len(rows))
+	for _, row := range rows {
+		lbs, err := s.getReleaseCustomLabels(row.Key, row.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if lbs == nil {
+			lbs = map[string]string{}
+		}
+		lbs["name"] = row.Name
+		lbs["owner"] = row.Owner
+		lbs["status"] = row.Status
+		lbs["version"] = strconv.Itoa(row.Version)
+		recs = append(recs, inspectRecord(s.Name(), row.Key, row.Namespace, row.Body, lbs, s.EncryptionProvider()))
+	}
+	return recs, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestSecretListRecords(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", common.StatusDeployed)
+	secrets := newTestFixtureSecrets(t, rel)
+
+	p, err := NewAESGCMProvider(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets.SetEncryptionProvider(p)
+	encrypted := releaseStub("smug-pigeon", 2, "default", common.StatusDeployed)
+	if err := secrets.Create(testKey(encrypted.Name, encrypted.Version), encrypted); err != nil {
+		t.Fatalf("Failed to create release: %s", err)
+	}
+
+	corrupted := releaseStub("smug-pigeon", 3, "default", common.StatusDeployed)
+	if err := secrets.Create(testKey(corrupted.Name, corrupted.Version), corrupted); err != nil {
+		t.Fatalf("Failed to create release: %s", err)
+	}
+	mock := secrets.impl.(*MockSecretsInterface)
+	mock.objects[testKey(corrupted.Name, corrupted.Version)].Data["release"] = []byte("not a release")
+
+	recs, err := secrets.ListRecords()
+	if err != nil {
+		t.Fatalf("Failed to list records: %s", err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(recs))
+	}
+
+	byKey := map[string]*Record{}
+	for _, r := range recs {
+		byKey[r.Key] = r
+		if r.Driver != SecretsDriverName {
+			t.Errorf("Expected driver %q, got %q", SecretsDriverName, r.Driver)
+		}
+	}
+
+	plain := byKey[testKey(rel.Name, 1)]
+	if plain.Err != nil {
+		t.Fatalf("Expected the record to decode, got %s", plain.Err)
+	}
+	if plain.Encrypted || !plain.Compressed {
+		t.Errorf("Expected a compressed, unencrypted record, got %+v", plain)
+	}
+	if plain.CompressionRatio() == 0 {
+		t.Errorf("Expected a compression ratio, got %f", plain.CompressionRatio())
+	}
+	if plain.Release.Name != rel.Name || plain.Labels["version"] != "1" {
+		t.Errorf("Unexpected record %+v", plain)
+	}
+
+	enc := byKey[testKey(rel.Name, 2)]
+	if enc.Err != nil {
+		t.Fatalf("Expected the record to decode, got %s", enc.Err)
+	}
+	if !enc.Encrypted {
+		t.Error("Expected the record to be encrypted")
+	}
+
+	bad := byKey[testKey(rel.Name, 3)]
+	if bad.Err == nil || bad.Release != nil {
+		t.Errorf("Expected the record to be corrupted, got %+v", bad)
+	}
+	if bad.CompressionRatio() != 0 {
+		t.Errorf("Expected no compression ratio, got %f", bad.CompressionRatio())
+	}
+}
+
+func TestMemoryListRecords(t *testing.T) {
+	mem := NewMemory()
+	rel := releaseStub("smug-pigeon", 1, "default", common.StatusDeployed)
+	if err := mem.Create(testKey(rel.Name, rel.Version), rel); err != nil {
+		t.Fatalf("Failed to create release: %s", err)
+	}
+
+	recs, err := mem.ListRecords()
+	if err != nil {
+		t.Fatalf("Failed to list records: %s", err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(recs))
+	}
+	r := recs[0]
+	if r.Err != nil || r.Release.Name != rel.Name || r.Namespace != "default" || r.Size == 0 {
+		t.Errorf("Unexpected record %+v", r)
+	}
+}
@@ -107,7 +107,7 @@ func (mock *MockConfigMapsInterface) Init(t *testing.T, releases ...*rspb.Releas
 	for _, rls := range releases {
 		objkey := testKey(rls.Name, rls.Version)
 
-		cfgmap, err := newConfigMapsObject(objkey, rls, nil)
+		cfgmap, err := newConfigMapsObject(objkey, rls, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to create configmap: %s", err)
 		}
@@ -195,7 +195,7 @@ func (mock *MockSecretsInterface) Init(t *testing.T, releases ...*rspb.Release)
 	for _, rls := range releases {
 		objkey := testKey(rls.Name, rls.Version)
 
-		secret, err := newSecretsObject(objkey, rls, nil)
+		secret, err := newSecretsObject(objkey, rls, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to create secret: %s", err)
 		}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"strconv"
 	"strings"
 	"time"
@@ -47,6 +48,8 @@ type Secrets struct {
 	impl corev1.SecretInterface
 	// Embed a LogHolder to provide logger functionality
 	logging.LogHolder
+	// Embed an EncryptionHolder to encrypt the stored releases
+	EncryptionHolder
 }
 
 // NewSecrets initializes a new Secrets wrapping an implementation of
@@ -76,7 +79,7 @@ func (secrets *Secrets) Get(key string) (release.Releaser, error) {
 		return nil, fmt.Errorf("get: failed to get %q: %w", key, err)
 	}
 	// found the secret, decode the base64 data string
-	r, err := decodeRelease(string(obj.Data["release"]))
+	r, err := secrets.decodeRelease(string(obj.Data["release"]))
 	if err != nil {
 		return r, fmt.Errorf("get: failed to decode data %q: %w", key, err)
 	}
@@ -101,7 +104,7 @@ func (secrets *Secrets) List(filter func(release.Releaser) bool) ([]release.Rele
 	// iterate over the secrets object list
 	// and decode each release
 	for _, item := range list.Items {
-		rls, err := decodeRelease(string(item.Data["release"]))
+		rls, err := secrets.decodeRelease(string(item.Data["release"]))
 		if err != nil {
 			secrets.Logger().Debug(
 				"list failed to decode release", slog.String("key", item.Name),
@@ -143,7 +146,7 @@ func (secrets *Secrets) Query(labels map[string]string) ([]release.Releaser, err
 
 	var results []release.Releaser
 	for _, item := range list.Items {
-		rls, err := decodeRelease(string(item.Data["release"]))
+		rls, err := secrets.decodeRelease(string(item.Data["release"]))
 		if err != nil {
 			secrets.Logger().Debug(
 				"failed to decode release",
@@ -174,7 +177,7 @@ func (secrets *Secrets) Create(key string, rel release.Releaser) error {
 	lbs.set("createdAt", strconv.FormatInt(time.Now().Unix(), 10))
 
 	// create a new secret to hold the release
-	obj, err := newSecretsObject(key, rls, lbs)
+	obj, err := newSecretsObject(key, rls, lbs, secrets.EncryptionProvider())
 	if err != nil {
 		return fmt.Errorf("create: failed to encode release %q: %w", rls.Name, err)
 	}
@@ -205,7 +208,7 @@ func (secrets *Secrets) Update(key string, rel release.Releaser) error {
 	lbs.set("modifiedAt", strconv.FormatInt(time.Now().Unix(), 10))
 
 	// create a new secret object to hold the release
-	obj, err := newSecretsObject(key, rls, lbs)
+	obj, err := newSecretsObject(key, rls, lbs, secrets.EncryptionProvider())
 	if err != nil {
 		return fmt.Errorf("update: failed to encode release %q: %w", rls.Name, err)
 	}
@@ -243,11 +246,11 @@ func (secrets *Secrets) Delete(key string) (rls release.Releaser, err error) {
 //	"status"         - status of the release (see pkg/release/status.go for variants)
 //	"owner"          - owner of the secret, currently "helm".
 //	"name"           - name of the release.
-func newSecretsObject(key string, rls *rspb.Release, lbs labels) (*v1.Secret, error) {
+func newSecretsObject(key string, rls *rspb.Release, lbs labels, enc EncryptionProvider) (*v1.Secret, error) {
 	const owner = "helm"
 
 	// encode the release
-	s, err := encodeRelease(rls)
+	s, err := encodeEncryptedRelease(rls, enc)
 	if err != nil {
 		return nil, err
 	}
@@ -278,8 +281,9 @@ func newSecretsObject(key string, rls *rspb.Release, lbs labels) (*v1.Secret, er
 	// and should only happen between major versions.
 	return &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   key,
-			Labels: lbs.toMap(),
+			Name:        key,
+			Labels:      lbs.toMap(),
+			Annotations: maps.Clone(rls.Annotations),
 		},
 		Type: "helm.sh/release.v1",
 		Data: map[string][]byte{"release": []byte(s)},
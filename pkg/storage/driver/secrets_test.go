@@ -62,7 +62,7 @@ func TestUNcompressedSecretGet(t *testing.T) {
 	rel := releaseStub(name, vers, namespace, common.StatusDeployed)
 
 	// Create a test fixture which contains an uncompressed release
-	secret, err := newSecretsObject(key, rel, nil)
+	secret, err := newSecretsObject(key, rel, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to create secret: %s", err)
 	}
@@ -196,6 +196,32 @@ func TestSecretCreate(t *testing.T) {
 	}
 }
 
+func TestSecretAnnotations(t *testing.T) {
+	secrets := newTestFixtureSecrets(t)
+
+	key := testKey("smug-pigeon", 1)
+	rel := releaseStub("smug-pigeon", 1, "default", common.StatusDeployed)
+	rel.Annotations = map[string]string{"example.com/team": "payments"}
+	if err := secrets.Create(key, rel); err != nil {
+		t.Fatalf("Failed to create release with key %q: %s", key, err)
+	}
+
+	obj := secrets.impl.(*MockSecretsInterface).objects[key]
+	if !reflect.DeepEqual(rel.Annotations, obj.Annotations) {
+		t.Errorf("Expected Secret annotations %v, got %v", rel.Annotations, obj.Annotations)
+	}
+
+	// The annotations added by other tools are not read back.
+	obj.Annotations["example.com/other-tool"] = "true"
+	got, err := secrets.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release with key %q: %s", key, err)
+	}
+	if !reflect.DeepEqual(rel.Annotations, got.(*rspb.Release).Annotations) {
+		t.Errorf("Expected annotations %v, got %v", rel.Annotations, got.(*rspb.Release).Annotations)
+	}
+}
+
 func TestSecretUpdate(t *testing.T) {
 	vers := 1
 	name := "smug-pigeon"
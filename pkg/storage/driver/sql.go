@@ -92,6 +92,8 @@ type SQL struct {
 	statementBuilder sq.StatementBuilderType
 	// Embed a LogHolder to provide logger functionality
 	logging.LogHolder
+	// Embed an EncryptionHolder to encrypt the stored releases
+	EncryptionHolder
 }
 
 // Name returns the name of the driver.
@@ -322,7 +324,7 @@ func (s *SQL) Get(key string) (release.Releaser, error) {
 		return nil, ErrReleaseNotFound
 	}
 
-	release, err := decodeRelease(record.Body)
+	release, err := s.decodeRelease(record.Body)
 	if err != nil {
 		s.Logger().Debug("failed to decode data", slog.String("key", key), slog.Any("error", err))
 		return nil, err
@@ -367,7 +369,7 @@ func (s *SQL) List(filter func(release.Releaser) bool) ([]release.Releaser, erro
 
 	var releases []release.Releaser
 	for _, record := range records {
-		release, err := decodeRelease(record.Body)
+		release, err := s.decodeRelease(record.Body)
 		if err != nil {
 			s.Logger().Debug("failed to decode release", slog.Any("record", record), slog.Any("error", err))
 			continue
@@ -436,7 +438,7 @@ func (s *SQL) Query(labels map[string]string) ([]release.Releaser, error) {
 
 	var releases []release.Releaser
 	for _, record := range records {
-		release, err := decodeRelease(record.Body)
+		release, err := s.decodeRelease(record.Body)
 		if err != nil {
 			s.Logger().Debug("failed to decode release", slog.Any("record", record), slog.Any("error", err))
 			continue
@@ -475,7 +477,7 @@ func (s *SQL) Create(key string, rel release.Releaser) error {
 	}
 	s.namespace = namespace
 
-	body, err := encodeRelease(rls)
+	body, err := s.encodeRelease(rls)
 	if err != nil {
 		s.Logger().Debug("failed to encode release", slog.Any("error", err))
 		return err
@@ -586,7 +588,7 @@ func (s *SQL) Update(key string, rel release.Releaser) error {
 	}
 	s.namespace = namespace
 
-	body, err := encodeRelease(rls)
+	body, err := s.encodeRelease(rls)
 	if err != nil {
 		s.Logger().Debug("failed to encode release", slog.Any("error", err))
 		return err
@@ -643,7 +645,7 @@ func (s *SQL) Delete(key string) (release.Releaser, error) {
 		return nil, ErrReleaseNotFound
 	}
 
-	release, err := decodeRelease(record.Body)
+	release, err := s.decodeRelease(record.Body)
 	if err != nil {
 		s.Logger().Debug("failed to decode release", slog.String("key", key), slog.Any("error", err))
 		transaction.Rollback()
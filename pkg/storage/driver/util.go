@@ -21,6 +21,8 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"slices"
 
@@ -31,11 +33,21 @@ var b64 = base64.StdEncoding
 
 var magicGzip = []byte{0x1f, 0x8b, 0x08}
 
+// magicEncrypted prefixes the encrypted records. It is followed by the name of
+// the encryption provider, a NUL byte and the encrypted gzipped release.
+var magicEncrypted = []byte("helm-encrypted:")
+
 var systemLabels = []string{"name", "owner", "status", "version", "createdAt", "modifiedAt"}
 
 // encodeRelease encodes a release returning a base64 encoded
 // gzipped string representation, or error.
 func encodeRelease(rls *rspb.Release) (string, error) {
+	return encodeEncryptedRelease(rls, nil)
+}
+
+// encodeEncryptedRelease encodes a release like encodeRelease, encrypting the
+// gzipped release with the provider if it is not nil.
+func encodeEncryptedRelease(rls *rspb.Release, p EncryptionProvider) (string, error) {
 	b, err := json.Marshal(rls)
 	if err != nil {
 		return "", err
@@ -53,23 +65,67 @@ func encodeRelease(rls *rspb.Release) (string, error) {
 		return "", err
 	}
 
-	return b64.EncodeToString(buf.Bytes()), nil
+	if p == nil {
+		return b64.EncodeToString(buf.Bytes()), nil
+	}
+	encrypted, err := p.Encrypt(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("unable to encrypt release %q: %w", rls.Name, err)
+	}
+	out := append(append(append([]byte{}, magicEncrypted...), p.Name()...), 0)
+	return b64.EncodeToString(append(out, encrypted...)), nil
 }
 
 // decodeRelease decodes the bytes of data into a release
 // type. Data must contain a base64 encoded gzipped string of a
 // valid release, otherwise an error is returned.
 func decodeRelease(data string) (*rspb.Release, error) {
+	return decodeEncryptedRelease(data, nil)
+}
+
+// decodeEncryptedRelease decodes a release like decodeRelease. Encrypted
+// releases are decrypted with the provider, which must be the provider that
+// encrypted them.
+func decodeEncryptedRelease(data string, p EncryptionProvider) (*rspb.Release, error) {
+	return decodeRecord(data, p, nil)
+}
+
+// decodeRecord decodes a release like decodeEncryptedRelease, filling the
+// sizes and encoding of the record in rec when it is not nil.
+func decodeRecord(data string, p EncryptionProvider, rec *Record) (*rspb.Release, error) {
+	if rec == nil {
+		rec = &Record{}
+	}
+	rec.Size = len(data)
+
 	// base64 decode string
 	b, err := b64.DecodeString(data)
 	if err != nil {
 		return nil, err
 	}
 
+	if bytes.HasPrefix(b, magicEncrypted) {
+		rec.Encrypted = true
+		name, encrypted, ok := bytes.Cut(b[len(magicEncrypted):], []byte{0})
+		if !ok {
+			return nil, errors.New("invalid encrypted release")
+		}
+		if p == nil {
+			return nil, fmt.Errorf("release is encrypted by the %q encryption provider, but no encryption provider is configured", name)
+		}
+		if string(name) != p.Name() {
+			return nil, fmt.Errorf("release is encrypted by the %q encryption provider, not %q", name, p.Name())
+		}
+		if b, err = p.Decrypt(encrypted); err != nil {
+			return nil, fmt.Errorf("unable to decrypt release: %w", err)
+		}
+	}
+
 	// For backwards compatibility with releases that were stored before
 	// compression was introduced we skip decompression if the
 	// gzip magic header is not found
 	if len(b) > 3 && bytes.Equal(b[0:3], magicGzip) {
+		rec.Compressed = true
 		r, err := gzip.NewReader(bytes.NewReader(b))
 		if err != nil {
 			return nil, err
@@ -81,6 +137,7 @@ func decodeRelease(data string) (*rspb.Release, error) {
 		}
 		b = b2
 	}
+	rec.ReleaseSize = len(b)
 
 	var rls rspb.Release
 	// unmarshal release object bytes
@@ -90,6 +147,16 @@ func decodeRelease(data string) (*rspb.Release, error) {
 	return &rls, nil
 }
 
+// encodeRelease encodes a release with the encryption provider of the driver.
+func (h *EncryptionHolder) encodeRelease(rls *rspb.Release) (string, error) {
+	return encodeEncryptedRelease(rls, h.provider)
+}
+
+// decodeRelease decodes a release with the encryption provider of the driver.
+func (h *EncryptionHolder) decodeRelease(data string) (*rspb.Release, error) {
+	return decodeEncryptedRelease(data, h.provider)
+}
+
 // Checks if label is system
 func isSystemLabel(key string) bool {
 	return slices.Contains(GetSystemLabels(), key)
@@ -0,0 +1,159 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+
+	"helm.sh/helm/v4/pkg/release"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// Migration copies the release records of a driver to another driver, for
+// instance from the Secrets driver to the SQL driver. The releases are copied
+// as decoded by the source driver, so the destination driver encodes them with
+// its own encryption settings.
+type Migration struct {
+	// From is the driver the records are copied from.
+	From driver.Driver
+	// To is the driver the records are copied to.
+	To driver.Driver
+	// Overwrite replaces the records that already exist in To. Otherwise they
+	// are skipped.
+	Overwrite bool
+	// Verify reads back every copied release from To and checks that it matches
+	// the release of From.
+	Verify bool
+	// DeleteSource deletes the records from From once they are copied, and
+	// verified when Verify is set.
+	DeleteSource bool
+	// DryRun lists the releases to migrate without copying them.
+	DryRun bool
+}
+
+// MigratedRelease reports the migration of a release record.
+type MigratedRelease struct {
+	Release release.Releaser
+	// Skipped is set when the record already exists in the destination driver
+	// and Overwrite is not set.
+	Skipped bool
+}
+
+// Run copies the records. It fails before copying anything if a record of the
+// source driver cannot be decoded, and otherwise stops at the first record
+// that cannot be copied and returns the records migrated so far along with the
+// error.
+func (m *Migration) Run() ([]MigratedRelease, error) {
+	if m.From == nil || m.To == nil {
+		return nil, errors.New("migration requires a source and a destination driver")
+	}
+	ls, err := m.list()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the releases of the %s driver: %w", m.From.Name(), err)
+	}
+	rls, err := releaseListToV1List(ls)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []MigratedRelease
+	for _, rel := range rls {
+		key := makeKey(rel.Name, rel.Version)
+		_, err := m.To.Get(key)
+		exists := err == nil
+		if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+			return migrated, fmt.Errorf("unable to check release %q in the %s driver: %w", key, m.To.Name(), err)
+		}
+		if exists && !m.Overwrite {
+			migrated = append(migrated, MigratedRelease{Release: rel, Skipped: true})
+			continue
+		}
+		if !m.DryRun {
+			if exists {
+				err = m.To.Update(key, rel)
+			} else {
+				err = m.To.Create(key, rel)
+			}
+			if err != nil {
+				return migrated, fmt.Errorf("unable to copy release %q to the %s driver: %w", key, m.To.Name(), err)
+			}
+			if m.Verify {
+				if err := m.verify(key, rel); err != nil {
+					return migrated, err
+				}
+			}
+			if m.DeleteSource {
+				if _, err := m.From.Delete(key); err != nil {
+					return migrated, fmt.Errorf("unable to delete release %q from the %s driver: %w", key, m.From.Name(), err)
+				}
+			}
+		}
+		migrated = append(migrated, MigratedRelease{Release: rel})
+	}
+	return migrated, nil
+}
+
+// list returns every release of the source driver. As List skips the records
+// it cannot decode, the records of drivers that can list them are checked
+// first, so that no record is left behind silently.
+func (m *Migration) list() ([]release.Releaser, error) {
+	if rl, ok := m.From.(driver.RecordLister); ok {
+		recs, err := rl.ListRecords()
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		for _, rec := range recs {
+			if rec.Err != nil {
+				errs = append(errs, fmt.Errorf("failed to decode record %q: %w", rec.Key, rec.Err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+	}
+	return m.From.List(func(release.Releaser) bool { return true })
+}
+
+// verify checks that the release stored under key in the destination driver
+// matches rel.
+func (m *Migration) verify(key string, rel *rspb.Release) error {
+	got, err := m.To.Get(key)
+	if err != nil {
+		return fmt.Errorf("unable to verify release %q in the %s driver: %w", key, m.To.Name(), err)
+	}
+	copied, err := releaserToV1Release(got)
+	if err != nil {
+		return err
+	}
+	want, err := json.Marshal(rel)
+	if err != nil {
+		return err
+	}
+	have, err := json.Marshal(copied)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(want, have) || !maps.Equal(rel.Labels, copied.Labels) {
+		return fmt.Errorf("release %q in the %s driver does not match the release in the %s driver", key, m.To.Name(), m.From.Name())
+	}
+	return nil
+}
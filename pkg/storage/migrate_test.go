@@ -0,0 +1,181 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/release/common"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestMigration(t *testing.T) {
+	newDrivers := func(t *testing.T) (driver.Driver, driver.Driver) {
+		t.Helper()
+		from, to := driver.NewMemory(), driver.NewMemory()
+		for _, rls := range []*rspb.Release{
+			ReleaseTestData{Name: "angry-beaver", Version: 1, Status: common.StatusSuperseded}.ToRelease(),
+			ReleaseTestData{Name: "angry-beaver", Version: 2, Status: common.StatusDeployed}.ToRelease(),
+		} {
+			require.NoError(t, from.Create(makeKey(rls.Name, rls.Version), rls))
+		}
+		existing := ReleaseTestData{Name: "angry-beaver", Version: 1, Status: common.StatusFailed}.ToRelease()
+		require.NoError(t, to.Create(makeKey(existing.Name, existing.Version), existing))
+		return from, to
+	}
+	statusOf := func(t *testing.T, d driver.Driver, version int) common.Status {
+		t.Helper()
+		r, err := d.Get(makeKey("angry-beaver", version))
+		require.NoError(t, err)
+		return r.(*rspb.Release).Info.Status
+	}
+
+	tests := []struct {
+		name         string
+		overwrite    bool
+		deleteSource bool
+		dryRun       bool
+		wantSkipped  int
+		check        func(t *testing.T, from, to driver.Driver)
+	}{{
+		name:        "skips the existing releases",
+		wantSkipped: 1,
+		check: func(t *testing.T, from, to driver.Driver) {
+			t.Helper()
+			assert.Equal(t, common.StatusFailed, statusOf(t, to, 1))
+			assert.Equal(t, common.StatusDeployed, statusOf(t, to, 2))
+			assert.Equal(t, common.StatusDeployed, statusOf(t, from, 2))
+		},
+	}, {
+		name:      "overwrites the existing releases",
+		overwrite: true,
+		check: func(t *testing.T, _, to driver.Driver) {
+			t.Helper()
+			assert.Equal(t, common.StatusSuperseded, statusOf(t, to, 1))
+			assert.Equal(t, common.StatusDeployed, statusOf(t, to, 2))
+		},
+	}, {
+		name:         "deletes the migrated releases from the source",
+		deleteSource: true,
+		wantSkipped:  1,
+		check: func(t *testing.T, from, _ driver.Driver) {
+			t.Helper()
+			assert.Equal(t, common.StatusSuperseded, statusOf(t, from, 1))
+			_, err := from.Get(makeKey("angry-beaver", 2))
+			assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+		},
+	}, {
+		name:         "dry run copies nothing",
+		overwrite:    true,
+		deleteSource: true,
+		dryRun:       true,
+		check: func(t *testing.T, from, to driver.Driver) {
+			t.Helper()
+			assert.Equal(t, common.StatusFailed, statusOf(t, to, 1))
+			_, err := to.Get(makeKey("angry-beaver", 2))
+			assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+			assert.Equal(t, common.StatusDeployed, statusOf(t, from, 2))
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := newDrivers(t)
+			m := &Migration{From: from, To: to, Overwrite: tt.overwrite, DeleteSource: tt.deleteSource, DryRun: tt.dryRun}
+			migrated, err := m.Run()
+			require.NoError(t, err)
+			assert.Len(t, migrated, 2)
+			skipped := 0
+			for _, mr := range migrated {
+				if mr.Skipped {
+					skipped++
+				}
+			}
+			assert.Equal(t, tt.wantSkipped, skipped)
+			tt.check(t, from, to)
+		})
+	}
+}
+
+// tamperingDriver returns releases that differ from the stored ones.
+type tamperingDriver struct {
+	driver.Driver
+}
+
+func (d tamperingDriver) Get(key string) (release.Releaser, error) {
+	r, err := d.Driver.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	rls := *r.(*rspb.Release)
+	rls.Manifest = "tampered"
+	return &rls, nil
+}
+
+func TestMigrationVerify(t *testing.T) {
+	from := driver.NewMemory()
+	rls := ReleaseTestData{Name: "angry-beaver", Version: 1, Status: common.StatusDeployed}.ToRelease()
+	key := makeKey(rls.Name, rls.Version)
+	require.NoError(t, from.Create(key, rls))
+
+	m := &Migration{From: from, To: driver.NewMemory(), Verify: true, DeleteSource: true}
+	migrated, err := m.Run()
+	require.NoError(t, err)
+	assert.Len(t, migrated, 1)
+
+	require.NoError(t, from.Create(key, rls))
+	m = &Migration{From: from, To: tamperingDriver{driver.NewMemory()}, Verify: true, DeleteSource: true}
+	migrated, err = m.Run()
+	assert.ErrorContains(t, err, "does not match")
+	assert.Empty(t, migrated)
+	_, err = from.Get(key)
+	assert.NoError(t, err, "the source record must be kept when the verification fails")
+}
+
+// undecodableDriver lists a record that cannot be decoded.
+type undecodableDriver struct {
+	driver.Driver
+}
+
+func (d undecodableDriver) ListRecords() ([]*driver.Record, error) {
+	return []*driver.Record{{Key: "sh.helm.release.v1.broken.v1", Err: errors.New("illegal base64 data")}}, nil
+}
+
+func TestMigrationUndecodableRecord(t *testing.T) {
+	from := driver.NewMemory()
+	rls := ReleaseTestData{Name: "angry-beaver", Version: 1, Status: common.StatusDeployed}.ToRelease()
+	require.NoError(t, from.Create(makeKey(rls.Name, rls.Version), rls))
+
+	to := driver.NewMemory()
+	m := &Migration{From: undecodableDriver{from}, To: to, DeleteSource: true}
+	migrated, err := m.Run()
+	assert.ErrorContains(t, err, "sh.helm.release.v1.broken.v1")
+	assert.Empty(t, migrated)
+	_, err = to.Get(makeKey(rls.Name, rls.Version))
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound, "nothing must be copied when a source record cannot be decoded")
+}
+
+func TestMigrationRequiresDrivers(t *testing.T) {
+	_, err := (&Migration{From: driver.NewMemory()}).Run()
+	assert.Error(t, err)
+}
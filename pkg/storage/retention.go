@@ -0,0 +1,84 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"errors"
+	"time"
+
+	"helm.sh/helm/v4/pkg/release"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	relutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// RetentionHook returns the retention enforced when a revision of a release
+// is created. It receives the retention requested for the release, either
+// recorded on it or configured on the Storage, and lets operators enforce
+// cluster-wide defaults and limits, e.g. by capping MaxHistory.
+type RetentionHook func(rls release.Releaser, requested rspb.Retention) rspb.Retention
+
+// retention returns the retention to enforce when creating rls.
+func (s *Storage) retention(rls release.Releaser) rspb.Retention {
+	policy := rspb.Retention{MaxHistory: s.MaxHistory, MaxAge: s.MaxHistoryAge}
+	if r, ok := rls.(*rspb.Release); ok && r.Retention != nil {
+		policy = *r.Retention
+	}
+	if s.RetentionHook != nil {
+		policy = s.RetentionHook(rls, policy)
+	}
+	return policy
+}
+
+// removeOlderThan removes the revisions of a release last deployed more than
+// maxAge ago. The newest and the deployed revisions are kept.
+func (s *Storage) removeOlderThan(name string, maxAge time.Duration) error {
+	h, err := s.History(name)
+	if err != nil {
+		return err
+	}
+	rls, err := releaseListToV1List(h)
+	if err != nil {
+		return err
+	}
+	relutil.SortByRevision(rls)
+
+	deployed := -1
+	if ld, err := s.Deployed(name); err == nil {
+		if ldac, err := release.NewAccessor(ld); err == nil {
+			deployed = ldac.Version()
+		}
+	} else if !errors.Is(err, driver.ErrNoDeployedReleases) {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var errs []error
+	removed := 0
+	for _, rel := range rls[:len(rls)-1] {
+		if rel.Version == deployed || rel.Info == nil || rel.Info.LastDeployed.IsZero() || !rel.Info.LastDeployed.Before(cutoff) {
+			continue
+		}
+		if err := s.deleteReleaseVersion(name, rel.Version); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	s.Logger().Debug("pruned records older than the maximum age", "count", removed, "release", name, "maxAge", maxAge, "errors", len(errs))
+	return errors.Join(errs...)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/release/common"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestStorageRetention(t *testing.T) {
+	const name = "angry-bird"
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		storage    func(*Storage)
+		retention  *rspb.Retention
+		deployedAt []time.Duration
+		statuses   []common.Status
+		want       []int
+	}{
+		{
+			name:    "storage max history",
+			storage: func(s *Storage) { s.MaxHistory = 2 },
+			want:    []int{4, 5},
+		},
+		{
+			name:      "release max history overrides the storage",
+			storage:   func(s *Storage) { s.MaxHistory = 2 },
+			retention: &rspb.Retention{MaxHistory: 3},
+			want:      []int{3, 4, 5},
+		},
+		{
+			name:       "max age keeps the newest and the deployed revisions",
+			retention:  &rspb.Retention{MaxAge: 24 * time.Hour},
+			deployedAt: []time.Duration{72 * time.Hour, 48 * time.Hour, 36 * time.Hour, time.Hour, 0},
+			statuses:   []common.Status{common.StatusSuperseded, common.StatusDeployed, common.StatusFailed, common.StatusFailed, common.StatusFailed},
+			want:       []int{2, 4, 5},
+		},
+		{
+			name:       "storage max age",
+			storage:    func(s *Storage) { s.MaxHistoryAge = 24 * time.Hour },
+			deployedAt: []time.Duration{72 * time.Hour, 48 * time.Hour, 36 * time.Hour, 48 * time.Hour, 0},
+			want:       []int{4, 5},
+		},
+		{
+			name: "hook caps the requested max history",
+			storage: func(s *Storage) {
+				s.RetentionHook = func(_ release.Releaser, requested rspb.Retention) rspb.Retention {
+					if requested.MaxHistory == 0 || requested.MaxHistory > 2 {
+						requested.MaxHistory = 2
+					}
+					return requested
+				}
+			},
+			retention: &rspb.Retention{MaxHistory: 10},
+			want:      []int{4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Init(driver.NewMemory())
+			if tt.storage != nil {
+				tt.storage(s)
+			}
+			for i := range 5 {
+				status := common.StatusSuperseded
+				if tt.statuses != nil {
+					status = tt.statuses[i]
+				}
+				rls := ReleaseTestData{Name: name, Version: i + 1, Status: status}.ToRelease()
+				rls.Retention = tt.retention
+				if tt.deployedAt != nil {
+					rls.Info.LastDeployed = now.Add(-tt.deployedAt[i])
+				}
+				require.NoError(t, s.Create(rls))
+			}
+
+			hist, err := s.History(name)
+			require.NoError(t, err)
+			rhist, err := releaseListToV1List(hist)
+			require.NoError(t, err)
+			var versions []int
+			for _, rls := range rhist {
+				versions = append(versions, rls.Version)
+			}
+			slices.Sort(versions)
+			assert.Equal(t, tt.want, versions)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/release"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// KeyProvider encrypts and decrypts the data keys used to encrypt the values
+// of releases that the chart schemas mark as sensitive (see
+// util.SensitiveSchemaKey).
+type KeyProvider interface {
+	// Name identifies the provider in the release records.
+	Name() string
+	// EncryptKey encrypts a data key.
+	EncryptKey(key []byte) ([]byte, error)
+	// DecryptKey decrypts a data key encrypted by EncryptKey.
+	DecryptKey(encrypted []byte) ([]byte, error)
+}
+
+// KeyProviderFromEnv returns the key provider configured by the environment:
+//
+//   - HELM_SENSITIVE_VALUES_KEY holds a base64 encoded AES key of 16, 24 or
+//     32 bytes, see NewLocalKeyProvider.
+//   - HELM_SENSITIVE_VALUES_KMS_PLUGIN holds the path of a KMS plugin, see
+//     NewPluginKeyProvider.
+//
+// It returns nil when neither is set.
+func KeyProviderFromEnv() (KeyProvider, error) {
+	key, plugin := os.Getenv("HELM_SENSITIVE_VALUES_KEY"), os.Getenv("HELM_SENSITIVE_VALUES_KMS_PLUGIN")
+	switch {
+	case key != "" && plugin != "":
+		return nil, errors.New("HELM_SENSITIVE_VALUES_KEY and HELM_SENSITIVE_VALUES_KMS_PLUGIN are mutually exclusive")
+	case key != "":
+		b, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HELM_SENSITIVE_VALUES_KEY: %w", err)
+		}
+		return NewLocalKeyProvider(b)
+	case plugin != "":
+		return NewPluginKeyProvider(plugin), nil
+	}
+	return nil, nil
+}
+
+// keyProvider adapts an encryption provider of the drivers to a KeyProvider.
+type keyProvider struct {
+	name string
+	p    driver.EncryptionProvider
+}
+
+// NewLocalKeyProvider returns a key provider that encrypts the data keys with
+// a local AES key of 16, 24 or 32 bytes, using AES-GCM.
+func NewLocalKeyProvider(key []byte) (KeyProvider, error) {
+	p, err := driver.NewAESGCMProvider(key)
+	if err != nil {
+		return nil, err
+	}
+	return &keyProvider{name: "local", p: p}, nil
+}
+
+// NewPluginKeyProvider returns a key provider that delegates the encryption of
+// the data keys to a KMS plugin. The plugin is an executable run as
+// "<command> encrypt" or "<command> decrypt", which reads a key on its
+// standard input and writes the encrypted or decrypted key on its standard
+// output.
+func NewPluginKeyProvider(command string) KeyProvider {
+	return &keyProvider{name: "kms", p: driver.NewExecProvider(command)}
+}
+
+func (p *keyProvider) Name() string { return p.name }
+
+func (p *keyProvider) EncryptKey(key []byte) ([]byte, error) {
+	return p.p.Encrypt(key)
+}
+
+func (p *keyProvider) DecryptKey(encrypted []byte) ([]byte, error) {
+	return p.p.Decrypt(encrypted)
+}
+
+// encryptSensitiveValues returns a copy of the release with the sensitive
+// values of its config moved to SensitiveValues, encrypted. The release is
+// returned as is when it has no sensitive values.
+func (s *Storage) encryptSensitiveValues(rls release.Releaser) (release.Releaser, error) {
+	rel, err := releaserToV1Release(rls)
+	if err != nil || rel == nil || rel.Chart == nil || len(rel.Config) == 0 {
+		return rls, nil
+	}
+	paths, err := util.SensitivePaths(rel.Chart)
+	if err != nil || len(paths) == 0 {
+		return rls, err
+	}
+	rest, sensitive := util.SplitSensitiveValues(rel.Config, paths)
+	if len(sensitive) == 0 {
+		return rls, nil
+	}
+	if s.KeyProvider == nil {
+		return nil, fmt.Errorf("release %q has values marked as sensitive, set HELM_SENSITIVE_VALUES_KEY or HELM_SENSITIVE_VALUES_KMS_PLUGIN to encrypt them", rel.Name)
+	}
+
+	data, err := json.Marshal(sensitive)
+	if err != nil {
+		return nil, err
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := driver.NewAESGCMProvider(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := aead.Encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.KeyProvider.EncryptKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt the sensitive values of release %q: %w", rel.Name, err)
+	}
+
+	out := *rel
+	out.Config = rest
+	out.SensitiveValues = &rspb.SensitiveValues{
+		Provider: s.KeyProvider.Name(),
+		Key:      key,
+		Data:     encrypted,
+	}
+	return &out, nil
+}
+
+// decryptSensitiveValues returns a copy of the release with its sensitive
+// values decrypted and merged back into its config.
+func (s *Storage) decryptSensitiveValues(rls release.Releaser) (release.Releaser, error) {
+	rel, err := releaserToV1Release(rls)
+	if err != nil || rel == nil || rel.SensitiveValues == nil {
+		return rls, nil
+	}
+	sv := rel.SensitiveValues
+	if s.KeyProvider == nil {
+		return nil, fmt.Errorf("release %q has encrypted sensitive values, set HELM_SENSITIVE_VALUES_KEY or HELM_SENSITIVE_VALUES_KMS_PLUGIN to decrypt them", rel.Name)
+	}
+	if sv.Provider != s.KeyProvider.Name() {
+		return nil, fmt.Errorf("the sensitive values of release %q were encrypted by the %q key provider, not %q", rel.Name, sv.Provider, s.KeyProvider.Name())
+	}
+	dataKey, err := s.KeyProvider.DecryptKey(sv.Key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the sensitive values of release %q: %w", rel.Name, err)
+	}
+	aead, err := driver.NewAESGCMProvider(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Decrypt(sv.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the sensitive values of release %q: %w", rel.Name, err)
+	}
+	var sensitive map[string]any
+	if err := json.Unmarshal(data, &sensitive); err != nil {
+		return nil, err
+	}
+
+	out := *rel
+	out.Config = util.MergeTables(sensitive, rel.Config)
+	out.SensitiveValues = nil
+	return &out, nil
+}
+
+// decryptSensitiveValuesList decrypts the sensitive values of the releases.
+// The releases whose sensitive values cannot be decrypted are left out with a
+// warning, so that the others can still be listed.
+func (s *Storage) decryptSensitiveValuesList(ls []release.Releaser) ([]release.Releaser, error) {
+	out := ls[:0]
+	for _, rls := range ls {
+		dec, err := s.decryptSensitiveValues(rls)
+		if err != nil {
+			s.Logger().Warn("skipping a release whose sensitive values cannot be decrypted", slog.Any("error", err))
+			continue
+		}
+		out = append(out, dec)
+	}
+	return out, nil
+}
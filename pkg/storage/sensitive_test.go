@@ -0,0 +1,120 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/release/common"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func sensitiveRelease() *rspb.Release {
+	rls := ReleaseTestData{Name: "angry-beaver", Version: 1, Status: common.StatusDeployed}.ToRelease()
+	rls.Chart = &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app", Version: "0.1.0"},
+		Schema:   []byte(`{"properties": {"password": {"type": "string", "x-helm-sensitive": true}}}`),
+	}
+	rls.Config = map[string]any{"password": "hunter2", "replicas": 2}
+	return rls
+}
+
+func TestStorageSensitiveValues(t *testing.T) {
+	kp, err := NewLocalKeyProvider(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	d := driver.NewMemory()
+	s := Init(d)
+	s.KeyProvider = kp
+
+	rls := sensitiveRelease()
+	require.NoError(t, s.Create(rls))
+
+	// The record holds the sensitive values encrypted only.
+	stored, err := d.Get(makeKey(rls.Name, rls.Version))
+	require.NoError(t, err)
+	rec := stored.(*rspb.Release)
+	assert.Equal(t, map[string]any{"replicas": 2}, rec.Config)
+	require.NotNil(t, rec.SensitiveValues)
+	assert.Equal(t, "local", rec.SensitiveValues.Provider)
+	assert.NotContains(t, string(rec.SensitiveValues.Data), "hunter2")
+
+	res, err := s.Get(rls.Name, rls.Version)
+	require.NoError(t, err)
+	assert.Equal(t, rls, res)
+
+	history, err := s.History(rls.Name)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, rls, history[0])
+
+	// Without the key, the sensitive values cannot be read.
+	s.KeyProvider = nil
+	_, err = s.Get(rls.Name, rls.Version)
+	assert.ErrorContains(t, err, "has encrypted sensitive values")
+
+	// With another key, they cannot be decrypted.
+	s.KeyProvider, err = NewLocalKeyProvider(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = s.Get(rls.Name, rls.Version)
+	assert.ErrorContains(t, err, "unable to decrypt the sensitive values")
+
+	// Listing skips the releases that cannot be decrypted.
+	history, err = s.History(rls.Name)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestStorageSensitiveValuesWithoutKeyProvider(t *testing.T) {
+	d := driver.NewMemory()
+	s := Init(d)
+
+	rls := sensitiveRelease()
+	assert.ErrorContains(t, s.Create(rls), "set HELM_SENSITIVE_VALUES_KEY or HELM_SENSITIVE_VALUES_KMS_PLUGIN")
+
+	_, err := d.Get(makeKey(rls.Name, rls.Version))
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound, "sensitive values must not be stored unencrypted")
+}
+
+func TestKeyProviderFromEnv(t *testing.T) {
+	kp, err := KeyProviderFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, kp)
+
+	t.Setenv("HELM_SENSITIVE_VALUES_KEY", "AQEBAQEBAQEBAQEBAQEBAQ==")
+	kp, err = KeyProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "local", kp.Name())
+
+	t.Setenv("HELM_SENSITIVE_VALUES_KMS_PLUGIN", "/usr/local/bin/kms")
+	_, err = KeyProviderFromEnv()
+	assert.ErrorContains(t, err, "mutually exclusive")
+
+	t.Setenv("HELM_SENSITIVE_VALUES_KEY", "")
+	kp, err = KeyProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "kms", kp.Name())
+
+	t.Setenv("HELM_SENSITIVE_VALUES_KMS_PLUGIN", "")
+	t.Setenv("HELM_SENSITIVE_VALUES_KEY", "c2hvcnQ=")
+	_, err = KeyProviderFromEnv()
+	assert.Error(t, err)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"helm.sh/helm/v4/internal/logging"
 	"helm.sh/helm/v4/pkg/release"
@@ -46,6 +47,26 @@ type Storage struct {
 	// ignored (meaning no limits are imposed).
 	MaxHistory int
 
+	// MaxHistoryAge is the age after which revisions are removed, based on
+	// when they were last deployed. The newest and the deployed revisions are
+	// always kept. Zero means no limit.
+	MaxHistoryAge time.Duration
+
+	// RetentionHook, when set, decides the retention enforced for each new
+	// revision. Releases that record a retention take precedence over
+	// MaxHistory and MaxHistoryAge before the hook is called.
+	RetentionHook RetentionHook
+
+	// KeyProvider encrypts the keys of the sensitive values of the releases.
+	// When it is nil, the releases with sensitive values cannot be stored.
+	KeyProvider KeyProvider
+
+	// ManifestDeltas stores the manifests of the revisions other than the
+	// latest one as deltas against the manifest of their next revision.
+	// Releases stored this way can still be read whatever its value, but
+	// not by versions of Helm that predate it.
+	ManifestDeltas bool
+
 	// Embed a LogHolder to provide logger functionality
 	logging.LogHolder
 }
@@ -55,7 +76,40 @@ type Storage struct {
 // release identified by the key, version pair does not exist.
 func (s *Storage) Get(name string, version int) (release.Releaser, error) {
 	s.Logger().Debug("getting release", "key", makeKey(name, version))
-	return s.Driver.Get(makeKey(name, version))
+	rls, err := s.Driver.Get(makeKey(name, version))
+	if err != nil {
+		return nil, err
+	}
+	if rls, err = s.expandManifest(rls, map[string]string{}); err != nil {
+		return nil, err
+	}
+	return s.decryptSensitiveValues(rls)
+}
+
+// List returns the releases accepted by the filter. Their sensitive values
+// are decrypted and their manifests rebuilt.
+func (s *Storage) List(filter func(release.Releaser) bool) ([]release.Releaser, error) {
+	ls, err := s.Driver.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	if ls, err = s.expandManifestList(ls); err != nil {
+		return nil, err
+	}
+	return s.decryptSensitiveValuesList(ls)
+}
+
+// Query returns the releases that match the labels. Their sensitive values
+// are decrypted and their manifests rebuilt.
+func (s *Storage) Query(labels map[string]string) ([]release.Releaser, error) {
+	ls, err := s.Driver.Query(labels)
+	if err != nil {
+		return nil, err
+	}
+	if ls, err = s.expandManifestList(ls); err != nil {
+		return nil, err
+	}
+	return s.decryptSensitiveValuesList(ls)
 }
 
 // Create creates a new storage entry holding the release. An
@@ -67,14 +121,35 @@ func (s *Storage) Create(rls release.Releaser) error {
 		return err
 	}
 	s.Logger().Debug("creating release", "key", makeKey(rac.Name(), rac.Version()))
-	if s.MaxHistory > 0 {
+	policy := s.retention(rls)
+	if policy.MaxHistory > 0 {
 		// Want to make space for one more release.
-		if err := s.removeLeastRecent(rac.Name(), s.MaxHistory-1); err != nil &&
+		if err := s.removeLeastRecent(rac.Name(), policy.MaxHistory-1); err != nil &&
 			!errors.Is(err, driver.ErrReleaseNotFound) {
 			return err
 		}
 	}
-	return s.Driver.Create(makeKey(rac.Name(), rac.Version()), rls)
+	if policy.MaxAge > 0 {
+		if err := s.removeOlderThan(rac.Name(), policy.MaxAge); err != nil &&
+			!errors.Is(err, driver.ErrReleaseNotFound) {
+			return err
+		}
+	}
+	stored, err := s.encryptSensitiveValues(rls)
+	if err != nil {
+		return err
+	}
+	if err := s.Driver.Create(makeKey(rac.Name(), rac.Version()), stored); err != nil {
+		return err
+	}
+	if rel, err := releaserToV1Release(rls); s.ManifestDeltas && err == nil && rel != nil {
+		// The revision is stored already, so failing to compress the
+		// history is not an error.
+		if err := s.rebaseManifestDeltas(rel); err != nil {
+			s.Logger().Warn("unable to store the history as manifest deltas", "release", rac.Name(), slog.Any("error", err))
+		}
+	}
+	return nil
 }
 
 // Update updates the release in storage. An error is returned if the
@@ -86,6 +161,14 @@ func (s *Storage) Update(rls release.Releaser) error {
 		return err
 	}
 	s.Logger().Debug("updating release", "key", makeKey(rac.Name(), rac.Version()))
+	rls, err = s.encodeManifestDelta(rls)
+	if err != nil {
+		return err
+	}
+	rls, err = s.encryptSensitiveValues(rls)
+	if err != nil {
+		return err
+	}
 	return s.Driver.Update(makeKey(rac.Name(), rac.Version()), rls)
 }
 
@@ -94,7 +177,23 @@ func (s *Storage) Update(rls release.Releaser) error {
 // does not exist.
 func (s *Storage) Delete(name string, version int) (release.Releaser, error) {
 	s.Logger().Debug("deleting release", "key", makeKey(name, version))
-	return s.Driver.Delete(makeKey(name, version))
+	// The revisions stored as deltas against this one need its manifest.
+	if err := s.detachManifestDeltas(name, version); err != nil {
+		return nil, err
+	}
+	rls, err := s.Driver.Delete(makeKey(name, version))
+	if err != nil {
+		return nil, err
+	}
+	// The record is gone already, so failing to decode it is not an error.
+	// This also lets a revision whose base revision is missing be removed.
+	if exp, err := s.expandManifest(rls, map[string]string{}); err == nil {
+		rls = exp
+	}
+	if dec, err := s.decryptSensitiveValues(rls); err == nil {
+		return dec, nil
+	}
+	return rls, nil
 }
 
 // ListReleases returns all releases from storage. An error is returned if the